@@ -10,9 +10,19 @@ type Config struct {
 	Logger           LoggerConfig            `env:",prefix=LOGGER_"`
 	Observability    ObservabilityHTTPConfig `env:",prefix=OBSERVABILITY_"`
 	ShutdownDuration time.Duration           `env:"SHUTDOWN_DURATION,default=30s"`
+	Modules          []string                `env:"MODULES,default=bot,workers,http"` // bot, workers, http: что запускать в этом процессе
 	DB               SQLiteConfig            `env:",prefix=DB_"`
 	Telegram         TelegramConfig          `env:",prefix=TELEGRAM_"`
 	YooKassa         YooKassaConfig          `env:",prefix=YOOKASSA_"`
+	Traffic          TrafficConfig           `env:",prefix=TRAFFIC_"`
+	ShortLinks       ShortLinksConfig        `env:",prefix=SHORTLINK_"`
+	WebAdmin         WebAdminConfig          `env:",prefix=WEBADMIN_"`
+	Commission       CommissionConfig        `env:",prefix=COMMISSION_"`
+	Drip             DripConfig              `env:",prefix=DRIP_"`
+	Reminder         ReminderConfig          `env:",prefix=REMINDER_"`
+	Brand            BrandConfig             `env:",prefix=BRAND_"`
+	Report           ReportConfig            `env:",prefix=REPORT_"`
+	Guarantee        GuaranteeConfig         `env:",prefix=GUARANTEE_"`
 	Metrics          struct {
 		Collector struct {
 			Timeout time.Duration `env:"COLLECTOR_TIMEOUT,default=10s"`
@@ -25,6 +35,14 @@ type TelegramConfig struct {
 	Timeout      time.Duration `env:"TIMEOUT,default=30s"`
 	AdminIDs     []int64       `env:"ADMIN_IDS"`
 	AssistantIDs []int64       `env:"ASSISTANT_IDS"`
+	AdminGroupID int64         `env:"ADMIN_GROUP_ID"`
+	// FloodLimit - сколько апдейтов от пользователя за FloodWindow допустимо; больше - автобан. 0 - без ограничения
+	FloodLimit  int           `env:"FLOOD_LIMIT,default=30"`
+	FloodWindow time.Duration `env:"FLOOD_WINDOW,default=10s"`
+	// LaunchMode - мягкий запуск: ботом пользуются только админы и пользователи из белого списка (/whitelist)
+	LaunchMode bool `env:"LAUNCH_MODE,default=false"`
+	// FlowTimeout - через сколько бездействия флоу отменяется с уведомлением. 0 - флоу не истекают
+	FlowTimeout time.Duration `env:"FLOW_TIMEOUT,default=30m"`
 }
 
 type YooKassaConfig struct {
@@ -32,6 +50,77 @@ type YooKassaConfig struct {
 	SecretKey     string `env:"SECRET_KEY,required"`
 	ReturnURL     string `env:"RETURN_URL,default=https://example.com/payment/return"`
 	ManualPayment bool   `env:"MANUAL_PAYMENT,default=false"`
+	// Description и ReceiptItem - шаблоны описания платежа и названия позиции в чеке с подстановками
+	// {id}, {amount}, {tariff}, {client}; пусто - «Оплата подписки #{id}», в чеке - как в описании
+	Description string `env:"DESCRIPTION"`
+	ReceiptItem string `env:"RECEIPT_ITEM"`
+}
+
+type TrafficConfig struct {
+	TopUpPricePerGB float64 `env:"TOPUP_PRICE_PER_GB,default=5"`
+}
+
+// CommissionConfig - комиссия ассистента за каждое оплаченное создание или продление подписки; нули - комиссия не начисляется
+type CommissionConfig struct {
+	Percent  float64 `env:"PERCENT,default=0"`
+	FixedFee float64 `env:"FIXED_FEE,default=0"`
+}
+
+// DripConfig - рассылка клиентам на пробном периоде
+type DripConfig struct {
+	// UpgradeDiscountPercent - скидка, которую ассистент предлагает в последний день пробного периода
+	UpgradeDiscountPercent int `env:"UPGRADE_DISCOUNT_PERCENT,default=10"`
+}
+
+// ReminderConfig - когда ассистенты получают напоминания об истекающих подписках
+type ReminderConfig struct {
+	// DefaultHour - час напоминаний (0-23) для клиентов без истории оплат
+	DefaultHour int `env:"DEFAULT_HOUR,default=7"`
+	// PeakHours - напоминать в час, когда клиент обычно оплачивает
+	PeakHours bool `env:"PEAK_HOURS,default=true"`
+}
+
+// BrandConfig - брендинг: одна сборка запускает несколько ботов под разными брендами
+type BrandConfig struct {
+	Name    string `env:"NAME,default=Kurut VPN"`
+	LogoURL string `env:"LOGO_URL"` // фото в /start; пусто - без фото
+	// Support - контакт поддержки (например, @kurut_support); пусто - сообщения клиентам без подписи бренда
+	Support string `env:"SUPPORT"`
+	Color   string `env:"COLOR,default=#D42631"` // основной цвет страницы подключения
+}
+
+// ReportConfig - месячная финансовая сводка владельцу бизнеса
+type ReportConfig struct {
+	// OwnerIDs - кому слать сводку 1-го числа; пусто - админам
+	OwnerIDs []int64 `env:"OWNER_IDS"`
+	// ProviderFeePercent - комиссия платежного провайдера для оценки расходов, %
+	ProviderFeePercent float64 `env:"PROVIDER_FEE_PERCENT,default=3.5"`
+}
+
+// OwnerChatIDs возвращает получателей месячной сводки
+func (c Config) OwnerChatIDs() []int64 {
+	if len(c.Report.OwnerIDs) > 0 {
+		return c.Report.OwnerIDs
+	}
+	return c.Telegram.AdminIDs
+}
+
+// GuaranteeConfig - гарантия возврата денег за первую оплату подписки
+type GuaranteeConfig struct {
+	// Days - сколько дней после оплаты клиент может вернуть деньги из карточки подписки; 0 - гарантии нет
+	Days int `env:"DAYS,default=0"`
+}
+
+type ShortLinksConfig struct {
+	// BaseURL - публичный адрес API сервера (например, https://pay.example.com); пусто - ссылки не сокращаются
+	BaseURL string `env:"BASE_URL"`
+}
+
+type WebAdminConfig struct {
+	SessionTTL   time.Duration `env:"SESSION_TTL,default=168h"`
+	CookieSecure bool          `env:"COOKIE_SECURE,default=true"`
+	// RedirectURL - куда вернуть браузер после входа через Telegram; пусто - ответ в JSON
+	RedirectURL string `env:"REDIRECT_URL"`
 }
 
 type HTTPClientConfig struct {
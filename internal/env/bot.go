@@ -0,0 +1,590 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"kurut-bot/internal/config"
+	tgclient "kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/bans"
+	"kurut-bot/internal/stories/bulkrenew"
+	"kurut-bot/internal/stories/commissions"
+	"kurut-bot/internal/stories/guarantee"
+	"kurut-bot/internal/stories/orders"
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/stories/users"
+	"kurut-bot/internal/stories/wgagent"
+	"kurut-bot/internal/telegram"
+	"kurut-bot/internal/telegram/cmds"
+	"kurut-bot/internal/telegram/flows/addserver"
+	"kurut-bot/internal/telegram/flows/bulkrenewsub"
+	"kurut-bot/internal/telegram/flows/cancelsub"
+	"kurut-bot/internal/telegram/flows/createsubforclient"
+	"kurut-bot/internal/telegram/flows/createtariff"
+	"kurut-bot/internal/telegram/flows/editserver"
+	"kurut-bot/internal/telegram/flows/editsub"
+	"kurut-bot/internal/telegram/flows/edittariff"
+	"kurut-bot/internal/telegram/flows/migrateclient"
+	"kurut-bot/internal/telegram/flows/sendbroadcast"
+	"kurut-bot/internal/telegram/flows/subnote"
+	"kurut-bot/internal/telegram/flows/transfersubs"
+	"kurut-bot/internal/telegram/flows/watemplate"
+	"kurut-bot/internal/telegram/messages"
+	"kurut-bot/internal/telegram/states"
+	"kurut-bot/internal/workers/broadcast"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+)
+
+// Bot - модуль Telegram бота: роутер с флоу и командами, long polling и очередь рассылок.
+// Рассылки держатся в памяти, поэтому их воркер живет в процессе бота, а не в модуле workers
+type Bot struct {
+	Router *telegram.Router
+
+	client    *tgclient.Client
+	broadcast *broadcast.Worker
+	expirer   *telegram.FlowExpirer // nil - флоу не истекают
+	logger    *slog.Logger
+	cancel    context.CancelFunc
+}
+
+func newBot(clients *Clients, cfg *config.Config, payments *Payments, logger *slog.Logger) (*Bot, error) {
+	if clients.TelegramBot == nil {
+		return nil, errors.New("telegram bot не инициализирован")
+	}
+	storageImpl := storage.New(clients.SQLiteDB.DB)
+
+	userService := users.NewService(storageImpl)
+	tariffService := tariffs.NewService(storageImpl)
+	serverService := servers.NewService(storageImpl.ServersRepo)
+	createSubService := newCreateSubService(clients)
+	paymentService := payments.Service
+
+	// Создаем StateManager
+	stateManager := states.NewManager()
+
+	// Создаем AdminChecker
+	adminChecker := telegram.NewAdminChecker(&cfg.Telegram, storageImpl, logger)
+
+	// Создаем Orders service
+	orderService := orders.NewService(storageImpl.OrdersRepo)
+
+	// Опрос о причине отмены флоу, для статистики
+	cancelReasonCommand := cmds.NewCancelReasonCommand(clients.TelegramBot.GetBotAPI(), storageImpl, logger)
+
+	// Создаем createSubForClientHandler
+	createSubForClientHandler := createsubforclient.NewHandler(
+		clients.TelegramBot,
+		stateManager,
+		tariffService,
+		createSubService,
+		storageImpl, // subscriptionStorage для проверки trial
+		paymentService,
+		orderService,
+		storageImpl,
+		storageImpl, // waitlistStorage
+		cancelReasonCommand,
+		adminChecker,
+		logger,
+	)
+
+	// Создаем createTariffHandler
+	createTariffHandler := createtariff.NewHandler(
+		clients.TelegramBot,
+		stateManager,
+		tariffService,
+		logger,
+	)
+
+	// Создаем editTariffHandler
+	editTariffHandler := edittariff.NewHandler(
+		clients.TelegramBot,
+		stateManager,
+		tariffService,
+		serverService,
+		logger,
+	)
+
+	// Создаем addServerHandler
+	addServerHandler := addserver.NewHandler(
+		clients.TelegramBot,
+		stateManager,
+		serverService,
+		logger,
+	)
+
+	// Создаем editServerHandler
+	editServerHandler := editserver.NewHandler(
+		clients.TelegramBot,
+		stateManager,
+		serverService,
+		logger,
+	)
+
+	// Создаем mySubsCommand
+	mySubsCommand := cmds.NewMySubsCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+	)
+
+	// Создаем statsCommand
+	statsCommand := cmds.NewStatsCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		adminChecker,
+	)
+
+	expirationNotificationService := newExpirationNotifications(clients, payments, logger)
+
+	// Создаем expirationCommand
+	expirationCommand := cmds.NewExpirationCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		storageImpl.ServersRepo, // serverStorage
+		tariffService,
+		paymentService,
+		storageImpl, // messageStorage
+		expirationNotificationService,
+		logger,
+	)
+
+	// Создаем tariffsCommand
+	tariffsCommand := cmds.NewTariffsCommand(
+		clients.TelegramBot.GetBotAPI(),
+		tariffService,
+		storageImpl,
+		logger,
+	)
+
+	// Создаем serversCommand
+	serversCommand := cmds.NewServersCommand(
+		clients.TelegramBot.GetBotAPI(),
+		serverService,
+		wgagent.NewService(storageImpl),
+		logger,
+	)
+
+	// Создаем topReferrersCommand
+	topReferrersCommand := cmds.NewTopReferrersCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+	)
+
+	stuckPaymentsCommand := cmds.NewStuckPaymentsCommand(
+		clients.TelegramBot.GetBotAPI(),
+		paymentService,
+	)
+
+	cohortsCommand := cmds.NewCohortsCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+	)
+
+	revenueCommand := cmds.NewRevenueCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+	)
+
+	commissionCommand := cmds.NewCommissionCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		commissions.Rate{Percent: cfg.Commission.Percent, FixedFee: cfg.Commission.FixedFee},
+		adminChecker,
+		logger,
+	)
+
+	waPlanCommand := cmds.NewWAPlanCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		storageImpl,
+		logger,
+	)
+
+	calendarCommand := cmds.NewCalendarCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		tariffService,
+	)
+
+	trafficTopUpCommand := cmds.NewTrafficTopUpCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		storageImpl,
+		tariffService,
+		paymentService,
+		cfg.Traffic.TopUpPricePerGB,
+		logger,
+	)
+
+	unpaidSubsCommand := cmds.NewUnpaidSubsCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		tariffService,
+	)
+
+	vacationCommand := cmds.NewVacationCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		adminChecker,
+		adminChecker,
+		logger,
+	)
+
+	clientPlatformCommand := cmds.NewClientPlatformCommand(clients.TelegramBot.GetBotAPI(), storageImpl)
+
+	clientLanguageCommand := cmds.NewClientLanguageCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		storageImpl,
+	)
+
+	escalationCommand := cmds.NewEscalationCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		adminChecker,
+		logger,
+	)
+
+	trialDripCommand := newTrialDripCommand(clients, cfg, logger)
+
+	subPriceCommand := cmds.NewSubPriceCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		tariffService,
+		logger,
+	)
+
+	tariffPriceCommand := newTariffPriceCommand(clients, logger)
+
+	serverPriceCommand := cmds.NewServerPriceCommand(
+		clients.TelegramBot.GetBotAPI(),
+		serverService,
+		logger,
+	)
+
+	// Создаем subViewCommand
+	subViewCommand := cmds.NewSubViewCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		paymentService,
+		guarantee.Policy{Days: cfg.Guarantee.Days},
+		logger,
+	)
+
+	// Создаем findCommand
+	findCommand := cmds.NewFindCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		subViewCommand,
+	)
+
+	// Создаем clientsCommand
+	clientsCommand := cmds.NewClientsCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+	)
+
+	// Создаем referralCommand
+	referralCommand := cmds.NewReferralCommand(clients.TelegramBot.GetBotAPI(), storageImpl, logger)
+
+	// Создаем whitelistCommand
+	whitelistCommand := cmds.NewWhitelistCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		cfg.Telegram.LaunchMode,
+		adminChecker,
+		logger,
+	)
+
+	// Создаем languageCommand
+	languageCommand := cmds.NewLanguageCommand(
+		clients.TelegramBot.GetBotAPI(),
+		userService,
+	)
+
+	// Создаем rolesCommand
+	rolesCommand := cmds.NewRolesCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		adminChecker,
+		logger,
+	)
+
+	// Создаем bansCommand
+	bansCommand := cmds.NewBansCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		adminChecker,
+		logger,
+	)
+
+	// Создаем mergePhonesCommand
+	mergePhonesCommand := cmds.NewMergePhonesCommand(clients.TelegramBot.GetBotAPI(), storageImpl, logger)
+
+	// Создаем dbStatsCommand
+	dbStatsCommand := cmds.NewDBStatsCommand(clients.TelegramBot.GetBotAPI(), storageImpl, logger)
+
+	// Создаем latePaymentsCommand
+	latePaymentsCommand := cmds.NewLatePaymentsCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		paymentService,
+		createSubService,
+		logger,
+	)
+
+	// Создаем migrateClientHandler
+	migrateClientHandler := migrateclient.NewHandler(
+		clients.TelegramBot,
+		stateManager,
+		tariffService,
+		serverService,
+		createSubService,
+		paymentService,
+		orderService,
+		storageImpl,
+		storageImpl,
+		cancelReasonCommand,
+		logger,
+	)
+
+	// Создаем editSubHandler
+	editSubHandler := editsub.NewHandler(
+		clients.TelegramBot,
+		stateManager,
+		storageImpl,
+		serverService,
+		logger,
+	)
+
+	// Создаем сервис и флоу массового продления
+	bulkRenewService := bulkrenew.NewService(storageImpl, logger)
+	bulkRenewSubHandler := bulkrenewsub.NewHandler(
+		clients.TelegramBot,
+		stateManager,
+		storageImpl,
+		tariffService,
+		paymentService,
+		storageImpl,
+		bulkRenewService,
+		logger,
+	)
+
+	// Создаем флоу передачи подписок между ассистентами
+	transferSubsHandler := transfersubs.NewHandler(
+		clients.TelegramBot.GetBotAPI(),
+		stateManager,
+		storageImpl,
+		adminChecker,
+		logger,
+	)
+
+	// Создаем cancelSubHandler
+	cancelSubHandler := cancelsub.NewHandler(
+		clients.TelegramBot,
+		stateManager,
+		storageImpl,
+		logger,
+	)
+
+	// Создаем broadcast worker и флоу рассылки
+	broadcastWorker := broadcast.NewWorker(clients.TelegramBot, logger)
+	broadcastHandler := sendbroadcast.NewHandler(
+		clients.TelegramBot.GetBotAPI(),
+		stateManager,
+		storageImpl,
+		broadcastWorker,
+		logger,
+	)
+
+	subNoteHandler := subnote.NewHandler(
+		clients.TelegramBot.GetBotAPI(),
+		stateManager,
+		storageImpl,
+		logger,
+	)
+
+	// Создаем роутер
+	router := telegram.NewRouter(
+		clients.TelegramBot.GetBotAPI(),
+		stateManager,
+		userService,
+		adminChecker,
+		createSubForClientHandler,
+		createTariffHandler,
+		addServerHandler,
+		migrateClientHandler,
+		editSubHandler,
+		cancelSubHandler,
+		mySubsCommand,
+		statsCommand,
+		expirationCommand,
+		tariffsCommand,
+		serversCommand,
+		topReferrersCommand,
+		stuckPaymentsCommand,
+		cohortsCommand,
+		waPlanCommand,
+		calendarCommand,
+		trafficTopUpCommand,
+		unpaidSubsCommand,
+		vacationCommand,
+		clientLanguageCommand,
+		subPriceCommand,
+		tariffPriceCommand,
+		serverPriceCommand,
+		findCommand,
+		latePaymentsCommand,
+		subViewCommand,
+		bulkRenewSubHandler,
+		transferSubsHandler,
+		editTariffHandler,
+		editServerHandler,
+		bansCommand,
+		mergePhonesCommand,
+		dbStatsCommand,
+		bans.NewFloodGuard(cfg.Telegram.FloodLimit, cfg.Telegram.FloodWindow),
+		clientsCommand,
+		whitelistCommand,
+		broadcastHandler,
+		referralCommand,
+		revenueCommand,
+		cmds.NewExportSubsCommand(clients.TelegramBot.GetBotAPI(), storageImpl, logger),
+		cmds.NewExportPaymentsCommand(clients.TelegramBot.GetBotAPI(), storageImpl, logger),
+		clientPlatformCommand,
+		commissionCommand,
+		trialDripCommand,
+		escalationCommand,
+		cancelReasonCommand,
+		subNoteHandler,
+		watemplate.NewHandler(clients.TelegramBot.GetBotAPI(), stateManager, storageImpl, logger),
+		rolesCommand,
+		languageCommand,
+		cmds.NewInlineClientsCommand(clients.TelegramBot.GetBotAPI(), storageImpl, logger),
+		cmds.NewCheckCommand(clients.TelegramBot.GetBotAPI(), storageImpl),
+		cmds.NewTariffOffersCommand(clients.TelegramBot.GetBotAPI(), storageImpl, tariffService, logger),
+	)
+
+	var expirer *telegram.FlowExpirer
+	if cfg.Telegram.FlowTimeout > 0 {
+		expirer = telegram.NewFlowExpirer(clients.TelegramBot.GetBotAPI(), stateManager, cfg.Telegram.FlowTimeout, logger)
+	}
+
+	return &Bot{
+		Router:    router,
+		client:    clients.TelegramBot,
+		broadcast: broadcastWorker,
+		expirer:   expirer,
+		logger:    logger,
+	}, nil
+}
+
+func (b *Bot) Name() string {
+	return ModuleBot
+}
+
+// Start запускает очередь рассылок, отмену брошенных флоу и long polling; апдейты обрабатываются роутером до Stop
+func (b *Bot) Start(ctx context.Context) error {
+	if err := b.broadcast.Start(); err != nil {
+		return fmt.Errorf("запуск воркера рассылок: %w", err)
+	}
+
+	ctx, b.cancel = context.WithCancel(ctx)
+
+	if b.expirer != nil {
+		go b.expirer.Run(ctx)
+	}
+
+	// Запускаем telegram клиент
+	if err := b.client.Start(ctx); err != nil {
+		return fmt.Errorf("запуск telegram клиента: %w", err)
+	}
+
+	// Устанавливаем команды для меню бота
+	if err := b.Router.SetupBotCommands(); err != nil {
+		b.logger.Error("Failed to setup bot commands", slog.Any("error", err))
+		// Не возвращаем ошибку, т.к. это не критично
+	} else {
+		b.logger.Info("Bot commands set up successfully")
+	}
+
+	// Получаем канал обновлений
+	updates := b.client.GetUpdates()
+
+	b.logger.Info("Started listening for updates with router...")
+
+	// Запускаем роутер для обработки обновлений
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case update := <-updates:
+				b.handleUpdate(update)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop прекращает получение апдейтов и останавливает очередь рассылок
+func (b *Bot) Stop(_ context.Context) {
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.client.Stop()
+	b.broadcast.Stop()
+}
+
+// handleUpdate обрабатывает апдейт роутером; паника не роняет бота, пользователю уходит сообщение об ошибке
+func (b *Bot) handleUpdate(update tgbotapi.Update) {
+	// Определяем chatID для отправки ошибки
+	var chatID int64
+	if update.Message != nil {
+		chatID = update.Message.Chat.ID
+	} else if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		chatID = update.CallbackQuery.Message.Chat.ID
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			b.logger.Error("PANIC при обработке update",
+				slog.Any("panic", r),
+				slog.String("stack", string(stack)))
+
+			// Отправляем пользователю сообщение об ошибке
+			if chatID != 0 {
+				errMsg := "⚠️ Произошла внутренняя ошибка.\n\n" +
+					"Если вы оплатили подписку - не переживайте! " +
+					"Ваш платеж сохранен и подписка будет создана автоматически в течение нескольких минут.\n\n" +
+					"Если проблема повторяется - " + messages.CurrentBrand().SupportHint() + "."
+				_ = b.client.SendMessage(chatID, errMsg)
+			}
+		}
+	}()
+
+	// Логируем входящие обновления
+	if update.Message != nil {
+		b.logger.Info("Получено сообщение",
+			slog.Int64("chat_id", update.Message.Chat.ID),
+			slog.Int64("user_id", update.Message.From.ID),
+			slog.String("text", update.Message.Text))
+	} else if update.CallbackQuery != nil {
+		b.logger.Info("Получен callback",
+			slog.Int64("chat_id", chatID),
+			slog.Int64("user_id", update.CallbackQuery.From.ID),
+			slog.String("data", update.CallbackQuery.Data))
+	}
+
+	// Обрабатываем через роутер
+	if err := b.Router.Route(&update); err != nil {
+		b.logger.Error("Ошибка обработки обновления", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,81 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Модули приложения: процесс запускает те, что перечислены в MODULES
+const (
+	ModuleBot     = "bot"     // Telegram бот: апдейты, флоу, команды, очередь рассылок
+	ModuleWorkers = "workers" // фоновые воркеры по расписанию
+	ModuleHTTP    = "http"    // API сервер и observability
+)
+
+// moduleOrder - порядок запуска; останавливаются модули в обратном порядке
+var moduleOrder = []string{ModuleHTTP, ModuleBot, ModuleWorkers}
+
+// Module - часть приложения со своим жизненным циклом
+type Module interface {
+	Name() string
+	Start(ctx context.Context) error
+	Stop(ctx context.Context)
+}
+
+// failer - модуль, который может упасть после запуска; процесс после этого должен завершиться
+type failer interface {
+	Failed() <-chan error
+}
+
+// parseModules проверяет список модулей из конфига: неизвестный модуль или пустой список - ошибка
+func parseModules(names []string) (map[string]bool, error) {
+	enabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !slices.Contains(moduleOrder, name) {
+			return nil, fmt.Errorf("unknown module %q, expected one of %v", name, moduleOrder)
+		}
+		enabled[name] = true
+	}
+	if len(enabled) == 0 {
+		return nil, fmt.Errorf("no modules enabled, expected some of %v", moduleOrder)
+	}
+	return enabled, nil
+}
+
+// Start запускает модули по порядку. Если модуль не запустился, уже запущенные останавливаются
+func (e *Env) Start(ctx context.Context) error {
+	for i, module := range e.Modules {
+		e.Logger.Info("Starting module", "module", module.Name())
+		if err := module.Start(ctx); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				e.Modules[j].Stop(ctx)
+			}
+			return fmt.Errorf("start module %s: %w", module.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Stop останавливает модули в обратном порядке запуска
+func (e *Env) Stop(ctx context.Context) {
+	for i := len(e.Modules) - 1; i >= 0; i-- {
+		e.Logger.Info("Stopping module", "module", e.Modules[i].Name())
+		e.Modules[i].Stop(ctx)
+	}
+}
+
+// Failed отдает ошибку первого упавшего после запуска модуля. Вызывается один раз после Start
+func (e *Env) Failed() <-chan error {
+	failed := make(chan error, len(e.Modules))
+	for _, module := range e.Modules {
+		f, ok := module.(failer)
+		if !ok || f.Failed() == nil {
+			continue
+		}
+		go func(name string, ch <-chan error) {
+			failed <- fmt.Errorf("module %s: %w", name, <-ch)
+		}(module.Name(), f.Failed())
+	}
+	return failed
+}
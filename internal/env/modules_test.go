@@ -0,0 +1,102 @@
+package environment
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+type fakeModule struct {
+	name     string
+	startErr error
+	events   *[]string
+}
+
+func (m fakeModule) Name() string { return m.name }
+
+func (m fakeModule) Start(context.Context) error {
+	*m.events = append(*m.events, "start "+m.name)
+	return m.startErr
+}
+
+func (m fakeModule) Stop(context.Context) {
+	*m.events = append(*m.events, "stop "+m.name)
+}
+
+type failingModule struct {
+	fakeModule
+	failed chan error
+}
+
+func (m failingModule) Failed() <-chan error { return m.failed }
+
+func TestParseModules(t *testing.T) {
+	enabled, err := parseModules([]string{ModuleWorkers})
+	if err != nil || !enabled[ModuleWorkers] || enabled[ModuleBot] {
+		t.Errorf("parseModules(workers) = %v, %v", enabled, err)
+	}
+	if _, err := parseModules([]string{"bot", "cron"}); err == nil {
+		t.Error("parseModules() must reject unknown modules")
+	}
+	if _, err := parseModules(nil); err == nil {
+		t.Error("parseModules() must reject an empty list")
+	}
+}
+
+func TestEnvStartStopsStartedModulesOnFailure(t *testing.T) {
+	var events []string
+	env := &Env{
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Modules: []Module{
+			fakeModule{name: ModuleHTTP, events: &events},
+			fakeModule{name: ModuleBot, events: &events},
+			fakeModule{name: ModuleWorkers, startErr: errors.New("boom"), events: &events},
+		},
+	}
+
+	if err := env.Start(context.Background()); err == nil {
+		t.Fatal("Start() must return the module error")
+	}
+	want := []string{"start http", "start bot", "start workers", "stop bot", "stop http"}
+	if !slices.Equal(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+
+	events = nil
+	env.Modules = env.Modules[:2]
+	if err := env.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	env.Stop(context.Background())
+	want = []string{"start http", "start bot", "stop bot", "stop http"}
+	if !slices.Equal(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestEnvFailed(t *testing.T) {
+	var events []string
+	workers := failingModule{fakeModule: fakeModule{name: ModuleWorkers, events: &events}, failed: make(chan error, 1)}
+	env := &Env{
+		Modules: []Module{
+			fakeModule{name: ModuleBot, events: &events},
+			failingModule{fakeModule: fakeModule{name: ModuleHTTP, events: &events}}, // nil канал - модуль без отказов
+			workers,
+		},
+	}
+
+	failed := env.Failed()
+	workers.failed <- errors.New("lock lost")
+	select {
+	case err := <-failed:
+		if err.Error() != "module workers: lock lost" {
+			t.Errorf("err = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Failed() did not report the module failure")
+	}
+}
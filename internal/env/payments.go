@@ -0,0 +1,93 @@
+package environment
+
+import (
+	"log/slog"
+	"time"
+
+	"kurut-bot/internal/config"
+	"kurut-bot/internal/infra/yookassa"
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/bonusrules"
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/shortlinks"
+	"kurut-bot/internal/stories/subs/createsubs"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/telegram/cmds"
+
+	"github.com/pkg/errors"
+)
+
+// Payments - платежи через ЮKassa и короткие ссылки на оплату; нужны боту и воркерам
+type Payments struct {
+	Service    *payment.Service
+	ShortLinks *shortlinks.Service
+}
+
+func newPayments(clients *Clients, cfg *config.Config, logger *slog.Logger) (*Payments, error) {
+	storageImpl := storage.New(clients.SQLiteDB.DB)
+
+	// Создаем YooKassa client
+	yookassaClient, err := yookassa.NewClient(cfg.YooKassa.ShopID, cfg.YooKassa.SecretKey, cfg.YooKassa.ReturnURL, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create yookassa client")
+	}
+
+	// Короткие ссылки на оплату (/p/<token> на API сервере)
+	shortLinkService := shortlinks.NewService(storageImpl, cfg.ShortLinks.BaseURL)
+
+	// Шаблоны описания платежа и позиции чека - для операторов, которым нужна своя юридическая формулировка
+	templates := payment.Templates{
+		Description: cfg.YooKassa.Description,
+		ReceiptItem: cfg.YooKassa.ReceiptItem,
+	}
+
+	return &Payments{
+		Service:    payment.NewService(storageImpl.PaymentsRepo, yookassaClient, shortLinkService, cfg.YooKassa.ReturnURL, cfg.YooKassa.ManualPayment, templates, logger),
+		ShortLinks: shortLinkService,
+	}, nil
+}
+
+// newCreateSubService создает сервис создания подписок с бонусными правилами
+func newCreateSubService(clients *Clients) *createsubs.Service {
+	storageImpl := storage.New(clients.SQLiteDB.DB)
+	return createsubs.NewService(storageImpl, bonusrules.NewService(storageImpl), time.Now)
+}
+
+// newExpirationNotifications создает уведомления об истечении подписок: их шлют и команды бота, и воркер
+func newExpirationNotifications(clients *Clients, payments *Payments, logger *slog.Logger) *cmds.ExpirationNotificationService {
+	storageImpl := storage.New(clients.SQLiteDB.DB)
+	return cmds.NewExpirationNotificationService(
+		clients.TelegramBot.GetBotAPI(),
+		tariffs.NewService(storageImpl),
+		storageImpl.ServersRepo, // serverStorage
+		storageImpl,             // messageStorage
+		payments.Service,
+		storageImpl, // langStorage
+		logger,
+	)
+}
+
+// newTrialDripCommand создает рассылку пробного периода: кнопки в боте и отправка из воркера
+func newTrialDripCommand(clients *Clients, cfg *config.Config, logger *slog.Logger) *cmds.TrialDripCommand {
+	storageImpl := storage.New(clients.SQLiteDB.DB)
+	return cmds.NewTrialDripCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		storageImpl,
+		storageImpl,
+		cfg.Drip.UpgradeDiscountPercent,
+		logger,
+	)
+}
+
+// newTariffPriceCommand создает планирование цен тарифов: команда в боте и рассылка карточек из воркера
+func newTariffPriceCommand(clients *Clients, logger *slog.Logger) *cmds.TariffPriceCommand {
+	storageImpl := storage.New(clients.SQLiteDB.DB)
+	return cmds.NewTariffPriceCommand(
+		clients.TelegramBot.GetBotAPI(),
+		storageImpl,
+		tariffs.NewService(storageImpl),
+		storageImpl,
+		logger,
+	)
+}
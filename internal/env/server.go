@@ -3,25 +3,71 @@ package environment
 import (
 	"context"
 	"kurut-bot/internal/config"
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/shortlinks"
+	"kurut-bot/internal/stories/websessions"
+	"kurut-bot/internal/stories/wgagent"
 	"kurut-bot/internal/telegram"
 	"log/slog"
 	"net/http"
 )
 
+// Servers - модуль HTTP: API сервер (страница подключения, Mini App, агенты, веб-админка) и observability
 type Servers struct {
 	HTTP struct {
 		Observability *http.Server
 		API           *http.Server
 	}
+
+	logger *slog.Logger
 }
 
 func newServers(ctx context.Context, cfg config.Config, logger *slog.Logger, clients *Clients, configStore *telegram.ConfigStore) *Servers {
-	var servers Servers
+	servers := Servers{logger: logger}
 
 	mux := http.NewServeMux()
+
+	// Короткие ссылки на оплату и учет переходов по ссылкам для клиентов
+	shortLinkService := shortlinks.NewService(storage.New(clients.SQLiteDB.DB), cfg.ShortLinks.BaseURL)
 	
-	mux.HandleFunc("/wg/connect", telegram.WGConnectHandler(configStore))
+	mux.HandleFunc("/wg/connect", telegram.WGConnectHandler(configStore, shortLinkService))
 	mux.HandleFunc("/wg/config/", telegram.WGConfigDownloadHandler(configStore))
+
+	// Контракт API для интеграций
+	mux.HandleFunc("GET /api/openapi.json", telegram.OpenAPISpecHandler())
+
+	// API для Mini App
+	mux.HandleFunc("GET /api/v1/users/{telegramID}/subscriptions", telegram.MiniAppSubscriptionsHandler(
+		storage.New(clients.SQLiteDB.DB),
+		cfg.Telegram.BotToken,
+		logger.WithGroup("miniapp"),
+	))
+	
+	// Состояние пиров для агентов WireGuard на серверах
+	mux.HandleFunc("GET /api/v1/agent/peers", telegram.AgentPeersHandler(
+		wgagent.NewService(storage.New(clients.SQLiteDB.DB)),
+		logger.WithGroup("agent"),
+	))
+
+	// Вход в веб-админку через Telegram Login Widget
+	webSessionService := websessions.NewService(
+		storage.New(clients.SQLiteDB.DB),
+		telegram.NewAdminChecker(&cfg.Telegram, storage.New(clients.SQLiteDB.DB), logger.WithGroup("admins")),
+		cfg.WebAdmin.SessionTTL,
+	)
+	mux.HandleFunc("GET /api/auth/telegram", telegram.TelegramLoginHandler(
+		webSessionService,
+		cfg.Telegram.BotToken,
+		cfg.WebAdmin,
+		logger.WithGroup("webauth"),
+	))
+	mux.HandleFunc("GET /api/auth/session", telegram.WebSessionHandler(webSessionService, logger.WithGroup("webauth")))
+	mux.HandleFunc("POST /api/auth/logout", telegram.WebLogoutHandler(webSessionService, cfg.WebAdmin, logger.WithGroup("webauth")))
+
+	mux.HandleFunc("GET "+shortlinks.PathPrefix+"{token}", telegram.PaymentLinkRedirectHandler(
+		shortLinkService,
+		logger.WithGroup("paylinks"),
+	))
 	
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -38,3 +84,40 @@ func newServers(ctx context.Context, cfg config.Config, logger *slog.Logger, cli
 
 	return &servers
 }
+
+func (s *Servers) Name() string {
+	return ModuleHTTP
+}
+
+// Start запускает серверы в фоне; ошибки ListenAndServe только логируются
+func (s *Servers) Start(_ context.Context) error {
+	for _, server := range []*http.Server{s.HTTP.Observability, s.HTTP.API} {
+		if server == nil {
+			continue
+		}
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					s.logger.Error("Panic in HTTP server goroutine", slog.Any("panic", r), slog.String("addr", server.Addr))
+				}
+			}()
+			s.logger.Info("Starting HTTP server", slog.String("addr", server.Addr))
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				s.logger.Error("HTTP server error", slog.Any("error", err), slog.String("addr", server.Addr))
+			}
+		}()
+	}
+	return nil
+}
+
+// Stop дожидается завершения запросов, но не дольше ctx
+func (s *Servers) Stop(ctx context.Context) {
+	for _, server := range []*http.Server{s.HTTP.Observability, s.HTTP.API} {
+		if server == nil {
+			continue
+		}
+		if err := server.Shutdown(ctx); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("HTTP server shutdown error", slog.Any("error", err), slog.String("addr", server.Addr))
+		}
+	}
+}
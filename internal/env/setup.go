@@ -6,7 +6,9 @@ import (
 	"log/slog"
 
 	"kurut-bot/internal/config"
+	"kurut-bot/internal/storage"
 	"kurut-bot/internal/telegram"
+	"kurut-bot/internal/telegram/messages"
 
 	"github.com/joho/godotenv"
 	"github.com/sethvargo/go-envconfig"
@@ -15,16 +17,16 @@ import (
 type closer func()
 
 type Env struct {
-	Config      *config.Config
-	Logger      *slog.Logger
-	Servers     *Servers
-	Clients     *Clients
-	Services    *Services
-	ConfigStore *telegram.ConfigStore
+	Config  *config.Config
+	Logger  *slog.Logger
+	Clients *Clients
 
+	// Modules - включенные модули в порядке запуска
+	Modules []Module
 	Closers []closer
 }
 
+// Setup собирает модули, перечисленные в MODULES
 func Setup(ctx context.Context) (*Env, error) {
 	// Загружаем .env файл если он существует (игнорируем ошибки - файл может не существовать)
 	_ = godotenv.Load()
@@ -35,6 +37,18 @@ func Setup(ctx context.Context) (*Env, error) {
 		return nil, fmt.Errorf("env processing: %w", err)
 	}
 
+	messages.SetBrand(messages.Brand{
+		Name:    cfg.Brand.Name,
+		LogoURL: cfg.Brand.LogoURL,
+		Support: cfg.Brand.Support,
+		Color:   cfg.Brand.Color,
+	})
+
+	enabled, err := parseModules(cfg.Modules)
+	if err != nil {
+		return nil, fmt.Errorf("parseModules: %w", err)
+	}
+
 	var e Env
 
 	logger, err := initLogger(cfg)
@@ -47,21 +61,46 @@ func Setup(ctx context.Context) (*Env, error) {
 		return nil, fmt.Errorf("newClients: %w", err)
 	}
 
-	configStore := telegram.NewConfigStore()
-
-	services, err := newServices(ctx, clients, &cfg, logger, configStore)
+	// Тексты сообщений клиентам, измененные через /templates; без них остаются встроенные
+	templates, err := storage.New(clients.SQLiteDB.DB).ListWhatsAppTemplates(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("newServices: %w", err)
+		logger.Error("Failed to load WhatsApp templates, using builtin texts", "error", err)
+	}
+	messages.SetWhatsAppOverrides(templates)
+
+	// Платежи нужны и боту, и воркерам
+	var payments *Payments
+	if enabled[ModuleBot] || enabled[ModuleWorkers] {
+		payments, err = newPayments(clients, &cfg, logger)
+		if err != nil {
+			return nil, fmt.Errorf("newPayments: %w", err)
+		}
 	}
 
-	servers := newServers(ctx, cfg, logger, clients, configStore)
+	for _, name := range moduleOrder {
+		if !enabled[name] {
+			continue
+		}
+
+		var module Module
+		switch name {
+		case ModuleHTTP:
+			module = newServers(ctx, cfg, logger, clients, telegram.NewConfigStore())
+		case ModuleBot:
+			module, err = newBot(clients, &cfg, payments, logger)
+		case ModuleWorkers:
+			// Блокировка нужна только отдельному процессу воркеров: рядом с ботом воркеры запускаются без нее
+			module, err = newWorkers(clients, &cfg, payments, !enabled[ModuleBot], logger)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %w", name, err)
+		}
+		e.Modules = append(e.Modules, module)
+	}
 
-	e.Servers = servers
 	e.Config = &cfg
 	e.Logger = logger
 	e.Clients = clients
-	e.Services = services
-	e.ConfigStore = configStore
 	e.Closers = []closer{} // Empty for now
 
 	return &e, nil
@@ -0,0 +1,182 @@
+package environment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"kurut-bot/internal/config"
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/bulkrenew"
+	"kurut-bot/internal/stories/peakhour"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/telegram"
+	"kurut-bot/internal/workers"
+	"kurut-bot/internal/workers/archival"
+	// "kurut-bot/internal/workers/disablereminder" // TODO: включить позже
+	"kurut-bot/internal/workers/expiration"
+	"kurut-bot/internal/workers/latepayments"
+	"kurut-bot/internal/workers/paymentautocheck"
+	"kurut-bot/internal/workers/pricechange"
+	"kurut-bot/internal/workers/stuckpayments"
+	"kurut-bot/internal/workers/trialdrip"
+	"kurut-bot/internal/workers/unpaidsubs"
+	"kurut-bot/internal/workers/waitlist"
+	"kurut-bot/internal/workers/weeklyreport"
+)
+
+// Workers - модуль фоновых воркеров по расписанию. Telegram клиент нужен только для отправки сообщений:
+// апдейты получает модуль bot, поэтому воркеры можно запускать отдельным процессом
+type Workers struct {
+	manager *workers.Manager
+}
+
+func newWorkers(clients *Clients, cfg *config.Config, payments *Payments, withLock bool, logger *slog.Logger) (*Workers, error) {
+	if clients.TelegramBot == nil {
+		return nil, errors.New("telegram bot не инициализирован")
+	}
+	storageImpl := storage.New(clients.SQLiteDB.DB)
+
+	tariffService := tariffs.NewService(storageImpl)
+	createSubService := newCreateSubService(clients)
+	bulkRenewService := bulkrenew.NewService(storageImpl, logger)
+	paymentService := payments.Service
+	expirationNotificationService := newExpirationNotifications(clients, payments, logger)
+	// Получатели уведомлений определяются по текущим ролям, а не по конфигу на момент запуска
+	adminChecker := telegram.NewAdminChecker(&cfg.Telegram, storageImpl, logger)
+
+	// Создаем expiration worker
+	expirationWorker := expiration.NewWorker(
+		storageImpl,
+		clients.TelegramBot,
+		expirationNotificationService,
+		adminChecker,
+		peakhour.Schedule{DefaultHour: cfg.Reminder.DefaultHour, PeakHours: cfg.Reminder.PeakHours},
+		logger,
+	)
+
+	// Создаем payment autocheck worker
+	paymentAutocheckWorker := paymentautocheck.NewWorker(
+		storageImpl,      // orderStorage
+		storageImpl,      // messageStorage
+		storageImpl,      // bulkRenewalStorage
+		bulkRenewService, // bulkRenewService
+		paymentService,   // paymentService
+		createSubService, // subscriptionService
+		storageImpl,      // subscriptionStorage
+		tariffService,    // tariffService
+		storageImpl,      // serverStorage
+		clients.TelegramBot,
+		cfg.YooKassa.ManualPayment,
+		logger,
+	)
+
+	// Создаем stuck payments worker
+	stuckPaymentsWorker := stuckpayments.NewWorker(
+		storageImpl,
+		clients.TelegramBot,
+		adminChecker,
+		cfg.YooKassa.ManualPayment,
+		logger,
+	)
+
+	// Создаем unpaid subscriptions worker
+	unpaidSubsWorker := unpaidsubs.NewWorker(
+		storageImpl,
+		clients.TelegramBot,
+		adminChecker,
+		logger,
+	)
+
+	// Создаем weekly report worker
+	weeklyReportWorker := weeklyreport.NewWorker(
+		storageImpl,
+		clients.TelegramBot,
+		adminChecker,
+		cfg.OwnerChatIDs(),
+		cfg.Report.ProviderFeePercent,
+		logger,
+	)
+
+	// Создаем late payments worker
+	latePaymentsWorker := latepayments.NewWorker(
+		storageImpl,
+		paymentService,
+		clients.TelegramBot,
+		adminChecker,
+		cfg.YooKassa.ManualPayment,
+		logger,
+	)
+
+	// Создаем waitlist worker
+	waitlistWorker := waitlist.NewWorker(storageImpl, clients.TelegramBot, logger)
+
+	// Создаем trial drip worker
+	trialDripWorker := trialdrip.NewWorker(storageImpl, newTrialDripCommand(clients, cfg, logger), adminChecker, logger)
+
+	// Создаем price change worker
+	priceChangeWorker := pricechange.NewWorker(storageImpl, newTariffPriceCommand(clients, logger), adminChecker, logger)
+
+	// Создаем archival worker
+	archivalWorker := archival.NewWorker(storageImpl, logger)
+
+	// TODO: включить позже
+	// Создаем disable reminder worker
+	// disableReminderWorker := disablereminder.NewWorker(
+	// 	storageImpl,
+	// 	clients.TelegramBot,
+	// 	expirationNotificationService,
+	// 	logger,
+	// )
+
+	// Создаем менеджер воркеров
+	manager := workers.NewManager(
+		logger,
+		expirationWorker,
+		paymentAutocheckWorker,
+		stuckPaymentsWorker,
+		unpaidSubsWorker,
+		latePaymentsWorker,
+		weeklyReportWorker,
+		archivalWorker,
+		waitlistWorker,
+		trialDripWorker,
+		priceChangeWorker,
+		// disableReminderWorker, // TODO: включить позже
+	)
+	// Отдельный процесс воркеров (MODULES=workers) держит блокировку в БД, чтобы во время деплоя
+	// старый и новый процессы не запускали воркеры одновременно
+	if withLock {
+		manager = manager.WithLock(storageImpl, lockOwner())
+	}
+
+	return &Workers{manager: manager}, nil
+}
+
+// lockOwner - имя процесса в блокировке воркеров: хост и PID
+func lockOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+func (w *Workers) Name() string {
+	return ModuleWorkers
+}
+
+func (w *Workers) Start(_ context.Context) error {
+	return w.manager.Start()
+}
+
+func (w *Workers) Stop(_ context.Context) {
+	w.manager.Stop()
+}
+
+// Failed срабатывает, когда процесс потерял блокировку воркеров и должен перезапуститься
+func (w *Workers) Failed() <-chan error {
+	return w.manager.Failed()
+}
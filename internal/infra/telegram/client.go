@@ -2,8 +2,10 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"golang.org/x/time/rate"
@@ -71,11 +73,9 @@ func (c *Client) SendMessage(chatID int64, text string) error {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "MarkdownV2"
 	msg.DisableWebPagePreview = true
-	_, err := c.api.Send(msg)
+	_, err := c.sendWithRetry(msg)
 	if err != nil {
-		c.logger.Error("ошибка отправки сообщения",
-			slog.Int64("chat_id", chatID),
-			slog.String("error", err.Error()))
+		err = c.logError("ошибка отправки сообщения", err, slog.Int64("chat_id", chatID))
 		return fmt.Errorf("отправка сообщения: %w", err)
 	}
 
@@ -91,12 +91,11 @@ func (c *Client) EditMessage(chatID int64, messageID int, text string) error {
 	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
 	edit.ParseMode = "MarkdownV2"
 	edit.DisableWebPagePreview = true
-	_, err := c.api.Send(edit)
+	_, err := c.sendWithRetry(edit)
 	if err != nil {
-		c.logger.Error("ошибка редактирования сообщения",
+		err = c.logError("ошибка редактирования сообщения", err,
 			slog.Int64("chat_id", chatID),
-			slog.Int("message_id", messageID),
-			slog.String("error", err.Error()))
+			slog.Int("message_id", messageID))
 		return fmt.Errorf("редактирование сообщения: %w", err)
 	}
 
@@ -112,8 +111,11 @@ func (c *Client) SendKeyboard(chatID int64, text string, keyboard tgbotapi.Inlin
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ReplyMarkup = keyboard
 
-	_, err := c.api.Send(msg)
-	return err
+	_, err := c.sendWithRetry(msg)
+	if err != nil {
+		return c.logError("ошибка отправки клавиатуры", err, slog.Int64("chat_id", chatID))
+	}
+	return nil
 }
 
 // Send отправляет любое сообщение с rate limiting (для интерфейса botApi)
@@ -122,9 +124,9 @@ func (c *Client) Send(chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
 		return tgbotapi.Message{}, fmt.Errorf("rate limiting: %w", err)
 	}
 
-	message, err := c.api.Send(chattable)
+	message, err := c.sendWithRetry(chattable)
 	if err != nil {
-		c.logger.Error("ошибка отправки", slog.Any("error", err))
+		err = c.logError("ошибка отправки", err)
 		return tgbotapi.Message{}, fmt.Errorf("отправка: %w", err)
 	}
 
@@ -138,15 +140,82 @@ func (c *Client) Request(chattable tgbotapi.Chattable) (*tgbotapi.APIResponse, e
 	}
 
 	resp, err := c.api.Request(chattable)
+	if wait, ok := floodWait(err); ok && c.waitFlood(wait) {
+		resp, err = c.api.Request(chattable)
+	}
 	if err != nil {
-		c.logger.Error("ошибка запроса к API", slog.Any("error", err))
+		err = c.logError("ошибка запроса к API", err)
 		return nil, fmt.Errorf("запрос к API: %w", err)
 	}
 
 	return resp, nil
 }
 
+// GetChat возвращает информацию о чате с rate limiting (для telegram.ChatGetter)
+func (c *Client) GetChat(config tgbotapi.ChatInfoConfig) (tgbotapi.Chat, error) {
+	if err := c.limiter.Wait(c.ctx); err != nil {
+		return tgbotapi.Chat{}, fmt.Errorf("rate limiting: %w", err)
+	}
+	return c.api.GetChat(config)
+}
+
 // GetBotAPI возвращает внутренний BotAPI объект
 func (c *Client) GetBotAPI() *tgbotapi.BotAPI {
 	return c.api
 }
+
+// sendWithRetry отправляет сообщение и один раз повторяет попытку после flood wait
+func (c *Client) sendWithRetry(chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	message, err := c.api.Send(chattable)
+	if wait, ok := floodWait(err); ok && c.waitFlood(wait) {
+		message, err = c.api.Send(chattable)
+	}
+	return message, err
+}
+
+// waitFlood ждет указанное Telegram время, false если контекст отменен
+func (c *Client) waitFlood(wait time.Duration) bool {
+	c.logger.Warn("flood wait от Telegram, ожидаем", slog.Duration("retry_after", wait))
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-c.ctx.Done():
+		return false
+	}
+}
+
+// logError классифицирует ошибку Telegram API и логирует ее со структурированными полями
+func (c *Client) logError(msg string, err error, attrs ...slog.Attr) error {
+	err = ClassifyError(err)
+
+	args := []any{slog.String("error_kind", errorKind(err)), slog.String("error", err.Error())}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		args = append(args, slog.Int("error_code", apiErr.Code))
+	}
+	for _, attr := range attrs {
+		args = append(args, attr)
+	}
+
+	// "message is not modified" - штатная ситуация при повторном редактировании
+	if errors.Is(err, ErrMessageNotModified) {
+		c.logger.Debug(msg, args...)
+	} else {
+		c.logger.Error(msg, args...)
+	}
+
+	return err
+}
+
+// floodWait возвращает время ожидания, если ошибка - flood wait
+func floodWait(err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(ClassifyError(err), &apiErr) && errors.Is(apiErr, ErrFloodWait) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, true
+	}
+	return 0, false
+}
@@ -0,0 +1,29 @@
+package telegram
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Sender - минимальный интерфейс отправки, который реализуют tgbotapi.BotAPI и Client
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+}
+
+// SafeEdit отправляет редактирование сообщения и считает "message is not modified" успехом.
+// Если сообщение не изменилось и передан callbackID - только отвечает на callback,
+// чтобы у пользователя пропали "часики" на кнопке
+func SafeEdit(bot Sender, edit tgbotapi.Chattable, callbackID string) error {
+	_, err := bot.Send(edit)
+	if err == nil {
+		return nil
+	}
+	if !IsMessageNotModified(err) {
+		return err
+	}
+
+	if callbackID != "" {
+		_, _ = bot.Request(tgbotapi.NewCallback(callbackID, ""))
+	}
+	return nil
+}
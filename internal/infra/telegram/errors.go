@@ -0,0 +1,107 @@
+package telegram
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Классы ошибок Telegram API, на которые вызывающий код может реагировать по-разному
+var (
+	// ErrBotBlocked - пользователь заблокировал бота или деактивирован, слать ему бесполезно
+	ErrBotBlocked = errors.New("telegram: bot blocked by user")
+	// ErrChatNotFound - чат не существует или бот в нем не состоит
+	ErrChatNotFound = errors.New("telegram: chat not found")
+	// ErrMessageNotModified - при редактировании текст и клавиатура не изменились, можно игнорировать
+	ErrMessageNotModified = errors.New("telegram: message is not modified")
+	// ErrFloodWait - превышен лимит запросов, нужно подождать RetryAfter
+	ErrFloodWait = errors.New("telegram: flood wait")
+)
+
+// APIError - классифицированная ошибка Telegram API
+type APIError struct {
+	Kind       error // один из ErrBotBlocked, ErrChatNotFound, ErrMessageNotModified, ErrFloodWait или nil
+	Code       int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap позволяет проверять класс через errors.Is(err, ErrBotBlocked) и исходную ошибку через errors.As
+func (e *APIError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// ClassifyError оборачивает ошибку tgbotapi в APIError с определенным классом.
+// Ошибки, не пришедшие от Telegram API (сеть, rate limiter), возвращаются как есть
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return err
+	}
+
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		var tgErrValue tgbotapi.Error
+		if !errors.As(err, &tgErrValue) {
+			return err
+		}
+		tgErr = &tgErrValue
+	}
+
+	result := &APIError{Code: tgErr.Code, Err: err}
+	msg := strings.ToLower(tgErr.Message)
+
+	switch {
+	case tgErr.Code == 429 || tgErr.RetryAfter > 0:
+		result.Kind = ErrFloodWait
+		result.RetryAfter = time.Duration(tgErr.RetryAfter) * time.Second
+	case strings.Contains(msg, "message is not modified"):
+		result.Kind = ErrMessageNotModified
+	case tgErr.Code == 403 && (strings.Contains(msg, "blocked") || strings.Contains(msg, "deactivated")):
+		result.Kind = ErrBotBlocked
+	case strings.Contains(msg, "chat not found"):
+		result.Kind = ErrChatNotFound
+	}
+
+	return result
+}
+
+// IsMessageNotModified проверяет что ошибка - "message is not modified"
+func IsMessageNotModified(err error) bool {
+	return errors.Is(ClassifyError(err), ErrMessageNotModified)
+}
+
+// IsUnreachable проверяет что пользователю нельзя доставить сообщение (заблокировал бота или чата нет)
+func IsUnreachable(err error) bool {
+	err = ClassifyError(err)
+	return errors.Is(err, ErrBotBlocked) || errors.Is(err, ErrChatNotFound)
+}
+
+// errorKind возвращает название класса ошибки для логов
+func errorKind(err error) string {
+	switch {
+	case errors.Is(err, ErrFloodWait):
+		return "flood_wait"
+	case errors.Is(err, ErrMessageNotModified):
+		return "not_modified"
+	case errors.Is(err, ErrBotBlocked):
+		return "blocked"
+	case errors.Is(err, ErrChatNotFound):
+		return "chat_not_found"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,46 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		kind      error
+		retryWait time.Duration
+	}{
+		{"blocked", &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}, ErrBotBlocked, 0},
+		{"deactivated", &tgbotapi.Error{Code: 403, Message: "Forbidden: user is deactivated"}, ErrBotBlocked, 0},
+		{"chat not found", &tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"}, ErrChatNotFound, 0},
+		{"not modified", &tgbotapi.Error{Code: 400, Message: "Bad Request: message is not modified: specified new message content and reply markup are exactly the same"}, ErrMessageNotModified, 0},
+		{"flood wait", &tgbotapi.Error{Code: 429, Message: "Too Many Requests: retry after 5", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 5}}, ErrFloodWait, 5 * time.Second},
+		{"wrapped", fmt.Errorf("отправка: %w", &tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"}), ErrChatNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ClassifyError(tt.err)
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("ClassifyError() = %v, want kind %v", err, tt.kind)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("ClassifyError() is not *APIError")
+			}
+			if apiErr.RetryAfter != tt.retryWait {
+				t.Errorf("RetryAfter = %v, want %v", apiErr.RetryAfter, tt.retryWait)
+			}
+		})
+	}
+
+	if err := errors.New("network"); ClassifyError(err) != err {
+		t.Errorf("non-API error must be returned as is")
+	}
+}
@@ -0,0 +1,85 @@
+package telegram
+
+import (
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// progressEditInterval - как часто обновлять сообщение с прогрессом: правки идут через ту же
+// очередь отправки, что и остальные сообщения, поэтому не чаще раза в несколько секунд
+const progressEditInterval = 3 * time.Second
+
+// Progress - сообщение с ходом долгой операции: "обработано N из M" и оценка оставшегося времени
+type Progress struct {
+	bot       Sender
+	chatID    int64
+	messageID int
+	title     string
+	total     int
+	interval  time.Duration
+	startedAt time.Time
+	editedAt  time.Time
+	now       func() time.Time
+}
+
+// StartProgress отправляет начальное сообщение прогресса операции title из total шагов
+func StartProgress(bot Sender, chatID int64, title string, total int) (*Progress, error) {
+	p := &Progress{
+		bot:      bot,
+		chatID:   chatID,
+		title:    title,
+		total:    total,
+		interval: progressEditInterval,
+		now:      time.Now,
+	}
+	p.startedAt = p.now()
+	p.editedAt = p.startedAt
+
+	msg, err := bot.Send(tgbotapi.NewMessage(chatID, p.text(0)))
+	if err != nil {
+		return nil, err
+	}
+	p.messageID = msg.MessageID
+	return p, nil
+}
+
+// Update сообщает, что обработано done шагов. Сообщение редактируется не чаще progressEditInterval
+func (p *Progress) Update(done int) {
+	now := p.now()
+	if now.Sub(p.editedAt) < p.interval {
+		return
+	}
+	p.editedAt = now
+
+	// Прогресс - вспомогательное сообщение: ошибка правки не должна прерывать операцию
+	_ = SafeEdit(p.bot, tgbotapi.NewEditMessageText(p.chatID, p.messageID, p.text(done)), "")
+}
+
+// Finish заменяет прогресс итоговым текстом
+func (p *Progress) Finish(text string) error {
+	return SafeEdit(p.bot, tgbotapi.NewEditMessageText(p.chatID, p.messageID, text), "")
+}
+
+func (p *Progress) text(done int) string {
+	text := fmt.Sprintf("⏳ %s\n\nОбработано: %d из %d", p.title, done, p.total)
+	if done > 0 && done < p.total {
+		elapsed := p.now().Sub(p.startedAt)
+		remaining := elapsed * time.Duration(p.total-done) / time.Duration(done)
+		text += "\nОсталось: " + formatETA(remaining)
+	}
+	return text
+}
+
+// formatETA округляет оставшееся время до понятного вида: "~40 с", "~3 мин"
+func formatETA(d time.Duration) string {
+	if d < time.Minute {
+		seconds := int(d.Round(time.Second) / time.Second)
+		if seconds < 1 {
+			seconds = 1
+		}
+		return fmt.Sprintf("~%d с", seconds)
+	}
+	return fmt.Sprintf("~%d мин", int(d.Round(time.Minute)/time.Minute))
+}
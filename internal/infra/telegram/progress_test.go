@@ -0,0 +1,76 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type recordingSender struct {
+	texts []string
+}
+
+func (s *recordingSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	switch msg := c.(type) {
+	case tgbotapi.MessageConfig:
+		s.texts = append(s.texts, msg.Text)
+	case tgbotapi.EditMessageTextConfig:
+		s.texts = append(s.texts, msg.Text)
+	}
+	return tgbotapi.Message{MessageID: 7}, nil
+}
+
+func (s *recordingSender) Request(tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func TestProgressThrottlesEdits(t *testing.T) {
+	bot := &recordingSender{}
+	p, err := StartProgress(bot, 1, "Рассылка", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := p.startedAt
+	p.now = func() time.Time { return now }
+
+	now = now.Add(time.Second)
+	p.Update(10) // слишком рано - не редактируем
+
+	now = now.Add(3 * time.Second)
+	p.Update(20) // 20 за 4 секунды - осталось 16 секунд
+
+	if err := p.Finish("Готово"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"⏳ Рассылка\n\nОбработано: 0 из 100",
+		"⏳ Рассылка\n\nОбработано: 20 из 100\nОсталось: ~16 с",
+		"Готово",
+	}
+	if len(bot.texts) != len(want) {
+		t.Fatalf("texts = %q, want %q", bot.texts, want)
+	}
+	for i := range want {
+		if bot.texts[i] != want[i] {
+			t.Errorf("texts[%d] = %q, want %q", i, bot.texts[i], want[i])
+		}
+	}
+}
+
+func TestFormatETA(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{200 * time.Millisecond, "~1 с"},
+		{42 * time.Second, "~42 с"},
+		{150 * time.Second, "~3 мин"},
+	}
+	for _, tt := range tests {
+		if got := formatETA(tt.d); got != tt.want {
+			t.Errorf("formatETA(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package telegram
+
+import (
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ChatGetter - получение информации о чате, реализует tgbotapi.BotAPI
+type ChatGetter interface {
+	GetChat(config tgbotapi.ChatInfoConfig) (tgbotapi.Chat, error)
+}
+
+// UserName возвращает @username сотрудника, а если его нет - имя или Telegram ID
+func UserName(bot ChatGetter, telegramID int64) string {
+	chat, err := bot.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: telegramID}})
+	if err != nil {
+		return strconv.FormatInt(telegramID, 10)
+	}
+	if chat.UserName != "" {
+		return "@" + chat.UserName
+	}
+	if name := strings.TrimSpace(chat.FirstName + " " + chat.LastName); name != "" {
+		return name
+	}
+	return strconv.FormatInt(telegramID, 10)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/rvinnie/yookassa-sdk-go/yookassa"
 	yoocommon "github.com/rvinnie/yookassa-sdk-go/yookassa/common"
 	yoopayment "github.com/rvinnie/yookassa-sdk-go/yookassa/payment"
+	yoorefund "github.com/rvinnie/yookassa-sdk-go/yookassa/refund"
 )
 
 // Client wraps the YooKassa SDK client
@@ -30,8 +31,8 @@ func NewClient(shopID, secretKey, returnURL string, logger *slog.Logger) (*Clien
 	}, nil
 }
 
-// CreatePayment creates a new payment in YooKassa
-func (c *Client) CreatePayment(ctx context.Context, amount float64, description string, metadata map[string]string) (*yoopayment.Payment, error) {
+// CreatePayment creates a new payment in YooKassa; receiptItem names the only item of the receipt
+func (c *Client) CreatePayment(ctx context.Context, amount float64, description, receiptItem string, metadata map[string]string) (*yoopayment.Payment, error) {
 	c.logger.Info("Creating payment in YooKassa", "amount", amount)
 
 	idempotenceKey := fmt.Sprintf("%s_%d", uuid.New().String(), time.Now().Unix())
@@ -54,7 +55,7 @@ func (c *Client) CreatePayment(ctx context.Context, amount float64, description
 			},
 			Items: []*yoocommon.Item{
 				{
-					Description: description,
+					Description: receiptItem,
 					Quantity:    "1",
 					Amount: &yoocommon.Amount{
 						Value:    fmt.Sprintf("%.2f", amount),
@@ -93,3 +94,46 @@ func (c *Client) GetPaymentStatus(ctx context.Context, paymentID string) (*yoopa
 	c.logger.Info("Payment status retrieved", "payment_id", paymentID, "status", result.Status)
 	return result, nil
 }
+
+// CancelPayment cancels a payment in YooKassa
+func (c *Client) CancelPayment(ctx context.Context, paymentID string) (*yoopayment.Payment, error) {
+	c.logger.Info("Cancelling payment in YooKassa", "payment_id", paymentID)
+
+	idempotenceKey := fmt.Sprintf("%s_%d", uuid.New().String(), time.Now().Unix())
+
+	paymentHandler := yookassa.NewPaymentHandler(c.client).WithIdempotencyKey(idempotenceKey)
+	result, err := paymentHandler.CancelPayment(paymentID)
+	if err != nil {
+		c.logger.Error("Failed to cancel payment in YooKassa", "error", err, "payment_id", paymentID)
+		return nil, fmt.Errorf("failed to cancel payment: %w", err)
+	}
+
+	c.logger.Info("Payment cancelled in YooKassa", "payment_id", paymentID, "status", result.Status)
+	return result, nil
+}
+
+// CreateRefund returns the full payment amount to the client
+func (c *Client) CreateRefund(ctx context.Context, paymentID string, amount float64, description string) (*yoorefund.Refund, error) {
+	c.logger.Info("Creating refund in YooKassa", "payment_id", paymentID, "amount", amount)
+
+	idempotenceKey := fmt.Sprintf("%s_%d", uuid.New().String(), time.Now().Unix())
+
+	refund := &yoorefund.Refund{
+		PaymentId: paymentID,
+		Amount: &yoocommon.Amount{
+			Value:    fmt.Sprintf("%.2f", amount),
+			Currency: "RUB",
+		},
+		Description: description,
+	}
+
+	refundHandler := yookassa.NewRefundHandler(c.client).WithIdempotencyKey(idempotenceKey)
+	result, err := refundHandler.CreateRefund(refund)
+	if err != nil {
+		c.logger.Error("Failed to create refund in YooKassa", "error", err, "payment_id", paymentID)
+		return nil, fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	c.logger.Info("Refund created in YooKassa", "payment_id", paymentID, "refund_id", result.Id, "status", result.Status)
+	return result, nil
+}
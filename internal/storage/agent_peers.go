@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/subs"
+)
+
+// agentPeerStatuses - статусы подписок, пиры которых агент включает или отключает.
+// Ожидающие оплаты и архивные подписки агенту не передаются
+var agentPeerStatuses = []string{
+	string(subs.StatusActive),
+	string(subs.StatusExpired),
+	string(subs.StatusDisabled),
+	string(subs.StatusPaused),
+	string(subs.StatusCancelled),
+}
+
+// SetServerAgentTokenHash сохраняет хеш нового токена агента сервера; старый токен перестает действовать
+func (s *ServersRepo) SetServerAgentTokenHash(ctx context.Context, serverID int64, tokenHash string) error {
+	q, args, err := s.stmpBuilder().
+		Update(serversTable).
+		Set("agent_token_hash", tokenHash).
+		Set("updated_at", s.now()).
+		Where(sq.Eq{"id": serverID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// GetServerByAgentTokenHash возвращает сервер по хешу токена агента; nil - токен неизвестен
+func (s *ServersRepo) GetServerByAgentTokenHash(ctx context.Context, tokenHash string) (*servers.Server, error) {
+	q, args, err := s.stmpBuilder().
+		Select(serverRowFields).
+		From(serversTable).
+		Where(sq.Eq{"agent_token_hash": tokenHash}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var row serverRow
+	if err := s.db.GetContext(ctx, &row, q, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+	return row.ToModel(), nil
+}
+
+// ListServerPeers возвращает подписки сервера с пиром WireGuard, состояние которых агент должен поддерживать
+func (s *SubscriptionsRepo) ListServerPeers(ctx context.Context, serverID int64) ([]*subs.Subscription, error) {
+	q, args, err := s.stmpBuilder().
+		Select(subscriptionRowFields).
+		From(subscriptionsTable).
+		Where(sq.Eq{"server_id": serverID}).
+		Where(sq.NotEq{"generated_user_id": nil}).
+		Where(sq.Eq{"status": agentPeerStatuses}).
+		OrderBy("id ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []subscriptionRow
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*subs.Subscription, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/bans"
+)
+
+const bansTable = "bans"
+
+var banRowFields = fields(banRow{})
+
+type banRow struct {
+	ID         int64     `db:"id"`
+	TelegramID int64     `db:"telegram_id"`
+	Reason     string    `db:"reason"`
+	BannedBy   *int64    `db:"banned_by"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func (r banRow) ToModel() *bans.Ban {
+	return &bans.Ban{
+		ID:         r.ID,
+		TelegramID: r.TelegramID,
+		Reason:     r.Reason,
+		BannedBy:   r.BannedBy,
+		CreatedAt:  r.CreatedAt,
+	}
+}
+
+// SaveBan блокирует пользователя; повторный бан обновляет причину и автора
+func (s *storageImpl) SaveBan(ctx context.Context, ban bans.Ban) (*bans.Ban, error) {
+	q, args, err := s.stmpBuilder().
+		Insert(bansTable).
+		Columns("telegram_id", "reason", "banned_by", "created_at").
+		Values(ban.TelegramID, ban.Reason, ban.BannedBy, s.now()).
+		Suffix(upsertSuffix("telegram_id", "reason", "banned_by", "created_at")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return nil, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return s.GetBan(ctx, ban.TelegramID)
+}
+
+// GetBan возвращает бан пользователя; nil - пользователь не заблокирован
+func (s *storageImpl) GetBan(ctx context.Context, telegramID int64) (*bans.Ban, error) {
+	q, args, err := s.stmpBuilder().
+		Select(banRowFields).
+		From(bansTable).
+		Where(sq.Eq{"telegram_id": telegramID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var row banRow
+	if err := s.db.GetContext(ctx, &row, q, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return row.ToModel(), nil
+}
+
+// ListBans возвращает все баны, новые первыми
+func (s *storageImpl) ListBans(ctx context.Context) ([]*bans.Ban, error) {
+	q, args, err := s.stmpBuilder().
+		Select(banRowFields).
+		From(bansTable).
+		OrderBy("created_at DESC", "id DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []banRow
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*bans.Ban, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+
+	return result, nil
+}
+
+// DeleteBan снимает бан; false - пользователь не был заблокирован
+func (s *storageImpl) DeleteBan(ctx context.Context, telegramID int64) (bool, error) {
+	q, args, err := s.stmpBuilder().
+		Delete(bansTable).
+		Where(sq.Eq{"telegram_id": telegramID}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/stories/bonusrules"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const bonusRulesTable = "bonus_rules"
+
+var bonusRuleRowFields = fields(bonusRuleRow{})
+
+type bonusRuleRow struct {
+	ID              int64     `db:"id"`
+	Name            string    `db:"name"`
+	Trigger         string    `db:"event"`
+	TariffID        *int64    `db:"tariff_id"`
+	MinAmount       *float64  `db:"min_amount"`
+	Channel         *string   `db:"channel"`
+	Action          string    `db:"action"`
+	BonusDays       int       `db:"bonus_days"`
+	DiscountPercent int       `db:"discount_percent"`
+	NotifyText      string    `db:"notify_text"`
+	IsActive        bool      `db:"is_active"`
+	CreatedAt       time.Time `db:"created_at"`
+}
+
+func (r bonusRuleRow) ToModel() bonusrules.Rule {
+	rule := bonusrules.Rule{
+		ID:              r.ID,
+		Name:            r.Name,
+		Trigger:         bonusrules.Trigger(r.Trigger),
+		TariffID:        r.TariffID,
+		MinAmount:       r.MinAmount,
+		Action:          bonusrules.Action(r.Action),
+		BonusDays:       r.BonusDays,
+		DiscountPercent: r.DiscountPercent,
+		NotifyText:      r.NotifyText,
+		IsActive:        r.IsActive,
+		CreatedAt:       r.CreatedAt,
+	}
+	if r.Channel != nil {
+		channel := bonusrules.Channel(*r.Channel)
+		rule.Channel = &channel
+	}
+	return rule
+}
+
+// ListBonusRules возвращает активные правила бонусов для события
+func (s *storageImpl) ListBonusRules(ctx context.Context, trigger bonusrules.Trigger) ([]bonusrules.Rule, error) {
+	q, args, err := s.stmpBuilder().
+		Select(bonusRuleRowFields).
+		From(bonusRulesTable).
+		Where(sq.Eq{"event": string(trigger), "is_active": true}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []bonusRuleRow
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	rules := make([]bonusrules.Rule, 0, len(rows))
+	for _, r := range rows {
+		rules = append(rules, r.ToModel())
+	}
+	return rules, nil
+}
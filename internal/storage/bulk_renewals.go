@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/bulkrenew"
+)
+
+const bulkRenewalsTable = "bulk_renewals"
+
+var bulkRenewalRowFields = fields(bulkRenewalRow{})
+
+type bulkRenewalRow struct {
+	ID                  int64      `db:"id"`
+	PaymentID           int64      `db:"payment_id"`
+	AssistantTelegramID int64      `db:"assistant_telegram_id"`
+	ChatID              int64      `db:"chat_id"`
+	MessageID           *int       `db:"message_id"`
+	TotalAmount         float64    `db:"total_amount"`
+	Status              string     `db:"status"`
+	CompletedAt         *time.Time `db:"completed_at"`
+	CreatedAt           time.Time  `db:"created_at"`
+	UpdatedAt           time.Time  `db:"updated_at"`
+}
+
+func (r bulkRenewalRow) ToModel() *bulkrenew.BulkRenewal {
+	return &bulkrenew.BulkRenewal{
+		ID:                  r.ID,
+		PaymentID:           r.PaymentID,
+		AssistantTelegramID: r.AssistantTelegramID,
+		ChatID:              r.ChatID,
+		MessageID:           r.MessageID,
+		TotalAmount:         r.TotalAmount,
+		Status:              bulkrenew.Status(r.Status),
+		CompletedAt:         r.CompletedAt,
+		CreatedAt:           r.CreatedAt,
+		UpdatedAt:           r.UpdatedAt,
+	}
+}
+
+// CreateBulkRenewal сохраняет массовое продление, ожидающее оплаты
+func (s *storageImpl) CreateBulkRenewal(ctx context.Context, renewal bulkrenew.BulkRenewal) (*bulkrenew.BulkRenewal, error) {
+	now := s.now()
+
+	params := map[string]interface{}{
+		"payment_id":            renewal.PaymentID,
+		"assistant_telegram_id": renewal.AssistantTelegramID,
+		"chat_id":               renewal.ChatID,
+		"message_id":            renewal.MessageID,
+		"total_amount":          renewal.TotalAmount,
+		"status":                string(bulkrenew.StatusPending),
+		"created_at":            now,
+		"updated_at":            now,
+	}
+
+	q, args, err := s.stmpBuilder().
+		Insert(bulkRenewalsTable).
+		SetMap(params).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("result.LastInsertId: %w", err)
+	}
+
+	return s.GetBulkRenewal(ctx, id)
+}
+
+func (s *storageImpl) GetBulkRenewal(ctx context.Context, id int64) (*bulkrenew.BulkRenewal, error) {
+	q, args, err := s.stmpBuilder().
+		Select(bulkRenewalRowFields).
+		From(bulkRenewalsTable).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var row bulkRenewalRow
+	if err := s.db.GetContext(ctx, &row, q, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return row.ToModel(), nil
+}
+
+// ListPendingBulkRenewals возвращает массовые продления, которые еще ждут оплаты
+func (s *storageImpl) ListPendingBulkRenewals(ctx context.Context) ([]*bulkrenew.BulkRenewal, error) {
+	q, args, err := s.stmpBuilder().
+		Select(bulkRenewalRowFields).
+		From(bulkRenewalsTable).
+		Where(sq.Eq{"status": string(bulkrenew.StatusPending)}).
+		OrderBy("id ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []bulkRenewalRow
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*bulkrenew.BulkRenewal, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+
+	return result, nil
+}
+
+// ClaimBulkRenewal атомарно переводит ожидающее продление в status.
+// false - продление уже обработано кем-то другим
+func (s *storageImpl) ClaimBulkRenewal(ctx context.Context, id int64, status bulkrenew.Status) (bool, error) {
+	now := s.now()
+	query := s.stmpBuilder().
+		Update(bulkRenewalsTable).
+		Set("status", string(status)).
+		Set("updated_at", now).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Eq{"status": string(bulkrenew.StatusPending)})
+
+	if status == bulkrenew.StatusCompleted {
+		query = query.Set("completed_at", now)
+	}
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const clientBalancesTable = "client_balances"
+
+// GetClientBalance returns the client's balance, 0 if nothing was credited
+func (s *storageImpl) GetClientBalance(ctx context.Context, clientWhatsApp string) (float64, error) {
+	q, args, err := s.stmpBuilder().
+		Select("amount").
+		From(clientBalancesTable).
+		Where(sq.Eq{"client_whatsapp": NormalizePhone(clientWhatsApp)}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var amount float64
+	err = s.db.GetContext(ctx, &amount, q, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return amount, nil
+}
+
+// AddClientBalance зачисляет сумму на баланс клиента и возвращает новый баланс
+func (s *storageImpl) AddClientBalance(ctx context.Context, clientWhatsApp string, amount float64) (float64, error) {
+	now := s.now()
+	q, args, err := s.stmpBuilder().
+		Insert(clientBalancesTable).
+		Columns("client_whatsapp", "amount", "created_at", "updated_at").
+		Values(NormalizePhone(clientWhatsApp), amount, now, now).
+		Suffix("ON CONFLICT(client_whatsapp) DO UPDATE SET amount = amount + excluded.amount, updated_at = excluded.updated_at").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return 0, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return s.GetClientBalance(ctx, clientWhatsApp)
+}
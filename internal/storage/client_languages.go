@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/clientlang"
+)
+
+const clientLanguagesTable = "client_languages"
+
+// GetClientLanguage returns the language chosen for the client, Default if none was set
+func (s *storageImpl) GetClientLanguage(ctx context.Context, clientWhatsApp string) (clientlang.Language, error) {
+	q, args, err := s.stmpBuilder().
+		Select("language").
+		From(clientLanguagesTable).
+		Where(sq.Eq{"client_whatsapp": NormalizePhone(clientWhatsApp)}).
+		ToSql()
+	if err != nil {
+		return clientlang.Default, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var lang string
+	err = s.db.GetContext(ctx, &lang, q, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return clientlang.Default, nil
+		}
+		return clientlang.Default, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return clientlang.Language(lang), nil
+}
+
+// SetClientLanguage saves the language the assistant uses with the client
+func (s *storageImpl) SetClientLanguage(ctx context.Context, clientWhatsApp string, lang clientlang.Language) error {
+	now := s.now()
+	q, args, err := s.stmpBuilder().
+		Insert(clientLanguagesTable).
+		Columns("client_whatsapp", "language", "created_at", "updated_at").
+		Values(NormalizePhone(clientWhatsApp), string(lang), now, now).
+		Suffix(upsertSuffix("client_whatsapp", "language", "updated_at")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/subs"
+)
+
+// ClientSummary - клиент (номер WhatsApp) с его последней подпиской из выборки
+type ClientSummary struct {
+	LastSubscription   *subs.Subscription
+	SubscriptionsCount int
+	ActiveCount        int
+	ServerName         *string
+}
+
+type clientSummaryRow struct {
+	subscriptionRow
+	SubscriptionsCount int     `db:"subscriptions_count"`
+	ActiveCount        int     `db:"active_count"`
+	ServerName         *string `db:"server_name"`
+}
+
+// ListClientsPage возвращает страницу клиентов: подписки, подходящие под фильтр, сгруппированы по client_whatsapp.
+// Для каждого клиента берется его последняя подписка; сортировка по ее ID по убыванию (новые клиенты первыми)
+func (s *storageImpl) ListClientsPage(ctx context.Context, criteria subs.ClientPageCriteria) ([]ClientSummary, error) {
+	groups := sq.Select(
+		"client_whatsapp",
+		"MAX(id) AS last_id",
+		"COUNT(*) AS subscriptions_count",
+		"SUM(CASE WHEN status = 'active' THEN 1 ELSE 0 END) AS active_count").
+		From(subscriptionsTable).
+		Where(sq.NotEq{"client_whatsapp": nil}).
+		Where(sq.NotEq{"client_whatsapp": ""}).
+		GroupBy("client_whatsapp")
+
+	groups = whereEq(groups, "created_by_telegram_id", criteria.CreatedByTelegramID)
+	groups = whereIn(groups, "status", criteria.Status)
+	groups = whereEq(groups, "server_id", criteria.ServerID)
+	groups = whereFrom(groups, "expires_at", criteria.ExpiresAfter)
+	groups = whereBefore(groups, "expires_at", criteria.ExpiresBefore)
+
+	groupsSQL, groupsArgs, err := groups.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	query := s.stmpBuilder().
+		Select(prefixWithTable("s", subscriptionRowFields),
+			"g.subscriptions_count",
+			"g.active_count",
+			"srv.name AS server_name").
+		From(subscriptionsTable+" s").
+		Join("("+groupsSQL+") g ON g.last_id = s.id", groupsArgs...).
+		LeftJoin(serversTable + " srv ON srv.id = s.server_id")
+
+	query = whereBefore(query, "s.id", criteria.BeforeSubscriptionID)
+	query = paginate(query, criteria.Limit, 0)
+
+	q, args, err := query.OrderBy("s.id DESC").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []clientSummaryRow
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]ClientSummary, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, ClientSummary{
+			LastSubscription:   row.subscriptionRow.ToModel(),
+			SubscriptionsCount: row.SubscriptionsCount,
+			ActiveCount:        row.ActiveCount,
+			ServerName:         row.ServerName,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// CohortRetentionMonths - горизонты удержания в месяцах
+var CohortRetentionMonths = []int{1, 3, 6}
+
+// CohortPurchase represents a single approved purchase of a client
+type CohortPurchase struct {
+	ClientWhatsApp      string
+	AssistantTelegramID *int64
+	PaidAt              time.Time
+	ExpiresAt           *time.Time
+}
+
+// CohortRow represents retention of clients who made their first purchase in one month
+type CohortRow struct {
+	Month               time.Time
+	AssistantTelegramID *int64 // nil - общая когорта по всем ассистентам
+	Clients             int
+	// Retained и Eligible индексируются так же, как CohortRetentionMonths.
+	// Eligible - сколько клиентов когорты уже прожили N месяцев с первой покупки
+	Retained []int
+	Eligible []int
+}
+
+// RetentionRate returns retention percentage for the i-th horizon, ok=false if cohort is too young
+func (r CohortRow) RetentionRate(i int) (float64, bool) {
+	if r.Eligible[i] == 0 {
+		return 0, false
+	}
+	return float64(r.Retained[i]) / float64(r.Eligible[i]) * 100, true
+}
+
+// CohortReport contains overall and per-assistant cohorts
+type CohortReport struct {
+	Overall     []CohortRow
+	ByAssistant []CohortRow
+}
+
+// ListApprovedPurchases returns all approved purchases ordered by payment time
+func (s *storageImpl) ListApprovedPurchases(ctx context.Context) ([]CohortPurchase, error) {
+	query := `
+		SELECT s.client_whatsapp, s.created_by_telegram_id, p.created_at, s.expires_at
+		FROM subscriptions s
+		JOIN payment_subscriptions ps ON s.id = ps.subscription_id
+		JOIN payments p ON ps.payment_id = p.id
+		WHERE p.status = 'approved'
+		  AND s.client_whatsapp IS NOT NULL
+		ORDER BY p.created_at ASC, p.id ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("db.QueryContext: %w", err)
+	}
+	defer rows.Close()
+
+	var result []CohortPurchase
+	for rows.Next() {
+		var p CohortPurchase
+		if err := rows.Scan(&p.ClientWhatsApp, &p.AssistantTelegramID, &p.PaidAt, &p.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		result = append(result, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetCohortReport returns retention by month of first purchase, overall and per assistant
+func (s *storageImpl) GetCohortReport(ctx context.Context) (*CohortReport, error) {
+	purchases, err := s.ListApprovedPurchases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list approved purchases: %w", err)
+	}
+
+	return BuildCohortReport(purchases, s.now()), nil
+}
+
+// BuildCohortReport groups purchases into monthly cohorts.
+// Клиент относится к когорте месяца первой оплаты и к ассистенту, оформившему эту оплату.
+// Клиент считается удержанным на горизонте N месяцев, если его подписка
+// действует на дату "первая оплата + N месяцев".
+func BuildCohortReport(purchases []CohortPurchase, now time.Time) *CohortReport {
+	type clientInfo struct {
+		firstPaidAt time.Time
+		assistantID *int64
+		maxExpires  time.Time
+	}
+
+	clients := make(map[string]*clientInfo)
+	for _, p := range purchases {
+		info, ok := clients[p.ClientWhatsApp]
+		if !ok || p.PaidAt.Before(info.firstPaidAt) {
+			if !ok {
+				info = &clientInfo{}
+				clients[p.ClientWhatsApp] = info
+			}
+			info.firstPaidAt = p.PaidAt
+			info.assistantID = p.AssistantTelegramID
+		}
+		if p.ExpiresAt != nil && p.ExpiresAt.After(info.maxExpires) {
+			info.maxExpires = *p.ExpiresAt
+		}
+	}
+
+	type cohortKey struct {
+		month       time.Time
+		assistantID int64
+		hasAssist   bool
+	}
+
+	rows := make(map[cohortKey]*CohortRow)
+	add := func(key cohortKey, info *clientInfo) {
+		row, ok := rows[key]
+		if !ok {
+			row = &CohortRow{
+				Month:    key.month,
+				Retained: make([]int, len(CohortRetentionMonths)),
+				Eligible: make([]int, len(CohortRetentionMonths)),
+			}
+			if key.hasAssist {
+				id := key.assistantID
+				row.AssistantTelegramID = &id
+			}
+			rows[key] = row
+		}
+		row.Clients++
+		for i, months := range CohortRetentionMonths {
+			checkpoint := info.firstPaidAt.AddDate(0, months, 0)
+			if checkpoint.After(now) {
+				continue
+			}
+			row.Eligible[i]++
+			if !info.maxExpires.Before(checkpoint) {
+				row.Retained[i]++
+			}
+		}
+	}
+
+	for _, info := range clients {
+		first := info.firstPaidAt.UTC()
+		month := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.UTC)
+		add(cohortKey{month: month}, info)
+		if info.assistantID != nil {
+			add(cohortKey{month: month, assistantID: *info.assistantID, hasAssist: true}, info)
+		}
+	}
+
+	report := &CohortReport{}
+	for _, row := range rows {
+		if row.AssistantTelegramID == nil {
+			report.Overall = append(report.Overall, *row)
+		} else {
+			report.ByAssistant = append(report.ByAssistant, *row)
+		}
+	}
+
+	sort.Slice(report.Overall, func(i, j int) bool {
+		return report.Overall[i].Month.Before(report.Overall[j].Month)
+	})
+	sort.Slice(report.ByAssistant, func(i, j int) bool {
+		a, b := report.ByAssistant[i], report.ByAssistant[j]
+		if *a.AssistantTelegramID != *b.AssistantTelegramID {
+			return *a.AssistantTelegramID < *b.AssistantTelegramID
+		}
+		return a.Month.Before(b.Month)
+	})
+
+	return report
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildCohortReport(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+	}
+	ptr := func(t time.Time) *time.Time { return &t }
+	assistant := int64(42)
+
+	purchases := []CohortPurchase{
+		// Клиент продлевался полгода
+		{ClientWhatsApp: "a", AssistantTelegramID: &assistant, PaidAt: date(2025, 1, 10), ExpiresAt: ptr(date(2025, 8, 1))},
+		// Клиент вернулся через месяц и ушел
+		{ClientWhatsApp: "b", AssistantTelegramID: &assistant, PaidAt: date(2025, 1, 20), ExpiresAt: ptr(date(2025, 2, 19))},
+		// Повторная покупка не меняет когорту и ассистента клиента
+		{ClientWhatsApp: "b", PaidAt: date(2025, 3, 1), ExpiresAt: ptr(date(2025, 3, 31))},
+		// Молодая когорта - горизонт 3 месяца еще не наступил
+		{ClientWhatsApp: "c", PaidAt: date(2025, 5, 5), ExpiresAt: ptr(date(2025, 6, 5))},
+	}
+
+	report := BuildCohortReport(purchases, date(2025, 7, 1))
+
+	if len(report.Overall) != 2 {
+		t.Fatalf("overall cohorts = %d, want 2", len(report.Overall))
+	}
+
+	jan := report.Overall[0]
+	if jan.Clients != 2 {
+		t.Errorf("jan clients = %d, want 2", jan.Clients)
+	}
+	if jan.Retained[0] != 2 || jan.Eligible[0] != 2 {
+		t.Errorf("jan 1m = %d/%d, want 2/2", jan.Retained[0], jan.Eligible[0])
+	}
+	if jan.Retained[1] != 1 || jan.Eligible[1] != 2 {
+		t.Errorf("jan 3m = %d/%d, want 1/2", jan.Retained[1], jan.Eligible[1])
+	}
+	if jan.Eligible[2] != 0 {
+		t.Errorf("jan 6m eligible = %d, want 0", jan.Eligible[2])
+	}
+
+	may := report.Overall[1]
+	if may.Retained[0] != 1 || may.Eligible[0] != 1 || may.Eligible[1] != 0 {
+		t.Errorf("may = %+v, want 1/1 at 1m and no 3m", may)
+	}
+
+	if len(report.ByAssistant) != 1 || report.ByAssistant[0].Clients != 2 {
+		t.Errorf("by assistant = %+v, want one cohort with 2 clients", report.ByAssistant)
+	}
+}
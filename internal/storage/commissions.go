@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/commissions"
+)
+
+const commissionSettlementsTable = "commission_settlements"
+
+var settlementRowFields = fields(settlementRow{})
+
+type settlementRow struct {
+	ID                  int64     `db:"id"`
+	AssistantTelegramID int64     `db:"assistant_telegram_id"`
+	PeriodFrom          time.Time `db:"period_from"`
+	PeriodTo            time.Time `db:"period_to"`
+	Amount              float64   `db:"amount"`
+	CreatedByTelegramID int64     `db:"created_by_telegram_id"`
+	CreatedAt           time.Time `db:"created_at"`
+}
+
+func (r settlementRow) ToModel() *commissions.Settlement {
+	return &commissions.Settlement{
+		ID:                  r.ID,
+		AssistantTelegramID: r.AssistantTelegramID,
+		PeriodFrom:          r.PeriodFrom,
+		PeriodTo:            r.PeriodTo,
+		Amount:              r.Amount,
+		CreatedByTelegramID: r.CreatedByTelegramID,
+		CreatedAt:           r.CreatedAt,
+	}
+}
+
+// AssistantSales - оплаченные создания и продления подписок ассистента за период.
+// Платеж за несколько подписок - несколько продаж, сумма делится между ними поровну
+type AssistantSales struct {
+	AssistantTelegramID int64   `db:"assistant_telegram_id"`
+	Revenue             float64 `db:"revenue"`
+	SalesCount          int     `db:"sales_count"`
+}
+
+// GetAssistantSales возвращает продажи каждого ассистента за период [from, to)
+func (s *storageImpl) GetAssistantSales(ctx context.Context, from, to time.Time) ([]AssistantSales, error) {
+	query := s.revenueQuery(from, to,
+		"s.created_by_telegram_id AS assistant_telegram_id",
+		"COALESCE(SUM("+revenueShare+"), 0) AS revenue",
+		"COUNT(*) AS sales_count",
+	).
+		Where(sq.NotEq{"s.created_by_telegram_id": nil}).
+		GroupBy("s.created_by_telegram_id").
+		OrderBy("revenue DESC")
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var sales []AssistantSales
+	if err := s.db.SelectContext(ctx, &sales, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+	return sales, nil
+}
+
+// CreateCommissionSettlement записывает выплату комиссии ассистенту
+func (s *storageImpl) CreateCommissionSettlement(ctx context.Context, settlement commissions.Settlement) (*commissions.Settlement, error) {
+	now := s.now()
+	q, args, err := s.stmpBuilder().
+		Insert(commissionSettlementsTable).
+		Columns("assistant_telegram_id", "period_from", "period_to", "amount", "created_by_telegram_id", "created_at").
+		Values(settlement.AssistantTelegramID, settlement.PeriodFrom, settlement.PeriodTo, settlement.Amount, settlement.CreatedByTelegramID, now).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db.ExecContext: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("result.LastInsertId: %w", err)
+	}
+
+	settlement.ID = id
+	settlement.CreatedAt = now
+	return &settlement, nil
+}
+
+// GetLastCommissionSettlement возвращает последнюю выплату ассистенту; nil - выплат не было
+func (s *storageImpl) GetLastCommissionSettlement(ctx context.Context, assistantTelegramID int64) (*commissions.Settlement, error) {
+	q, args, err := s.stmpBuilder().
+		Select(settlementRowFields).
+		From(commissionSettlementsTable).
+		Where(sq.Eq{"assistant_telegram_id": assistantTelegramID}).
+		OrderBy("period_to DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var row settlementRow
+	if err := s.db.GetContext(ctx, &row, q, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+	return row.ToModel(), nil
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/stories/dbstats"
+)
+
+// GetDBStats собирает размеры файла базы и количество строк в таблицах
+func (s *storageImpl) GetDBStats(ctx context.Context) (*dbstats.Stats, error) {
+	var stats dbstats.Stats
+
+	var pageSize, pageCount, freePages int64
+	if err := s.db.GetContext(ctx, &pageSize, "PRAGMA page_size"); err != nil {
+		return nil, fmt.Errorf("pragma page_size: %w", err)
+	}
+	if err := s.db.GetContext(ctx, &pageCount, "PRAGMA page_count"); err != nil {
+		return nil, fmt.Errorf("pragma page_count: %w", err)
+	}
+	if err := s.db.GetContext(ctx, &freePages, "PRAGMA freelist_count"); err != nil {
+		return nil, fmt.Errorf("pragma freelist_count: %w", err)
+	}
+	stats.FileSize = pageSize * pageCount
+	stats.FreeSize = pageSize * freePages
+
+	if err := s.db.GetContext(ctx, &stats.JournalMode, "PRAGMA journal_mode"); err != nil {
+		return nil, fmt.Errorf("pragma journal_mode: %w", err)
+	}
+
+	var databases []struct {
+		Seq  int64  `db:"seq"`
+		Name string `db:"name"`
+		File string `db:"file"`
+	}
+	if err := s.db.SelectContext(ctx, &databases, "PRAGMA database_list"); err != nil {
+		return nil, fmt.Errorf("pragma database_list: %w", err)
+	}
+	for _, d := range databases {
+		if d.Name == "main" {
+			stats.Path = d.File
+		}
+	}
+	// Пустой путь - база в памяти, WAL файла у нее нет
+	if stats.Path != "" {
+		if info, err := os.Stat(stats.Path + "-wal"); err == nil {
+			stats.WALSize = info.Size()
+		}
+	}
+
+	var tables []string
+	err := s.db.SelectContext(ctx, &tables,
+		"SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+	for _, table := range tables {
+		var rows int64
+		q := fmt.Sprintf(`SELECT COUNT(*) FROM "%s"`, strings.ReplaceAll(table, `"`, `""`))
+		if err := s.db.GetContext(ctx, &rows, q); err != nil {
+			return nil, fmt.Errorf("count %s: %w", table, err)
+		}
+		stats.Tables = append(stats.Tables, dbstats.TableRows{Name: table, Rows: rows})
+	}
+
+	var oldest time.Time
+	err = s.db.GetContext(ctx, &oldest,
+		"SELECT created_at FROM pending_orders WHERE status = 'pending' ORDER BY created_at, id LIMIT 1")
+	switch {
+	case err == nil:
+		stats.OldestPendingOrder = &oldest
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("oldest pending order: %w", err)
+	}
+
+	err = s.db.SelectContext(ctx, &stats.UserIDRenames,
+		"SELECT subscription_id, old_user_id, new_user_id FROM generated_user_id_renames ORDER BY subscription_id")
+	if err != nil {
+		return nil, fmt.Errorf("generated user id renames: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// VacuumDB пересобирает файл базы и возвращает свободные страницы системе. Блокирует запись на время работы
+func (s *storageImpl) VacuumDB(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("vacuum: %w", err)
+	}
+	return nil
+}
+
+// AnalyzeDB обновляет статистику индексов для планировщика запросов
+func (s *storageImpl) AnalyzeDB(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "ANALYZE"); err != nil {
+		return fmt.Errorf("analyze: %w", err)
+	}
+	return nil
+}
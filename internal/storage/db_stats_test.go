@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"kurut-bot/internal/stories/dbstats"
+)
+
+func TestUniqueGeneratedUserIDMigration(t *testing.T) {
+	db := newTestDBBefore(t, "047")
+
+	_, err := db.Exec(`INSERT INTO subscriptions (id, user_id, tariff_id, status, generated_user_id) VALUES
+		(1, 1, 1, 'active', '1_881_3456'),
+		(2, 1, 1, 'active', '1_881_3456'),
+		(3, 1, 1, 'expired', '1_881_3456'),
+		(4, 1, 1, 'active', '4_881_1111'),
+		(5, 1, 1, 'active', NULL),
+		(6, 1, 1, 'active', NULL)`)
+	if err != nil {
+		t.Fatalf("insert subscriptions: %v", err)
+	}
+
+	applyMigration(t, db, "../../migrations/047_unique_generated_user_id.sql")
+
+	var userIDs []string
+	if err := db.Select(&userIDs, `SELECT generated_user_id FROM subscriptions WHERE generated_user_id IS NOT NULL ORDER BY id`); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1_881_3456", "1_881_3456_d2", "1_881_3456_d3", "4_881_1111"}
+	if !slices.Equal(userIDs, want) {
+		t.Errorf("generated_user_id = %v, want %v", userIDs, want)
+	}
+
+	stats, err := New(db).GetDBStats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRenames := []dbstats.UserIDRename{
+		{SubscriptionID: 2, OldUserID: "1_881_3456", NewUserID: "1_881_3456_d2"},
+		{SubscriptionID: 3, OldUserID: "1_881_3456", NewUserID: "1_881_3456_d3"},
+	}
+	if !slices.Equal(stats.UserIDRenames, wantRenames) {
+		t.Errorf("renames = %+v, want %+v", stats.UserIDRenames, wantRenames)
+	}
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/stories/drip"
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/subs"
+)
+
+const (
+	dripMessagesTable = "drip_messages"
+	dripOptOutsTable  = "drip_optouts"
+)
+
+// ListDripCandidates returns trial subscriptions created since createdAfter whose clients
+// did not opt out of the drip and did not move to a paid tariff afterwards
+func (s *storageImpl) ListDripCandidates(ctx context.Context, createdAfter time.Time) ([]*subs.Subscription, error) {
+	query := `
+		SELECT ` + prefixWithTable("s", subscriptionRowFields) + `
+		FROM ` + subscriptionsTable + ` s
+		JOIN ` + tariffsTable + ` t ON t.id = s.tariff_id
+		WHERE t.price = 0
+		AND s.client_whatsapp IS NOT NULL AND s.client_whatsapp != ''
+		AND s.status != ?
+		AND s.created_at >= ?
+		AND NOT EXISTS (
+			SELECT 1 FROM ` + dripOptOutsTable + ` o
+			WHERE o.client_whatsapp = REPLACE(REPLACE(REPLACE(s.client_whatsapp, '+', ''), ' ', ''), '-', '')
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM ` + subscriptionsTable + ` s2
+			JOIN ` + tariffsTable + ` t2 ON t2.id = s2.tariff_id
+			WHERE t2.price > 0
+			AND s2.client_whatsapp = s.client_whatsapp
+			AND s2.created_at >= s.created_at
+		)
+		ORDER BY s.created_at ASC
+	`
+
+	var rows []subscriptionRow
+	err := s.db.SelectContext(ctx, &rows, query, string(subs.StatusCancelled), createdAfter)
+	if err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*subs.Subscription, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+	return result, nil
+}
+
+// ListSentDripSteps returns already sent drip steps grouped by subscription ID
+func (s *storageImpl) ListSentDripSteps(ctx context.Context, subscriptionIDs []int64) (map[int64]map[drip.Step]bool, error) {
+	result := make(map[int64]map[drip.Step]bool, len(subscriptionIDs))
+	if len(subscriptionIDs) == 0 {
+		return result, nil
+	}
+
+	query := s.stmpBuilder().
+		Select("subscription_id", "step").
+		From(dripMessagesTable)
+	q, args, err := whereIn(query, "subscription_id", subscriptionIDs).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []struct {
+		SubscriptionID int64  `db:"subscription_id"`
+		Step           string `db:"step"`
+	}
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	for _, row := range rows {
+		if result[row.SubscriptionID] == nil {
+			result[row.SubscriptionID] = make(map[drip.Step]bool)
+		}
+		result[row.SubscriptionID][drip.Step(row.Step)] = true
+	}
+	return result, nil
+}
+
+// CreateDripMessage records a sent drip step; a repeated step for the subscription is ignored
+func (s *storageImpl) CreateDripMessage(ctx context.Context, msg drip.Message) error {
+	q, args, err := s.stmpBuilder().
+		Insert(dripMessagesTable).
+		Columns("subscription_id", "client_whatsapp", "step", "assistant_telegram_id", "sent_at").
+		Values(msg.SubscriptionID, NormalizePhone(msg.ClientWhatsApp), string(msg.Step), msg.AssistantTelegramID, s.now()).
+		Suffix("ON CONFLICT(subscription_id, step) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// SetDripOptOut stops the trial drip for the client
+func (s *storageImpl) SetDripOptOut(ctx context.Context, clientWhatsApp string, createdByTelegramID int64) error {
+	q, args, err := s.stmpBuilder().
+		Insert(dripOptOutsTable).
+		Columns("client_whatsapp", "created_by_telegram_id", "created_at").
+		Values(NormalizePhone(clientWhatsApp), createdByTelegramID, s.now()).
+		Suffix("ON CONFLICT(client_whatsapp) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// GetDripStats returns how many messages of each drip step were sent and how many clients paid for a subscription afterwards
+func (s *storageImpl) GetDripStats(ctx context.Context) ([]drip.StepStats, error) {
+	query := `
+		SELECT m.step AS step,
+			COUNT(*) AS sent,
+			COALESCE(SUM(CASE WHEN EXISTS (
+				SELECT 1 FROM ` + subscriptionsTable + ` s
+				JOIN ` + tariffsTable + ` t ON t.id = s.tariff_id
+				JOIN ` + paymentSubscriptionsTable + ` ps ON ps.subscription_id = s.id
+				JOIN ` + paymentsTable + ` p ON p.id = ps.payment_id
+				WHERE t.price > 0
+				AND p.status = ?
+				AND p.created_at >= m.sent_at
+				AND REPLACE(REPLACE(REPLACE(s.client_whatsapp, '+', ''), ' ', ''), '-', '') = m.client_whatsapp
+			) THEN 1 ELSE 0 END), 0) AS converted
+		FROM ` + dripMessagesTable + ` m
+		GROUP BY m.step
+	`
+
+	var rows []drip.StepStats
+	if err := s.db.SelectContext(ctx, &rows, query, string(payment.StatusApproved)); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	// Все этапы в порядке отправки, даже без сообщений
+	byStep := make(map[drip.Step]drip.StepStats, len(rows))
+	for _, row := range rows {
+		byStep[row.Step] = row
+	}
+	result := make([]drip.StepStats, 0, len(drip.Steps))
+	for _, step := range drip.Steps {
+		stats := byStep[step]
+		stats.Step = step
+		result = append(result, stats)
+	}
+	return result, nil
+}
+
+// CountDripOptOuts returns how many clients opted out of the trial drip
+func (s *storageImpl) CountDripOptOuts(ctx context.Context) (int, error) {
+	return s.count(ctx, s.stmpBuilder().Select("client_whatsapp").From(dripOptOutsTable))
+}
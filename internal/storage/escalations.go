@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/escalations"
+)
+
+const escalationsTable = "escalations"
+
+var escalationRowFields = fields(escalationRow{})
+
+type escalationRow struct {
+	ID                  int64      `db:"id"`
+	SubjectType         string     `db:"subject_type"`
+	SubjectID           int64      `db:"subject_id"`
+	CreatedByTelegramID int64      `db:"created_by_telegram_id"`
+	AssigneeTelegramID  *int64     `db:"assignee_telegram_id"`
+	Status              string     `db:"status"`
+	CreatedAt           time.Time  `db:"created_at"`
+	TakenAt             *time.Time `db:"taken_at"`
+	ResolvedAt          *time.Time `db:"resolved_at"`
+}
+
+func (r escalationRow) ToModel() *escalations.Escalation {
+	return &escalations.Escalation{
+		ID:                  r.ID,
+		SubjectType:         escalations.SubjectType(r.SubjectType),
+		SubjectID:           r.SubjectID,
+		CreatedByTelegramID: r.CreatedByTelegramID,
+		AssigneeTelegramID:  r.AssigneeTelegramID,
+		Status:              escalations.Status(r.Status),
+		CreatedAt:           r.CreatedAt,
+		TakenAt:             r.TakenAt,
+		ResolvedAt:          r.ResolvedAt,
+	}
+}
+
+// CreateEscalation сохраняет новую открытую эскалацию
+func (s *storageImpl) CreateEscalation(ctx context.Context, subjectType escalations.SubjectType, subjectID, createdByTelegramID int64) (*escalations.Escalation, error) {
+	now := s.now()
+	q, args, err := s.stmpBuilder().
+		Insert(escalationsTable).
+		Columns("subject_type", "subject_id", "created_by_telegram_id", "status", "created_at").
+		Values(string(subjectType), subjectID, createdByTelegramID, string(escalations.StatusOpen), now).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db.ExecContext: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("result.LastInsertId: %w", err)
+	}
+
+	return &escalations.Escalation{
+		ID:                  id,
+		SubjectType:         subjectType,
+		SubjectID:           subjectID,
+		CreatedByTelegramID: createdByTelegramID,
+		Status:              escalations.StatusOpen,
+		CreatedAt:           now,
+	}, nil
+}
+
+// GetEscalation возвращает эскалацию по ID; nil - не найдена
+func (s *storageImpl) GetEscalation(ctx context.Context, id int64) (*escalations.Escalation, error) {
+	return s.getEscalation(ctx, sq.Eq{"id": id})
+}
+
+// GetOpenEscalation возвращает нерешенную эскалацию по подписке или заказу; nil - такой нет
+func (s *storageImpl) GetOpenEscalation(ctx context.Context, subjectType escalations.SubjectType, subjectID int64) (*escalations.Escalation, error) {
+	return s.getEscalation(ctx, sq.And{
+		sq.Eq{"subject_type": string(subjectType), "subject_id": subjectID},
+		sq.NotEq{"status": string(escalations.StatusResolved)},
+	})
+}
+
+func (s *storageImpl) getEscalation(ctx context.Context, where sq.Sqlizer) (*escalations.Escalation, error) {
+	q, args, err := s.stmpBuilder().
+		Select(escalationRowFields).
+		From(escalationsTable).
+		Where(where).
+		OrderBy("id DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var row escalationRow
+	if err := s.db.GetContext(ctx, &row, q, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+	return row.ToModel(), nil
+}
+
+// TakeEscalation назначает открытую эскалацию админу; false - ее уже взяли или решили
+func (s *storageImpl) TakeEscalation(ctx context.Context, id, assigneeTelegramID int64) (bool, error) {
+	return s.updateEscalation(ctx, s.stmpBuilder().
+		Update(escalationsTable).
+		Set("status", string(escalations.StatusTaken)).
+		Set("assignee_telegram_id", assigneeTelegramID).
+		Set("taken_at", s.now()).
+		Where(sq.Eq{"id": id, "status": string(escalations.StatusOpen)}))
+}
+
+// ResolveEscalation закрывает эскалацию; не взятая в работу назначается закрывшему админу.
+// false - эскалация уже решена
+func (s *storageImpl) ResolveEscalation(ctx context.Context, id, adminTelegramID int64) (bool, error) {
+	return s.updateEscalation(ctx, s.stmpBuilder().
+		Update(escalationsTable).
+		Set("status", string(escalations.StatusResolved)).
+		Set("assignee_telegram_id", sq.Expr("COALESCE(assignee_telegram_id, ?)", adminTelegramID)).
+		Set("resolved_at", s.now()).
+		Where(sq.Eq{"id": id}).
+		Where(sq.NotEq{"status": string(escalations.StatusResolved)}))
+}
+
+func (s *storageImpl) updateEscalation(ctx context.Context, query sq.UpdateBuilder) (bool, error) {
+	q, args, err := query.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/flowcancel"
+)
+
+const flowCancellationsTable = "flow_cancellations"
+
+// CreateFlowCancellation записывает отмену флоу без причины и возвращает ее ID для опроса
+func (s *storageImpl) CreateFlowCancellation(ctx context.Context, cancellation flowcancel.Cancellation) (int64, error) {
+	q, args, err := s.stmpBuilder().
+		Insert(flowCancellationsTable).
+		Columns("flow", "state", "telegram_id", "created_at").
+		Values(string(cancellation.Flow), cancellation.State, cancellation.TelegramID, s.now()).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("result.LastInsertId: %w", err)
+	}
+	return id, nil
+}
+
+// SetFlowCancellationReason сохраняет ответ на опрос; false - отмена чужая или на нее уже ответили
+func (s *storageImpl) SetFlowCancellationReason(ctx context.Context, id, telegramID int64, reason flowcancel.Reason) (bool, error) {
+	q, args, err := s.stmpBuilder().
+		Update(flowCancellationsTable).
+		Set("reason", string(reason)).
+		Set("answered_at", s.now()).
+		Where(sq.Eq{"id": id, "telegram_id": telegramID, "reason": nil}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// GetCancelReasonStats считает отмены флоу по причинам с момента since
+func (s *storageImpl) GetCancelReasonStats(ctx context.Context, since time.Time) ([]flowcancel.ReasonStats, error) {
+	q, args, err := s.stmpBuilder().
+		Select("flow", "COALESCE(reason, '') AS reason", "COUNT(*) AS count").
+		From(flowCancellationsTable).
+		Where(sq.GtOrEq{"created_at": since}).
+		GroupBy("flow", "COALESCE(reason, '')").
+		OrderBy("flow", "count DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []flowcancel.ReasonStats
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/stories/heatmap"
+)
+
+// GetWeekdayHeatmap returns upcoming expirations and recent renewals grouped by weekday
+func (s *storageImpl) GetWeekdayHeatmap(ctx context.Context) (*heatmap.WeekdayHeatmap, error) {
+	now := s.now()
+	period := heatmap.Weeks * 7
+
+	var expirations []time.Time
+	err := s.db.SelectContext(ctx, &expirations, `
+		SELECT expires_at
+		FROM subscriptions
+		WHERE status = 'active'
+		  AND expires_at >= ? AND expires_at < ?
+	`, now, now.AddDate(0, 0, period))
+	if err != nil {
+		return nil, fmt.Errorf("select expirations: %w", err)
+	}
+
+	// Истории продлений нет, поэтому каждая подписка учитывается по последнему продлению
+	var renewals []time.Time
+	err = s.db.SelectContext(ctx, &renewals, `
+		SELECT last_renewed_at
+		FROM subscriptions
+		WHERE renewal_count > 0
+		  AND last_renewed_at >= ? AND last_renewed_at < ?
+	`, now.AddDate(0, 0, -period), now)
+	if err != nil {
+		return nil, fmt.Errorf("select renewals: %w", err)
+	}
+
+	return heatmap.Build(heatmap.Weeks, expirations, renewals), nil
+}
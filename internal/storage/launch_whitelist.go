@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/launch"
+)
+
+const launchWhitelistTable = "launch_whitelist"
+
+var launchEntryRowFields = fields(launchEntryRow{})
+
+type launchEntryRow struct {
+	TelegramID int64     `db:"telegram_id"`
+	Username   string    `db:"username"`
+	Approved   bool      `db:"approved"`
+	AddedBy    *int64    `db:"added_by"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+func (r launchEntryRow) ToModel() *launch.Entry {
+	return &launch.Entry{
+		TelegramID: r.TelegramID,
+		Username:   r.Username,
+		Approved:   r.Approved,
+		AddedBy:    r.AddedBy,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+}
+
+// SaveWhitelistEntry добавляет пользователя в белый список или лист ожидания.
+// Повторное сохранение обновляет статус; пустое имя не затирает сохраненное
+func (s *storageImpl) SaveWhitelistEntry(ctx context.Context, entry launch.Entry) (*launch.Entry, error) {
+	now := s.now()
+	q, args, err := s.stmpBuilder().
+		Insert(launchWhitelistTable).
+		Columns("telegram_id", "username", "approved", "added_by", "created_at", "updated_at").
+		Values(entry.TelegramID, entry.Username, entry.Approved, entry.AddedBy, now, now).
+		Suffix("ON CONFLICT(telegram_id) DO UPDATE SET " +
+			"username = COALESCE(NULLIF(excluded.username, ''), username), " +
+			"approved = excluded.approved, " +
+			"added_by = excluded.added_by, " +
+			"updated_at = excluded.updated_at").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return nil, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return s.GetWhitelistEntry(ctx, entry.TelegramID)
+}
+
+// GetWhitelistEntry возвращает запись пользователя; nil - пользователя нет ни в списке, ни в листе ожидания
+func (s *storageImpl) GetWhitelistEntry(ctx context.Context, telegramID int64) (*launch.Entry, error) {
+	q, args, err := s.stmpBuilder().
+		Select(launchEntryRowFields).
+		From(launchWhitelistTable).
+		Where(sq.Eq{"telegram_id": telegramID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var row launchEntryRow
+	if err := s.db.GetContext(ctx, &row, q, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return row.ToModel(), nil
+}
+
+// ListWhitelist возвращает белый список и лист ожидания: сначала заявки, потом по дате добавления
+func (s *storageImpl) ListWhitelist(ctx context.Context) ([]*launch.Entry, error) {
+	q, args, err := s.stmpBuilder().
+		Select(launchEntryRowFields).
+		From(launchWhitelistTable).
+		OrderBy("approved ASC", "created_at ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []launchEntryRow
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*launch.Entry, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+
+	return result, nil
+}
+
+// DeleteWhitelistEntry убирает пользователя из белого списка; false - записи не было
+func (s *storageImpl) DeleteWhitelistEntry(ctx context.Context, telegramID int64) (bool, error) {
+	q, args, err := s.stmpBuilder().
+		Delete(launchWhitelistTable).
+		Where(sq.Eq{"telegram_id": telegramID}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	return affected > 0, nil
+}
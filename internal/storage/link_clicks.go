@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/stories/shortlinks"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const linkClicksTable = "link_clicks"
+
+// CreateLinkClick сохраняет переход по ссылке. Для ссылок на оплату подписка и заказ
+// определяются по платежу: сообщение об истечении, докупка трафика, связь платеж-подписка, заказ
+func (s *storageImpl) CreateLinkClick(ctx context.Context, click shortlinks.Click) error {
+	q := `
+		INSERT INTO link_clicks (link_type, link_key, payment_id, subscription_id, order_id, user_agent, clicked_at)
+		VALUES (
+			?, ?, ?,
+			COALESCE(
+				(SELECT subscription_id FROM subscription_messages WHERE payment_id = ? ORDER BY id DESC LIMIT 1),
+				(SELECT subscription_id FROM traffic_topups WHERE payment_id = ? ORDER BY id DESC LIMIT 1),
+				(SELECT subscription_id FROM payment_subscriptions WHERE payment_id = ? LIMIT 1)
+			),
+			(SELECT id FROM pending_orders WHERE payment_id = ? ORDER BY id DESC LIMIT 1),
+			?, ?
+		)`
+	args := []interface{}{
+		string(click.LinkType), click.LinkKey, click.PaymentID,
+		click.PaymentID, click.PaymentID, click.PaymentID,
+		click.PaymentID,
+		click.UserAgent, click.ClickedAt,
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return nil
+}
+
+// GetLinkClickStats возвращает количество переходов и время последнего
+func (s *storageImpl) GetLinkClickStats(ctx context.Context, criteria shortlinks.ClickCriteria) (*shortlinks.ClickStats, error) {
+	where := sq.Eq{}
+	if criteria.PaymentID != nil {
+		where["payment_id"] = *criteria.PaymentID
+	}
+	if criteria.SubscriptionID != nil {
+		where["subscription_id"] = *criteria.SubscriptionID
+	}
+	if criteria.OrderID != nil {
+		where["order_id"] = *criteria.OrderID
+	}
+
+	q, args, err := s.stmpBuilder().
+		Select("COUNT(*)").
+		From(linkClicksTable).
+		Where(where).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	stats := &shortlinks.ClickStats{}
+	if err := s.db.GetContext(ctx, &stats.Count, q, args...); err != nil {
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+	if stats.Count == 0 {
+		return stats, nil
+	}
+
+	// Время последнего перехода - отдельным запросом, чтобы драйвер вернул TIMESTAMP как time.Time
+	q, args, err = s.stmpBuilder().
+		Select("clicked_at").
+		From(linkClicksTable).
+		Where(where).
+		OrderBy("clicked_at DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var last time.Time
+	if err := s.db.GetContext(ctx, &last, q, args...); err != nil {
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+	stats.LastClickedAt = &last
+
+	return stats, nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/stories/shortlinks"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const paymentLinksTable = "payment_links"
+
+var paymentLinkRowFields = fields(paymentLinkRow{})
+
+type paymentLinkRow struct {
+	Token         string     `db:"token"`
+	PaymentID     int64      `db:"payment_id"`
+	TargetURL     string     `db:"target_url"`
+	Clicks        int        `db:"clicks"`
+	LastClickedAt *time.Time `db:"last_clicked_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+func (r paymentLinkRow) ToModel() *shortlinks.Link {
+	return &shortlinks.Link{
+		Token:         r.Token,
+		PaymentID:     r.PaymentID,
+		TargetURL:     r.TargetURL,
+		Clicks:        r.Clicks,
+		LastClickedAt: r.LastClickedAt,
+		CreatedAt:     r.CreatedAt,
+	}
+}
+
+func (s *storageImpl) CreatePaymentLink(ctx context.Context, link shortlinks.Link) (*shortlinks.Link, error) {
+	params := map[string]interface{}{
+		"token":      link.Token,
+		"payment_id": link.PaymentID,
+		"target_url": link.TargetURL,
+		"created_at": s.now(),
+	}
+
+	q, args, err := s.stmpBuilder().
+		Insert(paymentLinksTable).
+		SetMap(params).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return nil, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return s.GetPaymentLink(ctx, link.Token)
+}
+
+func (s *storageImpl) GetPaymentLink(ctx context.Context, token string) (*shortlinks.Link, error) {
+	q, args, err := s.stmpBuilder().
+		Select(paymentLinkRowFields).
+		From(paymentLinksTable).
+		Where(sq.Eq{"token": token}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var row paymentLinkRow
+	if err := s.db.GetContext(ctx, &row, q, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return row.ToModel(), nil
+}
+
+// RegisterPaymentLinkClick увеличивает счетчик переходов по короткой ссылке
+func (s *storageImpl) RegisterPaymentLinkClick(ctx context.Context, token string) error {
+	q, args, err := s.stmpBuilder().
+		Update(paymentLinksTable).
+		Set("clicks", sq.Expr("clicks + 1")).
+		Set("last_clicked_at", s.now()).
+		Where(sq.Eq{"token": token}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return nil
+}
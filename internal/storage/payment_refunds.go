@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/payment"
+)
+
+const paymentRefundsTable = "payment_refunds"
+
+var paymentRefundRowFields = fields(paymentRefundRow{})
+
+type paymentRefundRow struct {
+	ID                    int64      `db:"id"`
+	PaymentID             int64      `db:"payment_id"`
+	SubscriptionID        *int64     `db:"subscription_id"`
+	Reason                string     `db:"reason"`
+	Status                string     `db:"status"`
+	Amount                float64    `db:"amount"`
+	RequestedByTelegramID int64      `db:"requested_by_telegram_id"`
+	ProviderRefundID      *string    `db:"provider_refund_id"`
+	Error                 *string    `db:"error"`
+	CreatedAt             time.Time  `db:"created_at"`
+	CompletedAt           *time.Time `db:"completed_at"`
+}
+
+func (r paymentRefundRow) ToModel() *payment.Refund {
+	return &payment.Refund{
+		ID:                    r.ID,
+		PaymentID:             r.PaymentID,
+		SubscriptionID:        r.SubscriptionID,
+		Reason:                payment.RefundReason(r.Reason),
+		Status:                payment.RefundStatus(r.Status),
+		Amount:                r.Amount,
+		RequestedByTelegramID: r.RequestedByTelegramID,
+		ProviderRefundID:      r.ProviderRefundID,
+		Error:                 r.Error,
+		CreatedAt:             r.CreatedAt,
+		CompletedAt:           r.CompletedAt,
+	}
+}
+
+// StartRefund записывает возврат платежа в статусе pending. Возврат, который ЮKassa отклонила, перезаписывается;
+// если возврат платежа уже начат или прошел - payment.ErrAlreadyRefunded
+func (s *PaymentsRepo) StartRefund(ctx context.Context, refund payment.Refund) error {
+	q, args, err := s.stmpBuilder().
+		Insert(paymentRefundsTable).
+		Columns("payment_id", "subscription_id", "reason", "status", "amount", "requested_by_telegram_id", "created_at").
+		Values(refund.PaymentID, refund.SubscriptionID, string(refund.Reason), string(refund.Status), refund.Amount,
+			refund.RequestedByTelegramID, s.now()).
+		Suffix(upsertSuffix("payment_id", "subscription_id", "reason", "status", "amount", "requested_by_telegram_id",
+			"provider_refund_id", "error", "created_at", "completed_at")+
+			" WHERE "+paymentRefundsTable+".status = ?", string(payment.RefundFailed)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("result.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return payment.ErrAlreadyRefunded
+	}
+	return nil
+}
+
+// GetRefund возвращает возврат платежа; nil - возврата не было
+func (s *PaymentsRepo) GetRefund(ctx context.Context, paymentID int64) (*payment.Refund, error) {
+	q, args, err := s.stmpBuilder().
+		Select(paymentRefundRowFields).
+		From(paymentRefundsTable).
+		Where(sq.Eq{"payment_id": paymentID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var row paymentRefundRow
+	if err := s.db.GetContext(ctx, &row, q, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+	return row.ToModel(), nil
+}
+
+// FinishRefund сохраняет ответ ЮKassa на возврат платежа
+func (s *PaymentsRepo) FinishRefund(ctx context.Context, paymentID int64, status payment.RefundStatus, providerRefundID, errText *string) error {
+	q, args, err := s.stmpBuilder().
+		Update(paymentRefundsTable).
+		Set("status", string(status)).
+		Set("provider_refund_id", providerRefundID).
+		Set("error", errText).
+		Set("completed_at", s.now()).
+		Where(sq.Eq{"payment_id": paymentID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// FailStaleRefund помечает failed возврат, который завис в pending с момента раньше startedBefore.
+// false - возврат уже завершен или его только что начали заново
+func (s *PaymentsRepo) FailStaleRefund(ctx context.Context, paymentID int64, startedBefore time.Time, errText string) (bool, error) {
+	q, args, err := s.stmpBuilder().
+		Update(paymentRefundsTable).
+		Set("status", string(payment.RefundFailed)).
+		Set("error", errText).
+		Set("completed_at", s.now()).
+		Where(sq.Eq{"payment_id": paymentID, "status": string(payment.RefundPending)}).
+		Where(sq.Lt{"created_at": startedBefore}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ListSubscriptionRefunds возвращает возвраты платежей, привязанных к подписке. Связь берется через
+// payment_subscriptions: у возвратов поздних оплат, перенесенных из payments, подписка не указана
+func (s *PaymentsRepo) ListSubscriptionRefunds(ctx context.Context, subscriptionID int64) ([]*payment.Refund, error) {
+	q, args, err := s.stmpBuilder().
+		Select(prefixWithTable("r", paymentRefundRowFields)).
+		From(paymentRefundsTable+" r").
+		Join(paymentSubscriptionsTable+" ps ON ps.payment_id = r.payment_id").
+		Where("ps.subscription_id = ?", subscriptionID).
+		OrderBy("r.id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []paymentRefundRow
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*payment.Refund, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+	return result, nil
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"kurut-bot/internal/stories/payment"
+)
+
+func TestFailStaleRefund(t *testing.T) {
+	db := newTestDB(t)
+	s := New(db)
+	ctx := context.Background()
+
+	if _, err := db.Exec(`INSERT INTO users (id, telegram_id) VALUES (1, 100)`); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	paid, err := s.CreatePayment(ctx, payment.Payment{UserID: 1, Amount: 300, Status: payment.StatusApproved})
+	if err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+
+	refund := payment.Refund{PaymentID: paid.ID, Reason: payment.RefundGuarantee, Status: payment.RefundPending, Amount: 300, RequestedByTelegramID: 100}
+	if err := s.StartRefund(ctx, refund); err != nil {
+		t.Fatalf("StartRefund: %v", err)
+	}
+	if err := s.StartRefund(ctx, refund); !errors.Is(err, payment.ErrAlreadyRefunded) {
+		t.Fatalf("second StartRefund = %v, want ErrAlreadyRefunded", err)
+	}
+
+	// Только что начатый возврат не считается зависшим
+	failed, err := s.FailStaleRefund(ctx, paid.ID, time.Now().Add(-time.Hour), "stale")
+	if err != nil || failed {
+		t.Fatalf("FailStaleRefund(fresh) = %v, %v, want false", failed, err)
+	}
+
+	failed, err = s.FailStaleRefund(ctx, paid.ID, time.Now().Add(time.Hour), "stale")
+	if err != nil || !failed {
+		t.Fatalf("FailStaleRefund(stale) = %v, %v, want true", failed, err)
+	}
+	got, err := s.GetRefund(ctx, paid.ID)
+	if err != nil || got == nil || got.Status != payment.RefundFailed || got.Error == nil || *got.Error != "stale" {
+		t.Fatalf("GetRefund = %+v, %v, want failed refund", got, err)
+	}
+
+	// Сброшенный возврат можно начать заново
+	if err := s.StartRefund(ctx, refund); err != nil {
+		t.Fatalf("StartRefund after reset: %v", err)
+	}
+	if got, _ := s.GetRefund(ctx, paid.ID); got == nil || got.Status != payment.RefundPending {
+		t.Errorf("GetRefund after reset = %+v, want pending refund", got)
+	}
+
+	missing, err := s.GetRefund(ctx, 999)
+	if err != nil || missing != nil {
+		t.Errorf("GetRefund(missing) = %v, %v, want nil, nil", missing, err)
+	}
+}
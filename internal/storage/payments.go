@@ -7,6 +7,7 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
 
 	"kurut-bot/internal/stories/payment"
 )
@@ -19,32 +20,53 @@ const (
 var paymentRowFields = fields(paymentRow{})
 
 type paymentRow struct {
-	ID          int64      `db:"id"`
-	UserID      int64      `db:"user_id"`
-	Amount      float64    `db:"amount"`
-	Status      string     `db:"status"`
-	YooKassaID  *string    `db:"yookassa_id"`
-	PaymentURL  *string    `db:"payment_url"`
-	ProcessedAt *time.Time `db:"processed_at"`
-	CreatedAt   time.Time  `db:"created_at"`
-	UpdatedAt   time.Time  `db:"updated_at"`
+	ID                   int64      `db:"id"`
+	UserID               int64      `db:"user_id"`
+	Amount               float64    `db:"amount"`
+	Status               string     `db:"status"`
+	YooKassaID           *string    `db:"yookassa_id"`
+	PaymentURL           *string    `db:"payment_url"`
+	ProcessedAt          *time.Time `db:"processed_at"`
+	BaseAmount           *float64   `db:"base_amount"`
+	ServerID             *int64     `db:"server_id"`
+	ProviderCancelResult *string    `db:"provider_cancel_result"`
+	ProviderCancelError  *string    `db:"provider_cancel_error"`
+	ProviderCancelAt     *time.Time `db:"provider_cancel_at"`
+	LateStatus           *string    `db:"late_status"`
+	LateResolvedAt       *time.Time `db:"late_resolved_at"`
+	CreatedAt            time.Time  `db:"created_at"`
+	UpdatedAt            time.Time  `db:"updated_at"`
 }
 
 func (p paymentRow) ToModel() *payment.Payment {
-	return &payment.Payment{
-		ID:          p.ID,
-		UserID:      p.UserID,
-		Amount:      p.Amount,
-		Status:      payment.Status(p.Status),
-		YooKassaID:  p.YooKassaID,
-		PaymentURL:  p.PaymentURL,
-		ProcessedAt: p.ProcessedAt,
-		CreatedAt:   p.CreatedAt,
-		UpdatedAt:   p.UpdatedAt,
-	}
+	model := &payment.Payment{
+		ID:                  p.ID,
+		UserID:              p.UserID,
+		Amount:              p.Amount,
+		Status:              payment.Status(p.Status),
+		YooKassaID:          p.YooKassaID,
+		PaymentURL:          p.PaymentURL,
+		ProcessedAt:         p.ProcessedAt,
+		BaseAmount:          p.BaseAmount,
+		ServerID:            p.ServerID,
+		ProviderCancelError: p.ProviderCancelError,
+		ProviderCancelAt:    p.ProviderCancelAt,
+		LateResolvedAt:      p.LateResolvedAt,
+		CreatedAt:           p.CreatedAt,
+		UpdatedAt:           p.UpdatedAt,
+	}
+	if p.ProviderCancelResult != nil {
+		result := payment.ProviderCancelResult(*p.ProviderCancelResult)
+		model.ProviderCancelResult = &result
+	}
+	if p.LateStatus != nil {
+		lateStatus := payment.LateStatus(*p.LateStatus)
+		model.LateStatus = &lateStatus
+	}
+	return model
 }
 
-func (s *storageImpl) CreatePayment(ctx context.Context, paymentEntity payment.Payment) (*payment.Payment, error) {
+func (s *PaymentsRepo) CreatePayment(ctx context.Context, paymentEntity payment.Payment) (*payment.Payment, error) {
 	params := map[string]interface{}{
 		"user_id":      paymentEntity.UserID,
 		"amount":       paymentEntity.Amount,
@@ -52,6 +74,8 @@ func (s *storageImpl) CreatePayment(ctx context.Context, paymentEntity payment.P
 		"yookassa_id":  paymentEntity.YooKassaID,
 		"payment_url":  paymentEntity.PaymentURL,
 		"processed_at": paymentEntity.ProcessedAt,
+		"base_amount":  paymentEntity.BaseAmount,
+		"server_id":    paymentEntity.ServerID,
 		"created_at":   s.now(),
 		"updated_at":   s.now(),
 	}
@@ -77,7 +101,7 @@ func (s *storageImpl) CreatePayment(ctx context.Context, paymentEntity payment.P
 	return s.GetPayment(ctx, payment.GetCriteria{ID: &id})
 }
 
-func (s *storageImpl) GetPayment(ctx context.Context, criteria payment.GetCriteria) (*payment.Payment, error) {
+func (s *PaymentsRepo) GetPayment(ctx context.Context, criteria payment.GetCriteria) (*payment.Payment, error) {
 	query := s.stmpBuilder().
 		Select(paymentRowFields).
 		From(paymentsTable).
@@ -95,22 +119,19 @@ func (s *storageImpl) GetPayment(ctx context.Context, criteria payment.GetCriter
 		return nil, fmt.Errorf("build sql query: %w", err)
 	}
 
-	row := s.db.QueryRowContext(ctx, q, args...)
-
 	var p paymentRow
-	err = row.Scan(&p.ID, &p.UserID, &p.Amount, &p.Status, &p.YooKassaID,
-		&p.PaymentURL, &p.ProcessedAt, &p.CreatedAt, &p.UpdatedAt)
+	err = s.db.GetContext(ctx, &p, q, args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("row.Scan: %w", err)
+		return nil, fmt.Errorf("db.GetContext: %w", err)
 	}
 
 	return p.ToModel(), nil
 }
 
-func (s *storageImpl) UpdatePayment(ctx context.Context, criteria payment.GetCriteria, params payment.UpdateParams) (*payment.Payment, error) {
+func (s *PaymentsRepo) UpdatePayment(ctx context.Context, criteria payment.GetCriteria, params payment.UpdateParams) (*payment.Payment, error) {
 	query := s.stmpBuilder().
 		Update(paymentsTable).
 		Set("updated_at", s.now())
@@ -134,6 +155,21 @@ func (s *storageImpl) UpdatePayment(ctx context.Context, criteria payment.GetCri
 	if params.ProcessedAt != nil {
 		query = query.Set("processed_at", *params.ProcessedAt)
 	}
+	if params.ProviderCancelResult != nil {
+		query = query.Set("provider_cancel_result", string(*params.ProviderCancelResult))
+	}
+	if params.ProviderCancelError != nil {
+		query = query.Set("provider_cancel_error", *params.ProviderCancelError)
+	}
+	if params.ProviderCancelAt != nil {
+		query = query.Set("provider_cancel_at", *params.ProviderCancelAt)
+	}
+	if params.LateStatus != nil {
+		query = query.Set("late_status", string(*params.LateStatus))
+	}
+	if params.LateResolvedAt != nil {
+		query = query.Set("late_resolved_at", *params.LateResolvedAt)
+	}
 
 	q, args, err := query.ToSql()
 	if err != nil {
@@ -148,57 +184,39 @@ func (s *storageImpl) UpdatePayment(ctx context.Context, criteria payment.GetCri
 	return s.GetPayment(ctx, criteria)
 }
 
-func (s *storageImpl) ListPayments(ctx context.Context, criteria payment.ListCriteria) ([]*payment.Payment, error) {
+func (s *PaymentsRepo) ListPayments(ctx context.Context, criteria payment.ListCriteria) ([]*payment.Payment, error) {
 	query := s.stmpBuilder().
 		Select(paymentRowFields).
 		From(paymentsTable)
 
-	if criteria.UserID != nil {
-		query = query.Where(sq.Eq{"user_id": *criteria.UserID})
-	}
-	if criteria.Status != nil {
-		query = query.Where(sq.Eq{"status": string(*criteria.Status)})
-	}
-
-	if criteria.Limit > 0 {
-		query = query.Limit(uint64(criteria.Limit))
-	}
-	if criteria.Offset > 0 {
-		query = query.Offset(uint64(criteria.Offset))
-	}
+	query = whereEq(query, "user_id", criteria.UserID)
+	query = whereEq(query, "status", criteria.Status)
+	query = whereFrom(query, "created_at", criteria.CreatedAfter)
+	query = whereBefore(query, "created_at", criteria.CreatedBefore)
+	query = paginate(query, criteria.Limit, criteria.Offset)
 
-	query = query.OrderBy("created_at DESC")
+	// id - для стабильного порядка при постраничной выборке
+	query = query.OrderBy("created_at DESC", "id DESC")
 
 	q, args, err := query.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build sql query: %w", err)
 	}
 
-	rows, err := s.db.QueryContext(ctx, q, args...)
-	if err != nil {
-		return nil, fmt.Errorf("db.QueryContext: %w", err)
+	var rows []paymentRow
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
 	}
-	defer rows.Close()
 
 	var result []*payment.Payment
-	for rows.Next() {
-		var p paymentRow
-		err = rows.Scan(&p.ID, &p.UserID, &p.Amount, &p.Status, &p.YooKassaID,
-			&p.PaymentURL, &p.ProcessedAt, &p.CreatedAt, &p.UpdatedAt)
-		if err != nil {
-			return nil, fmt.Errorf("rows.Scan: %w", err)
-		}
-		result = append(result, p.ToModel())
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows.Err: %w", err)
+	for _, row := range rows {
+		result = append(result, row.ToModel())
 	}
 
 	return result, nil
 }
 
-func (s *storageImpl) DeletePayment(ctx context.Context, criteria payment.DeleteCriteria) error {
+func (s *PaymentsRepo) DeletePayment(ctx context.Context, criteria payment.DeleteCriteria) error {
 	query := s.stmpBuilder().Delete(paymentsTable)
 
 	if criteria.ID != nil {
@@ -219,7 +237,7 @@ func (s *storageImpl) DeletePayment(ctx context.Context, criteria payment.Delete
 }
 
 // Payment-Subscription связи
-func (s *storageImpl) CreatePaymentSubscription(ctx context.Context, req payment.CreatePaymentSubscriptionRequest) error {
+func (s *PaymentsRepo) CreatePaymentSubscription(ctx context.Context, req payment.CreatePaymentSubscriptionRequest) error {
 	params := map[string]interface{}{
 		"payment_id":      req.PaymentID,
 		"subscription_id": req.SubscriptionID,
@@ -241,7 +259,7 @@ func (s *storageImpl) CreatePaymentSubscription(ctx context.Context, req payment
 	return nil
 }
 
-func (s *storageImpl) GetPaymentSubscriptions(ctx context.Context, paymentID int64) ([]int64, error) {
+func (s *PaymentsRepo) GetPaymentSubscriptions(ctx context.Context, paymentID int64) ([]int64, error) {
 	q, args, err := s.stmpBuilder().
 		Select("subscription_id").
 		From(paymentSubscriptionsTable).
@@ -274,7 +292,45 @@ func (s *storageImpl) GetPaymentSubscriptions(ctx context.Context, paymentID int
 	return result, nil
 }
 
-func (s *storageImpl) DeletePaymentSubscriptions(ctx context.Context, paymentID int64) error {
+// ListPaymentSubscriptionIDs возвращает ID подписок, связанных с платежами, по ID платежа
+func (s *PaymentsRepo) ListPaymentSubscriptionIDs(ctx context.Context, paymentIDs []int64) (map[int64][]int64, error) {
+	result := make(map[int64][]int64, len(paymentIDs))
+	if len(paymentIDs) == 0 {
+		return result, nil
+	}
+
+	q, args, err := s.stmpBuilder().
+		Select("payment_id", "subscription_id").
+		From(paymentSubscriptionsTable).
+		Where(sq.Eq{"payment_id": paymentIDs}).
+		OrderBy("payment_id", "subscription_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db.QueryContext: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var paymentID, subscriptionID int64
+		if err = rows.Scan(&paymentID, &subscriptionID); err != nil {
+			return nil, fmt.Errorf("rows.Scan: %w", err)
+		}
+		result[paymentID] = append(result[paymentID], subscriptionID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *PaymentsRepo) DeletePaymentSubscriptions(ctx context.Context, paymentID int64) error {
 	q, args, err := s.stmpBuilder().
 		Delete(paymentSubscriptionsTable).
 		Where(sq.Eq{"payment_id": paymentID}).
@@ -291,22 +347,31 @@ func (s *storageImpl) DeletePaymentSubscriptions(ctx context.Context, paymentID
 	return nil
 }
 
-// LinkPaymentToSubscriptions creates links between payment and multiple subscriptions
-func (s *storageImpl) LinkPaymentToSubscriptions(ctx context.Context, paymentID int64, subscriptionIDs []int64) error {
-	for _, subscriptionID := range subscriptionIDs {
-		req := payment.CreatePaymentSubscriptionRequest{
-			PaymentID:      paymentID,
-			SubscriptionID: subscriptionID,
+// LinkPaymentToSubscriptions creates links between payment and multiple subscriptions in one transaction
+func (s *PaymentsRepo) LinkPaymentToSubscriptions(ctx context.Context, paymentID int64, subscriptionIDs []int64) error {
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		for _, subscriptionID := range subscriptionIDs {
+			q, args, err := s.stmpBuilder().
+				Insert(paymentSubscriptionsTable).
+				SetMap(map[string]interface{}{
+					"payment_id":      paymentID,
+					"subscription_id": subscriptionID,
+				}).
+				ToSql()
+			if err != nil {
+				return fmt.Errorf("build sql query: %w", err)
+			}
+
+			if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+				return fmt.Errorf("failed to link payment %d to subscription %d: %w", paymentID, subscriptionID, err)
+			}
 		}
-		if err := s.CreatePaymentSubscription(ctx, req); err != nil {
-			return fmt.Errorf("failed to link payment %d to subscription %d: %w", paymentID, subscriptionID, err)
-		}
-	}
-	return nil
+		return nil
+	})
 }
 
 // IsSubscriptionLinkedToPayment checks if a subscription is linked to any payment
-func (s *storageImpl) IsSubscriptionLinkedToPayment(ctx context.Context, subscriptionID int64) (bool, error) {
+func (s *PaymentsRepo) IsSubscriptionLinkedToPayment(ctx context.Context, subscriptionID int64) (bool, error) {
 	query := `SELECT COUNT(*) FROM ` + paymentSubscriptionsTable + ` WHERE subscription_id = ?`
 
 	var count int
@@ -319,7 +384,7 @@ func (s *storageImpl) IsSubscriptionLinkedToPayment(ctx context.Context, subscri
 }
 
 // ListOrphanedPayments returns approved payments that have no linked subscriptions
-func (s *storageImpl) ListOrphanedPayments(ctx context.Context) ([]*payment.Payment, error) {
+func (s *PaymentsRepo) ListOrphanedPayments(ctx context.Context) ([]*payment.Payment, error) {
 	query := `
 		SELECT ` + paymentRowFields + `
 		FROM ` + paymentsTable + ` p
@@ -329,26 +394,83 @@ func (s *storageImpl) ListOrphanedPayments(ctx context.Context) ([]*payment.Paym
 		ORDER BY p.created_at ASC
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, string(payment.StatusApproved))
-	if err != nil {
-		return nil, fmt.Errorf("db.QueryContext: %w", err)
+	var rows []paymentRow
+	if err := s.db.SelectContext(ctx, &rows, query, string(payment.StatusApproved)); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
 	}
-	defer rows.Close()
 
 	var result []*payment.Payment
-	for rows.Next() {
-		var p paymentRow
-		err = rows.Scan(&p.ID, &p.UserID, &p.Amount, &p.Status, &p.YooKassaID,
-			&p.PaymentURL, &p.ProcessedAt, &p.CreatedAt, &p.UpdatedAt)
-		if err != nil {
-			return nil, fmt.Errorf("rows.Scan: %w", err)
-		}
-		result = append(result, p.ToModel())
+	for _, row := range rows {
+		result = append(result, row.ToModel())
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows.Err: %w", err)
+	return result, nil
+}
+
+// ListPendingPaymentsCreatedBefore returns pending payments created before the given time
+func (s *PaymentsRepo) ListPendingPaymentsCreatedBefore(ctx context.Context, before time.Time) ([]*payment.Payment, error) {
+	q, args, err := s.stmpBuilder().
+		Select(paymentRowFields).
+		From(paymentsTable).
+		Where(sq.Eq{"status": string(payment.StatusPending)}).
+		Where(sq.Lt{"created_at": before}).
+		OrderBy("created_at ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []paymentRow
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	var result []*payment.Payment
+	for _, row := range rows {
+		result = append(result, row.ToModel())
 	}
 
 	return result, nil
 }
+
+// CountPendingPayments returns the number of payments still waiting for confirmation
+func (s *PaymentsRepo) CountPendingPayments(ctx context.Context) (int, error) {
+	count, err := s.count(ctx, s.stmpBuilder().
+		Select("id").
+		From(paymentsTable).
+		Where(sq.Eq{"status": string(payment.StatusPending)}))
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ClaimLatePaymentResolution переводит позднюю оплату из detected в итоговый статус.
+// Условие в UPDATE не дает двум админам одновременно выдать подписку и вернуть деньги
+func (s *PaymentsRepo) ClaimLatePaymentResolution(ctx context.Context, paymentID int64, status payment.LateStatus) (bool, error) {
+	now := s.now()
+	q, args, err := s.stmpBuilder().
+		Update(paymentsTable).
+		Set("late_status", string(status)).
+		Set("late_resolved_at", now).
+		Set("updated_at", now).
+		Where(sq.Eq{"id": paymentID}).
+		Where(sq.Eq{"late_status": string(payment.LateDetected)}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	return affected > 0, nil
+}
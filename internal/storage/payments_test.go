@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+
+	"kurut-bot/internal/stories/payment"
+)
+
+// newTestDB - база в памяти со схемой из migrations (только секции goose Up)
+func newTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	return newTestDBBefore(t, "")
+}
+
+// newTestDBBefore - база в памяти с миграциями, имена файлов которых меньше before; пустой before - все миграции
+func newTestDBBefore(t *testing.T, before string) *sqlx.DB {
+	t.Helper()
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	// Одно соединение: у каждого соединения с :memory: своя база
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	files, err := filepath.Glob("../../migrations/*.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("find migrations: %v", err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if before != "" && filepath.Base(file) >= before {
+			break
+		}
+		applyMigration(t, db, file)
+	}
+	return db
+}
+
+// applyMigration выполняет секцию goose Up файла миграции
+func applyMigration(t *testing.T, db *sqlx.DB, file string) {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read %s: %v", file, err)
+	}
+	up, _, _ := strings.Cut(string(data), "-- +goose Down")
+	if _, err := db.Exec(up); err != nil {
+		t.Fatalf("apply %s: %v", file, err)
+	}
+}
+
+func TestPaymentsRoundTrip(t *testing.T) {
+	db := newTestDB(t)
+	s := New(db)
+	ctx := context.Background()
+
+	if _, err := db.Exec(`INSERT INTO users (id, telegram_id) VALUES (1, 100)`); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO servers (id, name, ui_url, ui_password) VALUES (3, 'srv', 'https://srv', 'pwd')`); err != nil {
+		t.Fatalf("insert server: %v", err)
+	}
+
+	base := 300.0
+	serverID := int64(3)
+	created, err := s.CreatePayment(ctx, payment.Payment{
+		UserID:     1,
+		Amount:     350,
+		Status:     payment.StatusPending,
+		BaseAmount: &base,
+		ServerID:   &serverID,
+	})
+	if err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+	if created == nil || created.Amount != 350 || created.Status != payment.StatusPending {
+		t.Fatalf("CreatePayment = %+v, want pending payment of 350", created)
+	}
+	if created.BaseAmount == nil || *created.BaseAmount != base || created.ServerID == nil || *created.ServerID != serverID {
+		t.Errorf("CreatePayment lost the surcharge breakdown: base %v, server %v", created.BaseAmount, created.ServerID)
+	}
+
+	pending, err := s.ListPendingPaymentsCreatedBefore(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ListPendingPaymentsCreatedBefore: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != created.ID {
+		t.Errorf("ListPendingPaymentsCreatedBefore = %v, want payment #%d", pending, created.ID)
+	}
+
+	approved := payment.StatusApproved
+	if _, err := s.UpdatePayment(ctx, payment.GetCriteria{ID: &created.ID}, payment.UpdateParams{Status: &approved}); err != nil {
+		t.Fatalf("UpdatePayment: %v", err)
+	}
+
+	userID := int64(1)
+	list, err := s.ListPayments(ctx, payment.ListCriteria{UserID: &userID})
+	if err != nil {
+		t.Fatalf("ListPayments: %v", err)
+	}
+	if len(list) != 1 || list[0].Status != payment.StatusApproved || list[0].ServerSurcharge() != 50 {
+		t.Errorf("ListPayments = %+v, want one approved payment with a 50 surcharge", list)
+	}
+
+	orphaned, err := s.ListOrphanedPayments(ctx)
+	if err != nil {
+		t.Fatalf("ListOrphanedPayments: %v", err)
+	}
+	if len(orphaned) != 1 || orphaned[0].ID != created.ID {
+		t.Errorf("ListOrphanedPayments = %v, want payment #%d", orphaned, created.ID)
+	}
+
+	missingID := int64(999)
+	missing, err := s.GetPayment(ctx, payment.GetCriteria{ID: &missingID})
+	if err != nil || missing != nil {
+		t.Errorf("GetPayment(missing) = %v, %v, want nil, nil", missing, err)
+	}
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// ListClientPaymentTimes возвращает время завершения оплат каждого клиента (WhatsApp) начиная с since.
+// Платеж за несколько подписок клиента учитывается один раз
+func (s *storageImpl) ListClientPaymentTimes(ctx context.Context, since time.Time) (map[string][]time.Time, error) {
+	q, args, err := s.stmpBuilder().
+		Select("DISTINCT p.id", "s.client_whatsapp", "p.processed_at").
+		From(paymentsTable + " p").
+		Join(paymentSubscriptionsTable + " ps ON ps.payment_id = p.id").
+		Join(subscriptionsTable + " s ON s.id = ps.subscription_id").
+		Where(sq.Eq{"p.status": "approved"}).
+		Where(sq.NotEq{"s.client_whatsapp": nil}).
+		Where(sq.GtOrEq{"p.processed_at": since}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []struct {
+		PaymentID      int64     `db:"id"`
+		ClientWhatsApp string    `db:"client_whatsapp"`
+		ProcessedAt    time.Time `db:"processed_at"`
+	}
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make(map[string][]time.Time)
+	for _, row := range rows {
+		result[row.ClientWhatsApp] = append(result[row.ClientWhatsApp], row.ProcessedAt)
+	}
+	return result, nil
+}
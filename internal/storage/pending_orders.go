@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"kurut-bot/internal/stories/orders"
+	"kurut-bot/internal/stories/payment"
 
 	sq "github.com/Masterminds/squirrel"
 )
@@ -16,23 +17,31 @@ const pendingOrdersTable = "pending_orders"
 var pendingOrderRowFields = fields(pendingOrderRow{})
 
 type pendingOrderRow struct {
-	ID                     int64     `db:"id"`
-	PaymentID              int64     `db:"payment_id"`
-	AdminUserID            int64     `db:"admin_user_id"`
-	AssistantTelegramID    int64     `db:"assistant_telegram_id"`
-	ChatID                 int64     `db:"chat_id"`
-	MessageID              *int      `db:"message_id"`
-	ClientWhatsApp         string    `db:"client_whatsapp"`
-	ServerID               *int64    `db:"server_id"`
-	ServerName             *string   `db:"server_name"`
-	TariffID               int64     `db:"tariff_id"`
-	TariffName             string    `db:"tariff_name"`
-	TotalAmount            float64   `db:"total_amount"`
-	ReferrerWhatsApp       *string   `db:"referrer_whatsapp"`
-	ReferrerSubscriptionID *int64    `db:"referrer_subscription_id"`
-	Status                 string    `db:"status"`
-	CreatedAt              time.Time `db:"created_at"`
-	UpdatedAt              time.Time `db:"updated_at"`
+	ID                     int64      `db:"id"`
+	PaymentID              int64      `db:"payment_id"`
+	AdminUserID            int64      `db:"admin_user_id"`
+	AssistantTelegramID    int64      `db:"assistant_telegram_id"`
+	ChatID                 int64      `db:"chat_id"`
+	MessageID              *int       `db:"message_id"`
+	ClientWhatsApp         string     `db:"client_whatsapp"`
+	ServerID               *int64     `db:"server_id"`
+	ServerName             *string    `db:"server_name"`
+	TariffID               int64      `db:"tariff_id"`
+	TariffName             string     `db:"tariff_name"`
+	TotalAmount            float64    `db:"total_amount"`
+	BaseAmount             *float64   `db:"base_amount"`
+	ReferrerWhatsApp       *string    `db:"referrer_whatsapp"`
+	ReferrerSubscriptionID *int64     `db:"referrer_subscription_id"`
+	TargetServerID         *int64     `db:"target_server_id"`
+	LinkRefreshCount       int        `db:"link_refresh_count"`
+	LinkRefreshedAt        *time.Time `db:"link_refreshed_at"`
+	ReceiptFileID          *string    `db:"receipt_file_id"`
+	ReceiptUploadedAt      *time.Time `db:"receipt_uploaded_at"`
+	ReceiptReviewedBy      *int64     `db:"receipt_reviewed_by"`
+	ReceiptReviewedAt      *time.Time `db:"receipt_reviewed_at"`
+	Status                 string     `db:"status"`
+	CreatedAt              time.Time  `db:"created_at"`
+	UpdatedAt              time.Time  `db:"updated_at"`
 }
 
 func (r pendingOrderRow) ToModel() *orders.PendingOrder {
@@ -49,15 +58,23 @@ func (r pendingOrderRow) ToModel() *orders.PendingOrder {
 		TariffID:               r.TariffID,
 		TariffName:             r.TariffName,
 		TotalAmount:            r.TotalAmount,
+		BaseAmount:             r.BaseAmount,
 		ReferrerWhatsApp:       r.ReferrerWhatsApp,
 		ReferrerSubscriptionID: r.ReferrerSubscriptionID,
+		TargetServerID:         r.TargetServerID,
+		LinkRefreshCount:       r.LinkRefreshCount,
+		LinkRefreshedAt:        r.LinkRefreshedAt,
+		ReceiptFileID:          r.ReceiptFileID,
+		ReceiptUploadedAt:      r.ReceiptUploadedAt,
+		ReceiptReviewedBy:      r.ReceiptReviewedBy,
+		ReceiptReviewedAt:      r.ReceiptReviewedAt,
 		Status:                 orders.Status(r.Status),
 		CreatedAt:              r.CreatedAt,
 		UpdatedAt:              r.UpdatedAt,
 	}
 }
 
-func (s *storageImpl) CreatePendingOrder(ctx context.Context, order orders.PendingOrder) (*orders.PendingOrder, error) {
+func (s *OrdersRepo) CreatePendingOrder(ctx context.Context, order orders.PendingOrder) (*orders.PendingOrder, error) {
 	now := s.now()
 
 	params := map[string]interface{}{
@@ -72,8 +89,10 @@ func (s *storageImpl) CreatePendingOrder(ctx context.Context, order orders.Pendi
 		"tariff_id":                order.TariffID,
 		"tariff_name":              order.TariffName,
 		"total_amount":             order.TotalAmount,
+		"base_amount":              order.BaseAmount,
 		"referrer_whatsapp":        order.ReferrerWhatsApp,
 		"referrer_subscription_id": order.ReferrerSubscriptionID,
+		"target_server_id":         order.TargetServerID,
 		"status":                   string(orders.StatusPending),
 		"created_at":               now,
 		"updated_at":               now,
@@ -100,7 +119,7 @@ func (s *storageImpl) CreatePendingOrder(ctx context.Context, order orders.Pendi
 	return s.GetPendingOrderByID(ctx, id)
 }
 
-func (s *storageImpl) GetPendingOrderByID(ctx context.Context, id int64) (*orders.PendingOrder, error) {
+func (s *OrdersRepo) GetPendingOrderByID(ctx context.Context, id int64) (*orders.PendingOrder, error) {
 	q, args, err := s.stmpBuilder().
 		Select(pendingOrderRowFields).
 		From(pendingOrdersTable).
@@ -123,7 +142,7 @@ func (s *storageImpl) GetPendingOrderByID(ctx context.Context, id int64) (*order
 	return row.ToModel(), nil
 }
 
-func (s *storageImpl) UpdatePendingOrderMessageID(ctx context.Context, id int64, messageID int) error {
+func (s *OrdersRepo) UpdatePendingOrderMessageID(ctx context.Context, id int64, messageID int) error {
 	params := map[string]interface{}{
 		"message_id": messageID,
 		"updated_at": s.now(),
@@ -146,7 +165,7 @@ func (s *storageImpl) UpdatePendingOrderMessageID(ctx context.Context, id int64,
 	return nil
 }
 
-func (s *storageImpl) UpdatePendingOrderPaymentID(ctx context.Context, id int64, paymentID int64) error {
+func (s *OrdersRepo) UpdatePendingOrderPaymentID(ctx context.Context, id int64, paymentID int64) error {
 	params := map[string]interface{}{
 		"payment_id": paymentID,
 		"updated_at": s.now(),
@@ -169,7 +188,98 @@ func (s *storageImpl) UpdatePendingOrderPaymentID(ctx context.Context, id int64,
 	return nil
 }
 
-func (s *storageImpl) UpdatePendingOrderStatus(ctx context.Context, id int64, status orders.Status) error {
+// ClaimPendingOrderLinkRefresh засчитывает обновление ссылки на оплату, если не исчерпан лимит
+// и прошел интервал с прошлого обновления. Условие в UPDATE защищает от одновременных нажатий
+func (s *OrdersRepo) ClaimPendingOrderLinkRefresh(ctx context.Context, id int64, now time.Time) (bool, error) {
+	q, args, err := s.stmpBuilder().
+		Update(pendingOrdersTable).
+		Set("link_refresh_count", sq.Expr("link_refresh_count + 1")).
+		Set("link_refreshed_at", now).
+		Set("updated_at", now).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Lt{"link_refresh_count": orders.MaxLinkRefreshes}).
+		Where(sq.Or{
+			sq.Eq{"link_refreshed_at": nil},
+			sq.LtOrEq{"link_refreshed_at": now.Add(-orders.LinkRefreshCooldown)},
+		}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// AttachPendingOrderReceipt сохраняет file_id чека у ожидающего заказа, который админ еще не рассмотрел
+func (s *OrdersRepo) AttachPendingOrderReceipt(ctx context.Context, id int64, fileID string, now time.Time) (bool, error) {
+	q, args, err := s.stmpBuilder().
+		Update(pendingOrdersTable).
+		Set("receipt_file_id", fileID).
+		Set("receipt_uploaded_at", now).
+		Set("updated_at", now).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Eq{"status": string(orders.StatusPending)}).
+		Where(sq.Eq{"receipt_reviewed_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// ClaimPendingOrderReceiptReview фиксирует решение админа по чеку. Условие в UPDATE не дает
+// двум админам одновременно подтвердить один чек, а админу - подтвердить отмененный ассистентом заказ
+func (s *OrdersRepo) ClaimPendingOrderReceiptReview(ctx context.Context, id int64, reviewerTelegramID int64, status orders.Status, now time.Time) (bool, error) {
+	q, args, err := s.stmpBuilder().
+		Update(pendingOrdersTable).
+		Set("status", string(status)).
+		Set("receipt_reviewed_by", reviewerTelegramID).
+		Set("receipt_reviewed_at", now).
+		Set("updated_at", now).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Eq{"status": string(orders.StatusPending)}).
+		Where(sq.NotEq{"receipt_file_id": nil}).
+		Where(sq.Eq{"receipt_reviewed_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (s *OrdersRepo) UpdatePendingOrderStatus(ctx context.Context, id int64, status orders.Status) error {
 	params := map[string]interface{}{
 		"status":     string(status),
 		"updated_at": s.now(),
@@ -192,7 +302,7 @@ func (s *storageImpl) UpdatePendingOrderStatus(ctx context.Context, id int64, st
 	return nil
 }
 
-func (s *storageImpl) DeletePendingOrder(ctx context.Context, id int64) error {
+func (s *OrdersRepo) DeletePendingOrder(ctx context.Context, id int64) error {
 	q, args, err := s.stmpBuilder().
 		Delete(pendingOrdersTable).
 		Where(sq.Eq{"id": id}).
@@ -210,7 +320,7 @@ func (s *storageImpl) DeletePendingOrder(ctx context.Context, id int64) error {
 }
 
 // ListPendingOrdersWithPayments returns all pending orders that have a payment_id
-func (s *storageImpl) ListPendingOrdersWithPayments(ctx context.Context) ([]*orders.PendingOrder, error) {
+func (s *OrdersRepo) ListPendingOrdersWithPayments(ctx context.Context) ([]*orders.PendingOrder, error) {
 	q, args, err := s.stmpBuilder().
 		Select(pendingOrderRowFields).
 		From(pendingOrdersTable).
@@ -235,3 +345,94 @@ func (s *storageImpl) ListPendingOrdersWithPayments(ctx context.Context) ([]*ord
 
 	return result, nil
 }
+
+// ListPendingOrdersByAssistant returns the assistant's pending orders with a payment, newest first
+func (s *OrdersRepo) ListPendingOrdersByAssistant(ctx context.Context, assistantTelegramID int64, limit int) ([]*orders.PendingOrder, error) {
+	q, args, err := s.stmpBuilder().
+		Select(pendingOrderRowFields).
+		From(pendingOrdersTable).
+		Where(sq.Eq{"status": string(orders.StatusPending), "assistant_telegram_id": assistantTelegramID}).
+		Where(sq.Gt{"payment_id": 0}).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []pendingOrderRow
+	err = s.db.SelectContext(ctx, &rows, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	var result []*orders.PendingOrder
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+
+	return result, nil
+}
+
+// ListCancelledOrdersAwaitingLateCheck returns orders cancelled after the given time whose payment
+// may still be paid by the client: it wasn't confirmed as cancelled in YooKassa or was already approved.
+// Платежи, по которым поздняя оплата уже найдена, не возвращаются
+func (s *OrdersRepo) ListCancelledOrdersAwaitingLateCheck(ctx context.Context, cancelledAfter time.Time) ([]*orders.PendingOrder, error) {
+	query := `
+		SELECT ` + prefixWithTable("o", pendingOrderRowFields) + `
+		FROM ` + pendingOrdersTable + ` o
+		JOIN ` + paymentsTable + ` p ON p.id = o.payment_id
+		WHERE o.status = ?
+		AND o.updated_at >= ?
+		AND p.late_status IS NULL
+		AND (
+			p.status = ?
+			OR (p.status = ? AND (p.provider_cancel_result IS NULL OR p.provider_cancel_result = ?))
+		)
+		ORDER BY o.updated_at ASC
+	`
+
+	var rows []pendingOrderRow
+	err := s.db.SelectContext(ctx, &rows, query,
+		string(orders.StatusCancelled),
+		cancelledAfter,
+		string(payment.StatusApproved),
+		string(payment.StatusCancelled),
+		string(payment.ProviderCancelFailed),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*orders.PendingOrder, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+
+	return result, nil
+}
+
+// GetPendingOrderByPaymentID returns the order the payment was created for
+func (s *OrdersRepo) GetPendingOrderByPaymentID(ctx context.Context, paymentID int64) (*orders.PendingOrder, error) {
+	q, args, err := s.stmpBuilder().
+		Select(pendingOrderRowFields).
+		From(pendingOrdersTable).
+		Where(sq.Eq{"payment_id": paymentID}).
+		OrderBy("id DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var row pendingOrderRow
+	err = s.db.GetContext(ctx, &row, q, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return row.ToModel(), nil
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"kurut-bot/internal/stories/phonemerge"
+)
+
+// phoneRefColumns - колонки с номером клиента без уникальности: при объединении просто переписываются
+var phoneRefColumns = []struct{ table, column string }{
+	{subscriptionsTable, "referrer_whatsapp"},
+	{pendingOrdersTable, "client_whatsapp"},
+	{pendingOrdersTable, "referrer_whatsapp"},
+	{waitlistEntriesTable, "client_whatsapp"},
+	{dripMessagesTable, "client_whatsapp"},
+}
+
+// normalizedPhoneSQL - SQL-аналог NormalizePhone для колонки: убирает "+", пробелы и дефисы
+func normalizedPhoneSQL(column string) string {
+	return fmt.Sprintf("REPLACE(REPLACE(REPLACE(%s, '+', ''), ' ', ''), '-', '')", column)
+}
+
+type phoneVariantRow struct {
+	Canonical string `db:"canonical"`
+	phonemerge.Variant
+}
+
+// ListPhoneDuplicates возвращает клиентов, подписки которых записаны под несколькими форматами одного номера
+func (s *storageImpl) ListPhoneDuplicates(ctx context.Context) ([]phonemerge.Group, error) {
+	query := fmt.Sprintf(`
+		WITH formats AS (
+			SELECT client_whatsapp AS phone,
+			       %s AS canonical,
+			       COUNT(*) AS subscriptions,
+			       SUM(CASE WHEN status = 'active' THEN 1 ELSE 0 END) AS active_subscriptions
+			FROM subscriptions
+			WHERE client_whatsapp IS NOT NULL AND client_whatsapp <> ''
+			GROUP BY client_whatsapp
+		)
+		SELECT canonical, phone, subscriptions, active_subscriptions
+		FROM formats
+		WHERE canonical IN (SELECT canonical FROM formats GROUP BY canonical HAVING COUNT(*) > 1)
+		ORDER BY canonical, phone
+	`, normalizedPhoneSQL("client_whatsapp"))
+
+	var rows []phoneVariantRow
+	if err := s.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	var groups []phonemerge.Group
+	for _, row := range rows {
+		if len(groups) == 0 || groups[len(groups)-1].Canonical != row.Canonical {
+			groups = append(groups, phonemerge.Group{Canonical: row.Canonical})
+		}
+		last := &groups[len(groups)-1]
+		last.Variants = append(last.Variants, row.Variant)
+	}
+	return groups, nil
+}
+
+// MergePhoneDuplicates переписывает все форматы номера на canonical (только цифры) и возвращает,
+// сколько подписок перенесено. Балансы разных форматов складываются; язык и отказ от рассылки
+// сохраняются у canonical, если он уже есть, иначе берется последняя запись
+func (s *storageImpl) MergePhoneDuplicates(ctx context.Context, canonical string) (int64, error) {
+	canonical = NormalizePhone(canonical)
+	var moved int64
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		q, args, err := s.stmpBuilder().
+			Update(subscriptionsTable).
+			Set("client_whatsapp", canonical).
+			Where(sq.Expr(normalizedPhoneSQL("client_whatsapp")+" = ?", canonical)).
+			Where(sq.NotEq{"client_whatsapp": canonical}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql query: %w", err)
+		}
+		result, err := tx.ExecContext(ctx, q, args...)
+		if err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+		moved, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("result.RowsAffected: %w", err)
+		}
+
+		for _, ref := range phoneRefColumns {
+			q, args, err := s.stmpBuilder().
+				Update(ref.table).
+				Set(ref.column, canonical).
+				Where(sq.Expr(normalizedPhoneSQL(ref.column)+" = ?", canonical)).
+				Where(sq.NotEq{ref.column: canonical}).
+				ToSql()
+			if err != nil {
+				return fmt.Errorf("build sql query: %w", err)
+			}
+			if _, err = tx.ExecContext(ctx, q, args...); err != nil {
+				return fmt.Errorf("tx.ExecContext %s.%s: %w", ref.table, ref.column, err)
+			}
+		}
+
+		if err := s.mergeClientBalances(ctx, tx, canonical); err != nil {
+			return err
+		}
+		if err := s.mergePhoneKeyedRows(ctx, tx, clientLanguagesTable, "updated_at", canonical); err != nil {
+			return err
+		}
+		return s.mergePhoneKeyedRows(ctx, tx, dripOptOutsTable, "created_at", canonical)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// mergeClientBalances переносит балансы других форматов номера на canonical
+func (s *storageImpl) mergeClientBalances(ctx context.Context, tx *sqlx.Tx, canonical string) error {
+	variants := sq.And{
+		sq.Expr(normalizedPhoneSQL("client_whatsapp")+" = ?", canonical),
+		sq.NotEq{"client_whatsapp": canonical},
+	}
+
+	q, args, err := s.stmpBuilder().
+		Select("COUNT(*)", "COALESCE(SUM(amount), 0)").
+		From(clientBalancesTable).
+		Where(variants).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+	var count int
+	var amount float64
+	if err = tx.QueryRowxContext(ctx, q, args...).Scan(&count, &amount); err != nil {
+		return fmt.Errorf("tx.QueryRowxContext: %w", err)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	q, args, err = s.stmpBuilder().Delete(clientBalancesTable).Where(variants).ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+
+	now := s.now()
+	q, args, err = s.stmpBuilder().
+		Insert(clientBalancesTable).
+		Columns("client_whatsapp", "amount", "created_at", "updated_at").
+		Values(canonical, amount, now, now).
+		Suffix("ON CONFLICT(client_whatsapp) DO UPDATE SET amount = amount + excluded.amount, updated_at = excluded.updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// mergePhoneKeyedRows оставляет в таблице с номером в первичном ключе одну запись клиента под canonical:
+// существующую запись canonical, а если ее нет - последнюю по orderColumn
+func (s *storageImpl) mergePhoneKeyedRows(ctx context.Context, tx *sqlx.Tx, table, orderColumn, canonical string) error {
+	rename := fmt.Sprintf(`
+		UPDATE %[1]s SET client_whatsapp = ?
+		WHERE client_whatsapp = (
+			SELECT client_whatsapp FROM %[1]s
+			WHERE %[2]s = ?
+			ORDER BY %[3]s DESC
+			LIMIT 1
+		)
+		AND NOT EXISTS (SELECT 1 FROM %[1]s WHERE client_whatsapp = ?)
+	`, table, normalizedPhoneSQL("client_whatsapp"), orderColumn)
+	if _, err := tx.ExecContext(ctx, rename, canonical, canonical, canonical); err != nil {
+		return fmt.Errorf("tx.ExecContext rename %s: %w", table, err)
+	}
+
+	q, args, err := s.stmpBuilder().
+		Delete(table).
+		Where(sq.Expr(normalizedPhoneSQL("client_whatsapp")+" = ?", canonical)).
+		Where(sq.NotEq{"client_whatsapp": canonical}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	return nil
+}
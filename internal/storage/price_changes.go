@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"kurut-bot/internal/stories/pricechanges"
+)
+
+const tariffPriceChangesTable = "tariff_price_changes"
+
+var priceChangeRowFields = fields(priceChangeRow{})
+
+type priceChangeRow struct {
+	ID                  int64      `db:"id"`
+	TariffID            int64      `db:"tariff_id"`
+	OldPrice            float64    `db:"old_price"`
+	NewPrice            float64    `db:"new_price"`
+	EffectiveAt         time.Time  `db:"effective_at"`
+	CreatedByTelegramID int64      `db:"created_by_telegram_id"`
+	NotifiedAt          *time.Time `db:"notified_at"`
+	AppliedAt           *time.Time `db:"applied_at"`
+	CancelledAt         *time.Time `db:"cancelled_at"`
+	CreatedAt           time.Time  `db:"created_at"`
+}
+
+func (r priceChangeRow) ToModel() *pricechanges.PriceChange {
+	return &pricechanges.PriceChange{
+		ID:                  r.ID,
+		TariffID:            r.TariffID,
+		OldPrice:            r.OldPrice,
+		NewPrice:            r.NewPrice,
+		EffectiveAt:         r.EffectiveAt,
+		CreatedByTelegramID: r.CreatedByTelegramID,
+		NotifiedAt:          r.NotifiedAt,
+		AppliedAt:           r.AppliedAt,
+		CancelledAt:         r.CancelledAt,
+		CreatedAt:           r.CreatedAt,
+	}
+}
+
+// SchedulePriceChange планирует изменение цены тарифа; прежнее запланированное изменение этого тарифа отменяется
+func (s *storageImpl) SchedulePriceChange(ctx context.Context, change pricechanges.PriceChange) (*pricechanges.PriceChange, error) {
+	now := s.now()
+	var id int64
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		q, args, err := s.stmpBuilder().
+			Update(tariffPriceChangesTable).
+			Set("cancelled_at", now).
+			Where(sq.Eq{"tariff_id": change.TariffID, "applied_at": nil, "cancelled_at": nil}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql query: %w", err)
+		}
+		if _, err = tx.ExecContext(ctx, q, args...); err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+
+		q, args, err = s.stmpBuilder().
+			Insert(tariffPriceChangesTable).
+			Columns("tariff_id", "old_price", "new_price", "effective_at", "created_by_telegram_id", "created_at").
+			Values(change.TariffID, change.OldPrice, change.NewPrice, change.EffectiveAt, change.CreatedByTelegramID, now).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql query: %w", err)
+		}
+		result, err := tx.ExecContext(ctx, q, args...)
+		if err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+		id, err = result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("result.LastInsertId: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	change.ID = id
+	change.CreatedAt = now
+	return &change, nil
+}
+
+// ListScheduledPriceChanges возвращает изменения цен, которые еще не применены и не отменены
+func (s *storageImpl) ListScheduledPriceChanges(ctx context.Context) ([]*pricechanges.PriceChange, error) {
+	q, args, err := s.stmpBuilder().
+		Select(priceChangeRowFields).
+		From(tariffPriceChangesTable).
+		Where(sq.Eq{"applied_at": nil, "cancelled_at": nil}).
+		OrderBy("effective_at", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []priceChangeRow
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*pricechanges.PriceChange, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+	return result, nil
+}
+
+// CancelPriceChange отменяет запланированное изменение цены тарифа; false - отменять нечего
+func (s *storageImpl) CancelPriceChange(ctx context.Context, tariffID int64) (bool, error) {
+	q, args, err := s.stmpBuilder().
+		Update(tariffPriceChangesTable).
+		Set("cancelled_at", s.now()).
+		Where(sq.Eq{"tariff_id": tariffID, "applied_at": nil, "cancelled_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// MarkPriceChangeNotified отмечает, что ассистенты получили карточки клиентов
+func (s *storageImpl) MarkPriceChangeNotified(ctx context.Context, id int64) error {
+	q, args, err := s.stmpBuilder().
+		Update(tariffPriceChangesTable).
+		Set("notified_at", s.now()).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// ApplyPriceChange устанавливает тарифу новую цену; false - изменение уже применено или отменено
+func (s *storageImpl) ApplyPriceChange(ctx context.Context, change pricechanges.PriceChange) (bool, error) {
+	now := s.now()
+	var applied bool
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		q, args, err := s.stmpBuilder().
+			Update(tariffPriceChangesTable).
+			Set("applied_at", now).
+			Where(sq.Eq{"id": change.ID, "applied_at": nil, "cancelled_at": nil}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql query: %w", err)
+		}
+		result, err := tx.ExecContext(ctx, q, args...)
+		if err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("result.RowsAffected: %w", err)
+		}
+		if affected == 0 {
+			return nil
+		}
+
+		q, args, err = s.stmpBuilder().
+			Update(tariffsTable).
+			Set("price", change.NewPrice).
+			Set("updated_at", now).
+			Where(sq.Eq{"id": change.TariffID}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql query: %w", err)
+		}
+		if _, err = tx.ExecContext(ctx, q, args...); err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+
+		applied = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return applied, nil
+}
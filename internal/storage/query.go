@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// Помощники для повторяющихся шаблонов squirrel: фильтры из критериев, пагинация, подсчет и upsert.
+// Новые List*-методы с фильтрами собираются из них, чтобы одинаково трактовать пустые критерии
+
+// whereEq добавляет условие column = *value; nil - фильтр не задан
+func whereEq[T any](query sq.SelectBuilder, column string, value *T) sq.SelectBuilder {
+	if value == nil {
+		return query
+	}
+	return query.Where(sq.Eq{column: *value})
+}
+
+// whereIn добавляет условие column IN (values); пустой список - фильтр не задан
+func whereIn[T any](query sq.SelectBuilder, column string, values []T) sq.SelectBuilder {
+	if len(values) == 0 {
+		return query
+	}
+	return query.Where(sq.Eq{column: values})
+}
+
+// whereFrom добавляет нижнюю границу column >= *value включительно
+func whereFrom[T any](query sq.SelectBuilder, column string, value *T) sq.SelectBuilder {
+	if value == nil {
+		return query
+	}
+	return query.Where(sq.GtOrEq{column: *value})
+}
+
+// whereBefore добавляет верхнюю границу column < *value не включительно
+func whereBefore[T any](query sq.SelectBuilder, column string, value *T) sq.SelectBuilder {
+	if value == nil {
+		return query
+	}
+	return query.Where(sq.Lt{column: *value})
+}
+
+// paginate применяет limit и offset; нулевые значения не ограничивают выборку
+func paginate(query sq.SelectBuilder, limit, offset int) sq.SelectBuilder {
+	if limit > 0 {
+		query = query.Limit(uint64(limit))
+	}
+	if offset > 0 {
+		query = query.Offset(uint64(offset))
+	}
+	return query
+}
+
+// countQuery превращает выборку в подсчет ее строк без учета пагинации.
+// Подзапрос сохраняет смысл DISTINCT и GROUP BY исходного запроса
+func countQuery(query sq.SelectBuilder) sq.SelectBuilder {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Question).
+		Select("COUNT(*)").
+		FromSelect(query.RemoveLimit().RemoveOffset(), "counted")
+}
+
+// count возвращает число строк выборки query
+func (c *core) count(ctx context.Context, query sq.SelectBuilder) (int, error) {
+	q, args, err := countQuery(query).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var n int
+	if err := c.db.GetContext(ctx, &n, q, args...); err != nil {
+		return 0, fmt.Errorf("db.GetContext: %w", err)
+	}
+	return n, nil
+}
+
+// upsertSuffix возвращает "ON CONFLICT(conflict) DO UPDATE SET ..." с заменой columns значениями из вставки
+func upsertSuffix(conflict string, columns ...string) string {
+	sets := make([]string, len(columns))
+	for i, column := range columns {
+		sets[i] = column + " = excluded." + column
+	}
+	return "ON CONFLICT(" + conflict + ") DO UPDATE SET " + strings.Join(sets, ", ")
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+func TestCriteriaHelpers(t *testing.T) {
+	status := "active"
+	var serverID *int64
+	after := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	query := sq.StatementBuilder.PlaceholderFormat(sq.Question).Select("id").From("subscriptions")
+	query = whereEq(query, "status", &status)
+	query = whereEq(query, "server_id", serverID)
+	query = whereIn(query, "tariff_id", []int64{1, 2})
+	query = whereIn(query, "user_id", []int64(nil))
+	query = whereFrom(query, "expires_at", &after)
+	query = whereBefore(query, "expires_at", (*time.Time)(nil))
+	query = paginate(query, 20, 40)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSQL := "SELECT id FROM subscriptions WHERE status = ? AND tariff_id IN (?,?) AND expires_at >= ? LIMIT 20 OFFSET 40"
+	if q != wantSQL {
+		t.Errorf("sql = %q, want %q", q, wantSQL)
+	}
+	if want := []any{"active", int64(1), int64(2), after}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+
+	q, args, err = countQuery(query).ToSql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSQL = "SELECT COUNT(*) FROM (SELECT id FROM subscriptions WHERE status = ? AND tariff_id IN (?,?) AND expires_at >= ?) AS counted"
+	if q != wantSQL || len(args) != 4 {
+		t.Errorf("count sql = %q (%d args), want %q", q, len(args), wantSQL)
+	}
+}
+
+func TestUpsertSuffix(t *testing.T) {
+	got := upsertSuffix("telegram_id", "reason", "banned_by")
+	want := "ON CONFLICT(telegram_id) DO UPDATE SET reason = excluded.reason, banned_by = excluded.banned_by"
+	if got != want {
+		t.Errorf("upsertSuffix() = %q, want %q", got, want)
+	}
+}
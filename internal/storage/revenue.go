@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/stories/payment"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// RevenueReport - оплаченные платежи за период [From, To) с разбивкой по тарифам и ассистентам.
+// Платеж за несколько подписок делится между ними поровну, поэтому суммы разбивок сходятся с Total
+type RevenueReport struct {
+	From          time.Time
+	To            time.Time
+	Total         float64
+	PaymentsCount int
+	ByTariff      []RevenueByTariff
+	ByAssistant   []RevenueByAssistant
+}
+
+// RevenueByTariff - выручка по тарифу; TariffID nil - платежи без подписки (например, докупка трафика без привязки)
+type RevenueByTariff struct {
+	TariffID      *int64  `db:"tariff_id"`
+	TariffName    string  `db:"tariff_name"`
+	Amount        float64 `db:"amount"`
+	PaymentsCount int     `db:"payments_count"`
+}
+
+// RevenueByAssistant - выручка по подпискам ассистента; AssistantTelegramID nil - создатель подписки неизвестен
+type RevenueByAssistant struct {
+	AssistantTelegramID *int64  `db:"assistant_telegram_id"`
+	Amount              float64 `db:"amount"`
+	PaymentsCount       int     `db:"payments_count"`
+}
+
+// revenueShare - доля платежа, приходящаяся на одну связанную подписку
+const revenueShare = "p.amount * 1.0 / MAX((SELECT COUNT(*) FROM " + paymentSubscriptionsTable + " x WHERE x.payment_id = p.id), 1)"
+
+// revenueQuery - оплаченные платежи периода с подписками, на которые они пришлись
+func (s *storageImpl) revenueQuery(from, to time.Time, columns ...string) sq.SelectBuilder {
+	query := s.stmpBuilder().
+		Select(columns...).
+		From(paymentsTable + " p").
+		LeftJoin(paymentSubscriptionsTable + " ps ON ps.payment_id = p.id").
+		LeftJoin(subscriptionsTable + " s ON s.id = ps.subscription_id").
+		Where(sq.Eq{"p.status": "approved"})
+	query = whereFrom(query, "p.created_at", &from)
+	return whereBefore(query, "p.created_at", &to)
+}
+
+// GetRevenueReport возвращает выручку за период [from, to) по тарифам и ассистентам
+func (s *storageImpl) GetRevenueReport(ctx context.Context, from, to time.Time) (*RevenueReport, error) {
+	report := &RevenueReport{From: from, To: to}
+
+	totalQuery := s.stmpBuilder().
+		Select("COALESCE(SUM(amount), 0) AS amount", "COUNT(*) AS payments_count").
+		From(paymentsTable).
+		Where(sq.Eq{"status": "approved"})
+	totalQuery = whereFrom(totalQuery, "created_at", &from)
+	totalQuery = whereBefore(totalQuery, "created_at", &to)
+
+	q, args, err := totalQuery.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+	var total struct {
+		Amount        float64 `db:"amount"`
+		PaymentsCount int     `db:"payments_count"`
+	}
+	if err := s.db.GetContext(ctx, &total, q, args...); err != nil {
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+	report.Total = total.Amount
+	report.PaymentsCount = total.PaymentsCount
+
+	byTariff := s.revenueQuery(from, to,
+		"s.tariff_id AS tariff_id",
+		"COALESCE(t.name, '') AS tariff_name",
+		"COALESCE(SUM("+revenueShare+"), 0) AS amount",
+		"COUNT(DISTINCT p.id) AS payments_count",
+	).
+		LeftJoin(tariffsTable + " t ON t.id = s.tariff_id").
+		GroupBy("s.tariff_id").
+		OrderBy("amount DESC")
+
+	q, args, err = byTariff.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+	if err := s.db.SelectContext(ctx, &report.ByTariff, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	byAssistant := s.revenueQuery(from, to,
+		"s.created_by_telegram_id AS assistant_telegram_id",
+		"COALESCE(SUM("+revenueShare+"), 0) AS amount",
+		"COUNT(DISTINCT p.id) AS payments_count",
+	).
+		GroupBy("s.created_by_telegram_id").
+		OrderBy("amount DESC")
+
+	q, args, err = byAssistant.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+	if err := s.db.SelectContext(ctx, &report.ByAssistant, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	return report, nil
+}
+
+// RefundsSummary - возвраты за период: оплаты отмененных заказов и возвраты по гарантии
+type RefundsSummary struct {
+	Amount float64 `db:"amount"`
+	Count  int     `db:"refunds_count"`
+}
+
+// GetRefundsSummary возвращает возвраты, которые ЮKassa приняла за период [from, to)
+func (s *storageImpl) GetRefundsSummary(ctx context.Context, from, to time.Time) (*RefundsSummary, error) {
+	query := s.stmpBuilder().
+		Select("COALESCE(SUM(amount), 0) AS amount", "COUNT(*) AS refunds_count").
+		From(paymentRefundsTable).
+		Where(sq.Eq{"status": string(payment.RefundSucceeded)})
+	query = whereFrom(query, "completed_at", &from)
+	query = whereBefore(query, "completed_at", &to)
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var summary RefundsSummary
+	if err := s.db.GetContext(ctx, &summary, q, args...); err != nil {
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+	return &summary, nil
+}
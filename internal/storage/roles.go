@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"kurut-bot/internal/stories/users"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// SetUserRole назначает роль пользователю по Telegram ID; пользователь создается, если еще не писал боту
+func (s *storageImpl) SetUserRole(ctx context.Context, telegramID int64, role users.Role) error {
+	q, args, err := s.stmpBuilder().
+		Insert(usersTable).
+		Columns("telegram_id", "language", "role", "created_at", "updated_at").
+		Values(telegramID, "ru", string(role), s.now(), s.now()).
+		Suffix("ON CONFLICT(telegram_id) DO UPDATE SET role = excluded.role, updated_at = excluded.updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// ListUserRoles возвращает назначенные роли: Telegram ID -> роль. Пользователи без роли не попадают
+func (s *storageImpl) ListUserRoles(ctx context.Context) (map[int64]users.Role, error) {
+	q, args, err := s.stmpBuilder().
+		Select("telegram_id", "role").
+		From(usersTable).
+		Where(sq.NotEq{"role": string(users.RoleNone)}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []struct {
+		TelegramID int64  `db:"telegram_id"`
+		Role       string `db:"role"`
+	}
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	roles := make(map[int64]users.Role, len(rows))
+	for _, row := range rows {
+		roles[row.TelegramID] = users.Role(row.Role)
+	}
+	return roles, nil
+}
@@ -16,32 +16,38 @@ const serversTable = "servers"
 var serverRowFields = fields(serverRow{})
 
 type serverRow struct {
-	ID           int64     `db:"id"`
-	Name         string    `db:"name"`
-	UIURL        string    `db:"ui_url"`
-	UIPassword   string    `db:"ui_password"`
-	CurrentUsers int       `db:"current_users"`
-	MaxUsers     int       `db:"max_users"`
-	Archived     bool      `db:"archived"`
-	CreatedAt    time.Time `db:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at"`
+	ID              int64     `db:"id"`
+	Name            string    `db:"name"`
+	UIURL           string    `db:"ui_url"`
+	UIPassword      string    `db:"ui_password"`
+	CurrentUsers    int       `db:"current_users"`
+	MaxUsers        int       `db:"max_users"`
+	Archived        bool      `db:"archived"`
+	PriceMultiplier float64   `db:"price_multiplier"`
+	PriceSurcharge  float64   `db:"price_surcharge"`
+	Cluster         string    `db:"cluster"`
+	CreatedAt       time.Time `db:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at"`
 }
 
 func (s serverRow) ToModel() *servers.Server {
 	return &servers.Server{
-		ID:           s.ID,
-		Name:         s.Name,
-		UIURL:        s.UIURL,
-		UIPassword:   s.UIPassword,
-		CurrentUsers: s.CurrentUsers,
-		MaxUsers:     s.MaxUsers,
-		Archived:     s.Archived,
-		CreatedAt:    s.CreatedAt,
-		UpdatedAt:    s.UpdatedAt,
+		ID:              s.ID,
+		Name:            s.Name,
+		UIURL:           s.UIURL,
+		UIPassword:      s.UIPassword,
+		CurrentUsers:    s.CurrentUsers,
+		MaxUsers:        s.MaxUsers,
+		Archived:        s.Archived,
+		PriceMultiplier: s.PriceMultiplier,
+		PriceSurcharge:  s.PriceSurcharge,
+		Cluster:         s.Cluster,
+		CreatedAt:       s.CreatedAt,
+		UpdatedAt:       s.UpdatedAt,
 	}
 }
 
-func (s *storageImpl) CreateServer(ctx context.Context, server servers.Server) (*servers.Server, error) {
+func (s *ServersRepo) CreateServer(ctx context.Context, server servers.Server) (*servers.Server, error) {
 	params := map[string]interface{}{
 		"name":          server.Name,
 		"ui_url":        server.UIURL,
@@ -49,6 +55,7 @@ func (s *storageImpl) CreateServer(ctx context.Context, server servers.Server) (
 		"current_users": server.CurrentUsers,
 		"max_users":     server.MaxUsers,
 		"archived":      server.Archived,
+		"cluster":       server.Cluster,
 		"created_at":    s.now(),
 		"updated_at":    s.now(),
 	}
@@ -74,7 +81,7 @@ func (s *storageImpl) CreateServer(ctx context.Context, server servers.Server) (
 	return s.GetServer(ctx, servers.GetCriteria{ID: &id})
 }
 
-func (s *storageImpl) GetServer(ctx context.Context, criteria servers.GetCriteria) (*servers.Server, error) {
+func (s *ServersRepo) GetServer(ctx context.Context, criteria servers.GetCriteria) (*servers.Server, error) {
 	query := s.stmpBuilder().
 		Select(serverRowFields).
 		From(serversTable).
@@ -104,21 +111,13 @@ func (s *storageImpl) GetServer(ctx context.Context, criteria servers.GetCriteri
 	return srv.ToModel(), nil
 }
 
-func (s *storageImpl) ListServers(ctx context.Context, criteria servers.ListCriteria) ([]*servers.Server, error) {
+func (s *ServersRepo) ListServers(ctx context.Context, criteria servers.ListCriteria) ([]*servers.Server, error) {
 	query := s.stmpBuilder().
 		Select(serverRowFields).
 		From(serversTable)
 
-	if criteria.Archived != nil {
-		query = query.Where(sq.Eq{"archived": *criteria.Archived})
-	}
-
-	if criteria.Limit > 0 {
-		query = query.Limit(uint64(criteria.Limit))
-	}
-	if criteria.Offset > 0 {
-		query = query.Offset(uint64(criteria.Offset))
-	}
+	query = whereEq(query, "archived", criteria.Archived)
+	query = paginate(query, criteria.Limit, criteria.Offset)
 
 	query = query.OrderBy("created_at ASC")
 
@@ -141,7 +140,7 @@ func (s *storageImpl) ListServers(ctx context.Context, criteria servers.ListCrit
 	return result, nil
 }
 
-func (s *storageImpl) UpdateServer(ctx context.Context, criteria servers.GetCriteria, params servers.UpdateParams) (*servers.Server, error) {
+func (s *ServersRepo) UpdateServer(ctx context.Context, criteria servers.GetCriteria, params servers.UpdateParams) (*servers.Server, error) {
 	query := s.stmpBuilder().
 		Update(serversTable).
 		Set("updated_at", s.now())
@@ -168,6 +167,15 @@ func (s *storageImpl) UpdateServer(ctx context.Context, criteria servers.GetCrit
 	if params.Archived != nil {
 		query = query.Set("archived", *params.Archived)
 	}
+	if params.PriceMultiplier != nil {
+		query = query.Set("price_multiplier", *params.PriceMultiplier)
+	}
+	if params.PriceSurcharge != nil {
+		query = query.Set("price_surcharge", *params.PriceSurcharge)
+	}
+	if params.Cluster != nil {
+		query = query.Set("cluster", *params.Cluster)
+	}
 
 	q, args, err := query.ToSql()
 	if err != nil {
@@ -183,13 +191,17 @@ func (s *storageImpl) UpdateServer(ctx context.Context, criteria servers.GetCrit
 }
 
 // GetAvailableServer returns a server with available capacity (not archived, active users < max_users)
-// Counts active subscriptions dynamically instead of using current_users field
-func (s *storageImpl) GetAvailableServer(ctx context.Context) (*servers.Server, error) {
+// Counts active subscriptions dynamically instead of using current_users field.
+// cluster ограничивает выбор серверами кластера; пустая строка - любой сервер
+func (s *ServersRepo) GetAvailableServer(ctx context.Context, cluster string) (*servers.Server, error) {
 	// Получаем все неархивированные серверы
 	query := s.stmpBuilder().
 		Select(serverRowFields).
 		From(serversTable).
 		Where(sq.Eq{"archived": false})
+	if cluster != "" {
+		query = query.Where(sq.Eq{"cluster": cluster})
+	}
 
 	q, args, err := query.ToSql()
 	if err != nil {
@@ -245,7 +257,7 @@ func (s *storageImpl) GetAvailableServer(ctx context.Context) (*servers.Server,
 }
 
 // IncrementServerUsers увеличивает счетчик пользователей на сервере
-func (s *storageImpl) IncrementServerUsers(ctx context.Context, serverID int64) error {
+func (s *ServersRepo) IncrementServerUsers(ctx context.Context, serverID int64) error {
 	q, args, err := s.stmpBuilder().
 		Update(serversTable).
 		Set("current_users", sq.Expr("current_users + 1")).
@@ -265,7 +277,7 @@ func (s *storageImpl) IncrementServerUsers(ctx context.Context, serverID int64)
 }
 
 // DecrementServerUsers уменьшает счетчик пользователей на сервере
-func (s *storageImpl) DecrementServerUsers(ctx context.Context, serverID int64) error {
+func (s *ServersRepo) DecrementServerUsers(ctx context.Context, serverID int64) error {
 	q, args, err := s.stmpBuilder().
 		Update(serversTable).
 		Set("current_users", sq.Expr("current_users - 1")).
@@ -286,12 +298,12 @@ func (s *storageImpl) DecrementServerUsers(ctx context.Context, serverID int64)
 }
 
 // GetServerByID возвращает сервер по ID (упрощённая обёртка над GetServer)
-func (s *storageImpl) GetServerByID(ctx context.Context, serverID int64) (*servers.Server, error) {
+func (s *ServersRepo) GetServerByID(ctx context.Context, serverID int64) (*servers.Server, error) {
 	return s.GetServer(ctx, servers.GetCriteria{ID: &serverID})
 }
 
 // GetActiveUsersCountByServer возвращает количество активных подписок на сервере
-func (s *storageImpl) GetActiveUsersCountByServer(ctx context.Context, serverID int64) (int, error) {
+func (s *ServersRepo) GetActiveUsersCountByServer(ctx context.Context, serverID int64) (int, error) {
 	query := `
 		SELECT COUNT(*)
 		FROM subscriptions
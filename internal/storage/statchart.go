@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/stories/statchart"
+)
+
+// GetStatsChart возвращает новые подписки по дням и выручку по неделям для графика /stats
+func (s *storageImpl) GetStatsChart(ctx context.Context) (*statchart.Chart, error) {
+	now := s.now()
+	period := statchart.NewChart(now)
+
+	var created []time.Time
+	err := s.db.SelectContext(ctx, &created, `
+		SELECT created_at
+		FROM subscriptions
+		WHERE created_at >= ?
+	`, period.Start())
+	if err != nil {
+		return nil, fmt.Errorf("select subscriptions: %w", err)
+	}
+
+	var payments []statchart.Payment
+	err = s.db.SelectContext(ctx, &payments, `
+		SELECT created_at, amount
+		FROM payments
+		WHERE status = 'approved'
+		  AND created_at >= ?
+	`, period.RevenueStart())
+	if err != nil {
+		return nil, fmt.Errorf("select payments: %w", err)
+	}
+
+	return statchart.Build(now, created, payments), nil
+}
@@ -6,6 +6,9 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/drip"
+	"kurut-bot/internal/stories/flowcancel"
 )
 
 type TariffStats struct {
@@ -26,6 +29,8 @@ type StatisticsData struct {
 	TodayRevenue             float64
 	YesterdayRevenue         float64
 	AverageRevenuePerDay     float64
+	// CurrentMonthServerSurcharge - часть выручки за месяц, пришедшаяся на наценки серверов
+	CurrentMonthServerSurcharge float64
 }
 
 func (s *storageImpl) GetActiveSubscriptionsCount(ctx context.Context) (int, error) {
@@ -160,6 +165,33 @@ func (s *storageImpl) GetRevenueForMonth(ctx context.Context, year int, month ti
 	return revenue, nil
 }
 
+// GetServerSurchargeForMonth возвращает сумму наценок серверов в оплаченных платежах за месяц
+func (s *storageImpl) GetServerSurchargeForMonth(ctx context.Context, year int, month time.Month) (float64, error) {
+	startDate := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 1, 0)
+
+	query := s.stmpBuilder().
+		Select("COALESCE(SUM(amount - base_amount), 0)").
+		From(paymentsTable).
+		Where(sq.Eq{"status": "approved"}).
+		Where(sq.NotEq{"base_amount": nil}).
+		Where(sq.GtOrEq{"created_at": startDate}).
+		Where(sq.Lt{"created_at": endDate})
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var surcharge float64
+	err = s.db.GetContext(ctx, &surcharge, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return surcharge, nil
+}
+
 func (s *storageImpl) GetRevenueForDay(ctx context.Context, date time.Time) (float64, error) {
 	startDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
 	endDate := startDate.AddDate(0, 0, 1)
@@ -185,6 +217,31 @@ func (s *storageImpl) GetRevenueForDay(ctx context.Context, date time.Time) (flo
 	return revenue, nil
 }
 
+// GetAssistantRevenue возвращает сумму оплаченных платежей за подписки ассистента за период [from, to).
+// Платеж за несколько подписок учитывается один раз
+func (s *storageImpl) GetAssistantRevenue(ctx context.Context, assistantTelegramID int64, from, to time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(p.amount), 0)
+		FROM ` + paymentsTable + ` p
+		WHERE p.status = 'approved'
+		AND p.created_at >= ?
+		AND p.created_at < ?
+		AND EXISTS (
+			SELECT 1 FROM ` + paymentSubscriptionsTable + ` ps
+			JOIN ` + subscriptionsTable + ` s ON s.id = ps.subscription_id
+			WHERE ps.payment_id = p.id AND s.created_by_telegram_id = ?
+		)
+	`
+
+	var revenue float64
+	err := s.db.GetContext(ctx, &revenue, query, from, to, assistantTelegramID)
+	if err != nil {
+		return 0, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return revenue, nil
+}
+
 func (s *storageImpl) GetStatistics(ctx context.Context) (*StatisticsData, error) {
 	now := s.now()
 	currentYear, currentMonth, _ := now.Date()
@@ -235,6 +292,11 @@ func (s *storageImpl) GetStatistics(ctx context.Context) (*StatisticsData, error
 		return nil, fmt.Errorf("get current month revenue: %w", err)
 	}
 
+	currentMonthSurcharge, err := s.GetServerSurchargeForMonth(ctx, currentYear, currentMonth)
+	if err != nil {
+		return nil, fmt.Errorf("get current month server surcharge: %w", err)
+	}
+
 	todayRevenue, err := s.GetRevenueForDay(ctx, now)
 	if err != nil {
 		return nil, fmt.Errorf("get today revenue: %w", err)
@@ -264,6 +326,8 @@ func (s *storageImpl) GetStatistics(ctx context.Context) (*StatisticsData, error
 		TodayRevenue:             todayRevenue,
 		YesterdayRevenue:         yesterdayRevenue,
 		AverageRevenuePerDay:     averageRevenuePerDay,
+
+		CurrentMonthServerSurcharge: currentMonthSurcharge,
 	}, nil
 }
 
@@ -287,6 +351,11 @@ type CustomerAnalytics struct {
 	ARPU                float64
 	TrialConversionRate float64
 	RevenueByTariff     []TariffRevenue
+
+	TrialDrip        []drip.StepStats // результаты рассылки пробным клиентам по этапам
+	TrialDripOptOuts int              // клиенты, отказавшиеся от рассылки
+
+	CancelReasons []flowcancel.ReasonStats // отмены флоу по причинам за flowcancel.StatsWindow
 }
 
 // TariffRevenue represents revenue data for a specific tariff
@@ -371,6 +440,20 @@ func (s *storageImpl) GetCustomerAnalytics(ctx context.Context) (*CustomerAnalyt
 		return nil, fmt.Errorf("get trial conversion rate: %w", err)
 	}
 
+	analytics.TrialDrip, err = s.GetDripStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get drip stats: %w", err)
+	}
+	analytics.TrialDripOptOuts, err = s.CountDripOptOuts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("count drip opt-outs: %w", err)
+	}
+
+	analytics.CancelReasons, err = s.GetCancelReasonStats(ctx, now.Add(-flowcancel.StatsWindow))
+	if err != nil {
+		return nil, fmt.Errorf("get cancel reason stats: %w", err)
+	}
+
 	return analytics, nil
 }
 
@@ -403,7 +486,7 @@ func (s *storageImpl) GetNewCustomersCount(ctx context.Context, start, end time.
 
 // GetRenewalAndChurnStats returns renewal and churn statistics for mature subscriptions (30+ days old)
 // - renewed: subscriptions with renewal_count > 0 (client renewed at least once)
-// - churned: subscriptions with status 'disabled' and renewal_count = 0 (client left and was disabled)
+// - churned: subscriptions with status 'disabled' or 'archived' and renewal_count = 0 (client left)
 // - pendingDisable: subscriptions with status 'expired' and renewal_count = 0 (awaiting disable action)
 func (s *storageImpl) GetRenewalAndChurnStats(ctx context.Context) (renewed, churned, pendingDisable, total int, err error) {
 	now := s.now()
@@ -412,7 +495,7 @@ func (s *storageImpl) GetRenewalAndChurnStats(ctx context.Context) (renewed, chu
 	query := `
 		SELECT
 			COUNT(CASE WHEN s.renewal_count > 0 THEN 1 END) as renewed,
-			COUNT(CASE WHEN s.status = 'disabled' AND s.renewal_count = 0 THEN 1 END) as churned,
+			COUNT(CASE WHEN s.status IN ('disabled', 'archived') AND s.renewal_count = 0 THEN 1 END) as churned,
 			COUNT(CASE WHEN s.status = 'expired' AND s.renewal_count = 0 THEN 1 END) as pending_disable,
 			COUNT(*) as total
 		FROM subscriptions s
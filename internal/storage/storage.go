@@ -1,24 +1,83 @@
 package storage
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
 )
 
-type storageImpl struct {
+// core - общее для всех репозиториев: подключение к БД, часы и построитель запросов
+type core struct {
 	db  *sqlx.DB
 	now func() time.Time
 }
 
-func New(db *sqlx.DB) *storageImpl {
-	return &storageImpl{db: db, now: func() time.Time { return time.Now().UTC() }}
+func (c *core) stmpBuilder() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Question)
 }
 
-func (s *storageImpl) stmpBuilder() sq.StatementBuilderType {
-	return sq.StatementBuilder.PlaceholderFormat(sq.Question)
+// withTx выполняет fn в транзакции: коммит, если fn вернула nil, иначе откат
+func (c *core) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := c.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("db.BeginTxx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+	return nil
+}
+
+// isUniqueViolation - ошибка нарушения UNIQUE индекса SQLite
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+// SubscriptionsRepo - подписки
+type SubscriptionsRepo struct{ *core }
+
+// PaymentsRepo - платежи и их связи с подписками
+type PaymentsRepo struct{ *core }
+
+// ServersRepo - VPN-серверы
+type ServersRepo struct{ *core }
+
+// OrdersRepo - заказы, ожидающие оплаты
+type OrdersRepo struct{ *core }
+
+// storageImpl собирает репозитории доменов и реализует остальные таблицы.
+// Сервисам, которым нужен один домен, лучше передавать его репозиторий: storage.PaymentsRepo и т.п.
+type storageImpl struct {
+	*core
+	*SubscriptionsRepo
+	*PaymentsRepo
+	*ServersRepo
+	*OrdersRepo
+}
+
+func New(db *sqlx.DB) *storageImpl {
+	c := &core{db: db, now: func() time.Time { return time.Now().UTC() }}
+	return &storageImpl{
+		core:              c,
+		SubscriptionsRepo: &SubscriptionsRepo{core: c},
+		PaymentsRepo:      &PaymentsRepo{core: c},
+		ServersRepo:       &ServersRepo{core: c},
+		OrdersRepo:        &OrdersRepo{core: c},
+	}
 }
 
 // Fields возвращает список всех полей структуры, которые есть в БД.
@@ -34,15 +93,15 @@ func fields(data any) string {
 	return s[:len(s)-1]
 }
 
-// prefixWithTable добавляет префикс таблицы к полям - пока не используется, но пригодится для JOIN запросов
-// func prefixWithTable(prefix string, fields string) string {
-// 	strs := strings.Split(fields, ",")
-//
-// 	var strBuilder strings.Builder
-// 	strBuilder.Grow(len(fields) + len(strs)*(len(prefix)+1))
-// 	for i := 0; i < len(strs); i++ {
-// 		strBuilder.WriteString(fmt.Sprintf("%s.%s,", prefix, strs[i]))
-// 	}
-// 	s := strBuilder.String()
-// 	return s[:len(s)-1]
-// }
+// prefixWithTable добавляет префикс таблицы к полям для JOIN запросов
+func prefixWithTable(prefix string, fields string) string {
+	strs := strings.Split(fields, ",")
+
+	var strBuilder strings.Builder
+	strBuilder.Grow(len(fields) + len(strs)*(len(prefix)+1))
+	for i := 0; i < len(strs); i++ {
+		strBuilder.WriteString(fmt.Sprintf("%s.%s,", prefix, strs[i]))
+	}
+	s := strBuilder.String()
+	return s[:len(s)-1]
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"kurut-bot/internal/stories/subs"
+)
+
+// CancelSubscription отменяет активную подписку и освобождает место в счетчике current_users ее сервера.
+// false - подписка уже не активна (истекла или ее отменили параллельно), счетчик не меняется
+func (s *storageImpl) CancelSubscription(ctx context.Context, subscriptionID int64) (bool, error) {
+	now := s.now()
+	var cancelled bool
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		q, args, err := s.stmpBuilder().
+			Update(subscriptionsTable).
+			Set("status", string(subs.StatusCancelled)).
+			Set("updated_at", now).
+			Where(sq.Eq{"id": subscriptionID, "status": string(subs.StatusActive)}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql query: %w", err)
+		}
+		result, err := tx.ExecContext(ctx, q, args...)
+		if err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("result.RowsAffected: %w", err)
+		}
+		if affected == 0 {
+			return nil
+		}
+
+		q, args, err = s.stmpBuilder().
+			Update(serversTable).
+			Set("current_users", sq.Expr("current_users - 1")).
+			Set("updated_at", now).
+			Where(sq.Expr("id = (SELECT server_id FROM subscriptions WHERE id = ?)", subscriptionID)).
+			Where("current_users > 0").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql query: %w", err)
+		}
+		if _, err = tx.ExecContext(ctx, q, args...); err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+
+		cancelled = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return cancelled, nil
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"kurut-bot/internal/stories/subs"
+)
+
+const subscriptionPhoneChangesTable = "subscription_phone_changes"
+
+var phoneChangeRowFields = fields(phoneChangeRow{})
+
+type phoneChangeRow struct {
+	ID                  int64     `db:"id"`
+	SubscriptionID      int64     `db:"subscription_id"`
+	OldWhatsApp         string    `db:"old_whatsapp"`
+	NewWhatsApp         string    `db:"new_whatsapp"`
+	ChangedByTelegramID int64     `db:"changed_by_telegram_id"`
+	CreatedAt           time.Time `db:"created_at"`
+}
+
+func (r phoneChangeRow) ToModel() *subs.PhoneChange {
+	return &subs.PhoneChange{
+		ID:                  r.ID,
+		SubscriptionID:      r.SubscriptionID,
+		OldWhatsApp:         r.OldWhatsApp,
+		NewWhatsApp:         r.NewWhatsApp,
+		ChangedByTelegramID: r.ChangedByTelegramID,
+		CreatedAt:           r.CreatedAt,
+	}
+}
+
+// ChangeSubscriptionWhatsApp меняет номер клиента в подписке и записывает старый номер в историю.
+// Номер сохраняется нормализованным, как и при создании подписки
+func (s *storageImpl) ChangeSubscriptionWhatsApp(ctx context.Context, change subs.PhoneChange) error {
+	now := s.now()
+	newWhatsApp := NormalizePhone(change.NewWhatsApp)
+
+	return s.withTx(ctx, func(tx *sqlx.Tx) error {
+		q, args, err := s.stmpBuilder().
+			Update(subscriptionsTable).
+			Set("client_whatsapp", newWhatsApp).
+			Set("updated_at", now).
+			Where(sq.Eq{"id": change.SubscriptionID}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql query: %w", err)
+		}
+		if _, err = tx.ExecContext(ctx, q, args...); err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+
+		q, args, err = s.stmpBuilder().
+			Insert(subscriptionPhoneChangesTable).
+			Columns("subscription_id", "old_whatsapp", "new_whatsapp", "changed_by_telegram_id", "created_at").
+			Values(change.SubscriptionID, change.OldWhatsApp, newWhatsApp, change.ChangedByTelegramID, now).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql query: %w", err)
+		}
+		if _, err = tx.ExecContext(ctx, q, args...); err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListSubscriptionPhoneChanges возвращает смены номера в подписке, последние первыми
+func (s *storageImpl) ListSubscriptionPhoneChanges(ctx context.Context, subscriptionID int64) ([]*subs.PhoneChange, error) {
+	q, args, err := s.stmpBuilder().
+		Select(phoneChangeRowFields).
+		From(subscriptionPhoneChangesTable).
+		Where(sq.Eq{"subscription_id": subscriptionID}).
+		OrderBy("created_at DESC", "id DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []phoneChangeRow
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*subs.PhoneChange, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+	return result, nil
+}
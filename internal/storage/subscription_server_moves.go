@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"kurut-bot/internal/stories/subs"
+)
+
+// MoveSubscriptionServer переносит активную подписку с сервера fromServerID (nil - без сервера) на toServerID
+// и переносит клиента в счетчиках current_users. false - подписка уже не активна или уже на другом сервере
+func (s *storageImpl) MoveSubscriptionServer(ctx context.Context, subscriptionID int64, fromServerID *int64, toServerID int64) (bool, error) {
+	now := s.now()
+	var moved bool
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		q, args, err := s.stmpBuilder().
+			Update(subscriptionsTable).
+			Set("server_id", toServerID).
+			Set("updated_at", now).
+			Where(sq.Eq{"id": subscriptionID, "status": subs.StatusActive, "server_id": fromServerID}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql query: %w", err)
+		}
+		result, err := tx.ExecContext(ctx, q, args...)
+		if err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("result.RowsAffected: %w", err)
+		}
+		if affected == 0 {
+			return nil
+		}
+
+		if fromServerID != nil {
+			q, args, err = s.stmpBuilder().
+				Update(serversTable).
+				Set("current_users", sq.Expr("current_users - 1")).
+				Set("updated_at", now).
+				Where(sq.Eq{"id": *fromServerID}).
+				Where("current_users > 0").
+				ToSql()
+			if err != nil {
+				return fmt.Errorf("build sql query: %w", err)
+			}
+			if _, err = tx.ExecContext(ctx, q, args...); err != nil {
+				return fmt.Errorf("tx.ExecContext: %w", err)
+			}
+		}
+
+		q, args, err = s.stmpBuilder().
+			Update(serversTable).
+			Set("current_users", sq.Expr("current_users + 1")).
+			Set("updated_at", now).
+			Where(sq.Eq{"id": toServerID}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql query: %w", err)
+		}
+		if _, err = tx.ExecContext(ctx, q, args...); err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+
+		moved = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return moved, nil
+}
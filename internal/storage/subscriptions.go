@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"kurut-bot/internal/stories/devices"
 	"kurut-bot/internal/stories/subs"
 
 	sq "github.com/Masterminds/squirrel"
@@ -30,6 +31,12 @@ type subscriptionRow struct {
 	ExpiresAt           *time.Time `db:"expires_at"`
 	LastRenewedAt       *time.Time `db:"last_renewed_at"`
 	RenewalCount        int        `db:"renewal_count"`
+	ExtraTrafficGB      int        `db:"extra_traffic_gb"`
+	CustomPrice         *float64   `db:"custom_price"`
+	ClientPlatform      string     `db:"client_platform"`
+	Note                string     `db:"note"`
+	PausedAt            *time.Time `db:"paused_at"`
+	PausedUntil         *time.Time `db:"paused_until"`
 	CreatedAt           time.Time  `db:"created_at"`
 	UpdatedAt           time.Time  `db:"updated_at"`
 }
@@ -49,12 +56,18 @@ func (s subscriptionRow) ToModel() *subs.Subscription {
 		ExpiresAt:           s.ExpiresAt,
 		LastRenewedAt:       s.LastRenewedAt,
 		RenewalCount:        s.RenewalCount,
+		ExtraTrafficGB:      s.ExtraTrafficGB,
+		CustomPrice:         s.CustomPrice,
+		ClientPlatform:      devices.Platform(s.ClientPlatform),
+		Note:                s.Note,
+		PausedAt:            s.PausedAt,
+		PausedUntil:         s.PausedUntil,
 		CreatedAt:           s.CreatedAt,
 		UpdatedAt:           s.UpdatedAt,
 	}
 }
 
-func (s *storageImpl) CreateSubscription(ctx context.Context, subscription subs.Subscription) (*subs.Subscription, error) {
+func (s *SubscriptionsRepo) CreateSubscription(ctx context.Context, subscription subs.Subscription) (*subs.Subscription, error) {
 	now := s.now()
 
 	params := map[string]interface{}{
@@ -94,7 +107,7 @@ func (s *storageImpl) CreateSubscription(ctx context.Context, subscription subs.
 	return s.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{id}})
 }
 
-func (s *storageImpl) GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error) {
+func (s *SubscriptionsRepo) GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error) {
 	query := s.stmpBuilder().
 		Select(subscriptionRowFields).
 		From(subscriptionsTable).
@@ -124,32 +137,24 @@ func (s *storageImpl) GetSubscription(ctx context.Context, criteria subs.GetCrit
 	return sub.ToModel(), nil
 }
 
-func (s *storageImpl) ListSubscriptions(ctx context.Context, criteria subs.ListCriteria) ([]*subs.Subscription, error) {
+func (s *SubscriptionsRepo) ListSubscriptions(ctx context.Context, criteria subs.ListCriteria) ([]*subs.Subscription, error) {
 	query := s.stmpBuilder().
 		Select(subscriptionRowFields).
 		From(subscriptionsTable)
 
-	if len(criteria.UserIDs) > 0 {
-		query = query.Where(sq.Eq{"user_id": criteria.UserIDs})
-	}
-	if len(criteria.TariffIDs) > 0 {
-		query = query.Where(sq.Eq{"tariff_id": criteria.TariffIDs})
-	}
-	if len(criteria.Status) > 0 {
-		query = query.Where(sq.Eq{"status": criteria.Status})
-	}
-	if criteria.CreatedByTelegramID != nil {
-		query = query.Where(sq.Eq{"created_by_telegram_id": *criteria.CreatedByTelegramID})
-	}
+	query = whereIn(query, "user_id", criteria.UserIDs)
+	query = whereIn(query, "tariff_id", criteria.TariffIDs)
+	query = whereIn(query, "status", criteria.Status)
+	query = whereEq(query, "created_by_telegram_id", criteria.CreatedByTelegramID)
+	query = whereIn(query, "server_id", criteria.ServerIDs)
+	query = whereFrom(query, "expires_at", criteria.ExpiresAfter)
+	query = whereBefore(query, "expires_at", criteria.ExpiresBefore)
+	query = whereFrom(query, "created_at", criteria.CreatedAfter)
+	query = whereBefore(query, "created_at", criteria.CreatedBefore)
+	query = paginate(query, criteria.Limit, criteria.Offset)
 
-	if criteria.Limit > 0 {
-		query = query.Limit(uint64(criteria.Limit))
-	}
-	if criteria.Offset > 0 {
-		query = query.Offset(uint64(criteria.Offset))
-	}
-
-	query = query.OrderBy("created_at DESC")
+	// id - для стабильного порядка при постраничной выборке
+	query = query.OrderBy("created_at DESC", "id DESC")
 
 	q, args, err := query.ToSql()
 	if err != nil {
@@ -171,9 +176,14 @@ func (s *storageImpl) ListSubscriptions(ctx context.Context, criteria subs.ListC
 }
 
 // ListExpiringSubscriptions returns active subscriptions expiring in specified number of days
-func (s *storageImpl) ListExpiringSubscriptions(ctx context.Context, daysUntilExpiry int) ([]*subs.Subscription, error) {
-	// Calculate time window: from now+days to now+days+24h
-	startTime := s.now().AddDate(0, 0, daysUntilExpiry)
+func (s *SubscriptionsRepo) ListExpiringSubscriptions(ctx context.Context, daysUntilExpiry int) ([]*subs.Subscription, error) {
+	return s.listExpiringFrom(ctx, s.now(), daysUntilExpiry)
+}
+
+// listExpiringFrom returns active subscriptions expiring in the 24h window starting at from+days
+func (s *SubscriptionsRepo) listExpiringFrom(ctx context.Context, from time.Time, daysUntilExpiry int) ([]*subs.Subscription, error) {
+	// Calculate time window: from from+days to from+days+24h
+	startTime := from.AddDate(0, 0, daysUntilExpiry)
 	endTime := startTime.Add(24 * time.Hour)
 
 	query := s.stmpBuilder().
@@ -204,7 +214,7 @@ func (s *storageImpl) ListExpiringSubscriptions(ctx context.Context, daysUntilEx
 }
 
 // ListExpiredSubscriptions returns active subscriptions that have expired
-func (s *storageImpl) ListExpiredSubscriptions(ctx context.Context) ([]*subs.Subscription, error) {
+func (s *SubscriptionsRepo) ListExpiredSubscriptions(ctx context.Context) ([]*subs.Subscription, error) {
 	now := s.now()
 
 	query := s.stmpBuilder().
@@ -234,7 +244,7 @@ func (s *storageImpl) ListExpiredSubscriptions(ctx context.Context) ([]*subs.Sub
 }
 
 // ExtendSubscription extends subscription by adding days to expires_at
-func (s *storageImpl) ExtendSubscription(ctx context.Context, subscriptionID int64, additionalDays int) error {
+func (s *SubscriptionsRepo) ExtendSubscription(ctx context.Context, subscriptionID int64, additionalDays int) error {
 	// First, get the current subscription to get expires_at
 	criteria := subs.GetCriteria{IDs: []int64{subscriptionID}}
 	subscription, err := s.GetSubscription(ctx, criteria)
@@ -278,7 +288,7 @@ func (s *storageImpl) ExtendSubscription(ctx context.Context, subscriptionID int
 }
 
 // UpdateSubscription updates subscription fields based on criteria
-func (s *storageImpl) UpdateSubscription(ctx context.Context, criteria subs.GetCriteria, params subs.UpdateParams) (*subs.Subscription, error) {
+func (s *SubscriptionsRepo) UpdateSubscription(ctx context.Context, criteria subs.GetCriteria, params subs.UpdateParams) (*subs.Subscription, error) {
 	updateMap := map[string]interface{}{
 		"updated_at": s.now(),
 	}
@@ -292,6 +302,12 @@ func (s *storageImpl) UpdateSubscription(ctx context.Context, criteria subs.GetC
 	if params.ExpiresAt != nil {
 		updateMap["expires_at"] = *params.ExpiresAt
 	}
+	if params.ClientWhatsApp != nil {
+		updateMap["client_whatsapp"] = *params.ClientWhatsApp
+	}
+	if params.ServerID != nil {
+		updateMap["server_id"] = *params.ServerID
+	}
 
 	query := s.stmpBuilder().
 		Update(subscriptionsTable).
@@ -317,8 +333,9 @@ func (s *storageImpl) UpdateSubscription(ctx context.Context, criteria subs.GetC
 	return s.GetSubscription(ctx, criteria)
 }
 
-// UpdateSubscriptionGeneratedUserID updates the generated_user_id field
-func (s *storageImpl) UpdateSubscriptionGeneratedUserID(ctx context.Context, subscriptionID int64, generatedUserID string) error {
+// UpdateSubscriptionGeneratedUserID updates the generated_user_id field.
+// Returns subs.ErrGeneratedUserIDTaken if another subscription already has this id
+func (s *SubscriptionsRepo) UpdateSubscriptionGeneratedUserID(ctx context.Context, subscriptionID int64, generatedUserID string) error {
 	params := map[string]interface{}{
 		"generated_user_id": generatedUserID,
 		"updated_at":        s.now(),
@@ -334,6 +351,9 @@ func (s *storageImpl) UpdateSubscriptionGeneratedUserID(ctx context.Context, sub
 	}
 
 	_, err = s.db.ExecContext(ctx, q, args...)
+	if isUniqueViolation(err) {
+		return subs.ErrGeneratedUserIDTaken
+	}
 	if err != nil {
 		return fmt.Errorf("db.ExecContext: %w", err)
 	}
@@ -342,7 +362,7 @@ func (s *storageImpl) UpdateSubscriptionGeneratedUserID(ctx context.Context, sub
 }
 
 // ListExpiringTodayGroupedByAssistant returns subscriptions expiring today grouped by assistant telegram ID
-func (s *storageImpl) ListExpiringTodayGroupedByAssistant(ctx context.Context) (map[int64][]*subs.Subscription, error) {
+func (s *SubscriptionsRepo) ListExpiringTodayGroupedByAssistant(ctx context.Context) (map[int64][]*subs.Subscription, error) {
 	subscriptions, err := s.ListExpiringSubscriptions(ctx, 0)
 	if err != nil {
 		return nil, err
@@ -359,8 +379,14 @@ func (s *storageImpl) ListExpiringTodayGroupedByAssistant(ctx context.Context) (
 }
 
 // ListExpiringByAssistantAndDays returns subscriptions expiring in N days grouped by assistant telegram ID
-func (s *storageImpl) ListExpiringByAssistantAndDays(ctx context.Context, daysUntilExpiry int) (map[int64][]*subs.Subscription, error) {
-	subscriptions, err := s.ListExpiringSubscriptions(ctx, daysUntilExpiry)
+func (s *SubscriptionsRepo) ListExpiringByAssistantAndDays(ctx context.Context, daysUntilExpiry int) (map[int64][]*subs.Subscription, error) {
+	return s.ListExpiringByAssistantAndDaysFrom(ctx, s.now(), daysUntilExpiry)
+}
+
+// ListExpiringByAssistantAndDaysFrom is ListExpiringByAssistantAndDays with the window counted from a fixed time
+// instead of now, so hourly runs during one day select the same subscriptions
+func (s *SubscriptionsRepo) ListExpiringByAssistantAndDaysFrom(ctx context.Context, from time.Time, daysUntilExpiry int) (map[int64][]*subs.Subscription, error) {
+	subscriptions, err := s.listExpiringFrom(ctx, from.UTC(), daysUntilExpiry)
 	if err != nil {
 		return nil, err
 	}
@@ -376,7 +402,7 @@ func (s *storageImpl) ListExpiringByAssistantAndDays(ctx context.Context, daysUn
 }
 
 // ListOverdueSubscriptionsGroupedByAssistant returns expired subscriptions grouped by assistant telegram ID
-func (s *storageImpl) ListOverdueSubscriptionsGroupedByAssistant(ctx context.Context) (map[int64][]*subs.Subscription, error) {
+func (s *SubscriptionsRepo) ListOverdueSubscriptionsGroupedByAssistant(ctx context.Context) (map[int64][]*subs.Subscription, error) {
 	now := s.now()
 
 	query := s.stmpBuilder().
@@ -410,7 +436,7 @@ func (s *storageImpl) ListOverdueSubscriptionsGroupedByAssistant(ctx context.Con
 
 // ListStaleExpiredSubscriptionsGroupedByAssistant returns expired subscriptions that have been expired for more than 24 hours
 // These are subscriptions that need to be disabled but haven't been yet
-func (s *storageImpl) ListStaleExpiredSubscriptionsGroupedByAssistant(ctx context.Context) (map[int64][]*subs.Subscription, error) {
+func (s *SubscriptionsRepo) ListStaleExpiredSubscriptionsGroupedByAssistant(ctx context.Context) (map[int64][]*subs.Subscription, error) {
 	now := s.now()
 	staleThreshold := now.Add(-24 * time.Hour) // expired more than 24 hours ago
 
@@ -453,7 +479,7 @@ type AssistantStats struct {
 }
 
 // GetAssistantStats returns subscription statistics for an assistant
-func (s *storageImpl) GetAssistantStats(ctx context.Context, assistantTelegramID int64) (*AssistantStats, error) {
+func (s *SubscriptionsRepo) GetAssistantStats(ctx context.Context, assistantTelegramID int64) (*AssistantStats, error) {
 	now := s.now()
 	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	yesterdayStart := todayStart.AddDate(0, 0, -1)
@@ -467,81 +493,57 @@ func (s *storageImpl) GetAssistantStats(ctx context.Context, assistantTelegramID
 	lastWeekStart := thisWeekStart.AddDate(0, 0, -7)
 
 	stats := &AssistantStats{}
+	var err error
 
 	// Count total active subscriptions
-	activeQuery := s.stmpBuilder().
-		Select("COUNT(*)").
+	stats.TotalActive, err = s.count(ctx, s.stmpBuilder().
+		Select("id").
 		From(subscriptionsTable).
 		Where(sq.Eq{"created_by_telegram_id": assistantTelegramID}).
-		Where(sq.Eq{"status": string(subs.StatusActive)})
-
-	q, args, err := activeQuery.ToSql()
+		Where(sq.Eq{"status": string(subs.StatusActive)}))
 	if err != nil {
-		return nil, fmt.Errorf("build active count query: %w", err)
-	}
-	if err := s.db.GetContext(ctx, &stats.TotalActive, q, args...); err != nil {
 		return nil, fmt.Errorf("count active: %w", err)
 	}
 
 	// Count renewed/created today (uses last_renewed_at to include renewals)
-	todayQuery := s.stmpBuilder().
-		Select("COUNT(*)").
+	stats.CreatedToday, err = s.count(ctx, s.stmpBuilder().
+		Select("id").
 		From(subscriptionsTable).
 		Where(sq.Eq{"created_by_telegram_id": assistantTelegramID}).
-		Where(sq.GtOrEq{"last_renewed_at": todayStart})
-
-	q, args, err = todayQuery.ToSql()
+		Where(sq.GtOrEq{"last_renewed_at": todayStart}))
 	if err != nil {
-		return nil, fmt.Errorf("build today count query: %w", err)
-	}
-	if err := s.db.GetContext(ctx, &stats.CreatedToday, q, args...); err != nil {
 		return nil, fmt.Errorf("count today: %w", err)
 	}
 
 	// Count renewed/created yesterday
-	yesterdayQuery := s.stmpBuilder().
-		Select("COUNT(*)").
+	stats.CreatedYesterday, err = s.count(ctx, s.stmpBuilder().
+		Select("id").
 		From(subscriptionsTable).
 		Where(sq.Eq{"created_by_telegram_id": assistantTelegramID}).
 		Where(sq.GtOrEq{"last_renewed_at": yesterdayStart}).
-		Where(sq.Lt{"last_renewed_at": todayStart})
-
-	q, args, err = yesterdayQuery.ToSql()
+		Where(sq.Lt{"last_renewed_at": todayStart}))
 	if err != nil {
-		return nil, fmt.Errorf("build yesterday count query: %w", err)
-	}
-	if err := s.db.GetContext(ctx, &stats.CreatedYesterday, q, args...); err != nil {
 		return nil, fmt.Errorf("count yesterday: %w", err)
 	}
 
 	// Count renewed/created this week (Monday to now)
-	thisWeekQuery := s.stmpBuilder().
-		Select("COUNT(*)").
+	stats.CreatedThisWeek, err = s.count(ctx, s.stmpBuilder().
+		Select("id").
 		From(subscriptionsTable).
 		Where(sq.Eq{"created_by_telegram_id": assistantTelegramID}).
-		Where(sq.GtOrEq{"last_renewed_at": thisWeekStart})
-
-	q, args, err = thisWeekQuery.ToSql()
+		Where(sq.GtOrEq{"last_renewed_at": thisWeekStart}))
 	if err != nil {
-		return nil, fmt.Errorf("build this week count query: %w", err)
-	}
-	if err := s.db.GetContext(ctx, &stats.CreatedThisWeek, q, args...); err != nil {
 		return nil, fmt.Errorf("count this week: %w", err)
 	}
 
 	// Count renewed/created last week (Monday to Sunday)
-	lastWeekQuery := s.stmpBuilder().
-		Select("COUNT(*)").
+	stats.CreatedLastWeek, err = s.count(ctx, s.stmpBuilder().
+		Select("id").
 		From(subscriptionsTable).
 		Where(sq.Eq{"created_by_telegram_id": assistantTelegramID}).
 		Where(sq.GtOrEq{"last_renewed_at": lastWeekStart}).
-		Where(sq.Lt{"last_renewed_at": thisWeekStart})
-
-	q, args, err = lastWeekQuery.ToSql()
+		Where(sq.Lt{"last_renewed_at": thisWeekStart}))
 	if err != nil {
-		return nil, fmt.Errorf("build last week count query: %w", err)
-	}
-	if err := s.db.GetContext(ctx, &stats.CreatedLastWeek, q, args...); err != nil {
 		return nil, fmt.Errorf("count last week: %w", err)
 	}
 
@@ -550,7 +552,7 @@ func (s *storageImpl) GetAssistantStats(ctx context.Context, assistantTelegramID
 
 // ListExpiringSubscriptionsByAssistant returns expiring subscriptions for a specific assistant
 // If assistantTelegramID is nil, returns all expiring subscriptions (for admins)
-func (s *storageImpl) ListExpiringSubscriptionsByAssistant(ctx context.Context, assistantTelegramID *int64, daysUntilExpiry int) ([]*subs.Subscription, error) {
+func (s *SubscriptionsRepo) ListExpiringSubscriptionsByAssistant(ctx context.Context, assistantTelegramID *int64, daysUntilExpiry int) ([]*subs.Subscription, error) {
 	startTime := s.now().AddDate(0, 0, daysUntilExpiry)
 	endTime := startTime.Add(24 * time.Hour)
 
@@ -587,7 +589,7 @@ func (s *storageImpl) ListExpiringSubscriptionsByAssistant(ctx context.Context,
 
 // ListExpiredSubscriptionsByAssistant returns expired subscriptions for a specific assistant
 // If assistantTelegramID is nil, returns all expired subscriptions (for admins)
-func (s *storageImpl) ListExpiredSubscriptionsByAssistant(ctx context.Context, assistantTelegramID *int64) ([]*subs.Subscription, error) {
+func (s *SubscriptionsRepo) ListExpiredSubscriptionsByAssistant(ctx context.Context, assistantTelegramID *int64) ([]*subs.Subscription, error) {
 	now := s.now()
 
 	query := s.stmpBuilder().
@@ -620,8 +622,118 @@ func (s *storageImpl) ListExpiredSubscriptionsByAssistant(ctx context.Context, a
 	return subscriptions, nil
 }
 
+// ListRenewableSubscriptionsByAssistant returns active subscriptions expiring within N days and expired ones,
+// soonest first. If assistantTelegramID is nil, returns subscriptions of all assistants (for admins)
+func (s *SubscriptionsRepo) ListRenewableSubscriptionsByAssistant(ctx context.Context, assistantTelegramID *int64, withinDays int, limit int) ([]*subs.Subscription, error) {
+	until := s.now().AddDate(0, 0, withinDays)
+
+	query := s.stmpBuilder().
+		Select(subscriptionRowFields).
+		From(subscriptionsTable).
+		Where(sq.Eq{"status": []string{string(subs.StatusActive), string(subs.StatusExpired)}}).
+		Where(sq.Lt{"expires_at": until}).
+		OrderBy("expires_at ASC", "id ASC")
+
+	if assistantTelegramID != nil {
+		query = query.Where(sq.Eq{"created_by_telegram_id": *assistantTelegramID})
+	}
+	if limit > 0 {
+		query = query.Limit(uint64(limit))
+	}
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []subscriptionRow
+	err = s.db.SelectContext(ctx, &rows, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	subscriptions := make([]*subs.Subscription, 0, len(rows))
+	for _, row := range rows {
+		subscriptions = append(subscriptions, row.ToModel())
+	}
+
+	return subscriptions, nil
+}
+
+// CountSubscriptionsByCreator returns how many subscriptions (of any status) the assistant owns
+func (s *SubscriptionsRepo) CountSubscriptionsByCreator(ctx context.Context, telegramID int64) (int, error) {
+	q, args, err := s.stmpBuilder().
+		Select("COUNT(*)").
+		From(subscriptionsTable).
+		Where(sq.Eq{"created_by_telegram_id": telegramID}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var count int
+	if err := s.db.GetContext(ctx, &count, q, args...); err != nil {
+		return 0, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListSubscriptionCreators returns Telegram IDs of everyone who has created at least one subscription.
+// Заблокированные пользователи (bans) не попадают в список
+func (s *SubscriptionsRepo) ListSubscriptionCreators(ctx context.Context) ([]int64, error) {
+	q, args, err := s.stmpBuilder().
+		Select("DISTINCT created_by_telegram_id").
+		From(subscriptionsTable).
+		Where(sq.NotEq{"created_by_telegram_id": nil}).
+		Where("created_by_telegram_id NOT IN (SELECT telegram_id FROM " + bansTable + ")").
+		OrderBy("created_by_telegram_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var ids []int64
+	if err := s.db.SelectContext(ctx, &ids, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ReassignSubscriptions transfers subscriptions from one assistant to another.
+// Empty subscriptionIDs transfers all subscriptions of fromTelegramID. Returns the number of transferred subscriptions
+func (s *SubscriptionsRepo) ReassignSubscriptions(ctx context.Context, fromTelegramID, toTelegramID int64, subscriptionIDs []int64) (int64, error) {
+	query := s.stmpBuilder().
+		Update(subscriptionsTable).
+		Set("created_by_telegram_id", toTelegramID).
+		Set("updated_at", s.now()).
+		Where(sq.Eq{"created_by_telegram_id": fromTelegramID})
+
+	if len(subscriptionIDs) > 0 {
+		query = query.Where(sq.Eq{"id": subscriptionIDs})
+	}
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	return affected, nil
+}
+
 // UpdateSubscriptionTariff updates the tariff for a subscription
-func (s *storageImpl) UpdateSubscriptionTariff(ctx context.Context, subscriptionID int64, tariffID int64) error {
+func (s *SubscriptionsRepo) UpdateSubscriptionTariff(ctx context.Context, subscriptionID int64, tariffID int64) error {
 	params := map[string]interface{}{
 		"tariff_id":  tariffID,
 		"updated_at": s.now(),
@@ -644,8 +756,181 @@ func (s *storageImpl) UpdateSubscriptionTariff(ctx context.Context, subscription
 	return nil
 }
 
+// KeepSubscriptionsPrice закрепляет price как индивидуальную цену продления за подписками тарифа,
+// у которых ее еще нет - чтобы смена цены тарифа коснулась только новых покупок
+func (s *SubscriptionsRepo) KeepSubscriptionsPrice(ctx context.Context, tariffID int64, price float64) (int64, error) {
+	q, args, err := s.stmpBuilder().
+		Update(subscriptionsTable).
+		Set("custom_price", price).
+		Set("updated_at", s.now()).
+		Where(sq.Eq{"tariff_id": tariffID}).
+		Where(sq.Eq{"custom_price": nil}).
+		Where(sq.Eq{"status": []string{string(subs.StatusActive), string(subs.StatusExpired)}}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	return affected, nil
+}
+
+// SetSubscriptionCustomPrice устанавливает индивидуальную цену продления (nil - сбросить на цену тарифа)
+func (s *SubscriptionsRepo) SetSubscriptionCustomPrice(ctx context.Context, subscriptionID int64, price *float64) error {
+	params := map[string]interface{}{
+		"custom_price": price,
+		"updated_at":   s.now(),
+	}
+
+	q, args, err := s.stmpBuilder().
+		Update(subscriptionsTable).
+		SetMap(params).
+		Where(sq.Eq{"id": subscriptionID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return nil
+}
+
+// SetSubscriptionClientPlatform сохраняет платформу устройства клиента для инструкций по подключению
+func (s *SubscriptionsRepo) SetSubscriptionClientPlatform(ctx context.Context, subscriptionID int64, platform devices.Platform) error {
+	q, args, err := s.stmpBuilder().
+		Update(subscriptionsTable).
+		Set("client_platform", string(platform)).
+		Set("updated_at", s.now()).
+		Where(sq.Eq{"id": subscriptionID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return nil
+}
+
+// SetSubscriptionNote сохраняет заметку ассистента о подписке; пустая строка удаляет заметку
+func (s *SubscriptionsRepo) SetSubscriptionNote(ctx context.Context, subscriptionID int64, note string) error {
+	q, args, err := s.stmpBuilder().
+		Update(subscriptionsTable).
+		Set("note", note).
+		Set("updated_at", s.now()).
+		Where(sq.Eq{"id": subscriptionID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return nil
+}
+
+// PauseSubscription замораживает активную подписку на days дней; false - подписка уже не активна или истекла
+func (s *SubscriptionsRepo) PauseSubscription(ctx context.Context, subscriptionID int64, days int) (bool, error) {
+	now := s.now()
+	q, args, err := s.stmpBuilder().
+		Update(subscriptionsTable).
+		Set("status", string(subs.StatusPaused)).
+		Set("paused_at", now).
+		Set("paused_until", now.AddDate(0, 0, days)).
+		Set("updated_at", now).
+		Where(sq.Eq{"id": subscriptionID, "status": string(subs.StatusActive)}).
+		Where(sq.Gt{"expires_at": now}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// ResumeSubscription снимает подписку с паузы с новой датой окончания (см. subs.ResumedExpiry);
+// false - подписка уже не на паузе
+func (s *SubscriptionsRepo) ResumeSubscription(ctx context.Context, subscriptionID int64, expiresAt time.Time) (bool, error) {
+	q, args, err := s.stmpBuilder().
+		Update(subscriptionsTable).
+		Set("status", string(subs.StatusActive)).
+		Set("expires_at", expiresAt).
+		Set("paused_at", nil).
+		Set("paused_until", nil).
+		Set("updated_at", s.now()).
+		Where(sq.Eq{"id": subscriptionID, "status": string(subs.StatusPaused)}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// ListEndedPauses возвращает подписки на паузе, срок паузы которых закончился
+func (s *SubscriptionsRepo) ListEndedPauses(ctx context.Context) ([]*subs.Subscription, error) {
+	q, args, err := s.stmpBuilder().
+		Select(subscriptionRowFields).
+		From(subscriptionsTable).
+		Where(sq.Eq{"status": string(subs.StatusPaused)}).
+		Where(sq.LtOrEq{"paused_until": s.now()}).
+		OrderBy("paused_until ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []subscriptionRow
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	subscriptions := make([]*subs.Subscription, 0, len(rows))
+	for _, row := range rows {
+		subscriptions = append(subscriptions, row.ToModel())
+	}
+	return subscriptions, nil
+}
+
 // FindActiveSubscriptionByWhatsApp finds an active subscription by client WhatsApp number
-func (s *storageImpl) FindActiveSubscriptionByWhatsApp(ctx context.Context, whatsapp string) (*subs.Subscription, error) {
+func (s *SubscriptionsRepo) FindActiveSubscriptionByWhatsApp(ctx context.Context, whatsapp string) (*subs.Subscription, error) {
 	normalized := NormalizePhone(whatsapp)
 
 	query := `
@@ -681,7 +966,7 @@ func NormalizePhone(phone string) string {
 }
 
 // HasUsedTrialByPhone checks if client has used trial by phone number
-func (s *storageImpl) HasUsedTrialByPhone(ctx context.Context, phoneNumber string) (bool, error) {
+func (s *SubscriptionsRepo) HasUsedTrialByPhone(ctx context.Context, phoneNumber string) (bool, error) {
 	normalized := NormalizePhone(phoneNumber)
 
 	query := `
@@ -700,7 +985,7 @@ func (s *storageImpl) HasUsedTrialByPhone(ctx context.Context, phoneNumber strin
 }
 
 // HasPaidSubscriptionByPhone checks if client has any paid subscription by phone number
-func (s *storageImpl) HasPaidSubscriptionByPhone(ctx context.Context, phoneNumber string) (bool, error) {
+func (s *SubscriptionsRepo) HasPaidSubscriptionByPhone(ctx context.Context, phoneNumber string) (bool, error) {
 	normalized := NormalizePhone(phoneNumber)
 
 	query := `
@@ -720,7 +1005,7 @@ func (s *storageImpl) HasPaidSubscriptionByPhone(ctx context.Context, phoneNumbe
 }
 
 // CountWeeklyReferrals counts how many people were invited by referrerWhatsApp this week
-func (s *storageImpl) CountWeeklyReferrals(ctx context.Context, referrerWhatsApp string) (int, error) {
+func (s *SubscriptionsRepo) CountWeeklyReferrals(ctx context.Context, referrerWhatsApp string) (int, error) {
 	now := s.now()
 	weekday := int(now.Weekday())
 	if weekday == 0 {
@@ -755,7 +1040,7 @@ type ReferrerStats struct {
 }
 
 // GetTopReferrersThisWeek returns top N referrers by invitation count this week
-func (s *storageImpl) GetTopReferrersThisWeek(ctx context.Context, limit int) ([]ReferrerStats, error) {
+func (s *SubscriptionsRepo) GetTopReferrersThisWeek(ctx context.Context, limit int) ([]ReferrerStats, error) {
 	now := s.now()
 	weekday := int(now.Weekday())
 	if weekday == 0 {
@@ -799,3 +1084,245 @@ func (s *storageImpl) GetTopReferrersThisWeek(ctx context.Context, limit int) ([
 
 	return result, nil
 }
+
+// SubscriptionDetails holds a subscription with embedded tariff and server info
+type SubscriptionDetails struct {
+	Subscription       *subs.Subscription
+	TariffName         string
+	TariffDurationDays int
+	TariffPrice        float64
+	ServerName         *string
+}
+
+type subscriptionDetailsRow struct {
+	subscriptionRow
+	TariffName         string  `db:"tariff_name"`
+	TariffDurationDays int     `db:"tariff_duration_days"`
+	TariffPrice        float64 `db:"tariff_price"`
+	ServerName         *string `db:"server_name"`
+}
+
+// ListSubscriptionsPage returns a page of subscriptions with tariff and server info in a single query.
+// Сортировка по убыванию ID, курсор - ID последней подписки предыдущей страницы
+func (s *SubscriptionsRepo) ListSubscriptionsPage(ctx context.Context, criteria subs.PageCriteria) ([]SubscriptionDetails, error) {
+	query := s.stmpBuilder().
+		Select(prefixWithTable("s", subscriptionRowFields),
+			"t.name AS tariff_name",
+			"t.duration_days AS tariff_duration_days",
+			"t.price AS tariff_price",
+			"srv.name AS server_name").
+		From(subscriptionsTable + " s").
+		Join(tariffsTable + " t ON t.id = s.tariff_id").
+		LeftJoin(serversTable + " srv ON srv.id = s.server_id").
+		Where(sq.Eq{"s.created_by_telegram_id": criteria.CreatedByTelegramID})
+
+	if len(criteria.Status) > 0 {
+		query = query.Where(sq.Eq{"s.status": criteria.Status})
+	}
+	if criteria.BeforeID != nil {
+		query = query.Where(sq.Lt{"s.id": *criteria.BeforeID})
+	}
+	if criteria.Limit > 0 {
+		query = query.Limit(uint64(criteria.Limit))
+	}
+
+	q, args, err := query.OrderBy("s.id DESC").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []subscriptionDetailsRow
+	err = s.db.SelectContext(ctx, &rows, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]SubscriptionDetails, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, SubscriptionDetails{
+			Subscription:       row.subscriptionRow.ToModel(),
+			TariffName:         row.TariffName,
+			TariffDurationDays: row.TariffDurationDays,
+			TariffPrice:        row.TariffPrice,
+			ServerName:         row.ServerName,
+		})
+	}
+
+	return result, nil
+}
+
+// SearchSubscriptions ищет подписки по части client_whatsapp или generated_user_id.
+// Активные подписки идут первыми, дальше - по убыванию ID
+func (s *SubscriptionsRepo) SearchSubscriptions(ctx context.Context, criteria subs.SearchCriteria) ([]SubscriptionDetails, error) {
+	if criteria.Query == "" && criteria.ID == nil {
+		return nil, nil
+	}
+
+	query := s.stmpBuilder().
+		Select(prefixWithTable("s", subscriptionRowFields),
+			"t.name AS tariff_name",
+			"t.duration_days AS tariff_duration_days",
+			"t.price AS tariff_price",
+			"srv.name AS server_name").
+		From(subscriptionsTable + " s").
+		Join(tariffsTable + " t ON t.id = s.tariff_id").
+		LeftJoin(serversTable + " srv ON srv.id = s.server_id")
+
+	if criteria.Query != "" {
+		pattern := "%" + escapeLike(criteria.Query) + "%"
+		query = query.Where(sq.Or{
+			sq.Expr(`s.client_whatsapp LIKE ? ESCAPE '\'`, pattern),
+			sq.Expr(`s.generated_user_id LIKE ? ESCAPE '\'`, pattern),
+		})
+	}
+	if criteria.ID != nil {
+		query = query.Where(sq.Eq{"s.id": *criteria.ID})
+	}
+	if criteria.CreatedByTelegramID != nil {
+		query = query.Where(sq.Eq{"s.created_by_telegram_id": *criteria.CreatedByTelegramID})
+	}
+	if criteria.Limit > 0 {
+		query = query.Limit(uint64(criteria.Limit))
+	}
+
+	q, args, err := query.
+		OrderBy("CASE WHEN s.status = 'active' THEN 0 ELSE 1 END", "s.id DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []subscriptionDetailsRow
+	err = s.db.SelectContext(ctx, &rows, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]SubscriptionDetails, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, SubscriptionDetails{
+			Subscription:       row.subscriptionRow.ToModel(),
+			TariffName:         row.TariffName,
+			TariffDurationDays: row.TariffDurationDays,
+			TariffPrice:        row.TariffPrice,
+			ServerName:         row.ServerName,
+		})
+	}
+
+	return result, nil
+}
+
+// SearchClientsByPrefix ищет клиентов, у которых номер WhatsApp начинается с criteria.Query.
+// На каждого клиента возвращается его последняя подписка, новые клиенты первыми
+func (s *SubscriptionsRepo) SearchClientsByPrefix(ctx context.Context, criteria subs.SearchCriteria) ([]SubscriptionDetails, error) {
+	if criteria.Query == "" {
+		return nil, nil
+	}
+
+	// Старые номера могли сохраниться с "+"
+	match := sq.Or{
+		sq.Expr(`client_whatsapp LIKE ? ESCAPE '\'`, escapeLike(criteria.Query)+"%"),
+		sq.Expr(`client_whatsapp LIKE ? ESCAPE '\'`, "+"+escapeLike(criteria.Query)+"%"),
+	}
+	return s.latestClientSubscriptions(ctx, match, criteria.CreatedByTelegramID, criteria.Limit)
+}
+
+// ListLatestSubscriptionsByWhatsApp возвращает последнюю подписку каждого клиента из phones
+// (нормализованные номера без "+"). createdByTelegramID != nil - только подписки этого ассистента
+func (s *SubscriptionsRepo) ListLatestSubscriptionsByWhatsApp(ctx context.Context, phones []string, createdByTelegramID *int64) ([]SubscriptionDetails, error) {
+	if len(phones) == 0 {
+		return nil, nil
+	}
+
+	// Старые номера могли сохраниться с "+"
+	variants := make([]string, 0, 2*len(phones))
+	for _, phone := range phones {
+		variants = append(variants, phone, "+"+phone)
+	}
+	return s.latestClientSubscriptions(ctx, sq.Eq{"client_whatsapp": variants}, createdByTelegramID, 0)
+}
+
+// latestClientSubscriptions возвращает последнюю подписку каждого клиента, номер которого подходит под match.
+// limit 0 - без ограничения
+func (s *SubscriptionsRepo) latestClientSubscriptions(ctx context.Context, match sq.Sqlizer, createdByTelegramID *int64, limit int) ([]SubscriptionDetails, error) {
+	latest := s.stmpBuilder().
+		Select("MAX(id)").
+		From(subscriptionsTable).
+		Where(match).
+		GroupBy("client_whatsapp")
+	if createdByTelegramID != nil {
+		latest = latest.Where(sq.Eq{"created_by_telegram_id": *createdByTelegramID})
+	}
+	latestSQL, latestArgs, err := latest.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql subquery: %w", err)
+	}
+
+	query := s.stmpBuilder().
+		Select(prefixWithTable("s", subscriptionRowFields),
+			"t.name AS tariff_name",
+			"t.duration_days AS tariff_duration_days",
+			"t.price AS tariff_price",
+			"srv.name AS server_name").
+		From(subscriptionsTable+" s").
+		Join(tariffsTable+" t ON t.id = s.tariff_id").
+		LeftJoin(serversTable+" srv ON srv.id = s.server_id").
+		Where("s.id IN ("+latestSQL+")", latestArgs...).
+		OrderBy("s.id DESC")
+	if limit > 0 {
+		query = query.Limit(uint64(limit))
+	}
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []subscriptionDetailsRow
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]SubscriptionDetails, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, SubscriptionDetails{
+			Subscription:       row.subscriptionRow.ToModel(),
+			TariffName:         row.TariffName,
+			TariffDurationDays: row.TariffDurationDays,
+			TariffPrice:        row.TariffPrice,
+			ServerName:         row.ServerName,
+		})
+	}
+	return result, nil
+}
+
+// escapeLike экранирует спецсимволы LIKE, чтобы "_" в generated_user_id искался буквально
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// ArchiveExpiredSubscriptions moves expired and disabled subscriptions that ended before the given time to archived status
+func (s *SubscriptionsRepo) ArchiveExpiredSubscriptions(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	q, args, err := s.stmpBuilder().
+		Update(subscriptionsTable).
+		Set("status", string(subs.StatusArchived)).
+		Set("updated_at", s.now()).
+		Where(sq.Eq{"status": []string{string(subs.StatusExpired), string(subs.StatusDisabled)}}).
+		Where(sq.Lt{"expires_at": expiredBefore}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	return affected, nil
+}
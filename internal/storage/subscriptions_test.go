@@ -1,6 +1,9 @@
 package storage
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestNormalizePhone(t *testing.T) {
 	tests := []struct {
@@ -25,3 +28,39 @@ func TestNormalizePhone(t *testing.T) {
 		})
 	}
 }
+
+func TestCancelSubscription(t *testing.T) {
+	db := newTestDB(t)
+	s := New(db)
+	ctx := context.Background()
+
+	if _, err := db.Exec(`INSERT INTO servers (id, name, ui_url, ui_password, current_users) VALUES (3, 'srv', 'https://srv', 'pwd', 2)`); err != nil {
+		t.Fatalf("insert server: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO subscriptions (id, user_id, tariff_id, status, server_id) VALUES (1, 1, 1, 'active', 3)`); err != nil {
+		t.Fatalf("insert subscription: %v", err)
+	}
+
+	// Повторная отмена (двойное нажатие) не должна освобождать место второй раз
+	for i, want := range []bool{true, false} {
+		cancelled, err := s.CancelSubscription(ctx, 1)
+		if err != nil {
+			t.Fatalf("CancelSubscription #%d: %v", i+1, err)
+		}
+		if cancelled != want {
+			t.Errorf("CancelSubscription #%d = %v, want %v", i+1, cancelled, want)
+		}
+	}
+
+	var status string
+	var currentUsers int
+	if err := db.Get(&status, `SELECT status FROM subscriptions WHERE id = 1`); err != nil {
+		t.Fatalf("select status: %v", err)
+	}
+	if err := db.Get(&currentUsers, `SELECT current_users FROM servers WHERE id = 3`); err != nil {
+		t.Fatalf("select current_users: %v", err)
+	}
+	if status != "cancelled" || currentUsers != 1 {
+		t.Errorf("status = %q, current_users = %d, want cancelled and 1", status, currentUsers)
+	}
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"kurut-bot/internal/stories/offers"
+)
+
+const tariffOfferLeadsTable = "tariff_offer_leads"
+
+// CreateOfferLead записывает заявку клиента по предложению тарифа; false - клиент уже открывал эту ссылку
+func (s *storageImpl) CreateOfferLead(ctx context.Context, lead offers.Lead) (bool, error) {
+	q, args, err := s.stmpBuilder().
+		Insert(tariffOfferLeadsTable).
+		Columns("tariff_id", "assistant_telegram_id", "client_telegram_id", "client_name", "created_at").
+		Values(lead.TariffID, lead.AssistantTelegramID, lead.ClientTelegramID, lead.ClientName, s.now()).
+		Suffix("ON CONFLICT(tariff_id, assistant_telegram_id, client_telegram_id) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -16,38 +17,60 @@ const tariffsTable = "tariffs"
 var tariffRowFields = fields(tariffRow{})
 
 type tariffRow struct {
-	ID             int64     `db:"id"`
-	Name           string    `db:"name"`
-	DurationDays   int       `db:"duration_days"`
-	Price          float64   `db:"price"`
-	TrafficLimitGB *int      `db:"traffic_limit_gb"`
-	IsActive       bool      `db:"is_active"`
-	CreatedAt      time.Time `db:"created_at"`
-	UpdatedAt      time.Time `db:"updated_at"`
+	ID               int64      `db:"id"`
+	Name             string     `db:"name"`
+	DurationDays     int        `db:"duration_days"`
+	Price            float64    `db:"price"`
+	TrafficLimitGB   *int       `db:"traffic_limit_gb"`
+	IsActive         bool       `db:"is_active"`
+	ReminderDays     *string    `db:"reminder_days"`
+	Cluster          string     `db:"cluster"`
+	ValidFrom        *time.Time `db:"valid_from"`
+	ValidUntil       *time.Time `db:"valid_until"`
+	FallbackTariffID *int64     `db:"fallback_tariff_id"`
+	CreatedAt        time.Time  `db:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at"`
 }
 
-func (t tariffRow) ToModel() *tariffs.Tariff {
-	return &tariffs.Tariff{
-		ID:             t.ID,
-		Name:           t.Name,
-		DurationDays:   t.DurationDays,
-		Price:          t.Price,
-		TrafficLimitGB: t.TrafficLimitGB,
-		IsActive:       t.IsActive,
-		CreatedAt:      t.CreatedAt,
-		UpdatedAt:      t.UpdatedAt,
+func (t tariffRow) ToModel() (*tariffs.Tariff, error) {
+	var reminderDays []int
+	if t.ReminderDays != nil {
+		if err := json.Unmarshal([]byte(*t.ReminderDays), &reminderDays); err != nil {
+			return nil, fmt.Errorf("tariff %d: parse reminder_days %q: %w", t.ID, *t.ReminderDays, err)
+		}
 	}
+
+	return &tariffs.Tariff{
+		ID:               t.ID,
+		Name:             t.Name,
+		DurationDays:     t.DurationDays,
+		Price:            t.Price,
+		TrafficLimitGB:   t.TrafficLimitGB,
+		IsActive:         t.IsActive,
+		ReminderDays:     reminderDays,
+		Cluster:          t.Cluster,
+		ValidFrom:        t.ValidFrom,
+		ValidUntil:       t.ValidUntil,
+		FallbackTariffID: t.FallbackTariffID,
+		CreatedAt:        t.CreatedAt,
+		UpdatedAt:        t.UpdatedAt,
+	}, nil
 }
 
 func (s *storageImpl) CreateTariff(ctx context.Context, tariff tariffs.Tariff) (*tariffs.Tariff, error) {
 	params := map[string]interface{}{
-		"name":             tariff.Name,
-		"duration_days":    tariff.DurationDays,
-		"price":            tariff.Price,
-		"traffic_limit_gb": tariff.TrafficLimitGB,
-		"is_active":        tariff.IsActive,
-		"created_at":       s.now(),
-		"updated_at":       s.now(),
+		"name":               tariff.Name,
+		"duration_days":      tariff.DurationDays,
+		"price":              tariff.Price,
+		"traffic_limit_gb":   tariff.TrafficLimitGB,
+		"is_active":          tariff.IsActive,
+		"reminder_days":      reminderDaysToJSON(tariff.ReminderDays),
+		"cluster":            tariff.Cluster,
+		"valid_from":         tariff.ValidFrom,
+		"valid_until":        tariff.ValidUntil,
+		"fallback_tariff_id": tariff.FallbackTariffID,
+		"created_at":         s.now(),
+		"updated_at":         s.now(),
 	}
 
 	q, args, err := s.stmpBuilder().
@@ -86,18 +109,16 @@ func (s *storageImpl) GetTariff(ctx context.Context, criteria tariffs.GetCriteri
 		return nil, fmt.Errorf("build sql query: %w", err)
 	}
 
-	row := s.db.QueryRowContext(ctx, q, args...)
-
 	var t tariffRow
-	err = row.Scan(&t.ID, &t.Name, &t.DurationDays, &t.Price, &t.TrafficLimitGB, &t.IsActive, &t.CreatedAt, &t.UpdatedAt)
+	err = s.db.GetContext(ctx, &t, q, args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("row.Scan: %w", err)
+		return nil, fmt.Errorf("db.GetContext: %w", err)
 	}
 
-	return t.ToModel(), nil
+	return t.ToModel()
 }
 
 func (s *storageImpl) UpdateTariff(ctx context.Context, criteria tariffs.GetCriteria, params tariffs.UpdateParams) (*tariffs.Tariff, error) {
@@ -122,10 +143,33 @@ func (s *storageImpl) UpdateTariff(ctx context.Context, criteria tariffs.GetCrit
 	}
 	if params.TrafficLimitGB != nil {
 		query = query.Set("traffic_limit_gb", *params.TrafficLimitGB)
+	} else if params.ClearTrafficLimit {
+		query = query.Set("traffic_limit_gb", nil)
 	}
 	if params.IsActive != nil {
 		query = query.Set("is_active", *params.IsActive)
 	}
+	if params.ReminderDays != nil {
+		query = query.Set("reminder_days", reminderDaysToJSON(params.ReminderDays))
+	}
+	if params.Cluster != nil {
+		query = query.Set("cluster", *params.Cluster)
+	}
+	if params.ClearPromoPeriod {
+		query = query.Set("valid_from", nil).Set("valid_until", nil)
+	} else {
+		if params.ValidFrom != nil {
+			query = query.Set("valid_from", *params.ValidFrom)
+		}
+		if params.ValidUntil != nil {
+			query = query.Set("valid_until", *params.ValidUntil)
+		}
+	}
+	if params.FallbackTariffID != nil {
+		query = query.Set("fallback_tariff_id", *params.FallbackTariffID)
+	} else if params.ClearFallbackTariff {
+		query = query.Set("fallback_tariff_id", nil)
+	}
 
 	q, args, err := query.ToSql()
 	if err != nil {
@@ -163,24 +207,18 @@ func (s *storageImpl) ListTariffs(ctx context.Context, criteria tariffs.ListCrit
 		return nil, fmt.Errorf("build sql query: %w", err)
 	}
 
-	rows, err := s.db.QueryContext(ctx, q, args...)
-	if err != nil {
-		return nil, fmt.Errorf("db.QueryContext: %w", err)
+	var rows []tariffRow
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
 	}
-	defer rows.Close()
 
 	var result []*tariffs.Tariff
-	for rows.Next() {
-		var t tariffRow
-		err = rows.Scan(&t.ID, &t.Name, &t.DurationDays, &t.Price, &t.TrafficLimitGB, &t.IsActive, &t.CreatedAt, &t.UpdatedAt)
+	for _, t := range rows {
+		tariff, err := t.ToModel()
 		if err != nil {
-			return nil, fmt.Errorf("rows.Scan: %w", err)
+			return nil, err
 		}
-		result = append(result, t.ToModel())
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows.Err: %w", err)
+		result = append(result, tariff)
 	}
 
 	return result, nil
@@ -230,5 +268,15 @@ func (s *storageImpl) GetTrialTariff(ctx context.Context) (*tariffs.Tariff, erro
 		return nil, fmt.Errorf("db.GetContext: %w", err)
 	}
 
-	return row.ToModel(), nil
+	return row.ToModel()
+}
+
+// reminderDaysToJSON сериализует расписание напоминаний, пустое расписание хранится как NULL
+func reminderDaysToJSON(days []int) *string {
+	if len(days) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(days)
+	str := string(data)
+	return &str
 }
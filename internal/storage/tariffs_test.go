@@ -0,0 +1,16 @@
+package storage
+
+import "testing"
+
+func TestTariffRowReminderDays(t *testing.T) {
+	days := "[7,3,0]"
+	tariff, err := tariffRow{ID: 1, ReminderDays: &days}.ToModel()
+	if err != nil || len(tariff.ReminderDays) != 3 || tariff.ReminderDays[0] != 7 {
+		t.Errorf("ToModel() = %v, %v", tariff, err)
+	}
+
+	broken := "7,3"
+	if _, err := (tariffRow{ID: 2, ReminderDays: &broken}).ToModel(); err == nil {
+		t.Error("ToModel() must reject invalid reminder_days")
+	}
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const taskPinsTable = "task_pins"
+
+type taskPinRow struct {
+	ChatID    int64 `db:"chat_id"`
+	MessageID int   `db:"message_id"`
+}
+
+// ListTaskPins возвращает закрепленные сообщения с задачами на день: ID сообщения по чату
+func (s *storageImpl) ListTaskPins(ctx context.Context) (map[int64]int, error) {
+	q, args, err := s.stmpBuilder().
+		Select("chat_id", "message_id").
+		From(taskPinsTable).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []taskPinRow
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make(map[int64]int, len(rows))
+	for _, row := range rows {
+		result[row.ChatID] = row.MessageID
+	}
+	return result, nil
+}
+
+// SaveTaskPin запоминает закрепленное сообщение с задачами вместо прежнего
+func (s *storageImpl) SaveTaskPin(ctx context.Context, chatID int64, messageID int) error {
+	q, args, err := s.stmpBuilder().
+		Insert(taskPinsTable).
+		Columns("chat_id", "message_id", "pinned_at").
+		Values(chatID, messageID, s.now()).
+		Suffix(upsertSuffix("chat_id", "message_id", "pinned_at")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// DeleteTaskPin забывает закрепленное сообщение чата, когда задач на день нет
+func (s *storageImpl) DeleteTaskPin(ctx context.Context, chatID int64) error {
+	q, args, err := s.stmpBuilder().
+		Delete(taskPinsTable).
+		Where(sq.Eq{"chat_id": chatID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/stories/traffic"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+const trafficTopUpsTable = "traffic_topups"
+
+var trafficTopUpRowFields = fields(trafficTopUpRow{})
+
+type trafficTopUpRow struct {
+	ID             int64     `db:"id"`
+	SubscriptionID int64     `db:"subscription_id"`
+	PaymentID      int64     `db:"payment_id"`
+	TrafficGB      int       `db:"traffic_gb"`
+	Amount         float64   `db:"amount"`
+	Status         string    `db:"status"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+func (r trafficTopUpRow) ToModel() *traffic.TopUp {
+	return &traffic.TopUp{
+		ID:             r.ID,
+		SubscriptionID: r.SubscriptionID,
+		PaymentID:      r.PaymentID,
+		TrafficGB:      r.TrafficGB,
+		Amount:         r.Amount,
+		Status:         traffic.Status(r.Status),
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+	}
+}
+
+func (s *storageImpl) CreateTrafficTopUp(ctx context.Context, topUp traffic.TopUp) (*traffic.TopUp, error) {
+	now := s.now()
+
+	params := map[string]interface{}{
+		"subscription_id": topUp.SubscriptionID,
+		"payment_id":      topUp.PaymentID,
+		"traffic_gb":      topUp.TrafficGB,
+		"amount":          topUp.Amount,
+		"status":          string(traffic.StatusPending),
+		"created_at":      now,
+		"updated_at":      now,
+	}
+
+	q, args, err := s.stmpBuilder().
+		Insert(trafficTopUpsTable).
+		SetMap(params).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("result.LastInsertId: %w", err)
+	}
+
+	return s.GetTrafficTopUp(ctx, id)
+}
+
+func (s *storageImpl) GetTrafficTopUp(ctx context.Context, id int64) (*traffic.TopUp, error) {
+	q, args, err := s.stmpBuilder().
+		Select(trafficTopUpRowFields).
+		From(trafficTopUpsTable).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var row trafficTopUpRow
+	if err := s.db.GetContext(ctx, &row, q, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return row.ToModel(), nil
+}
+
+// ApplyTrafficTopUp переводит докупку в applied и добавляет трафик к подписке.
+// Возвращает false, если докупка уже была применена или отменена
+func (s *storageImpl) ApplyTrafficTopUp(ctx context.Context, id int64) (bool, error) {
+	var applied bool
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		now := s.now()
+
+		q, args, err := s.stmpBuilder().
+			Update(trafficTopUpsTable).
+			Set("status", string(traffic.StatusApplied)).
+			Set("updated_at", now).
+			Where(sq.Eq{"id": id, "status": string(traffic.StatusPending)}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql query: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, q, args...)
+		if err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("result.RowsAffected: %w", err)
+		}
+		if affected == 0 {
+			return nil
+		}
+
+		q, args, err = s.stmpBuilder().
+			Update(subscriptionsTable).
+			Set("extra_traffic_gb", sq.Expr("extra_traffic_gb + (SELECT traffic_gb FROM traffic_topups WHERE id = ?)", id)).
+			Set("updated_at", now).
+			Where(sq.Expr("id = (SELECT subscription_id FROM traffic_topups WHERE id = ?)", id)).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("build sql query: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+
+		applied = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/unpaidsubs"
+)
+
+const unpaidSubscriptionAlertsTable = "unpaid_subscription_alerts"
+
+// ListUnpaidSubscriptions returns subscriptions on paid tariffs created in [createdAfter, createdBefore)
+// that have no linked approved payment and were not yet reported to admins
+func (s *storageImpl) ListUnpaidSubscriptions(ctx context.Context, createdAfter, createdBefore time.Time) ([]*subs.Subscription, error) {
+	query := `
+		SELECT ` + prefixWithTable("s", subscriptionRowFields) + `
+		FROM ` + subscriptionsTable + ` s
+		JOIN ` + tariffsTable + ` t ON t.id = s.tariff_id
+		WHERE t.price > 0
+		AND s.created_at >= ?
+		AND s.created_at < ?
+		AND NOT EXISTS (
+			SELECT 1 FROM ` + paymentSubscriptionsTable + ` ps
+			JOIN ` + paymentsTable + ` p ON p.id = ps.payment_id
+			WHERE ps.subscription_id = s.id AND p.status = ?
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM ` + unpaidSubscriptionAlertsTable + ` a
+			WHERE a.subscription_id = s.id
+		)
+		ORDER BY s.created_at ASC
+	`
+
+	var rows []subscriptionRow
+	err := s.db.SelectContext(ctx, &rows, query, createdAfter, createdBefore, string(payment.StatusApproved))
+	if err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*subs.Subscription, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+
+	return result, nil
+}
+
+// SetUnpaidSubscriptionAlertStatus records that the subscription was reported (or ignored by an admin)
+func (s *storageImpl) SetUnpaidSubscriptionAlertStatus(ctx context.Context, subscriptionID int64, status unpaidsubs.AlertStatus) error {
+	now := s.now()
+	q, args, err := s.stmpBuilder().
+		Insert(unpaidSubscriptionAlertsTable).
+		Columns("subscription_id", "status", "created_at", "updated_at").
+		Values(subscriptionID, string(status), now, now).
+		Suffix("ON CONFLICT(subscription_id) DO UPDATE SET status = excluded.status, updated_at = excluded.updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return nil
+}
+
+// ListSubscriptionPayments returns all payments linked to the subscription, newest first
+func (s *storageImpl) ListSubscriptionPayments(ctx context.Context, subscriptionID int64) ([]*payment.Payment, error) {
+	q, args, err := s.stmpBuilder().
+		Select(prefixWithTable("p", paymentRowFields)).
+		From(paymentsTable+" p").
+		Join(paymentSubscriptionsTable+" ps ON ps.payment_id = p.id").
+		Where("ps.subscription_id = ?", subscriptionID).
+		OrderBy("p.id DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []paymentRow
+	err = s.db.SelectContext(ctx, &rows, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*payment.Payment, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+
+	return result, nil
+}
@@ -20,6 +20,7 @@ type userRow struct {
 	TelegramID int64     `db:"telegram_id"`
 	UsedTrial  bool      `db:"used_trial"`
 	Language   string    `db:"language"`
+	Role       string    `db:"role"`
 	CreatedAt  time.Time `db:"created_at"`
 	UpdatedAt  time.Time `db:"updated_at"`
 }
@@ -30,6 +31,7 @@ func (u userRow) ToModel() *users.User {
 		TelegramID: u.TelegramID,
 		UsedTrial:  u.UsedTrial,
 		Language:   u.Language,
+		Role:       users.Role(u.Role),
 		CreatedAt:  u.CreatedAt,
 		UpdatedAt:  u.UpdatedAt,
 	}
@@ -85,7 +87,7 @@ func (s *storageImpl) GetUser(ctx context.Context, criteria users.GetCriteria) (
 	row := s.db.QueryRowContext(ctx, q, args...)
 
 	var u userRow
-	err = row.Scan(&u.ID, &u.TelegramID, &u.UsedTrial, &u.Language, &u.CreatedAt, &u.UpdatedAt)
+	err = row.Scan(&u.ID, &u.TelegramID, &u.UsedTrial, &u.Language, &u.Role, &u.CreatedAt, &u.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -158,7 +160,7 @@ func (s *storageImpl) ListUsers(ctx context.Context, criteria users.ListCriteria
 	var result []*users.User
 	for rows.Next() {
 		var u userRow
-		err = rows.Scan(&u.ID, &u.TelegramID, &u.UsedTrial, &u.Language, &u.CreatedAt, &u.UpdatedAt)
+		err = rows.Scan(&u.ID, &u.TelegramID, &u.UsedTrial, &u.Language, &u.Role, &u.CreatedAt, &u.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("rows.Scan: %w", err)
 		}
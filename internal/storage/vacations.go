@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/vacations"
+)
+
+const assistantVacationsTable = "assistant_vacations"
+
+var vacationRowFields = fields(vacationRow{})
+
+type vacationRow struct {
+	AssistantTelegramID int64     `db:"assistant_telegram_id"`
+	BackupTelegramID    *int64    `db:"backup_telegram_id"`
+	StartsAt            time.Time `db:"starts_at"`
+	EndsAt              time.Time `db:"ends_at"`
+	CreatedAt           time.Time `db:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at"`
+}
+
+func (v vacationRow) ToModel() *vacations.Vacation {
+	return &vacations.Vacation{
+		AssistantTelegramID: v.AssistantTelegramID,
+		BackupTelegramID:    v.BackupTelegramID,
+		StartsAt:            v.StartsAt,
+		EndsAt:              v.EndsAt,
+		CreatedAt:           v.CreatedAt,
+		UpdatedAt:           v.UpdatedAt,
+	}
+}
+
+// SaveAssistantVacation creates or replaces the assistant's vacation
+func (s *storageImpl) SaveAssistantVacation(ctx context.Context, vacation vacations.Vacation) (*vacations.Vacation, error) {
+	now := s.now()
+	q, args, err := s.stmpBuilder().
+		Insert(assistantVacationsTable).
+		Columns("assistant_telegram_id", "backup_telegram_id", "starts_at", "ends_at", "created_at", "updated_at").
+		Values(vacation.AssistantTelegramID, vacation.BackupTelegramID, vacation.StartsAt, vacation.EndsAt, now, now).
+		Suffix("ON CONFLICT(assistant_telegram_id) DO UPDATE SET " +
+			"backup_telegram_id = excluded.backup_telegram_id, " +
+			"starts_at = excluded.starts_at, " +
+			"ends_at = excluded.ends_at, " +
+			"updated_at = excluded.updated_at").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return nil, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return s.GetAssistantVacation(ctx, vacation.AssistantTelegramID)
+}
+
+// GetAssistantVacation returns the assistant's current or planned vacation
+func (s *storageImpl) GetAssistantVacation(ctx context.Context, assistantTelegramID int64) (*vacations.Vacation, error) {
+	q, args, err := s.stmpBuilder().
+		Select(vacationRowFields).
+		From(assistantVacationsTable).
+		Where(sq.Eq{"assistant_telegram_id": assistantTelegramID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var row vacationRow
+	err = s.db.GetContext(ctx, &row, q, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return row.ToModel(), nil
+}
+
+// DeleteAssistantVacation ends the assistant's vacation early
+func (s *storageImpl) DeleteAssistantVacation(ctx context.Context, assistantTelegramID int64) error {
+	q, args, err := s.stmpBuilder().
+		Delete(assistantVacationsTable).
+		Where(sq.Eq{"assistant_telegram_id": assistantTelegramID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return nil
+}
+
+// ListActiveVacations returns vacations that are in progress at the given time
+func (s *storageImpl) ListActiveVacations(ctx context.Context, at time.Time) ([]*vacations.Vacation, error) {
+	q, args, err := s.stmpBuilder().
+		Select(vacationRowFields).
+		From(assistantVacationsTable).
+		Where(sq.LtOrEq{"starts_at": at}).
+		Where(sq.Gt{"ends_at": at}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []vacationRow
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*vacations.Vacation, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/waitlist"
+)
+
+const waitlistEntriesTable = "waitlist_entries"
+
+var waitlistEntryRowFields = fields(waitlistEntryRow{})
+
+type waitlistEntryRow struct {
+	ID                  int64      `db:"id"`
+	AdminUserID         int64      `db:"admin_user_id"`
+	AssistantTelegramID int64      `db:"assistant_telegram_id"`
+	ChatID              int64      `db:"chat_id"`
+	ClientWhatsApp      string     `db:"client_whatsapp"`
+	TariffID            int64      `db:"tariff_id"`
+	TariffName          string     `db:"tariff_name"`
+	HeldPrice           float64    `db:"held_price"`
+	Status              string     `db:"status"`
+	NotifiedAt          *time.Time `db:"notified_at"`
+	PriceHeldUntil      *time.Time `db:"price_held_until"`
+	CreatedAt           time.Time  `db:"created_at"`
+	UpdatedAt           time.Time  `db:"updated_at"`
+}
+
+func (r waitlistEntryRow) ToModel() *waitlist.Entry {
+	return &waitlist.Entry{
+		ID:                  r.ID,
+		AdminUserID:         r.AdminUserID,
+		AssistantTelegramID: r.AssistantTelegramID,
+		ChatID:              r.ChatID,
+		ClientWhatsApp:      r.ClientWhatsApp,
+		TariffID:            r.TariffID,
+		TariffName:          r.TariffName,
+		HeldPrice:           r.HeldPrice,
+		Status:              waitlist.Status(r.Status),
+		NotifiedAt:          r.NotifiedAt,
+		PriceHeldUntil:      r.PriceHeldUntil,
+		CreatedAt:           r.CreatedAt,
+		UpdatedAt:           r.UpdatedAt,
+	}
+}
+
+// CreateWaitlistEntry записывает клиента в очередь на свободное место
+func (s *storageImpl) CreateWaitlistEntry(ctx context.Context, entry waitlist.Entry) (*waitlist.Entry, error) {
+	now := s.now()
+
+	params := map[string]interface{}{
+		"admin_user_id":         entry.AdminUserID,
+		"assistant_telegram_id": entry.AssistantTelegramID,
+		"chat_id":               entry.ChatID,
+		"client_whatsapp":       entry.ClientWhatsApp,
+		"tariff_id":             entry.TariffID,
+		"tariff_name":           entry.TariffName,
+		"held_price":            entry.HeldPrice,
+		"status":                string(waitlist.StatusWaiting),
+		"created_at":            now,
+		"updated_at":            now,
+	}
+
+	q, args, err := s.stmpBuilder().
+		Insert(waitlistEntriesTable).
+		SetMap(params).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("result.LastInsertId: %w", err)
+	}
+
+	return s.GetWaitlistEntry(ctx, id)
+}
+
+func (s *storageImpl) GetWaitlistEntry(ctx context.Context, id int64) (*waitlist.Entry, error) {
+	q, args, err := s.stmpBuilder().
+		Select(waitlistEntryRowFields).
+		From(waitlistEntriesTable).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var row waitlistEntryRow
+	if err := s.db.GetContext(ctx, &row, q, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return row.ToModel(), nil
+}
+
+// ListWaitlistEntries возвращает записи очереди в порядке записи (первым - кто раньше встал)
+func (s *storageImpl) ListWaitlistEntries(ctx context.Context, criteria waitlist.ListCriteria) ([]*waitlist.Entry, error) {
+	query := s.stmpBuilder().
+		Select(waitlistEntryRowFields).
+		From(waitlistEntriesTable).
+		OrderBy("created_at ASC", "id ASC")
+
+	if len(criteria.Statuses) > 0 {
+		statuses := make([]string, 0, len(criteria.Statuses))
+		for _, st := range criteria.Statuses {
+			statuses = append(statuses, string(st))
+		}
+		query = query.Where(sq.Eq{"status": statuses})
+	}
+	if criteria.ClientWhatsApp != nil {
+		query = query.Where(sq.Eq{"client_whatsapp": *criteria.ClientWhatsApp})
+	}
+	if criteria.Limit > 0 {
+		query = query.Limit(uint64(criteria.Limit))
+	}
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []waitlistEntryRow
+	if err := s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*waitlist.Entry, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+
+	return result, nil
+}
+
+// MarkWaitlistEntryNotified отмечает что ассистент уведомлен и фиксирует срок удержания цены
+func (s *storageImpl) MarkWaitlistEntryNotified(ctx context.Context, id int64, notifiedAt, priceHeldUntil time.Time) error {
+	q, args, err := s.stmpBuilder().
+		Update(waitlistEntriesTable).
+		Set("status", string(waitlist.StatusNotified)).
+		Set("notified_at", notifiedAt).
+		Set("price_held_until", priceHeldUntil).
+		Set("updated_at", s.now()).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateWaitlistEntryStatus меняет статус записи. Возврат в waiting сбрасывает удержание цены
+func (s *storageImpl) UpdateWaitlistEntryStatus(ctx context.Context, id int64, status waitlist.Status) error {
+	query := s.stmpBuilder().
+		Update(waitlistEntriesTable).
+		Set("status", string(status)).
+		Set("updated_at", s.now()).
+		Where(sq.Eq{"id": id})
+
+	if status == waitlist.StatusWaiting {
+		query = query.Set("notified_at", nil).Set("price_held_until", nil)
+	}
+
+	q, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/websessions"
+)
+
+const webSessionsTable = "web_sessions"
+
+var webSessionRowFields = fields(webSessionRow{})
+
+type webSessionRow struct {
+	ID         int64     `db:"id"`
+	TokenHash  string    `db:"token_hash"`
+	TelegramID int64     `db:"telegram_id"`
+	ExpiresAt  time.Time `db:"expires_at"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func (r webSessionRow) ToModel() *websessions.Session {
+	return &websessions.Session{
+		ID:         r.ID,
+		TokenHash:  r.TokenHash,
+		TelegramID: r.TelegramID,
+		ExpiresAt:  r.ExpiresAt,
+		CreatedAt:  r.CreatedAt,
+	}
+}
+
+// CreateWebSession сохраняет сессию веб-админки
+func (s *storageImpl) CreateWebSession(ctx context.Context, session websessions.Session) (*websessions.Session, error) {
+	params := map[string]interface{}{
+		"token_hash":  session.TokenHash,
+		"telegram_id": session.TelegramID,
+		"expires_at":  session.ExpiresAt,
+		"created_at":  s.now(),
+	}
+
+	q, args, err := s.stmpBuilder().
+		Insert(webSessionsTable).
+		SetMap(params).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return nil, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return s.GetWebSession(ctx, session.TokenHash)
+}
+
+// GetWebSession возвращает сессию по хешу токена; nil - сессии нет
+func (s *storageImpl) GetWebSession(ctx context.Context, tokenHash string) (*websessions.Session, error) {
+	q, args, err := s.stmpBuilder().
+		Select(webSessionRowFields).
+		From(webSessionsTable).
+		Where(sq.Eq{"token_hash": tokenHash}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var row webSessionRow
+	if err := s.db.GetContext(ctx, &row, q, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db.GetContext: %w", err)
+	}
+
+	return row.ToModel(), nil
+}
+
+// DeleteWebSession удаляет сессию (выход из веб-админки)
+func (s *storageImpl) DeleteWebSession(ctx context.Context, tokenHash string) error {
+	q, args, err := s.stmpBuilder().
+		Delete(webSessionsTable).
+		Where(sq.Eq{"token_hash": tokenHash}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredWebSessions удаляет сессии, истекшие к моменту now
+func (s *storageImpl) DeleteExpiredWebSessions(ctx context.Context, now time.Time) (int64, error) {
+	q, args, err := s.stmpBuilder().
+		Delete(webSessionsTable).
+		Where(sq.LtOrEq{"expires_at": now}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/watemplates"
+)
+
+const whatsAppTemplatesTable = "whatsapp_templates"
+
+var whatsAppTemplateRowFields = fields(whatsAppTemplateRow{})
+
+type whatsAppTemplateRow struct {
+	Name                string    `db:"name"`
+	Language            string    `db:"language"`
+	Text                string    `db:"text"`
+	UpdatedByTelegramID int64     `db:"updated_by_telegram_id"`
+	UpdatedAt           time.Time `db:"updated_at"`
+}
+
+func (r whatsAppTemplateRow) ToModel() *watemplates.Template {
+	return &watemplates.Template{
+		Name:                watemplates.Name(r.Name),
+		Language:            clientlang.Language(r.Language),
+		Text:                r.Text,
+		UpdatedByTelegramID: r.UpdatedByTelegramID,
+		UpdatedAt:           r.UpdatedAt,
+	}
+}
+
+// ListWhatsAppTemplates возвращает тексты шаблонов, измененные админом
+func (s *storageImpl) ListWhatsAppTemplates(ctx context.Context) ([]*watemplates.Template, error) {
+	q, args, err := s.stmpBuilder().
+		Select(whatsAppTemplateRowFields).
+		From(whatsAppTemplatesTable).
+		OrderBy("name", "language").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql query: %w", err)
+	}
+
+	var rows []whatsAppTemplateRow
+	if err = s.db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, fmt.Errorf("db.SelectContext: %w", err)
+	}
+
+	result := make([]*watemplates.Template, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.ToModel())
+	}
+	return result, nil
+}
+
+// SaveWhatsAppTemplate сохраняет текст шаблона на языке, заменяя прежний
+func (s *storageImpl) SaveWhatsAppTemplate(ctx context.Context, t watemplates.Template) error {
+	q, args, err := s.stmpBuilder().
+		Insert(whatsAppTemplatesTable).
+		Columns("name", "language", "text", "updated_by_telegram_id", "updated_at").
+		Values(string(t.Name), string(t.Language), t.Text, t.UpdatedByTelegramID, s.now()).
+		Suffix("ON CONFLICT(name, language) DO UPDATE SET " +
+			"text = excluded.text, updated_by_telegram_id = excluded.updated_by_telegram_id, updated_at = excluded.updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// DeleteWhatsAppTemplate возвращает шаблону встроенный текст; false - текст и не менялся
+func (s *storageImpl) DeleteWhatsAppTemplate(ctx context.Context, name watemplates.Name, lang clientlang.Language) (bool, error) {
+	q, args, err := s.stmpBuilder().
+		Delete(whatsAppTemplatesTable).
+		Where(sq.Eq{"name": string(name), "language": string(lang)}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("result.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const workerLocksTable = "worker_locks"
+
+// AcquireWorkerLock берет или продлевает блокировку name на ttl. Чужую блокировку можно забрать только
+// после истечения. false - блокировку держит другой процесс
+func (s *storageImpl) AcquireWorkerLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	now := s.now()
+	q, args, err := s.stmpBuilder().
+		Insert(workerLocksTable).
+		Columns("name", "owner", "expires_at").
+		Values(name, owner, now.Add(ttl)).
+		Suffix("ON CONFLICT(name) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at "+
+			"WHERE worker_locks.owner = excluded.owner OR worker_locks.expires_at <= ?", now).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build sql query: %w", err)
+	}
+
+	res, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("db.ExecContext: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ReleaseWorkerLock снимает блокировку name, если ее держит owner
+func (s *storageImpl) ReleaseWorkerLock(ctx context.Context, name, owner string) error {
+	q, args, err := s.stmpBuilder().
+		Delete(workerLocksTable).
+		Where(sq.Eq{"name": name, "owner": owner}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build sql query: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("db.ExecContext: %w", err)
+	}
+	return nil
+}
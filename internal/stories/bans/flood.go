@@ -0,0 +1,70 @@
+package bans
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepThreshold - после скольких отслеживаемых пользователей чистим тех, кто давно молчит
+const sweepThreshold = 1000
+
+// FloodGuard считает апдейты пользователя в скользящем окне и сообщает о превышении лимита
+type FloodGuard struct {
+	limit  int
+	window time.Duration
+
+	mu   sync.Mutex
+	hits map[int64][]time.Time
+}
+
+// NewFloodGuard создает счетчик; limit <= 0 выключает проверку
+func NewFloodGuard(limit int, window time.Duration) *FloodGuard {
+	return &FloodGuard{
+		limit:  limit,
+		window: window,
+		hits:   make(map[int64][]time.Time),
+	}
+}
+
+// Hit учитывает апдейт пользователя и возвращает true, если за окно пришло больше limit апдейтов.
+// После превышения счетчик пользователя сбрасывается, чтобы не срабатывать на каждый следующий апдейт
+func (g *FloodGuard) Hit(telegramID int64, now time.Time) bool {
+	if g.limit <= 0 {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.hits) > sweepThreshold {
+		g.sweep(now)
+	}
+
+	recent := g.recent(g.hits[telegramID], now)
+	recent = append(recent, now)
+	if len(recent) > g.limit {
+		delete(g.hits, telegramID)
+		return true
+	}
+	g.hits[telegramID] = recent
+
+	return false
+}
+
+// recent оставляет апдейты, попавшие в окно
+func (g *FloodGuard) recent(hits []time.Time, now time.Time) []time.Time {
+	from := now.Add(-g.window)
+	i := 0
+	for i < len(hits) && !hits[i].After(from) {
+		i++
+	}
+	return hits[i:]
+}
+
+func (g *FloodGuard) sweep(now time.Time) {
+	for id, hits := range g.hits {
+		if len(g.recent(hits, now)) == 0 {
+			delete(g.hits, id)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package bans
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFloodGuard(t *testing.T) {
+	guard := NewFloodGuard(3, 10*time.Second)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := range 3 {
+		if guard.Hit(1, now.Add(time.Duration(i)*time.Second)) {
+			t.Fatalf("hit %d: flood detected within limit", i+1)
+		}
+	}
+	if guard.Hit(2, now) {
+		t.Error("other user: flood detected, counters must be per user")
+	}
+	if !guard.Hit(1, now.Add(3*time.Second)) {
+		t.Error("4th hit within window: flood not detected")
+	}
+	if guard.Hit(1, now.Add(4*time.Second)) {
+		t.Error("hit after detection: counter was not reset")
+	}
+
+	// Старые апдейты выпадают из окна
+	slow := NewFloodGuard(2, 10*time.Second)
+	for i := range 10 {
+		if slow.Hit(1, now.Add(time.Duration(i)*6*time.Second)) {
+			t.Fatalf("hit %d every 6s: flood detected", i+1)
+		}
+	}
+
+	if NewFloodGuard(0, time.Second).Hit(1, now) {
+		t.Error("disabled guard detected flood")
+	}
+}
@@ -0,0 +1,17 @@
+package bans
+
+import "time"
+
+// Ban - блокировка пользователя бота
+type Ban struct {
+	ID         int64
+	TelegramID int64
+	Reason     string
+	BannedBy   *int64 // nil - бан выдан автоматически
+	CreatedAt  time.Time
+}
+
+// IsAuto проверяет что бан выдан автоматически, а не админом
+func (b *Ban) IsAuto() bool {
+	return b.BannedBy == nil
+}
@@ -0,0 +1,7 @@
+package bonusrules
+
+import "context"
+
+type Storage interface {
+	ListBonusRules(ctx context.Context, trigger Trigger) ([]Rule, error)
+}
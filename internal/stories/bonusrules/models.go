@@ -0,0 +1,107 @@
+package bonusrules
+
+import (
+	"math"
+	"time"
+)
+
+// Trigger - событие, на которое срабатывает правило
+type Trigger string
+
+const (
+	// TriggerReferralPaid - первая оплата клиента, пришедшего по рекомендации; бонус получает пригласивший
+	TriggerReferralPaid Trigger = "referral_paid"
+	// TriggerSubscriptionPaid - оплачена новая подписка; бонус получает сам клиент
+	TriggerSubscriptionPaid Trigger = "subscription_paid"
+)
+
+// Action - что делает правило
+type Action string
+
+const (
+	ActionBonusDays Action = "bonus_days" // продлить подписку на BonusDays дней
+	ActionDiscount  Action = "discount"   // скидка DiscountPercent% на продление
+	ActionNotify    Action = "notify"     // показать ассистенту NotifyText
+)
+
+// Channel - откуда создана подписка
+type Channel string
+
+const (
+	ChannelAssistant Channel = "assistant" // ассистент проверил оплату во флоу
+	ChannelAutoCheck Channel = "autocheck" // оплату нашел воркер автопроверки
+)
+
+// Rule - правило начисления бонуса. Пустые условия (nil) подходят под любое событие
+type Rule struct {
+	ID              int64
+	Name            string
+	Trigger         Trigger
+	TariffID        *int64
+	MinAmount       *float64
+	Channel         *Channel
+	Action          Action
+	BonusDays       int
+	DiscountPercent int
+	NotifyText      string
+	IsActive        bool
+	CreatedAt       time.Time
+}
+
+// Event - произошедшее событие, по которому подбираются правила
+type Event struct {
+	Trigger  Trigger
+	TariffID int64
+	Amount   float64
+	Channel  Channel
+}
+
+// Matches проверяет что правило активно и все его условия выполняются для события
+func (r Rule) Matches(e Event) bool {
+	if !r.IsActive || r.Trigger != e.Trigger {
+		return false
+	}
+	if r.TariffID != nil && *r.TariffID != e.TariffID {
+		return false
+	}
+	if r.MinAmount != nil && e.Amount < *r.MinAmount {
+		return false
+	}
+	if r.Channel != nil && *r.Channel != e.Channel {
+		return false
+	}
+	return true
+}
+
+// Outcome - суммарный результат всех сработавших правил
+type Outcome struct {
+	BonusDays       int      // дни складываются
+	DiscountPercent int      // берется наибольшая скидка
+	Notifications   []string // тексты для ассистента
+}
+
+// Evaluate применяет к событию все подходящие правила
+func Evaluate(rules []Rule, e Event) Outcome {
+	var out Outcome
+	for _, r := range rules {
+		if !r.Matches(e) {
+			continue
+		}
+		switch r.Action {
+		case ActionBonusDays:
+			out.BonusDays += r.BonusDays
+		case ActionDiscount:
+			out.DiscountPercent = max(out.DiscountPercent, min(r.DiscountPercent, 100))
+		case ActionNotify:
+			if r.NotifyText != "" {
+				out.Notifications = append(out.Notifications, r.NotifyText)
+			}
+		}
+	}
+	return out
+}
+
+// DiscountedPrice возвращает цену со скидкой, округленную до рубля
+func DiscountedPrice(price float64, percent int) float64 {
+	return math.Round(price * float64(100-percent) / 100)
+}
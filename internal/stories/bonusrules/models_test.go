@@ -0,0 +1,65 @@
+package bonusrules
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	tariffID := int64(3)
+	minAmount := 500.0
+	autocheck := ChannelAutoCheck
+
+	rules := []Rule{
+		{Trigger: TriggerReferralPaid, Action: ActionBonusDays, BonusDays: 10, IsActive: true},
+		{Trigger: TriggerReferralPaid, Action: ActionBonusDays, BonusDays: 5, TariffID: &tariffID, IsActive: true},
+		{Trigger: TriggerReferralPaid, Action: ActionDiscount, DiscountPercent: 20, MinAmount: &minAmount, IsActive: true},
+		{Trigger: TriggerReferralPaid, Action: ActionNotify, NotifyText: "автопроверка", Channel: &autocheck, IsActive: true},
+		{Trigger: TriggerReferralPaid, Action: ActionBonusDays, BonusDays: 100, IsActive: false},
+		{Trigger: TriggerSubscriptionPaid, Action: ActionBonusDays, BonusDays: 3, IsActive: true},
+	}
+
+	tests := []struct {
+		name  string
+		event Event
+		want  Outcome
+	}{
+		{
+			name:  "base referral rule only",
+			event: Event{Trigger: TriggerReferralPaid, TariffID: 1, Amount: 300, Channel: ChannelAssistant},
+			want:  Outcome{BonusDays: 10},
+		},
+		{
+			name:  "tariff and amount conditions",
+			event: Event{Trigger: TriggerReferralPaid, TariffID: 3, Amount: 500, Channel: ChannelAssistant},
+			want:  Outcome{BonusDays: 15, DiscountPercent: 20},
+		},
+		{
+			name:  "channel condition",
+			event: Event{Trigger: TriggerReferralPaid, TariffID: 1, Amount: 300, Channel: ChannelAutoCheck},
+			want:  Outcome{BonusDays: 10, Notifications: []string{"автопроверка"}},
+		},
+		{
+			name:  "other trigger",
+			event: Event{Trigger: TriggerSubscriptionPaid, TariffID: 3, Amount: 1000},
+			want:  Outcome{BonusDays: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Evaluate(rules, tt.event); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscountedPrice(t *testing.T) {
+	if got := DiscountedPrice(350, 15); got != 298 {
+		t.Errorf("DiscountedPrice(350, 15) = %v, want 298", got)
+	}
+	if got := DiscountedPrice(500, 100); got != 0 {
+		t.Errorf("DiscountedPrice(500, 100) = %v, want 0", got)
+	}
+}
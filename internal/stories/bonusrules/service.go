@@ -0,0 +1,24 @@
+package bonusrules
+
+import (
+	"context"
+	"fmt"
+)
+
+type Service struct {
+	storage Storage
+}
+
+// NewService создает движок правил; правила читаются из таблицы bonus_rules при каждом событии
+func NewService(storage Storage) *Service {
+	return &Service{storage: storage}
+}
+
+// Evaluate находит активные правила для события и возвращает суммарный бонус
+func (s *Service) Evaluate(ctx context.Context, e Event) (Outcome, error) {
+	rules, err := s.storage.ListBonusRules(ctx, e.Trigger)
+	if err != nil {
+		return Outcome{}, fmt.Errorf("list bonus rules: %w", err)
+	}
+	return Evaluate(rules, e), nil
+}
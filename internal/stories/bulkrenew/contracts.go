@@ -0,0 +1,19 @@
+package bulkrenew
+
+import (
+	"context"
+
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/tariffs"
+)
+
+type Storage interface {
+	ClaimBulkRenewal(ctx context.Context, id int64, status Status) (bool, error)
+	GetPaymentSubscriptions(ctx context.Context, paymentID int64) ([]int64, error)
+	GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error)
+	UpdateSubscription(ctx context.Context, criteria subs.GetCriteria, params subs.UpdateParams) (*subs.Subscription, error)
+	UpdateSubscriptionTariff(ctx context.Context, subscriptionID int64, tariffID int64) error
+	ExtendSubscription(ctx context.Context, subscriptionID int64, additionalDays int) error
+	DeactivateAllSubscriptionMessages(ctx context.Context, subscriptionID int64) error
+	GetTariff(ctx context.Context, criteria tariffs.GetCriteria) (*tariffs.Tariff, error)
+}
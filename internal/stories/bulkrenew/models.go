@@ -0,0 +1,71 @@
+package bulkrenew
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// ExpiringWithinDays - в список массового продления попадают подписки, истекающие в ближайшие N дней, и просроченные
+	ExpiringWithinDays = 3
+	// MaxSubscriptions - сколько подписок можно показать и выбрать в одном продлении
+	MaxSubscriptions = 40
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // ссылка выдана, ждем оплату
+	StatusCompleted Status = "completed" // оплачено, подписки продлены
+	StatusCancelled Status = "cancelled" // платеж отклонен или отменен
+)
+
+// BulkRenewal - один платеж за продление нескольких подписок
+type BulkRenewal struct {
+	ID                  int64
+	PaymentID           int64
+	AssistantTelegramID int64
+	ChatID              int64
+	MessageID           *int
+	TotalAmount         float64
+	Status              Status
+	CompletedAt         *time.Time
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// RenewedSubscription - подписка, продленная по массовому платежу
+type RenewedSubscription struct {
+	SubscriptionID int64
+	ClientWhatsApp *string
+	TariffName     string
+	DurationDays   int
+}
+
+// Result - итог обработки оплаченного массового продления
+type Result struct {
+	Renewed []RenewedSubscription
+	Failed  []int64 // подписки, которые не удалось продлить
+}
+
+// Summary возвращает текст для сообщения ассистенту
+func (r *Result) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "✅ Продлено подписок: %d\n", len(r.Renewed))
+	for _, item := range r.Renewed {
+		client := "—"
+		if item.ClientWhatsApp != nil && *item.ClientWhatsApp != "" {
+			client = *item.ClientWhatsApp
+		}
+		fmt.Fprintf(&b, "\n#%d · %s · %s (+%d дн.)", item.SubscriptionID, client, item.TariffName, item.DurationDays)
+	}
+	if len(r.Failed) > 0 {
+		ids := make([]string, 0, len(r.Failed))
+		for _, id := range r.Failed {
+			ids = append(ids, fmt.Sprintf("#%d", id))
+		}
+		fmt.Fprintf(&b, "\n\n⚠️ Не удалось продлить: %s\nПродлите их вручную.", strings.Join(ids, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,35 @@
+package bulkrenew
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResultSummary(t *testing.T) {
+	whatsapp := "996555123456"
+	r := Result{
+		Renewed: []RenewedSubscription{
+			{SubscriptionID: 12, ClientWhatsApp: &whatsapp, TariffName: "Месяц", DurationDays: 30},
+			{SubscriptionID: 15, TariffName: "Неделя", DurationDays: 7},
+		},
+	}
+
+	got := r.Summary()
+	for _, want := range []string{
+		"Продлено подписок: 2",
+		"#12 · 996555123456 · Месяц (+30 дн.)",
+		"#15 · — · Неделя (+7 дн.)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Summary() = %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "Не удалось") {
+		t.Errorf("Summary() = %q, want no failures section", got)
+	}
+
+	r.Failed = []int64{20, 21}
+	if got := r.Summary(); !strings.Contains(got, "Не удалось продлить: #20, #21") {
+		t.Errorf("Summary() = %q, want failed subscriptions listed", got)
+	}
+}
@@ -0,0 +1,126 @@
+package bulkrenew
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/tariffs"
+
+	"github.com/pkg/errors"
+)
+
+// ErrAlreadyProcessed - продление уже обработано (другим обработчиком или раньше)
+var ErrAlreadyProcessed = errors.New("bulk renewal already processed")
+
+type Service struct {
+	storage Storage
+	logger  *slog.Logger
+}
+
+func NewService(storage Storage, logger *slog.Logger) *Service {
+	return &Service{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Complete продлевает все подписки, связанные с оплаченным платежом, на срок их тарифа.
+// Продление резервируется атомарно, поэтому воркер и кнопка проверки не продлят подписки дважды
+func (s *Service) Complete(ctx context.Context, renewal *BulkRenewal) (*Result, error) {
+	subscriptionIDs, err := s.storage.GetPaymentSubscriptions(ctx, renewal.PaymentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get payment subscriptions")
+	}
+
+	claimed, err := s.storage.ClaimBulkRenewal(ctx, renewal.ID, StatusCompleted)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to claim bulk renewal")
+	}
+	if !claimed {
+		return nil, ErrAlreadyProcessed
+	}
+
+	result := &Result{}
+	for _, subID := range subscriptionIDs {
+		renewed, err := s.extend(ctx, subID)
+		if err != nil {
+			s.logger.Error("Failed to extend subscription in bulk renewal",
+				"error", err,
+				"bulk_renewal_id", renewal.ID,
+				"sub_id", subID)
+			result.Failed = append(result.Failed, subID)
+			continue
+		}
+		result.Renewed = append(result.Renewed, *renewed)
+	}
+
+	s.logger.Info("Bulk renewal completed",
+		"audit", true,
+		"bulk_renewal_id", renewal.ID,
+		"payment_id", renewal.PaymentID,
+		"assistant_telegram_id", renewal.AssistantTelegramID,
+		"amount", renewal.TotalAmount,
+		"renewed", len(result.Renewed),
+		"failed", len(result.Failed))
+
+	return result, nil
+}
+
+// Cancel помечает продление отмененным, если платеж отклонен. false - продление уже обработано
+func (s *Service) Cancel(ctx context.Context, renewal *BulkRenewal) (bool, error) {
+	claimed, err := s.storage.ClaimBulkRenewal(ctx, renewal.ID, StatusCancelled)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to claim bulk renewal")
+	}
+	return claimed, nil
+}
+
+// extend продлевает одну подписку на срок ее тарифа и снимает старые напоминания об оплате
+func (s *Service) extend(ctx context.Context, subID int64) (*RenewedSubscription, error) {
+	sub, err := s.storage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{subID}})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get subscription")
+	}
+	if sub == nil {
+		return nil, errors.Errorf("subscription %d not found", subID)
+	}
+
+	// После окончания промо подписка продлевается на тариф, указанный у промо
+	tariff, err := tariffs.RenewalTariff(ctx, s.storage, sub.TariffID, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tariff")
+	}
+	if tariff == nil {
+		return nil, errors.Errorf("tariff %d not found", sub.TariffID)
+	}
+	if tariff.ID != sub.TariffID {
+		if err := s.storage.UpdateSubscriptionTariff(ctx, subID, tariff.ID); err != nil {
+			return nil, errors.Wrap(err, "failed to update subscription tariff")
+		}
+	}
+
+	if err := s.storage.ExtendSubscription(ctx, subID, tariff.DurationDays); err != nil {
+		return nil, errors.Wrap(err, "failed to extend subscription")
+	}
+
+	activeStatus := subs.StatusActive
+	if _, err := s.storage.UpdateSubscription(ctx, subs.GetCriteria{IDs: []int64{subID}}, subs.UpdateParams{
+		Status: &activeStatus,
+	}); err != nil {
+		s.logger.Error("Failed to update subscription status", "error", err, "sub_id", subID)
+	}
+
+	// Старые ссылки из напоминаний не должны продлить подписку второй раз
+	if err := s.storage.DeactivateAllSubscriptionMessages(ctx, subID); err != nil {
+		s.logger.Error("Failed to deactivate subscription messages", "error", err, "sub_id", subID)
+	}
+
+	return &RenewedSubscription{
+		SubscriptionID: subID,
+		ClientWhatsApp: sub.ClientWhatsApp,
+		TariffName:     tariff.Name,
+		DurationDays:   tariff.DurationDays,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package clientlang
+
+import "strings"
+
+// Language - язык, на котором ассистент пишет клиенту в WhatsApp
+type Language string
+
+const (
+	// Default - язык не определен, используются исторические шаблоны
+	Default Language = ""
+	Kyrgyz  Language = "ky"
+	Russian Language = "ru"
+	Uzbek   Language = "uz"
+)
+
+// Supported - языки, доступные для выбора ассистентом
+var Supported = []Language{Kyrgyz, Russian, Uzbek}
+
+// phonePrefixes - определение языка по коду страны номера
+var phonePrefixes = []struct {
+	prefix string
+	lang   Language
+}{
+	{"996", Kyrgyz},
+	{"998", Uzbek},
+	{"7", Russian},
+}
+
+// Title возвращает название языка для кнопок
+func (l Language) Title() string {
+	switch l {
+	case Kyrgyz:
+		return "🇰🇬 Кыргызча"
+	case Russian:
+		return "🇷🇺 Русский"
+	case Uzbek:
+		return "🇺🇿 Oʻzbekcha"
+	default:
+		return "По умолчанию"
+	}
+}
+
+// IsSupported проверяет что язык можно выбрать
+func (l Language) IsSupported() bool {
+	for _, s := range Supported {
+		if s == l {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect определяет язык по коду страны номера WhatsApp
+func Detect(phone string) Language {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	for _, p := range phonePrefixes {
+		if strings.HasPrefix(digits, p.prefix) {
+			return p.lang
+		}
+	}
+	return Default
+}
+
+// Resolve возвращает язык клиента: выбранный ассистентом, иначе определенный по номеру
+func Resolve(stored Language, phone string) Language {
+	if stored.IsSupported() {
+		return stored
+	}
+	return Detect(phone)
+}
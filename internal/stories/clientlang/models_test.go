@@ -0,0 +1,26 @@
+package clientlang
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		stored Language
+		phone  string
+		want   Language
+	}{
+		{Default, "+996 555 123 456", Kyrgyz},
+		{Default, "+998901234567", Uzbek},
+		{Default, "+7 (926) 330-85-06", Russian},
+		{Default, "+49 151 1234567", Default},
+		{Russian, "+996555123456", Russian},
+		{Language("de"), "+996555123456", Kyrgyz},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.stored)+tt.phone, func(t *testing.T) {
+			if got := Resolve(tt.stored, tt.phone); got != tt.want {
+				t.Errorf("Resolve(%q, %q) = %q, want %q", tt.stored, tt.phone, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package commissions
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Rate - комиссия ассистента за каждое оплаченное создание или продление подписки:
+// процент от оплаты плюс фиксированная сумма
+type Rate struct {
+	Percent  float64
+	FixedFee float64
+}
+
+// Enabled проверяет что комиссия настроена
+func (r Rate) Enabled() bool {
+	return r.Percent > 0 || r.FixedFee > 0
+}
+
+// Commission считает комиссию за sales оплат на общую сумму revenue, с округлением до копеек
+func (r Rate) Commission(revenue float64, sales int) float64 {
+	amount := revenue*r.Percent/100 + r.FixedFee*float64(sales)
+	return math.Round(amount*100) / 100
+}
+
+// String описывает ставку для сообщений
+func (r Rate) String() string {
+	switch {
+	case r.Percent > 0 && r.FixedFee > 0:
+		return fmt.Sprintf("%g%% + %g ₽ за оплату", r.Percent, r.FixedFee)
+	case r.Percent > 0:
+		return fmt.Sprintf("%g%% от оплаты", r.Percent)
+	default:
+		return fmt.Sprintf("%g ₽ за оплату", r.FixedFee)
+	}
+}
+
+// Settlement - выплата комиссии ассистенту за продажи в [PeriodFrom, PeriodTo)
+type Settlement struct {
+	ID                  int64
+	AssistantTelegramID int64
+	PeriodFrom          time.Time
+	PeriodTo            time.Time
+	Amount              float64
+	CreatedByTelegramID int64 // админ, отметивший выплату
+	CreatedAt           time.Time
+}
@@ -0,0 +1,28 @@
+package commissions
+
+import "testing"
+
+func TestRateCommission(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    Rate
+		revenue float64
+		sales   int
+		want    float64
+	}{
+		{name: "percent", rate: Rate{Percent: 10}, revenue: 1990, sales: 5, want: 199},
+		{name: "fixed fee", rate: Rate{FixedFee: 50}, revenue: 1990, sales: 5, want: 250},
+		{name: "both", rate: Rate{Percent: 5, FixedFee: 20}, revenue: 1000, sales: 3, want: 110},
+		{name: "rounded to kopecks", rate: Rate{Percent: 7.5}, revenue: 333.33, sales: 1, want: 25},
+		{name: "no sales", rate: Rate{Percent: 10, FixedFee: 50}, revenue: 0, sales: 0, want: 0},
+	}
+	for _, tt := range tests {
+		if got := tt.rate.Commission(tt.revenue, tt.sales); got != tt.want {
+			t.Errorf("%s: Commission() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if (Rate{}).Enabled() {
+		t.Error("zero rate is enabled")
+	}
+}
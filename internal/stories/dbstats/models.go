@@ -0,0 +1,71 @@
+package dbstats
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// TableRows - количество строк в таблице
+type TableRows struct {
+	Name string
+	Rows int64
+}
+
+// Stats - состояние файла SQLite для /db_stats
+type Stats struct {
+	Path               string
+	FileSize           int64 // страницы базы: page_count * page_size
+	FreeSize           int64 // свободные страницы, которые вернет VACUUM
+	WALSize            int64 // размер -wal файла; 0 - файла нет или журнал не WAL
+	JournalMode        string
+	Tables             []TableRows
+	OldestPendingOrder *time.Time // самый старый заказ в статусе pending; nil - таких нет
+	UserIDRenames      []UserIDRename
+}
+
+// UserIDRename - generated_user_id, переименованный миграцией 047 из-за повтора.
+// На VPN-сервере пользователь остался под старым именем - его нужно переименовать вручную
+type UserIDRename struct {
+	SubscriptionID int64  `db:"subscription_id"`
+	OldUserID      string `db:"old_user_id"`
+	NewUserID      string `db:"new_user_id"`
+}
+
+// TotalRows - строк во всех таблицах
+func (s Stats) TotalRows() int64 {
+	var total int64
+	for _, t := range s.Tables {
+		total += t.Rows
+	}
+	return total
+}
+
+// Largest возвращает n таблиц с наибольшим числом строк (при равенстве - по имени)
+func (s Stats) Largest(n int) []TableRows {
+	tables := slices.Clone(s.Tables)
+	slices.SortFunc(tables, func(a, b TableRows) int {
+		if c := cmp.Compare(b.Rows, a.Rows); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return tables[:min(n, len(tables))]
+}
+
+// FormatBytes - размер для людей: 512 Б, 1.5 КБ, 12.3 МБ
+func FormatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d Б", size)
+	}
+	value := float64(size) / unit
+	for _, suffix := range []string{"КБ", "МБ"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f ГБ", value)
+}
@@ -0,0 +1,47 @@
+package dbstats
+
+import "testing"
+
+func TestLargest(t *testing.T) {
+	s := Stats{Tables: []TableRows{
+		{Name: "users", Rows: 10},
+		{Name: "payments", Rows: 500},
+		{Name: "subscriptions", Rows: 120},
+		{Name: "bans", Rows: 10},
+	}}
+
+	got := s.Largest(3)
+	want := []string{"payments", "subscriptions", "bans"}
+	if len(got) != len(want) {
+		t.Fatalf("Largest(3) = %v", got)
+	}
+	for i, name := range want {
+		if got[i].Name != name {
+			t.Errorf("Largest(3)[%d] = %s, want %s", i, got[i].Name, name)
+		}
+	}
+	if s.Tables[0].Name != "users" {
+		t.Error("Largest must not reorder Stats.Tables")
+	}
+	if len(s.Largest(10)) != 4 {
+		t.Error("Largest(10) must return all tables")
+	}
+	if s.TotalRows() != 640 {
+		t.Errorf("TotalRows() = %d", s.TotalRows())
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:                "0 Б",
+		512:              "512 Б",
+		1536:             "1.5 КБ",
+		12 * 1024 * 1024: "12.0 МБ",
+		3 << 30:          "3.0 ГБ",
+	}
+	for size, want := range tests {
+		if got := FormatBytes(size); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package devices
+
+// Platform - платформа устройства клиента, под которую подбирается инструкция по подключению
+type Platform string
+
+const (
+	// Unknown - платформа не указана, клиенту уходит общая инструкция
+	Unknown Platform = ""
+	IOS     Platform = "ios"
+	Android Platform = "android"
+	Windows Platform = "windows"
+	Router  Platform = "router"
+)
+
+// Supported - платформы, доступные для выбора ассистентом
+var Supported = []Platform{IOS, Android, Windows, Router}
+
+// Title возвращает название платформы для кнопок
+func (p Platform) Title() string {
+	switch p {
+	case IOS:
+		return "🍏 iOS"
+	case Android:
+		return "🤖 Android"
+	case Windows:
+		return "💻 Windows"
+	case Router:
+		return "📡 Роутер"
+	default:
+		return "не указана"
+	}
+}
+
+// IsSupported проверяет что платформу можно выбрать
+func (p Platform) IsSupported() bool {
+	for _, s := range Supported {
+		if s == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package devices
+
+import "testing"
+
+func TestPlatformIsSupported(t *testing.T) {
+	for _, p := range Supported {
+		if !p.IsSupported() {
+			t.Errorf("%q.IsSupported() = false", p)
+		}
+		if p.Title() == Unknown.Title() {
+			t.Errorf("%q has no title", p)
+		}
+	}
+	for _, p := range []Platform{Unknown, "macos", "IOS"} {
+		if p.IsSupported() {
+			t.Errorf("%q.IsSupported() = true, want false", p)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package drip
+
+import "time"
+
+// Step - этап рассылки клиентам на пробном периоде
+type Step string
+
+const (
+	StepCheckIn Step = "checkin" // 2-й день пробного периода: как впечатления
+	StepUpgrade Step = "upgrade" // последний день пробного периода: оплатить со скидкой
+	StepWinBack Step = "winback" // пробный период закончился, а клиент не оплатил
+)
+
+// Steps - этапы в порядке отправки
+var Steps = []Step{StepCheckIn, StepUpgrade, StepWinBack}
+
+const (
+	// CheckInAfter - через сколько после начала пробного периода спрашиваем о впечатлениях
+	CheckInAfter = 24 * time.Hour
+	// UpgradeBefore - за сколько до окончания предлагаем оплатить
+	UpgradeBefore = 24 * time.Hour
+	// WinBackAfter - через сколько после окончания пробуем вернуть клиента
+	WinBackAfter = 24 * time.Hour
+	// WinBackWindow - позже этого срока после окончания win-back уже не отправляется
+	WinBackWindow = 7 * 24 * time.Hour
+)
+
+// Title возвращает название этапа для карточек и статистики
+func (s Step) Title() string {
+	switch s {
+	case StepCheckIn:
+		return "Как впечатления"
+	case StepUpgrade:
+		return "Оплата со скидкой"
+	case StepWinBack:
+		return "Возврат клиента"
+	default:
+		return string(s)
+	}
+}
+
+// Message - отправленный этап рассылки по пробной подписке
+type Message struct {
+	ID                  int64
+	SubscriptionID      int64
+	ClientWhatsApp      string
+	Step                Step
+	AssistantTelegramID int64
+	SentAt              time.Time
+}
+
+// StepStats - результаты этапа: сколько отправлено и сколько клиентов оплатили после сообщения
+type StepStats struct {
+	Step      Step `db:"step"`
+	Sent      int  `db:"sent"`
+	Converted int  `db:"converted"`
+}
+
+// ConversionRate возвращает долю оплативших в процентах
+func (s StepStats) ConversionRate() float64 {
+	if s.Sent == 0 {
+		return 0
+	}
+	return float64(s.Converted) / float64(s.Sent) * 100
+}
+
+// DueStep возвращает этап, который пора отправить по пробной подписке в момент now, или "" если отправлять нечего.
+// Окна этапов не пересекаются, поэтому пропущенный из-за простоя этап не догоняется устаревшим сообщением
+func DueStep(createdAt time.Time, expiresAt *time.Time, now time.Time, sent map[Step]bool) Step {
+	var due Step
+	switch {
+	case expiresAt == nil:
+		if !now.Before(createdAt.Add(CheckInAfter)) {
+			due = StepCheckIn
+		}
+	case !now.Before(expiresAt.Add(WinBackAfter)):
+		if now.Before(expiresAt.Add(WinBackWindow)) {
+			due = StepWinBack
+		}
+	case !now.Before(*expiresAt):
+		// Между окончанием и win-back - пауза
+	case !now.Before(expiresAt.Add(-UpgradeBefore)):
+		due = StepUpgrade
+	case !now.Before(createdAt.Add(CheckInAfter)):
+		due = StepCheckIn
+	}
+
+	if due == "" || sent[due] {
+		return ""
+	}
+	return due
+}
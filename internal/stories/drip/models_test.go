@@ -0,0 +1,37 @@
+package drip
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDueStep(t *testing.T) {
+	created := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	expires := created.AddDate(0, 0, 5)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		expires *time.Time
+		sent    map[Step]bool
+		want    Step
+	}{
+		{"first day", created.Add(2 * time.Hour), &expires, nil, ""},
+		{"second day", created.Add(CheckInAfter), &expires, nil, StepCheckIn},
+		{"check-in already sent", created.Add(30 * time.Hour), &expires, map[Step]bool{StepCheckIn: true}, ""},
+		{"day before expiry", expires.Add(-time.Hour), &expires, map[Step]bool{StepCheckIn: true}, StepUpgrade},
+		{"skips check-in when late", expires.Add(-time.Hour), &expires, nil, StepUpgrade},
+		{"pause after expiry", expires.Add(time.Hour), &expires, nil, ""},
+		{"win-back", expires.Add(WinBackAfter), &expires, nil, StepWinBack},
+		{"win-back window passed", expires.Add(WinBackWindow), &expires, nil, ""},
+		{"no expiry date", created.Add(CheckInAfter), nil, nil, StepCheckIn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DueStep(created, tt.expires, tt.now, tt.sent); got != tt.want {
+				t.Errorf("DueStep() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package escalations
+
+import (
+	"sort"
+	"time"
+)
+
+// SubjectType - к чему относится эскалация
+type SubjectType string
+
+const (
+	SubjectSubscription SubjectType = "subscription"
+	SubjectOrder        SubjectType = "order"
+)
+
+// Title возвращает название для сообщений админам
+func (t SubjectType) Title() string {
+	switch t {
+	case SubjectSubscription:
+		return "Подписка"
+	case SubjectOrder:
+		return "Заказ"
+	default:
+		return string(t)
+	}
+}
+
+type Status string
+
+const (
+	StatusOpen     Status = "open"     // ждет, пока админ возьмет
+	StatusTaken    Status = "taken"    // админ взял в работу
+	StatusResolved Status = "resolved" // вопрос решен
+)
+
+// Escalation - проблема по подписке или заказу, переданная ассистентом админам
+type Escalation struct {
+	ID                  int64
+	SubjectType         SubjectType
+	SubjectID           int64
+	CreatedByTelegramID int64
+	AssigneeTelegramID  *int64
+	Status              Status
+	CreatedAt           time.Time
+	TakenAt             *time.Time
+	ResolvedAt          *time.Time
+}
+
+// IsOpen возвращает true пока эскалация не решена
+func (e *Escalation) IsOpen() bool {
+	return e.Status != StatusResolved
+}
+
+// Event - событие из истории подписки или заказа для контекста эскалации
+type Event struct {
+	At   time.Time
+	Text string
+}
+
+// Recent возвращает последние limit событий в хронологическом порядке
+func Recent(events []Event, limit int) []Event {
+	sorted := make([]Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].At.Before(sorted[j].At)
+	})
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[len(sorted)-limit:]
+	}
+	return sorted
+}
@@ -0,0 +1,36 @@
+package escalations
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecent(t *testing.T) {
+	at := func(day int) time.Time {
+		return time.Date(2026, 10, day, 12, 0, 0, 0, time.UTC)
+	}
+	events := []Event{
+		{At: at(5), Text: "payment"},
+		{At: at(1), Text: "created"},
+		{At: at(9), Text: "click"},
+		{At: at(3), Text: "activated"},
+	}
+
+	got := Recent(events, 3)
+	want := []string{"activated", "payment", "click"}
+	if len(got) != len(want) {
+		t.Fatalf("Recent() returned %d events, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Text != want[i] {
+			t.Errorf("event %d = %q, want %q", i, got[i].Text, want[i])
+		}
+	}
+
+	if events[0].Text != "payment" {
+		t.Error("Recent() must not reorder the input")
+	}
+	if all := Recent(events, 0); len(all) != len(events) {
+		t.Errorf("Recent(0) returned %d events, want all %d", len(all), len(events))
+	}
+}
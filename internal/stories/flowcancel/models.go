@@ -0,0 +1,82 @@
+package flowcancel
+
+import "time"
+
+// StatsWindow - за какой период /stats показывает причины отмен
+const StatsWindow = 30 * 24 * time.Hour
+
+// Flow - флоу, отмены которого собираются в статистику
+type Flow string
+
+const (
+	FlowCreateSub     Flow = "create_sub"
+	FlowMigrateClient Flow = "migrate_client"
+)
+
+// Title возвращает название флоу для статистики
+func (f Flow) Title() string {
+	switch f {
+	case FlowCreateSub:
+		return "Создание подписки"
+	case FlowMigrateClient:
+		return "Миграция клиента"
+	default:
+		return string(f)
+	}
+}
+
+// Reason - причина отмены, выбранная одной кнопкой
+type Reason string
+
+const (
+	ReasonTooExpensive Reason = "expensive"
+	ReasonChangedMind  Reason = "changed_mind"
+	ReasonNoServer     Reason = "no_server"
+)
+
+// Reasons - причины в порядке кнопок
+var Reasons = []Reason{ReasonTooExpensive, ReasonChangedMind, ReasonNoServer}
+
+// Title возвращает текст кнопки и строки статистики
+func (r Reason) Title() string {
+	switch r {
+	case ReasonTooExpensive:
+		return "Слишком дорого"
+	case ReasonChangedMind:
+		return "Передумал"
+	case ReasonNoServer:
+		return "Нет нужного сервера"
+	case "":
+		return "Без ответа"
+	default:
+		return string(r)
+	}
+}
+
+// IsValid проверяет, что причина из списка кнопок
+func (r Reason) IsValid() bool {
+	for _, reason := range Reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// Cancellation - отмена флоу пользователем
+type Cancellation struct {
+	ID         int64
+	Flow       Flow
+	State      string // шаг, на котором отменили
+	TelegramID int64
+	Reason     *Reason // nil - пользователь не ответил
+	AnsweredAt *time.Time
+	CreatedAt  time.Time
+}
+
+// ReasonStats - сколько раз флоу отменяли по причине; пустая причина - без ответа
+type ReasonStats struct {
+	Flow   Flow   `db:"flow"`
+	Reason Reason `db:"reason"`
+	Count  int    `db:"count"`
+}
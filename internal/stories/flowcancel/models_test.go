@@ -0,0 +1,16 @@
+package flowcancel
+
+import "testing"
+
+func TestReasonIsValid(t *testing.T) {
+	for _, reason := range Reasons {
+		if !reason.IsValid() {
+			t.Errorf("%q.IsValid() = false, want true", reason)
+		}
+	}
+	for _, reason := range []Reason{"", "skip", "EXPENSIVE"} {
+		if reason.IsValid() {
+			t.Errorf("%q.IsValid() = true, want false", reason)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package guarantee
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/subs"
+)
+
+// Policy - гарантия возврата денег: в течение Days дней после первой оплаты подписки клиент может отказаться
+// от нее и получить всю сумму назад. Продления гарантией не покрываются. Days = 0 - гарантии нет
+type Policy struct {
+	Days int
+}
+
+// Enabled - включена ли гарантия
+func (p Policy) Enabled() bool {
+	return p.Days > 0
+}
+
+// Deadline - до какого момента действует гарантия на оплату, прошедшую в paidAt
+func (p Policy) Deadline(paidAt time.Time) time.Time {
+	return paidAt.AddDate(0, 0, p.Days)
+}
+
+// Covers - показывать ли в карточке кнопку возврата: подписка активна, не продлевалась и активирована
+// меньше Days дней назад. Окончательно возврат проверяет Check по платежу
+func (p Policy) Covers(sub *subs.Subscription, now time.Time) bool {
+	if !p.Enabled() || sub.Status != subs.StatusActive || sub.RenewalCount > 0 {
+		return false
+	}
+	activatedAt := sub.CreatedAt
+	if sub.ActivatedAt != nil {
+		activatedAt = *sub.ActivatedAt
+	}
+	return now.Before(p.Deadline(activatedAt))
+}
+
+// Check проверяет, можно ли вернуть деньги за подписку по гарантии, и возвращает платеж для возврата.
+// Текст ошибки - причина отказа для ассистента
+func (p Policy) Check(sub *subs.Subscription, payments []*payment.Payment, now time.Time) (*payment.Payment, error) {
+	if !p.Enabled() {
+		return nil, errors.New("возврат по гарантии отключен")
+	}
+	if sub.Status != subs.StatusActive {
+		return nil, errors.New("вернуть деньги можно только за активную подписку")
+	}
+	if sub.RenewalCount > 0 {
+		return nil, errors.New("подписку уже продлевали, гарантия действует только на первую оплату")
+	}
+
+	var paid *payment.Payment
+	for _, pm := range payments {
+		if pm.Status == payment.StatusApproved && (paid == nil || pm.ID > paid.ID) {
+			paid = pm
+		}
+	}
+	if paid == nil {
+		return nil, errors.New("у подписки нет оплаченных платежей")
+	}
+	if paid.YooKassaID == nil {
+		return nil, errors.New("оплата прошла мимо ЮKassa, деньги нужно вернуть вручную")
+	}
+	if paid.LateStatus != nil && *paid.LateStatus == payment.LateRefunded {
+		return nil, errors.New("деньги за эту оплату уже возвращены")
+	}
+
+	paidAt := paid.CreatedAt
+	if paid.ProcessedAt != nil {
+		paidAt = *paid.ProcessedAt
+	}
+	if deadline := p.Deadline(paidAt); !now.Before(deadline) {
+		return nil, fmt.Errorf("гарантия %d дн. закончилась %s", p.Days, deadline.Format("02.01.2006 15:04"))
+	}
+	return paid, nil
+}
@@ -0,0 +1,72 @@
+package guarantee
+
+import (
+	"testing"
+	"time"
+
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/subs"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	yookassaID := "2c5e-000f"
+	paidAt := now.Add(-48 * time.Hour)
+	refunded := payment.LateRefunded
+
+	active := &subs.Subscription{ID: 1, Status: subs.StatusActive}
+	paid := &payment.Payment{ID: 7, Amount: 350, Status: payment.StatusApproved, YooKassaID: &yookassaID, ProcessedAt: &paidAt}
+	pending := &payment.Payment{ID: 8, Status: payment.StatusPending, YooKassaID: &yookassaID, CreatedAt: now}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		sub      *subs.Subscription
+		payments []*payment.Payment
+		wantID   int64
+		wantErr  bool
+	}{
+		{name: "within period", policy: Policy{Days: 3}, sub: active, payments: []*payment.Payment{pending, paid}, wantID: 7},
+		{name: "period is over", policy: Policy{Days: 2}, sub: active, payments: []*payment.Payment{paid}, wantErr: true},
+		{name: "disabled", policy: Policy{}, sub: active, payments: []*payment.Payment{paid}, wantErr: true},
+		{name: "not active", policy: Policy{Days: 3}, sub: &subs.Subscription{Status: subs.StatusDisabled}, payments: []*payment.Payment{paid}, wantErr: true},
+		{name: "renewed", policy: Policy{Days: 3}, sub: &subs.Subscription{Status: subs.StatusActive, RenewalCount: 1}, payments: []*payment.Payment{paid}, wantErr: true},
+		{name: "nothing paid", policy: Policy{Days: 3}, sub: active, payments: []*payment.Payment{pending}, wantErr: true},
+		{name: "manual payment", policy: Policy{Days: 3}, sub: active, payments: []*payment.Payment{{ID: 9, Status: payment.StatusApproved, CreatedAt: now}}, wantErr: true},
+		{name: "already refunded", policy: Policy{Days: 3}, sub: active, payments: []*payment.Payment{{ID: 10, Status: payment.StatusApproved, YooKassaID: &yookassaID, LateStatus: &refunded, CreatedAt: now}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.policy.Check(tt.sub, tt.payments, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Check() = payment #%d, want error", got.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("Check() = payment #%d, want #%d", got.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestPolicyCovers(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	activatedAt := now.Add(-24 * time.Hour)
+	sub := &subs.Subscription{Status: subs.StatusActive, ActivatedAt: &activatedAt}
+
+	if !(Policy{Days: 3}).Covers(sub, now) {
+		t.Error("Covers() = false for a subscription activated a day ago")
+	}
+	if (Policy{Days: 1}).Covers(sub, now) {
+		t.Error("Covers() = true after the guarantee period")
+	}
+	if (Policy{}).Covers(sub, now) {
+		t.Error("Covers() = true with the guarantee disabled")
+	}
+}
@@ -0,0 +1,103 @@
+package heatmap
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Weeks - за сколько недель считаются истечения (вперед) и продления (назад)
+const Weeks = 4
+
+// weekdayNames индексируются так же, как WeekdayHeatmap: 0 - понедельник
+var weekdayNames = [7]string{"Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"}
+
+// WeekdayHeatmap - распределение истечений и продлений подписок по дням недели.
+// Индекс 0 - понедельник, 6 - воскресенье
+type WeekdayHeatmap struct {
+	Weeks       int
+	Expirations [7]int // подписки, истекающие в ближайшие Weeks недель
+	Renewals    [7]int // продления за прошлые Weeks недель
+}
+
+// WeekdayIndex возвращает индекс дня недели, начиная с понедельника
+func WeekdayIndex(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+// Build раскладывает даты истечений и продлений по дням недели
+func Build(weeks int, expirations, renewals []time.Time) *WeekdayHeatmap {
+	h := &WeekdayHeatmap{Weeks: weeks}
+	for _, t := range expirations {
+		h.Expirations[WeekdayIndex(t)]++
+	}
+	for _, t := range renewals {
+		h.Renewals[WeekdayIndex(t)]++
+	}
+	return h
+}
+
+// Load возвращает нагрузку на ассистентов в день недели: истечения плюс продления
+func (h *WeekdayHeatmap) Load(day int) int {
+	return h.Expirations[day] + h.Renewals[day]
+}
+
+// PeakDays возвращает дни недели с максимальной нагрузкой; nil - данных нет
+func (h *WeekdayHeatmap) PeakDays() []int {
+	maxLoad := 0
+	for day := range weekdayNames {
+		maxLoad = max(maxLoad, h.Load(day))
+	}
+	if maxLoad == 0 {
+		return nil
+	}
+
+	var peaks []int
+	for day := range weekdayNames {
+		if h.Load(day) == maxLoad {
+			peaks = append(peaks, day)
+		}
+	}
+	return peaks
+}
+
+// Text форматирует тепловую карту для Telegram (Markdown)
+func (h *WeekdayHeatmap) Text() string {
+	var b strings.Builder
+	b.WriteString("🗓 *Нагрузка по дням недели*\n")
+	fmt.Fprintf(&b, "_Истекает — ближайшие %d нед., продлено — прошлые %d нед._\n\n", h.Weeks, h.Weeks)
+
+	peaks := h.PeakDays()
+	if peaks == nil {
+		b.WriteString("Нет истечений и продлений за этот период")
+		return b.String()
+	}
+
+	maxLoad := h.Load(peaks[0])
+	for day, name := range weekdayNames {
+		fmt.Fprintf(&b, "%s %s — истекает *%d*, продлено *%d*\n",
+			heatLevel(h.Load(day), maxLoad), name, h.Expirations[day], h.Renewals[day])
+	}
+
+	names := make([]string, 0, len(peaks))
+	for _, day := range peaks {
+		names = append(names, weekdayNames[day])
+	}
+	fmt.Fprintf(&b, "\nПик нагрузки: *%s*", strings.Join(names, ", "))
+
+	return b.String()
+}
+
+// heatLevel возвращает цвет ячейки по доле от максимальной нагрузки
+func heatLevel(load, maxLoad int) string {
+	switch {
+	case load == 0:
+		return "⬜"
+	case load*3 <= maxLoad:
+		return "🟨"
+	case load*3 <= maxLoad*2:
+		return "🟧"
+	default:
+		return "🟥"
+	}
+}
@@ -0,0 +1,57 @@
+package heatmap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWeekdayIndex(t *testing.T) {
+	monday := time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)
+	sunday := time.Date(2026, 3, 15, 23, 0, 0, 0, time.UTC)
+
+	if got := WeekdayIndex(monday); got != 0 {
+		t.Errorf("WeekdayIndex(monday) = %d, want 0", got)
+	}
+	if got := WeekdayIndex(sunday); got != 6 {
+		t.Errorf("WeekdayIndex(sunday) = %d, want 6", got)
+	}
+}
+
+func TestBuildAndPeakDays(t *testing.T) {
+	monday := time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)
+	thursday := monday.AddDate(0, 0, 3)
+
+	h := Build(4,
+		[]time.Time{monday, monday, thursday},
+		[]time.Time{thursday, monday.AddDate(0, 0, 7)},
+	)
+
+	if h.Expirations[0] != 2 || h.Expirations[3] != 1 {
+		t.Errorf("Expirations = %v, want 2 on Monday and 1 on Thursday", h.Expirations)
+	}
+	if h.Renewals[0] != 1 || h.Renewals[3] != 1 {
+		t.Errorf("Renewals = %v, want 1 on Monday and 1 on Thursday", h.Renewals)
+	}
+	if got, want := h.PeakDays(), []int{0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PeakDays() = %v, want %v", got, want)
+	}
+
+	text := h.Text()
+	for _, want := range []string{"🟥 Пн — истекает *2*, продлено *1*", "⬜ Вт", "Пик нагрузки: *Пн*"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Text() = %q, want it to contain %q", text, want)
+		}
+	}
+}
+
+func TestEmptyHeatmap(t *testing.T) {
+	h := Build(4, nil, nil)
+	if peaks := h.PeakDays(); peaks != nil {
+		t.Errorf("PeakDays() = %v, want nil", peaks)
+	}
+	if text := h.Text(); !strings.Contains(text, "Нет истечений и продлений") {
+		t.Errorf("Text() = %q, want empty state", text)
+	}
+}
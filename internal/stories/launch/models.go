@@ -0,0 +1,13 @@
+package launch
+
+import "time"
+
+// Entry - пользователь в белом списке мягкого запуска или в листе ожидания
+type Entry struct {
+	TelegramID int64
+	Username   string // @username или имя из Telegram, для списка у админа
+	Approved   bool   // false - пользователь только записался в лист ожидания
+	AddedBy    *int64 // админ, открывший доступ
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,47 @@
+package offers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// payloadPrefix - префикс параметра /start ссылки из предложения тарифа: t.me/<bot>?start=offer_<tariffID>_<assistantTelegramID>
+const payloadPrefix = "offer_"
+
+// Lead - клиент, открывший ссылку из предложения тарифа, которое ассистент отправил через inline-режим.
+// Заявка закреплена за ассистентом-отправителем
+type Lead struct {
+	ID                  int64
+	TariffID            int64
+	AssistantTelegramID int64
+	ClientTelegramID    int64
+	ClientName          string // имя и @username клиента в Telegram
+	CreatedAt           time.Time
+}
+
+// StartPayload возвращает параметр /start ссылки из предложения тарифа
+func StartPayload(tariffID, assistantTelegramID int64) string {
+	return payloadPrefix + strconv.FormatInt(tariffID, 10) + "_" + strconv.FormatInt(assistantTelegramID, 10)
+}
+
+// ParseStartPayload извлекает тариф и ассистента-отправителя из параметра /start
+func ParseStartPayload(payload string) (tariffID, assistantTelegramID int64, ok bool) {
+	raw, found := strings.CutPrefix(strings.TrimSpace(payload), payloadPrefix)
+	if !found {
+		return 0, 0, false
+	}
+	tariffRaw, assistantRaw, found := strings.Cut(raw, "_")
+	if !found {
+		return 0, 0, false
+	}
+	tariffID, err := strconv.ParseInt(tariffRaw, 10, 64)
+	if err != nil || tariffID <= 0 {
+		return 0, 0, false
+	}
+	assistantTelegramID, err = strconv.ParseInt(assistantRaw, 10, 64)
+	if err != nil || assistantTelegramID <= 0 {
+		return 0, 0, false
+	}
+	return tariffID, assistantTelegramID, true
+}
@@ -0,0 +1,31 @@
+package offers
+
+import "testing"
+
+func TestParseStartPayload(t *testing.T) {
+	tests := []struct {
+		payload       string
+		wantTariff    int64
+		wantAssistant int64
+		wantOK        bool
+	}{
+		{"offer_2_123456", 2, 123456, true},
+		{" offer_2_123456 ", 2, 123456, true},
+		{"offer_2", 0, 0, false},
+		{"offer_0_123456", 0, 0, false},
+		{"offer_2_abc", 0, 0, false},
+		{"gift_2", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		tariffID, assistantID, ok := ParseStartPayload(tt.payload)
+		if tariffID != tt.wantTariff || assistantID != tt.wantAssistant || ok != tt.wantOK {
+			t.Errorf("ParseStartPayload(%q) = (%d, %d, %v), want (%d, %d, %v)",
+				tt.payload, tariffID, assistantID, ok, tt.wantTariff, tt.wantAssistant, tt.wantOK)
+		}
+	}
+
+	if tariffID, assistantID, ok := ParseStartPayload(StartPayload(5, 77)); !ok || tariffID != 5 || assistantID != 77 {
+		t.Errorf("ParseStartPayload(StartPayload(5, 77)) = (%d, %d, %v)", tariffID, assistantID, ok)
+	}
+}
@@ -1,12 +1,20 @@
 package orders
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Repository interface {
 	CreatePendingOrder(ctx context.Context, order PendingOrder) (*PendingOrder, error)
 	GetPendingOrderByID(ctx context.Context, id int64) (*PendingOrder, error)
 	UpdatePendingOrderMessageID(ctx context.Context, id int64, messageID int) error
 	UpdatePendingOrderPaymentID(ctx context.Context, id int64, paymentID int64) error
+	ClaimPendingOrderLinkRefresh(ctx context.Context, id int64, now time.Time) (bool, error)
 	UpdatePendingOrderStatus(ctx context.Context, id int64, status Status) error
+	AttachPendingOrderReceipt(ctx context.Context, id int64, fileID string, now time.Time) (bool, error)
+	ClaimPendingOrderReceiptReview(ctx context.Context, id int64, reviewerTelegramID int64, status Status, now time.Time) (bool, error)
 	DeletePendingOrder(ctx context.Context, id int64) error
+	ListPendingOrdersWithPayments(ctx context.Context) ([]*PendingOrder, error)
+	ListPendingOrdersByAssistant(ctx context.Context, assistantTelegramID int64, limit int) ([]*PendingOrder, error)
 }
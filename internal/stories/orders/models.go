@@ -1,9 +1,20 @@
 package orders
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"time"
+)
 
 type Status string
 
+const (
+	// LinkRefreshCooldown - минимальный интервал между обновлениями ссылки на оплату одного заказа
+	LinkRefreshCooldown = 60 * time.Second
+	// MaxLinkRefreshes - сколько раз можно обновить ссылку на оплату одного заказа
+	MaxLinkRefreshes = 5
+)
+
 const (
 	StatusPending   Status = "pending"
 	StatusCompleted Status = "completed"
@@ -23,8 +34,16 @@ type PendingOrder struct {
 	TariffID               int64
 	TariffName             string
 	TotalAmount            float64
-	ReferrerWhatsApp       *string // WhatsApp of referrer (who invited)
-	ReferrerSubscriptionID *int64  // ID of referrer's subscription to extend
+	BaseAmount             *float64   // Цена тарифа без наценки сервера (для разбивки в платеже)
+	ReferrerWhatsApp       *string    // WhatsApp of referrer (who invited)
+	ReferrerSubscriptionID *int64     // ID of referrer's subscription to extend
+	TargetServerID         *int64     // Сервер для новой подписки, выбранный вручную (/quick_sub)
+	LinkRefreshCount       int        // Сколько раз ассистент обновлял ссылку на оплату
+	LinkRefreshedAt        *time.Time // Когда ссылка обновлялась последний раз
+	ReceiptFileID          *string    // Фото чека в ручном режиме оплаты (Telegram file_id)
+	ReceiptUploadedAt      *time.Time
+	ReceiptReviewedBy      *int64 // Telegram ID админа, подтвердившего или отклонившего чек
+	ReceiptReviewedAt      *time.Time
 	Status                 Status
 	CreatedAt              time.Time
 	UpdatedAt              time.Time
@@ -34,3 +53,36 @@ type PendingOrder struct {
 func (p *PendingOrder) IsMigration() bool {
 	return p.ServerID != nil
 }
+
+// HasReceipt returns true if the assistant attached a payment receipt photo
+func (p *PendingOrder) HasReceipt() bool {
+	return p.ReceiptFileID != nil
+}
+
+// LinkRefreshLimitReached returns true if the payment link can't be refreshed anymore
+func (p *PendingOrder) LinkRefreshLimitReached() bool {
+	return p.LinkRefreshCount >= MaxLinkRefreshes
+}
+
+// LinkRefreshCooldownLeft returns how long to wait before the next link refresh (0 - can refresh now)
+func (p *PendingOrder) LinkRefreshCooldownLeft(now time.Time) time.Duration {
+	if p.LinkRefreshedAt == nil {
+		return 0
+	}
+	left := p.LinkRefreshedAt.Add(LinkRefreshCooldown).Sub(now)
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// LinkRefreshBlockReason returns why the payment link can't be refreshed now ("" - can refresh)
+func (p *PendingOrder) LinkRefreshBlockReason(now time.Time) string {
+	if p.LinkRefreshLimitReached() {
+		return fmt.Sprintf("⛔ Ссылку уже обновляли %d раз. Если клиенту нужна новая, отмените заказ и создайте его заново", MaxLinkRefreshes)
+	}
+	if left := p.LinkRefreshCooldownLeft(now); left > 0 {
+		return fmt.Sprintf("⏳ Ссылку можно обновить через %d сек.", int(math.Ceil(left.Seconds())))
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package orders
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLinkRefreshCooldownLeft(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	at := func(ago time.Duration) *time.Time {
+		v := now.Add(-ago)
+		return &v
+	}
+
+	tests := []struct {
+		name        string
+		refreshedAt *time.Time
+		want        time.Duration
+	}{
+		{name: "never refreshed", refreshedAt: nil, want: 0},
+		{name: "just refreshed", refreshedAt: at(0), want: LinkRefreshCooldown},
+		{name: "refreshed 45s ago", refreshedAt: at(45 * time.Second), want: 15 * time.Second},
+		{name: "cooldown passed", refreshedAt: at(2 * time.Minute), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := PendingOrder{LinkRefreshedAt: tt.refreshedAt}
+			if got := order.LinkRefreshCooldownLeft(now); got != tt.want {
+				t.Errorf("LinkRefreshCooldownLeft() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkRefreshLimitReached(t *testing.T) {
+	if (&PendingOrder{LinkRefreshCount: MaxLinkRefreshes - 1}).LinkRefreshLimitReached() {
+		t.Error("limit reached before MaxLinkRefreshes")
+	}
+	if !(&PendingOrder{LinkRefreshCount: MaxLinkRefreshes}).LinkRefreshLimitReached() {
+		t.Error("limit not reached at MaxLinkRefreshes")
+	}
+}
+
+func TestLinkRefreshBlockReason(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	refreshedAt := now.Add(-59*time.Second - 500*time.Millisecond)
+
+	if got := (&PendingOrder{}).LinkRefreshBlockReason(now); got != "" {
+		t.Errorf("fresh order blocked: %q", got)
+	}
+	if got, want := (&PendingOrder{LinkRefreshCount: 1, LinkRefreshedAt: &refreshedAt}).LinkRefreshBlockReason(now), "⏳ Ссылку можно обновить через 1 сек."; got != want {
+		t.Errorf("cooldown reason = %q, want %q", got, want)
+	}
+	if got := (&PendingOrder{LinkRefreshCount: MaxLinkRefreshes}).LinkRefreshBlockReason(now); got == "" {
+		t.Error("order over the limit is not blocked")
+	}
+}
@@ -1,6 +1,9 @@
 package orders
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Service struct {
 	repo Repository
@@ -26,10 +29,38 @@ func (s *Service) UpdatePaymentID(ctx context.Context, id int64, paymentID int64
 	return s.repo.UpdatePendingOrderPaymentID(ctx, id, paymentID)
 }
 
+// ClaimLinkRefresh атомарно резервирует обновление ссылки на оплату с учетом
+// LinkRefreshCooldown и MaxLinkRefreshes; false - обновлять сейчас нельзя
+func (s *Service) ClaimLinkRefresh(ctx context.Context, id int64) (bool, error) {
+	return s.repo.ClaimPendingOrderLinkRefresh(ctx, id, time.Now())
+}
+
 func (s *Service) UpdateStatus(ctx context.Context, id int64, status Status) error {
 	return s.repo.UpdatePendingOrderStatus(ctx, id, status)
 }
 
+// AttachReceipt прикрепляет к ожидающему заказу фото чека; новый чек заменяет прежний,
+// пока админ его не рассмотрел. false - заказ уже обработан
+func (s *Service) AttachReceipt(ctx context.Context, id int64, fileID string) (bool, error) {
+	return s.repo.AttachPendingOrderReceipt(ctx, id, fileID, time.Now())
+}
+
+// ClaimReceiptReview атомарно закрывает заказ с чеком решением админа (completed - подтвержден,
+// cancelled - отклонен); false - заказ уже рассмотрен или отменен
+func (s *Service) ClaimReceiptReview(ctx context.Context, id int64, reviewerTelegramID int64, status Status) (bool, error) {
+	return s.repo.ClaimPendingOrderReceiptReview(ctx, id, reviewerTelegramID, status, time.Now())
+}
+
 func (s *Service) DeletePendingOrder(ctx context.Context, id int64) error {
 	return s.repo.DeletePendingOrder(ctx, id)
 }
+
+// ListPending возвращает ожидающие заказы с платежом, старые первыми
+func (s *Service) ListPending(ctx context.Context) ([]*PendingOrder, error) {
+	return s.repo.ListPendingOrdersWithPayments(ctx)
+}
+
+// ListPendingByAssistant возвращает неоплаченные заказы ассистента с платежом, новые первыми
+func (s *Service) ListPendingByAssistant(ctx context.Context, assistantTelegramID int64, limit int) ([]*PendingOrder, error) {
+	return s.repo.ListPendingOrdersByAssistant(ctx, assistantTelegramID, limit)
+}
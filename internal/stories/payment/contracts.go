@@ -2,8 +2,10 @@ package payment
 
 import (
 	"context"
+	"time"
 
 	yoopayment "github.com/rvinnie/yookassa-sdk-go/yookassa/payment"
+	yoorefund "github.com/rvinnie/yookassa-sdk-go/yookassa/refund"
 )
 
 type (
@@ -16,11 +18,22 @@ type (
 		DeletePayment(ctx context.Context, criteria DeleteCriteria) error
 		LinkPaymentToSubscriptions(ctx context.Context, paymentID int64, subscriptionIDs []int64) error
 		ListOrphanedPayments(ctx context.Context) ([]*Payment, error)
+		StartRefund(ctx context.Context, refund Refund) error
+		GetRefund(ctx context.Context, paymentID int64) (*Refund, error)
+		FailStaleRefund(ctx context.Context, paymentID int64, startedBefore time.Time, errText string) (bool, error)
+		FinishRefund(ctx context.Context, paymentID int64, status RefundStatus, providerRefundID, errText *string) error
 	}
 
 	// YooKassaClient provides YooKassa API operations
 	YooKassaClient interface {
-		CreatePayment(ctx context.Context, amount float64, description string, metadata map[string]string) (*yoopayment.Payment, error)
+		CreatePayment(ctx context.Context, amount float64, description, receiptItem string, metadata map[string]string) (*yoopayment.Payment, error)
 		GetPaymentStatus(ctx context.Context, paymentID string) (*yoopayment.Payment, error)
+		CancelPayment(ctx context.Context, paymentID string) (*yoopayment.Payment, error)
+		CreateRefund(ctx context.Context, paymentID string, amount float64, description string) (*yoorefund.Refund, error)
+	}
+
+	// LinkShortener makes payment URLs short enough to share with clients
+	LinkShortener interface {
+		Shorten(ctx context.Context, paymentID int64, targetURL string) (string, error)
 	}
 )
@@ -11,6 +11,24 @@ const (
 	StatusCancelled Status = "cancelled"
 )
 
+// ProviderCancelResult - чем закончилась отмена платежа в ЮKassa
+type ProviderCancelResult string
+
+const (
+	ProviderCancelCancelled ProviderCancelResult = "cancelled"
+	ProviderCancelFailed    ProviderCancelResult = "failed"
+)
+
+// LateStatus - состояние оплаты, пришедшей по ссылке уже отмененного заказа
+type LateStatus string
+
+const (
+	LateDetected  LateStatus = "detected"  // оплата найдена, админ еще не решил что делать
+	LateFulfilled LateStatus = "fulfilled" // подписка выдана несмотря на отмену заказа
+	LateRefunded  LateStatus = "refunded"  // деньги возвращены клиенту через ЮKassa
+	LateCredited  LateStatus = "credited"  // сумма зачислена на баланс клиента
+)
+
 type Payment struct {
 	ID          int64
 	UserID      int64
@@ -19,8 +37,25 @@ type Payment struct {
 	YooKassaID  *string
 	PaymentURL  *string
 	ProcessedAt *time.Time
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	BaseAmount  *float64 // Цена тарифа без наценки сервера; nil - разбивки нет
+	ServerID    *int64   // Сервер, наценка которого включена в Amount
+	// Отмена в ЮKassa (заказ отменен или ссылка обновлена); nil - не отменяли
+	ProviderCancelResult *ProviderCancelResult
+	ProviderCancelError  *string
+	ProviderCancelAt     *time.Time
+	// Поздняя оплата отмененного заказа; nil - платеж не поздний
+	LateStatus     *LateStatus
+	LateResolvedAt *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// ServerSurcharge возвращает часть суммы, которая приходится на наценку сервера
+func (p *Payment) ServerSurcharge() float64 {
+	if p.BaseAmount == nil {
+		return 0
+	}
+	return p.Amount - *p.BaseAmount
 }
 
 type GetCriteria struct {
@@ -34,21 +69,30 @@ type DeleteCriteria struct {
 
 // Критерии для списка платежей
 type ListCriteria struct {
-	UserID *int64
-	Status *Status
-	Limit  int
-	Offset int
+	UserID        *int64
+	Status        *Status
+	CreatedAfter  *time.Time // created_at >= CreatedAfter
+	CreatedBefore *time.Time // created_at < CreatedBefore
+	Limit         int
+	Offset        int
 }
 
 type UpdateParams struct {
-	Status      *Status
-	YooKassaID  *string
-	PaymentURL  *string
-	ProcessedAt *time.Time
+	Status               *Status
+	YooKassaID           *string
+	PaymentURL           *string
+	ProcessedAt          *time.Time
+	ProviderCancelResult *ProviderCancelResult
+	ProviderCancelError  *string
+	ProviderCancelAt     *time.Time
+	LateStatus           *LateStatus
+	LateResolvedAt       *time.Time
 }
 
+// CreatePaymentMeta - данные для шаблонов описания платежа и чека; пустые поля подставляются пустой строкой
 type CreatePaymentMeta struct {
-	Description string
+	TariffName     string
+	ClientWhatsApp string
 }
 
 type PaymentSubscription struct {
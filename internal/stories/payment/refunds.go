@@ -0,0 +1,50 @@
+package payment
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrAlreadyRefunded - по платежу уже есть успешный или выполняющийся возврат
+var ErrAlreadyRefunded = errors.New("payment already refunded")
+
+// RefundReason - почему клиенту вернули деньги
+type RefundReason string
+
+const (
+	RefundLatePayment RefundReason = "late_payment" // клиент оплатил отмененный заказ
+	RefundGuarantee   RefundReason = "guarantee"    // клиент отказался от подписки в гарантийный период
+)
+
+// RefundStatus - состояние возврата
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "pending"   // запрос в ЮKassa еще не завершился
+	RefundSucceeded RefundStatus = "succeeded" // ЮKassa приняла возврат
+	RefundFailed    RefundStatus = "failed"    // ЮKassa отклонила возврат, его можно повторить
+)
+
+// Refund - возврат всей суммы платежа
+type Refund struct {
+	ID                    int64
+	PaymentID             int64
+	SubscriptionID        *int64
+	Reason                RefundReason
+	Status                RefundStatus
+	Amount                float64
+	RequestedByTelegramID int64
+	ProviderRefundID      *string
+	Error                 *string
+	CreatedAt             time.Time
+	CompletedAt           *time.Time
+}
+
+// RefundRequest - запрос возврата платежа через ЮKassa
+type RefundRequest struct {
+	PaymentID             int64
+	SubscriptionID        *int64 // подписка, за которую возвращаются деньги; nil - подписки нет
+	Reason                RefundReason
+	RequestedByTelegramID int64
+	Description           string // описание возврата в ЮKassa
+}
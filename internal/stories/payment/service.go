@@ -2,8 +2,10 @@ package payment
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"time"
 
 	yoopayment "github.com/rvinnie/yookassa-sdk-go/yookassa/payment"
@@ -13,24 +15,34 @@ import (
 type Service struct {
 	storage        Storage
 	yookassaClient YooKassaClient
+	shortener      LinkShortener
 	logger         *slog.Logger
 	returnURL      string
 	manualPayment  bool
+	templates      Templates
 }
 
 // NewService creates a new payment service
-func NewService(storage Storage, yookassaClient YooKassaClient, returnURL string, manualPayment bool, logger *slog.Logger) *Service {
+func NewService(storage Storage, yookassaClient YooKassaClient, shortener LinkShortener, returnURL string, manualPayment bool, templates Templates, logger *slog.Logger) *Service {
 	return &Service{
 		storage:        storage,
 		yookassaClient: yookassaClient,
+		shortener:      shortener,
 		logger:         logger,
 		returnURL:      returnURL,
 		manualPayment:  manualPayment,
+		templates:      templates,
 	}
 }
 
 // CreatePayment creates a new payment and processes it with YooKassa
 func (s *Service) CreatePayment(ctx context.Context, paymentEntity Payment) (*Payment, error) {
+	return s.CreatePaymentWithMeta(ctx, paymentEntity, CreatePaymentMeta{})
+}
+
+// CreatePaymentWithMeta creates a payment like CreatePayment; meta fills the tariff and client placeholders
+// of the payment description and receipt templates
+func (s *Service) CreatePaymentWithMeta(ctx context.Context, paymentEntity Payment, meta CreatePaymentMeta) (*Payment, error) {
 	s.logger.Info("Creating payment",
 		"user_id", paymentEntity.UserID,
 		"amount", paymentEntity.Amount,
@@ -64,12 +76,12 @@ func (s *Service) CreatePayment(ctx context.Context, paymentEntity Payment) (*Pa
 	metadata := map[string]string{
 		"internal_payment_id": fmt.Sprintf("%d", createdPayment.ID),
 	}
-	description := fmt.Sprintf("Оплата подписки #%d", createdPayment.ID)
+	description, receiptItem := s.templates.Render(createdPayment.ID, createdPayment.Amount, meta)
 
 	// 4. Вызываем YooKassa API
 	s.logger.Info("Calling YooKassa API", "payment_id", createdPayment.ID, "amount", createdPayment.Amount)
 
-	yookassaPayment, err := s.yookassaClient.CreatePayment(ctx, createdPayment.Amount, description, metadata)
+	yookassaPayment, err := s.yookassaClient.CreatePayment(ctx, createdPayment.Amount, description, receiptItem, metadata)
 	if err != nil {
 		s.logger.Error("Failed to create payment in YooKassa",
 			"error", err,
@@ -114,6 +126,16 @@ func (s *Service) CreatePayment(ctx context.Context, paymentEntity Payment) (*Pa
 		"yookassa_id", *updatedPayment.YooKassaID,
 	)
 
+	// 6. Клиенту отправляется короткая ссылка, в БД остается исходная ссылка YooKassa
+	if updatedPayment.PaymentURL != nil {
+		shortURL, err := s.shortener.Shorten(ctx, updatedPayment.ID, *updatedPayment.PaymentURL)
+		if err != nil {
+			s.logger.Warn("Failed to shorten payment URL, using original", "error", err, "payment_id", updatedPayment.ID)
+		} else {
+			updatedPayment.PaymentURL = &shortURL
+		}
+	}
+
 	return updatedPayment, nil
 }
 
@@ -137,6 +159,34 @@ func (s *Service) createManualPayment(ctx context.Context, paymentEntity Payment
 	return createdPayment, nil
 }
 
+// CreateReceiptPayment creates a pending manual payment that an admin approves by the receipt photo.
+// Подтверждение - CheckPaymentStatus (в ручном режиме он переводит платеж в approved), отказ - CancelPayment
+func (s *Service) CreateReceiptPayment(ctx context.Context, paymentEntity Payment) (*Payment, error) {
+	if !s.manualPayment {
+		return nil, fmt.Errorf("receipt payments are available only in manual payment mode")
+	}
+	if paymentEntity.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	if paymentEntity.UserID <= 0 {
+		return nil, fmt.Errorf("userID must be positive")
+	}
+
+	paymentEntity.Status = StatusPending
+	createdPayment, err := s.storage.CreatePayment(ctx, paymentEntity)
+	if err != nil {
+		s.logger.Error("Failed to create receipt payment in storage", "error", err, "user_id", paymentEntity.UserID)
+		return nil, fmt.Errorf("failed to create receipt payment in storage: %w", err)
+	}
+
+	s.logger.Info("Receipt payment created, waiting for admin approval",
+		"payment_id", createdPayment.ID,
+		"amount", createdPayment.Amount,
+	)
+
+	return createdPayment, nil
+}
+
 // CheckPaymentStatus checks payment status in YooKassa and updates local storage
 func (s *Service) CheckPaymentStatus(ctx context.Context, paymentID int64) (*Payment, error) {
 	s.logger.Info("Checking payment status", "payment_id", paymentID)
@@ -205,6 +255,12 @@ func (s *Service) CheckPaymentStatus(ctx context.Context, paymentID int64) (*Pay
 	// 4. Маппим статус из YooKassa в наш внутренний статус
 	newStatus := mapYooKassaStatusToInternal(yookassaPayment.Status)
 
+	// Платеж, отмененный вручную, не возвращаем обратно в pending
+	if payment.Status == StatusCancelled && newStatus == StatusPending {
+		s.logger.Info("Payment cancelled locally, keeping status", "payment_id", paymentID)
+		return payment, nil
+	}
+
 	// 5. Обновляем статус в БД если изменился
 	if newStatus != payment.Status {
 		s.logger.Info("Payment status changed",
@@ -246,6 +302,181 @@ func (s *Service) CheckPaymentStatus(ctx context.Context, paymentID int64) (*Pay
 	return payment, nil
 }
 
+// CancelPayment marks a pending payment as cancelled locally and cancels it in YooKassa,
+// so an old link can't be paid later. The provider result is recorded on the payment row
+func (s *Service) CancelPayment(ctx context.Context, paymentID int64) (*Payment, error) {
+	criteria := GetCriteria{ID: &paymentID}
+	payment, err := s.storage.GetPayment(ctx, criteria)
+	if err != nil {
+		s.logger.Error("Failed to get payment from storage", "error", err, "payment_id", paymentID)
+		return nil, fmt.Errorf("failed to get payment from storage: %w", err)
+	}
+	if payment == nil {
+		return nil, fmt.Errorf("payment not found: %d", paymentID)
+	}
+
+	if payment.Status != StatusPending {
+		s.logger.Info("Payment is not pending, skip cancel", "payment_id", paymentID, "status", payment.Status)
+		return payment, nil
+	}
+
+	newStatus := StatusCancelled
+	now := time.Now()
+	params := UpdateParams{
+		Status:      &newStatus,
+		ProcessedAt: &now,
+	}
+	if !s.manualPayment && payment.YooKassaID != nil {
+		s.cancelAtProvider(ctx, payment, &params)
+	}
+
+	updatedPayment, err := s.storage.UpdatePayment(ctx, criteria, params)
+	if err != nil {
+		s.logger.Error("Failed to cancel payment", "error", err, "payment_id", paymentID)
+		return nil, fmt.Errorf("failed to cancel payment: %w", err)
+	}
+
+	s.logger.Info("Payment cancelled", "payment_id", paymentID, "status", updatedPayment.Status)
+	return updatedPayment, nil
+}
+
+// cancelAtProvider отменяет платеж в ЮKassa и дописывает результат в params.
+// Ошибка ЮKassa не мешает локальной отмене: она сохраняется в provider_cancel_error.
+// Если клиент уже успел оплатить, платеж остается approved, а не cancelled
+func (s *Service) cancelAtProvider(ctx context.Context, payment *Payment, params *UpdateParams) {
+	now := time.Now()
+	params.ProviderCancelAt = &now
+
+	result := ProviderCancelCancelled
+	params.ProviderCancelResult = &result
+
+	_, err := s.yookassaClient.CancelPayment(ctx, *payment.YooKassaID)
+	if err == nil {
+		return
+	}
+
+	s.logger.Warn("Failed to cancel payment in YooKassa",
+		"error", err,
+		"payment_id", payment.ID,
+		"yookassa_id", *payment.YooKassaID,
+	)
+	result = ProviderCancelFailed
+	errText := err.Error()
+	params.ProviderCancelError = &errText
+
+	yookassaPayment, statusErr := s.yookassaClient.GetPaymentStatus(ctx, *payment.YooKassaID)
+	if statusErr == nil && mapYooKassaStatusToInternal(yookassaPayment.Status) == StatusApproved {
+		s.logger.Warn("Payment already paid in YooKassa, keeping it approved", "payment_id", payment.ID)
+		approved := StatusApproved
+		params.Status = &approved
+	}
+}
+
+// refundPendingTimeout - через сколько возврат в статусе pending считается прерванным: запрос в ЮKassa
+// столько не идет, значит процесс упал между StartRefund и FinishRefund
+const refundPendingTimeout = 10 * time.Minute
+
+// RefundPayment возвращает клиенту всю сумму оплаченного платежа через ЮKassa и записывает возврат в payment_refunds.
+// Платеж возвращается не больше одного раза: при начатом или успешном возврате - ErrAlreadyRefunded.
+// Прерванный возврат сверяется с ЮKassa и либо закрывается, либо выполняется заново.
+// В ручном режиме вернуть деньги через бота нельзя - платежи проходят мимо ЮKassa
+func (s *Service) RefundPayment(ctx context.Context, req RefundRequest) error {
+	payment, err := s.storage.GetPayment(ctx, GetCriteria{ID: &req.PaymentID})
+	if err != nil {
+		return fmt.Errorf("failed to get payment from storage: %w", err)
+	}
+	if payment == nil {
+		return fmt.Errorf("payment not found: %d", req.PaymentID)
+	}
+	if payment.Status != StatusApproved {
+		return fmt.Errorf("payment %d is not approved: %s", req.PaymentID, payment.Status)
+	}
+	if s.manualPayment || payment.YooKassaID == nil {
+		return fmt.Errorf("payment %d can't be refunded via YooKassa", req.PaymentID)
+	}
+
+	refundEntity := Refund{
+		PaymentID:             payment.ID,
+		SubscriptionID:        req.SubscriptionID,
+		Reason:                req.Reason,
+		Status:                RefundPending,
+		Amount:                payment.Amount,
+		RequestedByTelegramID: req.RequestedByTelegramID,
+	}
+	err = s.storage.StartRefund(ctx, refundEntity)
+	if errors.Is(err, ErrAlreadyRefunded) {
+		rearmed, recoverErr := s.recoverStaleRefund(ctx, payment)
+		if recoverErr != nil {
+			return fmt.Errorf("failed to recover stale refund: %w", recoverErr)
+		}
+		if rearmed {
+			err = s.storage.StartRefund(ctx, refundEntity)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start refund: %w", err)
+	}
+
+	refund, err := s.yookassaClient.CreateRefund(ctx, *payment.YooKassaID, payment.Amount, req.Description)
+	if err != nil {
+		s.logger.Error("Failed to refund payment", "error", err, "payment_id", req.PaymentID)
+		errText := err.Error()
+		if finishErr := s.storage.FinishRefund(ctx, payment.ID, RefundFailed, nil, &errText); finishErr != nil {
+			s.logger.Error("Failed to save failed refund", "error", finishErr, "payment_id", req.PaymentID)
+		}
+		return fmt.Errorf("failed to refund payment: %w", err)
+	}
+
+	// Деньги уже возвращены: ошибку записи только логируем, чтобы возврат не повторили
+	if err := s.storage.FinishRefund(ctx, payment.ID, RefundSucceeded, &refund.Id, nil); err != nil {
+		s.logger.Error("Failed to save succeeded refund", "error", err, "payment_id", req.PaymentID, "refund_id", refund.Id)
+	}
+
+	s.logger.Info("Payment refunded", "payment_id", req.PaymentID, "amount", payment.Amount, "reason", req.Reason)
+	return nil
+}
+
+// recoverStaleRefund сверяет с ЮKassa возврат, застрявший в pending дольше refundPendingTimeout.
+// Если ЮKassa уже вернула деньги, возврат закрывается как succeeded; иначе помечается failed,
+// и true означает, что его можно начать заново
+func (s *Service) recoverStaleRefund(ctx context.Context, payment *Payment) (bool, error) {
+	refund, err := s.storage.GetRefund(ctx, payment.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get refund from storage: %w", err)
+	}
+	staleBefore := time.Now().Add(-refundPendingTimeout)
+	if refund == nil || refund.Status != RefundPending || !refund.CreatedAt.Before(staleBefore) {
+		return false, nil
+	}
+
+	yookassaPayment, err := s.yookassaClient.GetPaymentStatus(ctx, *payment.YooKassaID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get payment status: %w", err)
+	}
+
+	var refunded float64
+	if yookassaPayment.RefundedAmount != nil {
+		refunded, _ = strconv.ParseFloat(yookassaPayment.RefundedAmount.Value, 64)
+	}
+	if refunded > 0 {
+		s.logger.Warn("Stale refund found in YooKassa, marking it succeeded", "payment_id", payment.ID, "refunded", refunded)
+		if err := s.storage.FinishRefund(ctx, payment.ID, RefundSucceeded, nil, nil); err != nil {
+			return false, fmt.Errorf("failed to save succeeded refund: %w", err)
+		}
+		return false, nil
+	}
+
+	// Условное обновление: параллельный запрос, который уже начал возврат заново, не будет сброшен
+	rearmed, err := s.storage.FailStaleRefund(ctx, payment.ID, staleBefore, "возврат прервался до ответа ЮKassa")
+	if err != nil {
+		return false, fmt.Errorf("failed to save failed refund: %w", err)
+	}
+	if rearmed {
+		s.logger.Warn("Stale refund not found in YooKassa, retrying it", "payment_id", payment.ID, "started_at", refund.CreatedAt)
+	}
+	return rearmed, nil
+}
+
 // IsManualPayment returns true if manual payment mode is enabled
 func (s *Service) IsManualPayment() bool {
 	return s.manualPayment
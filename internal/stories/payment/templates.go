@@ -0,0 +1,51 @@
+package payment
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DefaultDescriptionTemplate - описание платежа, если оператор не задал свое
+const DefaultDescriptionTemplate = "Оплата подписки #{id}"
+
+// maxDescriptionLength - ЮKassa принимает описание платежа и название позиции чека не длиннее 128 символов
+const maxDescriptionLength = 128
+
+// Templates - шаблоны описания платежа и названия позиции в чеке ЮKassa.
+// Подстановки: {id} - номер платежа, {amount} - сумма, {tariff} - тариф, {client} - WhatsApp клиента.
+// Пустой ReceiptItem - в чеке то же, что в описании
+type Templates struct {
+	Description string
+	ReceiptItem string
+}
+
+// Render возвращает описание платежа и название позиции чека; неизвестные подстановки остаются как есть
+func (t Templates) Render(paymentID int64, amount float64, meta CreatePaymentMeta) (description, receiptItem string) {
+	replacer := strings.NewReplacer(
+		"{id}", strconv.FormatInt(paymentID, 10),
+		"{amount}", strconv.FormatFloat(amount, 'f', -1, 64),
+		"{tariff}", meta.TariffName,
+		"{client}", meta.ClientWhatsApp,
+	)
+
+	descriptionTemplate := t.Description
+	if strings.TrimSpace(descriptionTemplate) == "" {
+		descriptionTemplate = DefaultDescriptionTemplate
+	}
+	description = renderTemplate(replacer, descriptionTemplate)
+
+	receiptItem = description
+	if strings.TrimSpace(t.ReceiptItem) != "" {
+		receiptItem = renderTemplate(replacer, t.ReceiptItem)
+	}
+	return description, receiptItem
+}
+
+// renderTemplate подставляет значения и убирает лишние пробелы, которые остаются от пустых подстановок
+func renderTemplate(replacer *strings.Replacer, template string) string {
+	text := strings.Join(strings.Fields(replacer.Replace(template)), " ")
+	if runes := []rune(text); len(runes) > maxDescriptionLength {
+		text = string(runes[:maxDescriptionLength])
+	}
+	return text
+}
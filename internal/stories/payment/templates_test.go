@@ -0,0 +1,67 @@
+package payment
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTemplatesRender(t *testing.T) {
+	meta := CreatePaymentMeta{TariffName: "3 месяца", ClientWhatsApp: "+996700123456"}
+
+	tests := []struct {
+		name            string
+		templates       Templates
+		meta            CreatePaymentMeta
+		wantDescription string
+		wantReceiptItem string
+	}{
+		{
+			name:            "default",
+			meta:            meta,
+			wantDescription: "Оплата подписки #42",
+			wantReceiptItem: "Оплата подписки #42",
+		},
+		{
+			name: "custom description and receipt item",
+			templates: Templates{
+				Description: "Услуги доступа, тариф {tariff}, клиент {client}",
+				ReceiptItem: "Предоставление доступа к сервису ({tariff}) на сумму {amount} ₽",
+			},
+			meta:            meta,
+			wantDescription: "Услуги доступа, тариф 3 месяца, клиент +996700123456",
+			wantReceiptItem: "Предоставление доступа к сервису (3 месяца) на сумму 350 ₽",
+		},
+		{
+			name:            "empty placeholder collapses spaces",
+			templates:       Templates{Description: "Подписка {tariff} №{id}"},
+			wantDescription: "Подписка №42",
+			wantReceiptItem: "Подписка №42",
+		},
+		{
+			name:            "unknown placeholder kept",
+			templates:       Templates{Description: "Заказ {order}"},
+			wantDescription: "Заказ {order}",
+			wantReceiptItem: "Заказ {order}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			description, receiptItem := tt.templates.Render(42, 350, tt.meta)
+			if description != tt.wantDescription {
+				t.Errorf("description = %q, want %q", description, tt.wantDescription)
+			}
+			if receiptItem != tt.wantReceiptItem {
+				t.Errorf("receiptItem = %q, want %q", receiptItem, tt.wantReceiptItem)
+			}
+		})
+	}
+}
+
+func TestTemplatesRenderTruncates(t *testing.T) {
+	description, _ := Templates{Description: strings.Repeat("ф", 200)}.Render(1, 100, CreatePaymentMeta{})
+	if n := utf8.RuneCountInString(description); n != maxDescriptionLength {
+		t.Errorf("description length = %d, want %d", n, maxDescriptionLength)
+	}
+}
@@ -0,0 +1,65 @@
+package peakhour
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// Lookback - за какой период учитываются оплаты клиента
+	Lookback = 180 * 24 * time.Hour
+	// MinPayments - сколько оплат нужно, чтобы доверять часу клиента; меньше - напоминание в час по умолчанию
+	MinPayments = 2
+)
+
+// Schedule - в какой час отправлять напоминания о продлении
+type Schedule struct {
+	// DefaultHour - час по умолчанию (0-23) для клиентов без истории оплат
+	DefaultHour int
+	// PeakHours - отправлять напоминание в час, когда клиент обычно оплачивает
+	PeakHours bool
+}
+
+// HourFor возвращает час напоминания клиента: его час оплат из hours или час по умолчанию
+func (s Schedule) HourFor(hours map[string]int, clientWhatsApp *string) int {
+	if !s.PeakHours || clientWhatsApp == nil {
+		return s.DefaultHour
+	}
+	if hour, ok := hours[*clientWhatsApp]; ok {
+		return hour
+	}
+	return s.DefaultHour
+}
+
+// PreferredHour возвращает час (в loc), в который клиент чаще всего завершал оплату.
+// При равенстве побеждает час последней оплаты. ok=false - оплат меньше MinPayments
+func PreferredHour(paidAt []time.Time, loc *time.Location) (hour int, ok bool) {
+	if len(paidAt) < MinPayments {
+		return 0, false
+	}
+
+	sorted := append([]time.Time(nil), paidAt...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	var counts [24]int
+	best := -1
+	for _, t := range sorted {
+		h := t.In(loc).Hour()
+		counts[h]++
+		if best < 0 || counts[h] >= counts[best] {
+			best = h
+		}
+	}
+	return best, true
+}
+
+// PreferredHours возвращает час оплат каждого клиента с достаточной историей
+func PreferredHours(paidAt map[string][]time.Time, loc *time.Location) map[string]int {
+	hours := make(map[string]int, len(paidAt))
+	for client, times := range paidAt {
+		if hour, ok := PreferredHour(times, loc); ok {
+			hours[client] = hour
+		}
+	}
+	return hours
+}
@@ -0,0 +1,66 @@
+package peakhour
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreferredHour(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, 3, day, hour, 15, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		paidAt []time.Time
+		want   int
+		wantOK bool
+	}{
+		{name: "no history", paidAt: nil, wantOK: false},
+		{name: "single payment", paidAt: []time.Time{at(1, 20)}, wantOK: false},
+		{name: "most frequent hour", paidAt: []time.Time{at(1, 20), at(2, 9), at(3, 20)}, want: 20, wantOK: true},
+		{name: "tie goes to latest payment", paidAt: []time.Time{at(5, 9), at(1, 20), at(2, 9), at(3, 20)}, want: 9, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := PreferredHour(tt.paidAt, time.UTC)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("PreferredHour() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPreferredHourUsesLocation(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	paidAt := []time.Time{
+		time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 2, 22, 30, 0, 0, time.UTC),
+	}
+
+	if got, _ := PreferredHour(paidAt, moscow); got != 1 {
+		t.Errorf("PreferredHour() = %d, want 1", got)
+	}
+}
+
+func TestScheduleHourFor(t *testing.T) {
+	client := "+79990001122"
+	unknown := "+79990003344"
+	hours := map[string]int{client: 21}
+
+	schedule := Schedule{DefaultHour: 7, PeakHours: true}
+	if got := schedule.HourFor(hours, &client); got != 21 {
+		t.Errorf("HourFor(client) = %d, want 21", got)
+	}
+	if got := schedule.HourFor(hours, &unknown); got != 7 {
+		t.Errorf("HourFor(unknown) = %d, want 7", got)
+	}
+	if got := schedule.HourFor(hours, nil); got != 7 {
+		t.Errorf("HourFor(nil) = %d, want 7", got)
+	}
+
+	schedule.PeakHours = false
+	if got := schedule.HourFor(hours, &client); got != 7 {
+		t.Errorf("HourFor() with peak hours disabled = %d, want 7", got)
+	}
+}
@@ -0,0 +1,36 @@
+package phonemerge
+
+// Variant - один формат номера клиента в подписках
+type Variant struct {
+	Phone               string `db:"phone"`
+	Subscriptions       int    `db:"subscriptions"`
+	ActiveSubscriptions int    `db:"active_subscriptions"`
+}
+
+// Group - клиент, подписки которого записаны под несколькими форматами одного номера.
+// Canonical - номер только из цифр, под ним подписки остаются после объединения
+type Group struct {
+	Canonical string
+	Variants  []Variant
+}
+
+// Subscriptions возвращает число подписок клиента во всех форматах
+func (g Group) Subscriptions() int {
+	var total int
+	for _, v := range g.Variants {
+		total += v.Subscriptions
+	}
+	return total
+}
+
+// HasActiveConflict - активные подписки есть под несколькими форматами: после объединения у клиента
+// окажется несколько активных подписок, и админу стоит проверить, не лишняя ли одна из них
+func (g Group) HasActiveConflict() bool {
+	var withActive int
+	for _, v := range g.Variants {
+		if v.ActiveSubscriptions > 0 {
+			withActive++
+		}
+	}
+	return withActive > 1
+}
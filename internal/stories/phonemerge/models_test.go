@@ -0,0 +1,52 @@
+package phonemerge
+
+import "testing"
+
+func TestGroupHasActiveConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		variants []Variant
+		want     bool
+	}{
+		{
+			name: "active in one format",
+			variants: []Variant{
+				{Phone: "+996700123456", Subscriptions: 2},
+				{Phone: "996700123456", Subscriptions: 1, ActiveSubscriptions: 1},
+			},
+			want: false,
+		},
+		{
+			name: "active in both formats",
+			variants: []Variant{
+				{Phone: "+996700123456", Subscriptions: 1, ActiveSubscriptions: 1},
+				{Phone: "996700123456", Subscriptions: 1, ActiveSubscriptions: 1},
+			},
+			want: true,
+		},
+		{
+			name: "no active",
+			variants: []Variant{
+				{Phone: "+996700123456", Subscriptions: 1},
+				{Phone: "996 700 123 456", Subscriptions: 1},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := Group{Canonical: "996700123456", Variants: tt.variants}
+			if got := g.HasActiveConflict(); got != tt.want {
+				t.Errorf("HasActiveConflict() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupSubscriptions(t *testing.T) {
+	g := Group{Variants: []Variant{{Subscriptions: 2}, {Subscriptions: 3}}}
+	if got := g.Subscriptions(); got != 5 {
+		t.Errorf("Subscriptions() = %d, want 5", got)
+	}
+}
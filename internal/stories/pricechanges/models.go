@@ -0,0 +1,38 @@
+package pricechanges
+
+import "time"
+
+// PriceChange - запланированное изменение цены тарифа.
+// До EffectiveAt тариф продается по OldPrice, поэтому клиенты успевают продлиться по старой цене
+type PriceChange struct {
+	ID                  int64
+	TariffID            int64
+	OldPrice            float64
+	NewPrice            float64
+	EffectiveAt         time.Time // начало дня, с которого действует новая цена
+	CreatedByTelegramID int64
+	NotifiedAt          *time.Time
+	AppliedAt           *time.Time
+	CancelledAt         *time.Time
+	CreatedAt           time.Time
+}
+
+// IsIncrease - цена растет; о снижении клиентов заранее не предупреждаем
+func (c *PriceChange) IsIncrease() bool {
+	return c.NewPrice > c.OldPrice
+}
+
+// Due - пора применить новую цену
+func (c *PriceChange) Due(now time.Time) bool {
+	return c.AppliedAt == nil && c.CancelledAt == nil && !now.Before(c.EffectiveAt)
+}
+
+// NeedsNotification - ассистентов еще не предупредили, а до повышения есть время продлиться
+func (c *PriceChange) NeedsNotification(now time.Time) bool {
+	return c.IsIncrease() && c.NotifiedAt == nil && c.AppliedAt == nil && c.CancelledAt == nil && now.Before(c.EffectiveAt)
+}
+
+// LastOldPriceDay - последний день, когда можно продлиться по старой цене (для отображения)
+func (c *PriceChange) LastOldPriceDay() time.Time {
+	return c.EffectiveAt.AddDate(0, 0, -1)
+}
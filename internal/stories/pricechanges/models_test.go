@@ -0,0 +1,37 @@
+package pricechanges
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriceChangeSchedule(t *testing.T) {
+	effective := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	before := effective.Add(-time.Hour)
+	notified := before.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		change     PriceChange
+		now        time.Time
+		wantNotify bool
+		wantDue    bool
+	}{
+		{"increase before effective date", PriceChange{OldPrice: 300, NewPrice: 350, EffectiveAt: effective}, before, true, false},
+		{"already notified", PriceChange{OldPrice: 300, NewPrice: 350, EffectiveAt: effective, NotifiedAt: &notified}, before, false, false},
+		{"decrease is not announced", PriceChange{OldPrice: 350, NewPrice: 300, EffectiveAt: effective}, before, false, false},
+		{"effective date reached", PriceChange{OldPrice: 300, NewPrice: 350, EffectiveAt: effective}, effective, false, true},
+		{"cancelled", PriceChange{OldPrice: 300, NewPrice: 350, EffectiveAt: effective, CancelledAt: &notified}, effective, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.change.NeedsNotification(tt.now); got != tt.wantNotify {
+				t.Errorf("NeedsNotification() = %v, want %v", got, tt.wantNotify)
+			}
+			if got := tt.change.Due(tt.now); got != tt.wantDue {
+				t.Errorf("Due() = %v, want %v", got, tt.wantDue)
+			}
+		})
+	}
+}
@@ -1,17 +1,72 @@
 package servers
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
 
 type Server struct {
-	ID           int64
-	Name         string
-	UIURL        string
-	UIPassword   string
-	CurrentUsers int
-	MaxUsers     int
-	Archived     bool
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID              int64
+	Name            string
+	UIURL           string
+	UIPassword      string
+	CurrentUsers    int
+	MaxUsers        int
+	Archived        bool
+	PriceMultiplier float64 // Наценка за локацию: цена тарифа * PriceMultiplier + PriceSurcharge
+	PriceSurcharge  float64
+	Cluster         string // Кластер серверов ("EU", "Premium"); пусто - вне кластеров
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// FitsCluster возвращает true если на сервере можно разместить подписку тарифа кластера cluster.
+// Пустой cluster - тариф без ограничения, подходит любой сервер
+func (s *Server) FitsCluster(cluster string) bool {
+	return cluster == "" || s.Cluster == cluster
+}
+
+// HasPriceModifier возвращает true если на сервере цена отличается от цены тарифа
+func (s *Server) HasPriceModifier() bool {
+	return s != nil && (s.multiplier() != 1 || s.PriceSurcharge != 0)
+}
+
+// EffectivePrice возвращает цену тарифа на этом сервере. Бесплатные тарифы остаются бесплатными
+func (s *Server) EffectivePrice(price float64) float64 {
+	if !s.HasPriceModifier() || price == 0 {
+		return price
+	}
+	return max(0, math.Round((price*s.multiplier()+s.PriceSurcharge)*100)/100)
+}
+
+// PriceModifierText описывает наценку для админа: "×1.5 +100 ₽", "без наценки"
+func (s *Server) PriceModifierText() string {
+	if !s.HasPriceModifier() {
+		return "без наценки"
+	}
+	var text string
+	if s.multiplier() != 1 {
+		text = fmt.Sprintf("×%g", s.multiplier())
+	}
+	if s.PriceSurcharge != 0 {
+		if text != "" {
+			text += " "
+		}
+		text += fmt.Sprintf("%+.0f ₽", s.PriceSurcharge)
+	}
+	return text
+}
+
+// multiplier - множитель цены; 0 (сервер создан до появления наценок) означает без множителя
+func (s *Server) multiplier() float64 {
+	if s.PriceMultiplier <= 0 {
+		return 1
+	}
+	return s.PriceMultiplier
 }
 
 // GetCriteria - критерии для получения сервера
@@ -29,10 +84,42 @@ type ListCriteria struct {
 
 // UpdateParams - параметры для обновления сервера
 type UpdateParams struct {
-	Name         *string
-	UIURL        *string
-	UIPassword   *string
-	CurrentUsers *int
-	MaxUsers     *int
-	Archived     *bool
+	Name            *string
+	UIURL           *string
+	UIPassword      *string
+	CurrentUsers    *int
+	MaxUsers        *int
+	Archived        *bool
+	PriceMultiplier *float64
+	PriceSurcharge  *float64
+	Cluster         *string // "" - убрать сервер из кластера
+}
+
+// maxClusterLength - ограничение длины названия кластера
+const maxClusterLength = 32
+
+// NormalizeCluster проверяет название кластера: буквы, цифры, пробел и дефис, до 32 символов.
+// Пробелы по краям обрезаются; "-" означает "без кластера" и возвращается как пустая строка
+func NormalizeCluster(input string) (string, bool) {
+	cluster := strings.Join(strings.Fields(input), " ")
+	if cluster == "-" || cluster == "" {
+		return "", true
+	}
+	if utf8.RuneCountInString(cluster) > maxClusterLength {
+		return "", false
+	}
+	for _, r := range cluster {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && r != '-' {
+			return "", false
+		}
+	}
+	return cluster, true
+}
+
+// ClusterName - название кластера для показа админу
+func ClusterName(cluster string) string {
+	if cluster == "" {
+		return "без кластера"
+	}
+	return cluster
 }
@@ -0,0 +1,53 @@
+package servers
+
+import "testing"
+
+func TestServerEffectivePrice(t *testing.T) {
+	tests := []struct {
+		name   string
+		server *Server
+		price  float64
+		want   float64
+		text   string
+	}{
+		{name: "no server", server: nil, price: 300, want: 300, text: "без наценки"},
+		{name: "legacy zero multiplier", server: &Server{}, price: 300, want: 300, text: "без наценки"},
+		{name: "multiplier", server: &Server{PriceMultiplier: 1.5}, price: 300, want: 450, text: "×1.5"},
+		{name: "surcharge", server: &Server{PriceMultiplier: 1, PriceSurcharge: 100}, price: 300, want: 400, text: "+100 ₽"},
+		{name: "both", server: &Server{PriceMultiplier: 1.2, PriceSurcharge: -50}, price: 350, want: 370, text: "×1.2 -50 ₽"},
+		{name: "free tariff stays free", server: &Server{PriceMultiplier: 2, PriceSurcharge: 100}, price: 0, want: 0, text: "×2 +100 ₽"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.server.EffectivePrice(tt.price); got != tt.want {
+				t.Errorf("EffectivePrice(%v) = %v, want %v", tt.price, got, tt.want)
+			}
+			if got := tt.server.PriceModifierText(); got != tt.text {
+				t.Errorf("PriceModifierText() = %q, want %q", got, tt.text)
+			}
+		})
+	}
+}
+
+func TestNormalizeCluster(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"  EU  ", "EU", true},
+		{"Asia  Pacific", "Asia Pacific", true},
+		{"Премиум-2", "Премиум-2", true},
+		{"-", "", true},
+		{"", "", true},
+		{"EU/West", "", false},
+		{"abcdefghijklmnopqrstuvwxyz0123456", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizeCluster(tt.input)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("NormalizeCluster(%q) = %q, %v, want %q, %v", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package servers
 
 import (
 	"context"
+	"sort"
 
 	"github.com/pkg/errors"
 )
@@ -86,3 +87,24 @@ func (s *Service) DecrementServerUsers(ctx context.Context, serverID int64) erro
 	// Оставлено для обратной совместимости, но больше не используется
 	return nil
 }
+
+// ListClusters возвращает названия кластеров, в которых есть активные серверы, по алфавиту
+func (s *Service) ListClusters(ctx context.Context) ([]string, error) {
+	archived := false
+	list, err := s.storage.ListServers(ctx, ListCriteria{Archived: &archived})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list servers for clusters")
+	}
+
+	seen := make(map[string]bool)
+	var clusters []string
+	for _, server := range list {
+		if server.Cluster == "" || seen[server.Cluster] {
+			continue
+		}
+		seen[server.Cluster] = true
+		clusters = append(clusters, server.Cluster)
+	}
+	sort.Strings(clusters)
+	return clusters, nil
+}
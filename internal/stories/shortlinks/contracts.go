@@ -0,0 +1,11 @@
+package shortlinks
+
+import "context"
+
+type Storage interface {
+	CreatePaymentLink(ctx context.Context, link Link) (*Link, error)
+	GetPaymentLink(ctx context.Context, token string) (*Link, error)
+	RegisterPaymentLinkClick(ctx context.Context, token string) error
+	CreateLinkClick(ctx context.Context, click Click) error
+	GetLinkClickStats(ctx context.Context, criteria ClickCriteria) (*ClickStats, error)
+}
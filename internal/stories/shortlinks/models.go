@@ -0,0 +1,73 @@
+package shortlinks
+
+import (
+	"fmt"
+	"time"
+)
+
+// Link - короткая ссылка /p/<token> на страницу оплаты
+type Link struct {
+	Token         string
+	PaymentID     int64
+	TargetURL     string
+	Clicks        int
+	LastClickedAt *time.Time
+	CreatedAt     time.Time
+}
+
+type LinkType string
+
+const (
+	LinkTypePayment LinkType = "payment" // короткая ссылка на оплату
+	LinkTypeConfig  LinkType = "config"  // страница подключения /wg/connect
+)
+
+// Click - один переход по ссылке. Подписка и заказ определяются по платежу в момент перехода
+type Click struct {
+	LinkType  LinkType
+	LinkKey   string // токен короткой ссылки или ID конфига
+	PaymentID *int64
+	UserAgent string
+	ClickedAt time.Time
+}
+
+// ClickCriteria - по какой сущности считать переходы (заполненные поля объединяются через AND)
+type ClickCriteria struct {
+	PaymentID      *int64
+	SubscriptionID *int64
+	OrderID        *int64
+}
+
+// ClickStats - сводка переходов для ассистента
+type ClickStats struct {
+	Count         int
+	LastClickedAt *time.Time
+}
+
+// Summary возвращает "клиент открыл ссылку N раз, последний раз в HH:MM"
+func (s ClickStats) Summary(now time.Time) string {
+	if s.Count == 0 || s.LastClickedAt == nil {
+		return "👀 Клиент еще не открывал ссылку"
+	}
+
+	last := s.LastClickedAt.In(now.Location())
+	when := "в " + last.Format("15:04")
+	if y, m, d := last.Date(); y != now.Year() || m != now.Month() || d != now.Day() {
+		when = last.Format("02.01") + " в " + last.Format("15:04")
+	}
+
+	return fmt.Sprintf("👀 Клиент открыл ссылку %d %s, последний раз %s", s.Count, pluralTimes(s.Count), when)
+}
+
+// pluralTimes склоняет "раз": 1 раз, 2 раза, 5 раз
+func pluralTimes(n int) string {
+	if n%100 >= 12 && n%100 <= 14 {
+		return "раз"
+	}
+	switch n % 10 {
+	case 2, 3, 4:
+		return "раза"
+	default:
+		return "раз"
+	}
+}
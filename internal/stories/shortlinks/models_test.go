@@ -0,0 +1,34 @@
+package shortlinks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClickStatsSummary(t *testing.T) {
+	now := time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+	at := func(d, h, m int) *time.Time {
+		v := time.Date(2026, 10, d, h, m, 0, 0, time.UTC)
+		return &v
+	}
+
+	tests := []struct {
+		name  string
+		stats ClickStats
+		want  string
+	}{
+		{name: "no clicks", stats: ClickStats{}, want: "👀 Клиент еще не открывал ссылку"},
+		{name: "once today", stats: ClickStats{Count: 1, LastClickedAt: at(16, 9, 5)}, want: "👀 Клиент открыл ссылку 1 раз, последний раз в 09:05"},
+		{name: "few times", stats: ClickStats{Count: 3, LastClickedAt: at(16, 17, 40)}, want: "👀 Клиент открыл ссылку 3 раза, последний раз в 17:40"},
+		{name: "many times yesterday", stats: ClickStats{Count: 12, LastClickedAt: at(15, 22, 10)}, want: "👀 Клиент открыл ссылку 12 раз, последний раз 15.10 в 22:10"},
+		{name: "twenty two", stats: ClickStats{Count: 22, LastClickedAt: at(16, 8, 0)}, want: "👀 Клиент открыл ссылку 22 раза, последний раз в 08:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.Summary(now); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
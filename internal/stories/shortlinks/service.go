@@ -0,0 +1,102 @@
+package shortlinks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// tokenBytes - 6 случайных байт дают токен из 8 символов
+const tokenBytes = 6
+
+// PathPrefix - путь коротких ссылок на API сервере
+const PathPrefix = "/p/"
+
+type Service struct {
+	storage Storage
+	baseURL string
+}
+
+// NewService создает сервис коротких ссылок; при пустом baseURL ссылки не сокращаются
+func NewService(storage Storage, baseURL string) *Service {
+	return &Service{
+		storage: storage,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Shorten сохраняет ссылку на оплату и возвращает короткий адрес
+func (s *Service) Shorten(ctx context.Context, paymentID int64, targetURL string) (string, error) {
+	if s.baseURL == "" {
+		return targetURL, nil
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+
+	link, err := s.storage.CreatePaymentLink(ctx, Link{
+		Token:     token,
+		PaymentID: paymentID,
+		TargetURL: targetURL,
+	})
+	if err != nil {
+		return "", fmt.Errorf("create payment link: %w", err)
+	}
+
+	return s.baseURL + PathPrefix + link.Token, nil
+}
+
+// Open возвращает адрес для редиректа и учитывает переход. nil - ссылка не найдена
+func (s *Service) Open(ctx context.Context, token string, userAgent string) (*Link, error) {
+	link, err := s.storage.GetPaymentLink(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("get payment link: %w", err)
+	}
+	if link == nil {
+		return nil, nil
+	}
+
+	if err := s.storage.RegisterPaymentLinkClick(ctx, token); err != nil {
+		return nil, fmt.Errorf("register click: %w", err)
+	}
+
+	err = s.storage.CreateLinkClick(ctx, Click{
+		LinkType:  LinkTypePayment,
+		LinkKey:   token,
+		PaymentID: &link.PaymentID,
+		UserAgent: userAgent,
+		ClickedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create link click: %w", err)
+	}
+
+	return link, nil
+}
+
+// RecordConfigOpen учитывает открытие страницы подключения
+func (s *Service) RecordConfigOpen(ctx context.Context, configID string, userAgent string) error {
+	err := s.storage.CreateLinkClick(ctx, Click{
+		LinkType:  LinkTypeConfig,
+		LinkKey:   configID,
+		UserAgent: userAgent,
+		ClickedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("create link click: %w", err)
+	}
+	return nil
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,76 @@
+package shortlinks
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type memoryStorage struct {
+	links  map[string]*Link
+	clicks []Click
+}
+
+func (m *memoryStorage) CreatePaymentLink(_ context.Context, link Link) (*Link, error) {
+	m.links[link.Token] = &link
+	return &link, nil
+}
+
+func (m *memoryStorage) GetPaymentLink(_ context.Context, token string) (*Link, error) {
+	return m.links[token], nil
+}
+
+func (m *memoryStorage) RegisterPaymentLinkClick(_ context.Context, token string) error {
+	m.links[token].Clicks++
+	return nil
+}
+
+func (m *memoryStorage) CreateLinkClick(_ context.Context, click Click) error {
+	m.clicks = append(m.clicks, click)
+	return nil
+}
+
+func (m *memoryStorage) GetLinkClickStats(_ context.Context, _ ClickCriteria) (*ClickStats, error) {
+	return &ClickStats{Count: len(m.clicks)}, nil
+}
+
+func TestShortenAndOpen(t *testing.T) {
+	const target = "https://yoomoney.ru/checkout/payments/v2/contract?orderId=2e4a1b7c-000f-5000-9000-1a2b3c4d5e6f"
+	ctx := context.Background()
+	store := &memoryStorage{links: map[string]*Link{}}
+	service := NewService(store, "https://pay.example.com/")
+
+	short, err := service.Shorten(ctx, 7, target)
+	if err != nil {
+		t.Fatalf("Shorten() unexpected error: %v", err)
+	}
+
+	token, ok := strings.CutPrefix(short, "https://pay.example.com/p/")
+	if !ok || len(token) != 8 {
+		t.Fatalf("Shorten() = %q, want https://pay.example.com/p/<8 chars>", short)
+	}
+
+	link, err := service.Open(ctx, token, "WhatsApp/2.24")
+	if err != nil || link == nil {
+		t.Fatalf("Open(%q) = %v, %v", token, link, err)
+	}
+	if link.TargetURL != target || link.PaymentID != 7 || store.links[token].Clicks != 1 {
+		t.Errorf("Open(%q) = %+v, clicks %d", token, link, store.links[token].Clicks)
+	}
+	if len(store.clicks) != 1 || store.clicks[0].UserAgent != "WhatsApp/2.24" || *store.clicks[0].PaymentID != 7 {
+		t.Errorf("Open(%q) recorded clicks %+v", token, store.clicks)
+	}
+
+	if link, err := service.Open(ctx, "missing", ""); err != nil || link != nil {
+		t.Errorf("Open(missing) = %v, %v, want nil, nil", link, err)
+	}
+}
+
+func TestShortenDisabled(t *testing.T) {
+	service := NewService(&memoryStorage{links: map[string]*Link{}}, "")
+
+	got, err := service.Shorten(context.Background(), 1, "https://example.com/pay")
+	if err != nil || got != "https://example.com/pay" {
+		t.Errorf("Shorten() = %q, %v, want original URL", got, err)
+	}
+}
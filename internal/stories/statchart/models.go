@@ -0,0 +1,107 @@
+package statchart
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// Days - за сколько дней показываются новые подписки
+	Days = 30
+	// Weeks - за сколько недель показывается выручка; последняя неделя заканчивается сегодня
+	Weeks = 5
+)
+
+// Payment - оплаченный платеж для графика выручки
+type Payment struct {
+	CreatedAt time.Time `db:"created_at"`
+	Amount    float64   `db:"amount"`
+}
+
+// Chart - новые подписки по дням и выручка по неделям. DailySubs[Days-1] - сегодня,
+// WeeklyRevenue[Weeks-1] - последние 7 дней включая сегодня
+type Chart struct {
+	Today         time.Time
+	DailySubs     [Days]int
+	WeeklyRevenue [Weeks]float64
+}
+
+// Start возвращает первый день графика новых подписок
+func (c *Chart) Start() time.Time {
+	return c.Today.AddDate(0, 0, -(Days - 1))
+}
+
+// RevenueStart возвращает начало первой недели графика выручки
+func (c *Chart) RevenueStart() time.Time {
+	return c.Today.AddDate(0, 0, 1-7*Weeks)
+}
+
+// NewChart возвращает пустой график на дату now: по нему видно, за какой период выбирать данные
+func NewChart(now time.Time) *Chart {
+	now = now.UTC()
+	return &Chart{Today: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// Build раскладывает даты создания подписок по дням, а платежи - по неделям; даты вне периода не учитываются
+func Build(now time.Time, subsCreated []time.Time, payments []Payment) *Chart {
+	c := NewChart(now)
+
+	for _, t := range subsCreated {
+		day := daysBetween(c.Start(), t)
+		if day >= 0 && day < Days {
+			c.DailySubs[day]++
+		}
+	}
+	for _, p := range payments {
+		day := daysBetween(c.RevenueStart(), p.CreatedAt)
+		if day >= 0 && day < Weeks*7 {
+			c.WeeklyRevenue[day/7] += p.Amount
+		}
+	}
+	return c
+}
+
+// daysBetween возвращает номер дня t от начала from (from - полночь UTC); до from - отрицательный
+func daysBetween(from, t time.Time) int {
+	d := t.UTC().Sub(from)
+	if d < 0 {
+		return -1
+	}
+	return int(d / (24 * time.Hour))
+}
+
+// MaxDailySubs возвращает максимум новых подписок за день - верх шкалы графика
+func (c *Chart) MaxDailySubs() int {
+	var m int
+	for _, n := range c.DailySubs {
+		m = max(m, n)
+	}
+	return m
+}
+
+// MaxWeeklyRevenue возвращает максимум выручки за неделю - верх шкалы графика
+func (c *Chart) MaxWeeklyRevenue() float64 {
+	var m float64
+	for _, r := range c.WeeklyRevenue {
+		m = max(m, r)
+	}
+	return m
+}
+
+// Caption подписывает график: на картинке нет текста, поэтому шкалы и периоды - в подписи к фото
+func (c *Chart) Caption() string {
+	var subs int
+	for _, n := range c.DailySubs {
+		subs += n
+	}
+	var revenue float64
+	for _, r := range c.WeeklyRevenue {
+		revenue += r
+	}
+
+	return fmt.Sprintf("📈 Вверху — новые подписки по дням с %s: всего %d, максимум %d в день\n"+
+		"💰 Внизу — выручка по неделям с %s: всего %.0f ₽, максимум %.0f ₽ за неделю\n"+
+		"Верх шкалы — максимум, линии — каждые 25%%",
+		c.Start().Format("02.01"), subs, c.MaxDailySubs(),
+		c.RevenueStart().Format("02.01"), revenue, c.MaxWeeklyRevenue())
+}
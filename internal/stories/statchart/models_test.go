@@ -0,0 +1,65 @@
+package statchart
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	today := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	c := Build(now,
+		[]time.Time{
+			now,
+			today.Add(time.Minute),
+			today.AddDate(0, 0, -1),
+			today.AddDate(0, 0, -(Days - 1)),
+			today.AddDate(0, 0, -Days), // раньше периода
+		},
+		[]Payment{
+			{CreatedAt: now, Amount: 300},
+			{CreatedAt: today.AddDate(0, 0, -6), Amount: 200},
+			{CreatedAt: today.AddDate(0, 0, -7), Amount: 150},
+			{CreatedAt: today.AddDate(0, 0, 1-7*Weeks), Amount: 100},
+			{CreatedAt: today.AddDate(0, 0, -7*Weeks), Amount: 999}, // раньше периода
+		},
+	)
+
+	if c.DailySubs[Days-1] != 2 || c.DailySubs[Days-2] != 1 || c.DailySubs[0] != 1 {
+		t.Errorf("DailySubs = %v", c.DailySubs)
+	}
+	want := [Weeks]float64{100, 0, 0, 150, 500}
+	if c.WeeklyRevenue != want {
+		t.Errorf("WeeklyRevenue = %v, want %v", c.WeeklyRevenue, want)
+	}
+	if c.MaxDailySubs() != 2 || c.MaxWeeklyRevenue() != 500 {
+		t.Errorf("max = %d, %.0f", c.MaxDailySubs(), c.MaxWeeklyRevenue())
+	}
+	if caption := c.Caption(); !strings.Contains(caption, "с 17.09: всего 4, максимум 2") ||
+		!strings.Contains(caption, "с 12.09: всего 750 ₽, максимум 500 ₽") {
+		t.Errorf("Caption() = %q", caption)
+	}
+}
+
+func TestRender(t *testing.T) {
+	for _, c := range []*Chart{
+		Build(time.Now(), nil, nil),
+		Build(time.Now(), []time.Time{time.Now()}, []Payment{{CreatedAt: time.Now(), Amount: 100}}),
+	} {
+		data, err := c.Render()
+		if err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("png.Decode() error = %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != chartWidth || b.Dy() != chartHeight {
+			t.Errorf("size = %v", b)
+		}
+	}
+}
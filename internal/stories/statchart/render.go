@@ -0,0 +1,94 @@
+package statchart
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+)
+
+// Размеры картинки: две панели друг под другом, сверху - подписки по дням, снизу - выручка по неделям
+const (
+	chartWidth  = 800
+	chartHeight = 500
+	chartMargin = 30
+	panelGap    = 40
+	barGap      = 4
+	gridLines   = 4
+)
+
+var (
+	backgroundColor = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	gridColor       = color.RGBA{R: 0xe5, G: 0xe5, B: 0xe5, A: 0xff}
+	axisColor       = color.RGBA{R: 0x99, G: 0x99, B: 0x99, A: 0xff}
+	subsColor       = color.RGBA{R: 0x2f, G: 0x80, B: 0xed, A: 0xff}
+	weekendColor    = color.RGBA{R: 0x8a, G: 0xb6, B: 0xf5, A: 0xff}
+	revenueColor    = color.RGBA{R: 0xd4, G: 0x26, B: 0x31, A: 0xff}
+)
+
+// Render рисует график в PNG. Текста на картинке нет - подписи в Caption
+func (c *Chart) Render() ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: backgroundColor}, image.Point{}, draw.Src)
+
+	panelHeight := (chartHeight - 2*chartMargin - panelGap) / 2
+	top := image.Rect(chartMargin, chartMargin, chartWidth-chartMargin, chartMargin+panelHeight)
+	bottom := top.Add(image.Pt(0, panelHeight+panelGap))
+
+	subs := make([]float64, Days)
+	colors := make([]color.Color, Days)
+	for i, n := range c.DailySubs {
+		subs[i] = float64(n)
+		colors[i] = subsColor
+		if day := c.Start().AddDate(0, 0, i).Weekday(); day == 0 || day == 6 {
+			colors[i] = weekendColor
+		}
+	}
+	drawBars(img, top, subs, colors)
+
+	revenue := make([]float64, Weeks)
+	revenueColors := make([]color.Color, Weeks)
+	for i, r := range c.WeeklyRevenue {
+		revenue[i] = r
+		revenueColors[i] = revenueColor
+	}
+	drawBars(img, bottom, revenue, revenueColors)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawBars рисует столбцы в панели с сеткой; верх панели - максимальное значение
+func drawBars(img *image.RGBA, panel image.Rectangle, values []float64, colors []color.Color) {
+	for i := 1; i <= gridLines; i++ {
+		y := panel.Max.Y - panel.Dy()*i/gridLines
+		fillRect(img, image.Rect(panel.Min.X, y, panel.Max.X, y+1), gridColor)
+	}
+	fillRect(img, image.Rect(panel.Min.X, panel.Max.Y, panel.Max.X, panel.Max.Y+2), axisColor)
+
+	var maxValue float64
+	for _, v := range values {
+		maxValue = max(maxValue, v)
+	}
+	if maxValue == 0 || len(values) == 0 {
+		return
+	}
+
+	slot := panel.Dx() / len(values)
+	for i, v := range values {
+		height := int(v / maxValue * float64(panel.Dy()))
+		if v > 0 && height == 0 {
+			height = 1
+		}
+		x := panel.Min.X + i*slot
+		fillRect(img, image.Rect(x+barGap/2, panel.Max.Y-height, x+slot-barGap/2, panel.Max.Y), colors[i])
+	}
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
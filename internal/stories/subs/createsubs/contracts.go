@@ -3,6 +3,7 @@ package createsubs
 import (
 	"context"
 
+	"kurut-bot/internal/stories/bonusrules"
 	"kurut-bot/internal/stories/servers"
 	"kurut-bot/internal/stories/subs"
 	"kurut-bot/internal/stories/tariffs"
@@ -14,11 +15,17 @@ type storage interface {
 	GetTariff(ctx context.Context, criteria tariffs.GetCriteria) (*tariffs.Tariff, error)
 	LinkPaymentToSubscriptions(ctx context.Context, paymentID int64, subscriptionIDs []int64) error
 	UpdateSubscriptionGeneratedUserID(ctx context.Context, subscriptionID int64, generatedUserID string) error
-	GetAvailableServer(ctx context.Context) (*servers.Server, error)
+	GetAvailableServer(ctx context.Context, cluster string) (*servers.Server, error)
 	GetServerByID(ctx context.Context, serverID int64) (*servers.Server, error)
+	GetActiveUsersCountByServer(ctx context.Context, serverID int64) (int, error)
 	IncrementServerUsers(ctx context.Context, serverID int64) error
 	FindActiveSubscriptionByWhatsApp(ctx context.Context, whatsapp string) (*subs.Subscription, error)
 	ExtendSubscription(ctx context.Context, subscriptionID int64, additionalDays int) error
 	CountWeeklyReferrals(ctx context.Context, referrerWhatsApp string) (int, error)
 	HasPaidSubscriptionByPhone(ctx context.Context, phoneNumber string) (bool, error)
+	SetSubscriptionCustomPrice(ctx context.Context, subscriptionID int64, price *float64) error
+}
+
+type bonusRules interface {
+	Evaluate(ctx context.Context, e bonusrules.Event) (bonusrules.Outcome, error)
 }
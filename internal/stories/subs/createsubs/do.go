@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"kurut-bot/internal/stories/bonusrules"
+	"kurut-bot/internal/stories/servers"
 	"kurut-bot/internal/stories/subs"
 	"kurut-bot/internal/stories/tariffs"
 
@@ -12,18 +14,18 @@ import (
 
 type Service struct {
 	storage storage
+	rules   bonusRules
 	now     func() time.Time
 }
 
-func NewService(storage storage, now func() time.Time) *Service {
+func NewService(storage storage, rules bonusRules, now func() time.Time) *Service {
 	return &Service{
 		storage: storage,
+		rules:   rules,
 		now:     now,
 	}
 }
 
-const referralBonusDays = 10
-
 func (s *Service) CreateSubscription(ctx context.Context, req *subs.CreateSubscriptionRequest) (*subs.CreateSubscriptionResult, error) {
 	tariff, err := s.storage.GetTariff(ctx, tariffs.GetCriteria{ID: &req.TariffID})
 	if err != nil {
@@ -33,21 +35,29 @@ func (s *Service) CreateSubscription(ctx context.Context, req *subs.CreateSubscr
 		return nil, errors.Errorf("tariff not found")
 	}
 
-	// Получаем доступный сервер
-	server, err := s.storage.GetAvailableServer(ctx)
-	if err != nil {
-		return nil, errors.Errorf("failed to get available server: %v", err)
+	// Получаем выбранный сервер; если он уже не подходит - доступный сервер кластера тарифа
+	var server *servers.Server
+	if req.ServerID != nil {
+		server, err = s.pinnedServer(ctx, *req.ServerID, tariff.Cluster)
+		if err != nil {
+			return nil, err
+		}
 	}
 	if server == nil {
-		return nil, errors.Errorf("no available servers")
+		server, err = s.storage.GetAvailableServer(ctx, tariff.Cluster)
+		if err != nil {
+			return nil, errors.Errorf("failed to get available server: %v", err)
+		}
+		if server == nil {
+			return nil, errors.Errorf("no available servers")
+		}
 	}
 
 	now := s.now()
 
-	// No bonus for the new client - only the referrer gets bonus
 	durationDays := tariff.DurationDays
-	var referralBonusApplied bool
 	var referrerWhatsApp *string
+	var referralOutcome, clientOutcome bonusrules.Outcome
 
 	if req.ReferrerSubscriptionID != nil {
 		// Get referrer's WhatsApp for display in success message
@@ -60,11 +70,17 @@ func (s *Service) CreateSubscription(ctx context.Context, req *subs.CreateSubscr
 		if req.PaymentID != nil {
 			hasPaidSub, err := s.storage.HasPaidSubscriptionByPhone(ctx, req.ClientWhatsApp)
 			if err == nil && !hasPaidSub {
-				referralBonusApplied = true
+				referralOutcome = s.evaluateRules(ctx, bonusrules.TriggerReferralPaid, tariff, req.Channel)
 			}
 		}
 	}
 
+	// Бонусы самому клиенту за оплату новой подписки
+	if req.PaymentID != nil {
+		clientOutcome = s.evaluateRules(ctx, bonusrules.TriggerSubscriptionPaid, tariff, req.Channel)
+		durationDays += clientOutcome.BonusDays
+	}
+
 	expiresAt := now.AddDate(0, 0, durationDays)
 
 	subscription := subs.Subscription{
@@ -89,7 +105,8 @@ func (s *Service) CreateSubscription(ctx context.Context, req *subs.CreateSubscr
 	// Генерируем user_id после создания подписки (когда уже есть ID)
 	generatedUserID := subs.GenerateUserID(created.ID, req.CreatedByTelegramID, req.ClientWhatsApp)
 
-	// Обновляем подписку с generated_user_id
+	// Обновляем подписку с generated_user_id. Повтора не бывает: id начинается с ID новой подписки,
+	// уникальный индекс только страхует от ошибок (subs.ErrGeneratedUserIDTaken)
 	err = s.storage.UpdateSubscriptionGeneratedUserID(ctx, created.ID, generatedUserID)
 	if err != nil {
 		return nil, errors.Errorf("failed to update subscription with generated user id: %v", err)
@@ -103,11 +120,22 @@ func (s *Service) CreateSubscription(ctx context.Context, req *subs.CreateSubscr
 		}
 	}
 
+	if clientOutcome.DiscountPercent > 0 {
+		price := bonusrules.DiscountedPrice(tariff.Price, clientOutcome.DiscountPercent)
+		if err := s.storage.SetSubscriptionCustomPrice(ctx, created.ID, &price); err == nil {
+			created.CustomPrice = &price
+		}
+	}
+
 	// Extend referrer's subscription if referral bonus was applied
+	referralBonusApplied := req.ReferrerSubscriptionID != nil && referralOutcome.BonusDays > 0
 	var referrerNewExpiresAt *time.Time
 	var referrerWeeklyCount int
-	if referralBonusApplied && req.ReferrerSubscriptionID != nil {
-		if err := s.storage.ExtendSubscription(ctx, *req.ReferrerSubscriptionID, referralBonusDays); err == nil {
+	if req.ReferrerSubscriptionID != nil && referralOutcome.DiscountPercent > 0 {
+		s.applyReferrerDiscount(ctx, *req.ReferrerSubscriptionID, referralOutcome.DiscountPercent)
+	}
+	if referralBonusApplied {
+		if err := s.storage.ExtendSubscription(ctx, *req.ReferrerSubscriptionID, referralOutcome.BonusDays); err == nil {
 			// Get updated referrer subscription to get new expiry date
 			updatedReferrerSub, _ := s.storage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{*req.ReferrerSubscriptionID}})
 			if updatedReferrerSub != nil {
@@ -127,12 +155,67 @@ func (s *Service) CreateSubscription(ctx context.Context, req *subs.CreateSubscr
 		ServerUIURL:          &server.UIURL,
 		ServerUIPassword:     &server.UIPassword,
 		ReferralBonusApplied: referralBonusApplied,
+		ReferralBonusDays:    referralOutcome.BonusDays,
 		ReferrerWhatsApp:     referrerWhatsApp,
 		ReferrerNewExpiresAt: referrerNewExpiresAt,
 		ReferrerWeeklyCount:  referrerWeeklyCount,
+		ClientBonusDays:      clientOutcome.BonusDays,
+		BonusNotifications:   append(referralOutcome.Notifications, clientOutcome.Notifications...),
 	}, nil
 }
 
+// pinnedServer возвращает закрепленный за заказом сервер, если на нем еще можно разместить подписку:
+// между выбором сервера и оплатой его могли заархивировать, заполнить или перенести в другой кластер.
+// nil - сервер больше не подходит
+func (s *Service) pinnedServer(ctx context.Context, serverID int64, cluster string) (*servers.Server, error) {
+	server, err := s.storage.GetServerByID(ctx, serverID)
+	if err != nil {
+		return nil, errors.Errorf("failed to get server: %v", err)
+	}
+	if server == nil || server.Archived || !server.FitsCluster(cluster) {
+		return nil, nil
+	}
+	active, err := s.storage.GetActiveUsersCountByServer(ctx, serverID)
+	if err != nil {
+		return nil, errors.Errorf("failed to count server users: %v", err)
+	}
+	if active >= server.MaxUsers {
+		return nil, nil
+	}
+	return server, nil
+}
+
+// evaluateRules считает бонусы по правилам из bonus_rules; ошибка чтения правил не мешает созданию подписки
+func (s *Service) evaluateRules(ctx context.Context, trigger bonusrules.Trigger, tariff *tariffs.Tariff, channel bonusrules.Channel) bonusrules.Outcome {
+	outcome, err := s.rules.Evaluate(ctx, bonusrules.Event{
+		Trigger:  trigger,
+		TariffID: tariff.ID,
+		Amount:   tariff.Price,
+		Channel:  channel,
+	})
+	if err != nil {
+		return bonusrules.Outcome{}
+	}
+	return outcome
+}
+
+// applyReferrerDiscount ставит пригласившему скидку на продление от цены его тарифа (не повышая уже заданную цену)
+func (s *Service) applyReferrerDiscount(ctx context.Context, subscriptionID int64, percent int) {
+	sub, err := s.storage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{subscriptionID}})
+	if err != nil || sub == nil {
+		return
+	}
+	tariff, err := s.storage.GetTariff(ctx, tariffs.GetCriteria{ID: &sub.TariffID})
+	if err != nil || tariff == nil {
+		return
+	}
+	price := bonusrules.DiscountedPrice(tariff.Price, percent)
+	if sub.CustomPrice != nil && *sub.CustomPrice <= price {
+		return
+	}
+	_ = s.storage.SetSubscriptionCustomPrice(ctx, sub.ID, &price)
+}
+
 // FindActiveSubscriptionByWhatsApp finds an active subscription by client WhatsApp number
 func (s *Service) FindActiveSubscriptionByWhatsApp(ctx context.Context, whatsapp string) (*subs.Subscription, error) {
 	return s.storage.FindActiveSubscriptionByWhatsApp(ctx, whatsapp)
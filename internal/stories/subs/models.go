@@ -1,9 +1,15 @@
 package subs
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
+
+	"kurut-bot/internal/stories/bonusrules"
+	"kurut-bot/internal/stories/devices"
 )
 
 type Status string
@@ -13,6 +19,12 @@ const (
 	StatusActive   Status = "active"
 	StatusExpired  Status = "expired"
 	StatusDisabled Status = "disabled"
+	// StatusArchived - давно истекшая подписка, убранная из рабочих выборок
+	StatusArchived Status = "archived"
+	// StatusCancelled - подписка отменена ассистентом до истечения срока
+	StatusCancelled Status = "cancelled"
+	// StatusPaused - подписка заморожена: оставшиеся дни сохраняются и возвращаются при возобновлении
+	StatusPaused Status = "paused"
 )
 
 type Subscription struct {
@@ -28,11 +40,26 @@ type Subscription struct {
 	ActivatedAt         *time.Time
 	ExpiresAt           *time.Time
 	LastRenewedAt       *time.Time
-	RenewalCount        int // Number of times this subscription has been renewed
+	RenewalCount        int              // Number of times this subscription has been renewed
+	ExtraTrafficGB      int              // Докупленный трафик сверх лимита тарифа
+	CustomPrice         *float64         // Индивидуальная цена продления (например, старая цена); nil - цена тарифа
+	ClientPlatform      devices.Platform // Платформа устройства клиента для инструкций; пусто - не указана
+	Note                string           // Заметка ассистента ("платит 5-го"); пусто - нет заметки
+	PausedAt            *time.Time       // Начало паузы; nil - подписка не на паузе
+	PausedUntil         *time.Time       // Когда пауза закончится автоматически
 	CreatedAt           time.Time
 	UpdatedAt           time.Time
 }
 
+// RenewalPrice возвращает цену продления на тариф tariffID.
+// Индивидуальная цена действует только для текущего тарифа подписки
+func (s *Subscription) RenewalPrice(tariffID int64, tariffPrice float64) float64 {
+	if s.CustomPrice != nil && tariffID == s.TariffID {
+		return *s.CustomPrice
+	}
+	return tariffPrice
+}
+
 // Критерии для получения подписки
 type GetCriteria struct {
 	IDs     []int64
@@ -51,15 +78,61 @@ type ListCriteria struct {
 	TariffIDs           []int64
 	Status              []Status
 	CreatedByTelegramID *int64
+	ServerIDs           []int64
+	ExpiresAfter        *time.Time
+	ExpiresBefore       *time.Time
+	CreatedAfter        *time.Time // включительно
+	CreatedBefore       *time.Time // не включительно
 	Limit               int
 	Offset              int
 }
 
+// Критерии для постраничного списка подписок (курсор - ID последней подписки предыдущей страницы)
+type PageCriteria struct {
+	CreatedByTelegramID int64
+	Status              []Status
+	BeforeID            *int64
+	Limit               int
+}
+
+// Критерии для списка клиентов (подписки сгруппированы по WhatsApp).
+// Курсор - ID последней подписки клиента с предыдущей страницы
+type ClientPageCriteria struct {
+	CreatedByTelegramID  *int64 // nil - клиенты всех ассистентов
+	Status               []Status
+	ServerID             *int64
+	ExpiresAfter         *time.Time
+	ExpiresBefore        *time.Time
+	BeforeSubscriptionID *int64
+	Limit                int
+}
+
+// Критерии поиска подписок по части номера WhatsApp или generated_user_id
+type SearchCriteria struct {
+	Query               string // уже нормализованный запрос (см. NormalizeSearchQuery)
+	ID                  *int64 // точный ID подписки - для открытия карточки из результатов
+	CreatedByTelegramID *int64 // nil - по всем ассистентам
+	Limit               int
+}
+
+// NormalizeSearchQuery готовит запрос поиска: у номера телефона убирает "+", пробелы, дефисы и скобки,
+// чтобы "+996 555-12" находил "996555123456"; остальные запросы (generated_user_id) только обрезает
+func NormalizeSearchQuery(query string) string {
+	query = strings.TrimSpace(query)
+	phone := strings.NewReplacer("+", "", " ", "", "-", "", "(", "", ")", "").Replace(query)
+	if phone != "" && strings.Trim(phone, "0123456789") == "" {
+		return phone
+	}
+	return query
+}
+
 // Параметры для обновления подписки
 type UpdateParams struct {
-	Status      *Status
-	ActivatedAt *time.Time
-	ExpiresAt   *time.Time
+	Status         *Status
+	ActivatedAt    *time.Time
+	ExpiresAt      *time.Time
+	ClientWhatsApp *string
+	ServerID       *int64
 }
 
 // Запрос для создания подписки
@@ -69,7 +142,9 @@ type CreateSubscriptionRequest struct {
 	PaymentID              *int64
 	ClientWhatsApp         string
 	CreatedByTelegramID    int64
-	ReferrerSubscriptionID *int64 // ID of referrer's subscription to extend with bonus
+	ReferrerSubscriptionID *int64             // ID of referrer's subscription to extend with bonus
+	ServerID               *int64             // Конкретный сервер; если nil - выбирается доступный автоматически
+	Channel                bonusrules.Channel // Откуда создана подписка (для условий правил бонусов)
 }
 
 // Запрос для миграции существующего клиента (без увеличения счётчика сервера)
@@ -88,9 +163,12 @@ type CreateSubscriptionResult struct {
 	ServerUIURL          *string
 	ServerUIPassword     *string
 	ReferralBonusApplied bool       // true if referral bonus was applied
+	ReferralBonusDays    int        // days added to referrer's subscription by bonus rules
 	ReferrerWhatsApp     *string    // referrer's WhatsApp number
 	ReferrerNewExpiresAt *time.Time // referrer's new expiration date after bonus
 	ReferrerWeeklyCount  int        // how many people this referrer invited this week
+	ClientBonusDays      int        // days added to the new subscription by bonus rules
+	BonusNotifications   []string   // texts of matched "notify" bonus rules
 }
 
 // GenerateUserID создает уникальный идентификатор пользователя для VPN
@@ -120,3 +198,37 @@ func GenerateUserID(subscriptionID int64, assistantTelegramID int64, clientWhats
 
 	return fmt.Sprintf("%d_%s_%s", subscriptionID, tgSuffix, phoneSuffix)
 }
+
+// ErrGeneratedUserIDTaken - generated_user_id уже занят другой подпиской
+var ErrGeneratedUserIDTaken = errors.New("generated user id already taken")
+
+// referralPayloadPrefix - префикс параметра /start реферальной ссылки: t.me/<bot>?start=ref_<subID>
+const referralPayloadPrefix = "ref_"
+
+// ReferralStartPayload возвращает параметр /start реферальной ссылки для подписки пригласившего
+func ReferralStartPayload(subscriptionID int64) string {
+	return referralPayloadPrefix + strconv.FormatInt(subscriptionID, 10)
+}
+
+// ParseReferralStartPayload извлекает ID подписки пригласившего из параметра /start
+func ParseReferralStartPayload(payload string) (int64, bool) {
+	raw, ok := strings.CutPrefix(strings.TrimSpace(payload), referralPayloadPrefix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// PhoneChange - смена номера WhatsApp клиента в подписке; старый номер остается в истории
+type PhoneChange struct {
+	ID                  int64
+	SubscriptionID      int64
+	OldWhatsApp         string // пусто - номер не был указан
+	NewWhatsApp         string
+	ChangedByTelegramID int64
+	CreatedAt           time.Time
+}
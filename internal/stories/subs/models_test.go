@@ -0,0 +1,50 @@
+package subs
+
+import "testing"
+
+func TestNormalizeSearchQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"996555123456", "996555123456"},
+		{" +996 555-12 ", "99655512"},
+		{"(555) 12-34", "5551234"},
+		{"user_abc12", "user_abc12"},
+		{"  a1B2  ", "a1B2"},
+		{"+", "+"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeSearchQuery(tt.query); got != tt.want {
+			t.Errorf("NormalizeSearchQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestReferralStartPayload(t *testing.T) {
+	if got := ReferralStartPayload(42); got != "ref_42" {
+		t.Fatalf("ReferralStartPayload(42) = %q, want ref_42", got)
+	}
+
+	tests := []struct {
+		payload string
+		want    int64
+		ok      bool
+	}{
+		{"ref_42", 42, true},
+		{" ref_7 ", 7, true},
+		{"ref_", 0, false},
+		{"ref_0", 0, false},
+		{"ref_-3", 0, false},
+		{"ref_abc", 0, false},
+		{"42", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseReferralStartPayload(tt.payload)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseReferralStartPayload(%q) = %d, %v, want %d, %v", tt.payload, got, ok, tt.want, tt.ok)
+		}
+	}
+}
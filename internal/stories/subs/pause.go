@@ -0,0 +1,20 @@
+package subs
+
+import "time"
+
+// PauseDayOptions - на сколько дней ассистент может заморозить подписку
+var PauseDayOptions = []int{7, 14, 30}
+
+// CanPause - заморозить можно только активную подписку, срок которой еще не истек
+func (s *Subscription) CanPause(now time.Time) bool {
+	return s.Status == StatusActive && s.ExpiresAt != nil && s.ExpiresAt.After(now)
+}
+
+// ResumedExpiry возвращает новую дату окончания после паузы: оставшиеся на момент паузы дни
+// отсчитываются заново от момента возобновления
+func ResumedExpiry(expiresAt, pausedAt, resumedAt time.Time) time.Time {
+	if resumedAt.Before(pausedAt) {
+		return expiresAt
+	}
+	return expiresAt.Add(resumedAt.Sub(pausedAt))
+}
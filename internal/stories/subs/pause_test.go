@@ -0,0 +1,56 @@
+package subs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResumedExpiry(t *testing.T) {
+	pausedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		resumedAt time.Time
+		want      time.Time
+	}{
+		{name: "after full pause", resumedAt: pausedAt.AddDate(0, 0, 14), want: expiresAt.AddDate(0, 0, 14)},
+		{name: "resumed early", resumedAt: pausedAt.Add(36 * time.Hour), want: expiresAt.Add(36 * time.Hour)},
+		{name: "resumed immediately", resumedAt: pausedAt, want: expiresAt},
+		{name: "clock skew", resumedAt: pausedAt.Add(-time.Hour), want: expiresAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResumedExpiry(expiresAt, pausedAt, tt.resumedAt); !got.Equal(tt.want) {
+				t.Errorf("ResumedExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanPause(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	future := now.AddDate(0, 0, 5)
+	past := now.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name string
+		sub  Subscription
+		want bool
+	}{
+		{name: "active", sub: Subscription{Status: StatusActive, ExpiresAt: &future}, want: true},
+		{name: "already expired", sub: Subscription{Status: StatusActive, ExpiresAt: &past}},
+		{name: "no expiry", sub: Subscription{Status: StatusActive}},
+		{name: "paused", sub: Subscription{Status: StatusPaused, ExpiresAt: &future}},
+		{name: "disabled", sub: Subscription{Status: StatusDisabled, ExpiresAt: &future}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sub.CanPause(now); got != tt.want {
+				t.Errorf("CanPause() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,5 +10,6 @@ type (
 		UpdateTariff(ctx context.Context, criteria GetCriteria, params UpdateParams) (*Tariff, error)
 		ListTariffs(ctx context.Context, criteria ListCriteria) ([]*Tariff, error)
 		DeleteTariff(ctx context.Context, criteria DeleteCriteria) error
+		KeepSubscriptionsPrice(ctx context.Context, tariffID int64, price float64) (int64, error)
 	}
 )
@@ -2,15 +2,25 @@ package tariffs
 
 import "time"
 
+// DefaultReminderDays - расписание напоминаний для тарифов без своего: за 3 дня и в день истечения
+var DefaultReminderDays = []int{3, 0}
+
 type Tariff struct {
 	ID             int64
 	Name           string
 	DurationDays   int
 	Price          float64
 	TrafficLimitGB *int
-	IsActive     bool
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	IsActive       bool
+	ReminderDays   []int  // За сколько дней до истечения напоминать, пусто - DefaultReminderDays
+	Cluster        string // Подписки выдаются только на серверах этого кластера; пусто - на любом сервере
+	// Промо-период: тариф доступен для покупки только в окне [ValidFrom, ValidUntil); nil - без ограничения
+	ValidFrom  *time.Time
+	ValidUntil *time.Time
+	// FallbackTariffID - тариф, на который продлеваются подписки после окончания промо; nil - продлеваются на промо-тариф
+	FallbackTariffID *int64
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
 }
 
 // Критерии для получения тарифа
@@ -26,8 +36,8 @@ type DeleteCriteria struct {
 // Критерии для списка тарифов
 type ListCriteria struct {
 	IsActive *bool
-	Limit      int
-	Offset     int
+	Limit    int
+	Offset   int
 }
 
 // Параметры для обновления тарифа
@@ -36,5 +46,24 @@ type UpdateParams struct {
 	DurationDays   *int
 	Price          *float64
 	TrafficLimitGB *int
-	IsActive     *bool
+	IsActive       *bool
+	ReminderDays   []int
+	Cluster        *string // "" - снять привязку к кластеру
+	// ClearTrafficLimit снимает лимит трафика (TrafficLimitGB = NULL)
+	ClearTrafficLimit bool
+	ValidFrom         *time.Time
+	ValidUntil        *time.Time
+	// ClearPromoPeriod снимает промо-период (ValidFrom и ValidUntil = NULL)
+	ClearPromoPeriod bool
+	FallbackTariffID *int64
+	// ClearFallbackTariff снимает тариф для продлений после промо
+	ClearFallbackTariff bool
+}
+
+// ReminderSchedule возвращает дни напоминаний тарифа с учетом значения по умолчанию
+func (t *Tariff) ReminderSchedule() []int {
+	if len(t.ReminderDays) == 0 {
+		return DefaultReminderDays
+	}
+	return t.ReminderDays
 }
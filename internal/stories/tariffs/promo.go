@@ -0,0 +1,53 @@
+package tariffs
+
+import (
+	"context"
+	"time"
+)
+
+// Getter - источник тарифов для RenewalTariff
+type Getter interface {
+	GetTariff(ctx context.Context, criteria GetCriteria) (*Tariff, error)
+}
+
+// IsPromo - у тарифа задан промо-период
+func (t *Tariff) IsPromo() bool {
+	return t.ValidFrom != nil || t.ValidUntil != nil
+}
+
+// IsAvailableAt - тариф активен и now попадает в его промо-период
+func (t *Tariff) IsAvailableAt(now time.Time) bool {
+	return t.IsActive && !t.IsScheduled(now) && !t.PromoEnded(now)
+}
+
+// IsScheduled - промо-период тарифа еще не начался
+func (t *Tariff) IsScheduled(now time.Time) bool {
+	return t.ValidFrom != nil && now.Before(*t.ValidFrom)
+}
+
+// PromoEnded - промо-период тарифа закончился
+func (t *Tariff) PromoEnded(now time.Time) bool {
+	return t.ValidUntil != nil && !now.Before(*t.ValidUntil)
+}
+
+// RenewalTariff возвращает тариф, на который продлевается подписка с тарифом tariffID.
+// После окончания промо подписки переходят на FallbackTariffID, если он задан и доступен;
+// иначе продлеваются на прежний тариф. nil - тариф не найден
+func RenewalTariff(ctx context.Context, getter Getter, tariffID int64, now time.Time) (*Tariff, error) {
+	tariff, err := getter.GetTariff(ctx, GetCriteria{ID: &tariffID})
+	if err != nil || tariff == nil {
+		return tariff, err
+	}
+	if !tariff.PromoEnded(now) || tariff.FallbackTariffID == nil {
+		return tariff, nil
+	}
+
+	fallback, err := getter.GetTariff(ctx, GetCriteria{ID: tariff.FallbackTariffID})
+	if err != nil {
+		return nil, err
+	}
+	if fallback == nil || !fallback.IsAvailableAt(now) {
+		return tariff, nil
+	}
+	return fallback, nil
+}
@@ -0,0 +1,76 @@
+package tariffs
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTariffIsAvailableAt(t *testing.T) {
+	now := time.Date(2026, 12, 10, 12, 0, 0, 0, time.UTC)
+	past := now.AddDate(0, 0, -1)
+	future := now.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name   string
+		tariff Tariff
+		want   bool
+	}{
+		{name: "regular", tariff: Tariff{IsActive: true}, want: true},
+		{name: "archived", tariff: Tariff{}},
+		{name: "inside promo", tariff: Tariff{IsActive: true, ValidFrom: &past, ValidUntil: &future}, want: true},
+		{name: "starts exactly now", tariff: Tariff{IsActive: true, ValidFrom: &now}, want: true},
+		{name: "not started", tariff: Tariff{IsActive: true, ValidFrom: &future}},
+		{name: "ends exactly now", tariff: Tariff{IsActive: true, ValidUntil: &now}},
+		{name: "ended", tariff: Tariff{IsActive: true, ValidUntil: &past}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tariff.IsAvailableAt(now); got != tt.want {
+				t.Errorf("IsAvailableAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenewalTariff(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 12, 10, 12, 0, 0, 0, time.UTC)
+	past := now.AddDate(0, 0, -1)
+	future := now.AddDate(0, 0, 1)
+	regularID, archivedID := int64(1), int64(2)
+
+	store := &memoryStorage{tariffs: map[int64]*Tariff{
+		1:  {ID: 1, Price: 299, IsActive: true},
+		2:  {ID: 2, Price: 299},
+		10: {ID: 10, Price: 199, IsActive: true, ValidUntil: &past, FallbackTariffID: &regularID},
+		11: {ID: 11, Price: 199, IsActive: true, ValidUntil: &future, FallbackTariffID: &regularID},
+		12: {ID: 12, Price: 199, IsActive: true, ValidUntil: &past},
+		13: {ID: 13, Price: 199, IsActive: true, ValidUntil: &past, FallbackTariffID: &archivedID},
+	}}
+
+	tests := []struct {
+		name     string
+		tariffID int64
+		want     int64
+	}{
+		{name: "regular tariff", tariffID: 1, want: 1},
+		{name: "promo ended reverts", tariffID: 10, want: 1},
+		{name: "promo still running", tariffID: 11, want: 11},
+		{name: "no fallback", tariffID: 12, want: 12},
+		{name: "fallback archived", tariffID: 13, want: 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenewalTariff(ctx, store, tt.tariffID, now)
+			if err != nil {
+				t.Fatalf("RenewalTariff() unexpected error: %v", err)
+			}
+			if got == nil || got.ID != tt.want {
+				t.Errorf("RenewalTariff(%d) = %v, want tariff %d", tt.tariffID, got, tt.want)
+			}
+		})
+	}
+}
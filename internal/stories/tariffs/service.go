@@ -2,19 +2,27 @@ package tariffs
 
 import (
 	"context"
+	"sort"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/samber/lo"
 )
 
+// ErrTariffNotFound - тариф с указанным ID не найден
+var ErrTariffNotFound = errors.New("tariff not found")
+
 // Service provides business logic for tariff operations
 type Service struct {
 	storage Storage
+	now     func() time.Time
 }
 
 // NewService creates a new tariff service
 func NewService(storage Storage) *Service {
 	return &Service{
 		storage: storage,
+		now:     time.Now,
 	}
 }
 
@@ -63,9 +71,11 @@ func (s *Service) GetActiveTariffs(ctx context.Context) ([]*Tariff, error) {
 	}
 
 	// Фильтруем бесплатные тарифы (они только для пробного периода)
+	// и промо-тарифы вне их периода действия
+	now := s.now()
 	var paidTariffs []*Tariff
 	for _, t := range allTariffs {
-		if t.Price > 0 {
+		if t.Price > 0 && t.IsAvailableAt(now) {
 			paidTariffs = append(paidTariffs, t)
 		}
 	}
@@ -73,6 +83,44 @@ func (s *Service) GetActiveTariffs(ctx context.Context) ([]*Tariff, error) {
 	return paidTariffs, nil
 }
 
+// GetScheduledTariffs возвращает активные промо-тарифы вне периода действия: еще не начавшиеся
+// и уже закончившиеся. Отсортированы по началу промо
+func (s *Service) GetScheduledTariffs(ctx context.Context) ([]*Tariff, error) {
+	allTariffs, err := s.storage.ListTariffs(ctx, ListCriteria{
+		IsActive: lo.ToPtr(true),
+		Limit:    100,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	var scheduled []*Tariff
+	for _, t := range allTariffs {
+		if t.Price > 0 && !t.IsAvailableAt(now) {
+			scheduled = append(scheduled, t)
+		}
+	}
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		return promoStart(scheduled[i]).Before(promoStart(scheduled[j]))
+	})
+
+	return scheduled, nil
+}
+
+// promoStart - начало промо для сортировки; без начала тариф идет первым
+func promoStart(t *Tariff) time.Time {
+	if t.ValidFrom == nil {
+		return time.Time{}
+	}
+	return *t.ValidFrom
+}
+
+// GetRenewalTariff возвращает тариф для продления подписки на тарифе tariffID с учетом окончания промо
+func (s *Service) GetRenewalTariff(ctx context.Context, tariffID int64) (*Tariff, error) {
+	return RenewalTariff(ctx, s.storage, tariffID, s.now())
+}
+
 func (s *Service) GetInactiveTariffs(ctx context.Context) ([]*Tariff, error) {
 	criteria := ListCriteria{
 		IsActive: lo.ToPtr(false),
@@ -91,6 +139,40 @@ func (s *Service) UpdateTariffStatus(ctx context.Context, tariffID int64, isActi
 	return s.storage.UpdateTariff(ctx, criteria, params)
 }
 
+// UpdateTariff меняет название, длительность или лимит трафика тарифа
+func (s *Service) UpdateTariff(ctx context.Context, tariffID int64, params UpdateParams) (*Tariff, error) {
+	return s.storage.UpdateTariff(ctx, GetCriteria{ID: lo.ToPtr(tariffID)}, params)
+}
+
+// ChangePrice меняет цену тарифа. При onlyNewPurchases текущие подписки тарифа
+// сохраняют старую цену продления как индивидуальную. Возвращает число таких подписок
+func (s *Service) ChangePrice(ctx context.Context, tariffID int64, price float64, onlyNewPurchases bool) (*Tariff, int64, error) {
+	criteria := GetCriteria{ID: lo.ToPtr(tariffID)}
+
+	tariff, err := s.storage.GetTariff(ctx, criteria)
+	if err != nil {
+		return nil, 0, err
+	}
+	if tariff == nil {
+		return nil, 0, ErrTariffNotFound
+	}
+
+	var kept int64
+	if onlyNewPurchases && tariff.Price != price {
+		kept, err = s.storage.KeepSubscriptionsPrice(ctx, tariffID, tariff.Price)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	updated, err := s.storage.UpdateTariff(ctx, criteria, UpdateParams{Price: lo.ToPtr(price)})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return updated, kept, nil
+}
+
 // GetTrialTariff returns active trial tariff
 func (s *Service) GetTrialTariff(ctx context.Context) (*Tariff, error) {
 	return s.storage.GetTrialTariff(ctx)
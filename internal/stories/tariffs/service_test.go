@@ -0,0 +1,131 @@
+package tariffs
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type memoryStorage struct {
+	tariffs   map[int64]*Tariff
+	keptPrice *float64 // цена, закрепленная за подписками через KeepSubscriptionsPrice
+}
+
+func (m *memoryStorage) CreateTariff(_ context.Context, tariff Tariff) (*Tariff, error) {
+	m.tariffs[tariff.ID] = &tariff
+	return &tariff, nil
+}
+
+func (m *memoryStorage) GetTariff(_ context.Context, criteria GetCriteria) (*Tariff, error) {
+	return m.tariffs[*criteria.ID], nil
+}
+
+func (m *memoryStorage) GetTrialTariff(_ context.Context) (*Tariff, error) {
+	return nil, nil
+}
+
+func (m *memoryStorage) UpdateTariff(_ context.Context, criteria GetCriteria, params UpdateParams) (*Tariff, error) {
+	tariff := m.tariffs[*criteria.ID]
+	if params.Price != nil {
+		tariff.Price = *params.Price
+	}
+	return tariff, nil
+}
+
+func (m *memoryStorage) ListTariffs(_ context.Context, criteria ListCriteria) ([]*Tariff, error) {
+	var result []*Tariff
+	for _, t := range m.tariffs {
+		if criteria.IsActive == nil || t.IsActive == *criteria.IsActive {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryStorage) DeleteTariff(_ context.Context, _ DeleteCriteria) error {
+	return nil
+}
+
+func (m *memoryStorage) KeepSubscriptionsPrice(_ context.Context, _ int64, price float64) (int64, error) {
+	m.keptPrice = &price
+	return 3, nil
+}
+
+func TestChangePrice(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("only new purchases keeps old price", func(t *testing.T) {
+		store := &memoryStorage{tariffs: map[int64]*Tariff{1: {ID: 1, Price: 299}}}
+		tariff, kept, err := NewService(store).ChangePrice(ctx, 1, 349, true)
+		if err != nil {
+			t.Fatalf("ChangePrice() unexpected error: %v", err)
+		}
+		if tariff.Price != 349 || kept != 3 {
+			t.Errorf("ChangePrice() = price %v, kept %d, want 349, 3", tariff.Price, kept)
+		}
+		if store.keptPrice == nil || *store.keptPrice != 299 {
+			t.Errorf("KeepSubscriptionsPrice called with %v, want old price 299", store.keptPrice)
+		}
+	})
+
+	t.Run("all subscriptions", func(t *testing.T) {
+		store := &memoryStorage{tariffs: map[int64]*Tariff{1: {ID: 1, Price: 299}}}
+		tariff, kept, err := NewService(store).ChangePrice(ctx, 1, 349, false)
+		if err != nil {
+			t.Fatalf("ChangePrice() unexpected error: %v", err)
+		}
+		if tariff.Price != 349 || kept != 0 || store.keptPrice != nil {
+			t.Errorf("ChangePrice() = price %v, kept %d, kept price %v; want 349, 0, none", tariff.Price, kept, store.keptPrice)
+		}
+	})
+
+	t.Run("unknown tariff", func(t *testing.T) {
+		store := &memoryStorage{tariffs: map[int64]*Tariff{}}
+		if _, _, err := NewService(store).ChangePrice(ctx, 5, 349, true); err != ErrTariffNotFound {
+			t.Errorf("ChangePrice() error = %v, want ErrTariffNotFound", err)
+		}
+	})
+}
+
+func TestPromoTariffsVisibility(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 12, 10, 12, 0, 0, 0, time.UTC)
+	past := now.AddDate(0, 0, -10)
+	future := now.AddDate(0, 0, 10)
+	later := now.AddDate(0, 0, 20)
+
+	store := &memoryStorage{tariffs: map[int64]*Tariff{
+		1: {ID: 1, Price: 299, IsActive: true},
+		2: {ID: 2, Price: 199, IsActive: true, ValidFrom: &past, ValidUntil: &future},
+		3: {ID: 3, Price: 199, IsActive: true, ValidFrom: &future, ValidUntil: &later},
+		4: {ID: 4, Price: 149, IsActive: true, ValidUntil: &past},
+		5: {ID: 5, Price: 0, IsActive: true},
+		6: {ID: 6, Price: 99, IsActive: false},
+	}}
+	service := NewService(store)
+	service.now = func() time.Time { return now }
+
+	active, err := service.GetActiveTariffs(ctx)
+	if err != nil {
+		t.Fatalf("GetActiveTariffs() unexpected error: %v", err)
+	}
+	if got := tariffIDs(active); len(got) != 2 || !got[1] || !got[2] {
+		t.Errorf("GetActiveTariffs() = %v, want tariffs 1 and 2", got)
+	}
+
+	scheduled, err := service.GetScheduledTariffs(ctx)
+	if err != nil {
+		t.Fatalf("GetScheduledTariffs() unexpected error: %v", err)
+	}
+	if len(scheduled) != 2 || scheduled[0].ID != 4 || scheduled[1].ID != 3 {
+		t.Errorf("GetScheduledTariffs() = %v, want tariffs 4 and 3", tariffIDs(scheduled))
+	}
+}
+
+func tariffIDs(list []*Tariff) map[int64]bool {
+	ids := make(map[int64]bool, len(list))
+	for _, t := range list {
+		ids[t.ID] = true
+	}
+	return ids
+}
@@ -0,0 +1,35 @@
+package traffic
+
+import "time"
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusApplied   Status = "applied"
+	StatusCancelled Status = "cancelled"
+)
+
+// TopUpPackagesGB - размеры пакетов докупки трафика
+var TopUpPackagesGB = []int{10, 50, 100}
+
+// TopUp - докупка трафика к подписке
+type TopUp struct {
+	ID             int64
+	SubscriptionID int64
+	PaymentID      int64
+	TrafficGB      int
+	Amount         float64
+	Status         Status
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// TotalLimitGB возвращает лимит трафика подписки с учетом докупленного; nil - безлимит
+func TotalLimitGB(tariffLimitGB *int, extraGB int) *int {
+	if tariffLimitGB == nil {
+		return nil
+	}
+	total := *tariffLimitGB + extraGB
+	return &total
+}
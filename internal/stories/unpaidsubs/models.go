@@ -0,0 +1,10 @@
+package unpaidsubs
+
+type AlertStatus string
+
+const (
+	// AlertStatusAlerted - админы уведомлены, решение не принято
+	AlertStatusAlerted AlertStatus = "alerted"
+	// AlertStatusIgnored - админ проверил подписку и пометил как нормальную
+	AlertStatusIgnored AlertStatus = "ignored"
+)
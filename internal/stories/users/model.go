@@ -7,6 +7,7 @@ type User struct {
 	TelegramID int64
 	UsedTrial  bool
 	Language   string
+	Role       Role
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
@@ -0,0 +1,42 @@
+package users
+
+// Role - роль пользователя в боте
+type Role string
+
+const (
+	// RoleNone - роль не назначена: доступ определяется конфигом
+	RoleNone Role = ""
+	// RoleAdmin - полный доступ
+	RoleAdmin Role = "admin"
+	// RoleAssistant - создание подписок и работа со своими клиентами
+	RoleAssistant Role = "assistant"
+	// RoleViewer - только просмотр: /my_subs и общая статистика
+	RoleViewer Role = "viewer"
+)
+
+// Roles - роли, которые можно назначить через /set_role
+var Roles = []Role{RoleAdmin, RoleAssistant, RoleViewer}
+
+// ParseRole разбирает роль из аргумента команды или callback; "none" снимает роль
+func ParseRole(s string) (Role, bool) {
+	switch Role(s) {
+	case RoleAdmin, RoleAssistant, RoleViewer:
+		return Role(s), true
+	case "none":
+		return RoleNone, true
+	}
+	return RoleNone, false
+}
+
+// Title возвращает название роли для сообщений бота
+func (r Role) Title() string {
+	switch r {
+	case RoleAdmin:
+		return "👑 Админ"
+	case RoleAssistant:
+		return "🧑‍💼 Ассистент"
+	case RoleViewer:
+		return "👀 Наблюдатель"
+	}
+	return "Без роли"
+}
@@ -0,0 +1,23 @@
+package vacations
+
+import "time"
+
+// Vacation - отпуск ассистента, на время которого его уведомления уходят замене
+type Vacation struct {
+	AssistantTelegramID int64
+	BackupTelegramID    *int64    // nil - уведомления уходят админам
+	StartsAt            time.Time // начало первого дня отпуска
+	EndsAt              time.Time // начало дня выхода из отпуска (не включительно)
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// IsActive проверяет идет ли отпуск в момент now
+func (v *Vacation) IsActive(now time.Time) bool {
+	return !now.Before(v.StartsAt) && now.Before(v.EndsAt)
+}
+
+// LastDay возвращает последний день отпуска (для отображения)
+func (v *Vacation) LastDay() time.Time {
+	return v.EndsAt.AddDate(0, 0, -1)
+}
@@ -0,0 +1,63 @@
+package waitlist
+
+import "time"
+
+// PriceHoldDuration - сколько после уведомления держится цена тарифа, выбранная при записи в очередь
+const PriceHoldDuration = 48 * time.Hour
+
+type Status string
+
+const (
+	StatusWaiting   Status = "waiting"   // ждет свободного места
+	StatusNotified  Status = "notified"  // место освободилось, ассистент уведомлен
+	StatusFulfilled Status = "fulfilled" // по записи создан заказ
+	StatusCancelled Status = "cancelled"
+)
+
+// Entry - запись в очереди на покупку, когда все серверы заполнены
+type Entry struct {
+	ID                  int64
+	AdminUserID         int64
+	AssistantTelegramID int64
+	ChatID              int64
+	ClientWhatsApp      string
+	TariffID            int64
+	TariffName          string
+	HeldPrice           float64 // цена тарифа на момент записи
+	Status              Status
+	NotifiedAt          *time.Time
+	PriceHeldUntil      *time.Time
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// IsOpen возвращает true пока по записи не создан заказ и она не отменена
+func (e *Entry) IsOpen() bool {
+	return e.Status == StatusWaiting || e.Status == StatusNotified
+}
+
+// IsPriceHeld проверяет действует ли еще удержание цены в момент now
+func (e *Entry) IsPriceHeld(now time.Time) bool {
+	return e.Status == StatusNotified && e.PriceHeldUntil != nil && now.Before(*e.PriceHeldUntil)
+}
+
+// Price возвращает цену заказа по записи: удержанную, пока действует удержание, иначе текущую цену тарифа
+func (e *Entry) Price(currentPrice float64, now time.Time) float64 {
+	if e.IsPriceHeld(now) {
+		return e.HeldPrice
+	}
+	return currentPrice
+}
+
+// ListCriteria - критерии для списка записей очереди
+type ListCriteria struct {
+	Statuses       []Status
+	ClientWhatsApp *string
+	Limit          int
+}
+
+// FreeSlots считает сколько записей можно уведомить: свободные места минус места,
+// уже обещанные уведомленным записям с действующим удержанием цены
+func FreeSlots(capacity, active, held int) int {
+	return max(0, capacity-active-held)
+}
@@ -0,0 +1,53 @@
+package waitlist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntryPrice(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	until := now.Add(time.Hour)
+	expired := now.Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		entry Entry
+		want  float64
+	}{
+		{name: "waiting uses current price", entry: Entry{Status: StatusWaiting, HeldPrice: 300}, want: 350},
+		{name: "notified within hold", entry: Entry{Status: StatusNotified, HeldPrice: 300, PriceHeldUntil: &until}, want: 300},
+		{name: "hold expired", entry: Entry{Status: StatusNotified, HeldPrice: 300, PriceHeldUntil: &expired}, want: 350},
+		{name: "fulfilled", entry: Entry{Status: StatusFulfilled, HeldPrice: 300, PriceHeldUntil: &until}, want: 350},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.Price(350, now); got != tt.want {
+				t.Errorf("Price() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeSlots(t *testing.T) {
+	tests := []struct {
+		name                   string
+		capacity, active, held int
+		want                   int
+	}{
+		{name: "full", capacity: 100, active: 100, held: 0, want: 0},
+		{name: "one freed", capacity: 100, active: 99, held: 0, want: 1},
+		{name: "freed slot already promised", capacity: 100, active: 99, held: 1, want: 0},
+		{name: "new server", capacity: 150, active: 100, held: 2, want: 48},
+		{name: "over capacity", capacity: 100, active: 105, held: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FreeSlots(tt.capacity, tt.active, tt.held); got != tt.want {
+				t.Errorf("FreeSlots() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package watemplates
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"kurut-bot/internal/stories/clientlang"
+)
+
+// Name - редактируемый шаблон предзаполненного сообщения WhatsApp
+type Name string
+
+const (
+	NameExpiringToday    Name = "expiring_today"
+	NameExpiringTomorrow Name = "expiring_tomorrow"
+	NameOverdue          Name = "overdue"
+	NameActivated        Name = "activated"
+)
+
+// Names - шаблоны в порядке показа в /templates
+var Names = []Name{NameExpiringToday, NameExpiringTomorrow, NameOverdue, NameActivated}
+
+// Languages - языки, для которых можно задать текст; clientlang.Default - основной текст
+var Languages = []clientlang.Language{clientlang.Default, clientlang.Kyrgyz, clientlang.Russian, clientlang.Uzbek}
+
+// MaxTextLength - ссылка wa.me с длинным текстом перестает открываться в некоторых клиентах
+const MaxTextLength = 1000
+
+// Placeholders - подстановки, доступные в тексте шаблона
+var Placeholders = []string{"{client}", "{expires_at}", "{tariff}"}
+
+var placeholderRe = regexp.MustCompile(`\{[a-z_]*\}`)
+
+// Title - название шаблона для админа
+func (n Name) Title() string {
+	switch n {
+	case NameExpiringToday:
+		return "Истекает сегодня"
+	case NameExpiringTomorrow:
+		return "Истекает завтра"
+	case NameOverdue:
+		return "Просрочена"
+	case NameActivated:
+		return "Подписка активирована"
+	default:
+		return string(n)
+	}
+}
+
+// Valid - шаблон из списка редактируемых
+func (n Name) Valid() bool {
+	return slices.Contains(Names, n)
+}
+
+// Template - текст шаблона, сохраненный админом вместо встроенного
+type Template struct {
+	Name                Name
+	Language            clientlang.Language
+	Text                string
+	UpdatedByTelegramID int64
+	UpdatedAt           time.Time
+}
+
+// Vars - значения подстановок для конкретной подписки
+type Vars struct {
+	Client    string // номер WhatsApp клиента
+	ExpiresAt string // дата окончания, дд.мм.гггг
+	Tariff    string // название тарифа
+}
+
+// Validate проверяет текст, введенный админом: неизвестная подстановка скорее всего опечатка,
+// и клиент получил бы ее как есть
+func Validate(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", errors.New("текст пустой")
+	}
+	if utf8.RuneCountInString(text) > MaxTextLength {
+		return "", fmt.Errorf("текст длиннее %d символов", MaxTextLength)
+	}
+	for _, p := range placeholderRe.FindAllString(text, -1) {
+		if !slices.Contains(Placeholders, p) {
+			return "", fmt.Errorf("неизвестная подстановка %s, доступны: %s", p, strings.Join(Placeholders, ", "))
+		}
+	}
+	return text, nil
+}
+
+// Render подставляет значения в текст шаблона; незаполненные значения подставляются пустыми
+func Render(text string, vars Vars) string {
+	return strings.NewReplacer(
+		"{client}", vars.Client,
+		"{expires_at}", vars.ExpiresAt,
+		"{tariff}", vars.Tariff,
+	).Replace(text)
+}
+
+// NewVars собирает подстановки по данным подписки; дата окончания может быть еще неизвестна
+func NewVars(clientWhatsApp string, expiresAt *time.Time, tariff string) Vars {
+	vars := Vars{Client: clientWhatsApp, Tariff: tariff}
+	if expiresAt != nil {
+		vars.ExpiresAt = expiresAt.Format("02.01.2006")
+	}
+	return vars
+}
@@ -0,0 +1,41 @@
+package watemplates
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", text: "  Здравствуйте!  ", want: "Здравствуйте!"},
+		{name: "placeholders", text: "Тариф {tariff} до {expires_at}, {client}", want: "Тариф {tariff} до {expires_at}, {client}"},
+		{name: "empty", text: "   ", wantErr: true},
+		{name: "unknown placeholder", text: "Привет, {name}", wantErr: true},
+		{name: "too long", text: string(make([]rune, MaxTextLength+1)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Validate(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	got := Render("{client}: {tariff} до {expires_at}", Vars{Client: "996700", ExpiresAt: "01.11.2026", Tariff: "1 месяц"})
+	if want := "996700: 1 месяц до 01.11.2026"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	if got := Render("до {expires_at}", Vars{}); got != "до " {
+		t.Errorf("Render() with empty vars = %q", got)
+	}
+}
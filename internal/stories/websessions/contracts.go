@@ -0,0 +1,21 @@
+package websessions
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	Storage interface {
+		CreateWebSession(ctx context.Context, session Session) (*Session, error)
+		GetWebSession(ctx context.Context, tokenHash string) (*Session, error)
+		DeleteWebSession(ctx context.Context, tokenHash string) error
+		DeleteExpiredWebSessions(ctx context.Context, now time.Time) (int64, error)
+	}
+
+	// RoleChecker - проверка ролей бота (AdminChecker)
+	RoleChecker interface {
+		IsAdmin(telegramID int64) bool
+		IsAssistant(telegramID int64) bool
+	}
+)
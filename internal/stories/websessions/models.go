@@ -0,0 +1,32 @@
+package websessions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Role - роль пользователя веб-админки, та же что в боте
+type Role string
+
+const (
+	RoleAdmin     Role = "admin"
+	RoleAssistant Role = "assistant"
+)
+
+// Session - сессия веб-админки. Роль не хранится: она берется из настроек бота при каждой проверке,
+// чтобы удаление ассистента из ASSISTANT_IDS сразу закрывало ему доступ
+type Session struct {
+	ID         int64
+	TokenHash  string
+	TelegramID int64
+	Role       Role
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+}
+
+// HashToken возвращает хеш токена сессии для хранения в БД
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
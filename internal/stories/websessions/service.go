@@ -0,0 +1,119 @@
+package websessions
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// tokenBytes - 32 случайных байта токена сессии
+const tokenBytes = 32
+
+// ErrNoRole - пользователь не админ и не ассистент бота
+var ErrNoRole = errors.New("user has no role")
+
+type Service struct {
+	storage Storage
+	roles   RoleChecker
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// NewService создает сервис сессий веб-админки; ttl - время жизни сессии
+func NewService(storage Storage, roles RoleChecker, ttl time.Duration) *Service {
+	return &Service{
+		storage: storage,
+		roles:   roles,
+		ttl:     ttl,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// RoleOf возвращает роль пользователя в боте; пустая строка - доступа нет
+func (s *Service) RoleOf(telegramID int64) Role {
+	switch {
+	case s.roles.IsAdmin(telegramID):
+		return RoleAdmin
+	case s.roles.IsAssistant(telegramID):
+		return RoleAssistant
+	default:
+		return ""
+	}
+}
+
+// Login создает сессию для пользователя, уже подтвержденного через Telegram.
+// Возвращает сессию и токен для cookie; токен в БД не хранится
+func (s *Service) Login(ctx context.Context, telegramID int64) (*Session, string, error) {
+	role := s.RoleOf(telegramID)
+	if role == "" {
+		return nil, "", ErrNoRole
+	}
+
+	now := s.now()
+	// Заодно чистим истекшие сессии: входов мало, отдельный воркер не нужен
+	if _, err := s.storage.DeleteExpiredWebSessions(ctx, now); err != nil {
+		return nil, "", fmt.Errorf("delete expired sessions: %w", err)
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("generate token: %w", err)
+	}
+
+	session, err := s.storage.CreateWebSession(ctx, Session{
+		TokenHash:  HashToken(token),
+		TelegramID: telegramID,
+		ExpiresAt:  now.Add(s.ttl),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("create session: %w", err)
+	}
+	session.Role = role
+
+	return session, token, nil
+}
+
+// Authenticate возвращает сессию по токену из cookie. nil - сессии нет, она истекла
+// или у пользователя больше нет роли в боте
+func (s *Service) Authenticate(ctx context.Context, token string) (*Session, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	session, err := s.storage.GetWebSession(ctx, HashToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+	if session == nil || !s.now().Before(session.ExpiresAt) {
+		return nil, nil
+	}
+
+	session.Role = s.RoleOf(session.TelegramID)
+	if session.Role == "" {
+		return nil, nil
+	}
+
+	return session, nil
+}
+
+// Logout удаляет сессию
+func (s *Service) Logout(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	if err := s.storage.DeleteWebSession(ctx, HashToken(token)); err != nil {
+		return fmt.Errorf("delete session: %w", err)
+	}
+	return nil
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,129 @@
+package websessions
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+type memoryStorage struct {
+	sessions map[string]*Session
+}
+
+func (m *memoryStorage) CreateWebSession(_ context.Context, session Session) (*Session, error) {
+	session.ID = int64(len(m.sessions) + 1)
+	m.sessions[session.TokenHash] = &session
+	copied := session
+	return &copied, nil
+}
+
+func (m *memoryStorage) GetWebSession(_ context.Context, tokenHash string) (*Session, error) {
+	session, ok := m.sessions[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (m *memoryStorage) DeleteWebSession(_ context.Context, tokenHash string) error {
+	delete(m.sessions, tokenHash)
+	return nil
+}
+
+func (m *memoryStorage) DeleteExpiredWebSessions(_ context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	for hash, session := range m.sessions {
+		if !now.Before(session.ExpiresAt) {
+			delete(m.sessions, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+type staticRoles struct {
+	admins, assistants []int64
+}
+
+func (r *staticRoles) IsAdmin(telegramID int64) bool { return slices.Contains(r.admins, telegramID) }
+func (r *staticRoles) IsAssistant(telegramID int64) bool {
+	return slices.Contains(r.assistants, telegramID)
+}
+
+func TestLoginAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStorage{sessions: map[string]*Session{}}
+	roles := &staticRoles{admins: []int64{1}, assistants: []int64{2}}
+	service := NewService(store, roles, time.Hour)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	if _, _, err := service.Login(ctx, 3); err != ErrNoRole {
+		t.Fatalf("Login(stranger) error = %v, want ErrNoRole", err)
+	}
+
+	session, token, err := service.Login(ctx, 2)
+	if err != nil {
+		t.Fatalf("Login() unexpected error: %v", err)
+	}
+	if session.Role != RoleAssistant || !session.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("Login() = %+v, want assistant session for an hour", session)
+	}
+	if _, stored := store.sessions[token]; stored {
+		t.Error("raw token is stored, want only its hash")
+	}
+
+	got, err := service.Authenticate(ctx, token)
+	if err != nil || got == nil || got.TelegramID != 2 || got.Role != RoleAssistant {
+		t.Fatalf("Authenticate() = %+v, %v, want assistant 2", got, err)
+	}
+
+	// Ассистента повысили до админа - роль меняется без повторного входа
+	roles.admins = append(roles.admins, 2)
+	if got, _ := service.Authenticate(ctx, token); got == nil || got.Role != RoleAdmin {
+		t.Errorf("Authenticate() after promotion = %+v, want admin", got)
+	}
+
+	// Ассистента убрали из настроек - сессия больше не действует
+	roles.admins, roles.assistants = []int64{1}, nil
+	if got, _ := service.Authenticate(ctx, token); got != nil {
+		t.Errorf("Authenticate() without role = %+v, want nil", got)
+	}
+
+	if got, _ := service.Authenticate(ctx, "unknown"); got != nil {
+		t.Errorf("Authenticate(unknown) = %+v, want nil", got)
+	}
+}
+
+func TestSessionExpiryAndLogout(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStorage{sessions: map[string]*Session{}}
+	service := NewService(store, &staticRoles{admins: []int64{1}}, time.Hour)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	_, expiring, _ := service.Login(ctx, 1)
+	_, active, _ := service.Login(ctx, 1)
+
+	if err := service.Logout(ctx, active); err != nil {
+		t.Fatalf("Logout() unexpected error: %v", err)
+	}
+	if got, _ := service.Authenticate(ctx, active); got != nil {
+		t.Errorf("Authenticate() after logout = %+v, want nil", got)
+	}
+
+	now = now.Add(time.Hour)
+	if got, _ := service.Authenticate(ctx, expiring); got != nil {
+		t.Errorf("Authenticate() after expiry = %+v, want nil", got)
+	}
+
+	// Следующий вход удаляет истекшие сессии
+	if _, _, err := service.Login(ctx, 1); err != nil {
+		t.Fatalf("Login() unexpected error: %v", err)
+	}
+	if len(store.sessions) != 1 {
+		t.Errorf("sessions after cleanup = %d, want 1", len(store.sessions))
+	}
+}
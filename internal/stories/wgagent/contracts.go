@@ -0,0 +1,16 @@
+package wgagent
+
+import (
+	"context"
+
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/subs"
+)
+
+type (
+	Storage interface {
+		SetServerAgentTokenHash(ctx context.Context, serverID int64, tokenHash string) error
+		GetServerByAgentTokenHash(ctx context.Context, tokenHash string) (*servers.Server, error)
+		ListServerPeers(ctx context.Context, serverID int64) ([]*subs.Subscription, error)
+	}
+)
@@ -0,0 +1,34 @@
+package wgagent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"kurut-bot/internal/stories/subs"
+)
+
+// Peer - состояние, в котором агент должен держать пир WireGuard на сервере
+type Peer struct {
+	PeerID         string // generated_user_id подписки - имя клиента в панели
+	SubscriptionID int64
+	Enabled        bool
+	// ExpiresAt - когда агент отключает пир сам, если бот недоступен; nil - без срока
+	ExpiresAt *time.Time
+}
+
+// PeerFor возвращает состояние пира подписки на момент now: включен только у активной неистекшей подписки
+func PeerFor(sub *subs.Subscription, now time.Time) Peer {
+	peer := Peer{SubscriptionID: sub.ID, ExpiresAt: sub.ExpiresAt}
+	if sub.GeneratedUserID != nil {
+		peer.PeerID = *sub.GeneratedUserID
+	}
+	peer.Enabled = sub.Status == subs.StatusActive && (sub.ExpiresAt == nil || now.Before(*sub.ExpiresAt))
+	return peer
+}
+
+// HashToken возвращает SHA-256 хеш токена агента в hex: в БД хранится только он
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,65 @@
+package wgagent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/stories/servers"
+)
+
+// tokenBytes - 32 случайных байта токена агента
+const tokenBytes = 32
+
+// Service выдает токены агентам WireGuard и отдает им состояние пиров их сервера
+type Service struct {
+	storage Storage
+	now     func() time.Time
+}
+
+func NewService(storage Storage) *Service {
+	return &Service{
+		storage: storage,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// IssueToken выпускает новый токен агента сервера. Токен возвращается один раз, в БД хранится его хеш;
+// предыдущий токен сервера перестает действовать
+func (s *Service) IssueToken(ctx context.Context, serverID int64) (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	if err := s.storage.SetServerAgentTokenHash(ctx, serverID, HashToken(token)); err != nil {
+		return "", fmt.Errorf("save token: %w", err)
+	}
+	return token, nil
+}
+
+// Authenticate возвращает сервер агента по токену; nil - токен неизвестен
+func (s *Service) Authenticate(ctx context.Context, token string) (*servers.Server, error) {
+	if token == "" {
+		return nil, nil
+	}
+	return s.storage.GetServerByAgentTokenHash(ctx, HashToken(token))
+}
+
+// Peers возвращает состояние пиров сервера на текущий момент. Пиров, которых нет в списке, агент не трогает
+func (s *Service) Peers(ctx context.Context, serverID int64) ([]Peer, time.Time, error) {
+	subscriptions, err := s.storage.ListServerPeers(ctx, serverID)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("list server peers: %w", err)
+	}
+
+	now := s.now()
+	peers := make([]Peer, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		peers = append(peers, PeerFor(sub, now))
+	}
+	return peers, now, nil
+}
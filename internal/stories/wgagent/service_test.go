@@ -0,0 +1,103 @@
+package wgagent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/subs"
+)
+
+type memoryStorage struct {
+	tokenHashes map[int64]string
+	peers       map[int64][]*subs.Subscription
+}
+
+func (m *memoryStorage) SetServerAgentTokenHash(_ context.Context, serverID int64, tokenHash string) error {
+	m.tokenHashes[serverID] = tokenHash
+	return nil
+}
+
+func (m *memoryStorage) GetServerByAgentTokenHash(_ context.Context, tokenHash string) (*servers.Server, error) {
+	for serverID, hash := range m.tokenHashes {
+		if hash == tokenHash {
+			return &servers.Server{ID: serverID}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryStorage) ListServerPeers(_ context.Context, serverID int64) ([]*subs.Subscription, error) {
+	return m.peers[serverID], nil
+}
+
+func TestIssueTokenAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStorage{tokenHashes: map[int64]string{}}
+	service := NewService(store)
+
+	first, err := service.IssueToken(ctx, 7)
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+	if store.tokenHashes[7] == first {
+		t.Fatal("IssueToken() must store only the token hash")
+	}
+
+	server, err := service.Authenticate(ctx, first)
+	if err != nil || server == nil || server.ID != 7 {
+		t.Fatalf("Authenticate(first) = %v, %v, want server 7", server, err)
+	}
+
+	second, err := service.IssueToken(ctx, 7)
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+	if server, _ := service.Authenticate(ctx, first); server != nil {
+		t.Error("Authenticate() must reject a rotated token")
+	}
+	if server, _ := service.Authenticate(ctx, second); server == nil {
+		t.Error("Authenticate() must accept the new token")
+	}
+	if server, _ := service.Authenticate(ctx, ""); server != nil {
+		t.Error("Authenticate() must reject an empty token")
+	}
+}
+
+func TestPeers(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	future := now.Add(48 * time.Hour)
+	past := now.Add(-time.Hour)
+	peerID := func(id string) *string { return &id }
+
+	store := &memoryStorage{peers: map[int64][]*subs.Subscription{1: {
+		{ID: 1, Status: subs.StatusActive, GeneratedUserID: peerID("active"), ExpiresAt: &future},
+		{ID: 2, Status: subs.StatusActive, GeneratedUserID: peerID("overdue"), ExpiresAt: &past},
+		{ID: 3, Status: subs.StatusPaused, GeneratedUserID: peerID("paused"), ExpiresAt: &future},
+		{ID: 4, Status: subs.StatusDisabled, GeneratedUserID: peerID("disabled"), ExpiresAt: &past},
+	}}}
+	service := NewService(store)
+	service.now = func() time.Time { return now }
+
+	peers, generatedAt, err := service.Peers(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Peers() error = %v", err)
+	}
+	if !generatedAt.Equal(now) {
+		t.Errorf("Peers() generatedAt = %v, want %v", generatedAt, now)
+	}
+
+	want := map[string]bool{"active": true, "overdue": false, "paused": false, "disabled": false}
+	if len(peers) != len(want) {
+		t.Fatalf("Peers() returned %d peers, want %d", len(peers), len(want))
+	}
+	for _, peer := range peers {
+		if peer.Enabled != want[peer.PeerID] {
+			t.Errorf("peer %s enabled = %v, want %v", peer.PeerID, peer.Enabled, want[peer.PeerID])
+		}
+	}
+	if peers[0].ExpiresAt == nil || !peers[0].ExpiresAt.Equal(future) {
+		t.Errorf("peer expires_at = %v, want %v", peers[0].ExpiresAt, future)
+	}
+}
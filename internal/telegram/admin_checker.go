@@ -1,35 +1,153 @@
 package telegram
 
 import (
-	"kurut-bot/internal/config"
+	"context"
+	"log/slog"
 	"slices"
+	"sync"
+	"time"
+
+	"kurut-bot/internal/config"
+	"kurut-bot/internal/stories/users"
 )
 
-// AdminChecker проверяет является ли пользователь админом или ассистентом
+// roleCacheTTL - как долго используется закэшированный список ролей из БД.
+// Веб-админка держит свой AdminChecker, поэтому изменения из бота доходят до нее не позже этого срока
+const roleCacheTTL = time.Minute
+
+// RoleStorage - роли, назначенные через бота
+type RoleStorage interface {
+	ListUserRoles(ctx context.Context) (map[int64]users.Role, error)
+}
+
+// AdminChecker определяет роль пользователя.
+// Супер-админы задаются в конфиге и всегда админы. Для остальных роль из БД важнее конфига:
+// ассистента из TELEGRAM_ASSISTANT_IDS можно понизить до наблюдателя через /set_role
 type AdminChecker struct {
-	adminIDs     []int64
-	assistantIDs []int64
+	superAdminIDs []int64
+	assistantIDs  []int64
+	adminGroupID  int64
+	storage       RoleStorage
+	logger        *slog.Logger
+
+	mu       sync.Mutex
+	roles    map[int64]users.Role
+	loadedAt time.Time
 }
 
 // NewAdminChecker создает новый проверялка админов
-func NewAdminChecker(cfg *config.TelegramConfig) *AdminChecker {
+func NewAdminChecker(cfg *config.TelegramConfig, storage RoleStorage, logger *slog.Logger) *AdminChecker {
 	return &AdminChecker{
-		adminIDs:     cfg.AdminIDs,
-		assistantIDs: cfg.AssistantIDs,
+		superAdminIDs: cfg.AdminIDs,
+		assistantIDs:  cfg.AssistantIDs,
+		adminGroupID:  cfg.AdminGroupID,
+		storage:       storage,
+		logger:        logger,
 	}
 }
 
+// Role возвращает роль пользователя; RoleNone - доступа к боту нет
+func (a *AdminChecker) Role(telegramID int64) users.Role {
+	if a.IsSuperAdmin(telegramID) {
+		return users.RoleAdmin
+	}
+	if role, ok := a.storedRoles()[telegramID]; ok {
+		return role
+	}
+	if slices.Contains(a.assistantIDs, telegramID) {
+		return users.RoleAssistant
+	}
+	return users.RoleNone
+}
+
+// IsSuperAdmin проверяет является ли пользователь админом из конфига: только они назначают других админов
+func (a *AdminChecker) IsSuperAdmin(telegramID int64) bool {
+	return slices.Contains(a.superAdminIDs, telegramID)
+}
+
 // IsAdmin проверяет является ли пользователь с данным Telegram ID админом
 func (a *AdminChecker) IsAdmin(telegramID int64) bool {
-	return slices.Contains(a.adminIDs, telegramID)
+	return a.Role(telegramID) == users.RoleAdmin
 }
 
 // IsAssistant проверяет является ли пользователь ассистентом
 func (a *AdminChecker) IsAssistant(telegramID int64) bool {
-	return slices.Contains(a.assistantIDs, telegramID)
+	return a.Role(telegramID) == users.RoleAssistant
 }
 
-// IsAllowedUser проверяет имеет ли пользователь доступ к боту (админ или ассистент)
+// IsViewer проверяет является ли пользователь наблюдателем (только просмотр)
+func (a *AdminChecker) IsViewer(telegramID int64) bool {
+	return a.Role(telegramID) == users.RoleViewer
+}
+
+// IsAllowedUser проверяет имеет ли пользователь доступ к боту (любая роль)
 func (a *AdminChecker) IsAllowedUser(telegramID int64) bool {
-	return a.IsAdmin(telegramID) || a.IsAssistant(telegramID)
+	return a.Role(telegramID) != users.RoleNone
+}
+
+// SuperAdminIDs возвращает админов из конфига
+func (a *AdminChecker) SuperAdminIDs() []int64 {
+	return slices.Clone(a.superAdminIDs)
+}
+
+// AdminIDs возвращает текущих админов: супер-админов и назначенных через /set_role
+func (a *AdminChecker) AdminIDs() []int64 {
+	return a.idsWithRole(users.RoleAdmin)
+}
+
+// StaffIDs возвращает текущих админов и ассистентов - тех, кто создает подписки
+func (a *AdminChecker) StaffIDs() []int64 {
+	return a.idsWithRole(users.RoleAdmin, users.RoleAssistant)
+}
+
+// AdminChatIDs возвращает куда слать служебные уведомления: в админскую группу, если она задана, иначе каждому админу
+func (a *AdminChecker) AdminChatIDs() []int64 {
+	if a.adminGroupID != 0 {
+		return []int64{a.adminGroupID}
+	}
+	return a.AdminIDs()
+}
+
+// idsWithRole возвращает отсортированные Telegram ID пользователей, чья текущая роль входит в roles
+func (a *AdminChecker) idsWithRole(roles ...users.Role) []int64 {
+	candidates := slices.Concat(a.superAdminIDs, a.assistantIDs)
+	for id := range a.storedRoles() {
+		candidates = append(candidates, id)
+	}
+	slices.Sort(candidates)
+
+	var ids []int64
+	for _, id := range slices.Compact(candidates) {
+		if slices.Contains(roles, a.Role(id)) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Invalidate сбрасывает кэш ролей из БД: следующая проверка перечитает список
+func (a *AdminChecker) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.loadedAt = time.Time{}
+}
+
+// storedRoles возвращает роли из БД, перечитывая их после roleCacheTTL.
+// При ошибке БД остается прежний список: супер-админы работают в любом случае
+func (a *AdminChecker) storedRoles() map[int64]users.Role {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.loadedAt.IsZero() && time.Since(a.loadedAt) < roleCacheTTL {
+		return a.roles
+	}
+
+	roles, err := a.storage.ListUserRoles(context.Background())
+	if err != nil {
+		a.logger.Error("Failed to load user roles", "error", err)
+		return a.roles
+	}
+	a.roles = roles
+	a.loadedAt = time.Now()
+	return a.roles
 }
@@ -0,0 +1,88 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"kurut-bot/internal/config"
+	"kurut-bot/internal/stories/users"
+)
+
+type fakeRoleStorage struct {
+	roles map[int64]users.Role
+	err   error
+	calls int
+}
+
+func (f *fakeRoleStorage) ListUserRoles(context.Context) (map[int64]users.Role, error) {
+	f.calls++
+	return f.roles, f.err
+}
+
+func TestAdminCheckerStoredRoles(t *testing.T) {
+	storage := &fakeRoleStorage{roles: map[int64]users.Role{
+		10: users.RoleViewer,
+		20: users.RoleAdmin,
+		31: users.RoleViewer,
+		50: users.RoleViewer,
+	}}
+	checker := NewAdminChecker(&config.TelegramConfig{AdminIDs: []int64{10}, AssistantIDs: []int64{30, 31}}, storage, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if !checker.IsSuperAdmin(10) || checker.IsSuperAdmin(20) {
+		t.Error("IsSuperAdmin() must be true only for config admins")
+	}
+	if !checker.IsAdmin(10) || !checker.IsAdmin(20) || checker.IsAdmin(30) {
+		t.Error("IsAdmin() must be true for config and stored admins")
+	}
+	// Роль из БД важнее конфига ассистентов, но не супер-админов
+	if got := checker.Role(31); got != users.RoleViewer {
+		t.Errorf("Role(31) = %q, want viewer", got)
+	}
+	if got := checker.Role(30); got != users.RoleAssistant {
+		t.Errorf("Role(30) = %q, want assistant", got)
+	}
+	if !checker.IsViewer(50) || checker.IsAssistant(50) {
+		t.Error("IsViewer() must be true for stored viewers")
+	}
+	if !checker.IsAllowedUser(20) || !checker.IsAllowedUser(30) || !checker.IsAllowedUser(50) || checker.IsAllowedUser(40) {
+		t.Error("IsAllowedUser() must be true for any role")
+	}
+
+	if got := checker.AdminIDs(); !slices.Equal(got, []int64{10, 20}) {
+		t.Errorf("AdminIDs() = %v, want [10 20]", got)
+	}
+	if got := checker.StaffIDs(); !slices.Equal(got, []int64{10, 20, 30}) {
+		t.Errorf("StaffIDs() = %v, want [10 20 30]", got)
+	}
+	if got := checker.AdminChatIDs(); !slices.Equal(got, []int64{10, 20}) {
+		t.Errorf("AdminChatIDs() = %v, want admins without a group", got)
+	}
+
+	// Пока кэш свежий, БД не перечитывается
+	storage.roles = nil
+	if !checker.IsAdmin(20) {
+		t.Error("IsAdmin() must use cached roles")
+	}
+	if storage.calls != 1 {
+		t.Errorf("ListUserRoles() calls = %d, want 1", storage.calls)
+	}
+
+	checker.Invalidate()
+	if checker.IsAdmin(20) {
+		t.Error("IsAdmin() must reload roles after Invalidate()")
+	}
+
+	// Ошибка БД не отнимает права у супер-админа и не сбрасывает загруженный список
+	storage.roles = map[int64]users.Role{20: users.RoleAdmin}
+	checker.Invalidate()
+	checker.IsAdmin(20)
+	storage.err = errors.New("db is locked")
+	checker.Invalidate()
+	if !checker.IsAdmin(10) || !checker.IsAdmin(20) {
+		t.Error("IsAdmin() must keep previous roles on storage error")
+	}
+}
@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/wgagent"
+)
+
+// AgentService - состояние пиров для агентов WireGuard на серверах
+type AgentService interface {
+	Authenticate(ctx context.Context, token string) (*servers.Server, error)
+	Peers(ctx context.Context, serverID int64) ([]wgagent.Peer, time.Time, error)
+}
+
+type agentPeer struct {
+	PeerID         string     `json:"peer_id"`
+	SubscriptionID int64      `json:"subscription_id"`
+	Enabled        bool       `json:"enabled"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+}
+
+type agentPeersResponse struct {
+	ServerID    int64       `json:"server_id"`
+	GeneratedAt time.Time   `json:"generated_at"`
+	Peers       []agentPeer `json:"peers"`
+}
+
+// AgentPeersHandler обрабатывает GET /api/v1/agent/peers - пиры сервера, которые агент включает или отключает.
+// Авторизация: заголовок "Authorization: Bearer <токен агента>", токен выдается в /servers.
+// Агент отключает пир и сам по expires_at, поэтому сроки соблюдаются, даже если бот недоступен
+func AgentPeersHandler(service AgentService, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			writeJSONError(w, http.StatusUnauthorized, "missing agent token")
+			return
+		}
+
+		server, err := service.Authenticate(r.Context(), token)
+		if err != nil {
+			logger.Error("Failed to authenticate agent", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if server == nil {
+			writeJSONError(w, http.StatusUnauthorized, "invalid agent token")
+			return
+		}
+
+		peers, generatedAt, err := service.Peers(r.Context(), server.ID)
+		if err != nil {
+			logger.Error("Failed to list server peers", "error", err, "server_id", server.ID)
+			writeJSONError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		resp := agentPeersResponse{
+			ServerID:    server.ID,
+			GeneratedAt: generatedAt,
+			Peers:       make([]agentPeer, 0, len(peers)),
+		}
+		for _, peer := range peers {
+			resp.Peers = append(resp.Peers, agentPeer{
+				PeerID:         peer.PeerID,
+				SubscriptionID: peer.SubscriptionID,
+				Enabled:        peer.Enabled,
+				ExpiresAt:      peer.ExpiresAt,
+			})
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
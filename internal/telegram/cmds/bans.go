@@ -0,0 +1,234 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/bans"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxBanReasonLength - причина бана показывается в списке, длинные обрезаем
+const maxBanReasonLength = 200
+
+const banUsage = "🚫 *Бан пользователя*\n\n" +
+	"`/ban 123456789` — заблокировать пользователя по Telegram ID\n" +
+	"`/ban 123456789 спам` — с причиной\n" +
+	"/bans — список блокировок"
+
+// BansCommand управляет блокировками: /ban, /bans и автоматический бан при злоупотреблениях
+type BansCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage BanStorage
+	admins  banAdmins
+	logger  *slog.Logger
+}
+
+type BanStorage interface {
+	SaveBan(ctx context.Context, ban bans.Ban) (*bans.Ban, error)
+	GetBan(ctx context.Context, telegramID int64) (*bans.Ban, error)
+	ListBans(ctx context.Context) ([]*bans.Ban, error)
+	DeleteBan(ctx context.Context, telegramID int64) (bool, error)
+}
+
+// banAdmins - текущие админы: их забанить нельзя, в их чаты уходят уведомления об автобане
+type banAdmins interface {
+	IsAdmin(telegramID int64) bool
+	AdminChatIDs() []int64
+}
+
+// NewBansCommand создает команду; админов забанить нельзя, им же уходят уведомления об автобане
+func NewBansCommand(bot *tgbotapi.BotAPI, storage BanStorage, admins banAdmins, logger *slog.Logger) *BansCommand {
+	return &BansCommand{
+		bot:     bot,
+		storage: storage,
+		admins:  admins,
+		logger:  logger,
+	}
+}
+
+// ParseBanArgs разбирает "<Telegram ID> [причина]"
+func ParseBanArgs(args string) (int64, string, error) {
+	idPart, reason, _ := strings.Cut(strings.TrimSpace(args), " ")
+	telegramID, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil || telegramID <= 0 {
+		return 0, "", errors.New("неверный Telegram ID")
+	}
+
+	reason = strings.TrimSpace(reason)
+	if runes := []rune(reason); len(runes) > maxBanReasonLength {
+		reason = string(runes[:maxBanReasonLength])
+	}
+	return telegramID, reason, nil
+}
+
+// Ban блокирует пользователя по команде админа
+func (c *BansCommand) Ban(ctx context.Context, adminTelegramID, chatID int64, args string) error {
+	if strings.TrimSpace(args) == "" {
+		return c.send(chatID, banUsage)
+	}
+
+	telegramID, reason, err := ParseBanArgs(args)
+	if err != nil {
+		return c.send(chatID, fmt.Sprintf("❌ %s\n\n%s", err, banUsage))
+	}
+	if c.admins.IsAdmin(telegramID) {
+		return c.send(chatID, "❌ Админа заблокировать нельзя")
+	}
+
+	if _, err := c.storage.SaveBan(ctx, bans.Ban{
+		TelegramID: telegramID,
+		Reason:     reason,
+		BannedBy:   &adminTelegramID,
+	}); err != nil {
+		c.logger.Error("Failed to save ban", "error", err, "telegram_id", telegramID)
+		return c.send(chatID, "❌ Ошибка сохранения бана")
+	}
+
+	c.logger.Info("User banned",
+		"audit", true,
+		"admin_telegram_id", adminTelegramID,
+		"telegram_id", telegramID,
+		"reason", reason,
+	)
+
+	return c.send(chatID, fmt.Sprintf("🚫 Пользователь `%d` заблокирован", telegramID))
+}
+
+// AutoBan блокирует пользователя при злоупотреблении и уведомляет админов. Админов не трогает
+func (c *BansCommand) AutoBan(ctx context.Context, telegramID int64, reason string) {
+	if c.admins.IsAdmin(telegramID) {
+		return
+	}
+
+	if _, err := c.storage.SaveBan(ctx, bans.Ban{TelegramID: telegramID, Reason: reason}); err != nil {
+		c.logger.Error("Failed to save auto ban", "error", err, "telegram_id", telegramID)
+		return
+	}
+
+	c.logger.Warn("User banned automatically",
+		"audit", true,
+		"telegram_id", telegramID,
+		"reason", reason,
+	)
+
+	text := fmt.Sprintf("🚫 *Автобан*\n\nПользователь `%d` заблокирован: %s\n\nСнять блокировку: /bans", telegramID, reason)
+	for _, adminChatID := range c.admins.AdminChatIDs() {
+		if err := c.send(adminChatID, text); err != nil {
+			c.logger.Error("Failed to notify admin about auto ban", "error", err, "chat_id", adminChatID)
+		}
+	}
+}
+
+// IsBanned проверяет блокировку. При ошибке БД пропускаем пользователя, чтобы не закрыть бот всем
+func (c *BansCommand) IsBanned(ctx context.Context, telegramID int64) bool {
+	ban, err := c.storage.GetBan(ctx, telegramID)
+	if err != nil {
+		c.logger.Error("Failed to get ban", "error", err, "telegram_id", telegramID)
+		return false
+	}
+	return ban != nil
+}
+
+// Execute показывает список блокировок
+func (c *BansCommand) Execute(ctx context.Context, chatID int64) error {
+	return c.showList(ctx, chatID, 0)
+}
+
+// HandleCallback обрабатывает снятие бана (ban_rm:<telegram_id>)
+func (c *BansCommand) HandleCallback(ctx context.Context, adminTelegramID int64, query *tgbotapi.CallbackQuery) error {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+
+	telegramID, err := strconv.ParseInt(strings.TrimPrefix(query.Data, "ban_rm:"), 10, 64)
+	if err != nil {
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "Неверный формат"))
+		return nil
+	}
+
+	removed, err := c.storage.DeleteBan(ctx, telegramID)
+	if err != nil {
+		c.logger.Error("Failed to delete ban", "error", err, "telegram_id", telegramID)
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "❌ Ошибка"))
+		return nil
+	}
+
+	if removed {
+		c.logger.Info("User unbanned",
+			"audit", true,
+			"admin_telegram_id", adminTelegramID,
+			"telegram_id", telegramID,
+		)
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "✅ Бан снят"))
+	} else {
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "Бан уже снят"))
+	}
+
+	return c.showList(ctx, chatID, messageID)
+}
+
+func (c *BansCommand) showList(ctx context.Context, chatID int64, messageID int) error {
+	list, err := c.storage.ListBans(ctx)
+	if err != nil {
+		c.logger.Error("Failed to list bans", "error", err)
+		return c.send(chatID, "❌ Ошибка получения списка блокировок")
+	}
+
+	var text strings.Builder
+	text.WriteString("🚫 *Блокировки*\n\n")
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if len(list) == 0 {
+		text.WriteString("_Никто не заблокирован_\n")
+	}
+	for _, ban := range list {
+		who := "автобан"
+		if !ban.IsAuto() {
+			who = fmt.Sprintf("админ `%d`", *ban.BannedBy)
+		}
+		text.WriteString(fmt.Sprintf("• `%d` — %s, %s", ban.TelegramID, who, ban.CreatedAt.Format("02.01.2006 15:04")))
+		if ban.Reason != "" {
+			text.WriteString("\n  Причина: " + tgbotapi.EscapeText(tgbotapi.ModeMarkdown, ban.Reason))
+		}
+		text.WriteString("\n")
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("✅ Разбанить: %d", ban.TelegramID),
+				fmt.Sprintf("ban_rm:%d", ban.TelegramID),
+			),
+		))
+	}
+	text.WriteString("\nЗаблокировать: `/ban <Telegram ID> [причина]`")
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", "main_menu"),
+	))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	if messageID > 0 {
+		editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text.String())
+		editMsg.ParseMode = "Markdown"
+		editMsg.ReplyMarkup = &keyboard
+		return telegram.SafeEdit(c.bot, editMsg, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+func (c *BansCommand) send(chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	_, err := c.bot.Send(msg)
+	return err
+}
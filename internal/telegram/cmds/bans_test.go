@@ -0,0 +1,38 @@
+package cmds
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBanArgs(t *testing.T) {
+	tests := []struct {
+		args       string
+		wantID     int64
+		wantReason string
+		wantErr    bool
+	}{
+		{"123456789", 123456789, "", false},
+		{"  123456789   спам в боте ", 123456789, "спам в боте", false},
+		{"@user", 0, "", true},
+		{"-5", 0, "", true},
+		{"", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			id, reason, err := ParseBanArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseBanArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if id != tt.wantID || reason != tt.wantReason {
+				t.Errorf("ParseBanArgs(%q) = %d, %q, want %d, %q", tt.args, id, reason, tt.wantID, tt.wantReason)
+			}
+		})
+	}
+
+	_, reason, _ := ParseBanArgs("1 " + strings.Repeat("я", maxBanReasonLength+10))
+	if got := len([]rune(reason)); got != maxBanReasonLength {
+		t.Errorf("long reason length = %d, want %d", got, maxBanReasonLength)
+	}
+}
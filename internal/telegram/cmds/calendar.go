@@ -0,0 +1,153 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/tariffs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// CalendarCommand отправляет .ics файл с датой окончания подписки
+type CalendarCommand struct {
+	bot           *tgbotapi.BotAPI
+	subStorage    CalendarSubStorage
+	tariffService CalendarTariffService
+}
+
+type CalendarSubStorage interface {
+	GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error)
+}
+
+type CalendarTariffService interface {
+	GetTariff(ctx context.Context, criteria tariffs.GetCriteria) (*tariffs.Tariff, error)
+}
+
+func NewCalendarCommand(bot *tgbotapi.BotAPI, subStorage CalendarSubStorage, tariffService CalendarTariffService) *CalendarCommand {
+	return &CalendarCommand{
+		bot:           bot,
+		subStorage:    subStorage,
+		tariffService: tariffService,
+	}
+}
+
+// CalendarButton возвращает кнопку "В календарь" для подписки
+func CalendarButton(subscriptionID int64) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("📅 В календарь", fmt.Sprintf("cal_sub:%d", subscriptionID))
+}
+
+// HandleCallback обрабатывает callback cal_sub:subID. Ассистент получает файл только своих подписок, админ - любых:
+// в названии события номер клиента
+func (c *CalendarCommand) HandleCallback(ctx context.Context, viewerTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(callbackQuery.Data, ":")
+	if len(parts) != 2 {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+	subID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return c.answerCallback(callbackQuery.ID, "Неверный ID подписки")
+	}
+
+	sub, err := c.subStorage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{subID}})
+	if err != nil || sub == nil {
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	if !isAdmin && (sub.CreatedByTelegramID == nil || *sub.CreatedByTelegramID != viewerTelegramID) {
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	if sub.ExpiresAt == nil {
+		return c.answerCallback(callbackQuery.ID, "Подписка еще не активирована")
+	}
+
+	tariffName := ""
+	if tariff, err := c.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &sub.TariffID}); err == nil && tariff != nil {
+		tariffName = tariff.Name
+	}
+
+	_ = c.answerCallback(callbackQuery.ID, "")
+
+	title, description := calendarEventText(sub, tariffName)
+
+	doc := tgbotapi.NewDocument(callbackQuery.Message.Chat.ID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("subscription_%d.ics", sub.ID),
+		Bytes: buildExpiryICS(sub, title, description, time.Now().UTC()),
+	})
+	doc.Caption = fmt.Sprintf("📅 Окончание подписки: %s", sub.ExpiresAt.Format("02.01.2006"))
+	doc.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL("Google Calendar", googleCalendarLink(*sub.ExpiresAt, title, description)),
+		),
+	)
+	_, err = c.bot.Send(doc)
+	return err
+}
+
+func calendarEventText(sub *subs.Subscription, tariffName string) (string, string) {
+	title := "Окончание VPN подписки"
+	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
+		title = fmt.Sprintf("Окончание VPN подписки %s", *sub.ClientWhatsApp)
+	}
+
+	description := fmt.Sprintf("Подписка #%d", sub.ID)
+	if tariffName != "" {
+		description += fmt.Sprintf(", тариф %s", tariffName)
+	}
+	return title, description
+}
+
+// buildExpiryICS формирует событие календаря на день окончания подписки с напоминанием за день
+func buildExpiryICS(sub *subs.Subscription, title, description string, now time.Time) []byte {
+	day := sub.ExpiresAt.UTC()
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//kurut-bot//subscriptions//RU",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		fmt.Sprintf("UID:subscription-%d-%s@kurut-bot", sub.ID, day.Format("20060102")),
+		"DTSTAMP:" + now.UTC().Format("20060102T150405Z"),
+		"DTSTART;VALUE=DATE:" + day.Format("20060102"),
+		"DTEND;VALUE=DATE:" + day.AddDate(0, 0, 1).Format("20060102"),
+		"SUMMARY:" + escapeICSText(title),
+		"DESCRIPTION:" + escapeICSText(description),
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:" + escapeICSText(title),
+		"TRIGGER:-P1D",
+		"END:VALARM",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}
+
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+// escapeICSText экранирует спецсимволы текста по RFC 5545
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// googleCalendarLink формирует ссылку на создание события в Google Calendar
+func googleCalendarLink(expiresAt time.Time, title, description string) string {
+	day := expiresAt.UTC()
+	params := url.Values{}
+	params.Set("action", "TEMPLATE")
+	params.Set("text", title)
+	params.Set("details", description)
+	params.Set("dates", day.Format("20060102")+"/"+day.AddDate(0, 0, 1).Format("20060102"))
+	return "https://calendar.google.com/calendar/render?" + params.Encode()
+}
+
+func (c *CalendarCommand) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := c.bot.Request(callback)
+	return err
+}
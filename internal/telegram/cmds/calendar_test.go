@@ -0,0 +1,33 @@
+package cmds
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"kurut-bot/internal/stories/subs"
+)
+
+func TestBuildExpiryICS(t *testing.T) {
+	expiresAt := time.Date(2025, 3, 15, 18, 30, 0, 0, time.UTC)
+	sub := &subs.Subscription{ID: 7, ExpiresAt: &expiresAt}
+	now := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	ics := string(buildExpiryICS(sub, "Окончание; подписки", "Тариф 1, месяц", now))
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:subscription-7-20250315@kurut-bot\r\n",
+		"DTSTAMP:20250201T100000Z\r\n",
+		"DTSTART;VALUE=DATE:20250315\r\n",
+		"DTEND;VALUE=DATE:20250316\r\n",
+		"SUMMARY:Окончание\\; подписки\r\n",
+		"DESCRIPTION:Тариф 1\\, месяц\r\n",
+		"TRIGGER:-P1D\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("ics does not contain %q:\n%s", want, ics)
+		}
+	}
+}
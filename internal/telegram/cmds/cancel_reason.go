@@ -0,0 +1,99 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/flowcancel"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// cancelReasonSkip - кнопка «Пропустить»: отмена остается без причины
+const cancelReasonSkip = "skip"
+
+// CancelReasonCommand записывает отмены флоу и спрашивает причину одной кнопкой (fcr:ID:reason)
+type CancelReasonCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage CancelReasonStorage
+	logger  *slog.Logger
+}
+
+type CancelReasonStorage interface {
+	CreateFlowCancellation(ctx context.Context, cancellation flowcancel.Cancellation) (int64, error)
+	SetFlowCancellationReason(ctx context.Context, id, telegramID int64, reason flowcancel.Reason) (bool, error)
+}
+
+func NewCancelReasonCommand(bot *tgbotapi.BotAPI, storage CancelReasonStorage, logger *slog.Logger) *CancelReasonCommand {
+	return &CancelReasonCommand{
+		bot:     bot,
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Ask записывает отмену флоу на шаге state и предлагает указать причину.
+// Ошибки только логируются: отмена флоу не должна от них зависеть
+func (c *CancelReasonCommand) Ask(ctx context.Context, telegramID, chatID int64, flow flowcancel.Flow, state string) {
+	id, err := c.storage.CreateFlowCancellation(ctx, flowcancel.Cancellation{
+		Flow:       flow,
+		State:      state,
+		TelegramID: telegramID,
+	})
+	if err != nil {
+		c.logger.Error("Failed to record flow cancellation", "error", err, "flow", flow, "state", state)
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, reason := range flowcancel.Reasons {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(reason.Title(), fmt.Sprintf("fcr:%d:%s", id, reason)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Пропустить", fmt.Sprintf("fcr:%d:%s", id, cancelReasonSkip)),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, "🤔 Почему отменили? Ответ поможет сделать бота удобнее")
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	if _, err := c.bot.Send(msg); err != nil {
+		c.logger.Error("Failed to send cancel reason survey", "error", err, "cancellation_id", id)
+	}
+}
+
+// HandleCallback сохраняет выбранную причину (fcr:ID:reason) и закрывает опрос
+func (c *CancelReasonCommand) HandleCallback(ctx context.Context, telegramID int64, callbackQuery *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(callbackQuery.Data, ":")
+	if len(parts) != 3 || parts[0] != "fcr" {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+
+	text := "Хорошо, без причины"
+	if reason := flowcancel.Reason(parts[2]); reason.IsValid() {
+		if _, err := c.storage.SetFlowCancellationReason(ctx, id, telegramID, reason); err != nil {
+			_ = c.answerCallback(callbackQuery.ID, "Ошибка сохранения")
+			return fmt.Errorf("set flow cancellation reason: %w", err)
+		}
+		text = "🙏 Спасибо! Причина: " + reason.Title()
+	} else if parts[2] != cancelReasonSkip {
+		return c.answerCallback(callbackQuery.ID, "Неизвестная причина")
+	}
+
+	_ = c.answerCallback(callbackQuery.ID, "")
+	edit := tgbotapi.NewEditMessageText(callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID, text)
+	return telegram.SafeEdit(c.bot, edit, "")
+}
+
+func (c *CancelReasonCommand) answerCallback(callbackID string, text string) error {
+	_, err := c.bot.Request(tgbotapi.NewCallback(callbackID, text))
+	return err
+}
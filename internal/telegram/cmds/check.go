@@ -0,0 +1,162 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// checkMaxPhones - сколько номеров можно проверить одной командой
+const checkMaxPhones = 50
+
+type CheckStorage interface {
+	ListLatestSubscriptionsByWhatsApp(ctx context.Context, phones []string, createdByTelegramID *int64) ([]storage.SubscriptionDetails, error)
+}
+
+// CheckCommand показывает статус, срок и сервер по списку номеров: /check <номер1, номер2, ...>
+type CheckCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage CheckStorage
+}
+
+func NewCheckCommand(bot *tgbotapi.BotAPI, storage CheckStorage) *CheckCommand {
+	return &CheckCommand{
+		bot:     bot,
+		storage: storage,
+	}
+}
+
+// Execute проверяет номера одним запросом. Ассистент видит только своих клиентов, админ - всех
+func (c *CheckCommand) Execute(ctx context.Context, assistantTelegramID, chatID int64, isAdmin bool, args string) error {
+	phones, invalid := ParseCheckPhones(args)
+	if len(phones) == 0 && len(invalid) == 0 {
+		return c.send(chatID, "Используйте: /check <номер1, номер2, ...>\n\n"+
+			"Номера можно разделять запятыми или переносами строк, "+
+			fmt.Sprintf("не больше %d за раз.", checkMaxPhones), false)
+	}
+	if len(phones) > checkMaxPhones {
+		return c.send(chatID, fmt.Sprintf("❌ Слишком много номеров: %d. Можно проверить не больше %d за раз", len(phones), checkMaxPhones), false)
+	}
+
+	var createdBy *int64
+	if !isAdmin {
+		createdBy = &assistantTelegramID
+	}
+
+	results, err := c.storage.ListLatestSubscriptionsByWhatsApp(ctx, phones, createdBy)
+	if err != nil {
+		_ = c.send(chatID, "❌ Ошибка проверки номеров", false)
+		return fmt.Errorf("list latest subscriptions: %w", err)
+	}
+
+	return c.send(chatID, formatCheckTable(phones, invalid, latestByPhone(results)), true)
+}
+
+// ParseCheckPhones разбирает список номеров: нормализует, убирает повторы, неверные номера возвращает отдельно
+func ParseCheckPhones(args string) (phones, invalid []string) {
+	seen := make(map[string]bool)
+	fields := strings.FieldsFunc(args, func(r rune) bool {
+		return r == ',' || r == ';' || r == '\n' || r == '\t'
+	})
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		phone := storage.NormalizePhone(field)
+		if len(phone) < 10 || len(phone) > 15 {
+			invalid = append(invalid, field)
+			continue
+		}
+		if !seen[phone] {
+			seen[phone] = true
+			phones = append(phones, phone)
+		}
+	}
+	return phones, invalid
+}
+
+// latestByPhone - последняя подписка по нормализованному номеру: номер мог сохраниться и с "+", и без
+func latestByPhone(results []storage.SubscriptionDetails) map[string]storage.SubscriptionDetails {
+	byPhone := make(map[string]storage.SubscriptionDetails, len(results))
+	for _, details := range results {
+		if details.Subscription.ClientWhatsApp == nil {
+			continue
+		}
+		phone := storage.NormalizePhone(*details.Subscription.ClientWhatsApp)
+		if prev, ok := byPhone[phone]; !ok || details.Subscription.ID > prev.Subscription.ID {
+			byPhone[phone] = details
+		}
+	}
+	return byPhone
+}
+
+// formatCheckTable - таблица "номер / статус / до / сервер" в порядке запроса
+func formatCheckTable(phones, invalid []string, byPhone map[string]storage.SubscriptionDetails) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📋 *Проверка номеров: %d*\n", len(phones))
+
+	if len(phones) > 0 {
+		sb.WriteString("```\n")
+		for _, phone := range phones {
+			details, ok := byPhone[phone]
+			if !ok {
+				fmt.Fprintf(&sb, "%-15s %s\n", phone, "не найден")
+				continue
+			}
+
+			sub := details.Subscription
+			expires := "—"
+			if sub.ExpiresAt != nil {
+				expires = sub.ExpiresAt.Format("02.01.06")
+			}
+			server := "—"
+			if details.ServerName != nil {
+				server = *details.ServerName
+			}
+			fmt.Fprintf(&sb, "%-15s %-9s %-8s %s\n", phone, checkStatusText(sub.Status), expires, server)
+		}
+		sb.WriteString("```")
+	}
+
+	if len(invalid) > 0 {
+		sb.WriteString("\n⚠️ Неверный формат: " + tgbotapi.EscapeText(tgbotapi.ModeMarkdown, strings.Join(invalid, ", ")))
+	}
+	return sb.String()
+}
+
+// checkStatusText - короткий статус без эмодзи, чтобы колонки таблицы не съезжали
+func checkStatusText(status subs.Status) string {
+	switch status {
+	case subs.StatusActive:
+		return "активна"
+	case subs.StatusPending:
+		return "ожидает"
+	case subs.StatusExpired:
+		return "истекла"
+	case subs.StatusDisabled:
+		return "отключена"
+	case subs.StatusArchived:
+		return "в архиве"
+	case subs.StatusCancelled:
+		return "отменена"
+	case subs.StatusPaused:
+		return "пауза"
+	default:
+		return string(status)
+	}
+}
+
+func (c *CheckCommand) send(chatID int64, text string, markdown bool) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	if markdown {
+		msg.ParseMode = "Markdown"
+	}
+	_, err := c.bot.Send(msg)
+	return err
+}
@@ -0,0 +1,44 @@
+package cmds
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
+)
+
+func TestParseCheckPhones(t *testing.T) {
+	phones, invalid := ParseCheckPhones("+996 555 123-456, 996555123456;\n996700111222,12345")
+
+	if want := []string{"996555123456", "996700111222"}; !slices.Equal(phones, want) {
+		t.Errorf("phones = %v, want %v", phones, want)
+	}
+	if want := []string{"12345"}; !slices.Equal(invalid, want) {
+		t.Errorf("invalid = %v, want %v", invalid, want)
+	}
+}
+
+func TestFormatCheckTable(t *testing.T) {
+	oldPhone, newPhone := "996555123456", "+996555123456"
+	expires := time.Date(2026, 11, 5, 0, 0, 0, 0, time.UTC)
+	server := "de-1"
+
+	byPhone := latestByPhone([]storage.SubscriptionDetails{
+		{Subscription: &subs.Subscription{ID: 1, ClientWhatsApp: &oldPhone, Status: subs.StatusExpired}},
+		{Subscription: &subs.Subscription{ID: 7, ClientWhatsApp: &newPhone, Status: subs.StatusActive, ExpiresAt: &expires}, ServerName: &server},
+	})
+	text := formatCheckTable([]string{"996555123456", "996700111222"}, []string{"abc_1"}, byPhone)
+
+	for _, want := range []string{
+		"996555123456    активна   05.11.26 de-1",
+		"996700111222    не найден",
+		`Неверный формат: abc\_1`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("table %q does not contain %q", text, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type ClientLanguageStorage interface {
+	GetClientLanguage(ctx context.Context, clientWhatsApp string) (clientlang.Language, error)
+}
+
+// ClientLanguageCommand позволяет ассистенту выбрать язык, на котором генерируются сообщения клиенту в WhatsApp
+type ClientLanguageCommand struct {
+	bot        *tgbotapi.BotAPI
+	subStorage ClientLanguageSubStorage
+	storage    ClientLanguageSetter
+}
+
+type ClientLanguageSubStorage interface {
+	GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error)
+}
+
+type ClientLanguageSetter interface {
+	GetClientLanguage(ctx context.Context, clientWhatsApp string) (clientlang.Language, error)
+	SetClientLanguage(ctx context.Context, clientWhatsApp string, lang clientlang.Language) error
+}
+
+func NewClientLanguageCommand(bot *tgbotapi.BotAPI, subStorage ClientLanguageSubStorage, storage ClientLanguageSetter) *ClientLanguageCommand {
+	return &ClientLanguageCommand{
+		bot:        bot,
+		subStorage: subStorage,
+		storage:    storage,
+	}
+}
+
+// ClientLanguageButton возвращает кнопку выбора языка клиента для подписки
+func ClientLanguageButton(subscriptionID int64) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("🌐 Язык клиента", fmt.Sprintf("wal_menu:%d", subscriptionID))
+}
+
+// HandleCallback обрабатывает wal_menu:subID и wal_set:subID:lang.
+// Ассистенту доступны только его подписки, админу - любые
+func (c *ClientLanguageCommand) HandleCallback(ctx context.Context, viewerTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(callbackQuery.Data, ":")
+	if len(parts) < 2 {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+
+	subID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return c.answerCallback(callbackQuery.ID, "Неверный ID подписки")
+	}
+
+	sub, err := c.subStorage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{subID}})
+	if err != nil || sub == nil {
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	if !isAdmin && (sub.CreatedByTelegramID == nil || *sub.CreatedByTelegramID != viewerTelegramID) {
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	if sub.ClientWhatsApp == nil || *sub.ClientWhatsApp == "" {
+		return c.answerCallback(callbackQuery.ID, "У подписки нет номера клиента")
+	}
+	phone := *sub.ClientWhatsApp
+
+	switch parts[0] {
+	case "wal_menu":
+		return c.showMenu(ctx, callbackQuery, subID, phone)
+	case "wal_set":
+		if len(parts) != 3 {
+			return c.answerCallback(callbackQuery.ID, "Неверный формат")
+		}
+		lang := clientlang.Language(parts[2])
+		if !lang.IsSupported() {
+			return c.answerCallback(callbackQuery.ID, "Неизвестный язык")
+		}
+		if err := c.storage.SetClientLanguage(ctx, phone, lang); err != nil {
+			_ = c.answerCallback(callbackQuery.ID, "Ошибка сохранения")
+			return fmt.Errorf("set client language: %w", err)
+		}
+		_ = c.answerCallback(callbackQuery.ID, "Язык сохранен")
+
+		text := fmt.Sprintf("🌐 Клиент %s: %s\n\nНовые сообщения для WhatsApp будут на этом языке.", phone, lang.Title())
+		_, err = c.bot.Send(tgbotapi.NewMessage(callbackQuery.Message.Chat.ID, text))
+		return err
+	default:
+		return c.answerCallback(callbackQuery.ID, "Неизвестная команда")
+	}
+}
+
+// showMenu отправляет выбор языка с отметкой текущего
+func (c *ClientLanguageCommand) showMenu(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery, subID int64, phone string) error {
+	stored, err := c.storage.GetClientLanguage(ctx, phone)
+	if err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка получения языка")
+		return fmt.Errorf("get client language: %w", err)
+	}
+	current := clientlang.Resolve(stored, phone)
+
+	_ = c.answerCallback(callbackQuery.ID, "")
+
+	text := fmt.Sprintf("🌐 Язык сообщений для %s\n\nСейчас: %s", phone, current.Title())
+	if !stored.IsSupported() && current != clientlang.Default {
+		text += " (по номеру)"
+	}
+
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(clientlang.Supported))
+	for _, lang := range clientlang.Supported {
+		title := lang.Title()
+		if lang == current {
+			title = "✓ " + title
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(title, fmt.Sprintf("wal_set:%d:%s", subID, lang)))
+	}
+
+	msg := tgbotapi.NewMessage(callbackQuery.Message.Chat.ID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+func (c *ClientLanguageCommand) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := c.bot.Request(callback)
+	return err
+}
@@ -0,0 +1,128 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/stories/devices"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/telegram/messages"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ClientPlatformCommand запоминает платформу устройства клиента и выдает инструкцию по подключению под нее
+type ClientPlatformCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage ClientPlatformStorage
+}
+
+type ClientPlatformStorage interface {
+	GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error)
+	SetSubscriptionClientPlatform(ctx context.Context, subscriptionID int64, platform devices.Platform) error
+}
+
+func NewClientPlatformCommand(bot *tgbotapi.BotAPI, storage ClientPlatformStorage) *ClientPlatformCommand {
+	return &ClientPlatformCommand{
+		bot:     bot,
+		storage: storage,
+	}
+}
+
+// ClientPlatformButton возвращает кнопку инструкции по подключению для подписки
+func ClientPlatformButton(subscriptionID int64) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("📲 Инструкция", fmt.Sprintf("dev_menu:%d", subscriptionID))
+}
+
+// ClientPlatformRow возвращает кнопки выбора платформы клиента; current отмечается галочкой
+func ClientPlatformRow(subscriptionID int64, current devices.Platform) []tgbotapi.InlineKeyboardButton {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(devices.Supported))
+	for _, platform := range devices.Supported {
+		title := platform.Title()
+		if platform == current {
+			title = "✓ " + title
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(title, fmt.Sprintf("dev_set:%d:%s", subscriptionID, platform)))
+	}
+	return row
+}
+
+// HandleCallback обрабатывает dev_menu:subID и dev_set:subID:platform.
+// Ассистенту доступны только его подписки, админу - любые
+func (c *ClientPlatformCommand) HandleCallback(ctx context.Context, viewerTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(callbackQuery.Data, ":")
+	if len(parts) < 2 {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+
+	subID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return c.answerCallback(callbackQuery.ID, "Неверный ID подписки")
+	}
+
+	sub, err := c.storage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{subID}})
+	if err != nil || sub == nil {
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	if !isAdmin && (sub.CreatedByTelegramID == nil || *sub.CreatedByTelegramID != viewerTelegramID) {
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+
+	switch parts[0] {
+	case "dev_menu":
+		_ = c.answerCallback(callbackQuery.ID, "")
+		return c.sendInstructions(callbackQuery.Message.Chat.ID, sub)
+	case "dev_set":
+		if len(parts) != 3 {
+			return c.answerCallback(callbackQuery.ID, "Неверный формат")
+		}
+		platform := devices.Platform(parts[2])
+		if !platform.IsSupported() {
+			return c.answerCallback(callbackQuery.ID, "Неизвестная платформа")
+		}
+		if err := c.storage.SetSubscriptionClientPlatform(ctx, subID, platform); err != nil {
+			_ = c.answerCallback(callbackQuery.ID, "Ошибка сохранения")
+			return fmt.Errorf("set client platform: %w", err)
+		}
+		_ = c.answerCallback(callbackQuery.ID, "Платформа сохранена")
+
+		sub.ClientPlatform = platform
+		return c.sendInstructions(callbackQuery.Message.Chat.ID, sub)
+	default:
+		return c.answerCallback(callbackQuery.ID, "Неизвестная команда")
+	}
+}
+
+// sendInstructions отправляет инструкцию под платформу подписки со ссылкой, чтобы переслать ее клиенту в WhatsApp.
+// Если платформа не указана - просит ее выбрать
+func (c *ClientPlatformCommand) sendInstructions(chatID int64, sub *subs.Subscription) error {
+	instructions := messages.SetupInstructions(sub.ClientPlatform)
+
+	var text string
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if sub.ClientPlatform.IsSupported() {
+		text = fmt.Sprintf("📲 Подписка #%d, платформа клиента: %s\n\n%s", sub.ID, sub.ClientPlatform.Title(), instructions)
+		if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonURL("💬 Отправить клиенту", GenerateWhatsAppLink(*sub.ClientWhatsApp, instructions)),
+			))
+		}
+	} else {
+		text = fmt.Sprintf("📲 Подписка #%d\n\nНа каком устройстве клиент будет пользоваться VPN? Инструкция и ссылка на приложение подберутся под него.", sub.ID)
+	}
+	rows = append(rows, ClientPlatformRow(sub.ID, sub.ClientPlatform))
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	msg.DisableWebPagePreview = true
+	_, err := c.bot.Send(msg)
+	return err
+}
+
+func (c *ClientPlatformCommand) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := c.bot.Request(callback)
+	return err
+}
@@ -0,0 +1,320 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// clientsPageSize - сколько клиентов показывать на одной странице /clients
+const clientsPageSize = 10
+
+// clientsExpiryWindows - варианты фильтра «истекают в ближайшие N дней»; 0 - без фильтра
+var clientsExpiryWindows = []int{0, 3, 7, 30}
+
+// clientsStatusFilters - фильтр по статусу: код в callback и статусы подписок. Пустой код - все рабочие статусы
+var clientsStatusFilters = []struct {
+	code     string
+	title    string
+	statuses []subs.Status
+}{
+	{"", "Все", []subs.Status{subs.StatusActive, subs.StatusPaused, subs.StatusExpired, subs.StatusDisabled}},
+	{"a", "🟢 Активные", []subs.Status{subs.StatusActive}},
+	{"e", "⌛ Истекшие", []subs.Status{subs.StatusExpired}},
+	{"d", "⛔ Отключенные", []subs.Status{subs.StatusDisabled}},
+}
+
+type ClientsStorage interface {
+	ListClientsPage(ctx context.Context, criteria subs.ClientPageCriteria) ([]storage.ClientSummary, error)
+	ListServers(ctx context.Context, criteria servers.ListCriteria) ([]*servers.Server, error)
+}
+
+// ClientsCommand - справочник клиентов: подписки сгруппированы по WhatsApp, с фильтрами и постраничным просмотром
+type ClientsCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage ClientsStorage
+}
+
+func NewClientsCommand(bot *tgbotapi.BotAPI, storage ClientsStorage) *ClientsCommand {
+	return &ClientsCommand{
+		bot:     bot,
+		storage: storage,
+	}
+}
+
+// clientsFilter - состояние списка, целиком хранится в callback: cli:<статус>:<сервер>:<дни>:<курсор>
+type clientsFilter struct {
+	Status   string
+	ServerID int64
+	Days     int
+	BeforeID int64
+}
+
+func (f clientsFilter) callback() string {
+	return fmt.Sprintf("cli:%s:%d:%d:%d", f.Status, f.ServerID, f.Days, f.BeforeID)
+}
+
+// parseClientsFilter разбирает callback списка клиентов
+func parseClientsFilter(data string) (clientsFilter, error) {
+	parts := strings.Split(strings.TrimPrefix(data, "cli:"), ":")
+	if len(parts) != 4 {
+		return clientsFilter{}, errors.New("invalid clients callback")
+	}
+
+	var f clientsFilter
+	f.Status = parts[0]
+	if !isClientsStatus(f.Status) {
+		return clientsFilter{}, errors.New("invalid clients status")
+	}
+
+	var err error
+	if f.ServerID, err = strconv.ParseInt(parts[1], 10, 64); err != nil || f.ServerID < 0 {
+		return clientsFilter{}, errors.New("invalid clients server")
+	}
+	if f.Days, err = strconv.Atoi(parts[2]); err != nil || f.Days < 0 {
+		return clientsFilter{}, errors.New("invalid clients window")
+	}
+	if f.BeforeID, err = strconv.ParseInt(parts[3], 10, 64); err != nil || f.BeforeID < 0 {
+		return clientsFilter{}, errors.New("invalid clients cursor")
+	}
+	return f, nil
+}
+
+func isClientsStatus(code string) bool {
+	for _, s := range clientsStatusFilters {
+		if s.code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// criteria переводит фильтр в критерии выборки. Ассистент видит только своих клиентов
+func (f clientsFilter) criteria(assistantTelegramID int64, isAdmin bool, now time.Time) subs.ClientPageCriteria {
+	criteria := subs.ClientPageCriteria{Limit: clientsPageSize}
+	for _, s := range clientsStatusFilters {
+		if s.code == f.Status {
+			criteria.Status = s.statuses
+		}
+	}
+	if !isAdmin {
+		criteria.CreatedByTelegramID = &assistantTelegramID
+	}
+	if f.ServerID > 0 {
+		criteria.ServerID = &f.ServerID
+	}
+	if f.Days > 0 {
+		before := now.AddDate(0, 0, f.Days)
+		criteria.ExpiresAfter = &now
+		criteria.ExpiresBefore = &before
+	}
+	if f.BeforeID > 0 {
+		criteria.BeforeSubscriptionID = &f.BeforeID
+	}
+	return criteria
+}
+
+// Execute показывает первую страницу клиентов без фильтров
+func (c *ClientsCommand) Execute(ctx context.Context, assistantTelegramID, chatID int64, isAdmin bool) error {
+	return c.showList(ctx, assistantTelegramID, chatID, 0, isAdmin, clientsFilter{})
+}
+
+// HandleCallback обрабатывает смену фильтров и страниц (cli:...) и выбор сервера (clisrv:...)
+func (c *ClientsCommand) HandleCallback(ctx context.Context, assistantTelegramID int64, isAdmin bool, query *tgbotapi.CallbackQuery) error {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+
+	if strings.HasPrefix(query.Data, "clisrv:") {
+		filter, err := parseClientsFilter("cli:" + strings.TrimPrefix(query.Data, "clisrv:"))
+		if err != nil {
+			_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "Неверный формат"))
+			return nil
+		}
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		return c.showServerPicker(ctx, chatID, messageID, filter)
+	}
+
+	filter, err := parseClientsFilter(query.Data)
+	if err != nil {
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "Неверный формат"))
+		return nil
+	}
+	_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, ""))
+	return c.showList(ctx, assistantTelegramID, chatID, messageID, isAdmin, filter)
+}
+
+func (c *ClientsCommand) showList(ctx context.Context, assistantTelegramID, chatID int64, messageID int, isAdmin bool, filter clientsFilter) error {
+	page, err := c.storage.ListClientsPage(ctx, filter.criteria(assistantTelegramID, isAdmin, time.Now()))
+	if err != nil {
+		_, _ = c.bot.Send(tgbotapi.NewMessage(chatID, "❌ Ошибка загрузки клиентов"))
+		return fmt.Errorf("list clients page: %w", err)
+	}
+
+	serverName := "все"
+	if filter.ServerID > 0 {
+		serverName = fmt.Sprintf("#%d", filter.ServerID)
+		if list, err := c.storage.ListServers(ctx, servers.ListCriteria{Limit: 100}); err == nil {
+			for _, s := range list {
+				if s.ID == filter.ServerID {
+					serverName = s.Name
+				}
+			}
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString("👥 Клиенты\n\n")
+	for _, s := range clientsStatusFilters {
+		if s.code == filter.Status {
+			fmt.Fprintf(&text, "Статус: %s\n", s.title)
+		}
+	}
+	fmt.Fprintf(&text, "Сервер: %s\n", serverName)
+	if filter.Days > 0 {
+		fmt.Fprintf(&text, "Истекают: в ближайшие %d дн.\n", filter.Days)
+	}
+	if len(page) == 0 {
+		text.WriteString("\nКлиентов не найдено")
+	} else {
+		text.WriteString("\nНажмите на клиента, чтобы открыть его последнюю подписку:")
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(page)+5)
+	rows = append(rows, clientsStatusRow(filter), clientsWindowRow(filter))
+	serverFilter := filter
+	serverFilter.BeforeID = 0
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🖥 Сервер: "+serverName, "clisrv:"+strings.TrimPrefix(serverFilter.callback(), "cli:")),
+	))
+
+	for _, client := range page {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(SubViewButton(clientLabel(client), client.LastSubscription.ID)))
+	}
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if filter.BeforeID > 0 {
+		first := filter
+		first.BeforeID = 0
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("⏮ В начало", first.callback()))
+	}
+	if len(page) == clientsPageSize {
+		next := filter
+		next.BeforeID = page[len(page)-1].LastSubscription.ID
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("➡️ Ещё", next.callback()))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	if messageID > 0 {
+		editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text.String())
+		editMsg.ReplyMarkup = &keyboard
+		return telegram.SafeEdit(c.bot, editMsg, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ReplyMarkup = keyboard
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+// clientsStatusRow - кнопки фильтра по статусу; выбранный отмечен галочкой. Смена фильтра сбрасывает страницу
+func clientsStatusRow(filter clientsFilter) []tgbotapi.InlineKeyboardButton {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(clientsStatusFilters))
+	for _, s := range clientsStatusFilters {
+		title := s.title
+		if s.code == filter.Status {
+			title = "✅ " + title
+		}
+		next := filter
+		next.Status, next.BeforeID = s.code, 0
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(title, next.callback()))
+	}
+	return row
+}
+
+// clientsWindowRow - кнопки фильтра по сроку истечения
+func clientsWindowRow(filter clientsFilter) []tgbotapi.InlineKeyboardButton {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(clientsExpiryWindows))
+	for _, days := range clientsExpiryWindows {
+		title := "Любой срок"
+		if days > 0 {
+			title = fmt.Sprintf("≤%d дн", days)
+		}
+		if days == filter.Days {
+			title = "✅ " + title
+		}
+		next := filter
+		next.Days, next.BeforeID = days, 0
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(title, next.callback()))
+	}
+	return row
+}
+
+func (c *ClientsCommand) showServerPicker(ctx context.Context, chatID int64, messageID int, filter clientsFilter) error {
+	list, err := c.storage.ListServers(ctx, servers.ListCriteria{Limit: 100})
+	if err != nil {
+		_, _ = c.bot.Send(tgbotapi.NewMessage(chatID, "❌ Ошибка получения списка серверов"))
+		return fmt.Errorf("list servers: %w", err)
+	}
+
+	all := filter
+	all.ServerID = 0
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Все серверы", all.callback())),
+	}
+	for _, s := range list {
+		title := s.Name
+		if s.Archived {
+			title = "📦 " + title
+		}
+		if s.ID == filter.ServerID {
+			title = "✅ " + title
+		}
+		next := filter
+		next.ServerID = s.ID
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(title, next.callback())))
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, messageID, "🖥 Выберите сервер:")
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	editMsg.ReplyMarkup = &keyboard
+	return telegram.SafeEdit(c.bot, editMsg, "")
+}
+
+// clientLabel - текст кнопки клиента: "🟢 996555123456 · 2 подп. · до 01.02.2026"
+func clientLabel(client storage.ClientSummary) string {
+	sub := client.LastSubscription
+
+	icon := "⌛"
+	switch {
+	case client.ActiveCount > 0:
+		icon = "🟢"
+	case sub.Status == subs.StatusDisabled:
+		icon = "⛔"
+	}
+
+	label := icon + " "
+	if sub.ClientWhatsApp != nil {
+		label += *sub.ClientWhatsApp
+	}
+	if client.SubscriptionsCount > 1 {
+		label += fmt.Sprintf(" · %d подп.", client.SubscriptionsCount)
+	}
+	if sub.ExpiresAt != nil {
+		label += " · до " + sub.ExpiresAt.Format("02.01.2006")
+	}
+	return label
+}
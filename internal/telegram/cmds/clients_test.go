@@ -0,0 +1,55 @@
+package cmds
+
+import (
+	"testing"
+	"time"
+
+	"kurut-bot/internal/stories/subs"
+)
+
+func TestClientsFilterCallback(t *testing.T) {
+	filters := []clientsFilter{
+		{},
+		{Status: "a", ServerID: 3, Days: 7, BeforeID: 120},
+		{Status: "d", Days: 30},
+	}
+	for _, want := range filters {
+		data := want.callback()
+		if len(data) > 64 {
+			t.Errorf("callback %q longer than 64 bytes", data)
+		}
+		got, err := parseClientsFilter(data)
+		if err != nil || got != want {
+			t.Errorf("parseClientsFilter(%q) = %+v, %v, want %+v", data, got, err, want)
+		}
+	}
+
+	for _, data := range []string{"cli:", "cli:x:0:0:0", "cli:a:-1:0:0", "cli:a:0:abc:0", "cli:a:0:0"} {
+		if _, err := parseClientsFilter(data); err == nil {
+			t.Errorf("parseClientsFilter(%q) error = nil, want error", data)
+		}
+	}
+}
+
+func TestClientsFilterCriteria(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	all := clientsFilter{}.criteria(7, true, now)
+	if all.CreatedByTelegramID != nil || all.ServerID != nil || all.ExpiresBefore != nil || len(all.Status) != 4 {
+		t.Errorf("admin without filters = %+v, want all working statuses of all assistants", all)
+	}
+
+	got := clientsFilter{Status: "e", ServerID: 2, Days: 3, BeforeID: 50}.criteria(7, false, now)
+	if got.CreatedByTelegramID == nil || *got.CreatedByTelegramID != 7 {
+		t.Errorf("assistant criteria CreatedByTelegramID = %v, want 7", got.CreatedByTelegramID)
+	}
+	if len(got.Status) != 1 || got.Status[0] != subs.StatusExpired {
+		t.Errorf("Status = %v, want [expired]", got.Status)
+	}
+	if got.ServerID == nil || *got.ServerID != 2 || got.BeforeSubscriptionID == nil || *got.BeforeSubscriptionID != 50 {
+		t.Errorf("criteria = %+v, want server 2 before 50", got)
+	}
+	if !got.ExpiresAfter.Equal(now) || !got.ExpiresBefore.Equal(now.AddDate(0, 0, 3)) {
+		t.Errorf("expiry window = %v..%v, want next 3 days", got.ExpiresAfter, got.ExpiresBefore)
+	}
+}
@@ -0,0 +1,200 @@
+package cmds
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxCohortsInMessage - сколько последних когорт показывать в сообщении (в CSV выгружаются все)
+const maxCohortsInMessage = 12
+
+type CohortsCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage CohortsStorage
+}
+
+type CohortsStorage interface {
+	GetCohortReport(ctx context.Context) (*storage.CohortReport, error)
+}
+
+func NewCohortsCommand(bot *tgbotapi.BotAPI, storage CohortsStorage) *CohortsCommand {
+	return &CohortsCommand{
+		bot:     bot,
+		storage: storage,
+	}
+}
+
+func (c *CohortsCommand) Execute(ctx context.Context, chatID int64) error {
+	report, err := c.storage.GetCohortReport(ctx)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при построении когорт")
+		_, _ = c.bot.Send(msg)
+		return fmt.Errorf("get cohort report: %w", err)
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📥 Выгрузить CSV", "cohorts_csv"),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, c.formatReport(report))
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+// SendCSV отправляет полный отчет по когортам CSV-файлом
+func (c *CohortsCommand) SendCSV(ctx context.Context, chatID int64) error {
+	report, err := c.storage.GetCohortReport(ctx)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при построении когорт")
+		_, _ = c.bot.Send(msg)
+		return fmt.Errorf("get cohort report: %w", err)
+	}
+
+	data, err := buildCohortsCSV(report)
+	if err != nil {
+		return fmt.Errorf("build cohorts csv: %w", err)
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  "cohorts.csv",
+		Bytes: data,
+	})
+	doc.Caption = "Когорты клиентов по месяцу первой оплаты"
+	_, err = c.bot.Send(doc)
+	return err
+}
+
+func (c *CohortsCommand) formatReport(report *storage.CohortReport) string {
+	var sb strings.Builder
+	sb.WriteString("📈 *Когорты клиентов*\n")
+	sb.WriteString("_Удержание по месяцу первой оплаты_\n\n")
+
+	if len(report.Overall) == 0 {
+		sb.WriteString("Нет оплаченных подписок")
+		return sb.String()
+	}
+
+	overall := report.Overall
+	if len(overall) > maxCohortsInMessage {
+		overall = overall[len(overall)-maxCohortsInMessage:]
+	}
+
+	sb.WriteString("```\n")
+	sb.WriteString(cohortHeader())
+	for _, row := range overall {
+		sb.WriteString(fmt.Sprintf("%-8s %4d", row.Month.Format("2006-01"), row.Clients))
+		for i := range storage.CohortRetentionMonths {
+			sb.WriteString(" " + formatCohortRate(row, i))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("```\n")
+
+	assistants := summarizeByAssistant(report.ByAssistant)
+	if len(assistants) > 0 {
+		sb.WriteString("\n👥 *По ассистентам (все когорты)*\n```\n")
+		sb.WriteString(cohortHeader())
+		for _, row := range assistants {
+			sb.WriteString(fmt.Sprintf("%-8d %4d", *row.AssistantTelegramID, row.Clients))
+			for i := range storage.CohortRetentionMonths {
+				sb.WriteString(" " + formatCohortRate(row, i))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("```")
+	}
+
+	return sb.String()
+}
+
+func cohortHeader() string {
+	header := fmt.Sprintf("%-8s %4s", "Когорта", "Кл.")
+	for _, months := range storage.CohortRetentionMonths {
+		header += fmt.Sprintf(" %5s", fmt.Sprintf("%dм", months))
+	}
+	return header + "\n"
+}
+
+func formatCohortRate(row storage.CohortRow, i int) string {
+	rate, ok := row.RetentionRate(i)
+	if !ok {
+		return fmt.Sprintf("%5s", "—")
+	}
+	return fmt.Sprintf("%4.0f%%", rate)
+}
+
+// summarizeByAssistant суммирует когорты каждого ассистента в одну строку
+func summarizeByAssistant(rows []storage.CohortRow) []storage.CohortRow {
+	var result []storage.CohortRow
+	for _, row := range rows {
+		if len(result) == 0 || *result[len(result)-1].AssistantTelegramID != *row.AssistantTelegramID {
+			result = append(result, storage.CohortRow{
+				AssistantTelegramID: row.AssistantTelegramID,
+				Retained:            make([]int, len(row.Retained)),
+				Eligible:            make([]int, len(row.Eligible)),
+			})
+		}
+		last := &result[len(result)-1]
+		last.Clients += row.Clients
+		for i := range row.Retained {
+			last.Retained[i] += row.Retained[i]
+			last.Eligible[i] += row.Eligible[i]
+		}
+	}
+	return result
+}
+
+func buildCohortsCSV(report *storage.CohortReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := []string{"cohort_month", "assistant_telegram_id", "clients"}
+	for _, months := range storage.CohortRetentionMonths {
+		header = append(header,
+			fmt.Sprintf("eligible_%dm", months),
+			fmt.Sprintf("retained_%dm", months),
+			fmt.Sprintf("retention_%dm_pct", months),
+		)
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	rows := append(append([]storage.CohortRow{}, report.Overall...), report.ByAssistant...)
+	for _, row := range rows {
+		assistant := "all"
+		if row.AssistantTelegramID != nil {
+			assistant = strconv.FormatInt(*row.AssistantTelegramID, 10)
+		}
+		record := []string{row.Month.Format("2006-01"), assistant, strconv.Itoa(row.Clients)}
+		for i := range storage.CohortRetentionMonths {
+			rate := ""
+			if r, ok := row.RetentionRate(i); ok {
+				rate = strconv.FormatFloat(r, 'f', 1, 64)
+			}
+			record = append(record, strconv.Itoa(row.Eligible[i]), strconv.Itoa(row.Retained[i]), rate)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
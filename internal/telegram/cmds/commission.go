@@ -0,0 +1,372 @@
+package cmds
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/commissions"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// CommissionCommand показывает ассистенту невыплаченную комиссию, а админу - комиссии всех ассистентов,
+// отметку выплат и выгрузку за месяц
+type CommissionCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage CommissionStorage
+	rate    commissions.Rate
+	staff   staffProvider
+	now     func() time.Time
+	logger  *slog.Logger
+}
+
+type CommissionStorage interface {
+	GetAssistantSales(ctx context.Context, from, to time.Time) ([]storage.AssistantSales, error)
+	CreateCommissionSettlement(ctx context.Context, settlement commissions.Settlement) (*commissions.Settlement, error)
+	GetLastCommissionSettlement(ctx context.Context, assistantTelegramID int64) (*commissions.Settlement, error)
+}
+
+// commissionBalance - невыплаченная комиссия ассистента за продажи в [from, to)
+type commissionBalance struct {
+	assistantID int64
+	from        time.Time
+	to          time.Time
+	sales       storage.AssistantSales
+	amount      float64
+}
+
+// NewCommissionCommand создает команду; staff - ассистенты и админы, которым начисляется комиссия
+func NewCommissionCommand(bot *tgbotapi.BotAPI, storage CommissionStorage, rate commissions.Rate, staff staffProvider, logger *slog.Logger) *CommissionCommand {
+	return &CommissionCommand{
+		bot:     bot,
+		storage: storage,
+		rate:    rate,
+		staff:   staff,
+		// Время оплат в базе хранится в UTC - границы периодов тоже
+		now:    func() time.Time { return time.Now().UTC() },
+		logger: logger,
+	}
+}
+
+// Execute показывает невыплаченную комиссию; админу - по всем ассистентам
+func (c *CommissionCommand) Execute(ctx context.Context, telegramID, chatID int64, isAdmin bool) error {
+	if !c.rate.Enabled() {
+		_, err := c.bot.Send(tgbotapi.NewMessage(chatID, "ℹ️ Комиссия ассистентов не настроена"))
+		return err
+	}
+
+	if isAdmin {
+		text, keyboard, err := c.adminScreen(ctx)
+		if err != nil {
+			_, _ = c.bot.Send(tgbotapi.NewMessage(chatID, "Ошибка при расчете комиссии"))
+			return err
+		}
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = "Markdown"
+		msg.ReplyMarkup = keyboard
+		_, err = c.bot.Send(msg)
+		return err
+	}
+
+	balance, err := c.balance(ctx, telegramID, c.now())
+	if err != nil {
+		_, _ = c.bot.Send(tgbotapi.NewMessage(chatID, "Ошибка при расчете комиссии"))
+		return err
+	}
+
+	var text strings.Builder
+	text.WriteString("💼 *Ваша комиссия*\n\n")
+	text.WriteString(fmt.Sprintf("Ставка: %s\n", c.rate))
+	text.WriteString(fmt.Sprintf("Период: с %s\n\n", formatCommissionFrom(balance.from)))
+	text.WriteString(fmt.Sprintf("Оплат: %d на %.2f ₽\n", balance.sales.SalesCount, balance.sales.Revenue))
+	text.WriteString(fmt.Sprintf("*К выплате: %.2f ₽*", balance.amount))
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ParseMode = "Markdown"
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+// HandleCallback обрабатывает админские com_pay:ID, com_payok:ID:unix, com_back и com_csv:YYYY-MM
+func (c *CommissionCommand) HandleCallback(ctx context.Context, adminTelegramID int64, callbackQuery *tgbotapi.CallbackQuery) error {
+	chatID := callbackQuery.Message.Chat.ID
+	messageID := callbackQuery.Message.MessageID
+	parts := strings.Split(callbackQuery.Data, ":")
+
+	switch parts[0] {
+	case "com_back":
+		_ = c.answerCallback(callbackQuery.ID, "")
+		return c.refresh(ctx, chatID, messageID)
+	case "com_pay":
+		if len(parts) != 2 {
+			return c.answerCallback(callbackQuery.ID, "Неверный формат")
+		}
+		assistantID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return c.answerCallback(callbackQuery.ID, "Неверный ID ассистента")
+		}
+		_ = c.answerCallback(callbackQuery.ID, "")
+		return c.confirmPayout(ctx, chatID, messageID, assistantID)
+	case "com_payok":
+		if len(parts) != 3 {
+			return c.answerCallback(callbackQuery.ID, "Неверный формат")
+		}
+		assistantID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return c.answerCallback(callbackQuery.ID, "Неверный ID ассистента")
+		}
+		unix, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return c.answerCallback(callbackQuery.ID, "Неверный период")
+		}
+		return c.settle(ctx, adminTelegramID, callbackQuery, assistantID, time.Unix(unix, 0).UTC())
+	case "com_csv":
+		if len(parts) != 2 {
+			return c.answerCallback(callbackQuery.ID, "Неверный формат")
+		}
+		month, err := time.Parse("2006-01", parts[1])
+		if err != nil {
+			return c.answerCallback(callbackQuery.ID, "Неверный месяц")
+		}
+		_ = c.answerCallback(callbackQuery.ID, "")
+		return c.sendCSV(ctx, chatID, month)
+	default:
+		return c.answerCallback(callbackQuery.ID, "Неизвестная команда")
+	}
+}
+
+// balance считает невыплаченную комиссию ассистента: продажи после последней выплаты и до to
+func (c *CommissionCommand) balance(ctx context.Context, assistantID int64, to time.Time) (commissionBalance, error) {
+	balance := commissionBalance{assistantID: assistantID, to: to, sales: storage.AssistantSales{AssistantTelegramID: assistantID}}
+
+	last, err := c.storage.GetLastCommissionSettlement(ctx, assistantID)
+	if err != nil {
+		return balance, fmt.Errorf("get last commission settlement: %w", err)
+	}
+	if last != nil {
+		balance.from = last.PeriodTo
+	}
+
+	sales, err := c.storage.GetAssistantSales(ctx, balance.from, to)
+	if err != nil {
+		return balance, fmt.Errorf("get assistant sales: %w", err)
+	}
+	for _, s := range sales {
+		if s.AssistantTelegramID == assistantID {
+			balance.sales = s
+		}
+	}
+	balance.amount = c.rate.Commission(balance.sales.Revenue, balance.sales.SalesCount)
+	return balance, nil
+}
+
+// adminScreen - невыплаченные комиссии всех сотрудников с кнопками выплаты и выгрузки
+func (c *CommissionCommand) adminScreen(ctx context.Context) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	now := c.now()
+
+	var text strings.Builder
+	text.WriteString("💼 *Комиссии ассистентов*\n\n")
+	text.WriteString(fmt.Sprintf("Ставка: %s\n\n", c.rate))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var total float64
+	for _, staffID := range uniqueIDs(c.staff.StaffIDs()) {
+		balance, err := c.balance(ctx, staffID, now)
+		if err != nil {
+			return "", tgbotapi.InlineKeyboardMarkup{}, err
+		}
+		if balance.sales.SalesCount == 0 {
+			continue
+		}
+		total += balance.amount
+
+		name := telegramUserName(c.bot, staffID)
+		text.WriteString(fmt.Sprintf("• %s: *%.2f ₽* (оплат: %d, с %s)\n",
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, name), balance.amount, balance.sales.SalesCount, formatCommissionFrom(balance.from)))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("💸 Выплачено: %s", name), fmt.Sprintf("com_pay:%d", staffID)),
+		))
+	}
+	if total == 0 {
+		text.WriteString("Невыплаченной комиссии нет\n")
+	} else {
+		text.WriteString(fmt.Sprintf("\n*Всего к выплате: %.2f ₽*\n", total))
+	}
+
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	previousMonth := currentMonth.AddDate(0, -1, 0)
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📥 "+formatMonth(previousMonth), "com_csv:"+previousMonth.Format("2006-01")),
+		tgbotapi.NewInlineKeyboardButtonData("📥 "+formatMonth(currentMonth), "com_csv:"+currentMonth.Format("2006-01")),
+	))
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+func (c *CommissionCommand) refresh(ctx context.Context, chatID int64, messageID int) error {
+	text, keyboard, err := c.adminScreen(ctx)
+	if err != nil {
+		return err
+	}
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = "Markdown"
+	edit.ReplyMarkup = &keyboard
+	return telegram.SafeEdit(c.bot, edit, "")
+}
+
+// confirmPayout фиксирует конец периода и сумму и спрашивает подтверждение выплаты
+func (c *CommissionCommand) confirmPayout(ctx context.Context, chatID int64, messageID int, assistantID int64) error {
+	to := c.now().Truncate(time.Second)
+	balance, err := c.balance(ctx, assistantID, to)
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("💸 Отметить выплату комиссии?\n\n"+
+		"Ассистент: %s\n"+
+		"Период: с %s по %s\n"+
+		"Оплат: %d на %.2f ₽\n"+
+		"Сумма: %.2f ₽",
+		telegramUserName(c.bot, assistantID),
+		formatCommissionFrom(balance.from), to.Format("02.01.2006 15:04"),
+		balance.sales.SalesCount, balance.sales.Revenue, balance.amount)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Выплачено", fmt.Sprintf("com_payok:%d:%d", assistantID, to.Unix())),
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Назад", "com_back"),
+		),
+	)
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ReplyMarkup = &keyboard
+	return telegram.SafeEdit(c.bot, edit, "")
+}
+
+// settle записывает выплату за продажи до to; повторное нажатие не создает вторую выплату
+func (c *CommissionCommand) settle(ctx context.Context, adminTelegramID int64, callbackQuery *tgbotapi.CallbackQuery, assistantID int64, to time.Time) error {
+	balance, err := c.balance(ctx, assistantID, to)
+	if err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка расчета")
+		return err
+	}
+	if !balance.from.Before(to) {
+		_ = c.answerCallback(callbackQuery.ID, "Выплата уже отмечена")
+		return c.refresh(ctx, callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID)
+	}
+
+	settlement, err := c.storage.CreateCommissionSettlement(ctx, commissions.Settlement{
+		AssistantTelegramID: assistantID,
+		PeriodFrom:          balance.from,
+		PeriodTo:            to,
+		Amount:              balance.amount,
+		CreatedByTelegramID: adminTelegramID,
+	})
+	if err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка сохранения")
+		return fmt.Errorf("create commission settlement: %w", err)
+	}
+
+	c.logger.Info("Commission settled",
+		"audit", true,
+		"admin_telegram_id", adminTelegramID,
+		"assistant_telegram_id", assistantID,
+		"settlement_id", settlement.ID,
+		"amount", settlement.Amount,
+		"sales", balance.sales.SalesCount,
+	)
+
+	_ = c.answerCallback(callbackQuery.ID, "✅ Выплата отмечена")
+	return c.refresh(ctx, callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID)
+}
+
+// sendCSV отправляет выгрузку комиссий за месяц для расчета с ассистентами
+func (c *CommissionCommand) sendCSV(ctx context.Context, chatID int64, month time.Time) error {
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	sales, err := c.storage.GetAssistantSales(ctx, from, from.AddDate(0, 1, 0))
+	if err != nil {
+		_, _ = c.bot.Send(tgbotapi.NewMessage(chatID, "Ошибка при выгрузке комиссий"))
+		return fmt.Errorf("get assistant sales: %w", err)
+	}
+
+	names := make(map[int64]string, len(sales))
+	for _, s := range sales {
+		names[s.AssistantTelegramID] = telegramUserName(c.bot, s.AssistantTelegramID)
+	}
+	data, err := buildCommissionCSV(sales, names, c.rate)
+	if err != nil {
+		return fmt.Errorf("build commission csv: %w", err)
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("commissions-%s.csv", from.Format("2006-01")),
+		Bytes: data,
+	})
+	doc.Caption = fmt.Sprintf("Комиссии ассистентов за %s (%s)", formatMonth(from), c.rate)
+	_, err = c.bot.Send(doc)
+	return err
+}
+
+func buildCommissionCSV(sales []storage.AssistantSales, names map[int64]string, rate commissions.Rate) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write([]string{"assistant_telegram_id", "assistant", "sales", "revenue", "commission"}); err != nil {
+		return nil, err
+	}
+	for _, s := range sales {
+		record := []string{
+			strconv.FormatInt(s.AssistantTelegramID, 10),
+			names[s.AssistantTelegramID],
+			strconv.Itoa(s.SalesCount),
+			strconv.FormatFloat(s.Revenue, 'f', 2, 64),
+			strconv.FormatFloat(rate.Commission(s.Revenue, s.SalesCount), 'f', 2, 64),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// formatCommissionFrom - начало периода комиссии; без выплат - с самого начала
+func formatCommissionFrom(from time.Time) string {
+	if from.IsZero() {
+		return "начала работы"
+	}
+	return from.Format("02.01.2006 15:04")
+}
+
+func formatMonth(month time.Time) string {
+	return fmt.Sprintf("%s %d", getMonthName(month.Month()), month.Year())
+}
+
+// uniqueIDs убирает повторы, сохраняя порядок: сотрудник может быть и ассистентом, и админом
+func uniqueIDs(ids []int64) []int64 {
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(result, id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func (c *CommissionCommand) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := c.bot.Request(callback)
+	return err
+}
@@ -0,0 +1,44 @@
+package cmds
+
+import (
+	"strings"
+	"testing"
+
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/commissions"
+)
+
+func TestBuildCommissionCSV(t *testing.T) {
+	sales := []storage.AssistantSales{
+		{AssistantTelegramID: 42, Revenue: 1500, SalesCount: 3},
+		{AssistantTelegramID: 7, Revenue: 299.5, SalesCount: 1},
+	}
+	names := map[int64]string{42: "@anna", 7: "Бекзат"}
+
+	data, err := buildCommissionCSV(sales, names, commissions.Rate{Percent: 10, FixedFee: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	want := []string{
+		"assistant_telegram_id,assistant,sales,revenue,commission",
+		"42,@anna,3,1500.00,210.00",
+		"7,Бекзат,1,299.50,49.95",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("csv lines = %d, want %d:\n%s", len(lines), len(want), data)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestUniqueIDs(t *testing.T) {
+	got := uniqueIDs([]int64{3, 1, 3, 2, 1})
+	if len(got) != 3 || got[0] != 3 || got[1] != 1 || got[2] != 2 {
+		t.Errorf("uniqueIDs() = %v, want [3 1 2]", got)
+	}
+}
@@ -0,0 +1,200 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/dbstats"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// dbStatsLargestTables - сколько самых больших таблиц показывать
+const dbStatsLargestTables = 10
+
+// dbStatsUserIDRenames - сколько переименованных user_id показывать
+const dbStatsUserIDRenames = 20
+
+// dbStatsStalePendingOrder - заказ в ожидании дольше этого срока скорее всего завис
+const dbStatsStalePendingOrder = 24 * time.Hour
+
+// DBStatsCommand показывает состояние базы SQLite и запускает ANALYZE и VACUUM (dbs_*)
+type DBStatsCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage DBStatsStorage
+	logger  *slog.Logger
+}
+
+type DBStatsStorage interface {
+	GetDBStats(ctx context.Context) (*dbstats.Stats, error)
+	VacuumDB(ctx context.Context) error
+	AnalyzeDB(ctx context.Context) error
+}
+
+func NewDBStatsCommand(bot *tgbotapi.BotAPI, storage DBStatsStorage, logger *slog.Logger) *DBStatsCommand {
+	return &DBStatsCommand{
+		bot:     bot,
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Execute отправляет сводку по базе
+func (c *DBStatsCommand) Execute(ctx context.Context, chatID int64) error {
+	return c.show(ctx, chatID, 0)
+}
+
+// HandleCallback обрабатывает dbs_refresh, dbs_analyze, dbs_vacuum (спрашивает подтверждение) и dbs_vacuum_ok
+func (c *DBStatsCommand) HandleCallback(ctx context.Context, adminTelegramID int64, query *tgbotapi.CallbackQuery) error {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+
+	switch query.Data {
+	case "dbs_refresh":
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		return c.show(ctx, chatID, messageID)
+	case "dbs_vacuum":
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("✅ Запустить VACUUM", "dbs_vacuum_ok"),
+				tgbotapi.NewInlineKeyboardButtonData("↩️ Назад", "dbs_refresh"),
+			),
+		)
+		editMsg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID,
+			"🧹 *VACUUM* пересобирает файл базы и возвращает свободное место.\n\n"+
+				"Пока он идет, бот не может записывать в базу: запускайте, когда нагрузка минимальна.", keyboard)
+		editMsg.ParseMode = "Markdown"
+		return telegram.SafeEdit(c.bot, editMsg, "")
+	case "dbs_vacuum_ok", "dbs_analyze":
+		command, run := "VACUUM", c.storage.VacuumDB
+		if query.Data == "dbs_analyze" {
+			command, run = "ANALYZE", c.storage.AnalyzeDB
+		}
+
+		started := time.Now()
+		if err := run(ctx); err != nil {
+			c.logger.Error("Failed to run database maintenance", "error", err, "command", command)
+			_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "❌ Ошибка "+command))
+			return nil
+		}
+		elapsed := time.Since(started).Round(time.Millisecond)
+
+		c.logger.Info("Database maintenance completed",
+			"audit", true,
+			"admin_telegram_id", adminTelegramID,
+			"command", command,
+			"elapsed", elapsed.String(),
+		)
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, fmt.Sprintf("✅ %s за %s", command, elapsed)))
+		return c.show(ctx, chatID, messageID)
+	default:
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "Неверный формат"))
+		return nil
+	}
+}
+
+func (c *DBStatsCommand) show(ctx context.Context, chatID int64, messageID int) error {
+	stats, err := c.storage.GetDBStats(ctx)
+	if err != nil {
+		c.logger.Error("Failed to get database stats", "error", err)
+		return c.send(chatID, "❌ Ошибка получения статистики базы")
+	}
+
+	text := formatDBStats(stats, time.Now().UTC())
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📊 ANALYZE", "dbs_analyze"),
+			tgbotapi.NewInlineKeyboardButtonData("🧹 VACUUM", "dbs_vacuum"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Обновить", "dbs_refresh"),
+		),
+	)
+
+	if messageID > 0 {
+		editMsg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard)
+		editMsg.ParseMode = "Markdown"
+		return telegram.SafeEdit(c.bot, editMsg, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+// formatDBStats - размеры файла, самые большие таблицы, самый старый заказ в ожидании
+// и user_id, переименованные миграцией
+func formatDBStats(stats *dbstats.Stats, now time.Time) string {
+	var text strings.Builder
+	text.WriteString("🗄 *База данных*\n\n")
+	if stats.Path != "" {
+		fmt.Fprintf(&text, "📁 `%s`\n", stats.Path)
+	}
+	fmt.Fprintf(&text, "💾 Размер: %s", dbstats.FormatBytes(stats.FileSize))
+	if stats.FreeSize > 0 {
+		fmt.Fprintf(&text, " (свободно %s, вернет VACUUM)", dbstats.FormatBytes(stats.FreeSize))
+	}
+	text.WriteString("\n")
+	if stats.JournalMode == "wal" {
+		fmt.Fprintf(&text, "📝 WAL: %s\n", dbstats.FormatBytes(stats.WALSize))
+	} else {
+		fmt.Fprintf(&text, "📝 Журнал: %s\n", stats.JournalMode)
+	}
+	fmt.Fprintf(&text, "📋 Таблиц: %d, строк: %d\n", len(stats.Tables), stats.TotalRows())
+
+	text.WriteString("\n⏳ Самый старый заказ в ожидании: ")
+	if stats.OldestPendingOrder == nil {
+		text.WriteString("нет\n")
+	} else {
+		age := now.Sub(*stats.OldestPendingOrder)
+		fmt.Fprintf(&text, "%s (%s назад)", stats.OldestPendingOrder.Format("02.01.2006 15:04"), formatDBStatsAge(age))
+		if age > dbStatsStalePendingOrder {
+			text.WriteString(" ⚠️")
+		}
+		text.WriteString("\n")
+	}
+
+	if len(stats.UserIDRenames) > 0 {
+		fmt.Fprintf(&text, "\n⚠️ *Переименованные ID пользователей: %d*\n", len(stats.UserIDRenames))
+		text.WriteString("Повторы исправлены миграцией, но на VPN-серверах пользователи остались под старыми именами - переименуйте их:\n")
+		for _, rename := range stats.UserIDRenames[:min(dbStatsUserIDRenames, len(stats.UserIDRenames))] {
+			fmt.Fprintf(&text, "#%d: `%s` → `%s`\n", rename.SubscriptionID, rename.OldUserID, rename.NewUserID)
+		}
+		if rest := len(stats.UserIDRenames) - dbStatsUserIDRenames; rest > 0 {
+			fmt.Fprintf(&text, "... и еще %d\n", rest)
+		}
+	}
+
+	text.WriteString("\n*Самые большие таблицы:*\n")
+	for _, table := range stats.Largest(dbStatsLargestTables) {
+		fmt.Fprintf(&text, "`%s` — %d\n", table.Name, table.Rows)
+	}
+
+	return strings.TrimRight(text.String(), "\n")
+}
+
+// formatDBStatsAge - возраст в днях, часах или минутах
+func formatDBStatsAge(age time.Duration) string {
+	switch {
+	case age >= 24*time.Hour:
+		return fmt.Sprintf("%d дн.", int(age.Hours()/24))
+	case age >= time.Hour:
+		return fmt.Sprintf("%d ч", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%d мин", int(age.Minutes()))
+	}
+}
+
+func (c *DBStatsCommand) send(chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	_, err := c.bot.Send(msg)
+	return err
+}
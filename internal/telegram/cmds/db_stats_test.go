@@ -0,0 +1,52 @@
+package cmds
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"kurut-bot/internal/stories/dbstats"
+)
+
+func TestFormatDBStats(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	oldest := now.Add(-50 * time.Hour)
+
+	text := formatDBStats(&dbstats.Stats{
+		Path:               "/data/kurut.db",
+		FileSize:           3 * 1024 * 1024,
+		FreeSize:           512 * 1024,
+		WALSize:            2048,
+		JournalMode:        "wal",
+		Tables:             []dbstats.TableRows{{Name: "users", Rows: 3}, {Name: "payments", Rows: 40}},
+		OldestPendingOrder: &oldest,
+	}, now)
+
+	for _, want := range []string{
+		"💾 Размер: 3.0 МБ (свободно 512.0 КБ, вернет VACUUM)",
+		"📝 WAL: 2.0 КБ",
+		"📋 Таблиц: 2, строк: 43",
+		"14.10.2026 10:00 (2 дн. назад) ⚠️",
+		"`payments` — 40\n`users` — 3",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("formatDBStats() misses %q:\n%s", want, text)
+		}
+	}
+
+	text = formatDBStats(&dbstats.Stats{JournalMode: "delete"}, now)
+	if !strings.Contains(text, "📝 Журнал: delete") || !strings.Contains(text, "в ожидании: нет") {
+		t.Errorf("formatDBStats() without WAL:\n%s", text)
+	}
+	if strings.Contains(text, "Переименованные") {
+		t.Errorf("formatDBStats() shows renames without any:\n%s", text)
+	}
+
+	text = formatDBStats(&dbstats.Stats{
+		JournalMode:   "wal",
+		UserIDRenames: []dbstats.UserIDRename{{SubscriptionID: 12, OldUserID: "10_881_3456", NewUserID: "10_881_3456_d12"}},
+	}, now)
+	if !strings.Contains(text, "Переименованные ID пользователей: 1") || !strings.Contains(text, "#12: `10_881_3456` → `10_881_3456_d12`") {
+		t.Errorf("formatDBStats() misses renames:\n%s", text)
+	}
+}
@@ -0,0 +1,415 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/escalations"
+	"kurut-bot/internal/stories/orders"
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/shortlinks"
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// escalationEvents - сколько последних событий показывать админам
+const escalationEvents = 8
+
+type EscalationStorage interface {
+	SearchSubscriptions(ctx context.Context, criteria subs.SearchCriteria) ([]storage.SubscriptionDetails, error)
+	ListSubscriptionPayments(ctx context.Context, subscriptionID int64) ([]*payment.Payment, error)
+	GetPendingOrderByID(ctx context.Context, id int64) (*orders.PendingOrder, error)
+	GetPayment(ctx context.Context, criteria payment.GetCriteria) (*payment.Payment, error)
+	GetLinkClickStats(ctx context.Context, criteria shortlinks.ClickCriteria) (*shortlinks.ClickStats, error)
+	CreateEscalation(ctx context.Context, subjectType escalations.SubjectType, subjectID, createdByTelegramID int64) (*escalations.Escalation, error)
+	GetEscalation(ctx context.Context, id int64) (*escalations.Escalation, error)
+	GetOpenEscalation(ctx context.Context, subjectType escalations.SubjectType, subjectID int64) (*escalations.Escalation, error)
+	TakeEscalation(ctx context.Context, id, assigneeTelegramID int64) (bool, error)
+	ResolveEscalation(ctx context.Context, id, adminTelegramID int64) (bool, error)
+}
+
+// EscalationCommand передает проблемную подписку или заказ админам с полным контекстом
+// и ведет эскалацию: админ берет ее в работу и отмечает решенной, ассистент получает уведомления
+type EscalationCommand struct {
+	bot        *tgbotapi.BotAPI
+	storage    EscalationStorage
+	adminChats adminChatsProvider
+	logger     *slog.Logger
+}
+
+func NewEscalationCommand(bot *tgbotapi.BotAPI, storage EscalationStorage, adminChats adminChatsProvider, logger *slog.Logger) *EscalationCommand {
+	return &EscalationCommand{
+		bot:        bot,
+		storage:    storage,
+		adminChats: adminChats,
+		logger:     logger,
+	}
+}
+
+// EscalationButton возвращает кнопку эскалации подписки админам
+func EscalationButton(subscriptionID int64) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("🆘 Эскалация", fmt.Sprintf("esc_sub:%d", subscriptionID))
+}
+
+// HandleCallback обрабатывает esc_sub:ID и esc_ord:ID от ассистента, esc_take:N и esc_done:N от админа
+func (c *EscalationCommand) HandleCallback(ctx context.Context, telegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	action, idStr, found := strings.Cut(callbackQuery.Data, ":")
+	if !found {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return c.answerCallback(callbackQuery.ID, "Неверный ID")
+	}
+
+	switch action {
+	case "esc_sub":
+		return c.escalate(ctx, telegramID, isAdmin, callbackQuery, escalations.SubjectSubscription, id)
+	case "esc_ord":
+		return c.escalate(ctx, telegramID, isAdmin, callbackQuery, escalations.SubjectOrder, id)
+	case "esc_take", "esc_done":
+		if !isAdmin {
+			return c.answerCallback(callbackQuery.ID, "❌ Нет прав")
+		}
+		if action == "esc_take" {
+			return c.take(ctx, telegramID, callbackQuery, id)
+		}
+		return c.resolve(ctx, telegramID, callbackQuery, id)
+	default:
+		return c.answerCallback(callbackQuery.ID, "Неизвестное действие")
+	}
+}
+
+// escalate собирает контекст и отправляет эскалацию в админский чат; повторная эскалация того же объекта не создается
+func (c *EscalationCommand) escalate(
+	ctx context.Context,
+	telegramID int64,
+	isAdmin bool,
+	callbackQuery *tgbotapi.CallbackQuery,
+	subjectType escalations.SubjectType,
+	subjectID int64,
+) error {
+	var subject string
+	var err error
+	switch subjectType {
+	case escalations.SubjectOrder:
+		subject, err = c.orderContext(ctx, telegramID, isAdmin, subjectID)
+	default:
+		subject, err = c.subscriptionContext(ctx, telegramID, isAdmin, subjectID)
+	}
+	if err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка")
+		return err
+	}
+	if subject == "" {
+		return c.answerCallback(callbackQuery.ID, "Не найдено")
+	}
+
+	existing, err := c.storage.GetOpenEscalation(ctx, subjectType, subjectID)
+	if err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка")
+		return fmt.Errorf("get open escalation: %w", err)
+	}
+	if existing != nil {
+		return c.answerCallback(callbackQuery.ID, fmt.Sprintf("Эскалация #%d уже у админов", existing.ID))
+	}
+
+	escalation, err := c.storage.CreateEscalation(ctx, subjectType, subjectID, telegramID)
+	if err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка сохранения")
+		return fmt.Errorf("create escalation: %w", err)
+	}
+
+	text := fmt.Sprintf("🆘 *Эскалация #%d*\nОт: %s\n\n%s",
+		escalation.ID, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, telegramUserName(c.bot, telegramID)), subject)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🙋 Беру", fmt.Sprintf("esc_take:%d", escalation.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("✅ Решено", fmt.Sprintf("esc_done:%d", escalation.ID)),
+		),
+	)
+
+	var delivered bool
+	for _, chatID := range c.adminChats.AdminChatIDs() {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = "Markdown"
+		msg.DisableWebPagePreview = true
+		msg.ReplyMarkup = keyboard
+		if _, err := c.bot.Send(msg); err != nil {
+			c.logger.Error("Failed to send escalation", "escalation_id", escalation.ID, "chat_id", chatID, "error", err)
+			continue
+		}
+		delivered = true
+	}
+	if !delivered {
+		// Эскалацию никто не увидит - закрываем, чтобы ассистент мог повторить
+		if _, err := c.storage.ResolveEscalation(ctx, escalation.ID, telegramID); err != nil {
+			c.logger.Error("Failed to close undelivered escalation", "escalation_id", escalation.ID, "error", err)
+		}
+		return c.answerCallback(callbackQuery.ID, "Не удалось отправить админам, попробуйте позже")
+	}
+
+	c.logger.Info("Escalation created",
+		"audit", true,
+		"telegram_id", telegramID,
+		"escalation_id", escalation.ID,
+		"subject_type", subjectType,
+		"subject_id", subjectID,
+	)
+
+	_ = c.answerCallback(callbackQuery.ID, "🆘 Передано админам")
+	reply := tgbotapi.NewMessage(callbackQuery.Message.Chat.ID,
+		fmt.Sprintf("🆘 Эскалация #%d отправлена админам. Сообщу, когда ее возьмут в работу.", escalation.ID))
+	reply.ReplyToMessageID = callbackQuery.Message.MessageID
+	_, err = c.bot.Send(reply)
+	return err
+}
+
+// take назначает эскалацию админу и уведомляет ассистента
+func (c *EscalationCommand) take(ctx context.Context, adminTelegramID int64, callbackQuery *tgbotapi.CallbackQuery, id int64) error {
+	taken, err := c.storage.TakeEscalation(ctx, id, adminTelegramID)
+	if err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка")
+		return fmt.Errorf("take escalation: %w", err)
+	}
+	escalation, err := c.storage.GetEscalation(ctx, id)
+	if err != nil || escalation == nil {
+		_ = c.answerCallback(callbackQuery.ID, "Эскалация не найдена")
+		return err
+	}
+
+	if !taken {
+		owner := "другой админ"
+		if escalation.AssigneeTelegramID != nil {
+			owner = telegramUserName(c.bot, *escalation.AssigneeTelegramID)
+		}
+		if escalation.Status == escalations.StatusResolved {
+			return c.answerCallback(callbackQuery.ID, "Эскалация уже решена")
+		}
+		return c.answerCallback(callbackQuery.ID, "Уже в работе: "+owner)
+	}
+
+	admin := telegramUserName(c.bot, adminTelegramID)
+	c.logger.Info("Escalation taken", "audit", true, "telegram_id", adminTelegramID, "escalation_id", id)
+	_ = c.answerCallback(callbackQuery.ID, "Эскалация ваша")
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ Решено (%s)", admin), fmt.Sprintf("esc_done:%d", id)),
+		),
+	)
+	if err := telegram.SafeEdit(c.bot, tgbotapi.NewEditMessageReplyMarkup(callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID, keyboard), ""); err != nil {
+		c.logger.Warn("Failed to update escalation keyboard", "escalation_id", id, "error", err)
+	}
+
+	c.notifyCreator(escalation, fmt.Sprintf("🙋 Эскалацию #%d взял в работу %s", id, admin))
+	return nil
+}
+
+// resolve закрывает эскалацию и уведомляет ассистента
+func (c *EscalationCommand) resolve(ctx context.Context, adminTelegramID int64, callbackQuery *tgbotapi.CallbackQuery, id int64) error {
+	resolved, err := c.storage.ResolveEscalation(ctx, id, adminTelegramID)
+	if err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка")
+		return fmt.Errorf("resolve escalation: %w", err)
+	}
+
+	// Кнопки больше не нужны - и при решении, и если эскалацию уже закрыли
+	empty := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	if err := telegram.SafeEdit(c.bot, tgbotapi.NewEditMessageReplyMarkup(callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID, empty), ""); err != nil {
+		c.logger.Warn("Failed to remove escalation keyboard", "escalation_id", id, "error", err)
+	}
+	if !resolved {
+		return c.answerCallback(callbackQuery.ID, "Эскалация уже решена")
+	}
+
+	escalation, err := c.storage.GetEscalation(ctx, id)
+	if err != nil || escalation == nil {
+		_ = c.answerCallback(callbackQuery.ID, "Эскалация не найдена")
+		return err
+	}
+
+	admin := telegramUserName(c.bot, adminTelegramID)
+	c.logger.Info("Escalation resolved", "audit", true, "telegram_id", adminTelegramID, "escalation_id", id)
+	_ = c.answerCallback(callbackQuery.ID, "✅ Решено")
+
+	reply := tgbotapi.NewMessage(callbackQuery.Message.Chat.ID, fmt.Sprintf("✅ Эскалация #%d решена (%s)", id, admin))
+	reply.ReplyToMessageID = callbackQuery.Message.MessageID
+	if _, err := c.bot.Send(reply); err != nil {
+		c.logger.Warn("Failed to send escalation resolution", "escalation_id", id, "error", err)
+	}
+
+	c.notifyCreator(escalation, fmt.Sprintf("✅ Эскалация #%d решена, отметил %s", id, admin))
+	return nil
+}
+
+func (c *EscalationCommand) notifyCreator(escalation *escalations.Escalation, text string) {
+	if _, err := c.bot.Send(tgbotapi.NewMessage(escalation.CreatedByTelegramID, text)); err != nil {
+		c.logger.Warn("Failed to notify escalation creator",
+			"escalation_id", escalation.ID, "telegram_id", escalation.CreatedByTelegramID, "error", err)
+	}
+}
+
+// subscriptionContext описывает подписку для админов: клиент, тариф, платежи и последние события.
+// Пустая строка - подписки нет или она чужая для ассистента
+func (c *EscalationCommand) subscriptionContext(ctx context.Context, telegramID int64, isAdmin bool, subID int64) (string, error) {
+	criteria := subs.SearchCriteria{ID: &subID, Limit: 1}
+	if !isAdmin {
+		criteria.CreatedByTelegramID = &telegramID
+	}
+	results, err := c.storage.SearchSubscriptions(ctx, criteria)
+	if err != nil {
+		return "", fmt.Errorf("search subscription: %w", err)
+	}
+	if len(results) == 0 {
+		return "", nil
+	}
+	details := results[0]
+	sub := details.Subscription
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📋 *%s #%d*\n", escalations.SubjectSubscription.Title(), sub.ID)
+	writeEscalationClient(&b, sub.ClientWhatsApp)
+	fmt.Fprintf(&b, "📌 Статус: %s\n", subscriptionStatusLabel(sub.Status))
+	fmt.Fprintf(&b, "📅 Тариф: %s (%.0f ₽)\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, details.TariffName), details.TariffPrice)
+	if sub.ExpiresAt != nil {
+		fmt.Fprintf(&b, "⏳ До: %s\n", sub.ExpiresAt.Format("02.01.2006"))
+	}
+	if details.ServerName != nil {
+		fmt.Fprintf(&b, "🖥 Сервер: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *details.ServerName))
+	}
+	if sub.GeneratedUserID != nil {
+		fmt.Fprintf(&b, "🆔 Пользователь: `%s`\n", *sub.GeneratedUserID)
+	}
+	if sub.CreatedByTelegramID != nil {
+		fmt.Fprintf(&b, "👤 Создал: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, telegramUserName(c.bot, *sub.CreatedByTelegramID)))
+	}
+
+	events := []escalations.Event{{At: sub.CreatedAt, Text: "подписка создана"}}
+	if sub.ActivatedAt != nil {
+		events = append(events, escalations.Event{At: *sub.ActivatedAt, Text: "подписка активирована"})
+	}
+	if sub.LastRenewedAt != nil {
+		events = append(events, escalations.Event{At: *sub.LastRenewedAt, Text: fmt.Sprintf("продлена (всего продлений: %d)", sub.RenewalCount)})
+	}
+
+	payments, err := c.storage.ListSubscriptionPayments(ctx, sub.ID)
+	if err != nil {
+		c.logger.Error("Failed to list payments for escalation", "error", err, "sub_id", sub.ID)
+	}
+	b.WriteString("\n*Платежи:*\n")
+	if len(payments) == 0 {
+		b.WriteString("нет привязанных платежей\n")
+	}
+	for i, p := range payments {
+		if i >= subViewPayments {
+			fmt.Fprintf(&b, "…и ещё %d\n", len(payments)-subViewPayments)
+			break
+		}
+		fmt.Fprintf(&b, "#%d — %.0f ₽, %s\n", p.ID, p.Amount, formatPaymentStatus(p.Status))
+		events = append(events, escalations.Event{At: p.CreatedAt, Text: fmt.Sprintf("платеж #%d на %.0f ₽", p.ID, p.Amount)})
+	}
+
+	clicks, err := c.storage.GetLinkClickStats(ctx, shortlinks.ClickCriteria{SubscriptionID: &sub.ID})
+	if err != nil {
+		c.logger.Error("Failed to get link clicks for escalation", "error", err, "sub_id", sub.ID)
+	} else if clicks.LastClickedAt != nil {
+		events = append(events, escalations.Event{At: *clicks.LastClickedAt, Text: fmt.Sprintf("клиент открыл ссылку (всего %d)", clicks.Count)})
+	}
+
+	writeEscalationEvents(&b, events)
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// orderContext описывает заказ для админов: клиент, сумма, платеж и последние события.
+// Пустая строка - заказа нет или он чужой для ассистента
+func (c *EscalationCommand) orderContext(ctx context.Context, telegramID int64, isAdmin bool, orderID int64) (string, error) {
+	order, err := c.storage.GetPendingOrderByID(ctx, orderID)
+	if err != nil {
+		return "", fmt.Errorf("get pending order: %w", err)
+	}
+	if order == nil || (!isAdmin && order.AssistantTelegramID != telegramID) {
+		return "", nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "💳 *%s #%d*\n", escalations.SubjectOrder.Title(), order.ID)
+	writeEscalationClient(&b, &order.ClientWhatsApp)
+	fmt.Fprintf(&b, "📌 Статус: %s\n", orderStatusLabel(order.Status))
+	fmt.Fprintf(&b, "📅 Тариф: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, order.TariffName))
+	fmt.Fprintf(&b, "💰 Сумма: %.0f ₽\n", order.TotalAmount)
+	if order.ServerName != nil {
+		fmt.Fprintf(&b, "🔀 Миграция на сервер: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *order.ServerName))
+	}
+	fmt.Fprintf(&b, "👤 Ассистент: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, telegramUserName(c.bot, order.AssistantTelegramID)))
+
+	events := []escalations.Event{{At: order.CreatedAt, Text: "заказ создан"}}
+	if order.LinkRefreshedAt != nil {
+		events = append(events, escalations.Event{At: *order.LinkRefreshedAt, Text: fmt.Sprintf("ссылка обновлена (всего %d)", order.LinkRefreshCount)})
+	}
+	if order.Status != orders.StatusPending {
+		events = append(events, escalations.Event{At: order.UpdatedAt, Text: "заказ " + orderStatusLabel(order.Status)})
+	}
+
+	pay, err := c.storage.GetPayment(ctx, payment.GetCriteria{ID: &order.PaymentID})
+	if err != nil {
+		c.logger.Error("Failed to get payment for escalation", "error", err, "order_id", order.ID)
+	}
+	if pay != nil {
+		fmt.Fprintf(&b, "\n*Платеж:* #%d — %.0f ₽, %s\n", pay.ID, pay.Amount, formatPaymentStatus(pay.Status))
+		if pay.Status != payment.StatusPending {
+			events = append(events, escalations.Event{At: pay.UpdatedAt, Text: "платеж " + formatPaymentStatus(pay.Status)})
+		}
+	} else {
+		fmt.Fprintf(&b, "\n*Платеж:* #%d не найден\n", order.PaymentID)
+	}
+
+	clicks, err := c.storage.GetLinkClickStats(ctx, shortlinks.ClickCriteria{OrderID: &order.ID})
+	if err != nil {
+		c.logger.Error("Failed to get link clicks for escalation", "error", err, "order_id", order.ID)
+	} else if clicks.LastClickedAt != nil {
+		events = append(events, escalations.Event{At: *clicks.LastClickedAt, Text: fmt.Sprintf("клиент открыл ссылку (всего %d)", clicks.Count)})
+	}
+
+	writeEscalationEvents(&b, events)
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+func writeEscalationClient(b *strings.Builder, clientWhatsApp *string) {
+	if clientWhatsApp == nil || *clientWhatsApp == "" {
+		b.WriteString("📱 Клиент: не указан\n")
+		return
+	}
+	fmt.Fprintf(b, "📱 Клиент: [%s](%s)\n", *clientWhatsApp, GenerateWhatsAppLink(*clientWhatsApp, ""))
+}
+
+func writeEscalationEvents(b *strings.Builder, events []escalations.Event) {
+	b.WriteString("\n*Последние события:*\n")
+	for _, e := range escalations.Recent(events, escalationEvents) {
+		fmt.Fprintf(b, "%s — %s\n", e.At.Format("02.01 15:04"), e.Text)
+	}
+}
+
+func orderStatusLabel(status orders.Status) string {
+	switch status {
+	case orders.StatusPending:
+		return "⏳ ожидает оплаты"
+	case orders.StatusCompleted:
+		return "✅ выполнен"
+	case orders.StatusCancelled:
+		return "🚫 отменен"
+	default:
+		return string(status)
+	}
+}
+
+func (c *EscalationCommand) answerCallback(callbackID, text string) error {
+	_, err := c.bot.Request(tgbotapi.NewCallback(callbackID, text))
+	return err
+}
@@ -7,12 +7,16 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"kurut-bot/internal/infra/telegram"
 	"kurut-bot/internal/stories/payment"
 	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/shortlinks"
 	"kurut-bot/internal/stories/submessages"
 	"kurut-bot/internal/stories/subs"
 	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/stories/watemplates"
 	"kurut-bot/internal/telegram/messages"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -38,6 +42,7 @@ type ExpirationSubStorage interface {
 	GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error)
 	ExtendSubscription(ctx context.Context, subscriptionID int64, additionalDays int) error
 	UpdateSubscriptionTariff(ctx context.Context, subscriptionID int64, tariffID int64) error
+	GetLinkClickStats(ctx context.Context, criteria shortlinks.ClickCriteria) (*shortlinks.ClickStats, error)
 }
 
 type ExpirationServerStorage interface {
@@ -48,10 +53,11 @@ type ExpirationServerStorage interface {
 type ExpirationTariffService interface {
 	GetTariff(ctx context.Context, criteria tariffs.GetCriteria) (*tariffs.Tariff, error)
 	GetActiveTariffs(ctx context.Context) ([]*tariffs.Tariff, error)
+	GetRenewalTariff(ctx context.Context, tariffID int64) (*tariffs.Tariff, error)
 }
 
 type ExpirationPaymentService interface {
-	CreatePayment(ctx context.Context, p payment.Payment) (*payment.Payment, error)
+	CreatePaymentWithMeta(ctx context.Context, p payment.Payment, meta payment.CreatePaymentMeta) (*payment.Payment, error)
 	CheckPaymentStatus(ctx context.Context, paymentID int64) (*payment.Payment, error)
 	IsManualPayment() bool
 }
@@ -356,7 +362,7 @@ func (c *ExpirationCommand) updateToDisabledMessage(ctx context.Context, chatID
 	price := 0.0
 	if tariff != nil {
 		tariffName = tariff.Name
-		price = tariff.Price
+		price, _ = renewalAmount(sub, tariff, c.notificationService.subServer(ctx, sub))
 	}
 
 	// Формируем строку пароля если есть сервер
@@ -368,7 +374,8 @@ func (c *ExpirationCommand) updateToDisabledMessage(ctx context.Context, chatID
 	// Формируем текст со ссылкой на WhatsApp в номере клиента
 	var text string
 	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
-		whatsappLink := generateWhatsAppLink(*sub.ClientWhatsApp, "Здравствуйте! Ваша подписка VPN истекла. Для продолжения работы необходимо оплатить подписку.")
+		lang := c.notificationService.clientLanguage(ctx, *sub.ClientWhatsApp)
+		whatsappLink := generateWhatsAppLink(*sub.ClientWhatsApp, messages.WhatsAppTextWithVars(lang, messages.WATemplateDisabled, subWhatsAppVars(sub, tariffName)))
 		text = fmt.Sprintf(
 			"⏸ *Подписка отключена*\n\n"+
 				"📱 Клиент: [%s](%s)\n"+
@@ -382,7 +389,7 @@ func (c *ExpirationCommand) updateToDisabledMessage(ctx context.Context, chatID
 			whatsapp, tariffName, price, passwordLine)
 	}
 
-	// Кнопки после отключения: Сменить тариф, Ссылка/Оплачено
+	// Кнопки после отключения: Сменить тариф, Ссылка/Оплачено, Создать как эта
 	var rows [][]tgbotapi.InlineKeyboardButton
 
 	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
@@ -392,6 +399,9 @@ func (c *ExpirationCommand) updateToDisabledMessage(ctx context.Context, chatID
 		tgbotapi.NewInlineKeyboardButtonData("🔗 Ссылка", fmt.Sprintf("exp_link:%d", sub.ID)),
 		tgbotapi.NewInlineKeyboardButtonData(c.paidButtonText(), fmt.Sprintf("exp_paid:%d", sub.ID)),
 	))
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📄 Создать как эта", fmt.Sprintf("clone_sub:%d", sub.ID)),
+	))
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
@@ -399,7 +409,7 @@ func (c *ExpirationCommand) updateToDisabledMessage(ctx context.Context, chatID
 	editMsg.ParseMode = "Markdown"
 	editMsg.ReplyMarkup = &keyboard
 	editMsg.DisableWebPagePreview = true
-	_, err := c.bot.Send(editMsg)
+	err := telegram.SafeEdit(c.bot, editMsg, "")
 
 	// Деактивируем все другие сообщения для этой подписки
 	c.deactivateOtherMessages(ctx, sub.ID, chatID, messageID)
@@ -427,10 +437,7 @@ func (c *ExpirationCommand) handleCreatePayment(ctx context.Context, callbackQue
 	// 2. Проверить selected_tariff из сообщения
 	subMsg, _ := c.messageStorage.GetSubscriptionMessageByChatAndMessageID(ctx, chatID, messageID)
 
-	tariffID := sub.TariffID
-	if subMsg != nil && subMsg.SelectedTariffID != nil {
-		tariffID = *subMsg.SelectedTariffID
-	}
+	tariffID := c.renewalTariffID(ctx, sub, subMsg)
 
 	// 3. Получить тариф для определения цены
 	tariff, err := c.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &tariffID})
@@ -439,14 +446,19 @@ func (c *ExpirationCommand) handleCreatePayment(ctx context.Context, callbackQue
 		return c.answerCallback(callbackQuery.ID, "Тариф не найден")
 	}
 
+	// Индивидуальная цена подписки важнее цены тарифа; к цене тарифа добавляется наценка сервера
+	price, base := renewalAmount(sub, tariff, c.notificationService.subServer(ctx, sub))
+
 	// 4. Создать платеж
 	paymentEntity := payment.Payment{
-		UserID: sub.UserID,
-		Amount: tariff.Price,
-		Status: payment.StatusPending,
+		UserID:     sub.UserID,
+		Amount:     price,
+		Status:     payment.StatusPending,
+		BaseAmount: base,
+		ServerID:   sub.ServerID,
 	}
 
-	paymentObj, err := c.paymentService.CreatePayment(ctx, paymentEntity)
+	paymentObj, err := c.paymentService.CreatePaymentWithMeta(ctx, paymentEntity, subPaymentMeta(sub, tariff.Name))
 	if err != nil {
 		c.logger.Error("Failed to create payment", "error", err, "sub_id", subID)
 		return c.answerCallback(callbackQuery.ID, "Ошибка создания платежа")
@@ -476,14 +488,15 @@ func (c *ExpirationCommand) handleCreatePayment(ctx context.Context, callbackQue
 	// Формируем текст со ссылкой как кликабельный alias "link"
 	var text string
 	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
-		whatsappLink := generateWhatsAppLink(*sub.ClientWhatsApp, messages.WhatsAppMsgExpired)
+		lang := c.notificationService.clientLanguage(ctx, *sub.ClientWhatsApp)
+		whatsappLink := generateWhatsAppLink(*sub.ClientWhatsApp, messages.WhatsAppText(lang, messages.WATemplateExpired))
 		text = fmt.Sprintf(
 			"💳 *Ссылка на оплату*\n\n"+
 				"📱 Клиент: [%s](%s)\n"+
 				"📅 Тариф: %s\n"+
 				"💰 Сумма: %.0f ₽\n\n"+
 				"🔗 [link](%s)",
-			whatsapp, whatsappLink, tariff.Name, tariff.Price, *paymentObj.PaymentURL)
+			whatsapp, whatsappLink, tariff.Name, price, *paymentObj.PaymentURL)
 	} else {
 		text = fmt.Sprintf(
 			"💳 *Ссылка на оплату*\n\n"+
@@ -491,7 +504,7 @@ func (c *ExpirationCommand) handleCreatePayment(ctx context.Context, callbackQue
 				"📅 Тариф: %s\n"+
 				"💰 Сумма: %.0f ₽\n\n"+
 				"🔗 [link](%s)",
-			whatsapp, tariff.Name, tariff.Price, *paymentObj.PaymentURL)
+			whatsapp, tariff.Name, price, *paymentObj.PaymentURL)
 	}
 
 	// Кнопки: Сменить тариф, Новый, Оплачено/Проверить
@@ -510,7 +523,7 @@ func (c *ExpirationCommand) handleCreatePayment(ctx context.Context, callbackQue
 	editMsg.ParseMode = "Markdown"
 	editMsg.ReplyMarkup = &keyboard
 	editMsg.DisableWebPagePreview = true
-	_, err = c.bot.Send(editMsg)
+	err = telegram.SafeEdit(c.bot, editMsg, "")
 
 	// Сохраняем payment_id в subscription_message для последующей проверки
 	if subMsg != nil {
@@ -542,9 +555,8 @@ func (c *ExpirationCommand) handleCheckPayment(ctx context.Context, callbackQuer
 	// 2. Проверить selected_tariff из сообщения
 	subMsg, _ := c.messageStorage.GetSubscriptionMessageByChatAndMessageID(ctx, chatID, messageID)
 
-	tariffID := sub.TariffID
-	if subMsg != nil && subMsg.SelectedTariffID != nil {
-		tariffID = *subMsg.SelectedTariffID
+	tariffID := c.renewalTariffID(ctx, sub, subMsg)
+	if tariffID != sub.TariffID {
 		// Обновляем тариф подписки
 		if err := c.subStorage.UpdateSubscriptionTariff(ctx, subID, tariffID); err != nil {
 			c.logger.Error("Failed to update subscription tariff", "error", err, "sub_id", subID, "tariff_id", tariffID)
@@ -562,12 +574,15 @@ func (c *ExpirationCommand) handleCheckPayment(ctx context.Context, callbackQuer
 	if c.paymentService.IsManualPayment() {
 		// Mock режим: создаём approved платёж если не было ссылки
 		if subMsg == nil || subMsg.PaymentID == nil {
+			price, base := renewalAmount(sub, tariff, c.notificationService.subServer(ctx, sub))
 			paymentEntity := payment.Payment{
-				UserID: sub.UserID,
-				Amount: tariff.Price,
-				Status: payment.StatusPending,
+				UserID:     sub.UserID,
+				Amount:     price,
+				Status:     payment.StatusPending,
+				BaseAmount: base,
+				ServerID:   sub.ServerID,
 			}
-			_, err := c.paymentService.CreatePayment(ctx, paymentEntity)
+			_, err := c.paymentService.CreatePaymentWithMeta(ctx, paymentEntity, subPaymentMeta(sub, tariff.Name))
 			if err != nil {
 				c.logger.Error("Failed to create payment", "error", err, "sub_id", subID)
 				return c.answerCallback(callbackQuery.ID, "Ошибка создания платежа")
@@ -586,7 +601,13 @@ func (c *ExpirationCommand) handleCheckPayment(ctx context.Context, callbackQuer
 			return c.answerCallback(callbackQuery.ID, "Ошибка проверки платежа")
 		}
 		if paymentObj.Status != payment.StatusApproved {
-			alertConfig := tgbotapi.NewCallbackWithAlert(callbackQuery.ID, "⏳ Платёж ещё не оплачен")
+			alertText := "⏳ Платёж ещё не оплачен"
+			if stats, err := c.subStorage.GetLinkClickStats(ctx, shortlinks.ClickCriteria{SubscriptionID: &subID}); err != nil {
+				c.logger.Warn("Failed to get link click stats", "error", err, "sub_id", subID)
+			} else {
+				alertText += "\n\n" + stats.Summary(time.Now())
+			}
+			alertConfig := tgbotapi.NewCallbackWithAlert(callbackQuery.ID, alertText)
 			_, _ = c.bot.Request(alertConfig)
 			return nil
 		}
@@ -642,7 +663,8 @@ func (c *ExpirationCommand) updateToRenewedMessage(ctx context.Context, chatID i
 	// Формируем текст со ссылкой на WhatsApp в номере клиента
 	var text string
 	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
-		whatsappLink := generateWhatsAppLink(*sub.ClientWhatsApp, "Ваша подписка VPN продлена!")
+		lang := c.notificationService.clientLanguage(ctx, *sub.ClientWhatsApp)
+		whatsappLink := generateWhatsAppLink(*sub.ClientWhatsApp, messages.WhatsAppText(lang, messages.WATemplateRenewed))
 		text = fmt.Sprintf(
 			"✅ *Подписка продлена!*\n\n"+
 				"📱 Клиент: [%s](%s)\n"+
@@ -657,6 +679,9 @@ func (c *ExpirationCommand) updateToRenewedMessage(ctx context.Context, chatID i
 				"⏱ Продлено на: %d дней%s",
 			whatsapp, tariff.Name, tariff.DurationDays, passwordLine)
 	}
+	if tariff.TrafficLimitGB != nil {
+		text += "\n" + FormatTrafficQuota(tariff.TrafficLimitGB, sub.ExtraTrafficGB)
+	}
 
 	// Кнопка для перехода на сервер - только если подписка была отключена
 	var rows [][]tgbotapi.InlineKeyboardButton
@@ -665,6 +690,10 @@ func (c *ExpirationCommand) updateToRenewedMessage(ctx context.Context, chatID i
 			tgbotapi.NewInlineKeyboardButtonURL("🌐 Сервер", server.UIURL),
 		))
 	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(CalendarButton(sub.ID)))
+	if tariff.TrafficLimitGB != nil {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(TrafficTopUpButton(sub.ID)))
+	}
 
 	var keyboard *tgbotapi.InlineKeyboardMarkup
 	if len(rows) > 0 {
@@ -676,7 +705,7 @@ func (c *ExpirationCommand) updateToRenewedMessage(ctx context.Context, chatID i
 	editMsg.ParseMode = "Markdown"
 	editMsg.ReplyMarkup = keyboard
 	editMsg.DisableWebPagePreview = true
-	_, err := c.bot.Send(editMsg)
+	err := telegram.SafeEdit(c.bot, editMsg, "")
 
 	// Деактивируем все сообщения для этой подписки
 	c.deactivateAllMessages(ctx, sub.ID)
@@ -720,10 +749,16 @@ func (c *ExpirationCommand) handleShowTariffs(ctx context.Context, callbackQuery
 
 	text := fmt.Sprintf("📋 *Выберите тариф для продления*\n\n📱 Клиент: `%s`", whatsapp)
 
+	// Цены тарифов - с наценкой сервера подписки
+	var server *servers.Server
+	if sub != nil {
+		server = c.notificationService.subServer(ctx, sub)
+	}
+
 	// Создаем кнопки с тарифами
 	var rows [][]tgbotapi.InlineKeyboardButton
 	for _, t := range tariffsList {
-		buttonText := fmt.Sprintf("%s - %.0f ₽ (%d дн.)", t.Name, t.Price, t.DurationDays)
+		buttonText := fmt.Sprintf("%s - %.0f ₽ (%d дн.)", t.Name, server.EffectivePrice(t.Price), t.DurationDays)
 		callbackData := fmt.Sprintf("exp_set_tariff:%d:%d", subID, t.ID)
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(buttonText, callbackData),
@@ -741,8 +776,7 @@ func (c *ExpirationCommand) handleShowTariffs(ctx context.Context, callbackQuery
 	editMsg.ParseMode = "Markdown"
 	editMsg.ReplyMarkup = &keyboard
 	editMsg.DisableWebPagePreview = true
-	_, err = c.bot.Send(editMsg)
-	return err
+	return telegram.SafeEdit(c.bot, editMsg, "")
 }
 
 // handleSetTariff - установка нового тарифа
@@ -793,21 +827,24 @@ func (c *ExpirationCommand) handleSetTariff(ctx context.Context, callbackQuery *
 		msgType = subMsg.Type
 	}
 
+	price, _ := renewalAmount(sub, tariff, c.notificationService.subServer(ctx, sub))
+
 	var text string
 	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
-		whatsappLink := generateWhatsAppLink(*sub.ClientWhatsApp, messages.WhatsAppMsgToday)
+		lang := c.notificationService.clientLanguage(ctx, *sub.ClientWhatsApp)
+		whatsappLink := generateWhatsAppLink(*sub.ClientWhatsApp, messages.WhatsAppTextWithVars(lang, messages.WATemplateToday, subWhatsAppVars(sub, tariff.Name)))
 		if msgType == submessages.TypeOverdue {
 			text = fmt.Sprintf(
 				"⏸ *Подписка отключена*\n\n"+
 					"📱 Клиент: [%s](%s)\n"+
 					"📅 *Новый тариф: %s (%.0f ₽)*",
-				whatsapp, whatsappLink, tariff.Name, tariff.Price)
+				whatsapp, whatsappLink, tariff.Name, price)
 		} else {
 			text = fmt.Sprintf(
 				"🔔 *Подписка истекает сегодня*\n\n"+
 					"📱 Клиент: [%s](%s)\n"+
 					"📅 *Новый тариф: %s (%.0f ₽)*",
-				whatsapp, whatsappLink, tariff.Name, tariff.Price)
+				whatsapp, whatsappLink, tariff.Name, price)
 		}
 	} else {
 		if msgType == submessages.TypeOverdue {
@@ -815,13 +852,13 @@ func (c *ExpirationCommand) handleSetTariff(ctx context.Context, callbackQuery *
 				"⏸ *Подписка отключена*\n\n"+
 					"📱 Клиент: `%s`\n"+
 					"📅 *Новый тариф: %s (%.0f ₽)*",
-				whatsapp, tariff.Name, tariff.Price)
+				whatsapp, tariff.Name, price)
 		} else {
 			text = fmt.Sprintf(
 				"🔔 *Подписка истекает сегодня*\n\n"+
 					"📱 Клиент: `%s`\n"+
 					"📅 *Новый тариф: %s (%.0f ₽)*",
-				whatsapp, tariff.Name, tariff.Price)
+				whatsapp, tariff.Name, price)
 		}
 	}
 
@@ -841,8 +878,7 @@ func (c *ExpirationCommand) handleSetTariff(ctx context.Context, callbackQuery *
 	editMsg.ParseMode = "Markdown"
 	editMsg.ReplyMarkup = &keyboard
 	editMsg.DisableWebPagePreview = true
-	_, err = c.bot.Send(editMsg)
-	return err
+	return telegram.SafeEdit(c.bot, editMsg, "")
 }
 
 // handleShowServer - показать сервер
@@ -881,10 +917,7 @@ func (c *ExpirationCommand) handleTariffBack(ctx context.Context, callbackQuery
 	subMsg, _ := c.messageStorage.GetSubscriptionMessageByChatAndMessageID(ctx, chatID, messageID)
 
 	// Получить тариф (используем selected_tariff если есть)
-	tariffID := sub.TariffID
-	if subMsg != nil && subMsg.SelectedTariffID != nil {
-		tariffID = *subMsg.SelectedTariffID
-	}
+	tariffID := c.renewalTariffID(ctx, sub, subMsg)
 
 	tariff, _ := c.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &tariffID})
 
@@ -906,6 +939,23 @@ func (c *ExpirationCommand) handleTariffBack(ctx context.Context, callbackQuery
 	return c.updateToExpiringMessage(ctx, chatID, messageID, sub, tariff)
 }
 
+// renewalTariffID возвращает тариф продления: выбранный в сообщении, иначе тариф подписки.
+// Подписка на закончившемся промо-тарифе продлевается на тариф, указанный у промо
+func (c *ExpirationCommand) renewalTariffID(ctx context.Context, sub *subs.Subscription, subMsg *submessages.SubscriptionMessage) int64 {
+	if subMsg != nil && subMsg.SelectedTariffID != nil {
+		return *subMsg.SelectedTariffID
+	}
+	tariff, err := c.tariffService.GetRenewalTariff(ctx, sub.TariffID)
+	if err != nil {
+		c.logger.Error("Failed to get renewal tariff", "error", err, "sub_id", sub.ID, "tariff_id", sub.TariffID)
+		return sub.TariffID
+	}
+	if tariff == nil {
+		return sub.TariffID
+	}
+	return tariff.ID
+}
+
 // updateToExpiringMessage обновляет сообщение обратно к формату истекающей подписки
 func (c *ExpirationCommand) updateToExpiringMessage(ctx context.Context, chatID int64, messageID int, sub *subs.Subscription, tariff *tariffs.Tariff) error {
 	whatsapp := "Не указан"
@@ -917,13 +967,14 @@ func (c *ExpirationCommand) updateToExpiringMessage(ctx context.Context, chatID
 	price := 0.0
 	if tariff != nil {
 		tariffName = tariff.Name
-		price = tariff.Price
+		price, _ = renewalAmount(sub, tariff, c.notificationService.subServer(ctx, sub))
 	}
 
 	// Формируем текст со ссылкой на WhatsApp в номере клиента
 	var text string
 	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
-		whatsappLink := generateWhatsAppLink(*sub.ClientWhatsApp, messages.WhatsAppMsgToday)
+		lang := c.notificationService.clientLanguage(ctx, *sub.ClientWhatsApp)
+		whatsappLink := generateWhatsAppLink(*sub.ClientWhatsApp, messages.WhatsAppTextWithVars(lang, messages.WATemplateToday, subWhatsAppVars(sub, tariffName)))
 		text = fmt.Sprintf(
 			"🔔 *Подписка истекает сегодня*\n\n"+
 				"📱 Клиент: [%s](%s)\n"+
@@ -936,6 +987,9 @@ func (c *ExpirationCommand) updateToExpiringMessage(ctx context.Context, chatID
 				"📅 Тариф: %s (%.0f ₽)",
 			whatsapp, tariffName, price)
 	}
+	if sub.Note != "" {
+		text += "\n" + formatSubNote(sub.Note)
+	}
 
 	// Кнопки: Сменить тариф, Ссылка/Оплачено, Отказ
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
@@ -952,8 +1006,7 @@ func (c *ExpirationCommand) updateToExpiringMessage(ctx context.Context, chatID
 	editMsg.ParseMode = "Markdown"
 	editMsg.ReplyMarkup = &keyboard
 	editMsg.DisableWebPagePreview = true
-	_, err := c.bot.Send(editMsg)
-	return err
+	return telegram.SafeEdit(c.bot, editMsg, "")
 }
 
 // checkMessageActive проверяет, активно ли сообщение
@@ -977,7 +1030,7 @@ func (c *ExpirationCommand) markMessageOutdated(chatID int64, messageID int, cal
 	text := "⚠️ *Это сообщение устарело*\n\nПодписка уже была обработана через другое сообщение."
 	editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text)
 	editMsg.ParseMode = "Markdown"
-	_, _ = c.bot.Send(editMsg)
+	_ = telegram.SafeEdit(c.bot, editMsg, "")
 	return nil
 }
 
@@ -1004,7 +1057,7 @@ func (c *ExpirationCommand) deactivateOtherMessages(ctx context.Context, subscri
 		text := "⚠️ *Это сообщение устарело*\n\nПодписка уже была обработана через другое сообщение."
 		editMsg := tgbotapi.NewEditMessageText(msg.ChatID, msg.MessageID, text)
 		editMsg.ParseMode = "Markdown"
-		_, _ = c.bot.Send(editMsg)
+		_ = telegram.SafeEdit(c.bot, editMsg, "")
 	}
 }
 
@@ -1015,6 +1068,24 @@ func (c *ExpirationCommand) deactivateAllMessages(ctx context.Context, subscript
 	}
 }
 
+// HandleTasksCallback обрабатывает кнопки закрепленных задач на сегодня (tasks:*):
+// показывает нажавшему ассистенту его подписки из выбранного списка
+func (c *ExpirationCommand) HandleTasksCallback(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery) error {
+	_ = c.answerCallback(callbackQuery.ID, "")
+	chatID := callbackQuery.Message.Chat.ID
+	assistantTelegramID := callbackQuery.From.ID
+
+	switch strings.TrimPrefix(callbackQuery.Data, "tasks:") {
+	case "expiring":
+		return c.ExecuteExpiring(ctx, chatID, &assistantTelegramID)
+	case "exp3":
+		return c.ExecuteExp3(ctx, chatID, &assistantTelegramID)
+	case "overdue":
+		return c.ExecuteOverdue(ctx, chatID, &assistantTelegramID)
+	}
+	return nil
+}
+
 // answerCallback отвечает на callback query
 func (c *ExpirationCommand) answerCallback(callbackID string, text string) error {
 	callback := tgbotapi.NewCallback(callbackID, text)
@@ -1029,3 +1100,21 @@ func generateWhatsAppLink(phone string, message string) string {
 	cleanPhone = strings.ReplaceAll(cleanPhone, "-", "")
 	return fmt.Sprintf("https://wa.me/%s?text=%s", cleanPhone, url.QueryEscape(message))
 }
+
+// subPaymentMeta - тариф и клиент подписки для шаблонов описания платежа и чека
+func subPaymentMeta(sub *subs.Subscription, tariffName string) payment.CreatePaymentMeta {
+	meta := payment.CreatePaymentMeta{TariffName: tariffName}
+	if sub.ClientWhatsApp != nil {
+		meta.ClientWhatsApp = *sub.ClientWhatsApp
+	}
+	return meta
+}
+
+// subWhatsAppVars - подстановки для шаблонов сообщений клиенту, измененных через /templates
+func subWhatsAppVars(sub *subs.Subscription, tariffName string) watemplates.Vars {
+	var phone string
+	if sub.ClientWhatsApp != nil {
+		phone = *sub.ClientWhatsApp
+	}
+	return watemplates.NewVars(phone, sub.ExpiresAt, tariffName)
+}
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"strings"
 
+	"kurut-bot/internal/stories/clientlang"
 	"kurut-bot/internal/stories/servers"
 	"kurut-bot/internal/stories/submessages"
 	"kurut-bot/internal/stories/subs"
@@ -24,6 +25,7 @@ type ExpirationNotificationService struct {
 	serverStorage  ExpirationServerStorage
 	messageStorage ExpirationMessageStorage
 	paymentService ExpirationPaymentService
+	langStorage    ClientLanguageStorage
 	logger         *slog.Logger
 }
 
@@ -34,6 +36,7 @@ func NewExpirationNotificationService(
 	serverStorage ExpirationServerStorage,
 	messageStorage ExpirationMessageStorage,
 	paymentService ExpirationPaymentService,
+	langStorage ClientLanguageStorage,
 	logger *slog.Logger,
 ) *ExpirationNotificationService {
 	return &ExpirationNotificationService{
@@ -42,10 +45,38 @@ func NewExpirationNotificationService(
 		serverStorage:  serverStorage,
 		messageStorage: messageStorage,
 		paymentService: paymentService,
+		langStorage:    langStorage,
 		logger:         logger,
 	}
 }
 
+// subServer возвращает сервер подписки; nil если сервер не задан или не найден
+func (s *ExpirationNotificationService) subServer(ctx context.Context, sub *subs.Subscription) *servers.Server {
+	if sub.ServerID == nil {
+		return nil
+	}
+	server, err := s.serverStorage.GetServer(ctx, servers.GetCriteria{ID: sub.ServerID})
+	if err != nil {
+		s.logger.Warn("Failed to get subscription server", "error", err, "sub_id", sub.ID, "server_id", *sub.ServerID)
+		return nil
+	}
+	return server
+}
+
+// renewalAmount возвращает сумму продления подписки на тариф с наценкой сервера и цену тарифа для разбивки
+// в платеже (nil, если наценки нет). Индивидуальная цена подписки - итоговая, наценка к ней не добавляется
+func renewalAmount(sub *subs.Subscription, tariff *tariffs.Tariff, server *servers.Server) (float64, *float64) {
+	price := sub.RenewalPrice(tariff.ID, tariff.Price)
+	if sub.CustomPrice != nil && tariff.ID == sub.TariffID {
+		return price, nil
+	}
+	total := server.EffectivePrice(price)
+	if total == price {
+		return price, nil
+	}
+	return total, &price
+}
+
 // SendOverdueSubscriptionMessage отправляет сообщение для одной просроченной подписки
 func (s *ExpirationNotificationService) SendOverdueSubscriptionMessage(ctx context.Context, chatID int64, sub *subs.Subscription) error {
 	tariff, _ := s.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &sub.TariffID})
@@ -74,7 +105,8 @@ func (s *ExpirationNotificationService) SendOverdueSubscriptionMessage(ctx conte
 	// Формируем текст со ссылкой на WhatsApp в номере клиента
 	var text string
 	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
-		whatsappLink := GenerateWhatsAppLink(*sub.ClientWhatsApp, "Здравствуйте! Ваша подписка VPN истекла. Для продолжения работы необходимо оплатить подписку.")
+		lang := s.clientLanguage(ctx, *sub.ClientWhatsApp)
+		whatsappLink := GenerateWhatsAppLink(*sub.ClientWhatsApp, messages.WhatsAppTextWithVars(lang, messages.WATemplateDisabled, subWhatsAppVars(sub, tariffName)))
 		text = fmt.Sprintf(
 			"⚠️ *Просроченная подписка*\n\n"+
 				"📱 Клиент: [%s](%s)\n"+
@@ -87,6 +119,9 @@ func (s *ExpirationNotificationService) SendOverdueSubscriptionMessage(ctx conte
 				"📅 Тариф: %s%s",
 			whatsapp, tariffName, passwordLine)
 	}
+	if sub.Note != "" {
+		text += "\n" + formatSubNote(sub.Note)
+	}
 
 	// Кнопки до отключения: Сервер, Отключить
 	var rows [][]tgbotapi.InlineKeyboardButton
@@ -100,6 +135,13 @@ func (s *ExpirationNotificationService) SendOverdueSubscriptionMessage(ctx conte
 	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
 		tgbotapi.NewInlineKeyboardButtonData("❌ Отключить", fmt.Sprintf("exp_dis:%d", sub.ID)),
 	))
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📄 Создать как эта", fmt.Sprintf("clone_sub:%d", sub.ID)),
+	))
+	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(ClientLanguageButton(sub.ID)))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(EscalationButton(sub.ID)))
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
@@ -129,9 +171,10 @@ func (s *ExpirationNotificationService) SendOverdueSubscriptionMessage(ctx conte
 }
 
 // SendExpiringSubscriptionMessage отправляет сообщение для одной истекающей подписки
-// daysUntilExpiry: 0 = сегодня, 3 = через 3 дня
+// daysUntilExpiry: 0 = сегодня, 1 = завтра, N = через N дней
 func (s *ExpirationNotificationService) SendExpiringSubscriptionMessage(ctx context.Context, chatID int64, sub *subs.Subscription, daysUntilExpiry int) error {
-	tariff, _ := s.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &sub.TariffID})
+	// Цену показываем по тарифу продления: после окончания промо это тариф, указанный у промо
+	tariff, _ := s.tariffService.GetRenewalTariff(ctx, sub.TariffID)
 
 	whatsapp := "Не указан"
 	if sub.ClientWhatsApp != nil {
@@ -142,27 +185,26 @@ func (s *ExpirationNotificationService) SendExpiringSubscriptionMessage(ctx cont
 	price := 0.0
 	if tariff != nil {
 		tariffName = tariff.Name
-		price = tariff.Price
+		price, _ = renewalAmount(sub, tariff, s.subServer(ctx, sub))
 	}
 
 	// Формируем заголовок в зависимости от количества дней
 	var headerText string
-	var whatsappMsg string
 	switch daysUntilExpiry {
 	case 0:
 		headerText = "🔔 *Подписка истекает сегодня*"
-		whatsappMsg = messages.WhatsAppMsgToday
+	case 1:
+		headerText = "⏰ *Подписка истекает завтра*"
 	case 3:
 		headerText = "⏰ *Подписка истекает через 3 дня*"
-		whatsappMsg = messages.WhatsAppMsg3Days
 	default:
 		headerText = fmt.Sprintf("⏰ *Подписка истекает через %d дней*", daysUntilExpiry)
-		whatsappMsg = messages.WhatsAppMsgToday
 	}
 
 	// Формируем текст со ссылкой на WhatsApp в номере клиента
 	var text string
 	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
+		whatsappMsg := messages.WhatsAppTextForDays(s.clientLanguage(ctx, *sub.ClientWhatsApp), daysUntilExpiry, subWhatsAppVars(sub, tariffName))
 		whatsappLink := GenerateWhatsAppLink(*sub.ClientWhatsApp, whatsappMsg)
 		text = fmt.Sprintf(
 			"%s\n\n"+
@@ -176,6 +218,12 @@ func (s *ExpirationNotificationService) SendExpiringSubscriptionMessage(ctx cont
 				"📅 Тариф: %s (%.0f ₽)",
 			headerText, whatsapp, tariffName, price)
 	}
+	if tariff != nil && tariff.TrafficLimitGB != nil {
+		text += "\n" + FormatTrafficQuota(tariff.TrafficLimitGB, sub.ExtraTrafficGB)
+	}
+	if sub.Note != "" {
+		text += "\n" + formatSubNote(sub.Note)
+	}
 
 	// Формируем кнопки
 	var rows [][]tgbotapi.InlineKeyboardButton
@@ -191,6 +239,17 @@ func (s *ExpirationNotificationService) SendExpiringSubscriptionMessage(ctx cont
 		tgbotapi.NewInlineKeyboardButtonData(s.paidButtonText(), fmt.Sprintf("exp_paid:%d", sub.ID)),
 	))
 
+	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(CalendarButton(sub.ID), ClientLanguageButton(sub.ID)))
+	} else {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(CalendarButton(sub.ID)))
+	}
+
+	// Докупка трафика - только для тарифов с лимитом
+	if tariff != nil && tariff.TrafficLimitGB != nil {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(TrafficTopUpButton(sub.ID)))
+	}
+
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
 	msg := tgbotapi.NewMessage(chatID, text)
@@ -226,6 +285,15 @@ func (s *ExpirationNotificationService) paidButtonText() string {
 	return "✅ Проверить"
 }
 
+// clientLanguage возвращает язык клиента для предзаполненных сообщений WhatsApp
+func (s *ExpirationNotificationService) clientLanguage(ctx context.Context, phone string) clientlang.Language {
+	stored, err := s.langStorage.GetClientLanguage(ctx, phone)
+	if err != nil {
+		s.logger.Warn("Failed to get client language", "error", err, "whatsapp", phone)
+	}
+	return clientlang.Resolve(stored, phone)
+}
+
 // GenerateWhatsAppLink генерирует ссылку на WhatsApp с предзаполненным сообщением
 func GenerateWhatsAppLink(phone string, message string) string {
 	cleanPhone := strings.TrimPrefix(phone, "+")
@@ -0,0 +1,49 @@
+package cmds
+
+import (
+	"testing"
+
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/tariffs"
+)
+
+func TestRenewalAmount(t *testing.T) {
+	custom := 250.0
+	tariff := &tariffs.Tariff{ID: 1, Price: 300}
+	premium := &servers.Server{ID: 2, PriceSurcharge: 100}
+
+	tests := []struct {
+		name     string
+		sub      *subs.Subscription
+		tariff   *tariffs.Tariff
+		server   *servers.Server
+		wantSum  float64
+		wantBase *float64
+	}{
+		{name: "no server", sub: &subs.Subscription{TariffID: 1}, tariff: tariff, wantSum: 300},
+		{name: "plain server", sub: &subs.Subscription{TariffID: 1}, tariff: tariff, server: &servers.Server{ID: 3}, wantSum: 300},
+		{name: "surcharge", sub: &subs.Subscription{TariffID: 1}, tariff: tariff, server: premium, wantSum: 400, wantBase: &tariff.Price},
+		{name: "custom price is final", sub: &subs.Subscription{TariffID: 1, CustomPrice: &custom}, tariff: tariff, server: premium, wantSum: 250},
+		{
+			name:     "custom price of another tariff",
+			sub:      &subs.Subscription{TariffID: 5, CustomPrice: &custom},
+			tariff:   tariff,
+			server:   premium,
+			wantSum:  400,
+			wantBase: &tariff.Price,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum, base := renewalAmount(tt.sub, tt.tariff, tt.server)
+			if sum != tt.wantSum {
+				t.Errorf("sum = %v, want %v", sum, tt.wantSum)
+			}
+			if (base == nil) != (tt.wantBase == nil) || base != nil && *base != *tt.wantBase {
+				t.Errorf("base = %v, want %v", base, tt.wantBase)
+			}
+		})
+	}
+}
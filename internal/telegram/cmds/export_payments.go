@@ -0,0 +1,219 @@
+package cmds
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/stories/payment"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// exportPaymentsBatch - платежей за один запрос к базе при выгрузке
+const exportPaymentsBatch = 500
+
+const exportPaymentsUsage = "📥 *Выгрузка платежей в CSV*\n\n" +
+	"`/export_payments` — все платежи\n" +
+	"`/export_payments status=approved from=01.09.2026 to=30.09.2026`\n\n" +
+	"Фильтры необязательны: статус, период создания платежа"
+
+// exportPaymentsStatuses - статусы, которые можно указать в фильтре
+var exportPaymentsStatuses = []payment.Status{
+	payment.StatusPending,
+	payment.StatusApproved,
+	payment.StatusRejected,
+	payment.StatusCancelled,
+}
+
+// exportPaymentsHeader - колонки выгрузки для сверки с ЮKassa; subscription_ids - связанные подписки через пробел
+var exportPaymentsHeader = []string{
+	"id", "user_id", "status", "amount", "base_amount", "server_id", "yookassa_id", "subscription_ids",
+	"processed_at", "provider_cancel_result", "late_status", "created_at", "updated_at",
+}
+
+// ExportPaymentsCommand выгружает платежи в CSV для бухгалтерской сверки
+type ExportPaymentsCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage ExportPaymentsStorage
+	logger  *slog.Logger
+}
+
+type ExportPaymentsStorage interface {
+	ListPayments(ctx context.Context, criteria payment.ListCriteria) ([]*payment.Payment, error)
+	ListPaymentSubscriptionIDs(ctx context.Context, paymentIDs []int64) (map[int64][]int64, error)
+}
+
+func NewExportPaymentsCommand(bot *tgbotapi.BotAPI, storage ExportPaymentsStorage, logger *slog.Logger) *ExportPaymentsCommand {
+	return &ExportPaymentsCommand{
+		bot:     bot,
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// ParseExportPaymentsArgs разбирает фильтры вида key=value: status, from и to (дд.мм.гггг, to включительно).
+// Даты - в UTC, как и время создания платежей в базе
+func ParseExportPaymentsArgs(args string) (payment.ListCriteria, error) {
+	var criteria payment.ListCriteria
+	for _, field := range strings.Fields(args) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return payment.ListCriteria{}, fmt.Errorf("неверный фильтр %s", field)
+		}
+
+		switch strings.ToLower(key) {
+		case "status":
+			status := payment.Status(strings.ToLower(value))
+			if !slices.Contains(exportPaymentsStatuses, status) {
+				return payment.ListCriteria{}, fmt.Errorf("неизвестный статус %s", value)
+			}
+			criteria.Status = &status
+		case "from":
+			from, err := time.Parse("02.01.2006", value)
+			if err != nil {
+				return payment.ListCriteria{}, fmt.Errorf("неверная дата %s", value)
+			}
+			criteria.CreatedAfter = &from
+		case "to":
+			last, err := time.Parse("02.01.2006", value)
+			if err != nil {
+				return payment.ListCriteria{}, fmt.Errorf("неверная дата %s", value)
+			}
+			to := last.AddDate(0, 0, 1)
+			criteria.CreatedBefore = &to
+		default:
+			return payment.ListCriteria{}, fmt.Errorf("неизвестный фильтр %s", key)
+		}
+	}
+
+	if criteria.CreatedAfter != nil && criteria.CreatedBefore != nil && !criteria.CreatedAfter.Before(*criteria.CreatedBefore) {
+		return payment.ListCriteria{}, errors.New("конец периода раньше начала")
+	}
+	return criteria, nil
+}
+
+// Execute собирает выгрузку по фильтрам и отправляет ее файлом
+func (c *ExportPaymentsCommand) Execute(ctx context.Context, chatID int64, args string) error {
+	criteria, err := ParseExportPaymentsArgs(args)
+	if err != nil {
+		return c.send(chatID, fmt.Sprintf("❌ %s\n\n%s", err, exportPaymentsUsage))
+	}
+
+	// Выбираем порциями вместе со связанными подписками, чтобы не держать большой запрос к базе
+	var payments []*payment.Payment
+	subscriptionIDs := make(map[int64][]int64)
+	criteria.Limit = exportPaymentsBatch
+	for {
+		batch, err := c.storage.ListPayments(ctx, criteria)
+		if err != nil {
+			c.logger.Error("Failed to list payments for export", "error", err)
+			return c.send(chatID, "❌ Ошибка выгрузки платежей")
+		}
+
+		ids := make([]int64, 0, len(batch))
+		for _, p := range batch {
+			ids = append(ids, p.ID)
+		}
+		linked, err := c.storage.ListPaymentSubscriptionIDs(ctx, ids)
+		if err != nil {
+			c.logger.Error("Failed to list payment subscriptions for export", "error", err)
+			return c.send(chatID, "❌ Ошибка выгрузки платежей")
+		}
+		for paymentID, subIDs := range linked {
+			subscriptionIDs[paymentID] = subIDs
+		}
+
+		payments = append(payments, batch...)
+		if len(batch) < exportPaymentsBatch {
+			break
+		}
+		criteria.Offset += exportPaymentsBatch
+	}
+
+	if len(payments) == 0 {
+		return c.send(chatID, "Платежей по фильтрам не найдено\n\n"+exportPaymentsUsage)
+	}
+
+	data, err := buildPaymentsCSV(payments, subscriptionIDs)
+	if err != nil {
+		return fmt.Errorf("build payments csv: %w", err)
+	}
+
+	var total float64
+	for _, p := range payments {
+		if p.Status == payment.StatusApproved {
+			total += p.Amount
+		}
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("payments-%s.csv", time.Now().UTC().Format("2006-01-02")),
+		Bytes: data,
+	})
+	doc.Caption = fmt.Sprintf("📥 Платежей: %d, оплачено на %.0f ₽", len(payments), total)
+	_, err = c.bot.Send(doc)
+	return err
+}
+
+func buildPaymentsCSV(payments []*payment.Payment, subscriptionIDs map[int64][]int64) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(exportPaymentsHeader); err != nil {
+		return nil, err
+	}
+	for _, p := range payments {
+		subIDs := make([]string, 0, len(subscriptionIDs[p.ID]))
+		for _, id := range subscriptionIDs[p.ID] {
+			subIDs = append(subIDs, strconv.FormatInt(id, 10))
+		}
+
+		var cancelResult, lateStatus string
+		if p.ProviderCancelResult != nil {
+			cancelResult = string(*p.ProviderCancelResult)
+		}
+		if p.LateStatus != nil {
+			lateStatus = string(*p.LateStatus)
+		}
+
+		record := []string{
+			strconv.FormatInt(p.ID, 10),
+			strconv.FormatInt(p.UserID, 10),
+			string(p.Status),
+			strconv.FormatFloat(p.Amount, 'f', 2, 64),
+			csvPrice(p.BaseAmount),
+			csvInt(p.ServerID),
+			csvString(p.YooKassaID),
+			strings.Join(subIDs, " "),
+			csvTime(p.ProcessedAt),
+			cancelResult,
+			lateStatus,
+			p.CreatedAt.UTC().Format(time.RFC3339),
+			p.UpdatedAt.UTC().Format(time.RFC3339),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *ExportPaymentsCommand) send(chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	_, err := c.bot.Send(msg)
+	return err
+}
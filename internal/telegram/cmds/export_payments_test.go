@@ -0,0 +1,75 @@
+package cmds
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"kurut-bot/internal/stories/payment"
+)
+
+func TestParseExportPaymentsArgs(t *testing.T) {
+	criteria, err := ParseExportPaymentsArgs("status=Approved from=01.09.2026 to=30.09.2026")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if criteria.Status == nil || *criteria.Status != payment.StatusApproved {
+		t.Errorf("Status = %v, want approved", criteria.Status)
+	}
+	if want := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC); criteria.CreatedAfter == nil || !criteria.CreatedAfter.Equal(want) {
+		t.Errorf("CreatedAfter = %v, want %v", criteria.CreatedAfter, want)
+	}
+	if want := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC); criteria.CreatedBefore == nil || !criteria.CreatedBefore.Equal(want) {
+		t.Errorf("CreatedBefore = %v, want %v", criteria.CreatedBefore, want)
+	}
+
+	for _, args := range []string{"status=paid", "status", "from=2026-09-01", "server=3", "from=10.09.2026 to=01.09.2026"} {
+		if _, err := ParseExportPaymentsArgs(args); err == nil {
+			t.Errorf("ParseExportPaymentsArgs(%q) expected error", args)
+		}
+	}
+}
+
+func TestBuildPaymentsCSV(t *testing.T) {
+	yooKassaID := "2f3a-0001"
+	processed := time.Date(2026, 10, 1, 12, 5, 0, 0, time.UTC)
+	created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	data, err := buildPaymentsCSV([]*payment.Payment{
+		{
+			ID:          10,
+			UserID:      1,
+			Amount:      350,
+			Status:      payment.StatusApproved,
+			YooKassaID:  &yooKassaID,
+			ProcessedAt: &processed,
+			CreatedAt:   created,
+			UpdatedAt:   processed,
+		},
+		{
+			ID:        11,
+			UserID:    1,
+			Amount:    200,
+			Status:    payment.StatusPending,
+			CreatedAt: created,
+			UpdatedAt: created,
+		},
+	}, map[int64][]int64{10: {5, 6}})
+	if err != nil {
+		t.Fatalf("buildPaymentsCSV() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("lines = %d, want 3", len(lines))
+	}
+	want := []string{
+		"10,1,approved,350.00,,,2f3a-0001,5 6,2026-10-01T12:05:00Z,,,2026-10-01T12:00:00Z,2026-10-01T12:05:00Z",
+		"11,1,pending,200.00,,,,,,,,2026-10-01T12:00:00Z,2026-10-01T12:00:00Z",
+	}
+	for i, w := range want {
+		if lines[i+1] != w {
+			t.Errorf("row %d =\n%s\nwant\n%s", i+1, lines[i+1], w)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package cmds
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// exportSubsBatch - подписок за один запрос к базе при выгрузке
+const exportSubsBatch = 500
+
+const exportSubsUsage = "📥 *Выгрузка подписок в CSV*\n\n" +
+	"`/export_subs` — все подписки\n" +
+	"`/export_subs status=active,expired server=3 assistant=123456 from=01.09.2026 to=30.09.2026`\n\n" +
+	"Фильтры необязательны: статус, ID сервера, Telegram ID ассистента, период создания подписки"
+
+// exportSubsStatuses - статусы, которые можно указать в фильтре
+var exportSubsStatuses = []subs.Status{
+	subs.StatusPending,
+	subs.StatusActive,
+	subs.StatusExpired,
+	subs.StatusDisabled,
+	subs.StatusArchived,
+	subs.StatusCancelled,
+	subs.StatusPaused,
+}
+
+// exportSubsHeader - колонки выгрузки совпадают с колонками таблицы subscriptions
+var exportSubsHeader = []string{
+	"id", "user_id", "tariff_id", "server_id", "status", "client_whatsapp", "generated_user_id",
+	"created_by_telegram_id", "referrer_whatsapp", "activated_at", "expires_at", "last_renewed_at",
+	"renewal_count", "extra_traffic_gb", "custom_price", "client_platform", "note", "created_at",
+}
+
+// ExportSubsCommand выгружает подписки в CSV с фильтрами
+type ExportSubsCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage ExportSubsStorage
+	logger  *slog.Logger
+}
+
+type ExportSubsStorage interface {
+	ListSubscriptions(ctx context.Context, criteria subs.ListCriteria) ([]*subs.Subscription, error)
+}
+
+func NewExportSubsCommand(bot *tgbotapi.BotAPI, storage ExportSubsStorage, logger *slog.Logger) *ExportSubsCommand {
+	return &ExportSubsCommand{
+		bot:     bot,
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// ParseExportSubsArgs разбирает фильтры вида key=value: status (через запятую), server, assistant,
+// from и to (дд.мм.гггг, to включительно). Даты - в UTC, как и время создания подписок в базе
+func ParseExportSubsArgs(args string) (subs.ListCriteria, error) {
+	var criteria subs.ListCriteria
+	for _, field := range strings.Fields(args) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return subs.ListCriteria{}, fmt.Errorf("неверный фильтр %s", field)
+		}
+
+		switch strings.ToLower(key) {
+		case "status":
+			for _, s := range strings.Split(value, ",") {
+				status := subs.Status(strings.ToLower(s))
+				if !slices.Contains(exportSubsStatuses, status) {
+					return subs.ListCriteria{}, fmt.Errorf("неизвестный статус %s", s)
+				}
+				criteria.Status = append(criteria.Status, status)
+			}
+		case "server":
+			serverID, err := strconv.ParseInt(strings.TrimPrefix(value, "#"), 10, 64)
+			if err != nil || serverID <= 0 {
+				return subs.ListCriteria{}, errors.New("неверный ID сервера")
+			}
+			criteria.ServerIDs = []int64{serverID}
+		case "assistant":
+			assistantID, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || assistantID <= 0 {
+				return subs.ListCriteria{}, errors.New("неверный Telegram ID ассистента")
+			}
+			criteria.CreatedByTelegramID = &assistantID
+		case "from":
+			from, err := time.Parse("02.01.2006", value)
+			if err != nil {
+				return subs.ListCriteria{}, fmt.Errorf("неверная дата %s", value)
+			}
+			criteria.CreatedAfter = &from
+		case "to":
+			last, err := time.Parse("02.01.2006", value)
+			if err != nil {
+				return subs.ListCriteria{}, fmt.Errorf("неверная дата %s", value)
+			}
+			to := last.AddDate(0, 0, 1)
+			criteria.CreatedBefore = &to
+		default:
+			return subs.ListCriteria{}, fmt.Errorf("неизвестный фильтр %s", key)
+		}
+	}
+
+	if criteria.CreatedAfter != nil && criteria.CreatedBefore != nil && !criteria.CreatedAfter.Before(*criteria.CreatedBefore) {
+		return subs.ListCriteria{}, errors.New("конец периода раньше начала")
+	}
+	return criteria, nil
+}
+
+// Execute собирает выгрузку по фильтрам и отправляет ее файлом
+func (c *ExportSubsCommand) Execute(ctx context.Context, chatID int64, args string) error {
+	criteria, err := ParseExportSubsArgs(args)
+	if err != nil {
+		return c.send(chatID, fmt.Sprintf("❌ %s\n\n%s", err, exportSubsUsage))
+	}
+
+	// Выбираем порциями, чтобы не держать большой запрос к базе
+	var subscriptions []*subs.Subscription
+	criteria.Limit = exportSubsBatch
+	for {
+		batch, err := c.storage.ListSubscriptions(ctx, criteria)
+		if err != nil {
+			c.logger.Error("Failed to list subscriptions for export", "error", err)
+			return c.send(chatID, "❌ Ошибка выгрузки подписок")
+		}
+		subscriptions = append(subscriptions, batch...)
+		if len(batch) < exportSubsBatch {
+			break
+		}
+		criteria.Offset += exportSubsBatch
+	}
+
+	if len(subscriptions) == 0 {
+		return c.send(chatID, "Подписок по фильтрам не найдено\n\n"+exportSubsUsage)
+	}
+
+	data, err := buildSubsCSV(subscriptions)
+	if err != nil {
+		return fmt.Errorf("build subscriptions csv: %w", err)
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("subscriptions-%s.csv", time.Now().UTC().Format("2006-01-02")),
+		Bytes: data,
+	})
+	doc.Caption = fmt.Sprintf("📥 Подписок: %d", len(subscriptions))
+	_, err = c.bot.Send(doc)
+	return err
+}
+
+func buildSubsCSV(subscriptions []*subs.Subscription) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(exportSubsHeader); err != nil {
+		return nil, err
+	}
+	for _, sub := range subscriptions {
+		record := []string{
+			strconv.FormatInt(sub.ID, 10),
+			strconv.FormatInt(sub.UserID, 10),
+			strconv.FormatInt(sub.TariffID, 10),
+			csvInt(sub.ServerID),
+			string(sub.Status),
+			csvString(sub.ClientWhatsApp),
+			csvString(sub.GeneratedUserID),
+			csvInt(sub.CreatedByTelegramID),
+			csvString(sub.ReferrerWhatsApp),
+			csvTime(sub.ActivatedAt),
+			csvTime(sub.ExpiresAt),
+			csvTime(sub.LastRenewedAt),
+			strconv.Itoa(sub.RenewalCount),
+			strconv.Itoa(sub.ExtraTrafficGB),
+			csvPrice(sub.CustomPrice),
+			string(sub.ClientPlatform),
+			sub.Note,
+			sub.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func csvInt(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
+
+func csvString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func csvTime(v *time.Time) string {
+	if v == nil {
+		return ""
+	}
+	return v.UTC().Format(time.RFC3339)
+}
+
+func csvPrice(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', 2, 64)
+}
+
+func (c *ExportSubsCommand) send(chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	_, err := c.bot.Send(msg)
+	return err
+}
@@ -0,0 +1,69 @@
+package cmds
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"kurut-bot/internal/stories/subs"
+)
+
+func TestParseExportSubsArgs(t *testing.T) {
+	criteria, err := ParseExportSubsArgs("status=active,Expired server=#3 assistant=42 from=01.09.2026 to=30.09.2026")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(criteria.Status) != 2 || criteria.Status[0] != subs.StatusActive || criteria.Status[1] != subs.StatusExpired {
+		t.Errorf("Status = %v, want [active expired]", criteria.Status)
+	}
+	if len(criteria.ServerIDs) != 1 || criteria.ServerIDs[0] != 3 {
+		t.Errorf("ServerIDs = %v, want [3]", criteria.ServerIDs)
+	}
+	if criteria.CreatedByTelegramID == nil || *criteria.CreatedByTelegramID != 42 {
+		t.Errorf("CreatedByTelegramID = %v, want 42", criteria.CreatedByTelegramID)
+	}
+	if want := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC); criteria.CreatedAfter == nil || !criteria.CreatedAfter.Equal(want) {
+		t.Errorf("CreatedAfter = %v, want %v", criteria.CreatedAfter, want)
+	}
+	if want := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC); criteria.CreatedBefore == nil || !criteria.CreatedBefore.Equal(want) {
+		t.Errorf("CreatedBefore = %v, want %v", criteria.CreatedBefore, want)
+	}
+
+	for _, args := range []string{"status=unknown", "server=abc", "assistant", "from=2026-09-01", "color=red", "from=10.09.2026 to=01.09.2026"} {
+		if _, err := ParseExportSubsArgs(args); err == nil {
+			t.Errorf("ParseExportSubsArgs(%q) expected error", args)
+		}
+	}
+}
+
+func TestBuildSubsCSV(t *testing.T) {
+	phone := "+996555000111"
+	serverID := int64(3)
+	price := 250.0
+	expires := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	data, err := buildSubsCSV([]*subs.Subscription{{
+		ID:             7,
+		UserID:         1,
+		TariffID:       2,
+		ServerID:       &serverID,
+		Status:         subs.StatusActive,
+		ClientWhatsApp: &phone,
+		ExpiresAt:      &expires,
+		CustomPrice:    &price,
+		Note:           "VIP, old client",
+		CreatedAt:      time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	}})
+	if err != nil {
+		t.Fatalf("buildSubsCSV() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %d, want 2", len(lines))
+	}
+	want := `7,1,2,3,active,+996555000111,,,,,2026-11-01T00:00:00Z,,0,0,250.00,,"VIP, old client",2026-10-01T12:00:00Z`
+	if lines[1] != want {
+		t.Errorf("row =\n%s\nwant\n%s", lines[1], want)
+	}
+}
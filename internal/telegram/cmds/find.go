@@ -0,0 +1,91 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// findResultsLimit - сколько подписок показывать в результатах поиска
+const findResultsLimit = 10
+
+type FindStorage interface {
+	SearchSubscriptions(ctx context.Context, criteria subs.SearchCriteria) ([]storage.SubscriptionDetails, error)
+}
+
+// FindCommand ищет клиента по номеру WhatsApp или ID пользователя
+type FindCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage FindStorage
+	subView *SubViewCommand
+}
+
+func NewFindCommand(bot *tgbotapi.BotAPI, storage FindStorage, subView *SubViewCommand) *FindCommand {
+	return &FindCommand{
+		bot:     bot,
+		storage: storage,
+		subView: subView,
+	}
+}
+
+// Execute выполняет поиск. Ассистент видит только свои подписки, админ - все
+func (c *FindCommand) Execute(ctx context.Context, assistantTelegramID, chatID int64, isAdmin bool, args string) error {
+	query := subs.NormalizeSearchQuery(args)
+	if query == "" {
+		msg := tgbotapi.NewMessage(chatID, "Используйте: /find <номер WhatsApp или ID пользователя>\nМожно указать часть номера.")
+		_, err := c.bot.Send(msg)
+		return err
+	}
+
+	criteria := subs.SearchCriteria{Query: query, Limit: findResultsLimit}
+	if !isAdmin {
+		criteria.CreatedByTelegramID = &assistantTelegramID
+	}
+
+	results, err := c.storage.SearchSubscriptions(ctx, criteria)
+	if err != nil {
+		_, _ = c.bot.Send(tgbotapi.NewMessage(chatID, "❌ Ошибка поиска"))
+		return fmt.Errorf("search subscriptions: %w", err)
+	}
+
+	switch len(results) {
+	case 0:
+		_, err = c.bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("🔍 По запросу «%s» ничего не найдено", query)))
+		return err
+	case 1:
+		return c.subView.Send(ctx, chatID, results[0], isAdmin)
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(results))
+	for _, details := range results {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			SubViewButton(findResultLabel(details), details.Subscription.ID),
+		))
+	}
+
+	text := fmt.Sprintf("🔍 Найдено подписок: %d", len(results))
+	if len(results) == findResultsLimit {
+		text += fmt.Sprintf("\nПоказаны первые %d, уточните запрос.", findResultsLimit)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+func findResultLabel(details storage.SubscriptionDetails) string {
+	sub := details.Subscription
+	label := fmt.Sprintf("#%d", sub.ID)
+	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
+		label += " · " + *sub.ClientWhatsApp
+	} else if sub.GeneratedUserID != nil {
+		label += " · " + *sub.GeneratedUserID
+	}
+	label += " · " + subscriptionStatusLabel(sub.Status)
+	return label
+}
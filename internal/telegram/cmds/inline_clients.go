@@ -0,0 +1,106 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// inlineResultsLimit - сколько клиентов показывать в inline-режиме
+	inlineResultsLimit = 10
+	// inlineMinQueryLen - с какой длины искать: по одной-двум цифрам совпадает половина базы
+	inlineMinQueryLen = 3
+)
+
+type InlineClientsStorage interface {
+	SearchClientsByPrefix(ctx context.Context, criteria subs.SearchCriteria) ([]storage.SubscriptionDetails, error)
+}
+
+// InlineClientsCommand - поиск клиента в inline-режиме: "@бот +99655512" в любом чате.
+// Нажатие на результат отправляет в чат краткую сводку по клиенту
+type InlineClientsCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage InlineClientsStorage
+	logger  *slog.Logger
+}
+
+func NewInlineClientsCommand(bot *tgbotapi.BotAPI, storage InlineClientsStorage, logger *slog.Logger) *InlineClientsCommand {
+	return &InlineClientsCommand{
+		bot:     bot,
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Handle отвечает на inline-запрос. Ассистент находит только своих клиентов, админ - всех
+func (c *InlineClientsCommand) Handle(ctx context.Context, assistantTelegramID int64, isAdmin bool, inlineQuery *tgbotapi.InlineQuery) error {
+	query := subs.NormalizeSearchQuery(inlineQuery.Query)
+
+	var results []any
+	if len([]rune(query)) >= inlineMinQueryLen {
+		criteria := subs.SearchCriteria{Query: query, Limit: inlineResultsLimit}
+		if !isAdmin {
+			criteria.CreatedByTelegramID = &assistantTelegramID
+		}
+
+		found, err := c.storage.SearchClientsByPrefix(ctx, criteria)
+		if err != nil {
+			c.logger.Error("Failed to search clients by prefix", "error", err, "query", query)
+		}
+		for _, details := range found {
+			results = append(results, inlineClientResult(details))
+		}
+	}
+
+	_, err := c.bot.Request(tgbotapi.InlineConfig{
+		InlineQueryID: inlineQuery.ID,
+		Results:       results,
+		CacheTime:     0,
+		IsPersonal:    true,
+	})
+	return err
+}
+
+func inlineClientResult(details storage.SubscriptionDetails) tgbotapi.InlineQueryResultArticle {
+	sub := details.Subscription
+	title := fmt.Sprintf("#%d", sub.ID)
+	if sub.ClientWhatsApp != nil {
+		title = "+" + strings.TrimPrefix(*sub.ClientWhatsApp, "+")
+	}
+
+	result := tgbotapi.NewInlineQueryResultArticle(strconv.FormatInt(sub.ID, 10), title, InlineClientSummary(details))
+	description := subscriptionStatusLabel(sub.Status) + " · " + details.TariffName
+	if sub.ExpiresAt != nil {
+		description += " · до " + sub.ExpiresAt.Format("02.01.2006")
+	}
+	result.Description = description
+	return result
+}
+
+// InlineClientSummary - сводка по клиенту, которая отправляется в чат из inline-режима.
+// Без пароля панели и заметок: сообщение может уйти в чат с клиентом
+func InlineClientSummary(details storage.SubscriptionDetails) string {
+	sub := details.Subscription
+
+	var b strings.Builder
+	if sub.ClientWhatsApp != nil {
+		fmt.Fprintf(&b, "📱 Клиент: +%s\n", strings.TrimPrefix(*sub.ClientWhatsApp, "+"))
+	}
+	fmt.Fprintf(&b, "📋 Подписка #%d: %s\n", sub.ID, subscriptionStatusLabel(sub.Status))
+	fmt.Fprintf(&b, "📅 Тариф: %s (%.0f ₽)\n", details.TariffName, details.TariffPrice)
+	if sub.ExpiresAt != nil {
+		fmt.Fprintf(&b, "⏳ До: %s\n", sub.ExpiresAt.Format("02.01.2006"))
+	}
+	if details.ServerName != nil {
+		fmt.Fprintf(&b, "🖥 Сервер: %s\n", *details.ServerName)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,41 @@
+package cmds
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
+)
+
+func TestInlineClientSummary(t *testing.T) {
+	phone := "+996555123456"
+	server := "kg-1"
+	expires := time.Date(2026, 11, 3, 0, 0, 0, 0, time.UTC)
+	details := storage.SubscriptionDetails{
+		Subscription: &subs.Subscription{
+			ID:             42,
+			ClientWhatsApp: &phone,
+			Status:         subs.StatusActive,
+			ExpiresAt:      &expires,
+			Note:           "не звонить после 22:00",
+		},
+		TariffName:  "1 месяц",
+		TariffPrice: 300,
+		ServerName:  &server,
+	}
+
+	want := "📱 Клиент: +996555123456\n" +
+		"📋 Подписка #42: ✅ активна\n" +
+		"📅 Тариф: 1 месяц (300 ₽)\n" +
+		"⏳ До: 03.11.2026\n" +
+		"🖥 Сервер: kg-1"
+	got := InlineClientSummary(details)
+	if got != want {
+		t.Errorf("InlineClientSummary() =\n%s\nwant\n%s", got, want)
+	}
+	if strings.Contains(got, "22:00") {
+		t.Error("InlineClientSummary() must not leak notes")
+	}
+}
@@ -0,0 +1,67 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/telegram/messages"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// LanguageCommand - выбор языка интерфейса бота (/language, callback lang_set:язык)
+type LanguageCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage LanguageStorage
+}
+
+type LanguageStorage interface {
+	SetLanguage(ctx context.Context, telegramID int64, language string) error
+}
+
+func NewLanguageCommand(bot *tgbotapi.BotAPI, storage LanguageStorage) *LanguageCommand {
+	return &LanguageCommand{
+		bot:     bot,
+		storage: storage,
+	}
+}
+
+// Execute показывает выбор языка с отметкой текущего; current - users.language
+func (c *LanguageCommand) Execute(chatID int64, current string) error {
+	l := messages.For(current)
+
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(messages.Langs))
+	for _, lang := range messages.Langs {
+		title := lang.Title()
+		if lang == l.Lang() {
+			title = "✓ " + title
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(title, "lang_set:"+string(lang)))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, l.T(messages.KeyLanguageChoose))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+	_, err := c.bot.Send(msg)
+	return err
+}
+
+// HandleCallback сохраняет выбранный язык (lang_set:язык) и отвечает уже на нем
+func (c *LanguageCommand) HandleCallback(ctx context.Context, telegramID int64, callbackQuery *tgbotapi.CallbackQuery) error {
+	lang, ok := messages.ParseLang(strings.TrimPrefix(callbackQuery.Data, "lang_set:"))
+	if !ok {
+		_, _ = c.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "Неизвестный язык"))
+		return nil
+	}
+
+	if err := c.storage.SetLanguage(ctx, telegramID, string(lang)); err != nil {
+		_, _ = c.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "Ошибка сохранения"))
+		return fmt.Errorf("set language: %w", err)
+	}
+	_, _ = c.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, ""))
+
+	l := messages.For(string(lang))
+	edit := tgbotapi.NewEditMessageText(callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID, l.T(messages.KeyLanguageChanged, lang.Title()))
+	return telegram.SafeEdit(c.bot, edit, "")
+}
@@ -0,0 +1,234 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/bonusrules"
+	"kurut-bot/internal/stories/orders"
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// LatePaymentsCommand обрабатывает решения админа по оплате отмененного заказа
+type LatePaymentsCommand struct {
+	bot                 *tgbotapi.BotAPI
+	storage             LatePaymentsStorage
+	paymentService      LatePaymentsPaymentService
+	subscriptionService LatePaymentsSubscriptionService
+	logger              *slog.Logger
+}
+
+type LatePaymentsStorage interface {
+	GetPayment(ctx context.Context, criteria payment.GetCriteria) (*payment.Payment, error)
+	UpdatePayment(ctx context.Context, criteria payment.GetCriteria, params payment.UpdateParams) (*payment.Payment, error)
+	ClaimLatePaymentResolution(ctx context.Context, paymentID int64, status payment.LateStatus) (bool, error)
+	GetPendingOrderByPaymentID(ctx context.Context, paymentID int64) (*orders.PendingOrder, error)
+	UpdatePendingOrderStatus(ctx context.Context, id int64, status orders.Status) error
+	AddClientBalance(ctx context.Context, clientWhatsApp string, amount float64) (float64, error)
+}
+
+type LatePaymentsPaymentService interface {
+	RefundPayment(ctx context.Context, req payment.RefundRequest) error
+}
+
+type LatePaymentsSubscriptionService interface {
+	CreateSubscription(ctx context.Context, req *subs.CreateSubscriptionRequest) (*subs.CreateSubscriptionResult, error)
+	MigrateSubscription(ctx context.Context, req *subs.MigrateSubscriptionRequest) (*subs.CreateSubscriptionResult, error)
+}
+
+func NewLatePaymentsCommand(
+	bot *tgbotapi.BotAPI,
+	storage LatePaymentsStorage,
+	paymentService LatePaymentsPaymentService,
+	subscriptionService LatePaymentsSubscriptionService,
+	logger *slog.Logger,
+) *LatePaymentsCommand {
+	return &LatePaymentsCommand{
+		bot:                 bot,
+		storage:             storage,
+		paymentService:      paymentService,
+		subscriptionService: subscriptionService,
+		logger:              logger,
+	}
+}
+
+// HandleCallback обрабатывает callback кнопок late_fulfill:paymentID, late_refund:paymentID и late_credit:paymentID
+func (c *LatePaymentsCommand) HandleCallback(ctx context.Context, adminTelegramID int64, callbackQuery *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(callbackQuery.Data, ":")
+	if len(parts) != 2 {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+
+	paymentID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return c.answerCallback(callbackQuery.ID, "Неверный ID платежа")
+	}
+
+	var status payment.LateStatus
+	switch parts[0] {
+	case "late_fulfill":
+		status = payment.LateFulfilled
+	case "late_refund":
+		status = payment.LateRefunded
+	case "late_credit":
+		status = payment.LateCredited
+	default:
+		return c.answerCallback(callbackQuery.ID, "Неизвестная команда")
+	}
+
+	order, err := c.storage.GetPendingOrderByPaymentID(ctx, paymentID)
+	if err != nil || order == nil {
+		c.logger.Error("Failed to get order for late payment", "error", err, "payment_id", paymentID)
+		return c.answerCallback(callbackQuery.ID, "Заказ не найден")
+	}
+
+	p, err := c.storage.GetPayment(ctx, payment.GetCriteria{ID: &paymentID})
+	if err != nil || p == nil {
+		c.logger.Error("Failed to get late payment", "error", err, "payment_id", paymentID)
+		return c.answerCallback(callbackQuery.ID, "Платеж не найден")
+	}
+
+	// Решение принимает первый нажавший админ, остальные получат "уже обработано"
+	claimed, err := c.storage.ClaimLatePaymentResolution(ctx, paymentID, status)
+	if err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка")
+		return fmt.Errorf("claim late payment %d: %w", paymentID, err)
+	}
+	if !claimed {
+		return c.answerCallback(callbackQuery.ID, "Оплата уже обработана")
+	}
+	_ = c.answerCallback(callbackQuery.ID, "")
+
+	var resultText string
+	switch status {
+	case payment.LateFulfilled:
+		resultText, err = c.fulfill(ctx, order)
+	case payment.LateRefunded:
+		resultText, err = c.refund(ctx, adminTelegramID, order, p)
+	case payment.LateCredited:
+		resultText, err = c.credit(ctx, order, p)
+	}
+	if err != nil {
+		c.logger.Error("Failed to resolve late payment", "error", err, "payment_id", paymentID, "resolution", status)
+		c.releaseClaim(ctx, paymentID)
+		_, _ = c.bot.Send(tgbotapi.NewMessage(callbackQuery.Message.Chat.ID,
+			fmt.Sprintf("❌ Не удалось обработать платеж #%d: %v\nМожно выбрать действие еще раз.", paymentID, err)))
+		return nil
+	}
+
+	c.logger.Info("Late payment resolved",
+		"audit", true,
+		"admin_telegram_id", adminTelegramID,
+		"payment_id", paymentID,
+		"order_id", order.ID,
+		"resolution", status,
+		"amount", p.Amount,
+		"whatsapp", order.ClientWhatsApp)
+
+	editMsg := tgbotapi.NewEditMessageText(callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID,
+		fmt.Sprintf("💸 Оплата отмененного заказа #%d (платеж #%d, %.2f ₽)\n\n%s", order.ID, paymentID, p.Amount, resultText))
+	return telegram.SafeEdit(c.bot, editMsg, "")
+}
+
+// fulfill выдает подписку по отмененному заказу так же, как при обычной оплате, и сообщает ассистенту
+func (c *LatePaymentsCommand) fulfill(ctx context.Context, order *orders.PendingOrder) (string, error) {
+	var result *subs.CreateSubscriptionResult
+	var err error
+	if order.IsMigration() {
+		result, err = c.subscriptionService.MigrateSubscription(ctx, &subs.MigrateSubscriptionRequest{
+			UserID:              order.AdminUserID,
+			TariffID:            order.TariffID,
+			ServerID:            *order.ServerID,
+			ClientWhatsApp:      order.ClientWhatsApp,
+			CreatedByTelegramID: order.AssistantTelegramID,
+		})
+	} else {
+		result, err = c.subscriptionService.CreateSubscription(ctx, &subs.CreateSubscriptionRequest{
+			UserID:                 order.AdminUserID,
+			TariffID:               order.TariffID,
+			PaymentID:              &order.PaymentID,
+			ClientWhatsApp:         order.ClientWhatsApp,
+			CreatedByTelegramID:    order.AssistantTelegramID,
+			ReferrerSubscriptionID: order.ReferrerSubscriptionID,
+			ServerID:               order.TargetServerID,
+			Channel:                bonusrules.ChannelAutoCheck,
+		})
+	}
+	if err != nil {
+		return "", fmt.Errorf("create subscription: %w", err)
+	}
+
+	if err := c.storage.UpdatePendingOrderStatus(ctx, order.ID, orders.StatusCompleted); err != nil {
+		c.logger.Error("Failed to complete order after late payment", "error", err, "order_id", order.ID)
+	}
+
+	password := ""
+	if result.ServerUIPassword != nil {
+		password = *result.ServerUIPassword
+	}
+	assistantText := fmt.Sprintf(
+		"✅ *Клиент оплатил отмененный заказ — подписка выдана*\n\n"+
+			"*Клиент:* %s\n"+
+			"*Тариф:* %s\n"+
+			"*User ID:* `%s`\n"+
+			"*Пароль:* `%s`",
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, order.ClientWhatsApp),
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, order.TariffName),
+		result.GeneratedUserID, password)
+	msg := tgbotapi.NewMessage(order.ChatID, assistantText)
+	msg.ParseMode = "Markdown"
+	if result.ServerUIURL != nil && *result.ServerUIURL != "" {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL("Сервер", *result.ServerUIURL),
+		))
+	}
+	if _, err := c.bot.Send(msg); err != nil {
+		c.logger.Error("Failed to notify assistant about late payment", "error", err, "order_id", order.ID)
+	}
+
+	return fmt.Sprintf("✅ Подписка #%d выдана клиенту %s, ассистент получил данные", result.Subscription.ID, order.ClientWhatsApp), nil
+}
+
+// refund возвращает деньги клиенту через ЮKassa
+func (c *LatePaymentsCommand) refund(ctx context.Context, adminTelegramID int64, order *orders.PendingOrder, p *payment.Payment) (string, error) {
+	err := c.paymentService.RefundPayment(ctx, payment.RefundRequest{
+		PaymentID:             p.ID,
+		Reason:                payment.RefundLatePayment,
+		RequestedByTelegramID: adminTelegramID,
+		Description:           fmt.Sprintf("Возврат оплаты отмененного заказа #%d", order.ID),
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("↩️ %.2f ₽ возвращены клиенту %s", p.Amount, order.ClientWhatsApp), nil
+}
+
+// credit зачисляет сумму платежа на баланс клиента
+func (c *LatePaymentsCommand) credit(ctx context.Context, order *orders.PendingOrder, p *payment.Payment) (string, error) {
+	balance, err := c.storage.AddClientBalance(ctx, order.ClientWhatsApp, p.Amount)
+	if err != nil {
+		return "", fmt.Errorf("add client balance: %w", err)
+	}
+	return fmt.Sprintf("💰 %.2f ₽ зачислены на баланс клиента %s (теперь %.2f ₽)", p.Amount, order.ClientWhatsApp, balance), nil
+}
+
+// releaseClaim возвращает оплату в ожидание решения, если выбранное действие не удалось
+func (c *LatePaymentsCommand) releaseClaim(ctx context.Context, paymentID int64) {
+	detected := payment.LateDetected
+	if _, err := c.storage.UpdatePayment(ctx, payment.GetCriteria{ID: &paymentID}, payment.UpdateParams{LateStatus: &detected}); err != nil {
+		c.logger.Error("Failed to release late payment", "error", err, "payment_id", paymentID)
+	}
+}
+
+func (c *LatePaymentsCommand) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := c.bot.Request(callback)
+	return err
+}
@@ -0,0 +1,134 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/phonemerge"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// mergePhonesPerPage - клиентов в одном списке; после объединения список обновляется и показывает следующих
+const mergePhonesPerPage = 10
+
+// MergePhonesCommand находит клиентов, подписки которых записаны под разными форматами одного номера
+// ("+996..." и "996..."), и по кнопке админа переносит их на номер из одних цифр (phm:<номер>)
+type MergePhonesCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage MergePhonesStorage
+	logger  *slog.Logger
+}
+
+type MergePhonesStorage interface {
+	ListPhoneDuplicates(ctx context.Context) ([]phonemerge.Group, error)
+	MergePhoneDuplicates(ctx context.Context, canonical string) (int64, error)
+}
+
+func NewMergePhonesCommand(bot *tgbotapi.BotAPI, storage MergePhonesStorage, logger *slog.Logger) *MergePhonesCommand {
+	return &MergePhonesCommand{
+		bot:     bot,
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Execute показывает клиентов с несколькими форматами номера
+func (c *MergePhonesCommand) Execute(ctx context.Context, chatID int64) error {
+	return c.showList(ctx, chatID, 0)
+}
+
+// HandleCallback объединяет форматы номера клиента (phm:<номер>) и обновляет список
+func (c *MergePhonesCommand) HandleCallback(ctx context.Context, adminTelegramID int64, query *tgbotapi.CallbackQuery) error {
+	canonical := strings.TrimPrefix(query.Data, "phm:")
+	if canonical == "" {
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "Неверный формат"))
+		return nil
+	}
+
+	moved, err := c.storage.MergePhoneDuplicates(ctx, canonical)
+	if err != nil {
+		c.logger.Error("Failed to merge phone duplicates", "error", err, "whatsapp", canonical)
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "❌ Ошибка объединения"))
+		return nil
+	}
+
+	c.logger.Info("Client phone formats merged",
+		"audit", true,
+		"admin_telegram_id", adminTelegramID,
+		"whatsapp", canonical,
+		"moved_subscriptions", moved,
+	)
+	_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, fmt.Sprintf("✅ Перенесено подписок: %d", moved)))
+
+	return c.showList(ctx, query.Message.Chat.ID, query.Message.MessageID)
+}
+
+func (c *MergePhonesCommand) showList(ctx context.Context, chatID int64, messageID int) error {
+	groups, err := c.storage.ListPhoneDuplicates(ctx)
+	if err != nil {
+		c.logger.Error("Failed to list phone duplicates", "error", err)
+		return c.send(chatID, "❌ Ошибка поиска дублей номеров")
+	}
+
+	text, keyboard := formatPhoneDuplicates(groups)
+	if messageID > 0 {
+		editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text)
+		editMsg.ParseMode = "Markdown"
+		if keyboard != nil {
+			editMsg.ReplyMarkup = keyboard
+		}
+		return telegram.SafeEdit(c.bot, editMsg, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+// formatPhoneDuplicates - список клиентов с форматами номера и предупреждениями; кнопка на каждого клиента
+func formatPhoneDuplicates(groups []phonemerge.Group) (string, *tgbotapi.InlineKeyboardMarkup) {
+	if len(groups) == 0 {
+		return "📞 *Дубли номеров*\n\n_Все клиенты записаны под одним форматом номера_", nil
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "📞 *Дубли номеров: %d*\n\n", len(groups))
+	text.WriteString("Подписки клиента записаны под разными форматами номера. " +
+		"Объединение переносит их, баланс, язык и отказ от рассылки на номер из одних цифр.\n")
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, group := range groups[:min(len(groups), mergePhonesPerPage)] {
+		fmt.Fprintf(&text, "\n• `%s` — подписок: %d\n", group.Canonical, group.Subscriptions())
+		for _, v := range group.Variants {
+			fmt.Fprintf(&text, "  `%s`: %d, активных %d\n", v.Phone, v.Subscriptions, v.ActiveSubscriptions)
+		}
+		if group.HasActiveConflict() {
+			text.WriteString("  ⚠️ Активные подписки под разными форматами: после объединения проверьте, не лишняя ли одна\n")
+		}
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔗 Объединить: "+group.Canonical, "phm:"+group.Canonical),
+		))
+	}
+	if len(groups) > mergePhonesPerPage {
+		fmt.Fprintf(&text, "\n_…и еще %d, появятся после объединения_", len(groups)-mergePhonesPerPage)
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return text.String(), &keyboard
+}
+
+func (c *MergePhonesCommand) send(chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	_, err := c.bot.Send(msg)
+	return err
+}
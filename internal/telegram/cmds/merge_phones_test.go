@@ -0,0 +1,55 @@
+package cmds
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"kurut-bot/internal/stories/phonemerge"
+)
+
+func TestFormatPhoneDuplicates(t *testing.T) {
+	text, keyboard := formatPhoneDuplicates(nil)
+	if keyboard != nil || !strings.Contains(text, "одним форматом") {
+		t.Errorf("empty list: text = %q, keyboard = %v", text, keyboard)
+	}
+
+	groups := []phonemerge.Group{{
+		Canonical: "996700123456",
+		Variants: []phonemerge.Variant{
+			{Phone: "+996700123456", Subscriptions: 2, ActiveSubscriptions: 1},
+			{Phone: "996700123456", Subscriptions: 1, ActiveSubscriptions: 1},
+		},
+	}}
+	text, keyboard = formatPhoneDuplicates(groups)
+	for _, want := range []string{"`996700123456` — подписок: 3", "`+996700123456`: 2, активных 1", "⚠️"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text does not contain %q:\n%s", want, text)
+		}
+	}
+	if keyboard == nil || len(keyboard.InlineKeyboard) != 1 || *keyboard.InlineKeyboard[0][0].CallbackData != "phm:996700123456" {
+		t.Errorf("keyboard = %+v, want one phm:996700123456 button", keyboard)
+	}
+}
+
+func TestFormatPhoneDuplicatesPaginates(t *testing.T) {
+	var groups []phonemerge.Group
+	for i := range mergePhonesPerPage + 3 {
+		phone := fmt.Sprintf("99670000%04d", i)
+		groups = append(groups, phonemerge.Group{
+			Canonical: phone,
+			Variants:  []phonemerge.Variant{{Phone: "+" + phone, Subscriptions: 1}, {Phone: phone, Subscriptions: 1}},
+		})
+	}
+
+	text, keyboard := formatPhoneDuplicates(groups)
+	if len(keyboard.InlineKeyboard) != mergePhonesPerPage {
+		t.Errorf("buttons = %d, want %d", len(keyboard.InlineKeyboard), mergePhonesPerPage)
+	}
+	if !strings.Contains(text, "еще 3") {
+		t.Errorf("text does not mention remaining groups:\n%s", text)
+	}
+	if strings.Contains(text, "⚠️") {
+		t.Errorf("unexpected conflict warning:\n%s", text)
+	}
+}
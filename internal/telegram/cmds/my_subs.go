@@ -3,8 +3,12 @@ package cmds
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
+	"kurut-bot/internal/infra/telegram"
 	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -16,8 +20,15 @@ type MySubsCommand struct {
 
 type MySubsStorage interface {
 	GetAssistantStats(ctx context.Context, assistantTelegramID int64) (*storage.AssistantStats, error)
+	ListSubscriptionsPage(ctx context.Context, criteria subs.PageCriteria) ([]storage.SubscriptionDetails, error)
 }
 
+// mySubsPageSize - сколько подписок показывать на одной странице /my_subs
+const mySubsPageSize = 10
+
+// mySubsStatuses - какие подписки показываем в списке: рабочие, на паузе и недавно истекшие
+var mySubsStatuses = []subs.Status{subs.StatusActive, subs.StatusPaused, subs.StatusExpired}
+
 func NewMySubsCommand(bot *tgbotapi.BotAPI, storage MySubsStorage) *MySubsCommand {
 	return &MySubsCommand{
 		bot:     bot,
@@ -45,8 +56,85 @@ func (c *MySubsCommand) Execute(ctx context.Context, assistantTelegramID int64,
 		stats.CreatedLastWeek,
 	)
 
+	keyboard, count, err := c.pageKeyboard(ctx, assistantTelegramID, nil)
+	if err != nil {
+		return fmt.Errorf("list assistant subscriptions: %w", err)
+	}
+	if count > 0 {
+		text += "\n\nНажмите на подписку, чтобы открыть карточку:"
+	}
+
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "Markdown"
+	if count > 0 {
+		msg.ReplyMarkup = keyboard
+	}
 	_, err = c.bot.Send(msg)
 	return err
 }
+
+// HandleMore показывает следующую страницу списка (callback my_subs_more:lastID)
+func (c *MySubsCommand) HandleMore(ctx context.Context, assistantTelegramID int64, callbackQuery *tgbotapi.CallbackQuery) error {
+	beforeID, err := strconv.ParseInt(strings.TrimPrefix(callbackQuery.Data, "my_subs_more:"), 10, 64)
+	if err != nil {
+		_, _ = c.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "Неверный формат"))
+		return nil
+	}
+
+	keyboard, count, err := c.pageKeyboard(ctx, assistantTelegramID, &beforeID)
+	if err != nil {
+		_, _ = c.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "Ошибка"))
+		return fmt.Errorf("list assistant subscriptions: %w", err)
+	}
+	if count == 0 {
+		_, _ = c.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "Больше подписок нет"))
+		return nil
+	}
+	_, _ = c.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, ""))
+
+	return telegram.SafeEdit(c.bot, tgbotapi.NewEditMessageReplyMarkup(callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID, keyboard), "")
+}
+
+// pageKeyboard строит страницу подписок ассистента: каждая строка открывает карточку (sub_view:ID)
+func (c *MySubsCommand) pageKeyboard(ctx context.Context, assistantTelegramID int64, beforeID *int64) (tgbotapi.InlineKeyboardMarkup, int, error) {
+	page, err := c.storage.ListSubscriptionsPage(ctx, subs.PageCriteria{
+		CreatedByTelegramID: assistantTelegramID,
+		Status:              mySubsStatuses,
+		BeforeID:            beforeID,
+		Limit:               mySubsPageSize,
+	})
+	if err != nil {
+		return tgbotapi.InlineKeyboardMarkup{}, 0, err
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(page)+1)
+	for _, details := range page {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(SubViewButton(mySubsLabel(details.Subscription), details.Subscription.ID)))
+	}
+	if len(page) == mySubsPageSize {
+		lastID := page[len(page)-1].Subscription.ID
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➡️ Ещё", fmt.Sprintf("my_subs_more:%d", lastID)),
+		))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...), len(page), nil
+}
+
+// mySubsLabel - текст кнопки подписки: "#12 · 996555123456 · до 01.02.2026", истекшие помечены ⌛, на паузе - ⏸
+func mySubsLabel(sub *subs.Subscription) string {
+	label := fmt.Sprintf("#%d", sub.ID)
+	switch {
+	case sub.Status == subs.StatusPaused:
+		label = "⏸ " + label
+	case sub.Status != subs.StatusActive:
+		label = "⌛ " + label
+	}
+	if sub.ClientWhatsApp != nil {
+		label += " · " + *sub.ClientWhatsApp
+	}
+	if sub.ExpiresAt != nil {
+		label += " · до " + sub.ExpiresAt.Format("02.01.2006")
+	}
+	return label
+}
@@ -0,0 +1,72 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type ReferralStorage interface {
+	FindActiveSubscriptionByWhatsApp(ctx context.Context, whatsapp string) (*subs.Subscription, error)
+}
+
+// ReferralCommand выдает реферальную ссылку клиента: t.me/<bot>?start=ref_<subID>.
+// Ассистент, открывший ссылку, сразу попадает в создание подписки с уже указанным пригласившим
+type ReferralCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage ReferralStorage
+	logger  *slog.Logger
+}
+
+func NewReferralCommand(bot *tgbotapi.BotAPI, storage ReferralStorage, logger *slog.Logger) *ReferralCommand {
+	return &ReferralCommand{
+		bot:     bot,
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// ReferralLink возвращает deep-link бота с реферальным параметром для подписки
+func ReferralLink(botUsername string, subscriptionID int64) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s", botUsername, subs.ReferralStartPayload(subscriptionID))
+}
+
+// Execute обрабатывает /referral <номер WhatsApp клиента>
+func (c *ReferralCommand) Execute(ctx context.Context, chatID int64, args string) error {
+	phone := storage.NormalizePhone(args)
+	if phone == "" {
+		return c.send(chatID, "Используйте: /referral <номер WhatsApp клиента>\n\n"+
+			"Бот пришлет ссылку, по которой пригласившему начислится бонус за нового клиента.")
+	}
+
+	sub, err := c.storage.FindActiveSubscriptionByWhatsApp(ctx, phone)
+	if err != nil {
+		_ = c.send(chatID, "❌ Ошибка поиска клиента")
+		return fmt.Errorf("find active subscription: %w", err)
+	}
+	if sub == nil {
+		return c.send(chatID, fmt.Sprintf("❌ У клиента +%s нет активной подписки: пригласить может только действующий клиент", phone))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔗 Реферальная ссылка клиента +%s\n\n", phone)
+	b.WriteString(ReferralLink(c.bot.Self.UserName, sub.ID))
+	b.WriteString("\n\nКогда приглашенный напишет, откройте эту ссылку: создание подписки начнется " +
+		"с уже указанным пригласившим, а бонус начислится при первой оплате.")
+
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.DisableWebPagePreview = true
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+func (c *ReferralCommand) send(chatID int64, text string) error {
+	_, err := c.bot.Send(tgbotapi.NewMessage(chatID, text))
+	return err
+}
@@ -0,0 +1,11 @@
+package cmds
+
+import "testing"
+
+func TestReferralLink(t *testing.T) {
+	got := ReferralLink("kurut_vpn_bot", 42)
+	want := "https://t.me/kurut_vpn_bot?start=ref_42"
+	if got != want {
+		t.Errorf("ReferralLink() = %q, want %q", got, want)
+	}
+}
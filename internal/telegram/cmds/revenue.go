@@ -0,0 +1,190 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// RevenuePeriod - период отчета о выручке, выбираемый кнопкой
+type RevenuePeriod string
+
+const (
+	RevenueToday RevenuePeriod = "today"
+	RevenueWeek  RevenuePeriod = "week"
+	RevenueMonth RevenuePeriod = "month"
+)
+
+// maxRevenueRangeDays - ограничение произвольного периода, чтобы отчет не сканировал всю историю
+const maxRevenueRangeDays = 366
+
+type RevenueCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage RevenueStorage
+	now     func() time.Time
+}
+
+type RevenueStorage interface {
+	GetRevenueReport(ctx context.Context, from, to time.Time) (*storage.RevenueReport, error)
+}
+
+func NewRevenueCommand(bot *tgbotapi.BotAPI, storage RevenueStorage) *RevenueCommand {
+	return &RevenueCommand{
+		bot:     bot,
+		storage: storage,
+		now:     time.Now,
+	}
+}
+
+// Execute показывает выручку за сегодня, а с аргументом (/revenue 01.09.2026 15.09.2026) - за указанные дни
+func (c *RevenueCommand) Execute(ctx context.Context, chatID int64, args string) error {
+	from, to := RevenuePeriodRange(RevenueToday, c.now())
+	if strings.TrimSpace(args) != "" {
+		var err error
+		from, to, err = ParseRevenueRange(args)
+		if err != nil {
+			_, sendErr := c.bot.Send(tgbotapi.NewMessage(chatID, "❌ "+err.Error()+"\n\nФормат: /revenue 01.09.2026 15.09.2026 или /revenue 01.09.2026"))
+			return sendErr
+		}
+	}
+
+	report, err := c.storage.GetRevenueReport(ctx, from, to)
+	if err != nil {
+		_, _ = c.bot.Send(tgbotapi.NewMessage(chatID, "Ошибка при получении выручки"))
+		return fmt.Errorf("get revenue report: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, c.formatReport(report))
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = revenueKeyboard()
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+// HandleCallback переключает период отчета (rev_today, rev_week, rev_month) на месте сообщения
+func (c *RevenueCommand) HandleCallback(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery) error {
+	period := RevenuePeriod(strings.TrimPrefix(callbackQuery.Data, "rev_"))
+	switch period {
+	case RevenueToday, RevenueWeek, RevenueMonth:
+	default:
+		_, _ = c.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "Неизвестный период"))
+		return nil
+	}
+	_, _ = c.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, ""))
+
+	from, to := RevenuePeriodRange(period, c.now())
+	report, err := c.storage.GetRevenueReport(ctx, from, to)
+	if err != nil {
+		return fmt.Errorf("get revenue report: %w", err)
+	}
+
+	keyboard := revenueKeyboard()
+	edit := tgbotapi.NewEditMessageText(callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID, c.formatReport(report))
+	edit.ParseMode = "Markdown"
+	edit.ReplyMarkup = &keyboard
+	return telegram.SafeEdit(c.bot, edit, "")
+}
+
+func revenueKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Сегодня", "rev_today"),
+			tgbotapi.NewInlineKeyboardButtonData("Неделя", "rev_week"),
+			tgbotapi.NewInlineKeyboardButtonData("Месяц", "rev_month"),
+		),
+	)
+}
+
+// RevenuePeriodRange возвращает границы [from, to) периода: с начала дня, недели (с понедельника) или месяца до конца текущего дня.
+// Дни считаются по UTC, как и в выручке /stats
+func RevenuePeriodRange(period RevenuePeriod, now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	to := today.AddDate(0, 0, 1)
+
+	switch period {
+	case RevenueWeek:
+		daysSinceMonday := (int(today.Weekday()) + 6) % 7
+		return today.AddDate(0, 0, -daysSinceMonday), to
+	case RevenueMonth:
+		return time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC), to
+	default:
+		return today, to
+	}
+}
+
+// ParseRevenueRange разбирает произвольный период "ДД.ММ.ГГГГ ДД.ММ.ГГГГ" или один день "ДД.ММ.ГГГГ".
+// Последний день входит в период
+func ParseRevenueRange(args string) (time.Time, time.Time, error) {
+	fields := strings.Fields(strings.ReplaceAll(args, "-", " "))
+	if len(fields) == 0 || len(fields) > 2 {
+		return time.Time{}, time.Time{}, errors.New("укажите одну или две даты")
+	}
+
+	from, err := time.Parse("02.01.2006", fields[0])
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("неверная дата %s", fields[0])
+	}
+	last := from
+	if len(fields) == 2 {
+		last, err = time.Parse("02.01.2006", fields[1])
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("неверная дата %s", fields[1])
+		}
+	}
+
+	if last.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("конец периода раньше начала")
+	}
+	to := last.AddDate(0, 0, 1)
+	if to.Sub(from) > maxRevenueRangeDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("период длиннее %d дней", maxRevenueRangeDays)
+	}
+	return from, to, nil
+}
+
+func (c *RevenueCommand) formatReport(report *storage.RevenueReport) string {
+	var text strings.Builder
+
+	last := report.To.AddDate(0, 0, -1)
+	if last.Equal(report.From) {
+		text.WriteString(fmt.Sprintf("💰 *Выручка за %s*\n\n", report.From.Format("02.01.2006")))
+	} else {
+		text.WriteString(fmt.Sprintf("💰 *Выручка за %s — %s*\n\n", report.From.Format("02.01.2006"), last.Format("02.01.2006")))
+	}
+
+	text.WriteString(fmt.Sprintf("*Всего:* %.2f ₽ (платежей: %d)\n", report.Total, report.PaymentsCount))
+	if report.PaymentsCount == 0 {
+		return text.String()
+	}
+
+	text.WriteString("\n*По тарифам:*\n")
+	for _, row := range report.ByTariff {
+		name := tgbotapi.EscapeText(tgbotapi.ModeMarkdown, row.TariffName)
+		if row.TariffID == nil {
+			name = "без подписки"
+		} else if name == "" {
+			name = fmt.Sprintf("тариф #%d", *row.TariffID)
+		}
+		text.WriteString(fmt.Sprintf("• %s: *%.2f ₽* (%d)\n", name, row.Amount, row.PaymentsCount))
+	}
+
+	text.WriteString("\n*По ассистентам:*\n")
+	for _, row := range report.ByAssistant {
+		name := "неизвестно"
+		if row.AssistantTelegramID != nil {
+			name = tgbotapi.EscapeText(tgbotapi.ModeMarkdown, telegramUserName(c.bot, *row.AssistantTelegramID))
+		}
+		text.WriteString(fmt.Sprintf("• %s: *%.2f ₽* (%d)\n", name, row.Amount, row.PaymentsCount))
+	}
+
+	text.WriteString("\n_Платеж за несколько подписок делится между ними поровну_")
+	return text.String()
+}
@@ -0,0 +1,51 @@
+package cmds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRevenuePeriodRange(t *testing.T) {
+	// Четверг
+	now := time.Date(2026, 10, 15, 18, 30, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		period RevenuePeriod
+		from   time.Time
+	}{
+		{RevenueToday, day(15)},
+		{RevenueWeek, day(12)},
+		{RevenueMonth, day(1)},
+	}
+	for _, tt := range tests {
+		from, to := RevenuePeriodRange(tt.period, now)
+		if !from.Equal(tt.from) || !to.Equal(day(16)) {
+			t.Errorf("RevenuePeriodRange(%s) = %v - %v, want %v - %v", tt.period, from, to, tt.from, day(16))
+		}
+	}
+
+	// В воскресенье неделя начинается с прошедшего понедельника
+	from, _ := RevenuePeriodRange(RevenueWeek, time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC))
+	if !from.Equal(day(12)) {
+		t.Errorf("week on sunday starts %v, want %v", from, day(12))
+	}
+}
+
+func TestParseRevenueRange(t *testing.T) {
+	from, to, err := ParseRevenueRange("01.09.2026 15.09.2026")
+	if err != nil || !from.Equal(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2026, 9, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseRevenueRange(range) = %v, %v, %v", from, to, err)
+	}
+
+	from, to, err = ParseRevenueRange("01.09.2026")
+	if err != nil || to.Sub(from) != 24*time.Hour {
+		t.Errorf("ParseRevenueRange(day) = %v, %v, %v, want one day", from, to, err)
+	}
+
+	for _, input := range []string{"", "2026-09-01", "15.09.2026 01.09.2026", "01.01.2025 01.09.2026", "01.09.2026 02.09.2026 03.09.2026"} {
+		if _, _, err := ParseRevenueRange(input); err == nil {
+			t.Errorf("ParseRevenueRange(%q) accepted invalid range", input)
+		}
+	}
+}
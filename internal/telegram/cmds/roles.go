@@ -0,0 +1,259 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/users"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const rolesUsage = "🎭 *Роли*\n\n" +
+	"`/set_role 123456789` — выбрать роль кнопками\n" +
+	"`/set_role 123456789 viewer` — назначить роль сразу\n" +
+	"`/add_admin 123456789`, `/remove_admin 123456789` — то же, что роль admin и assistant\n\n" +
+	"Роли: `admin` — всё, `assistant` — создание подписок, `viewer` — только просмотр, `none` — снять роль.\n" +
+	"Админов назначает и снимает только супер-админ, супер-админы меняются только через TELEGRAM\\_ADMIN\\_IDS"
+
+// RolesCommand - назначение ролей через бота (/set_role, callback role_set:ID:роль)
+type RolesCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage RolesStorage
+	roles   roleResolver
+	logger  *slog.Logger
+}
+
+type RolesStorage interface {
+	SetUserRole(ctx context.Context, telegramID int64, role users.Role) error
+	ListUserRoles(ctx context.Context) (map[int64]users.Role, error)
+}
+
+// staffProvider - текущие админы и ассистенты: из конфига и назначенные через /set_role.
+// Список запрашивается при каждом использовании, чтобы смена роли применялась без перезапуска
+type staffProvider interface {
+	StaffIDs() []int64
+}
+
+// adminChatsProvider - куда слать служебные уведомления: админская группа или текущие админы
+type adminChatsProvider interface {
+	AdminChatIDs() []int64
+}
+
+// roleResolver - текущие роли с учетом конфига; кэш нужно сбросить после изменения.
+// Супер-админы - админы из конфига, их роль не меняется
+type roleResolver interface {
+	Role(telegramID int64) users.Role
+	IsSuperAdmin(telegramID int64) bool
+	SuperAdminIDs() []int64
+	Invalidate()
+}
+
+// NewRolesCommand создает команду
+func NewRolesCommand(
+	bot *tgbotapi.BotAPI,
+	storage RolesStorage,
+	roles roleResolver,
+	logger *slog.Logger,
+) *RolesCommand {
+	return &RolesCommand{
+		bot:     bot,
+		storage: storage,
+		roles:   roles,
+		logger:  logger,
+	}
+}
+
+// ParseRoleArgs разбирает аргументы /set_role: Telegram ID и необязательную роль
+func ParseRoleArgs(args string) (telegramID int64, role users.Role, hasRole bool, err error) {
+	parts := strings.Fields(args)
+	if len(parts) == 0 || len(parts) > 2 {
+		return 0, users.RoleNone, false, errors.New("укажите Telegram ID")
+	}
+
+	telegramID, err = strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || telegramID <= 0 {
+		return 0, users.RoleNone, false, errors.New("неверный Telegram ID")
+	}
+	if len(parts) == 1 {
+		return telegramID, users.RoleNone, false, nil
+	}
+
+	role, ok := users.ParseRole(strings.ToLower(parts[1]))
+	if !ok {
+		return 0, users.RoleNone, false, fmt.Errorf("неизвестная роль %q", parts[1])
+	}
+	return telegramID, role, true, nil
+}
+
+// Execute обрабатывает /set_role; без аргументов показывает назначенные роли
+func (c *RolesCommand) Execute(ctx context.Context, actorID, chatID int64, args string) error {
+	if strings.TrimSpace(args) == "" {
+		return c.showList(ctx, chatID)
+	}
+
+	telegramID, role, hasRole, err := ParseRoleArgs(args)
+	if err != nil {
+		return c.send(chatID, fmt.Sprintf("❌ %s\n\n%s", err, rolesUsage))
+	}
+	if !hasRole {
+		return c.showChoice(actorID, chatID, telegramID)
+	}
+
+	text, err := c.setRole(ctx, actorID, telegramID, role)
+	if err != nil {
+		return c.send(chatID, "❌ Ошибка сохранения")
+	}
+	return c.send(chatID, text)
+}
+
+// HandleCallback обрабатывает выбор роли кнопкой (role_set:ID:роль)
+func (c *RolesCommand) HandleCallback(ctx context.Context, actorID int64, callbackQuery *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(strings.TrimPrefix(callbackQuery.Data, "role_set:"), ":")
+	if len(parts) != 2 {
+		_, _ = c.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "Неверный формат"))
+		return nil
+	}
+	telegramID, err := strconv.ParseInt(parts[0], 10, 64)
+	role, ok := users.ParseRole(parts[1])
+	if err != nil || !ok {
+		_, _ = c.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "Неверный формат"))
+		return nil
+	}
+
+	text, err := c.setRole(ctx, actorID, telegramID, role)
+	if err != nil {
+		_, _ = c.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "❌ Ошибка сохранения"))
+		return nil
+	}
+	_, _ = c.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, ""))
+
+	edit := tgbotapi.NewEditMessageText(callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID, text)
+	edit.ParseMode = "Markdown"
+	return telegram.SafeEdit(c.bot, edit, "")
+}
+
+// setRole проверяет права и сохраняет роль; возвращает текст ответа админу.
+// Ошибка возвращается только при сбое БД
+func (c *RolesCommand) setRole(ctx context.Context, actorID, telegramID int64, role users.Role) (string, error) {
+	if c.roles.IsSuperAdmin(telegramID) {
+		return fmt.Sprintf("`%d` — супер-админ, его роль меняется только в конфиге", telegramID), nil
+	}
+	if telegramID == actorID {
+		return "❌ Нельзя менять собственную роль", nil
+	}
+
+	current := c.roles.Role(telegramID)
+	if (role == users.RoleAdmin || current == users.RoleAdmin) && !c.roles.IsSuperAdmin(actorID) {
+		return "❌ Назначать и снимать админов может только супер-админ", nil
+	}
+	if current == role {
+		return fmt.Sprintf("У `%d` уже роль %s", telegramID, role.Title()), nil
+	}
+
+	if err := c.storage.SetUserRole(ctx, telegramID, role); err != nil {
+		c.logger.Error("Failed to set user role", "error", err, "telegram_id", telegramID, "role", role)
+		return "", err
+	}
+	c.roles.Invalidate()
+
+	c.logger.Info("User role changed",
+		"audit", true,
+		"admin_telegram_id", actorID,
+		"telegram_id", telegramID,
+		"old_role", current,
+		"role", role,
+	)
+
+	notice := fmt.Sprintf("🎭 Ваша роль в боте: %s. Нажмите /start", role.Title())
+	if role == users.RoleNone {
+		notice = "🚫 Доступ к боту закрыт"
+	}
+	if _, err := c.bot.Send(tgbotapi.NewMessage(telegramID, notice)); err != nil {
+		c.logger.Warn("Failed to notify user about role", "error", err, "telegram_id", telegramID)
+	}
+	return fmt.Sprintf("✅ `%d`: %s → %s", telegramID, current.Title(), role.Title()), nil
+}
+
+func (c *RolesCommand) showChoice(actorID, chatID, telegramID int64) error {
+	if c.roles.IsSuperAdmin(telegramID) {
+		return c.send(chatID, fmt.Sprintf("`%d` — супер-админ, его роль меняется только в конфиге", telegramID))
+	}
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, role := range users.Roles {
+		if role == users.RoleAdmin && !c.roles.IsSuperAdmin(actorID) {
+			continue
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(role.Title(), fmt.Sprintf("role_set:%d:%s", telegramID, role)))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🎭 `%d`, сейчас: %s\n\nВыберите роль:", telegramID, c.roles.Role(telegramID).Title()))
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		row,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🚫 Снять роль", fmt.Sprintf("role_set:%d:none", telegramID)),
+		),
+	)
+	_, err := c.bot.Send(msg)
+	return err
+}
+
+// ExecuteAlias обрабатывает /add_admin и /remove_admin - короткие формы /set_role <ID> admin
+// и /set_role <ID> assistant; без аргументов показывает назначенные роли
+func (c *RolesCommand) ExecuteAlias(ctx context.Context, actorID, chatID int64, args string, role users.Role) error {
+	switch len(strings.Fields(args)) {
+	case 0:
+		return c.showList(ctx, chatID)
+	case 1:
+		return c.Execute(ctx, actorID, chatID, strings.TrimSpace(args)+" "+string(role))
+	default:
+		return c.send(chatID, "❌ Укажите только Telegram ID\n\n"+rolesUsage)
+	}
+}
+
+func (c *RolesCommand) showList(ctx context.Context, chatID int64) error {
+	roles, err := c.storage.ListUserRoles(ctx)
+	if err != nil {
+		c.logger.Error("Failed to list user roles", "error", err)
+		return c.send(chatID, "❌ Ошибка получения списка ролей")
+	}
+
+	byRole := make(map[users.Role][]int64)
+	for id, role := range roles {
+		byRole[role] = append(byRole[role], id)
+	}
+
+	var text strings.Builder
+	text.WriteString(rolesUsage)
+	text.WriteString("\n\n*Супер-админы:*\n")
+	for _, id := range c.roles.SuperAdminIDs() {
+		fmt.Fprintf(&text, "• `%d`\n", id)
+	}
+	for _, role := range users.Roles {
+		ids := byRole[role]
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		fmt.Fprintf(&text, "\n*%s:*\n", role.Title())
+		if len(ids) == 0 {
+			text.WriteString("пока нет\n")
+		}
+		for _, id := range ids {
+			fmt.Fprintf(&text, "• `%d`\n", id)
+		}
+	}
+	return c.send(chatID, text.String())
+}
+
+func (c *RolesCommand) send(chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	_, err := c.bot.Send(msg)
+	return err
+}
@@ -0,0 +1,39 @@
+package cmds
+
+import (
+	"testing"
+
+	"kurut-bot/internal/stories/users"
+)
+
+func TestParseRoleArgs(t *testing.T) {
+	tests := []struct {
+		args        string
+		wantID      int64
+		wantRole    users.Role
+		wantHasRole bool
+		wantErr     bool
+	}{
+		{"123456789", 123456789, users.RoleNone, false, false},
+		{"  42 viewer ", 42, users.RoleViewer, true, false},
+		{"42 Admin", 42, users.RoleAdmin, true, false},
+		{"42 none", 42, users.RoleNone, true, false},
+		{"", 0, users.RoleNone, false, true},
+		{"@user", 0, users.RoleNone, false, true},
+		{"-5", 0, users.RoleNone, false, true},
+		{"42 owner", 0, users.RoleNone, false, true},
+		{"42 viewer extra", 0, users.RoleNone, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			id, role, hasRole, err := ParseRoleArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRoleArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if id != tt.wantID || role != tt.wantRole || hasRole != tt.wantHasRole {
+				t.Errorf("ParseRoleArgs(%q) = %d, %q, %v, want %d, %q, %v", tt.args, id, role, hasRole, tt.wantID, tt.wantRole, tt.wantHasRole)
+			}
+		})
+	}
+}
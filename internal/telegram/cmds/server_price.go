@@ -0,0 +1,140 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/stories/servers"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxPriceMultiplier - защита от опечаток в множителе цены сервера
+const maxPriceMultiplier = 10
+
+const serverPriceUsage = "🖥 *Наценка сервера*\n\n" +
+	"`/server_price 3 x1.5` — цены тарифов на сервере #3 в 1.5 раза выше\n" +
+	"`/server_price 3 +100` — +100 ₽ к цене тарифа\n" +
+	"`/server_price 3 x1.2 +50` — множитель и доплата вместе\n" +
+	"`/server_price 3 off` — без наценки"
+
+// ServerPriceCommand задает наценку сервера (премиальные локации дороже)
+type ServerPriceCommand struct {
+	bot           *tgbotapi.BotAPI
+	serverService ServerPriceService
+	logger        *slog.Logger
+}
+
+type ServerPriceService interface {
+	GetServer(ctx context.Context, criteria servers.GetCriteria) (*servers.Server, error)
+	UpdateServer(ctx context.Context, criteria servers.GetCriteria, params servers.UpdateParams) (*servers.Server, error)
+}
+
+func NewServerPriceCommand(bot *tgbotapi.BotAPI, serverService ServerPriceService, logger *slog.Logger) *ServerPriceCommand {
+	return &ServerPriceCommand{
+		bot:           bot,
+		serverService: serverService,
+		logger:        logger,
+	}
+}
+
+// ParseServerPriceArgs разбирает "<ID сервера> <xМНОЖИТЕЛЬ|±ДОПЛАТА|off>..."; не указанная часть сбрасывается
+func ParseServerPriceArgs(args string) (int64, float64, float64, error) {
+	parts := strings.Fields(args)
+	if len(parts) < 2 {
+		return 0, 0, 0, errors.New("укажите ID сервера и наценку")
+	}
+
+	serverID, err := strconv.ParseInt(strings.TrimPrefix(parts[0], "#"), 10, 64)
+	if err != nil || serverID <= 0 {
+		return 0, 0, 0, errors.New("неверный ID сервера")
+	}
+
+	if len(parts) == 2 && strings.EqualFold(parts[1], "off") {
+		return serverID, 1, 0, nil
+	}
+
+	multiplier, surcharge := 1.0, 0.0
+	for _, part := range parts[1:] {
+		value := strings.ReplaceAll(part, ",", ".")
+		switch {
+		case strings.HasPrefix(value, "x"), strings.HasPrefix(value, "×"), strings.HasPrefix(value, "*"):
+			value = strings.TrimLeft(value, "x×*")
+			multiplier, err = strconv.ParseFloat(value, 64)
+			if err != nil {
+				return 0, 0, 0, errors.New("неверный формат множителя")
+			}
+			if multiplier <= 0 || multiplier > maxPriceMultiplier {
+				return 0, 0, 0, fmt.Errorf("множитель должен быть больше 0 и не больше %d", maxPriceMultiplier)
+			}
+		case strings.HasPrefix(value, "+"), strings.HasPrefix(value, "-"):
+			surcharge, err = strconv.ParseFloat(value, 64)
+			if err != nil {
+				return 0, 0, 0, errors.New("неверный формат доплаты")
+			}
+			if surcharge < -maxCustomPrice || surcharge > maxCustomPrice {
+				return 0, 0, 0, fmt.Errorf("доплата должна быть от -%d до %d ₽", maxCustomPrice, maxCustomPrice)
+			}
+		default:
+			return 0, 0, 0, fmt.Errorf("непонятная наценка «%s»", part)
+		}
+	}
+
+	return serverID, multiplier, surcharge, nil
+}
+
+// Execute устанавливает или сбрасывает наценку сервера
+func (c *ServerPriceCommand) Execute(ctx context.Context, adminTelegramID int64, chatID int64, args string) error {
+	if strings.TrimSpace(args) == "" {
+		return c.send(chatID, serverPriceUsage)
+	}
+
+	serverID, multiplier, surcharge, err := ParseServerPriceArgs(args)
+	if err != nil {
+		return c.send(chatID, fmt.Sprintf("❌ %s\n\n%s", err, serverPriceUsage))
+	}
+
+	server, err := c.serverService.GetServer(ctx, servers.GetCriteria{ID: &serverID})
+	if err != nil {
+		c.logger.Error("Failed to get server", "error", err, "server_id", serverID)
+		return c.send(chatID, "❌ Ошибка получения сервера")
+	}
+	if server == nil {
+		return c.send(chatID, fmt.Sprintf("❌ Сервер #%d не найден", serverID))
+	}
+
+	updated, err := c.serverService.UpdateServer(ctx, servers.GetCriteria{ID: &serverID}, servers.UpdateParams{
+		PriceMultiplier: &multiplier,
+		PriceSurcharge:  &surcharge,
+	})
+	if err != nil {
+		c.logger.Error("Failed to update server price modifier", "error", err, "server_id", serverID)
+		return c.send(chatID, "❌ Ошибка сохранения наценки")
+	}
+
+	// Аудит: кто и как поменял наценку
+	c.logger.Info("Server price modifier changed",
+		"audit", true,
+		"admin_telegram_id", adminTelegramID,
+		"server_id", serverID,
+		"old_modifier", server.PriceModifierText(),
+		"new_modifier", updated.PriceModifierText(),
+	)
+
+	text := fmt.Sprintf("✅ Сервер *%s*: %s", updated.Name, updated.PriceModifierText())
+	if updated.HasPriceModifier() {
+		text += fmt.Sprintf("\n\nНапример, тариф за 300 ₽ будет стоить %.2f ₽", updated.EffectivePrice(300))
+	}
+	return c.send(chatID, text)
+}
+
+func (c *ServerPriceCommand) send(chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	_, err := c.bot.Send(msg)
+	return err
+}
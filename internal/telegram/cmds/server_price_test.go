@@ -0,0 +1,45 @@
+package cmds
+
+import "testing"
+
+func TestParseServerPriceArgs(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           string
+		wantID         int64
+		wantMultiplier float64
+		wantSurcharge  float64
+		wantErr        bool
+	}{
+		{name: "multiplier", args: "3 x1.5", wantID: 3, wantMultiplier: 1.5},
+		{name: "surcharge", args: "#3 +100", wantID: 3, wantMultiplier: 1, wantSurcharge: 100},
+		{name: "discount", args: "3 -50", wantID: 3, wantMultiplier: 1, wantSurcharge: -50},
+		{name: "both", args: "3 ×1,2 +50", wantID: 3, wantMultiplier: 1.2, wantSurcharge: 50},
+		{name: "reset", args: "3 OFF", wantID: 3, wantMultiplier: 1},
+		{name: "missing modifier", args: "3", wantErr: true},
+		{name: "bad id", args: "abc x2", wantErr: true},
+		{name: "no sign", args: "3 100", wantErr: true},
+		{name: "zero multiplier", args: "3 x0", wantErr: true},
+		{name: "too big multiplier", args: "3 x50", wantErr: true},
+		{name: "too big surcharge", args: "3 +100000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, multiplier, surcharge, err := ParseServerPriceArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseServerPriceArgs(%q) expected error", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseServerPriceArgs(%q) unexpected error: %v", tt.args, err)
+			}
+			if id != tt.wantID || multiplier != tt.wantMultiplier || surcharge != tt.wantSurcharge {
+				t.Errorf("ParseServerPriceArgs(%q) = %d, %v, %v, want %d, %v, %v",
+					tt.args, id, multiplier, surcharge, tt.wantID, tt.wantMultiplier, tt.wantSurcharge)
+			}
+		})
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
+	"kurut-bot/internal/infra/telegram"
 	"kurut-bot/internal/stories/servers"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -15,6 +18,8 @@ import (
 type ServersCommand struct {
 	bot           *tgbotapi.BotAPI
 	serverService serverService
+	agentTokens   agentTokenIssuer
+	httpClient    *http.Client
 	logger        *slog.Logger
 }
 
@@ -25,14 +30,22 @@ type serverService interface {
 	GetActiveUsersCount(ctx context.Context, serverID int64) (int, error)
 }
 
+// agentTokenIssuer выпускает токены агентов WireGuard
+type agentTokenIssuer interface {
+	IssueToken(ctx context.Context, serverID int64) (string, error)
+}
+
 func NewServersCommand(
 	bot *tgbotapi.BotAPI,
 	serverService serverService,
+	agentTokens agentTokenIssuer,
 	logger *slog.Logger,
 ) *ServersCommand {
 	return &ServersCommand{
 		bot:           bot,
 		serverService: serverService,
+		agentTokens:   agentTokens,
+		httpClient:    &http.Client{Timeout: panelProbeTimeout},
 		logger:        logger,
 	}
 }
@@ -42,6 +55,12 @@ func (c *ServersCommand) Execute(ctx context.Context, chatID int64) error {
 	return c.showServersList(ctx, chatID, 0)
 }
 
+// clusterUsage - суммарная загрузка серверов одного кластера
+type clusterUsage struct {
+	users int
+	max   int
+}
+
 func (c *ServersCommand) showServersList(ctx context.Context, chatID int64, messageID int) error {
 	// Получаем все серверы
 	allServers, err := c.serverService.ListServers(ctx, servers.ListCriteria{Limit: 100})
@@ -65,6 +84,12 @@ func (c *ServersCommand) showServersList(ctx context.Context, chatID int64, mess
 	text.WriteString("📡 *Управление серверами*\n\n")
 
 	if len(activeServers) > 0 {
+		health := checkPanels(ctx, c.httpClient, activeServers)
+		var totalUsers, totalMax int
+		clusterLoad := make(map[string]*clusterUsage)
+		var clusterNames []string
+		hasClusters := false
+
 		text.WriteString("*Активные серверы:*\n")
 		for _, s := range activeServers {
 			// Получаем реальное количество активных подписок
@@ -73,6 +98,20 @@ func (c *ServersCommand) showServersList(ctx context.Context, chatID int64, mess
 				c.logger.Error("Failed to get active users count", "error", err, "server_id", s.ID)
 				activeCount = s.CurrentUsers // Fallback на старое значение в случае ошибки
 			}
+			totalUsers += activeCount
+			totalMax += s.MaxUsers
+
+			usage, ok := clusterLoad[s.Cluster]
+			if !ok {
+				usage = &clusterUsage{}
+				clusterLoad[s.Cluster] = usage
+				clusterNames = append(clusterNames, s.Cluster)
+			}
+			usage.users += activeCount
+			usage.max += s.MaxUsers
+			if s.Cluster != "" {
+				hasClusters = true
+			}
 
 			percent := 0.0
 			if s.MaxUsers > 0 {
@@ -86,10 +125,37 @@ func (c *ServersCommand) showServersList(ctx context.Context, chatID int64, mess
 			if percent >= 95 {
 				icon = "🔴"
 			}
-			text.WriteString(fmt.Sprintf("%s *%s:* %d/%d (%.0f%%)\n",
+			text.WriteString(fmt.Sprintf("%s *%s:* %d/%d (%.0f%%)",
 				icon, s.Name, activeCount, s.MaxUsers, percent))
+			if s.Cluster != "" {
+				text.WriteString(fmt.Sprintf(" 🏷 %s", s.Cluster))
+			}
+			if s.HasPriceModifier() {
+				text.WriteString(fmt.Sprintf(" 💲 %s", s.PriceModifierText()))
+			}
+			if !health[s.ID] {
+				text.WriteString(" ⚠️ панель недоступна")
+			}
+			text.WriteString("\n")
 		}
-		text.WriteString("\n")
+
+		// Загрузка по кластерам показываем, только если кластеры заведены
+		if hasClusters {
+			sort.Strings(clusterNames)
+			text.WriteString("\n*По кластерам:*\n")
+			for _, name := range clusterNames {
+				usage := clusterLoad[name]
+				text.WriteString(fmt.Sprintf("🏷 %s: %d/%d, свободно мест: %d\n",
+					servers.ClusterName(name), usage.users, usage.max, max(0, usage.max-usage.users)))
+			}
+		}
+
+		totalPercent := 0.0
+		if totalMax > 0 {
+			totalPercent = float64(totalUsers) / float64(totalMax) * 100
+		}
+		text.WriteString(fmt.Sprintf("\n*Всего:* %d/%d (%.0f%%), свободно мест: %d\n\n",
+			totalUsers, totalMax, totalPercent, max(0, totalMax-totalUsers)))
 	} else {
 		text.WriteString("_Нет активных серверов_\n\n")
 	}
@@ -117,6 +183,22 @@ func (c *ServersCommand) showServersList(ctx context.Context, chatID int64, mess
 		tgbotapi.NewInlineKeyboardButtonData("➕ Добавить сервер", "srv_add"),
 	))
 
+	// Кнопки редактирования для всех серверов: пароль панели может понадобиться сменить и у архивного.
+	// Рядом токен агента WireGuard и ссылка на панель, если адрес открывается из Telegram
+	for _, s := range allServers {
+		row := tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("✏️ Изменить: %s", s.Name),
+				fmt.Sprintf("srv_edit:%d", s.ID),
+			),
+			tgbotapi.NewInlineKeyboardButtonData("🔑 Агент", fmt.Sprintf("srv_token:%d", s.ID)),
+		)
+		if strings.HasPrefix(s.UIURL, "http://") || strings.HasPrefix(s.UIURL, "https://") {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonURL("🌐 Панель", s.UIURL))
+		}
+		rows = append(rows, row)
+	}
+
 	// Кнопки архивации для активных серверов
 	if len(activeServers) > 0 {
 		for _, s := range activeServers {
@@ -153,7 +235,7 @@ func (c *ServersCommand) showServersList(ctx context.Context, chatID int64, mess
 		editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text.String())
 		editMsg.ParseMode = "Markdown"
 		editMsg.ReplyMarkup = &keyboard
-		_, err = c.bot.Send(editMsg)
+		err = telegram.SafeEdit(c.bot, editMsg, "")
 	} else {
 		msg := tgbotapi.NewMessage(chatID, text.String())
 		msg.ParseMode = "Markdown"
@@ -175,8 +257,8 @@ func (c *ServersCommand) HandleCallback(ctx context.Context, query *tgbotapi.Cal
 	_, _ = c.bot.Request(callback)
 
 	switch {
-	case data == "srv_add":
-		// Этот callback будет обработан в router для запуска flow добавления сервера
+	case data == "srv_add", strings.HasPrefix(data, "srv_edit:"):
+		// Эти callback'и обрабатываются в router для запуска flow добавления и редактирования сервера
 		return nil
 
 	case strings.HasPrefix(data, "srv_archive:"):
@@ -195,6 +277,13 @@ func (c *ServersCommand) HandleCallback(ctx context.Context, query *tgbotapi.Cal
 		}
 		return c.restoreServer(ctx, chatID, messageID, serverID)
 
+	case strings.HasPrefix(data, "srv_token:"):
+		serverID, err := strconv.ParseInt(strings.TrimPrefix(data, "srv_token:"), 10, 64)
+		if err != nil {
+			return c.sendError(chatID, "Неверный ID сервера")
+		}
+		return c.issueAgentToken(ctx, chatID, query.From.ID, serverID)
+
 	case data == "srv_list":
 		return c.showServersList(ctx, chatID, messageID)
 	}
@@ -202,6 +291,31 @@ func (c *ServersCommand) HandleCallback(ctx context.Context, query *tgbotapi.Cal
 	return nil
 }
 
+// issueAgentToken выпускает новый токен агента WireGuard и отправляет его отдельным сообщением.
+// Токен показывается один раз, предыдущий токен сервера перестает действовать
+func (c *ServersCommand) issueAgentToken(ctx context.Context, chatID, adminTelegramID, serverID int64) error {
+	token, err := c.agentTokens.IssueToken(ctx, serverID)
+	if err != nil {
+		c.logger.Error("Failed to issue agent token", "error", err, "server_id", serverID)
+		return c.sendError(chatID, "Ошибка выпуска токена агента")
+	}
+
+	c.logger.Info("Agent token issued",
+		"audit", true,
+		"admin_telegram_id", adminTelegramID,
+		"server_id", serverID,
+	)
+
+	text := fmt.Sprintf("🔑 *Новый токен агента для сервера #%d*\n\n`%s`\n\n"+
+		"Агент запрашивает `GET /api/v1/agent/peers` с заголовком `Authorization: Bearer <токен>`.\n"+
+		"Токен показан один раз, предыдущий токен сервера больше не действует. Удалите это сообщение после настройки агента.",
+		serverID, token)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	_, err = c.bot.Send(msg)
+	return err
+}
+
 func (c *ServersCommand) archiveServer(ctx context.Context, chatID int64, messageID int, serverID int64) error {
 	_, err := c.serverService.ArchiveServer(ctx, serverID)
 	if err != nil {
@@ -0,0 +1,55 @@
+package cmds
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"kurut-bot/internal/stories/servers"
+)
+
+// panelProbeTimeout - сколько ждем панель при показе /servers, чтобы список не зависал на недоступном сервере
+const panelProbeTimeout = 3 * time.Second
+
+// checkPanels параллельно проверяет панели серверов и возвращает доступность по ID сервера.
+// Как и при добавлении сервера, любой ответ кроме 5xx считаем успехом: панель может требовать авторизацию
+func checkPanels(ctx context.Context, client *http.Client, list []*servers.Server) map[int64]bool {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		health = make(map[int64]bool, len(list))
+	)
+
+	for _, s := range list {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok := panelAlive(ctx, client, s.UIURL)
+			mu.Lock()
+			health[s.ID] = ok
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return health
+}
+
+func panelAlive(ctx context.Context, client *http.Client, url string) bool {
+	ctx, cancel := context.WithTimeout(ctx, panelProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode < http.StatusInternalServerError
+}
@@ -0,0 +1,43 @@
+package cmds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"kurut-bot/internal/stories/servers"
+)
+
+func TestCheckPanels(t *testing.T) {
+	newPanel := func(status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+	}
+
+	ok := newPanel(http.StatusOK)
+	defer ok.Close()
+	login := newPanel(http.StatusUnauthorized)
+	defer login.Close()
+	broken := newPanel(http.StatusBadGateway)
+	defer broken.Close()
+	down := newPanel(http.StatusOK)
+	down.Close()
+
+	list := []*servers.Server{
+		{ID: 1, UIURL: ok.URL},
+		{ID: 2, UIURL: login.URL},
+		{ID: 3, UIURL: broken.URL},
+		{ID: 4, UIURL: down.URL},
+		{ID: 5, UIURL: "://bad"},
+	}
+	want := map[int64]bool{1: true, 2: true, 3: false, 4: false, 5: false}
+
+	got := checkPanels(context.Background(), http.DefaultClient, list)
+	for id, alive := range want {
+		if got[id] != alive {
+			t.Errorf("server %d alive = %v, want %v", id, got[id], alive)
+		}
+	}
+}
@@ -6,7 +6,11 @@ import (
 	"strings"
 	"time"
 
+	"kurut-bot/internal/infra/telegram"
 	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/flowcancel"
+	"kurut-bot/internal/stories/heatmap"
+	"kurut-bot/internal/stories/statchart"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -14,21 +18,33 @@ import (
 type StatsCommand struct {
 	bot     *tgbotapi.BotAPI
 	storage StatisticsStorage
+	staff   staffProvider
 }
 
 type StatisticsStorage interface {
 	GetStatistics(ctx context.Context) (*storage.StatisticsData, error)
 	GetCustomerAnalytics(ctx context.Context) (*storage.CustomerAnalytics, error)
+	GetAssistantStats(ctx context.Context, assistantTelegramID int64) (*storage.AssistantStats, error)
+	GetAssistantRevenue(ctx context.Context, assistantTelegramID int64, from, to time.Time) (float64, error)
+	GetWeekdayHeatmap(ctx context.Context) (*heatmap.WeekdayHeatmap, error)
+	GetStatsChart(ctx context.Context) (*statchart.Chart, error)
 }
 
-func NewStatsCommand(bot *tgbotapi.BotAPI, storage StatisticsStorage) *StatsCommand {
+// NewStatsCommand создает команду; staff - ассистенты и админы, по которым можно смотреть статистику
+func NewStatsCommand(bot *tgbotapi.BotAPI, storage StatisticsStorage, staff staffProvider) *StatsCommand {
 	return &StatsCommand{
 		bot:     bot,
 		storage: storage,
+		staff:   staff,
 	}
 }
 
-func (c *StatsCommand) Execute(ctx context.Context, chatID int64) error {
+// Execute показывает общую статистику, а с аргументом (/stats @username или /stats ID) - статистику ассистента
+func (c *StatsCommand) Execute(ctx context.Context, chatID int64, args string) error {
+	if strings.TrimSpace(args) != "" {
+		return c.executeForAssistant(ctx, chatID, args)
+	}
+
 	stats, err := c.storage.GetStatistics(ctx)
 	if err != nil {
 		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении статистики")
@@ -38,17 +54,48 @@ func (c *StatsCommand) Execute(ctx context.Context, chatID int64) error {
 
 	text := c.formatStatistics(stats)
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔄 Обновить", "stats_refresh"),
-			tgbotapi.NewInlineKeyboardButtonData("📊 Аналитика", "stats_analytics"),
-		),
-	)
+	keyboard := statsOverviewKeyboard()
 
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "Markdown"
 	msg.ReplyMarkup = keyboard
-	_, err = c.bot.Send(msg)
+	if _, err = c.bot.Send(msg); err != nil {
+		return err
+	}
+	return c.sendChart(ctx, chatID)
+}
+
+// ExecuteOverview показывает общую статистику без разбивки по ассистентам и аналитики - для наблюдателей
+func (c *StatsCommand) ExecuteOverview(ctx context.Context, chatID int64) error {
+	stats, err := c.storage.GetStatistics(ctx)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении статистики")
+		_, _ = c.bot.Send(msg)
+		return fmt.Errorf("get statistics: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, c.formatStatistics(stats))
+	msg.ParseMode = "Markdown"
+	if _, err = c.bot.Send(msg); err != nil {
+		return err
+	}
+	return c.sendChart(ctx, chatID)
+}
+
+// sendChart отправляет под текстовой сводкой график новых подписок по дням и выручки по неделям
+func (c *StatsCommand) sendChart(ctx context.Context, chatID int64) error {
+	chart, err := c.storage.GetStatsChart(ctx)
+	if err != nil {
+		return fmt.Errorf("get stats chart: %w", err)
+	}
+	data, err := chart.Render()
+	if err != nil {
+		return fmt.Errorf("render stats chart: %w", err)
+	}
+
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "stats.png", Bytes: data})
+	photo.Caption = chart.Caption()
+	_, err = c.bot.Send(photo)
 	return err
 }
 
@@ -60,21 +107,25 @@ func (c *StatsCommand) Refresh(ctx context.Context, chatID int64, messageID int)
 
 	text := c.formatStatistics(stats)
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+	keyboard := statsOverviewKeyboard()
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = "Markdown"
+	edit.ReplyMarkup = &keyboard
+	return telegram.SafeEdit(c.bot, edit, "")
+}
+
+func statsOverviewKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("🔄 Обновить", "stats_refresh"),
 			tgbotapi.NewInlineKeyboardButtonData("📊 Аналитика", "stats_analytics"),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👤 По ассистентам", "stats_assistants"),
+			tgbotapi.NewInlineKeyboardButtonData("🗓 По дням недели", "stats_weekdays"),
+		),
 	)
-
-	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
-	edit.ParseMode = "Markdown"
-	edit.ReplyMarkup = &keyboard
-	_, err = c.bot.Send(edit)
-	if err != nil && strings.Contains(err.Error(), "message is not modified") {
-		return nil
-	}
-	return err
 }
 
 func (c *StatsCommand) formatStatistics(stats *storage.StatisticsData) string {
@@ -106,6 +157,9 @@ func (c *StatsCommand) formatStatistics(stats *storage.StatisticsData) string {
 	text.WriteString(fmt.Sprintf("• Средняя за день (%s): *%.2f ₽*\n", currentMonth, stats.AverageRevenuePerDay))
 	text.WriteString(fmt.Sprintf("• За %s: *%.2f ₽*\n", previousMonth, stats.PreviousMonthRevenue))
 	text.WriteString(fmt.Sprintf("• За %s: *%.2f ₽*\n", currentMonth, stats.CurrentMonthRevenue))
+	if stats.CurrentMonthServerSurcharge != 0 {
+		text.WriteString(fmt.Sprintf("  из них наценки серверов: *%.2f ₽*\n", stats.CurrentMonthServerSurcharge))
+	}
 
 	return text.String()
 }
@@ -146,11 +200,27 @@ func (c *StatsCommand) ShowAnalytics(ctx context.Context, chatID int64, messageI
 	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
 	edit.ParseMode = "Markdown"
 	edit.ReplyMarkup = &keyboard
-	_, err = c.bot.Send(edit)
-	if err != nil && strings.Contains(err.Error(), "message is not modified") {
-		return nil
+	return telegram.SafeEdit(c.bot, edit, "")
+}
+
+// ShowWeekdayHeatmap показывает, на какие дни недели приходится больше всего истечений и продлений
+func (c *StatsCommand) ShowWeekdayHeatmap(ctx context.Context, chatID int64, messageID int) error {
+	h, err := c.storage.GetWeekdayHeatmap(ctx)
+	if err != nil {
+		return fmt.Errorf("get weekday heatmap: %w", err)
 	}
-	return err
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Обновить", "stats_weekdays"),
+			tgbotapi.NewInlineKeyboardButtonData("📋 Обзор", "stats_overview"),
+		),
+	)
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, h.Text())
+	edit.ParseMode = "Markdown"
+	edit.ReplyMarkup = &keyboard
+	return telegram.SafeEdit(c.bot, edit, "")
 }
 
 func (c *StatsCommand) RefreshAnalytics(ctx context.Context, chatID int64, messageID int) error {
@@ -182,6 +252,26 @@ func (c *StatsCommand) formatAnalytics(analytics *storage.CustomerAnalytics) str
 	text.WriteString(fmt.Sprintf("• ARPU (выручка/клиент): *%.2f ₽*\n", analytics.ARPU))
 	text.WriteString(fmt.Sprintf("• Конверсия trial: *%.1f%%*\n", analytics.TrialConversionRate))
 
+	// Trial drip section
+	text.WriteString("\n📨 *Рассылка trial (отправлено → оплатили):*\n")
+	for _, step := range analytics.TrialDrip {
+		text.WriteString(fmt.Sprintf("• %s: *%d → %d* (%.1f%%)\n", step.Step.Title(), step.Sent, step.Converted, step.ConversionRate()))
+	}
+	text.WriteString(fmt.Sprintf("• Отказались: *%d*\n", analytics.TrialDripOptOuts))
+
+	// Cancel reasons section
+	if len(analytics.CancelReasons) > 0 {
+		text.WriteString("\n🚪 *Причины отмен за 30 дней:*\n")
+		var flow flowcancel.Flow
+		for _, row := range analytics.CancelReasons {
+			if row.Flow != flow {
+				flow = row.Flow
+				text.WriteString(fmt.Sprintf("_%s:_\n", flow.Title()))
+			}
+			text.WriteString(fmt.Sprintf("• %s: *%d*\n", row.Reason.Title(), row.Count))
+		}
+	}
+
 	return text.String()
 }
 
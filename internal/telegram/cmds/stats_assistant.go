@@ -0,0 +1,176 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// AssistantRevenue - выручка по подпискам ассистента
+type AssistantRevenue struct {
+	Today         float64
+	CurrentMonth  float64
+	PreviousMonth float64
+}
+
+// ParseStatsAssistantArg разбирает аргумент /stats: числовой Telegram ID или @username
+func ParseStatsAssistantArg(args string) (telegramID int64, username string) {
+	arg := strings.TrimSpace(args)
+	if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return id, ""
+	}
+	return 0, strings.ToLower(strings.TrimPrefix(arg, "@"))
+}
+
+// executeForAssistant показывает статистику ассистента по аргументу команды
+func (c *StatsCommand) executeForAssistant(ctx context.Context, chatID int64, args string) error {
+	assistantID, ok := c.resolveAssistant(args)
+	if !ok {
+		msg := tgbotapi.NewMessage(chatID, "❌ Ассистент не найден. Используйте /stats @username или /stats <Telegram ID>")
+		_, err := c.bot.Send(msg)
+		return err
+	}
+
+	text, keyboard, err := c.assistantScreen(ctx, assistantID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при получении статистики")
+		_, _ = c.bot.Send(msg)
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+// resolveAssistant ищет ассистента среди сотрудников по ID или username
+func (c *StatsCommand) resolveAssistant(args string) (int64, bool) {
+	telegramID, username := ParseStatsAssistantArg(args)
+	if telegramID != 0 {
+		return telegramID, true
+	}
+	if username == "" {
+		return 0, false
+	}
+	for _, staffID := range c.staff.StaffIDs() {
+		if strings.ToLower(strings.TrimPrefix(telegramUserName(c.bot, staffID), "@")) == username {
+			return staffID, true
+		}
+	}
+	return 0, false
+}
+
+// ShowAssistants показывает клавиатуру выбора ассистента
+func (c *StatsCommand) ShowAssistants(chatID int64, messageID int) error {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, staffID := range c.staff.StaffIDs() {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(telegramUserName(c.bot, staffID), fmt.Sprintf("stats_asst:%d", staffID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📋 Обзор", "stats_overview"),
+	))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, "👤 Выберите ассистента:")
+	edit.ReplyMarkup = &keyboard
+	return telegram.SafeEdit(c.bot, edit, "")
+}
+
+// ShowAssistant показывает статистику ассистента (callback stats_asst:ID)
+func (c *StatsCommand) ShowAssistant(ctx context.Context, chatID int64, messageID int, assistantID int64) error {
+	text, keyboard, err := c.assistantScreen(ctx, assistantID)
+	if err != nil {
+		return err
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = "Markdown"
+	edit.ReplyMarkup = &keyboard
+	return telegram.SafeEdit(c.bot, edit, "")
+}
+
+func (c *StatsCommand) assistantScreen(ctx context.Context, assistantID int64) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	stats, err := c.storage.GetAssistantStats(ctx, assistantID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("get assistant stats: %w", err)
+	}
+
+	revenue, err := c.assistantRevenue(ctx, assistantID, time.Now())
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("get assistant revenue: %w", err)
+	}
+
+	name := tgbotapi.EscapeText(tgbotapi.ModeMarkdown, telegramUserName(c.bot, assistantID))
+	text := formatAssistantStatistics(name, stats, revenue, time.Now())
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Обновить", fmt.Sprintf("stats_asst:%d", assistantID)),
+			tgbotapi.NewInlineKeyboardButtonData("👤 Ассистенты", "stats_assistants"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📋 Обзор", "stats_overview"),
+		),
+	)
+
+	return text, keyboard, nil
+}
+
+// assistantRevenue считает выручку за те же периоды, что и общая статистика (дни и месяцы в UTC)
+func (c *StatsCommand) assistantRevenue(ctx context.Context, assistantID int64, now time.Time) (*AssistantRevenue, error) {
+	now = now.UTC()
+	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	today, err := c.storage.GetAssistantRevenue(ctx, assistantID, todayStart, todayStart.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	currentMonth, err := c.storage.GetAssistantRevenue(ctx, assistantID, monthStart, monthStart.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+	previousMonth, err := c.storage.GetAssistantRevenue(ctx, assistantID, monthStart.AddDate(0, -1, 0), monthStart)
+	if err != nil {
+		return nil, err
+	}
+
+	revenue := &AssistantRevenue{
+		Today:         today,
+		CurrentMonth:  currentMonth,
+		PreviousMonth: previousMonth,
+	}
+
+	return revenue, nil
+}
+
+func formatAssistantStatistics(name string, stats *storage.AssistantStats, revenue *AssistantRevenue, now time.Time) string {
+	var text strings.Builder
+
+	text.WriteString(fmt.Sprintf("👤 *Статистика: %s*\n\n", name))
+	text.WriteString(fmt.Sprintf("*Активных подписок:* %d\n\n", stats.TotalActive))
+
+	text.WriteString("📅 *Подключено и продлено:*\n")
+	text.WriteString(fmt.Sprintf("• Сегодня: *%d*\n", stats.CreatedToday))
+	text.WriteString(fmt.Sprintf("• Вчера: *%d*\n", stats.CreatedYesterday))
+	text.WriteString(fmt.Sprintf("• Эта неделя: *%d*\n", stats.CreatedThisWeek))
+	text.WriteString(fmt.Sprintf("• Прошлая неделя: *%d*\n\n", stats.CreatedLastWeek))
+
+	text.WriteString("💰 *Выручка:*\n")
+	text.WriteString(fmt.Sprintf("• Сегодня: *%.2f ₽*\n", revenue.Today))
+	text.WriteString(fmt.Sprintf("• За %s: *%.2f ₽*\n", getMonthName(now.AddDate(0, -1, 0).Month()), revenue.PreviousMonth))
+	text.WriteString(fmt.Sprintf("• За %s: *%.2f ₽*\n", getMonthName(now.Month()), revenue.CurrentMonth))
+
+	return text.String()
+}
@@ -0,0 +1,24 @@
+package cmds
+
+import "testing"
+
+func TestParseStatsAssistantArg(t *testing.T) {
+	tests := []struct {
+		args     string
+		wantID   int64
+		wantName string
+	}{
+		{"123456789", 123456789, ""},
+		{" 42 ", 42, ""},
+		{"@Assistant_One", 0, "assistant_one"},
+		{"assistant", 0, "assistant"},
+		{"", 0, ""},
+	}
+
+	for _, tt := range tests {
+		id, name := ParseStatsAssistantArg(tt.args)
+		if id != tt.wantID || name != tt.wantName {
+			t.Errorf("ParseStatsAssistantArg(%q) = (%d, %q), want (%d, %q)", tt.args, id, name, tt.wantID, tt.wantName)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/stories/payment"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// StuckPaymentsCommand обрабатывает быстрые действия из алерта о зависших платежах
+type StuckPaymentsCommand struct {
+	bot            *tgbotapi.BotAPI
+	paymentService StuckPaymentsService
+}
+
+type StuckPaymentsService interface {
+	CheckPaymentStatus(ctx context.Context, paymentID int64) (*payment.Payment, error)
+	CancelPayment(ctx context.Context, paymentID int64) (*payment.Payment, error)
+}
+
+func NewStuckPaymentsCommand(bot *tgbotapi.BotAPI, paymentService StuckPaymentsService) *StuckPaymentsCommand {
+	return &StuckPaymentsCommand{
+		bot:            bot,
+		paymentService: paymentService,
+	}
+}
+
+// HandleCallback обрабатывает callback кнопок spay_*
+func (c *StuckPaymentsCommand) HandleCallback(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(callbackQuery.Data, ":")
+	if len(parts) != 2 {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+
+	paymentID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return c.answerCallback(callbackQuery.ID, "Неверный ID платежа")
+	}
+
+	var p *payment.Payment
+	switch parts[0] {
+	case "spay_check":
+		p, err = c.paymentService.CheckPaymentStatus(ctx, paymentID)
+	case "spay_cancel":
+		p, err = c.paymentService.CancelPayment(ctx, paymentID)
+	default:
+		return c.answerCallback(callbackQuery.ID, "Неизвестная команда")
+	}
+	if err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка обработки платежа")
+		return fmt.Errorf("handle %s for payment %d: %w", parts[0], paymentID, err)
+	}
+
+	text := fmt.Sprintf("Платеж #%d: %s", p.ID, formatPaymentStatus(p.Status))
+	if err := c.answerCallback(callbackQuery.ID, text); err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(callbackQuery.Message.Chat.ID, text)
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+func (c *StuckPaymentsCommand) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := c.bot.Request(callback)
+	return err
+}
+
+// formatPaymentStatus возвращает статус платежа для отображения
+func formatPaymentStatus(status payment.Status) string {
+	switch status {
+	case payment.StatusPending:
+		return "⏳ ожидает оплаты"
+	case payment.StatusApproved:
+		return "✅ оплачен"
+	case payment.StatusRejected:
+		return "❌ отклонен"
+	case payment.StatusCancelled:
+		return "🚫 отменен"
+	default:
+		return string(status)
+	}
+}
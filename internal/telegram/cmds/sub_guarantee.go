@@ -0,0 +1,123 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// HandleGuaranteeCallback обрабатывает grt:ID (проверка и подтверждение) и grt_ok:ID (возврат) из карточки подписки.
+// Возврат по гарантии отдает клиенту всю первую оплату через ЮKassa и отключает подписку
+func (c *SubViewCommand) HandleGuaranteeCallback(ctx context.Context, viewerTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	action, subID, ok := parseSubViewCallback(callbackQuery.Data)
+	if !ok {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+
+	details, err := c.load(ctx, viewerTelegramID, isAdmin, subID)
+	if err != nil {
+		c.logger.Error("Failed to load subscription for guarantee refund", "error", err, "sub_id", subID)
+		return c.answerCallback(callbackQuery.ID, "Ошибка")
+	}
+	if details == nil {
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	sub := details.Subscription
+
+	payments, err := c.storage.ListSubscriptionPayments(ctx, sub.ID)
+	if err != nil {
+		c.logger.Error("Failed to list payments for guarantee refund", "error", err, "sub_id", sub.ID)
+		return c.answerCallback(callbackQuery.ID, "Ошибка")
+	}
+	paid, err := c.guarantee.Check(sub, payments, time.Now())
+	if err != nil {
+		_, answerErr := c.bot.Request(tgbotapi.NewCallbackWithAlert(callbackQuery.ID, "❌ "+err.Error()))
+		return answerErr
+	}
+
+	chatID := callbackQuery.Message.Chat.ID
+	messageID := callbackQuery.Message.MessageID
+
+	switch action {
+	case "grt":
+		_ = c.answerCallback(callbackQuery.ID, "")
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("↩️ Вернуть %.0f ₽ и отключить", paid.Amount), fmt.Sprintf("grt_ok:%d", sub.ID)),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("↩️ Назад", fmt.Sprintf("sub_kb:%d", sub.ID)),
+			),
+		)
+		return telegram.SafeEdit(c.bot, tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard), "")
+	case "grt_ok":
+		if err := c.refundByGuarantee(ctx, viewerTelegramID, callbackQuery, sub, paid); err != nil {
+			return err
+		}
+		c.refreshKeyboard(ctx, chatID, messageID, *details, isAdmin)
+		return nil
+	default:
+		return c.answerCallback(callbackQuery.ID, "Неизвестное действие")
+	}
+}
+
+// refundByGuarantee возвращает оплату через ЮKassa, отключает подписку и сообщает ассистенту, создавшему подписку
+func (c *SubViewCommand) refundByGuarantee(ctx context.Context, viewerTelegramID int64, callbackQuery *tgbotapi.CallbackQuery, sub *subs.Subscription, paid *payment.Payment) error {
+	err := c.refunder.RefundPayment(ctx, payment.RefundRequest{
+		PaymentID:             paid.ID,
+		SubscriptionID:        &sub.ID,
+		Reason:                payment.RefundGuarantee,
+		RequestedByTelegramID: viewerTelegramID,
+		Description:           fmt.Sprintf("Возврат по гарантии за подписку #%d", sub.ID),
+	})
+	if errors.Is(err, payment.ErrAlreadyRefunded) {
+		return c.answerCallback(callbackQuery.ID, "Возврат уже оформлен")
+	}
+	if err != nil {
+		c.logger.Error("Failed to refund payment by guarantee", "error", err, "sub_id", sub.ID, "payment_id", paid.ID)
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка")
+		return c.reply(callbackQuery, fmt.Sprintf("❌ ЮKassa не приняла возврат платежа #%d, подписка не отключена. Можно попробовать еще раз.", paid.ID))
+	}
+	_ = c.answerCallback(callbackQuery.ID, "Деньги возвращены")
+
+	// Деньги уже вернулись клиенту: если подписку не удалось отключить, об этом нужно сказать явно
+	deactivateErr := c.deactivate(ctx, sub)
+	if deactivateErr != nil {
+		c.logger.Error("Failed to disable subscription after guarantee refund", "error", deactivateErr, "sub_id", sub.ID)
+	}
+
+	c.logger.Info("Subscription refunded by guarantee",
+		"audit", true,
+		"telegram_id", viewerTelegramID,
+		"sub_id", sub.ID,
+		"payment_id", paid.ID,
+		"amount", paid.Amount,
+	)
+
+	client := "клиенту"
+	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
+		client = "клиенту " + *sub.ClientWhatsApp
+	}
+	text := fmt.Sprintf("↩️ %.0f ₽ возвращены %s по гарантии, подписка #%d отключена.",
+		paid.Amount, client, sub.ID)
+	if deactivateErr != nil {
+		text = fmt.Sprintf("↩️ %.0f ₽ возвращены %s по гарантии, но подписку #%d отключить не удалось — отключите ее вручную.",
+			paid.Amount, client, sub.ID)
+	}
+
+	if sub.CreatedByTelegramID != nil && *sub.CreatedByTelegramID != viewerTelegramID {
+		msg := tgbotapi.NewMessage(*sub.CreatedByTelegramID, text)
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(SubViewButton("📋 Открыть подписку", sub.ID)))
+		if _, err := c.bot.Send(msg); err != nil {
+			c.logger.Error("Failed to notify assistant about guarantee refund", "error", err, "sub_id", sub.ID)
+		}
+	}
+	return c.reply(callbackQuery, text)
+}
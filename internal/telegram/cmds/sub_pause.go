@@ -0,0 +1,149 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// HandlePauseCallback обрабатывает pause_menu:ID, pause_set:ID:days и pause_resume:ID из карточки подписки.
+// На паузе подписка не истекает, а оставшиеся дни возвращаются при возобновлении
+func (c *SubViewCommand) HandlePauseCallback(ctx context.Context, viewerTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(callbackQuery.Data, ":")
+	if len(parts) < 2 {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+	subID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return c.answerCallback(callbackQuery.ID, "Неверный ID подписки")
+	}
+
+	details, err := c.load(ctx, viewerTelegramID, isAdmin, subID)
+	if err != nil {
+		c.logger.Error("Failed to load subscription for pause", "error", err, "sub_id", subID)
+		return c.answerCallback(callbackQuery.ID, "Ошибка")
+	}
+	if details == nil {
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	sub := details.Subscription
+
+	chatID := callbackQuery.Message.Chat.ID
+	messageID := callbackQuery.Message.MessageID
+	now := time.Now().UTC()
+
+	switch parts[0] {
+	case "pause_menu":
+		if !sub.CanPause(now) {
+			return c.answerCallback(callbackQuery.ID, "Заморозить можно только активную подписку")
+		}
+		_ = c.answerCallback(callbackQuery.ID, "")
+		return telegram.SafeEdit(c.bot, tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, pauseDaysKeyboard(sub.ID)), "")
+	case "pause_set":
+		if len(parts) != 3 {
+			return c.answerCallback(callbackQuery.ID, "Неверный формат")
+		}
+		days, err := strconv.Atoi(parts[2])
+		if err != nil || days <= 0 {
+			return c.answerCallback(callbackQuery.ID, "Неверный срок паузы")
+		}
+		paused, err := c.storage.PauseSubscription(ctx, sub.ID, days)
+		if err != nil {
+			_ = c.answerCallback(callbackQuery.ID, "Ошибка")
+			return fmt.Errorf("pause subscription %d: %w", sub.ID, err)
+		}
+		if !paused {
+			return c.answerCallback(callbackQuery.ID, "Подписка уже не активна")
+		}
+		_ = c.answerCallback(callbackQuery.ID, "Подписка на паузе")
+
+		c.logger.Info("Subscription paused",
+			"audit", true,
+			"telegram_id", viewerTelegramID,
+			"sub_id", sub.ID,
+			"days", days,
+		)
+
+		until := now.AddDate(0, 0, days)
+		sub.Status = subs.StatusPaused
+		sub.PausedAt = &now
+		sub.PausedUntil = &until
+		c.refreshKeyboard(ctx, chatID, messageID, *details, isAdmin)
+
+		text := fmt.Sprintf("⏸ Подписка #%d на паузе до %s.\n\n"+
+			"Оставшиеся дни сохранятся: после паузы срок продлится на время заморозки. "+
+			"Не забудьте отключить клиента в панели сервера.", sub.ID, until.Format("02.01.2006"))
+		return c.reply(callbackQuery, text)
+	case "pause_resume":
+		if sub.Status != subs.StatusPaused || sub.ExpiresAt == nil || sub.PausedAt == nil {
+			return c.answerCallback(callbackQuery.ID, "Подписка не на паузе")
+		}
+		expiresAt := subs.ResumedExpiry(*sub.ExpiresAt, *sub.PausedAt, now)
+		resumed, err := c.storage.ResumeSubscription(ctx, sub.ID, expiresAt)
+		if err != nil {
+			_ = c.answerCallback(callbackQuery.ID, "Ошибка")
+			return fmt.Errorf("resume subscription %d: %w", sub.ID, err)
+		}
+		if !resumed {
+			return c.answerCallback(callbackQuery.ID, "Подписка не на паузе")
+		}
+		_ = c.answerCallback(callbackQuery.ID, "Подписка возобновлена")
+
+		c.logger.Info("Subscription resumed",
+			"audit", true,
+			"telegram_id", viewerTelegramID,
+			"sub_id", sub.ID,
+			"expires_at", expiresAt,
+		)
+
+		sub.Status = subs.StatusActive
+		sub.ExpiresAt = &expiresAt
+		sub.PausedAt = nil
+		sub.PausedUntil = nil
+		c.refreshKeyboard(ctx, chatID, messageID, *details, isAdmin)
+
+		text := fmt.Sprintf("▶️ Подписка #%d возобновлена, активна до %s.\n\n"+
+			"Не забудьте включить клиента в панели сервера.", sub.ID, expiresAt.Format("02.01.2006"))
+		return c.reply(callbackQuery, text)
+	default:
+		return c.answerCallback(callbackQuery.ID, "Неизвестное действие")
+	}
+}
+
+// refreshKeyboard перерисовывает кнопки карточки после смены статуса подписки
+func (c *SubViewCommand) refreshKeyboard(ctx context.Context, chatID int64, messageID int, details storage.SubscriptionDetails, isAdmin bool) {
+	keyboard := c.keyboard(ctx, details, isAdmin)
+	if err := telegram.SafeEdit(c.bot, tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard), ""); err != nil {
+		c.logger.Error("Failed to refresh subscription card keyboard", "error", err, "sub_id", details.Subscription.ID)
+	}
+}
+
+// reply отвечает на карточку отдельным сообщением
+func (c *SubViewCommand) reply(callbackQuery *tgbotapi.CallbackQuery, text string) error {
+	msg := tgbotapi.NewMessage(callbackQuery.Message.Chat.ID, text)
+	msg.ReplyToMessageID = callbackQuery.Message.MessageID
+	_, err := c.bot.Send(msg)
+	return err
+}
+
+// pauseDaysKeyboard - выбор срока паузы вместо кнопок карточки
+func pauseDaysKeyboard(subID int64) tgbotapi.InlineKeyboardMarkup {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(subs.PauseDayOptions))
+	for _, days := range subs.PauseDayOptions {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d дн.", days), fmt.Sprintf("pause_set:%d:%d", subID, days)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(
+		row,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Назад", fmt.Sprintf("sub_kb:%d", subID)),
+		),
+	)
+}
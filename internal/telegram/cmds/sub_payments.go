@@ -0,0 +1,135 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// subPaymentsPerMessage - платежей в одном сообщении истории, чтобы не упираться в лимит Telegram
+const subPaymentsPerMessage = 30
+
+// sendPayments отправляет историю всех платежей подписки (sub_payments:ID) с возвратами
+func (c *SubViewCommand) sendPayments(ctx context.Context, chatID int64, sub *subs.Subscription) error {
+	payments, err := c.storage.ListSubscriptionPayments(ctx, sub.ID)
+	if err != nil {
+		c.logger.Error("Failed to list subscription payments", "error", err, "sub_id", sub.ID)
+		_, _ = c.bot.Send(tgbotapi.NewMessage(chatID, "❌ Ошибка загрузки платежей"))
+		return err
+	}
+	refunds, err := c.storage.ListSubscriptionRefunds(ctx, sub.ID)
+	if err != nil {
+		c.logger.Error("Failed to list subscription refunds", "error", err, "sub_id", sub.ID)
+		_, _ = c.bot.Send(tgbotapi.NewMessage(chatID, "❌ Ошибка загрузки платежей"))
+		return err
+	}
+
+	for _, text := range formatSubPayments(sub.ID, payments, refunds) {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = "Markdown"
+		if _, err := c.bot.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatSubPayments - история платежей подписки от новых к старым: итог оплаченного за вычетом возвратов,
+// затем по платежу сумма, статус, даты создания и оплаты и возврат. Длинная история делится на несколько сообщений
+func formatSubPayments(subID int64, payments []*payment.Payment, refunds []*payment.Refund) []string {
+	header := fmt.Sprintf("💳 *Платежи подписки #%d*", subID)
+	if len(payments) == 0 {
+		return []string{header + "\n\nНет привязанных платежей"}
+	}
+
+	refundByPayment := make(map[int64]*payment.Refund, len(refunds))
+	for _, refund := range refunds {
+		refundByPayment[refund.PaymentID] = refund
+	}
+
+	var paidCount int
+	var paidTotal float64
+	for _, p := range payments {
+		if p.Status != payment.StatusApproved {
+			continue
+		}
+		if refund, ok := refundByPayment[p.ID]; ok && refund.Status == payment.RefundSucceeded {
+			continue
+		}
+		paidCount++
+		paidTotal += p.Amount
+	}
+
+	var result []string
+	for start := 0; start < len(payments); start += subPaymentsPerMessage {
+		end := min(start+subPaymentsPerMessage, len(payments))
+
+		var b strings.Builder
+		if start == 0 {
+			fmt.Fprintf(&b, "%s\n\nОплачено: %d на %.0f ₽ (без возвратов)\n", header, paidCount, paidTotal)
+		} else {
+			fmt.Fprintf(&b, "%s (продолжение)\n", header)
+		}
+		for _, p := range payments[start:end] {
+			b.WriteString("\n" + formatSubPayment(p, refundByPayment[p.ID]))
+		}
+		result = append(result, b.String())
+	}
+	return result
+}
+
+func formatSubPayment(p *payment.Payment, refund *payment.Refund) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#%d — %.0f ₽", p.ID, p.Amount)
+	if surcharge := p.ServerSurcharge(); surcharge > 0 {
+		fmt.Fprintf(&b, " (тариф %.0f ₽ + сервер %.0f ₽)", *p.BaseAmount, surcharge)
+	}
+	fmt.Fprintf(&b, ", %s\n", formatPaymentStatus(p.Status))
+
+	fmt.Fprintf(&b, "   создан %s", p.CreatedAt.Format("02.01.2006 15:04"))
+	if p.Status == payment.StatusApproved && p.ProcessedAt != nil {
+		fmt.Fprintf(&b, ", оплачен %s", p.ProcessedAt.Format("02.01.2006 15:04"))
+	}
+	b.WriteString("\n")
+
+	if p.LateStatus != nil && *p.LateStatus == payment.LateCredited {
+		b.WriteString("   💰 поздняя оплата зачислена на баланс клиента\n")
+	}
+	if refund != nil {
+		fmt.Fprintf(&b, "   ↩️ возврат %.0f ₽ (%s): %s", refund.Amount, formatRefundReason(refund.Reason), formatRefundStatus(refund.Status))
+		if refund.Status == payment.RefundSucceeded && refund.CompletedAt != nil {
+			fmt.Fprintf(&b, " %s", refund.CompletedAt.Format("02.01.2006"))
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func formatRefundReason(reason payment.RefundReason) string {
+	switch reason {
+	case payment.RefundGuarantee:
+		return "гарантия"
+	case payment.RefundLatePayment:
+		return "оплата отмененного заказа"
+	default:
+		return string(reason)
+	}
+}
+
+func formatRefundStatus(status payment.RefundStatus) string {
+	switch status {
+	case payment.RefundSucceeded:
+		return "выполнен"
+	case payment.RefundPending:
+		return "⏳ выполняется"
+	case payment.RefundFailed:
+		return "❌ ЮKassa отклонила"
+	default:
+		return string(status)
+	}
+}
@@ -0,0 +1,68 @@
+package cmds
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"kurut-bot/internal/stories/payment"
+)
+
+func TestFormatSubPayments(t *testing.T) {
+	created := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	paid := created.Add(5 * time.Minute)
+	base := 300.0
+	refunded := created.AddDate(0, 0, 3)
+
+	payments := []*payment.Payment{
+		{ID: 3, Amount: 350, BaseAmount: &base, Status: payment.StatusApproved, ProcessedAt: &paid, CreatedAt: created},
+		{ID: 2, Amount: 300, Status: payment.StatusApproved, ProcessedAt: &paid, CreatedAt: created},
+		{ID: 1, Amount: 300, Status: payment.StatusCancelled, CreatedAt: created},
+	}
+	refunds := []*payment.Refund{
+		{PaymentID: 2, Amount: 300, Reason: payment.RefundGuarantee, Status: payment.RefundSucceeded, CompletedAt: &refunded},
+	}
+
+	messages := formatSubPayments(7, payments, refunds)
+	if len(messages) != 1 {
+		t.Fatalf("formatSubPayments() = %d messages, want 1", len(messages))
+	}
+	text := messages[0]
+	for _, want := range []string{
+		"Платежи подписки #7",
+		"Оплачено: 1 на 350 ₽",
+		"#3 — 350 ₽ (тариф 300 ₽ + сервер 50 ₽), ✅ оплачен",
+		"оплачен 01.09.2026 10:05",
+		"↩️ возврат 300 ₽ (гарантия): выполнен 04.09.2026",
+		"#1 — 300 ₽, 🚫 отменен",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("formatSubPayments() misses %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestFormatSubPaymentsSplit(t *testing.T) {
+	var payments []*payment.Payment
+	for i := range subPaymentsPerMessage + 1 {
+		payments = append(payments, &payment.Payment{ID: int64(i + 1), Amount: 100, Status: payment.StatusApproved})
+	}
+
+	messages := formatSubPayments(7, payments, nil)
+	if len(messages) != 2 {
+		t.Fatalf("formatSubPayments() = %d messages, want 2", len(messages))
+	}
+	if !strings.Contains(messages[0], "Оплачено: 31 на 3100 ₽") {
+		t.Errorf("first message has no total:\n%s", messages[0])
+	}
+	if !strings.Contains(messages[1], "продолжение") {
+		t.Errorf("second message is not marked as continuation:\n%s", messages[1])
+	}
+}
+
+func TestFormatSubPaymentsEmpty(t *testing.T) {
+	messages := formatSubPayments(7, nil, nil)
+	if len(messages) != 1 || !strings.Contains(messages[0], "Нет привязанных платежей") {
+		t.Errorf("formatSubPayments() = %v, want no payments message", messages)
+	}
+}
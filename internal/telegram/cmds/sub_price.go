@@ -0,0 +1,144 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/tariffs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxCustomPrice - защита от опечаток, как и в форме создания тарифа
+const maxCustomPrice = 10000
+
+const subPriceUsage = "💰 *Индивидуальная цена продления*\n\n" +
+	"`/sub_price 123 300` — продлевать подписку #123 за 300 ₽\n" +
+	"`/sub_price 123 off` — вернуть цену тарифа"
+
+// SubPriceCommand задает подписке индивидуальную цену продления (например, старую цену для давнего клиента)
+type SubPriceCommand struct {
+	bot           *tgbotapi.BotAPI
+	storage       SubPriceStorage
+	tariffService SubPriceTariffService
+	logger        *slog.Logger
+}
+
+type SubPriceStorage interface {
+	GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error)
+	SetSubscriptionCustomPrice(ctx context.Context, subscriptionID int64, price *float64) error
+}
+
+type SubPriceTariffService interface {
+	GetTariff(ctx context.Context, criteria tariffs.GetCriteria) (*tariffs.Tariff, error)
+}
+
+func NewSubPriceCommand(bot *tgbotapi.BotAPI, storage SubPriceStorage, tariffService SubPriceTariffService, logger *slog.Logger) *SubPriceCommand {
+	return &SubPriceCommand{
+		bot:           bot,
+		storage:       storage,
+		tariffService: tariffService,
+		logger:        logger,
+	}
+}
+
+// ParseSubPriceArgs разбирает "<ID подписки> <цена|off>"; nil цена - сброс на цену тарифа
+func ParseSubPriceArgs(args string) (int64, *float64, error) {
+	parts := strings.Fields(args)
+	if len(parts) != 2 {
+		return 0, nil, errors.New("укажите ID подписки и цену")
+	}
+
+	subID, err := strconv.ParseInt(strings.TrimPrefix(parts[0], "#"), 10, 64)
+	if err != nil || subID <= 0 {
+		return 0, nil, errors.New("неверный ID подписки")
+	}
+
+	if strings.EqualFold(parts[1], "off") {
+		return subID, nil, nil
+	}
+
+	price, err := strconv.ParseFloat(strings.ReplaceAll(parts[1], ",", "."), 64)
+	if err != nil {
+		return 0, nil, errors.New("неверный формат цены")
+	}
+	if price < 0 || price > maxCustomPrice {
+		return 0, nil, fmt.Errorf("цена должна быть от 0 до %d ₽", maxCustomPrice)
+	}
+
+	return subID, &price, nil
+}
+
+// Execute устанавливает или сбрасывает индивидуальную цену
+func (c *SubPriceCommand) Execute(ctx context.Context, adminTelegramID int64, chatID int64, args string) error {
+	if strings.TrimSpace(args) == "" {
+		return c.send(chatID, subPriceUsage)
+	}
+
+	subID, price, err := ParseSubPriceArgs(args)
+	if err != nil {
+		return c.send(chatID, fmt.Sprintf("❌ %s\n\n%s", err, subPriceUsage))
+	}
+
+	sub, err := c.storage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{subID}})
+	if err != nil {
+		c.logger.Error("Failed to get subscription", "error", err, "sub_id", subID)
+		return c.send(chatID, "❌ Ошибка получения подписки")
+	}
+	if sub == nil {
+		return c.send(chatID, fmt.Sprintf("❌ Подписка #%d не найдена", subID))
+	}
+
+	if err := c.storage.SetSubscriptionCustomPrice(ctx, subID, price); err != nil {
+		c.logger.Error("Failed to set subscription custom price", "error", err, "sub_id", subID)
+		return c.send(chatID, "❌ Ошибка сохранения цены")
+	}
+
+	// Аудит: кто и как поменял цену продления
+	c.logger.Info("Subscription custom price changed",
+		"audit", true,
+		"admin_telegram_id", adminTelegramID,
+		"sub_id", subID,
+		"old_price", formatCustomPrice(sub.CustomPrice),
+		"new_price", formatCustomPrice(price),
+	)
+
+	tariffLine := fmt.Sprintf("Тариф #%d", sub.TariffID)
+	if tariff, err := c.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &sub.TariffID}); err == nil && tariff != nil {
+		tariffLine = fmt.Sprintf("Тариф: %s (%.0f ₽)", tariff.Name, tariff.Price)
+	}
+
+	client := "—"
+	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
+		client = *sub.ClientWhatsApp
+	}
+
+	var text string
+	if price == nil {
+		text = fmt.Sprintf("✅ Подписка #%d (`%s`) продлевается по цене тарифа\n\n📅 %s", subID, client, tariffLine)
+	} else {
+		text = fmt.Sprintf("✅ Подписка #%d (`%s`) продлевается за %.0f ₽\n\n📅 %s\n\n"+
+			"При смене тарифа индивидуальная цена не применяется.", subID, client, *price, tariffLine)
+	}
+	return c.send(chatID, text)
+}
+
+// formatCustomPrice - значение для аудита: цена или "tariff", если действует цена тарифа
+func formatCustomPrice(price *float64) string {
+	if price == nil {
+		return "tariff"
+	}
+	return strconv.FormatFloat(*price, 'f', 2, 64)
+}
+
+func (c *SubPriceCommand) send(chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	_, err := c.bot.Send(msg)
+	return err
+}
@@ -0,0 +1,48 @@
+package cmds
+
+import "testing"
+
+func TestParseSubPriceArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      string
+		wantID    int64
+		wantPrice *float64
+		wantErr   bool
+	}{
+		{name: "price", args: "123 300", wantID: 123, wantPrice: ptrFloat(300)},
+		{name: "comma decimal", args: "#123 299,50", wantID: 123, wantPrice: ptrFloat(299.5)},
+		{name: "free", args: "5 0", wantID: 5, wantPrice: ptrFloat(0)},
+		{name: "reset", args: "123 OFF", wantID: 123},
+		{name: "missing price", args: "123", wantErr: true},
+		{name: "bad id", args: "abc 300", wantErr: true},
+		{name: "bad price", args: "123 free", wantErr: true},
+		{name: "negative", args: "123 -1", wantErr: true},
+		{name: "too big", args: "123 100000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, price, err := ParseSubPriceArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSubPriceArgs(%q) expected error", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSubPriceArgs(%q) unexpected error: %v", tt.args, err)
+			}
+			if id != tt.wantID {
+				t.Errorf("ParseSubPriceArgs(%q) id = %d, want %d", tt.args, id, tt.wantID)
+			}
+			if (price == nil) != (tt.wantPrice == nil) || (price != nil && *price != *tt.wantPrice) {
+				t.Errorf("ParseSubPriceArgs(%q) price = %v, want %v", tt.args, price, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func ptrFloat(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,486 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/guarantee"
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/submessages"
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// subViewPayments - сколько последних платежей показывать в карточке подписки
+const subViewPayments = 5
+
+type SubViewStorage interface {
+	SearchSubscriptions(ctx context.Context, criteria subs.SearchCriteria) ([]storage.SubscriptionDetails, error)
+	UpdateSubscription(ctx context.Context, criteria subs.GetCriteria, params subs.UpdateParams) (*subs.Subscription, error)
+	ListSubscriptionPayments(ctx context.Context, subscriptionID int64) ([]*payment.Payment, error)
+	ListSubscriptionRefunds(ctx context.Context, subscriptionID int64) ([]*payment.Refund, error)
+	GetServer(ctx context.Context, criteria servers.GetCriteria) (*servers.Server, error)
+	DecrementServerUsers(ctx context.Context, serverID int64) error
+	CreateSubscriptionMessage(ctx context.Context, msg submessages.SubscriptionMessage) (*submessages.SubscriptionMessage, error)
+	DeactivateAllSubscriptionMessages(ctx context.Context, subscriptionID int64) error
+	GetClientBalance(ctx context.Context, clientWhatsApp string) (float64, error)
+	PauseSubscription(ctx context.Context, subscriptionID int64, days int) (bool, error)
+	ResumeSubscription(ctx context.Context, subscriptionID int64, expiresAt time.Time) (bool, error)
+	ListSubscriptionPhoneChanges(ctx context.Context, subscriptionID int64) ([]*subs.PhoneChange, error)
+}
+
+// SubViewRefunder возвращает деньги за подписку по гарантии
+type SubViewRefunder interface {
+	RefundPayment(ctx context.Context, req payment.RefundRequest) error
+}
+
+// SubViewCommand показывает карточку подписки (sub_view:ID) и выполняет действия из нее
+type SubViewCommand struct {
+	bot       *tgbotapi.BotAPI
+	storage   SubViewStorage
+	refunder  SubViewRefunder
+	guarantee guarantee.Policy
+	logger    *slog.Logger
+}
+
+func NewSubViewCommand(bot *tgbotapi.BotAPI, storage SubViewStorage, refunder SubViewRefunder, guaranteePolicy guarantee.Policy, logger *slog.Logger) *SubViewCommand {
+	return &SubViewCommand{
+		bot:       bot,
+		storage:   storage,
+		refunder:  refunder,
+		guarantee: guaranteePolicy,
+		logger:    logger,
+	}
+}
+
+// subViewCard - данные для карточки подписки
+type subViewCard struct {
+	details  storage.SubscriptionDetails
+	server   *servers.Server
+	payments []*payment.Payment
+	creator  string
+	balance  float64
+
+	phoneChanges []*subs.PhoneChange
+}
+
+// HandleCallback обрабатывает sub_view:ID, sub_kb:ID, sub_payments:ID, sub_disable:ID и sub_disable_ok:ID.
+// Ассистент видит только свои подписки, админ - любые
+func (c *SubViewCommand) HandleCallback(ctx context.Context, viewerTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	action, subID, ok := parseSubViewCallback(callbackQuery.Data)
+	if !ok {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+
+	details, err := c.load(ctx, viewerTelegramID, isAdmin, subID)
+	if err != nil {
+		c.logger.Error("Failed to load subscription for card", "error", err, "sub_id", subID)
+		return c.answerCallback(callbackQuery.ID, "Ошибка")
+	}
+	if details == nil {
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+
+	chatID := callbackQuery.Message.Chat.ID
+	messageID := callbackQuery.Message.MessageID
+
+	switch action {
+	case "sub_view":
+		_ = c.answerCallback(callbackQuery.ID, "")
+		return c.Send(ctx, chatID, *details, isAdmin)
+	case "sub_payments":
+		_ = c.answerCallback(callbackQuery.ID, "")
+		return c.sendPayments(ctx, chatID, details.Subscription)
+	case "sub_kb":
+		_ = c.answerCallback(callbackQuery.ID, "")
+		keyboard := c.keyboard(ctx, *details, isAdmin)
+		return telegram.SafeEdit(c.bot, tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard), "")
+	case "sub_disable":
+		_ = c.answerCallback(callbackQuery.ID, "")
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("⛔ Да, отключить #%d", subID), fmt.Sprintf("sub_disable_ok:%d", subID)),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("↩️ Назад", fmt.Sprintf("sub_kb:%d", subID)),
+			),
+		)
+		return telegram.SafeEdit(c.bot, tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard), "")
+	case "sub_disable_ok":
+		if err := c.disable(ctx, viewerTelegramID, callbackQuery, details.Subscription); err != nil {
+			return err
+		}
+		// Убираем из карточки кнопку отключения
+		keyboard := c.keyboard(ctx, *details, isAdmin)
+		return telegram.SafeEdit(c.bot, tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard), "")
+	default:
+		return c.answerCallback(callbackQuery.ID, "Неизвестное действие")
+	}
+}
+
+// ClientWhatsApp возвращает номер клиента подписки для запуска миграции из карточки (sub_migrate:ID)
+func (c *SubViewCommand) ClientWhatsApp(ctx context.Context, viewerTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) (string, bool) {
+	subID, err := strconv.ParseInt(strings.TrimPrefix(callbackQuery.Data, "sub_migrate:"), 10, 64)
+	if err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Неверный ID подписки")
+		return "", false
+	}
+
+	details, err := c.load(ctx, viewerTelegramID, isAdmin, subID)
+	if err != nil || details == nil {
+		_ = c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+		return "", false
+	}
+	sub := details.Subscription
+	if sub.ClientWhatsApp == nil || *sub.ClientWhatsApp == "" {
+		_ = c.answerCallback(callbackQuery.ID, "У подписки нет номера клиента")
+		return "", false
+	}
+
+	_ = c.answerCallback(callbackQuery.ID, "")
+	return *sub.ClientWhatsApp, true
+}
+
+// Open отправляет карточку подписки по ID (ссылка /start sub_<subID>).
+// Ассистент видит только свои подписки, админ - любые
+func (c *SubViewCommand) Open(ctx context.Context, viewerTelegramID int64, isAdmin bool, chatID, subID int64) error {
+	details, err := c.load(ctx, viewerTelegramID, isAdmin, subID)
+	if err != nil {
+		c.logger.Error("Failed to load subscription for card", "error", err, "sub_id", subID)
+		_, sendErr := c.bot.Send(tgbotapi.NewMessage(chatID, "❌ Ошибка загрузки подписки"))
+		return sendErr
+	}
+	if details == nil {
+		_, err := c.bot.Send(tgbotapi.NewMessage(chatID, "❌ Подписка не найдена"))
+		return err
+	}
+	return c.Send(ctx, chatID, *details, isAdmin)
+}
+
+// Send отправляет карточку подписки отдельным сообщением
+func (c *SubViewCommand) Send(ctx context.Context, chatID int64, details storage.SubscriptionDetails, isAdmin bool) error {
+	sub := details.Subscription
+	card := subViewCard{
+		details: details,
+		server:  c.server(ctx, sub),
+	}
+
+	payments, err := c.storage.ListSubscriptionPayments(ctx, sub.ID)
+	if err != nil {
+		c.logger.Error("Failed to list payments for subscription card", "error", err, "sub_id", sub.ID)
+	}
+	card.payments = payments
+
+	if sub.CreatedByTelegramID != nil {
+		card.creator = telegramUserName(c.bot, *sub.CreatedByTelegramID)
+	}
+	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
+		card.balance, err = c.storage.GetClientBalance(ctx, *sub.ClientWhatsApp)
+		if err != nil {
+			c.logger.Error("Failed to get client balance for subscription card", "error", err, "sub_id", sub.ID)
+		}
+	}
+
+	card.phoneChanges, err = c.storage.ListSubscriptionPhoneChanges(ctx, sub.ID)
+	if err != nil {
+		c.logger.Error("Failed to list phone changes for subscription card", "error", err, "sub_id", sub.ID)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, formatSubViewCard(card))
+	msg.ParseMode = "Markdown"
+	msg.DisableWebPagePreview = true
+	msg.ReplyMarkup = subViewKeyboard(details, card.server, isAdmin, c.guarantee.Covers(sub, time.Now()))
+
+	sentMsg, err := c.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+
+	// Карточка содержит кнопку продления, поэтому регистрируем ее как сообщение об истечении,
+	// чтобы после оплаты кнопки снимались так же, как в уведомлениях
+	_, err = c.storage.CreateSubscriptionMessage(ctx, submessages.SubscriptionMessage{
+		SubscriptionID: sub.ID,
+		ChatID:         chatID,
+		MessageID:      sentMsg.MessageID,
+		Type:           submessages.TypeExpiring,
+		IsActive:       true,
+	})
+	if err != nil {
+		c.logger.Error("Failed to save subscription message", "error", err, "sub_id", sub.ID)
+	}
+
+	return nil
+}
+
+// disable отключает подписку после подтверждения и освобождает место на сервере
+func (c *SubViewCommand) disable(ctx context.Context, viewerTelegramID int64, callbackQuery *tgbotapi.CallbackQuery, sub *subs.Subscription) error {
+	if sub.Status != subs.StatusActive {
+		return c.answerCallback(callbackQuery.ID, "Подписка уже не активна")
+	}
+
+	if err := c.deactivate(ctx, sub); err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка")
+		return err
+	}
+	_ = c.answerCallback(callbackQuery.ID, "Подписка отключена")
+
+	var serverID int64
+	if sub.ServerID != nil {
+		serverID = *sub.ServerID
+	}
+	c.logger.Info("Subscription disabled",
+		"audit", true,
+		"telegram_id", viewerTelegramID,
+		"sub_id", sub.ID,
+		"server_id", serverID,
+	)
+
+	text := fmt.Sprintf("⛔ Подписка #%d отключена\n\nНе забудьте отключить клиента в панели сервера.", sub.ID)
+	msg := tgbotapi.NewMessage(callbackQuery.Message.Chat.ID, text)
+	msg.ReplyToMessageID = callbackQuery.Message.MessageID
+	_, err := c.bot.Send(msg)
+	return err
+}
+
+// deactivate отключает подписку, освобождает место на сервере и снимает кнопки продления с ее сообщений
+func (c *SubViewCommand) deactivate(ctx context.Context, sub *subs.Subscription) error {
+	disabled := subs.StatusDisabled
+	if _, err := c.storage.UpdateSubscription(ctx, subs.GetCriteria{IDs: []int64{sub.ID}}, subs.UpdateParams{
+		Status: &disabled,
+	}); err != nil {
+		return fmt.Errorf("disable subscription %d: %w", sub.ID, err)
+	}
+	sub.Status = disabled
+
+	if sub.ServerID != nil {
+		if err := c.storage.DecrementServerUsers(ctx, *sub.ServerID); err != nil {
+			c.logger.Error("Failed to decrement server users", "error", err, "server_id", *sub.ServerID)
+		}
+	}
+	if err := c.storage.DeactivateAllSubscriptionMessages(ctx, sub.ID); err != nil {
+		c.logger.Error("Failed to deactivate subscription messages", "error", err, "sub_id", sub.ID)
+	}
+	return nil
+}
+
+// keyboard - кнопки карточки с учетом сервера подписки и гарантии возврата
+func (c *SubViewCommand) keyboard(ctx context.Context, details storage.SubscriptionDetails, isAdmin bool) tgbotapi.InlineKeyboardMarkup {
+	return subViewKeyboard(details, c.server(ctx, details.Subscription), isAdmin, c.guarantee.Covers(details.Subscription, time.Now()))
+}
+
+// load возвращает подписку с тарифом и сервером с учетом прав: nil - нет или чужая
+func (c *SubViewCommand) load(ctx context.Context, viewerTelegramID int64, isAdmin bool, subID int64) (*storage.SubscriptionDetails, error) {
+	criteria := subs.SearchCriteria{ID: &subID, Limit: 1}
+	if !isAdmin {
+		criteria.CreatedByTelegramID = &viewerTelegramID
+	}
+
+	results, err := c.storage.SearchSubscriptions(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return &results[0], nil
+}
+
+func (c *SubViewCommand) server(ctx context.Context, sub *subs.Subscription) *servers.Server {
+	if sub.ServerID == nil {
+		return nil
+	}
+	server, err := c.storage.GetServer(ctx, servers.GetCriteria{ID: sub.ServerID})
+	if err != nil {
+		c.logger.Error("Failed to get server for subscription card", "error", err, "sub_id", sub.ID)
+		return nil
+	}
+	return server
+}
+
+func (c *SubViewCommand) answerCallback(callbackID, text string) error {
+	_, err := c.bot.Request(tgbotapi.NewCallback(callbackID, text))
+	return err
+}
+
+// SubViewButton - кнопка открытия карточки подписки
+func SubViewButton(label string, subID int64) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("sub_view:%d", subID))
+}
+
+// parseSubViewCallback разбирает "sub_view:12" на действие и ID подписки
+func parseSubViewCallback(data string) (action string, subID int64, ok bool) {
+	action, idStr, found := strings.Cut(data, ":")
+	if !found {
+		return "", 0, false
+	}
+	subID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return action, subID, true
+}
+
+func formatSubViewCard(card subViewCard) string {
+	sub := card.details.Subscription
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📋 *Подписка #%d*\n\n", sub.ID)
+
+	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
+		fmt.Fprintf(&b, "📱 Клиент: [%s](%s)\n", *sub.ClientWhatsApp, GenerateWhatsAppLink(*sub.ClientWhatsApp, ""))
+	}
+	for _, change := range card.phoneChanges {
+		if change.OldWhatsApp != "" {
+			fmt.Fprintf(&b, "↪️ Прежний номер: `%s` (до %s)\n", change.OldWhatsApp, change.CreatedAt.Format("02.01.2006"))
+		}
+	}
+	if sub.GeneratedUserID != nil {
+		fmt.Fprintf(&b, "🆔 Пользователь: `%s`\n", *sub.GeneratedUserID)
+	}
+	fmt.Fprintf(&b, "📌 Статус: %s\n", subscriptionStatusLabel(sub.Status))
+	fmt.Fprintf(&b, "📅 Тариф: %s (%.0f ₽)\n", card.details.TariffName, card.details.TariffPrice)
+	if sub.ExpiresAt != nil {
+		fmt.Fprintf(&b, "⏳ До: %s\n", sub.ExpiresAt.Format("02.01.2006"))
+	}
+	if sub.Status == subs.StatusPaused && sub.PausedUntil != nil {
+		fmt.Fprintf(&b, "⏸ Пауза до: %s (срок сдвинется на время паузы)\n", sub.PausedUntil.Format("02.01.2006"))
+	}
+
+	switch {
+	case card.server != nil:
+		fmt.Fprintf(&b, "🖥 Сервер: %s\n", card.server.Name)
+		if card.server.UIPassword != "" {
+			fmt.Fprintf(&b, "🔑 Пароль панели: `%s`\n", card.server.UIPassword)
+		}
+	case card.details.ServerName != nil:
+		fmt.Fprintf(&b, "🖥 Сервер: %s\n", *card.details.ServerName)
+	}
+
+	if sub.ClientPlatform.IsSupported() {
+		fmt.Fprintf(&b, "📲 Устройство: %s\n", sub.ClientPlatform.Title())
+	}
+	if sub.Note != "" {
+		b.WriteString(formatSubNote(sub.Note) + "\n")
+	}
+	if card.creator != "" {
+		fmt.Fprintf(&b, "👤 Создал: %s\n", card.creator)
+	}
+	fmt.Fprintf(&b, "🗓 Создана: %s\n", sub.CreatedAt.Format("02.01.2006"))
+	if card.balance > 0 {
+		fmt.Fprintf(&b, "💰 Баланс клиента: %.2f ₽\n", card.balance)
+	}
+
+	b.WriteString("\n*Платежи:*\n")
+	if len(card.payments) == 0 {
+		b.WriteString("нет привязанных платежей\n")
+	}
+	for i, p := range card.payments {
+		if i >= subViewPayments {
+			fmt.Fprintf(&b, "…и ещё %d\n", len(card.payments)-subViewPayments)
+			break
+		}
+		fmt.Fprintf(&b, "#%d — %.0f ₽, %s, %s\n", p.ID, p.Amount, formatPaymentStatus(p.Status), p.CreatedAt.Format("02.01.2006"))
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// subViewKeyboard - кнопки карточки: продление, инструкция, история платежей, миграция (только админ), пауза, отключение,
+// возврат по гарантии (если подписка под гарантией), смена номера клиента, дата окончания (только админ),
+// чат с клиентом и заметка
+func subViewKeyboard(details storage.SubscriptionDetails, server *servers.Server, isAdmin, guaranteed bool) tgbotapi.InlineKeyboardMarkup {
+	sub := details.Subscription
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔗 Продлить", fmt.Sprintf("exp_link:%d", sub.ID)),
+		),
+	}
+
+	var manageRow []tgbotapi.InlineKeyboardButton
+	manageRow = append(manageRow, ClientPlatformButton(sub.ID),
+		tgbotapi.NewInlineKeyboardButtonData("💳 Платежи", fmt.Sprintf("sub_payments:%d", sub.ID)))
+	if isAdmin && sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
+		manageRow = append(manageRow, tgbotapi.NewInlineKeyboardButtonData("🔀 Мигрировать", fmt.Sprintf("sub_migrate:%d", sub.ID)))
+	}
+	rows = append(rows, manageRow)
+
+	switch sub.Status {
+	case subs.StatusActive:
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏸ Пауза", fmt.Sprintf("pause_menu:%d", sub.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("⛔ Отключить", fmt.Sprintf("sub_disable:%d", sub.ID)),
+		))
+		if guaranteed {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("↩️ Возврат по гарантии", fmt.Sprintf("grt:%d", sub.ID)),
+			))
+		}
+		editRow := tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Изменить номер клиента", fmt.Sprintf("sub_phone:%d", sub.ID)),
+		)
+		if isAdmin {
+			editRow = append(editRow, tgbotapi.NewInlineKeyboardButtonData("📅 Дата окончания", fmt.Sprintf("sub_expiry:%d", sub.ID)))
+		}
+		rows = append(rows, editRow, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🖥 Сменить сервер", fmt.Sprintf("sub_server:%d", sub.ID)),
+		))
+	case subs.StatusPaused:
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("▶️ Возобновить", fmt.Sprintf("pause_resume:%d", sub.ID)),
+		))
+	}
+
+	var linkRow []tgbotapi.InlineKeyboardButton
+	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
+		linkRow = append(linkRow, tgbotapi.NewInlineKeyboardButtonURL("💬 WhatsApp", GenerateWhatsAppLink(*sub.ClientWhatsApp, "")))
+	}
+	if server != nil && server.UIURL != "" {
+		linkRow = append(linkRow, tgbotapi.NewInlineKeyboardButtonURL("🌐 Сервер", server.UIURL))
+	}
+	if len(linkRow) > 0 {
+		rows = append(rows, linkRow)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(SubNoteButton(sub.ID), EscalationButton(sub.ID)))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// SubNoteButton - кнопка заметки ассистента к подписке
+func SubNoteButton(subID int64) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("📝 Заметка", fmt.Sprintf("sub_note:%d", subID))
+}
+
+// formatSubNote - строка заметки для карточек в Markdown
+func formatSubNote(note string) string {
+	return "📝 Заметка: " + tgbotapi.EscapeText(tgbotapi.ModeMarkdown, note)
+}
+
+func subscriptionStatusLabel(status subs.Status) string {
+	switch status {
+	case subs.StatusActive:
+		return "✅ активна"
+	case subs.StatusPending:
+		return "⏳ ожидает оплаты"
+	case subs.StatusExpired:
+		return "⌛ истекла"
+	case subs.StatusDisabled:
+		return "⛔ отключена"
+	case subs.StatusArchived:
+		return "📦 в архиве"
+	case subs.StatusCancelled:
+		return "🗑 отменена"
+	case subs.StatusPaused:
+		return "⏸ на паузе"
+	default:
+		return string(status)
+	}
+}
@@ -0,0 +1,57 @@
+package cmds
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
+)
+
+func TestParseSubViewCallback(t *testing.T) {
+	tests := []struct {
+		data       string
+		wantAction string
+		wantID     int64
+		wantOK     bool
+	}{
+		{"sub_view:12", "sub_view", 12, true},
+		{"sub_disable_ok:7", "sub_disable_ok", 7, true},
+		{"sub_view", "", 0, false},
+		{"sub_view:abc", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		action, id, ok := parseSubViewCallback(tt.data)
+		if action != tt.wantAction || id != tt.wantID || ok != tt.wantOK {
+			t.Errorf("parseSubViewCallback(%q) = (%q, %d, %v), want (%q, %d, %v)",
+				tt.data, action, id, ok, tt.wantAction, tt.wantID, tt.wantOK)
+		}
+	}
+}
+
+func TestFormatSubViewCardPhoneChanges(t *testing.T) {
+	phone := "996700333444"
+	card := subViewCard{
+		details: storage.SubscriptionDetails{
+			Subscription: &subs.Subscription{ID: 5, Status: subs.StatusActive, ClientWhatsApp: &phone},
+			TariffName:   "1 месяц",
+		},
+		phoneChanges: []*subs.PhoneChange{
+			{OldWhatsApp: "996700111222", NewWhatsApp: phone, CreatedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)},
+			{OldWhatsApp: "", NewWhatsApp: "996700111222"},
+		},
+	}
+
+	text := formatSubViewCard(card)
+	if !strings.Contains(text, "wa.me/996700333444") {
+		t.Errorf("card links to the old number:\n%s", text)
+	}
+	if !strings.Contains(text, "↪️ Прежний номер: `996700111222` (до 01.10.2026)") {
+		t.Errorf("card misses the previous number:\n%s", text)
+	}
+	if strings.Count(text, "Прежний номер") != 1 {
+		t.Errorf("empty previous number should be skipped:\n%s", text)
+	}
+}
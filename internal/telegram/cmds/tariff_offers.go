@@ -0,0 +1,182 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/stories/offers"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/telegram/messages"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type TariffOffersStorage interface {
+	CreateOfferLead(ctx context.Context, lead offers.Lead) (bool, error)
+}
+
+type TariffOffersTariffService interface {
+	GetTariff(ctx context.Context, criteria tariffs.GetCriteria) (*tariffs.Tariff, error)
+	GetActiveTariffs(ctx context.Context) ([]*tariffs.Tariff, error)
+}
+
+// TariffOffersCommand - предложения тарифов в inline-режиме: "@бот тарифы" в чате с клиентом отправляет карточку
+// тарифа со ссылкой t.me/<bot>?start=offer_<tariffID>_<assistantTelegramID>. Клиент без доступа к боту по ссылке
+// оставляет заявку, и она уходит ассистенту-отправителю
+type TariffOffersCommand struct {
+	bot           *tgbotapi.BotAPI
+	storage       TariffOffersStorage
+	tariffService TariffOffersTariffService
+	logger        *slog.Logger
+}
+
+func NewTariffOffersCommand(bot *tgbotapi.BotAPI, storage TariffOffersStorage, tariffService TariffOffersTariffService, logger *slog.Logger) *TariffOffersCommand {
+	return &TariffOffersCommand{
+		bot:           bot,
+		storage:       storage,
+		tariffService: tariffService,
+		logger:        logger,
+	}
+}
+
+// IsTariffOfferQuery - inline-запрос просит предложения тарифов ("тарифы", "tariffs"), а не поиск клиента
+func IsTariffOfferQuery(query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	return strings.HasPrefix(query, "тариф") || strings.HasPrefix(query, "tarif")
+}
+
+// TariffOfferLink возвращает deep-link предложения тарифа от ассистента
+func TariffOfferLink(botUsername string, tariffID, assistantTelegramID int64) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s", botUsername, offers.StartPayload(tariffID, assistantTelegramID))
+}
+
+// HandleInline отвечает на inline-запрос "тарифы" карточками доступных сейчас тарифов
+func (c *TariffOffersCommand) HandleInline(ctx context.Context, assistantTelegramID int64, inlineQuery *tgbotapi.InlineQuery) error {
+	available, err := c.tariffService.GetActiveTariffs(ctx)
+	if err != nil {
+		c.logger.Error("Failed to get tariffs for inline offers", "error", err)
+	}
+
+	now := time.Now()
+	results := make([]any, 0, len(available))
+	for _, tariff := range available {
+		result := tgbotapi.NewInlineQueryResultArticleMarkdown("offer_"+strconv.FormatInt(tariff.ID, 10), tariff.Name,
+			FormatTariffOffer(tariff, now))
+		result.Description = fmt.Sprintf("%.0f ₽ · %d дн.", tariff.Price, tariff.DurationDays)
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL("🛒 Оформить", TariffOfferLink(c.bot.Self.UserName, tariff.ID, assistantTelegramID)),
+		))
+		result.ReplyMarkup = &keyboard
+		results = append(results, result)
+	}
+
+	_, err = c.bot.Request(tgbotapi.InlineConfig{
+		InlineQueryID: inlineQuery.ID,
+		Results:       results,
+		CacheTime:     0,
+		IsPersonal:    true,
+	})
+	return err
+}
+
+// FormatTariffOffer - карточка тарифа для клиента в Markdown: срок, цена, трафик и конец акции
+func FormatTariffOffer(tariff *tariffs.Tariff, now time.Time) string {
+	var b strings.Builder
+	if brand := messages.CurrentBrand().Name; brand != "" {
+		b.WriteString(tgbotapi.EscapeText(tgbotapi.ModeMarkdown, brand) + "\n\n")
+	}
+	fmt.Fprintf(&b, "⭐ *Тариф «%s»*\n\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, tariff.Name))
+	fmt.Fprintf(&b, "📅 Срок: %d дн.\n", tariff.DurationDays)
+	fmt.Fprintf(&b, "💰 Цена: %.0f ₽\n", tariff.Price)
+	if tariff.TrafficLimitGB != nil {
+		fmt.Fprintf(&b, "📊 Трафик: %d ГБ\n", *tariff.TrafficLimitGB)
+	}
+	if tariff.ValidUntil != nil && now.Before(*tariff.ValidUntil) {
+		fmt.Fprintf(&b, "⏳ Акция до %s\n", tariff.ValidUntil.Format("02.01.2006"))
+	}
+	b.WriteString("\nНажмите «Оформить» — заявка придет ассистенту, и он поможет подключиться.")
+	return b.String()
+}
+
+// HandleClientStart обрабатывает /start offer_* от клиента без доступа к боту: записывает заявку за ассистентом,
+// отправившим предложение, и сообщает ему о клиенте. Повторное открытие ссылки ассистента не беспокоит
+func (c *TariffOffersCommand) HandleClientStart(ctx context.Context, message *tgbotapi.Message, tariffID, assistantTelegramID int64) error {
+	chatID := message.Chat.ID
+
+	tariff, err := c.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &tariffID})
+	if err != nil {
+		c.logger.Error("Failed to get offered tariff", "error", err, "tariff_id", tariffID)
+		return c.send(chatID, "❌ Не удалось отправить заявку, попробуйте позже")
+	}
+	if tariff == nil || !tariff.IsAvailableAt(time.Now()) {
+		return c.send(chatID, "⚠️ Это предложение больше не действует. Напишите тому, кто его прислал.")
+	}
+
+	created, err := c.storage.CreateOfferLead(ctx, offers.Lead{
+		TariffID:            tariff.ID,
+		AssistantTelegramID: assistantTelegramID,
+		ClientTelegramID:    message.From.ID,
+		ClientName:          offerClientName(message.From),
+	})
+	if err != nil {
+		c.logger.Error("Failed to create offer lead", "error", err, "tariff_id", tariffID, "assistant_telegram_id", assistantTelegramID)
+		return c.send(chatID, "❌ Не удалось отправить заявку, попробуйте позже")
+	}
+	if !created {
+		return c.send(chatID, fmt.Sprintf("✅ Заявка на тариф «%s» уже отправлена, ассистент скоро напишет вам.", tariff.Name))
+	}
+
+	c.logger.Info("Tariff offer lead",
+		"audit", true,
+		"assistant_telegram_id", assistantTelegramID,
+		"client_telegram_id", message.From.ID,
+		"tariff_id", tariff.ID,
+	)
+
+	if err := c.notifyAssistant(assistantTelegramID, message.From, tariff); err != nil {
+		c.logger.Error("Failed to notify assistant about offer lead", "error", err, "assistant_telegram_id", assistantTelegramID)
+	}
+	return c.send(chatID, fmt.Sprintf("✅ Заявка на тариф «%s» отправлена. Ассистент скоро напишет вам в Telegram.", tariff.Name))
+}
+
+// notifyAssistant присылает ассистенту клиента со ссылкой на его профиль и кнопкой оформления подписки (offer_go:ID)
+func (c *TariffOffersCommand) notifyAssistant(assistantTelegramID int64, client *tgbotapi.User, tariff *tariffs.Tariff) error {
+	text := fmt.Sprintf("🛒 *Заявка по вашему предложению*\n\n"+
+		"👤 Клиент: [%s](tg://user?id=%d)\n"+
+		"📅 Тариф: %s (%.0f ₽)\n\n"+
+		"Напишите клиенту, узнайте номер WhatsApp и оформите подписку.",
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, offerClientName(client)), client.ID,
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, tariff.Name), tariff.Price)
+
+	msg := tgbotapi.NewMessage(assistantTelegramID, text)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("➕ Оформить подписку", fmt.Sprintf("offer_go:%d", tariff.ID)),
+	))
+	_, err := c.bot.Send(msg)
+	return err
+}
+
+// offerClientName - имя клиента в Telegram с @username, если он есть
+func offerClientName(user *tgbotapi.User) string {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	switch {
+	case name == "" && user.UserName != "":
+		return "@" + user.UserName
+	case name == "":
+		return strconv.FormatInt(user.ID, 10)
+	case user.UserName != "":
+		return name + " (@" + user.UserName + ")"
+	default:
+		return name
+	}
+}
+
+func (c *TariffOffersCommand) send(chatID int64, text string) error {
+	_, err := c.bot.Send(tgbotapi.NewMessage(chatID, text))
+	return err
+}
@@ -0,0 +1,61 @@
+package cmds
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"kurut-bot/internal/stories/tariffs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestIsTariffOfferQuery(t *testing.T) {
+	for query, want := range map[string]bool{
+		"тарифы":     true,
+		" Тариф ":    true,
+		"tariffs":    true,
+		"+996555":    false,
+		"":           false,
+		"мои тарифы": false,
+	} {
+		if got := IsTariffOfferQuery(query); got != want {
+			t.Errorf("IsTariffOfferQuery(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestFormatTariffOffer(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	limit := 100
+	until := time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+	tariff := &tariffs.Tariff{Name: "1_месяц", DurationDays: 30, Price: 300, TrafficLimitGB: &limit, ValidUntil: &until}
+
+	text := FormatTariffOffer(tariff, now)
+	for _, want := range []string{"Тариф «1\\_месяц»", "Срок: 30 дн.", "Цена: 300 ₽", "Трафик: 100 ГБ", "Акция до 31.10.2026"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("FormatTariffOffer() misses %q:\n%s", want, text)
+		}
+	}
+
+	if text := FormatTariffOffer(tariff, until.Add(time.Hour)); strings.Contains(text, "Акция") {
+		t.Errorf("FormatTariffOffer() shows an ended promo:\n%s", text)
+	}
+}
+
+func TestOfferClientName(t *testing.T) {
+	tests := []struct {
+		user tgbotapi.User
+		want string
+	}{
+		{tgbotapi.User{ID: 1, FirstName: "Айбек", LastName: "Осмонов", UserName: "aibek"}, "Айбек Осмонов (@aibek)"},
+		{tgbotapi.User{ID: 1, FirstName: "Айбек"}, "Айбек"},
+		{tgbotapi.User{ID: 1, UserName: "aibek"}, "@aibek"},
+		{tgbotapi.User{ID: 42}, "42"},
+	}
+	for _, tt := range tests {
+		if got := offerClientName(&tt.user); got != tt.want {
+			t.Errorf("offerClientName(%+v) = %q, want %q", tt.user, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/pricechanges"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/telegram/messages"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// priceLockClientsPerMessage - клиентов в одной карточке, чтобы сообщение не упиралось в лимит Telegram
+const priceLockClientsPerMessage = 30
+
+const tariffPriceUsage = "📈 *Плановое изменение цены тарифа*\n\n" +
+	"`/tariff_price` — запланированные изменения\n" +
+	"`/tariff_price 2 350 01.12.2026` — с 01.12.2026 тариф #2 стоит 350 ₽\n" +
+	"`/tariff_price 2 off` — отменить изменение\n\n" +
+	"При повышении ассистенты получат клиентов тарифа с готовым сообщением: продлиться по старой цене до даты повышения."
+
+// TariffPriceCommand планирует изменение цены тарифа на дату и рассылает ассистентам карточки
+// клиентов, которым стоит продлиться до повышения
+type TariffPriceCommand struct {
+	bot           *tgbotapi.BotAPI
+	storage       TariffPriceStorage
+	tariffService SubPriceTariffService
+	langStorage   ClientLanguageStorage
+	logger        *slog.Logger
+}
+
+type TariffPriceStorage interface {
+	SchedulePriceChange(ctx context.Context, change pricechanges.PriceChange) (*pricechanges.PriceChange, error)
+	ListScheduledPriceChanges(ctx context.Context) ([]*pricechanges.PriceChange, error)
+	CancelPriceChange(ctx context.Context, tariffID int64) (bool, error)
+}
+
+func NewTariffPriceCommand(
+	bot *tgbotapi.BotAPI,
+	storage TariffPriceStorage,
+	tariffService SubPriceTariffService,
+	langStorage ClientLanguageStorage,
+	logger *slog.Logger,
+) *TariffPriceCommand {
+	return &TariffPriceCommand{
+		bot:           bot,
+		storage:       storage,
+		tariffService: tariffService,
+		langStorage:   langStorage,
+		logger:        logger,
+	}
+}
+
+// ParseTariffPriceArgs разбирает "<ID тарифа> <цена> <дд.мм.гггг>" или "<ID тарифа> off" (nil цена - отмена).
+// Дата новой цены должна быть позже сегодняшнего дня (UTC), иначе клиенты не успеют продлиться
+func ParseTariffPriceArgs(args string, now time.Time) (int64, *float64, time.Time, error) {
+	parts := strings.Fields(args)
+	if len(parts) < 2 {
+		return 0, nil, time.Time{}, errors.New("укажите ID тарифа, цену и дату")
+	}
+
+	tariffID, err := strconv.ParseInt(strings.TrimPrefix(parts[0], "#"), 10, 64)
+	if err != nil || tariffID <= 0 {
+		return 0, nil, time.Time{}, errors.New("неверный ID тарифа")
+	}
+
+	if strings.EqualFold(parts[1], "off") && len(parts) == 2 {
+		return tariffID, nil, time.Time{}, nil
+	}
+	if len(parts) != 3 {
+		return 0, nil, time.Time{}, errors.New("укажите ID тарифа, цену и дату")
+	}
+
+	price, err := strconv.ParseFloat(strings.ReplaceAll(parts[1], ",", "."), 64)
+	if err != nil {
+		return 0, nil, time.Time{}, errors.New("неверный формат цены")
+	}
+	if price <= 0 || price > maxCustomPrice {
+		return 0, nil, time.Time{}, fmt.Errorf("цена должна быть от 1 до %d ₽", maxCustomPrice)
+	}
+
+	effectiveAt, err := time.Parse("02.01.2006", parts[2])
+	if err != nil {
+		return 0, nil, time.Time{}, fmt.Errorf("неверная дата %s", parts[2])
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !effectiveAt.After(today) {
+		return 0, nil, time.Time{}, errors.New("дата должна быть не раньше завтрашнего дня")
+	}
+
+	return tariffID, &price, effectiveAt, nil
+}
+
+// Execute показывает, планирует или отменяет изменение цены тарифа
+func (c *TariffPriceCommand) Execute(ctx context.Context, adminTelegramID int64, chatID int64, args string) error {
+	if strings.TrimSpace(args) == "" {
+		return c.showScheduled(ctx, chatID)
+	}
+
+	tariffID, price, effectiveAt, err := ParseTariffPriceArgs(args, time.Now().UTC())
+	if err != nil {
+		return c.send(chatID, fmt.Sprintf("❌ %s\n\n%s", err, tariffPriceUsage))
+	}
+
+	if price == nil {
+		cancelled, err := c.storage.CancelPriceChange(ctx, tariffID)
+		if err != nil {
+			c.logger.Error("Failed to cancel tariff price change", "error", err, "tariff_id", tariffID)
+			return c.send(chatID, "❌ Ошибка отмены изменения цены")
+		}
+		if !cancelled {
+			return c.send(chatID, fmt.Sprintf("ℹ️ Для тарифа #%d нет запланированных изменений цены", tariffID))
+		}
+		c.logger.Info("Tariff price change cancelled",
+			"audit", true,
+			"admin_telegram_id", adminTelegramID,
+			"tariff_id", tariffID,
+		)
+		return c.send(chatID, fmt.Sprintf("✅ Изменение цены тарифа #%d отменено", tariffID))
+	}
+
+	tariff, err := c.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &tariffID})
+	if err != nil {
+		c.logger.Error("Failed to get tariff", "error", err, "tariff_id", tariffID)
+		return c.send(chatID, "❌ Ошибка получения тарифа")
+	}
+	if tariff == nil {
+		return c.send(chatID, fmt.Sprintf("❌ Тариф #%d не найден", tariffID))
+	}
+	if *price == tariff.Price {
+		return c.send(chatID, fmt.Sprintf("ℹ️ Тариф %s уже стоит %.0f ₽", tariff.Name, tariff.Price))
+	}
+
+	change, err := c.storage.SchedulePriceChange(ctx, pricechanges.PriceChange{
+		TariffID:            tariffID,
+		OldPrice:            tariff.Price,
+		NewPrice:            *price,
+		EffectiveAt:         effectiveAt,
+		CreatedByTelegramID: adminTelegramID,
+	})
+	if err != nil {
+		c.logger.Error("Failed to schedule tariff price change", "error", err, "tariff_id", tariffID)
+		return c.send(chatID, "❌ Ошибка сохранения изменения цены")
+	}
+
+	// Аудит: кто и когда запланировал новую цену
+	c.logger.Info("Tariff price change scheduled",
+		"audit", true,
+		"admin_telegram_id", adminTelegramID,
+		"change_id", change.ID,
+		"tariff_id", tariffID,
+		"old_price", change.OldPrice,
+		"new_price", change.NewPrice,
+		"effective_at", change.EffectiveAt.Format("02.01.2006"),
+	)
+
+	text := fmt.Sprintf("✅ С %s тариф %s стоит %.0f ₽ (сейчас %.0f ₽)",
+		change.EffectiveAt.Format("02.01.2006"), tariff.Name, change.NewPrice, change.OldPrice)
+	if change.IsIncrease() {
+		text += "\n\nВ течение часа ассистенты получат клиентов тарифа с предложением продлиться по старой цене. " +
+			"Клиенты с индивидуальной ценой в рассылку не попадут."
+	}
+	return c.send(chatID, text)
+}
+
+func (c *TariffPriceCommand) showScheduled(ctx context.Context, chatID int64) error {
+	changes, err := c.storage.ListScheduledPriceChanges(ctx)
+	if err != nil {
+		c.logger.Error("Failed to list scheduled price changes", "error", err)
+		return c.send(chatID, "❌ Ошибка получения изменений цен")
+	}
+	if len(changes) == 0 {
+		return c.send(chatID, "Запланированных изменений цен нет\n\n"+tariffPriceUsage)
+	}
+
+	var text strings.Builder
+	text.WriteString("📈 *Запланированные изменения цен*\n\n")
+	for _, change := range changes {
+		fmt.Fprintf(&text, "• %s: %.0f → %.0f ₽ с %s",
+			c.tariffName(ctx, change.TariffID), change.OldPrice, change.NewPrice, change.EffectiveAt.Format("02.01.2006"))
+		if change.NotifiedAt != nil {
+			text.WriteString(" ✉️")
+		}
+		text.WriteString("\n")
+	}
+	text.WriteString("\n" + tariffPriceUsage)
+	return c.send(chatID, text.String())
+}
+
+// SendPriceLockMessages отправляет ассистенту клиентов тарифа со ссылками на WhatsApp и готовым
+// предложением продлиться по старой цене; большие списки делятся на несколько сообщений
+func (c *TariffPriceCommand) SendPriceLockMessages(ctx context.Context, chatID int64, change *pricechanges.PriceChange, clients []*subs.Subscription) error {
+	header := fmt.Sprintf("📈 *С %s тариф %s подорожает: %.0f → %.0f ₽*\n\n"+
+		"Предложите клиентам продлиться по старой цене до %s:",
+		change.EffectiveAt.Format("02.01.2006"), c.tariffName(ctx, change.TariffID),
+		change.OldPrice, change.NewPrice, change.LastOldPriceDay().Format("02.01.2006"))
+
+	for start := 0; start < len(clients); start += priceLockClientsPerMessage {
+		end := min(start+priceLockClientsPerMessage, len(clients))
+
+		var text strings.Builder
+		text.WriteString(header + "\n")
+		for _, sub := range clients[start:end] {
+			phone := *sub.ClientWhatsApp
+			waText := messages.WhatsAppText(c.clientLanguage(ctx, phone), messages.WATemplatePriceLock,
+				change.EffectiveAt.Format("02.01.2006"), change.NewPrice, change.OldPrice)
+			fmt.Fprintf(&text, "\n• [%s](%s)", phone, GenerateWhatsAppLink(phone, waText))
+			if sub.ExpiresAt != nil {
+				fmt.Fprintf(&text, " — до %s", sub.ExpiresAt.Format("02.01.2006"))
+			}
+		}
+
+		msg := tgbotapi.NewMessage(chatID, text.String())
+		msg.ParseMode = "Markdown"
+		msg.DisableWebPagePreview = true
+		if _, err := c.bot.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendPriceAppliedMessage сообщает админу, что новая цена тарифа вступила в силу
+func (c *TariffPriceCommand) SendPriceAppliedMessage(ctx context.Context, chatID int64, change *pricechanges.PriceChange) error {
+	return c.send(chatID, fmt.Sprintf("✅ Тариф %s теперь стоит %.0f ₽ (было %.0f ₽)",
+		c.tariffName(ctx, change.TariffID), change.NewPrice, change.OldPrice))
+}
+
+func (c *TariffPriceCommand) tariffName(ctx context.Context, tariffID int64) string {
+	tariff, err := c.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &tariffID})
+	if err != nil || tariff == nil {
+		return fmt.Sprintf("#%d", tariffID)
+	}
+	return tariff.Name
+}
+
+// clientLanguage возвращает язык клиента для предзаполненного сообщения WhatsApp
+func (c *TariffPriceCommand) clientLanguage(ctx context.Context, phone string) clientlang.Language {
+	stored, err := c.langStorage.GetClientLanguage(ctx, phone)
+	if err != nil {
+		c.logger.Warn("Failed to get client language", "error", err, "whatsapp", phone)
+	}
+	return clientlang.Resolve(stored, phone)
+}
+
+func (c *TariffPriceCommand) send(chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	_, err := c.bot.Send(msg)
+	return err
+}
@@ -0,0 +1,52 @@
+package cmds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTariffPriceArgs(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		args      string
+		wantID    int64
+		wantPrice *float64
+		wantDate  time.Time
+		wantErr   bool
+	}{
+		{name: "schedule", args: "2 350 01.11.2026", wantID: 2, wantPrice: ptrFloat(350), wantDate: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "tomorrow", args: "#2 349,50 17.10.2026", wantID: 2, wantPrice: ptrFloat(349.5), wantDate: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{name: "cancel", args: "2 off", wantID: 2},
+		{name: "today", args: "2 350 16.10.2026", wantErr: true},
+		{name: "missing date", args: "2 350", wantErr: true},
+		{name: "bad date", args: "2 350 2026-11-01", wantErr: true},
+		{name: "zero price", args: "2 0 01.11.2026", wantErr: true},
+		{name: "bad id", args: "abc 350 01.11.2026", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, price, date, err := ParseTariffPriceArgs(tt.args, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseTariffPriceArgs(%q) expected error", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTariffPriceArgs(%q) unexpected error: %v", tt.args, err)
+			}
+			if id != tt.wantID {
+				t.Errorf("ParseTariffPriceArgs(%q) id = %d, want %d", tt.args, id, tt.wantID)
+			}
+			if (price == nil) != (tt.wantPrice == nil) || (price != nil && *price != *tt.wantPrice) {
+				t.Errorf("ParseTariffPriceArgs(%q) price = %v, want %v", tt.args, price, tt.wantPrice)
+			}
+			if !date.Equal(tt.wantDate) {
+				t.Errorf("ParseTariffPriceArgs(%q) date = %v, want %v", tt.args, date, tt.wantDate)
+			}
+		})
+	}
+}
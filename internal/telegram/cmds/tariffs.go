@@ -6,7 +6,9 @@ import (
 	"log/slog"
 	"strconv"
 	"strings"
+	"time"
 
+	"kurut-bot/internal/infra/telegram"
 	"kurut-bot/internal/storage"
 	"kurut-bot/internal/stories/tariffs"
 
@@ -23,6 +25,7 @@ type TariffsCommand struct {
 type tariffService interface {
 	GetActiveTariffs(ctx context.Context) ([]*tariffs.Tariff, error)
 	GetInactiveTariffs(ctx context.Context) ([]*tariffs.Tariff, error)
+	GetScheduledTariffs(ctx context.Context) ([]*tariffs.Tariff, error)
 	UpdateTariffStatus(ctx context.Context, tariffID int64, isActive bool) (*tariffs.Tariff, error)
 }
 
@@ -77,6 +80,12 @@ func (c *TariffsCommand) showTariffsList(ctx context.Context, chatID int64, mess
 		return c.sendError(chatID, "Ошибка получения тарифов")
 	}
 
+	scheduledTariffs, err := c.tariffService.GetScheduledTariffs(ctx)
+	if err != nil {
+		c.logger.Error("Failed to get scheduled tariffs", "error", err)
+		return c.sendError(chatID, "Ошибка получения тарифов")
+	}
+
 	// Создаем map для быстрого поиска статистики
 	statsMap := make(map[int64]int)
 	for _, s := range activeStats {
@@ -112,6 +121,16 @@ func (c *TariffsCommand) showTariffsList(ctx context.Context, chatID int64, mess
 		text.WriteString("_Нет активных тарифов_\n\n")
 	}
 
+	if len(scheduledTariffs) > 0 {
+		text.WriteString("*Запланированные и завершенные промо:*\n")
+		now := time.Now()
+		for _, t := range scheduledTariffs {
+			text.WriteString(fmt.Sprintf("• %s (%d дн., %.0f₽): %s, *%d* чел.\n",
+				t.Name, t.DurationDays, t.Price, promoStatusText(t, now), statsMap[t.ID]))
+		}
+		text.WriteString("\n")
+	}
+
 	if len(inactiveTariffs) > 0 {
 		text.WriteString("*Архивные тарифы:*\n")
 		for _, t := range inactiveTariffs {
@@ -130,9 +149,22 @@ func (c *TariffsCommand) showTariffsList(ctx context.Context, chatID int64, mess
 		tgbotapi.NewInlineKeyboardButtonData("➕ Создать тариф", "trf_create"),
 	))
 
+	// Активные тарифы вне промо-периода управляются так же, как продающиеся
+	manageable := append(append([]*tariffs.Tariff{}, activeTariffs...), scheduledTariffs...)
+
+	// Кнопки редактирования для активных тарифов
+	for _, t := range manageable {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("✏️ Изменить: %s", t.Name),
+				fmt.Sprintf("trf_edit:%d", t.ID),
+			),
+		))
+	}
+
 	// Кнопки архивации для активных тарифов
-	if len(activeTariffs) > 0 {
-		for _, t := range activeTariffs {
+	if len(manageable) > 0 {
+		for _, t := range manageable {
 			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
 				tgbotapi.NewInlineKeyboardButtonData(
 					fmt.Sprintf("📦 Архивировать: %s", t.Name),
@@ -166,7 +198,7 @@ func (c *TariffsCommand) showTariffsList(ctx context.Context, chatID int64, mess
 		editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text.String())
 		editMsg.ParseMode = "Markdown"
 		editMsg.ReplyMarkup = &keyboard
-		_, err = c.bot.Send(editMsg)
+		err = telegram.SafeEdit(c.bot, editMsg, "")
 	} else {
 		msg := tgbotapi.NewMessage(chatID, text.String())
 		msg.ParseMode = "Markdown"
@@ -177,6 +209,14 @@ func (c *TariffsCommand) showTariffsList(ctx context.Context, chatID int64, mess
 	return err
 }
 
+// promoStatusText - почему тариф сейчас не продается: промо еще не началось или уже закончилось
+func promoStatusText(t *tariffs.Tariff, now time.Time) string {
+	if t.IsScheduled(now) {
+		return "с " + t.ValidFrom.Format("02.01.2006")
+	}
+	return "промо закончилось " + t.ValidUntil.AddDate(0, 0, -1).Format("02.01.2006")
+}
+
 // HandleCallback обрабатывает callback-запросы для тарифов
 func (c *TariffsCommand) HandleCallback(ctx context.Context, query *tgbotapi.CallbackQuery) error {
 	chatID := query.Message.Chat.ID
@@ -188,8 +228,8 @@ func (c *TariffsCommand) HandleCallback(ctx context.Context, query *tgbotapi.Cal
 	_, _ = c.bot.Request(callback)
 
 	switch {
-	case data == "trf_create":
-		// Этот callback будет обработан в router для запуска flow создания тарифа
+	case data == "trf_create", strings.HasPrefix(data, "trf_edit:"):
+		// Эти callback'и обрабатываются в router для запуска flow создания и редактирования тарифа
 		return nil
 
 	case strings.HasPrefix(data, "trf_archive:"):
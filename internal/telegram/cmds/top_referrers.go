@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"kurut-bot/internal/infra/telegram"
 	"kurut-bot/internal/storage"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -66,8 +67,7 @@ func (c *TopReferrersCommand) Refresh(ctx context.Context, chatID int64, message
 	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
 	edit.ParseMode = "Markdown"
 	edit.ReplyMarkup = &keyboard
-	_, err = c.bot.Send(edit)
-	return err
+	return telegram.SafeEdit(c.bot, edit, "")
 }
 
 func (c *TopReferrersCommand) formatTopReferrers(stats []storage.ReferrerStats) string {
@@ -0,0 +1,334 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/stories/traffic"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// TrafficTopUpCommand - докупка трафика для подписок с лимитом
+type TrafficTopUpCommand struct {
+	bot            *tgbotapi.BotAPI
+	subStorage     TrafficTopUpSubStorage
+	topUpStorage   TrafficTopUpStorage
+	tariffService  TrafficTopUpTariffService
+	paymentService TrafficTopUpPaymentService
+	pricePerGB     float64
+	logger         *slog.Logger
+}
+
+type TrafficTopUpSubStorage interface {
+	GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error)
+}
+
+type TrafficTopUpStorage interface {
+	CreateTrafficTopUp(ctx context.Context, topUp traffic.TopUp) (*traffic.TopUp, error)
+	GetTrafficTopUp(ctx context.Context, id int64) (*traffic.TopUp, error)
+	ApplyTrafficTopUp(ctx context.Context, id int64) (bool, error)
+}
+
+type TrafficTopUpTariffService interface {
+	GetTariff(ctx context.Context, criteria tariffs.GetCriteria) (*tariffs.Tariff, error)
+}
+
+type TrafficTopUpPaymentService interface {
+	CreatePaymentWithMeta(ctx context.Context, p payment.Payment, meta payment.CreatePaymentMeta) (*payment.Payment, error)
+	CheckPaymentStatus(ctx context.Context, paymentID int64) (*payment.Payment, error)
+}
+
+func NewTrafficTopUpCommand(
+	bot *tgbotapi.BotAPI,
+	subStorage TrafficTopUpSubStorage,
+	topUpStorage TrafficTopUpStorage,
+	tariffService TrafficTopUpTariffService,
+	paymentService TrafficTopUpPaymentService,
+	pricePerGB float64,
+	logger *slog.Logger,
+) *TrafficTopUpCommand {
+	return &TrafficTopUpCommand{
+		bot:            bot,
+		subStorage:     subStorage,
+		topUpStorage:   topUpStorage,
+		tariffService:  tariffService,
+		paymentService: paymentService,
+		pricePerGB:     pricePerGB,
+		logger:         logger,
+	}
+}
+
+// TrafficTopUpButton возвращает кнопку "Докупить трафик" для подписки
+func TrafficTopUpButton(subscriptionID int64) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("📶 Докупить трафик", fmt.Sprintf("ttu_menu:%d", subscriptionID))
+}
+
+// FormatTrafficQuota формирует строку лимита трафика с учетом докупленного
+func FormatTrafficQuota(tariffLimitGB *int, extraGB int) string {
+	total := traffic.TotalLimitGB(tariffLimitGB, extraGB)
+	if total == nil {
+		return "📊 Трафик: безлимитный"
+	}
+	if extraGB > 0 {
+		return fmt.Sprintf("📊 Трафик: %d ГБ (из них докуплено %d ГБ)", *total, extraGB)
+	}
+	return fmt.Sprintf("📊 Трафик: %d ГБ", *total)
+}
+
+// HandleCallback обрабатывает ttu_menu:subID, ttu_buy:subID:gb, ttu_check:topUpID.
+// Ассистент докупает трафик только своим подпискам, админ - любым
+func (c *TrafficTopUpCommand) HandleCallback(ctx context.Context, viewerTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	chatID := callbackQuery.Message.Chat.ID
+	parts := strings.Split(callbackQuery.Data, ":")
+	if len(parts) < 2 {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return c.answerCallback(callbackQuery.ID, "Неверный ID")
+	}
+
+	switch parts[0] {
+	case "ttu_menu":
+		return c.showPackages(ctx, callbackQuery, chatID, id, viewerTelegramID, isAdmin)
+	case "ttu_buy":
+		if len(parts) != 3 {
+			return c.answerCallback(callbackQuery.ID, "Неверный формат")
+		}
+		gb, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return c.answerCallback(callbackQuery.ID, "Неверный размер пакета")
+		}
+		return c.createTopUp(ctx, callbackQuery, chatID, id, gb, viewerTelegramID, isAdmin)
+	case "ttu_check":
+		return c.checkTopUp(ctx, callbackQuery, chatID, id, viewerTelegramID, isAdmin)
+	default:
+		return c.answerCallback(callbackQuery.ID, "Неизвестная команда")
+	}
+}
+
+// showPackages отправляет выбор пакета трафика отдельным сообщением
+func (c *TrafficTopUpCommand) showPackages(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery, chatID int64, subID int64, viewerTelegramID int64, isAdmin bool) error {
+	sub, tariff, err := c.getSubscriptionWithTariff(ctx, subID)
+	if err != nil {
+		c.logger.Error("Failed to get subscription for traffic top-up", "error", err, "sub_id", subID)
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	if !isAdmin && (sub.CreatedByTelegramID == nil || *sub.CreatedByTelegramID != viewerTelegramID) {
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	if tariff.TrafficLimitGB == nil {
+		return c.answerCallback(callbackQuery.ID, "У тарифа безлимитный трафик")
+	}
+
+	_ = c.answerCallback(callbackQuery.ID, "")
+
+	text := fmt.Sprintf(
+		"📶 *Докупить трафик*\n\n"+
+			"🔹 Подписка #%d\n"+
+			"📅 Тариф: %s\n"+
+			"%s\n\n"+
+			"Выберите пакет (%.0f ₽ за ГБ):",
+		sub.ID, tariff.Name, FormatTrafficQuota(tariff.TrafficLimitGB, sub.ExtraTrafficGB), c.pricePerGB)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, gb := range traffic.TopUpPackagesGB {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("+%d ГБ — %.0f ₽", gb, c.packagePrice(gb)),
+				fmt.Sprintf("ttu_buy:%d:%d", sub.ID, gb),
+			),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+// createTopUp создает платеж за пакет и показывает ссылку на оплату
+func (c *TrafficTopUpCommand) createTopUp(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery, chatID int64, subID int64, gb int, viewerTelegramID int64, isAdmin bool) error {
+	if !isTopUpPackage(gb) {
+		return c.answerCallback(callbackQuery.ID, "Неверный размер пакета")
+	}
+
+	sub, tariff, err := c.getSubscriptionWithTariff(ctx, subID)
+	if err != nil {
+		c.logger.Error("Failed to get subscription for traffic top-up", "error", err, "sub_id", subID)
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	if !isAdmin && (sub.CreatedByTelegramID == nil || *sub.CreatedByTelegramID != viewerTelegramID) {
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	if tariff.TrafficLimitGB == nil {
+		return c.answerCallback(callbackQuery.ID, "У тарифа безлимитный трафик")
+	}
+
+	amount := c.packagePrice(gb)
+	paymentObj, err := c.paymentService.CreatePaymentWithMeta(ctx, payment.Payment{
+		UserID: sub.UserID,
+		Amount: amount,
+		Status: payment.StatusPending,
+	}, subPaymentMeta(sub, tariff.Name))
+	if err != nil {
+		c.logger.Error("Failed to create traffic top-up payment", "error", err, "sub_id", subID)
+		return c.answerCallback(callbackQuery.ID, "Ошибка создания платежа")
+	}
+
+	topUp, err := c.topUpStorage.CreateTrafficTopUp(ctx, traffic.TopUp{
+		SubscriptionID: sub.ID,
+		PaymentID:      paymentObj.ID,
+		TrafficGB:      gb,
+		Amount:         amount,
+	})
+	if err != nil {
+		c.logger.Error("Failed to create traffic top-up", "error", err, "sub_id", subID)
+		return c.answerCallback(callbackQuery.ID, "Ошибка создания заказа")
+	}
+
+	// Mock mode: платёж уже approved - сразу начисляем трафик
+	if paymentObj.PaymentURL == nil && paymentObj.Status == payment.StatusApproved {
+		return c.applyTopUp(ctx, callbackQuery, chatID, topUp)
+	}
+
+	if paymentObj.PaymentURL == nil || *paymentObj.PaymentURL == "" {
+		c.logger.Error("Payment URL is empty", "payment_id", paymentObj.ID)
+		return c.answerCallback(callbackQuery.ID, "Ссылка на оплату недоступна")
+	}
+
+	_ = c.answerCallback(callbackQuery.ID, "Ссылка создана")
+
+	text := fmt.Sprintf(
+		"💳 *Оплата трафика*\n\n"+
+			"🔹 Подписка #%d\n"+
+			"📶 Пакет: +%d ГБ\n"+
+			"💰 Сумма: %.0f ₽\n\n"+
+			"🔗 [link](%s)",
+		sub.ID, gb, amount, *paymentObj.PaymentURL)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Проверить оплату", fmt.Sprintf("ttu_check:%d", topUp.ID)),
+		),
+	)
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, callbackQuery.Message.MessageID, text)
+	editMsg.ParseMode = "Markdown"
+	editMsg.ReplyMarkup = &keyboard
+	editMsg.DisableWebPagePreview = true
+	return telegram.SafeEdit(c.bot, editMsg, "")
+}
+
+// checkTopUp проверяет оплату пакета и начисляет трафик
+func (c *TrafficTopUpCommand) checkTopUp(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery, chatID int64, topUpID int64, viewerTelegramID int64, isAdmin bool) error {
+	topUp, err := c.topUpStorage.GetTrafficTopUp(ctx, topUpID)
+	if err != nil || topUp == nil {
+		c.logger.Error("Failed to get traffic top-up", "error", err, "top_up_id", topUpID)
+		return c.answerCallback(callbackQuery.ID, "Заказ не найден")
+	}
+	if !isAdmin {
+		sub, err := c.subStorage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{topUp.SubscriptionID}})
+		if err != nil || sub == nil || sub.CreatedByTelegramID == nil || *sub.CreatedByTelegramID != viewerTelegramID {
+			return c.answerCallback(callbackQuery.ID, "Заказ не найден")
+		}
+	}
+	if topUp.Status == traffic.StatusApplied {
+		return c.answerCallback(callbackQuery.ID, "Трафик уже начислен")
+	}
+
+	paymentObj, err := c.paymentService.CheckPaymentStatus(ctx, topUp.PaymentID)
+	if err != nil {
+		c.logger.Error("Failed to check traffic top-up payment", "error", err, "payment_id", topUp.PaymentID)
+		return c.answerCallback(callbackQuery.ID, "Ошибка проверки платежа")
+	}
+
+	switch paymentObj.Status {
+	case payment.StatusApproved:
+		return c.applyTopUp(ctx, callbackQuery, chatID, topUp)
+	case payment.StatusRejected, payment.StatusCancelled:
+		return c.answerCallback(callbackQuery.ID, "❌ Платёж отклонён или отменён")
+	default:
+		alertConfig := tgbotapi.NewCallbackWithAlert(callbackQuery.ID, "⏳ Платёж ещё не оплачен")
+		_, _ = c.bot.Request(alertConfig)
+		return nil
+	}
+}
+
+func (c *TrafficTopUpCommand) applyTopUp(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery, chatID int64, topUp *traffic.TopUp) error {
+	applied, err := c.topUpStorage.ApplyTrafficTopUp(ctx, topUp.ID)
+	if err != nil {
+		c.logger.Error("Failed to apply traffic top-up", "error", err, "top_up_id", topUp.ID)
+		return c.answerCallback(callbackQuery.ID, "Ошибка начисления трафика")
+	}
+	if !applied {
+		return c.answerCallback(callbackQuery.ID, "Трафик уже начислен")
+	}
+
+	c.logger.Info("Traffic top-up applied", "top_up_id", topUp.ID, "sub_id", topUp.SubscriptionID, "gb", topUp.TrafficGB)
+	_ = c.answerCallback(callbackQuery.ID, "✅ Трафик начислен")
+
+	quota := ""
+	if sub, tariff, err := c.getSubscriptionWithTariff(ctx, topUp.SubscriptionID); err == nil {
+		quota = "\n" + FormatTrafficQuota(tariff.TrafficLimitGB, sub.ExtraTrafficGB)
+	}
+
+	text := fmt.Sprintf(
+		"✅ *Трафик начислен*\n\n"+
+			"🔹 Подписка #%d\n"+
+			"📶 Пакет: +%d ГБ%s",
+		topUp.SubscriptionID, topUp.TrafficGB, quota)
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, callbackQuery.Message.MessageID, text)
+	editMsg.ParseMode = "Markdown"
+	return telegram.SafeEdit(c.bot, editMsg, "")
+}
+
+func (c *TrafficTopUpCommand) getSubscriptionWithTariff(ctx context.Context, subID int64) (*subs.Subscription, *tariffs.Tariff, error) {
+	sub, err := c.subStorage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{subID}})
+	if err != nil {
+		return nil, nil, fmt.Errorf("get subscription: %w", err)
+	}
+	if sub == nil {
+		return nil, nil, fmt.Errorf("subscription %d not found", subID)
+	}
+
+	tariff, err := c.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &sub.TariffID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("get tariff: %w", err)
+	}
+	if tariff == nil {
+		return nil, nil, fmt.Errorf("tariff %d not found", sub.TariffID)
+	}
+
+	return sub, tariff, nil
+}
+
+func (c *TrafficTopUpCommand) packagePrice(gb int) float64 {
+	return float64(gb) * c.pricePerGB
+}
+
+func isTopUpPackage(gb int) bool {
+	for _, size := range traffic.TopUpPackagesGB {
+		if size == gb {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *TrafficTopUpCommand) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := c.bot.Request(callback)
+	return err
+}
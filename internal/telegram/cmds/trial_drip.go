@@ -0,0 +1,137 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/drip"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/telegram/messages"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// TrialDripCommand отправляет ассистентам карточки рассылки клиентам на пробном периоде
+// и отключает рассылку, если клиент отказался
+type TrialDripCommand struct {
+	bot             *tgbotapi.BotAPI
+	subStorage      ClientLanguageSubStorage
+	storage         TrialDripStorage
+	langStorage     ClientLanguageStorage
+	discountPercent int
+	logger          *slog.Logger
+}
+
+type TrialDripStorage interface {
+	SetDripOptOut(ctx context.Context, clientWhatsApp string, createdByTelegramID int64) error
+}
+
+// NewTrialDripCommand создает команду; discountPercent - скидка, которую предлагаем в последний день пробного периода
+func NewTrialDripCommand(
+	bot *tgbotapi.BotAPI,
+	subStorage ClientLanguageSubStorage,
+	storage TrialDripStorage,
+	langStorage ClientLanguageStorage,
+	discountPercent int,
+	logger *slog.Logger,
+) *TrialDripCommand {
+	return &TrialDripCommand{
+		bot:             bot,
+		subStorage:      subStorage,
+		storage:         storage,
+		langStorage:     langStorage,
+		discountPercent: discountPercent,
+		logger:          logger,
+	}
+}
+
+// SendStepMessage отправляет ассистенту карточку этапа рассылки со ссылкой на WhatsApp клиента и готовым текстом
+func (c *TrialDripCommand) SendStepMessage(ctx context.Context, chatID int64, sub *subs.Subscription, step drip.Step) error {
+	if sub.ClientWhatsApp == nil || *sub.ClientWhatsApp == "" {
+		return fmt.Errorf("subscription %d has no client whatsapp", sub.ID)
+	}
+	phone := *sub.ClientWhatsApp
+
+	stored, err := c.langStorage.GetClientLanguage(ctx, phone)
+	if err != nil {
+		c.logger.Warn("Failed to get client language", "error", err, "whatsapp", phone)
+	}
+	waText := messages.WhatsAppTextForDripStep(clientlang.Resolve(stored, phone), step, c.discountPercent)
+
+	var header string
+	switch step {
+	case drip.StepUpgrade:
+		header = fmt.Sprintf("💳 *Пробный период заканчивается завтра*\n\nПредложите клиенту оплату со скидкой %d%%", c.discountPercent)
+	case drip.StepWinBack:
+		header = "🔄 *Пробный период закончился*\n\nКлиент не оплатил - попробуйте его вернуть"
+	default:
+		header = "👋 *Пробный период: 2-й день*\n\nСпросите клиента, как работает VPN"
+	}
+
+	text := fmt.Sprintf("%s\n\n📱 Клиент: [%s](%s)", header, phone, GenerateWhatsAppLink(phone, waText))
+	if sub.ExpiresAt != nil {
+		text += fmt.Sprintf("\n📅 Пробный до: %s", sub.ExpiresAt.Format("02.01.2006"))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	msg.DisableWebPagePreview = true
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(ClientLanguageButton(sub.ID)),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔕 Не писать клиенту", fmt.Sprintf("drip_off:%d", sub.ID)),
+		),
+	)
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+// HandleCallback обрабатывает drip_off:subID - клиент отказался от рассылки
+func (c *TrialDripCommand) HandleCallback(ctx context.Context, telegramID int64, callbackQuery *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(callbackQuery.Data, ":")
+	if len(parts) != 2 || parts[0] != "drip_off" {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+
+	subID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return c.answerCallback(callbackQuery.ID, "Неверный ID подписки")
+	}
+
+	sub, err := c.subStorage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{subID}})
+	if err != nil || sub == nil {
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	if sub.ClientWhatsApp == nil || *sub.ClientWhatsApp == "" {
+		return c.answerCallback(callbackQuery.ID, "У подписки нет номера клиента")
+	}
+
+	if err := c.storage.SetDripOptOut(ctx, *sub.ClientWhatsApp, telegramID); err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка сохранения")
+		return fmt.Errorf("set drip opt-out: %w", err)
+	}
+
+	c.logger.Info("Client opted out of trial drip",
+		"audit", true,
+		"telegram_id", telegramID,
+		"subscription_id", subID,
+		"whatsapp", *sub.ClientWhatsApp,
+	)
+
+	_ = c.answerCallback(callbackQuery.ID, "🔕 Клиенту больше не будет рассылки")
+
+	// Убираем кнопку отказа, выбор языка оставляем
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(ClientLanguageButton(sub.ID)))
+	return telegram.SafeEdit(c.bot, tgbotapi.NewEditMessageReplyMarkup(callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID, keyboard), "")
+}
+
+func (c *TrialDripCommand) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := c.bot.Request(callback)
+	return err
+}
@@ -0,0 +1,120 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/stories/unpaidsubs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// UnpaidSubsCommand обрабатывает действия из алерта о подписках без оплаты
+type UnpaidSubsCommand struct {
+	bot           *tgbotapi.BotAPI
+	storage       UnpaidSubsStorage
+	tariffService UnpaidSubsTariffService
+}
+
+type UnpaidSubsStorage interface {
+	GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error)
+	ListSubscriptionPayments(ctx context.Context, subscriptionID int64) ([]*payment.Payment, error)
+	SetUnpaidSubscriptionAlertStatus(ctx context.Context, subscriptionID int64, status unpaidsubs.AlertStatus) error
+}
+
+type UnpaidSubsTariffService interface {
+	GetTariff(ctx context.Context, criteria tariffs.GetCriteria) (*tariffs.Tariff, error)
+}
+
+func NewUnpaidSubsCommand(bot *tgbotapi.BotAPI, storage UnpaidSubsStorage, tariffService UnpaidSubsTariffService) *UnpaidSubsCommand {
+	return &UnpaidSubsCommand{
+		bot:           bot,
+		storage:       storage,
+		tariffService: tariffService,
+	}
+}
+
+// HandleCallback обрабатывает callback кнопок usub_inv:subID и usub_ign:subID
+func (c *UnpaidSubsCommand) HandleCallback(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(callbackQuery.Data, ":")
+	if len(parts) != 2 {
+		return c.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+
+	subID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return c.answerCallback(callbackQuery.ID, "Неверный ID подписки")
+	}
+
+	switch parts[0] {
+	case "usub_inv":
+		return c.investigate(ctx, callbackQuery, subID)
+	case "usub_ign":
+		if err := c.storage.SetUnpaidSubscriptionAlertStatus(ctx, subID, unpaidsubs.AlertStatusIgnored); err != nil {
+			_ = c.answerCallback(callbackQuery.ID, "Ошибка сохранения")
+			return fmt.Errorf("ignore unpaid subscription %d: %w", subID, err)
+		}
+		return c.answerCallback(callbackQuery.ID, fmt.Sprintf("Подписка #%d помечена как проверенная", subID))
+	default:
+		return c.answerCallback(callbackQuery.ID, "Неизвестная команда")
+	}
+}
+
+// investigate отправляет детали подписки и все привязанные к ней платежи
+func (c *UnpaidSubsCommand) investigate(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery, subID int64) error {
+	sub, err := c.storage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{subID}})
+	if err != nil || sub == nil {
+		return c.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+
+	payments, err := c.storage.ListSubscriptionPayments(ctx, subID)
+	if err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка получения платежей")
+		return fmt.Errorf("list payments for subscription %d: %w", subID, err)
+	}
+
+	_ = c.answerCallback(callbackQuery.ID, "")
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔍 *Подписка #%d*\n\n", sub.ID))
+	sb.WriteString(fmt.Sprintf("Статус: %s\n", sub.Status))
+	if tariff, err := c.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &sub.TariffID}); err == nil && tariff != nil {
+		sb.WriteString(fmt.Sprintf("Тариф: %s (%.2f ₽)\n", tariff.Name, tariff.Price))
+	}
+	if sub.ClientWhatsApp != nil {
+		sb.WriteString(fmt.Sprintf("Клиент: %s\n", *sub.ClientWhatsApp))
+	}
+	if sub.CreatedByTelegramID != nil {
+		sb.WriteString(fmt.Sprintf("Создал: [%d](tg://user?id=%d)\n", *sub.CreatedByTelegramID, *sub.CreatedByTelegramID))
+	}
+	sb.WriteString(fmt.Sprintf("Создана: %s UTC\n", sub.CreatedAt.Format("02.01.2006 15:04")))
+
+	sb.WriteString("\n*Платежи:*\n")
+	if len(payments) == 0 {
+		sb.WriteString("нет привязанных платежей\n")
+	}
+	for _, p := range payments {
+		sb.WriteString(fmt.Sprintf("#%d — %.0f ₽, %s\n", p.ID, p.Amount, formatPaymentStatus(p.Status)))
+	}
+
+	msg := tgbotapi.NewMessage(callbackQuery.Message.Chat.ID, sb.String())
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🙈 Игнорировать", fmt.Sprintf("usub_ign:%d", sub.ID)),
+		),
+	)
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+func (c *UnpaidSubsCommand) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := c.bot.Request(callback)
+	return err
+}
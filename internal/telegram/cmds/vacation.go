@@ -0,0 +1,337 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/vacations"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	vacationDateLayout     = "02.01.2006"
+	vacationCallbackLayout = "20060102"
+	// maxVacationDays - защита от опечаток в датах
+	maxVacationDays = 90
+	// maxHandoverSubs - сколько подписок перечислять в сводке при передаче дел
+	maxHandoverSubs = 30
+)
+
+var vacationPresetDays = []int{3, 7, 14}
+
+// VacationCommand управляет отпуском ассистента: пока он в отпуске, уведомления об истечении уходят замене
+type VacationCommand struct {
+	bot        *tgbotapi.BotAPI
+	storage    VacationStorage
+	staff      staffProvider
+	adminChats adminChatsProvider
+	logger     *slog.Logger
+}
+
+type VacationStorage interface {
+	SaveAssistantVacation(ctx context.Context, vacation vacations.Vacation) (*vacations.Vacation, error)
+	GetAssistantVacation(ctx context.Context, assistantTelegramID int64) (*vacations.Vacation, error)
+	DeleteAssistantVacation(ctx context.Context, assistantTelegramID int64) error
+	ListSubscriptions(ctx context.Context, criteria subs.ListCriteria) ([]*subs.Subscription, error)
+}
+
+// NewVacationCommand создает команду; staff - кого можно выбрать заменой, adminChats - куда уходят уведомления без замены
+func NewVacationCommand(bot *tgbotapi.BotAPI, storage VacationStorage, staff staffProvider, adminChats adminChatsProvider, logger *slog.Logger) *VacationCommand {
+	return &VacationCommand{
+		bot:        bot,
+		storage:    storage,
+		staff:      staff,
+		adminChats: adminChats,
+		logger:     logger,
+	}
+}
+
+// ParseVacationDates разбирает "ДД.ММ.ГГГГ ДД.ММ.ГГГГ" (первый и последний день отпуска).
+// Возвращает начало первого дня и начало дня выхода
+func ParseVacationDates(args string, today time.Time) (time.Time, time.Time, error) {
+	parts := strings.Fields(args)
+	if len(parts) != 2 {
+		return time.Time{}, time.Time{}, errors.New("укажите даты начала и окончания")
+	}
+
+	start, err := time.ParseInLocation(vacationDateLayout, parts[0], time.UTC)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("неверная дата начала")
+	}
+	last, err := time.ParseInLocation(vacationDateLayout, parts[1], time.UTC)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("неверная дата окончания")
+	}
+
+	end := last.AddDate(0, 0, 1)
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, errors.New("окончание раньше начала")
+	}
+	if !end.After(startOfDay(today)) {
+		return time.Time{}, time.Time{}, errors.New("отпуск уже закончился")
+	}
+	if end.Sub(start) > maxVacationDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("отпуск не может быть длиннее %d дней", maxVacationDays)
+	}
+
+	return start, end, nil
+}
+
+// Execute обрабатывает /vacation [ДД.ММ.ГГГГ ДД.ММ.ГГГГ]
+func (c *VacationCommand) Execute(ctx context.Context, telegramID, chatID int64, args string) error {
+	if strings.TrimSpace(args) != "" {
+		start, end, err := ParseVacationDates(args, time.Now().UTC())
+		if err != nil {
+			return c.send(chatID, "❌ "+err.Error()+"\n\nФормат: `/vacation 20.10.2026 01.11.2026`", nil)
+		}
+		text, keyboard := c.backupChoice(start, end, telegramID)
+		return c.send(chatID, text, &keyboard)
+	}
+
+	vacation, err := c.storage.GetAssistantVacation(ctx, telegramID)
+	if err != nil {
+		return fmt.Errorf("get vacation: %w", err)
+	}
+
+	text, keyboard := c.statusScreen(vacation)
+	return c.send(chatID, text, &keyboard)
+}
+
+// HandleCallback обрабатывает vac_days:N, vac_bk:начало:конец:замена и vac_off
+func (c *VacationCommand) HandleCallback(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(callbackQuery.Data, ":")
+	telegramID := callbackQuery.From.ID
+	chatID := callbackQuery.Message.Chat.ID
+	messageID := callbackQuery.Message.MessageID
+
+	switch parts[0] {
+	case "vac_days":
+		if len(parts) != 2 {
+			return c.answerCallback(callbackQuery.ID, "Неверный формат")
+		}
+		days, err := strconv.Atoi(parts[1])
+		if err != nil || days <= 0 || days > maxVacationDays {
+			return c.answerCallback(callbackQuery.ID, "Неверный срок")
+		}
+		start := startOfDay(time.Now().UTC())
+		text, keyboard := c.backupChoice(start, start.AddDate(0, 0, days), telegramID)
+		return c.edit(chatID, messageID, text, &keyboard, callbackQuery.ID)
+
+	case "vac_bk":
+		if len(parts) != 4 {
+			return c.answerCallback(callbackQuery.ID, "Неверный формат")
+		}
+		start, err1 := time.ParseInLocation(vacationCallbackLayout, parts[1], time.UTC)
+		end, err2 := time.ParseInLocation(vacationCallbackLayout, parts[2], time.UTC)
+		backupID, err3 := strconv.ParseInt(parts[3], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			return c.answerCallback(callbackQuery.ID, "Неверный формат")
+		}
+		return c.saveVacation(ctx, callbackQuery, start, end, backupID)
+
+	case "vac_off":
+		if err := c.storage.DeleteAssistantVacation(ctx, telegramID); err != nil {
+			_ = c.answerCallback(callbackQuery.ID, "Ошибка сохранения")
+			return fmt.Errorf("delete vacation: %w", err)
+		}
+		return c.edit(chatID, messageID, "✅ С возвращением! Уведомления снова приходят вам.", nil, callbackQuery.ID)
+
+	default:
+		return c.answerCallback(callbackQuery.ID, "Неизвестная команда")
+	}
+}
+
+// statusScreen показывает текущий отпуск или предлагает выбрать срок
+func (c *VacationCommand) statusScreen(vacation *vacations.Vacation) (string, tgbotapi.InlineKeyboardMarkup) {
+	if vacation != nil && vacation.EndsAt.After(time.Now().UTC()) {
+		text := fmt.Sprintf("🏖 *Отпуск*\n\nС %s по %s\nЗамена: %s",
+			vacation.StartsAt.Format(vacationDateLayout),
+			vacation.LastDay().Format(vacationDateLayout),
+			c.backupName(vacation.BackupTelegramID))
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("✅ Вернуться из отпуска", "vac_off"),
+			),
+		)
+		return text, keyboard
+	}
+
+	text := "🏖 *Отпуск*\n\n" +
+		"Пока вы в отпуске, уведомления об истекающих и просроченных подписках уходят замене.\n\n" +
+		"Выберите срок с сегодняшнего дня или укажите даты: `/vacation 20.10.2026 01.11.2026`"
+
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(vacationPresetDays))
+	for _, days := range vacationPresetDays {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%d дн.", days), fmt.Sprintf("vac_days:%d", days)))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(row,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel")),
+	)
+	return text, keyboard
+}
+
+// backupChoice предлагает выбрать замену из сотрудников или админов
+func (c *VacationCommand) backupChoice(start, end time.Time, assistantTelegramID int64) (string, tgbotapi.InlineKeyboardMarkup) {
+	text := fmt.Sprintf("🏖 Отпуск с %s по %s\n\nКому передать уведомления?",
+		start.Format(vacationDateLayout), end.AddDate(0, 0, -1).Format(vacationDateLayout))
+
+	prefix := fmt.Sprintf("vac_bk:%s:%s", start.Format(vacationCallbackLayout), end.Format(vacationCallbackLayout))
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, id := range c.staff.StaffIDs() {
+		if id == assistantTelegramID {
+			continue
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👤 "+c.userName(id), fmt.Sprintf("%s:%d", prefix, id)),
+		))
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("👥 Админам", prefix+":0")),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel")),
+	)
+
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// saveVacation сохраняет отпуск и отправляет замене сводку по подпискам, которые истекут за время отпуска
+func (c *VacationCommand) saveVacation(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery, start, end time.Time, backupID int64) error {
+	assistantID := callbackQuery.From.ID
+
+	vacation := vacations.Vacation{
+		AssistantTelegramID: assistantID,
+		StartsAt:            start,
+		EndsAt:              end,
+	}
+	if backupID != 0 {
+		vacation.BackupTelegramID = &backupID
+	}
+
+	saved, err := c.storage.SaveAssistantVacation(ctx, vacation)
+	if err != nil {
+		_ = c.answerCallback(callbackQuery.ID, "Ошибка сохранения")
+		return fmt.Errorf("save vacation: %w", err)
+	}
+
+	handover, err := c.handoverSummary(ctx, saved)
+	if err != nil {
+		c.logger.Error("Failed to build vacation handover summary", "assistant_id", assistantID, "error", err)
+		handover = ""
+	}
+
+	recipients := c.adminChats.AdminChatIDs()
+	if saved.BackupTelegramID != nil {
+		recipients = []int64{*saved.BackupTelegramID}
+	}
+	notice := fmt.Sprintf("🏖 *Передача дел*\n\nАссистент %s в отпуске с %s по %s. На это время его уведомления об истечении подписок будут приходить %s.",
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, c.userName(assistantID)),
+		saved.StartsAt.Format(vacationDateLayout),
+		saved.LastDay().Format(vacationDateLayout),
+		c.recipientText(saved.BackupTelegramID))
+	for _, chatID := range recipients {
+		if err := c.send(chatID, notice+handover, nil); err != nil {
+			c.logger.Error("Failed to send vacation handover", "chat_id", chatID, "error", err)
+		}
+	}
+
+	text := fmt.Sprintf("✅ Отпуск сохранен: с %s по %s\nЗамена: %s%s",
+		saved.StartsAt.Format(vacationDateLayout),
+		saved.LastDay().Format(vacationDateLayout),
+		c.backupName(saved.BackupTelegramID),
+		handover)
+	return c.edit(callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID, text, nil, callbackQuery.ID)
+}
+
+// handoverSummary перечисляет активные подписки ассистента, истекающие до конца отпуска
+func (c *VacationCommand) handoverSummary(ctx context.Context, vacation *vacations.Vacation) (string, error) {
+	assistantID := vacation.AssistantTelegramID
+	expiring, err := c.storage.ListSubscriptions(ctx, subs.ListCriteria{
+		CreatedByTelegramID: &assistantID,
+		Status:              []subs.Status{subs.StatusActive},
+		ExpiresBefore:       &vacation.EndsAt,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(expiring) == 0 {
+		return "\n\nЗа время отпуска подписки ассистента не истекают.", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n\n*Истекут до конца отпуска: %d*\n", len(expiring)))
+	for i, sub := range expiring {
+		if i >= maxHandoverSubs {
+			sb.WriteString(fmt.Sprintf("…и ещё %d\n", len(expiring)-maxHandoverSubs))
+			break
+		}
+		client := "—"
+		if sub.ClientWhatsApp != nil {
+			client = *sub.ClientWhatsApp
+		}
+		expires := "—"
+		if sub.ExpiresAt != nil {
+			expires = sub.ExpiresAt.Format(vacationDateLayout)
+		}
+		sb.WriteString(fmt.Sprintf("• %s — до %s\n", client, expires))
+	}
+	return sb.String(), nil
+}
+
+func (c *VacationCommand) backupName(backupID *int64) string {
+	if backupID == nil {
+		return "админы"
+	}
+	return tgbotapi.EscapeText(tgbotapi.ModeMarkdown, c.userName(*backupID))
+}
+
+func (c *VacationCommand) recipientText(backupID *int64) string {
+	if backupID == nil {
+		return "админам"
+	}
+	return "вам"
+}
+
+func (c *VacationCommand) userName(telegramID int64) string {
+	return telegramUserName(c.bot, telegramID)
+}
+
+// telegramUserName возвращает имя пользователя из Telegram, при ошибке - его ID
+func telegramUserName(bot *tgbotapi.BotAPI, telegramID int64) string {
+	return telegram.UserName(bot, telegramID)
+}
+
+func (c *VacationCommand) send(chatID int64, text string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	_, err := c.bot.Send(msg)
+	return err
+}
+
+func (c *VacationCommand) edit(chatID int64, messageID int, text string, keyboard *tgbotapi.InlineKeyboardMarkup, callbackID string) error {
+	editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	editMsg.ParseMode = "Markdown"
+	editMsg.ReplyMarkup = keyboard
+	return telegram.SafeEdit(c.bot, editMsg, callbackID)
+}
+
+func (c *VacationCommand) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := c.bot.Request(callback)
+	return err
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,54 @@
+package cmds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseVacationDates(t *testing.T) {
+	today := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		args      string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{
+			name:      "range",
+			args:      "20.10.2026 01.11.2026",
+			wantStart: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "single day",
+			args:      "16.10.2026 16.10.2026",
+			wantStart: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{name: "one date", args: "20.10.2026", wantErr: true},
+		{name: "bad format", args: "2026-10-20 2026-11-01", wantErr: true},
+		{name: "end before start", args: "01.11.2026 20.10.2026", wantErr: true},
+		{name: "already over", args: "01.10.2026 10.10.2026", wantErr: true},
+		{name: "too long", args: "20.10.2026 20.03.2027", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := ParseVacationDates(tt.args, today)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseVacationDates(%q) expected error", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseVacationDates(%q) unexpected error: %v", tt.args, err)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("ParseVacationDates(%q) = %v, %v, want %v, %v", tt.args, start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/watemplates"
+	"kurut-bot/internal/telegram/messages"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// waPlanPageSize - сколько клиентов показывать на одной странице плана
+const waPlanPageSize = 5
+
+// WAPlanCommand - план рассылки WhatsApp: список клиентов по фильтру с кнопками-ссылками
+type WAPlanCommand struct {
+	bot           *tgbotapi.BotAPI
+	subStorage    WAPlanSubStorage
+	serverStorage WAPlanServerStorage
+	logger        *slog.Logger
+}
+
+type WAPlanSubStorage interface {
+	ListSubscriptions(ctx context.Context, criteria subs.ListCriteria) ([]*subs.Subscription, error)
+	GetClientLanguage(ctx context.Context, clientWhatsApp string) (clientlang.Language, error)
+}
+
+type WAPlanServerStorage interface {
+	ListServers(ctx context.Context, criteria servers.ListCriteria) ([]*servers.Server, error)
+	GetServer(ctx context.Context, criteria servers.GetCriteria) (*servers.Server, error)
+}
+
+func NewWAPlanCommand(
+	bot *tgbotapi.BotAPI,
+	subStorage WAPlanSubStorage,
+	serverStorage WAPlanServerStorage,
+	logger *slog.Logger,
+) *WAPlanCommand {
+	return &WAPlanCommand{
+		bot:           bot,
+		subStorage:    subStorage,
+		serverStorage: serverStorage,
+		logger:        logger,
+	}
+}
+
+// Execute показывает выбор фильтра
+func (c *WAPlanCommand) Execute(ctx context.Context, chatID int64) error {
+	text, keyboard := c.filtersMenu()
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	_, err := c.bot.Send(msg)
+	return err
+}
+
+// HandleCallback обрабатывает callback кнопок wap_*
+func (c *WAPlanCommand) HandleCallback(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery) error {
+	chatID := callbackQuery.Message.Chat.ID
+	messageID := callbackQuery.Message.MessageID
+	parts := strings.Split(callbackQuery.Data, ":")
+
+	_ = c.answerCallback(callbackQuery.ID, "")
+
+	switch parts[0] {
+	case "wap_menu":
+		text, keyboard := c.filtersMenu()
+		return c.editMessage(chatID, messageID, text, keyboard)
+	case "wap_srvlist":
+		return c.showServers(ctx, chatID, messageID)
+	case "wap_week":
+		// wap_week:page
+		if len(parts) != 2 {
+			return nil
+		}
+		page, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil
+		}
+		now := time.Now().UTC()
+		weekEnd := now.AddDate(0, 0, 7)
+		list, err := c.subStorage.ListSubscriptions(ctx, subs.ListCriteria{
+			Status:        []subs.Status{subs.StatusActive},
+			ExpiresAfter:  &now,
+			ExpiresBefore: &weekEnd,
+		})
+		if err != nil {
+			c.logger.Error("Failed to list expiring subscriptions for waplan", "error", err)
+			return c.editMessage(chatID, messageID, "❌ Ошибка загрузки подписок", tgbotapi.InlineKeyboardMarkup{})
+		}
+		return c.showPage(ctx, chatID, messageID, "⏰ *Истекают на этой неделе*", list, page, "wap_week")
+	case "wap_srv":
+		// wap_srv:serverID:page
+		if len(parts) != 3 {
+			return nil
+		}
+		serverID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil
+		}
+		page, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil
+		}
+		list, err := c.subStorage.ListSubscriptions(ctx, subs.ListCriteria{
+			Status:    []subs.Status{subs.StatusActive},
+			ServerIDs: []int64{serverID},
+		})
+		if err != nil {
+			c.logger.Error("Failed to list server subscriptions for waplan", "error", err, "server_id", serverID)
+			return c.editMessage(chatID, messageID, "❌ Ошибка загрузки подписок", tgbotapi.InlineKeyboardMarkup{})
+		}
+		title := fmt.Sprintf("🖥 *Клиенты сервера #%d*", serverID)
+		if srv, err := c.serverStorage.GetServer(ctx, servers.GetCriteria{ID: &serverID}); err == nil && srv != nil {
+			title = fmt.Sprintf("🖥 *Клиенты сервера %s*", srv.Name)
+		}
+		return c.showPage(ctx, chatID, messageID, title, list, page, fmt.Sprintf("wap_srv:%d", serverID))
+	}
+
+	return nil
+}
+
+func (c *WAPlanCommand) filtersMenu() (string, tgbotapi.InlineKeyboardMarkup) {
+	text := "📋 *План рассылки WhatsApp*\n\nВыберите список клиентов:"
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏰ Истекают на неделе", "wap_week:0"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🖥 По серверу", "wap_srvlist"),
+		),
+	)
+	return text, keyboard
+}
+
+func (c *WAPlanCommand) showServers(ctx context.Context, chatID int64, messageID int) error {
+	archived := false
+	list, err := c.serverStorage.ListServers(ctx, servers.ListCriteria{Archived: &archived, Limit: 100})
+	if err != nil {
+		c.logger.Error("Failed to list servers for waplan", "error", err)
+		return c.editMessage(chatID, messageID, "❌ Ошибка получения списка серверов", tgbotapi.InlineKeyboardMarkup{})
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, srv := range list {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🖥 "+srv.Name, fmt.Sprintf("wap_srv:%d:0", srv.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Назад", "wap_menu"),
+	))
+
+	return c.editMessage(chatID, messageID, "🖥 *Выберите сервер:*", tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// showPage показывает страницу клиентов с кнопками WhatsApp и навигацией
+func (c *WAPlanCommand) showPage(ctx context.Context, chatID int64, messageID int, title string, list []*subs.Subscription, page int, navPrefix string) error {
+	// Только клиенты с номером, ближайшие к истечению - первыми
+	var clients []*subs.Subscription
+	for _, sub := range list {
+		if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" && sub.ExpiresAt != nil {
+			clients = append(clients, sub)
+		}
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ExpiresAt.Before(*clients[j].ExpiresAt)
+	})
+
+	backRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Фильтры", "wap_menu"),
+	)
+
+	if len(clients) == 0 {
+		return c.editMessage(chatID, messageID, title+"\n\nКлиентов не найдено", tgbotapi.NewInlineKeyboardMarkup(backRow))
+	}
+
+	totalPages := (len(clients) + waPlanPageSize - 1) / waPlanPageSize
+	if page < 0 || page >= totalPages {
+		page = 0
+	}
+	start := page * waPlanPageSize
+	end := min(start+waPlanPageSize, len(clients))
+
+	now := time.Now().UTC()
+	var text strings.Builder
+	text.WriteString(title)
+	text.WriteString(fmt.Sprintf("\n\nВсего клиентов: %d · страница %d/%d\n\n", len(clients), page+1, totalPages))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, sub := range clients[start:end] {
+		phone := *sub.ClientWhatsApp
+		text.WriteString(fmt.Sprintf("%d. `%s` — до %s\n", start+i+1, phone, sub.ExpiresAt.Format("02.01.2006")))
+
+		lang, err := c.subStorage.GetClientLanguage(ctx, phone)
+		if err != nil {
+			c.logger.Warn("Failed to get client language", "error", err, "whatsapp", phone)
+		}
+		link := GenerateWhatsAppLink(phone, whatsAppTemplateForExpiry(clientlang.Resolve(lang, phone), *sub.ExpiresAt, now,
+			watemplates.NewVars(phone, sub.ExpiresAt, "")))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL(fmt.Sprintf("💬 %s", phone), link),
+		))
+	}
+
+	var navRow []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		navRow = append(navRow, tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", fmt.Sprintf("%s:%d", navPrefix, page-1)))
+	}
+	if page < totalPages-1 {
+		navRow = append(navRow, tgbotapi.NewInlineKeyboardButtonData("Следующий ➡️", fmt.Sprintf("%s:%d", navPrefix, page+1)))
+	}
+	if len(navRow) > 0 {
+		rows = append(rows, navRow)
+	}
+	rows = append(rows, backRow)
+
+	return c.editMessage(chatID, messageID, text.String(), tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// whatsAppTemplateForExpiry подбирает шаблон сообщения на языке клиента по количеству дней до истечения.
+// Тариф в плане не загружается, поэтому {tariff} подставляется пустым
+func whatsAppTemplateForExpiry(lang clientlang.Language, expiresAt time.Time, now time.Time, vars watemplates.Vars) string {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	expiryDay := time.Date(expiresAt.Year(), expiresAt.Month(), expiresAt.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(expiryDay.Sub(today).Hours() / 24)
+
+	return messages.WhatsAppTextForDays(lang, days, vars)
+}
+
+func (c *WAPlanCommand) editMessage(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = "Markdown"
+	if len(keyboard.InlineKeyboard) > 0 {
+		edit.ReplyMarkup = &keyboard
+	}
+	return telegram.SafeEdit(c.bot, edit, "")
+}
+
+func (c *WAPlanCommand) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := c.bot.Request(callback)
+	return err
+}
@@ -0,0 +1,374 @@
+package cmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/launch"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const whitelistUsage = "🚀 *Мягкий запуск*\n\n" +
+	"/whitelist — белый список и лист ожидания\n" +
+	"`/whitelist add 123456789` — открыть доступ\n" +
+	"`/whitelist remove 123456789` — закрыть доступ"
+
+const launchSoonText = "🚀 Скоро запуск!\n\n" +
+	"Бот пока работает для ограниченного круга пользователей. " +
+	"Запишитесь в лист ожидания — мы сообщим, когда откроем доступ."
+
+// WhitelistCommand - мягкий запуск: пока он включен, ботом пользуются только админы и пользователи из белого списка
+type WhitelistCommand struct {
+	bot     *tgbotapi.BotAPI
+	storage WhitelistStorage
+	enabled bool
+	roles   whitelistRoles
+	logger  *slog.Logger
+}
+
+// whitelistRoles - текущие роли пользователей: админов режим не ограничивает, заявки уходят в админские чаты
+type whitelistRoles interface {
+	IsAdmin(telegramID int64) bool
+	IsAllowedUser(telegramID int64) bool
+	AdminChatIDs() []int64
+}
+
+type WhitelistStorage interface {
+	SaveWhitelistEntry(ctx context.Context, entry launch.Entry) (*launch.Entry, error)
+	GetWhitelistEntry(ctx context.Context, telegramID int64) (*launch.Entry, error)
+	ListWhitelist(ctx context.Context) ([]*launch.Entry, error)
+	DeleteWhitelistEntry(ctx context.Context, telegramID int64) (bool, error)
+}
+
+// NewWhitelistCommand создает команду; enabled - включен ли режим мягкого запуска,
+// roles - роли пользователей и куда уходят заявки из листа ожидания
+func NewWhitelistCommand(
+	bot *tgbotapi.BotAPI,
+	storage WhitelistStorage,
+	enabled bool,
+	roles whitelistRoles,
+	logger *slog.Logger,
+) *WhitelistCommand {
+	return &WhitelistCommand{
+		bot:     bot,
+		storage: storage,
+		enabled: enabled,
+		roles:   roles,
+		logger:  logger,
+	}
+}
+
+// ParseWhitelistArgs разбирает "add|remove <Telegram ID>"
+func ParseWhitelistArgs(args string) (string, int64, error) {
+	parts := strings.Fields(args)
+	if len(parts) != 2 {
+		return "", 0, errors.New("укажите действие и Telegram ID")
+	}
+
+	action := strings.ToLower(parts[0])
+	if action != "add" && action != "remove" {
+		return "", 0, fmt.Errorf("непонятное действие «%s»", parts[0])
+	}
+
+	telegramID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || telegramID <= 0 {
+		return "", 0, errors.New("неверный Telegram ID")
+	}
+	return action, telegramID, nil
+}
+
+// Restricts проверяет, закрыт ли бот для пользователя режимом мягкого запуска.
+// При ошибке БД пропускаем пользователя дальше: обычная проверка доступа все равно сработает
+func (c *WhitelistCommand) Restricts(ctx context.Context, telegramID int64) bool {
+	if !c.enabled || c.roles.IsAdmin(telegramID) {
+		return false
+	}
+
+	entry, err := c.storage.GetWhitelistEntry(ctx, telegramID)
+	if err != nil {
+		c.logger.Error("Failed to get whitelist entry", "error", err, "telegram_id", telegramID)
+		return false
+	}
+	return entry == nil || !entry.Approved
+}
+
+// HandleOutsider отвечает пользователю вне белого списка: «скоро запуск» и запись в лист ожидания (lwl_signup)
+func (c *WhitelistCommand) HandleOutsider(ctx context.Context, update *tgbotapi.Update) error {
+	if update.CallbackQuery != nil {
+		if update.CallbackQuery.Data != "lwl_signup" {
+			_, _ = c.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "🚀 Скоро запуск"))
+			return nil
+		}
+		return c.signup(ctx, update.CallbackQuery)
+	}
+
+	if update.Message == nil {
+		return nil
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📝 Записаться в лист ожидания", "lwl_signup"),
+	))
+	entry, err := c.storage.GetWhitelistEntry(ctx, update.Message.From.ID)
+	if err != nil {
+		c.logger.Error("Failed to get whitelist entry", "error", err, "telegram_id", update.Message.From.ID)
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, launchSoonText)
+	if entry != nil {
+		msg.Text = "🚀 Скоро запуск!\n\nВы уже в листе ожидания — мы сообщим, когда откроем доступ."
+	} else {
+		msg.ReplyMarkup = keyboard
+	}
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+func (c *WhitelistCommand) signup(ctx context.Context, query *tgbotapi.CallbackQuery) error {
+	telegramID := query.From.ID
+
+	existing, err := c.storage.GetWhitelistEntry(ctx, telegramID)
+	if err != nil {
+		c.logger.Error("Failed to get whitelist entry", "error", err, "telegram_id", telegramID)
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "❌ Ошибка, попробуйте позже"))
+		return nil
+	}
+	if existing == nil {
+		if _, err := c.storage.SaveWhitelistEntry(ctx, launch.Entry{
+			TelegramID: telegramID,
+			Username:   launchUserName(query.From),
+		}); err != nil {
+			c.logger.Error("Failed to save waitlist signup", "error", err, "telegram_id", telegramID)
+			_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "❌ Ошибка, попробуйте позже"))
+			return nil
+		}
+		c.notifyAdmins(telegramID, launchUserName(query.From))
+	}
+
+	_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "✅ Вы в листе ожидания"))
+	if query.Message != nil {
+		editMsg := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID,
+			"🚀 Скоро запуск!\n\nВы в листе ожидания — мы сообщим, когда откроем доступ.")
+		return telegram.SafeEdit(c.bot, editMsg, "")
+	}
+	return nil
+}
+
+func (c *WhitelistCommand) notifyAdmins(telegramID int64, username string) {
+	text := fmt.Sprintf("📝 Заявка в лист ожидания\n\n%s (ID %d)", username, telegramID)
+	if !c.roles.IsAllowedUser(telegramID) {
+		text += "\n⚠️ У пользователя нет роли в боте: после открытия доступа бот ему все равно будет недоступен"
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Открыть доступ", fmt.Sprintf("lwl_ok:%d", telegramID)),
+	))
+	for _, chatID := range c.roles.AdminChatIDs() {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ReplyMarkup = keyboard
+		if _, err := c.bot.Send(msg); err != nil {
+			c.logger.Error("Failed to notify admin about waitlist signup", "error", err, "chat_id", chatID)
+		}
+	}
+}
+
+// Execute обрабатывает /whitelist: без аргументов - список, add/remove - управление доступом
+func (c *WhitelistCommand) Execute(ctx context.Context, adminTelegramID, chatID int64, args string) error {
+	if strings.TrimSpace(args) == "" {
+		return c.showList(ctx, chatID, 0)
+	}
+
+	action, telegramID, err := ParseWhitelistArgs(args)
+	if err != nil {
+		return c.send(chatID, fmt.Sprintf("❌ %s\n\n%s", err, whitelistUsage))
+	}
+
+	if action == "add" {
+		if err := c.approve(ctx, adminTelegramID, telegramID); err != nil {
+			return c.send(chatID, "❌ Ошибка сохранения")
+		}
+		return c.send(chatID, fmt.Sprintf("✅ Доступ для `%d` открыт", telegramID))
+	}
+
+	removed, err := c.remove(ctx, adminTelegramID, telegramID)
+	if err != nil {
+		return c.send(chatID, "❌ Ошибка сохранения")
+	}
+	if !removed {
+		return c.send(chatID, fmt.Sprintf("Пользователя `%d` нет в белом списке", telegramID))
+	}
+	return c.send(chatID, fmt.Sprintf("🚫 Доступ для `%d` закрыт", telegramID))
+}
+
+// HandleCallback обрабатывает кнопки админа: lwl_ok:<id> - открыть доступ, lwl_rm:<id> - убрать из списка
+func (c *WhitelistCommand) HandleCallback(ctx context.Context, adminTelegramID int64, query *tgbotapi.CallbackQuery) error {
+	action, idStr, _ := strings.Cut(query.Data, ":")
+	telegramID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "Неверный формат"))
+		return nil
+	}
+
+	switch action {
+	case "lwl_ok":
+		if err := c.approve(ctx, adminTelegramID, telegramID); err != nil {
+			_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "❌ Ошибка"))
+			return nil
+		}
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "✅ Доступ открыт"))
+	case "lwl_rm":
+		if _, err := c.remove(ctx, adminTelegramID, telegramID); err != nil {
+			_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "❌ Ошибка"))
+			return nil
+		}
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, "🚫 Доступ закрыт"))
+	default:
+		_, _ = c.bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		return nil
+	}
+
+	return c.showList(ctx, query.Message.Chat.ID, query.Message.MessageID)
+}
+
+func (c *WhitelistCommand) approve(ctx context.Context, adminTelegramID, telegramID int64) error {
+	existing, err := c.storage.GetWhitelistEntry(ctx, telegramID)
+	if err != nil {
+		c.logger.Error("Failed to get whitelist entry", "error", err, "telegram_id", telegramID)
+		return err
+	}
+
+	if _, err := c.storage.SaveWhitelistEntry(ctx, launch.Entry{
+		TelegramID: telegramID,
+		Approved:   true,
+		AddedBy:    &adminTelegramID,
+	}); err != nil {
+		c.logger.Error("Failed to approve whitelist entry", "error", err, "telegram_id", telegramID)
+		return err
+	}
+
+	c.logger.Info("Whitelist access granted",
+		"audit", true,
+		"admin_telegram_id", adminTelegramID,
+		"telegram_id", telegramID,
+	)
+
+	// Сообщаем только тем, кто ждал в листе ожидания: остальные боту еще не писали
+	if existing != nil && !existing.Approved {
+		_, _ = c.bot.Send(tgbotapi.NewMessage(telegramID, "🎉 Доступ к боту открыт! Нажмите /start"))
+	}
+	return nil
+}
+
+func (c *WhitelistCommand) remove(ctx context.Context, adminTelegramID, telegramID int64) (bool, error) {
+	removed, err := c.storage.DeleteWhitelistEntry(ctx, telegramID)
+	if err != nil {
+		c.logger.Error("Failed to delete whitelist entry", "error", err, "telegram_id", telegramID)
+		return false, err
+	}
+	if removed {
+		c.logger.Info("Whitelist access revoked",
+			"audit", true,
+			"admin_telegram_id", adminTelegramID,
+			"telegram_id", telegramID,
+		)
+	}
+	return removed, nil
+}
+
+func (c *WhitelistCommand) showList(ctx context.Context, chatID int64, messageID int) error {
+	entries, err := c.storage.ListWhitelist(ctx)
+	if err != nil {
+		c.logger.Error("Failed to list whitelist", "error", err)
+		return c.send(chatID, "❌ Ошибка получения белого списка")
+	}
+
+	var text strings.Builder
+	text.WriteString("🚀 Мягкий запуск: ")
+	if c.enabled {
+		text.WriteString("включен\n")
+	} else {
+		text.WriteString("выключен, бот доступен всем ассистентам\n")
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var approved, waiting []*launch.Entry
+	for _, e := range entries {
+		if e.Approved {
+			approved = append(approved, e)
+		} else {
+			waiting = append(waiting, e)
+		}
+	}
+
+	if len(waiting) > 0 {
+		text.WriteString("\n📝 Лист ожидания:\n")
+		for _, e := range waiting {
+			text.WriteString(c.entryLine(e))
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ Открыть: %d", e.TelegramID), fmt.Sprintf("lwl_ok:%d", e.TelegramID)),
+				tgbotapi.NewInlineKeyboardButtonData("🗑", fmt.Sprintf("lwl_rm:%d", e.TelegramID)),
+			))
+		}
+	}
+
+	text.WriteString("\n✅ Белый список:\n")
+	if len(approved) == 0 {
+		text.WriteString("пусто — доступ есть только у админов\n")
+	}
+	for _, e := range approved {
+		text.WriteString(c.entryLine(e))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🚫 Закрыть: %d", e.TelegramID), fmt.Sprintf("lwl_rm:%d", e.TelegramID)),
+		))
+	}
+	text.WriteString("\nДобавить: /whitelist add <Telegram ID>")
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", "main_menu"),
+	))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	if messageID > 0 {
+		editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text.String())
+		editMsg.ReplyMarkup = &keyboard
+		return telegram.SafeEdit(c.bot, editMsg, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ReplyMarkup = keyboard
+	_, err = c.bot.Send(msg)
+	return err
+}
+
+// entryLine - строка списка: "• 123456789 @user", для пользователей без роли с пометкой
+func (c *WhitelistCommand) entryLine(e *launch.Entry) string {
+	line := fmt.Sprintf("• %d", e.TelegramID)
+	if e.Username != "" {
+		line += " " + e.Username
+	}
+	if !c.roles.IsAllowedUser(e.TelegramID) {
+		line += " (нет роли)"
+	}
+	return line + "\n"
+}
+
+// launchUserName - @username или имя пользователя для списка у админа
+func launchUserName(user *tgbotapi.User) string {
+	if user.UserName != "" {
+		return "@" + user.UserName
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+func (c *WhitelistCommand) send(chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	_, err := c.bot.Send(msg)
+	return err
+}
@@ -0,0 +1,31 @@
+package cmds
+
+import "testing"
+
+func TestParseWhitelistArgs(t *testing.T) {
+	tests := []struct {
+		args       string
+		wantAction string
+		wantID     int64
+		wantErr    bool
+	}{
+		{"add 123456789", "add", 123456789, false},
+		{"REMOVE 42", "remove", 42, false},
+		{"add", "", 0, true},
+		{"add @user", "", 0, true},
+		{"ban 42", "", 0, true},
+		{"add 42 43", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			action, id, err := ParseWhitelistArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWhitelistArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if action != tt.wantAction || id != tt.wantID {
+				t.Errorf("ParseWhitelistArgs(%q) = %q, %d, want %q, %d", tt.args, action, id, tt.wantAction, tt.wantID)
+			}
+		})
+	}
+}
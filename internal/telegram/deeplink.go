@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/stories/offers"
+	"kurut-bot/internal/stories/subs"
+)
+
+// DeepLinkKind - тип параметра /start (t.me/<bot>?start=<payload>)
+type DeepLinkKind string
+
+const (
+	DeepLinkNone     DeepLinkKind = ""      // без параметра или параметр не распознан - приветствие
+	DeepLinkReferral DeepLinkKind = "ref"   // ref_<subID> - подписка по приглашению
+	DeepLinkGift     DeepLinkKind = "gift"  // gift_<tariffID> - подписка на заданный тариф
+	DeepLinkPayment  DeepLinkKind = "pay"   // pay_<orderID> - статус ожидающего оплаты заказа
+	DeepLinkSub      DeepLinkKind = "sub"   // sub_<subID> - карточка подписки
+	DeepLinkOffer    DeepLinkKind = "offer" // offer_<tariffID>_<assistantTelegramID> - предложение тарифа от ассистента
+)
+
+// DeepLink - разобранный параметр /start
+type DeepLink struct {
+	Kind DeepLinkKind
+	ID   int64
+	// AssistantID - ассистент, отправивший предложение тарифа (только offer_)
+	AssistantID int64
+}
+
+// deepLinkPrefixes - префиксы параметров с числовым ID; ref_ разбирается в subs, offer_ - в offers
+var deepLinkPrefixes = map[string]DeepLinkKind{
+	"gift_": DeepLinkGift,
+	"pay_":  DeepLinkPayment,
+	"sub_":  DeepLinkSub,
+}
+
+// ParseDeepLink разбирает параметр /start. Неизвестный префикс или неверный ID - DeepLinkNone
+func ParseDeepLink(payload string) DeepLink {
+	payload = strings.TrimSpace(payload)
+	if id, ok := subs.ParseReferralStartPayload(payload); ok {
+		return DeepLink{Kind: DeepLinkReferral, ID: id}
+	}
+	if tariffID, assistantID, ok := offers.ParseStartPayload(payload); ok {
+		return DeepLink{Kind: DeepLinkOffer, ID: tariffID, AssistantID: assistantID}
+	}
+
+	for prefix, kind := range deepLinkPrefixes {
+		raw, ok := strings.CutPrefix(payload, prefix)
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			return DeepLink{}
+		}
+		return DeepLink{Kind: kind, ID: id}
+	}
+	return DeepLink{}
+}
+
+// StartPayload возвращает параметр /start для ссылки t.me/<bot>?start=<payload>
+func (d DeepLink) StartPayload() string {
+	switch d.Kind {
+	case DeepLinkReferral:
+		return subs.ReferralStartPayload(d.ID)
+	case DeepLinkOffer:
+		return offers.StartPayload(d.ID, d.AssistantID)
+	}
+	return string(d.Kind) + "_" + strconv.FormatInt(d.ID, 10)
+}
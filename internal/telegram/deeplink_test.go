@@ -0,0 +1,43 @@
+package telegram
+
+import "testing"
+
+func TestParseDeepLink(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    DeepLink
+	}{
+		{"", DeepLink{}},
+		{"ref_42", DeepLink{Kind: DeepLinkReferral, ID: 42}},
+		{" gift_3 ", DeepLink{Kind: DeepLinkGift, ID: 3}},
+		{"pay_17", DeepLink{Kind: DeepLinkPayment, ID: 17}},
+		{"sub_905", DeepLink{Kind: DeepLinkSub, ID: 905}},
+		{"offer_2_123456", DeepLink{Kind: DeepLinkOffer, ID: 2, AssistantID: 123456}},
+		{"offer_2", DeepLink{}},
+		{"sub_", DeepLink{}},
+		{"sub_0", DeepLink{}},
+		{"pay_-1", DeepLink{}},
+		{"gift_abc", DeepLink{}},
+		{"promo_5", DeepLink{}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseDeepLink(tt.payload); got != tt.want {
+			t.Errorf("ParseDeepLink(%q) = %+v, want %+v", tt.payload, got, tt.want)
+		}
+	}
+}
+
+func TestDeepLinkStartPayloadRoundTrip(t *testing.T) {
+	for _, link := range []DeepLink{
+		{Kind: DeepLinkReferral, ID: 1},
+		{Kind: DeepLinkGift, ID: 2},
+		{Kind: DeepLinkPayment, ID: 3},
+		{Kind: DeepLinkSub, ID: 4},
+		{Kind: DeepLinkOffer, ID: 5, AssistantID: 6},
+	} {
+		if got := ParseDeepLink(link.StartPayload()); got != link {
+			t.Errorf("ParseDeepLink(%q) = %+v, want %+v", link.StartPayload(), got, link)
+		}
+	}
+}
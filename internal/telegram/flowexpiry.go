@@ -0,0 +1,144 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgclient "kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/users"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// flowExpireInterval - как часто искать брошенные флоу
+	flowExpireInterval = time.Minute
+	// flowRestartPrefix - кнопка «Начать заново» после истечения сессии: flow_restart:<префикс состояния>
+	flowRestartPrefix = "flow_restart:"
+)
+
+// silentExpiryStates очищаются без уведомления: приветствие - не флоу, а оплату и чек обрабатывают кнопки заказа,
+// которым состояние не нужно
+var silentExpiryStates = map[states.State]bool{
+	states.StateWelcome:                  true,
+	states.StateDone:                     true,
+	states.UserBuySubWaitPayment:         true,
+	states.UserRenewSubWaitPayment:       true,
+	states.AdminCreateSubWaitPayment:     true,
+	states.AdminCreateSubWaitReceipt:     true,
+	states.AdminMigrateClientWaitPayment: true,
+}
+
+// restartableFlows - префиксы флоу, которые можно начать заново кнопкой из уведомления
+var restartableFlows = map[string]bool{
+	"acs": true,
+	"amc": true,
+	"act": true,
+	"asv": true,
+}
+
+type idleFlowStates interface {
+	ExpireIdle(ttl time.Duration) []states.IdleFlow
+}
+
+// FlowExpirer отменяет брошенные флоу: состояние без действий дольше ttl очищается,
+// а пользователь получает сообщение с кнопкой, чтобы начать заново
+type FlowExpirer struct {
+	bot    *tgbotapi.BotAPI
+	states idleFlowStates
+	ttl    time.Duration
+	logger *slog.Logger
+}
+
+func NewFlowExpirer(bot *tgbotapi.BotAPI, sm idleFlowStates, ttl time.Duration, logger *slog.Logger) *FlowExpirer {
+	return &FlowExpirer{
+		bot:    bot,
+		states: sm,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+// Run проверяет флоу раз в flowExpireInterval до отмены ctx
+func (e *FlowExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(flowExpireInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.expire()
+		}
+	}
+}
+
+func (e *FlowExpirer) expire() {
+	for _, flow := range e.states.ExpireIdle(e.ttl) {
+		e.logger.Info("Flow expired", "chat_id", flow.ChatID, "state", flow.State)
+		if silentExpiryStates[flow.State] {
+			continue
+		}
+		if _, err := e.bot.Send(expiredFlowMessage(flow, e.ttl)); err != nil {
+			e.logger.Warn("Failed to notify about expired flow", "error", err, "chat_id", flow.ChatID)
+		}
+	}
+}
+
+// expiredFlowMessage - уведомление об истекшей сессии с кнопкой перезапуска, если флоу можно начать заново
+func expiredFlowMessage(flow states.IdleFlow, ttl time.Duration) tgbotapi.MessageConfig {
+	text := fmt.Sprintf("⌛️ Сессия истекла: действие отменено после %d мин без ответа.", int(ttl.Minutes()))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if prefix := flowPrefix(flow.State); restartableFlows[prefix] {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Начать заново", flowRestartPrefix+prefix),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🏠 Главное меню", "main_menu"),
+	))
+
+	msg := tgbotapi.NewMessage(flow.ChatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return msg
+}
+
+// flowPrefix - префикс флоу из состояния: acs_wt_tariff -> acs
+func flowPrefix(state states.State) string {
+	prefix, _, _ := strings.Cut(string(state), "_")
+	return prefix
+}
+
+// restartFlow начинает заново флоу из уведомления об истекшей сессии
+func (r *Router) restartFlow(update *tgbotapi.Update, user *users.User) error {
+	chatID := update.CallbackQuery.Message.Chat.ID
+	prefix := strings.TrimPrefix(update.CallbackQuery.Data, flowRestartPrefix)
+
+	if prefix != "acs" && !r.adminChecker.IsAdmin(user.TelegramID) {
+		_, _ = r.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав"))
+		return nil
+	}
+	_, _ = r.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+	// Убираем кнопки с уведомления, чтобы не начать флоу дважды
+	_ = tgclient.SafeEdit(r.bot, tgbotapi.NewEditMessageReplyMarkup(chatID, update.CallbackQuery.Message.MessageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}), "")
+
+	switch prefix {
+	case "acs":
+		return r.createSubForClientHandler.Start(user.ID, user.TelegramID, chatID)
+	case "amc":
+		return r.migrateClientHandler.Start(user.ID, user.TelegramID, chatID)
+	case "act":
+		return r.createTariffHandler.Start(chatID)
+	case "asv":
+		return r.addServerHandler.Start(chatID)
+	default:
+		return r.sendHelp(chatID)
+	}
+}
@@ -0,0 +1,43 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestExpiredFlowMessage(t *testing.T) {
+	tests := []struct {
+		state       states.State
+		wantRestart string
+	}{
+		{state: states.AdminCreateSubWaitTariff, wantRestart: "flow_restart:acs"},
+		{state: states.AdminServerWaitURL, wantRestart: "flow_restart:asv"},
+		{state: states.AssistantEditSubWaitValue, wantRestart: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.state), func(t *testing.T) {
+			msg := expiredFlowMessage(states.IdleFlow{ChatID: 7, State: tt.state}, 30*time.Minute)
+			if msg.ChatID != 7 {
+				t.Errorf("ChatID = %d, want 7", msg.ChatID)
+			}
+
+			rows := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup).InlineKeyboard
+			var restart string
+			for _, row := range rows {
+				for _, button := range row {
+					if button.CallbackData != nil && *button.CallbackData != "main_menu" {
+						restart = *button.CallbackData
+					}
+				}
+			}
+			if restart != tt.wantRestart {
+				t.Errorf("restart button = %q, want %q", restart, tt.wantRestart)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package addserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// probeTimeout - сколько ждем ответа сервера при проверке подключения
+const probeTimeout = 5 * time.Second
+
+// checkResult - результат одной проверки подключения
+type checkResult struct {
+	Name    string
+	OK      bool
+	Details string
+}
+
+// probeHTTP проверяет что по адресу отвечает HTTP сервер.
+// Любой ответ кроме 5xx считаем успехом: панель может требовать авторизацию или редиректить на логин
+func probeHTTP(ctx context.Context, client *http.Client, name, url string) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return checkResult{Name: name, Details: "неверный адрес"}
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return checkResult{Name: name, Details: describeProbeError(err)}
+	}
+	defer resp.Body.Close()
+
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return checkResult{Name: name, Details: fmt.Sprintf("HTTP %d за %s", resp.StatusCode, elapsed)}
+	}
+	return checkResult{Name: name, OK: true, Details: fmt.Sprintf("HTTP %d за %s", resp.StatusCode, elapsed)}
+}
+
+// describeProbeError переводит типичные сетевые ошибки в понятный текст
+func describeProbeError(err error) string {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "deadline exceeded") || strings.Contains(msg, "Client.Timeout"):
+		return "нет ответа за " + probeTimeout.String()
+	case strings.Contains(msg, "no such host"):
+		return "домен не найден"
+	case strings.Contains(msg, "connection refused"):
+		return "соединение отклонено"
+	case strings.Contains(msg, "certificate"):
+		return "ошибка TLS сертификата"
+	default:
+		return "ошибка соединения"
+	}
+}
+
+// allChecksOK проверяет что все проверки прошли
+func allChecksOK(results []checkResult) bool {
+	for _, r := range results {
+		if !r.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// formatChecks формирует блок с результатами проверок для сообщения
+func formatChecks(results []checkResult) string {
+	var sb strings.Builder
+	sb.WriteString("🔌 *Проверка подключения:*\n")
+	for _, r := range results {
+		icon := "✅"
+		if !r.OK {
+			icon = "❌"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s: %s\n", icon, r.Name, r.Details))
+	}
+	return sb.String()
+}
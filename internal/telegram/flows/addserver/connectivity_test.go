@@ -0,0 +1,45 @@
+package addserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProbeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		wantOK bool
+	}{
+		{"ok", http.StatusOK, true},
+		{"login required", http.StatusUnauthorized, true},
+		{"server error", http.StatusBadGateway, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			result := probeHTTP(context.Background(), srv.Client(), "panel", srv.URL)
+			if result.OK != tt.wantOK {
+				t.Errorf("probeHTTP status %d: OK = %v, want %v (%s)", tt.status, result.OK, tt.wantOK, result.Details)
+			}
+		})
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		url := srv.URL
+		srv.Close()
+
+		result := probeHTTP(context.Background(), http.DefaultClient, "panel", url)
+		if result.OK {
+			t.Errorf("probeHTTP on closed server: OK = true, want false")
+		}
+	})
+}
@@ -18,6 +18,7 @@ type (
 
 	stateManager interface {
 		SetState(chatID int64, state states.State, data interface{})
+		Back(chatID int64) (states.State, bool)
 		GetAddServerData(chatID int64) (*flows.AddServerFlowData, error)
 		Clear(chatID int64)
 	}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"strconv"
 	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"kurut-bot/internal/infra/telegram"
 	"kurut-bot/internal/stories/servers"
 	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/keypad"
 	"kurut-bot/internal/telegram/states"
 )
 
@@ -18,6 +21,7 @@ type Handler struct {
 	bot           botApi
 	stateManager  stateManager
 	serverService serverService
+	httpClient    *http.Client
 	logger        *slog.Logger
 }
 
@@ -31,10 +35,23 @@ func NewHandler(
 		bot:           bot,
 		stateManager:  sm,
 		serverService: ss,
+		httpClient:    &http.Client{Timeout: probeTimeout},
 		logger:        logger,
 	}
 }
 
+const (
+	urlPrompt          = "🌐 Введите URL панели управления (например: https://wg.example.com):"
+	passwordPrompt     = "🔑 Введите пароль от панели управления:"
+	currentUsersPrompt = "👥 Введите текущее количество пользователей на сервере (0 если новый сервер):"
+	maxUsersPrompt     = "🔢 Введите максимальное количество пользователей (по умолчанию 150):"
+)
+
+var (
+	currentUsersKeypad = keypad.Config{Presets: []string{"0"}, Back: true}
+	maxUsersKeypad     = keypad.Config{Presets: []string{"50", "100", "150", "200"}, Back: true}
+)
+
 // Start начинает флоу добавления сервера
 func (h *Handler) Start(chatID int64) error {
 	flowData := &flows.AddServerFlowData{
@@ -42,6 +59,10 @@ func (h *Handler) Start(chatID int64) error {
 	}
 	h.stateManager.SetState(chatID, states.AdminServerWaitName, flowData)
 
+	return h.showNameInput(chatID)
+}
+
+func (h *Handler) showNameInput(chatID int64) error {
 	messageText := "🖥 *Добавление нового сервера*\n\n" +
 		"Введите название сервера (например: \"Server 1\", \"RU-1\"):"
 
@@ -59,6 +80,10 @@ func (h *Handler) Start(chatID int64) error {
 func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
 	ctx := context.Background()
 
+	if update.CallbackQuery != nil && update.CallbackQuery.Data == flows.BackCallback {
+		return h.handleBack(update)
+	}
+
 	switch state {
 	case states.AdminServerWaitName:
 		return h.handleNameInput(ctx, update)
@@ -102,14 +127,7 @@ func (h *Handler) handleNameInput(ctx context.Context, update *tgbotapi.Update)
 	data.Name = name
 	h.stateManager.SetState(chatID, states.AdminServerWaitURL, data)
 
-	messageText := "🌐 Введите URL панели управления (например: https://wg.example.com):"
-	keyboard := h.createCancelKeyboard()
-
-	msg := tgbotapi.NewMessage(chatID, messageText)
-	msg.ReplyMarkup = keyboard
-
-	_, err = h.bot.Send(msg)
-	return err
+	return h.sendPrompt(chatID, urlPrompt)
 }
 
 func (h *Handler) handleURLInput(ctx context.Context, update *tgbotapi.Update) error {
@@ -141,14 +159,7 @@ func (h *Handler) handleURLInput(ctx context.Context, update *tgbotapi.Update) e
 	data.UIURL = urlStr
 	h.stateManager.SetState(chatID, states.AdminServerWaitPassword, data)
 
-	messageText := "🔑 Введите пароль от панели управления:"
-	keyboard := h.createCancelKeyboard()
-
-	msg := tgbotapi.NewMessage(chatID, messageText)
-	msg.ReplyMarkup = keyboard
-
-	_, err = h.bot.Send(msg)
-	return err
+	return h.sendPrompt(chatID, passwordPrompt)
 }
 
 func (h *Handler) handlePasswordInput(ctx context.Context, update *tgbotapi.Update) error {
@@ -176,14 +187,7 @@ func (h *Handler) handlePasswordInput(ctx context.Context, update *tgbotapi.Upda
 	data.UIPassword = password
 	h.stateManager.SetState(chatID, states.AdminServerWaitCurrentUsers, data)
 
-	messageText := "👥 Введите текущее количество пользователей на сервере (0 если новый сервер):"
-	keyboard := h.createCancelKeyboard()
-
-	msg := tgbotapi.NewMessage(chatID, messageText)
-	msg.ReplyMarkup = keyboard
-
-	_, err = h.bot.Send(msg)
-	return err
+	return h.sendKeypad(chatID, currentUsersPrompt, currentUsersKeypad)
 }
 
 func (h *Handler) handleCurrentUsersInput(ctx context.Context, update *tgbotapi.Update) error {
@@ -193,11 +197,11 @@ func (h *Handler) handleCurrentUsersInput(ctx context.Context, update *tgbotapi.
 		return h.handleCancel(ctx, update)
 	}
 
-	if update.Message == nil || update.Message.Text == "" {
-		return h.sendError(chatID, "Пожалуйста, введите число")
+	currentUsersStr, ok, err := h.readNumber(update, currentUsersPrompt, currentUsersKeypad)
+	if !ok {
+		return err
 	}
 
-	currentUsersStr := strings.TrimSpace(update.Message.Text)
 	currentUsers, err := strconv.Atoi(currentUsersStr)
 	if err != nil {
 		return h.sendError(chatID, "❌ Неверный формат. Введите целое число")
@@ -215,14 +219,7 @@ func (h *Handler) handleCurrentUsersInput(ctx context.Context, update *tgbotapi.
 	data.CurrentUsers = currentUsers
 	h.stateManager.SetState(chatID, states.AdminServerWaitMaxUsers, data)
 
-	messageText := "🔢 Введите максимальное количество пользователей (по умолчанию 150):"
-	keyboard := h.createCancelKeyboard()
-
-	msg := tgbotapi.NewMessage(chatID, messageText)
-	msg.ReplyMarkup = keyboard
-
-	_, err = h.bot.Send(msg)
-	return err
+	return h.sendKeypad(chatID, maxUsersPrompt, maxUsersKeypad)
 }
 
 func (h *Handler) handleMaxUsersInput(ctx context.Context, update *tgbotapi.Update) error {
@@ -232,11 +229,11 @@ func (h *Handler) handleMaxUsersInput(ctx context.Context, update *tgbotapi.Upda
 		return h.handleCancel(ctx, update)
 	}
 
-	if update.Message == nil || update.Message.Text == "" {
-		return h.sendError(chatID, "Пожалуйста, введите число")
+	maxUsersStr, ok, err := h.readNumber(update, maxUsersPrompt, maxUsersKeypad)
+	if !ok {
+		return err
 	}
 
-	maxUsersStr := strings.TrimSpace(update.Message.Text)
 	maxUsers, err := strconv.Atoi(maxUsersStr)
 	if err != nil {
 		return h.sendError(chatID, "❌ Неверный формат. Введите целое число")
@@ -256,22 +253,34 @@ func (h *Handler) handleMaxUsersInput(ctx context.Context, update *tgbotapi.Upda
 	}
 
 	data.MaxUsers = maxUsers
-	h.stateManager.SetState(chatID, states.AdminServerWaitConfirmation, data)
 
-	return h.showConfirmation(chatID, data)
+	return h.showConfirmation(ctx, chatID, data)
 }
 
-func (h *Handler) showConfirmation(chatID int64, data *flows.AddServerFlowData) error {
+// showConfirmation проверяет подключение к серверу и показывает итог с результатами проверок
+func (h *Handler) showConfirmation(ctx context.Context, chatID int64, data *flows.AddServerFlowData) error {
+	checks := []checkResult{
+		probeHTTP(ctx, h.httpClient, "Панель управления", data.UIURL),
+	}
+	data.Reachable = allChecksOK(checks)
+	h.stateManager.SetState(chatID, states.AdminServerWaitConfirmation, data)
+
+	question := "✅ Все данные корректны?"
+	if !data.Reachable {
+		question = "⚠️ Сервер не прошел проверку. Проверьте адрес или сохраните всё равно."
+	}
+
 	messageText := fmt.Sprintf("📋 *Подтверждение добавления сервера*\n\n"+
 		"🖥 Название: %s\n"+
 		"🌐 URL: %s\n"+
 		"🔑 Пароль: `***`\n"+
 		"👥 Текущих пользователей: %d\n"+
 		"🔢 Максимум пользователей: %d\n\n"+
-		"✅ Все данные корректны?",
-		data.Name, data.UIURL, data.CurrentUsers, data.MaxUsers)
+		"%s\n"+
+		"%s",
+		data.Name, data.UIURL, data.CurrentUsers, data.MaxUsers, formatChecks(checks), question)
 
-	keyboard := h.createConfirmationKeyboard()
+	keyboard := h.createConfirmationKeyboard(data.Reachable)
 
 	msg := tgbotapi.NewMessage(chatID, messageText)
 	msg.ParseMode = "Markdown"
@@ -296,7 +305,16 @@ func (h *Handler) handleConfirmation(ctx context.Context, update *tgbotapi.Updat
 
 	switch callbackData {
 	case "confirm_add_server":
+		if !data.Reachable {
+			return h.sendError(chatID, "Сервер не прошел проверку подключения")
+		}
 		return h.createServerAndFinish(ctx, update, data)
+	case "force_add_server":
+		h.logger.Warn("Saving server that failed connectivity check", "name", data.Name, "url", data.UIURL)
+		return h.createServerAndFinish(ctx, update, data)
+	case "recheck_add_server":
+		_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Проверяем..."))
+		return h.showConfirmation(ctx, chatID, data)
 	case "cancel":
 		return h.handleCancel(ctx, update)
 	default:
@@ -368,6 +386,50 @@ func (h *Handler) handleCancel(ctx context.Context, update *tgbotapi.Update) err
 	return err
 }
 
+// handleBack возвращает на предыдущий шаг и заново показывает его подсказку. Введенные значения сохраняются
+func (h *Handler) handleBack(update *tgbotapi.Update) error {
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	state, ok := h.stateManager.Back(chatID)
+	if !ok {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Это первый шаг"))
+		return nil
+	}
+	_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+	// Убираем кнопки со старого шага, чтобы нажатие на них не попало в другой шаг
+	_ = telegram.SafeEdit(h.bot, tgbotapi.NewEditMessageReplyMarkup(chatID, update.CallbackQuery.Message.MessageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}), "")
+
+	switch state {
+	case states.AdminServerWaitName:
+		return h.showNameInput(chatID)
+	case states.AdminServerWaitURL:
+		return h.sendPrompt(chatID, urlPrompt)
+	case states.AdminServerWaitPassword:
+		return h.sendPrompt(chatID, passwordPrompt)
+	case states.AdminServerWaitCurrentUsers:
+		return h.sendKeypad(chatID, currentUsersPrompt, currentUsersKeypad)
+	case states.AdminServerWaitMaxUsers:
+		return h.sendKeypad(chatID, maxUsersPrompt, maxUsersKeypad)
+	default:
+		return fmt.Errorf("no back step for add server state: %s", state)
+	}
+}
+
+// sendPrompt отправляет подсказку текстового шага с кнопками «Назад» и «Отменить»
+func (h *Handler) sendPrompt(chatID int64, prompt string) error {
+	msg := tgbotapi.NewMessage(chatID, prompt)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			flows.BackButton(),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+		),
+	)
+
+	_, err := h.bot.Send(msg)
+	return err
+}
+
 func (h *Handler) createCancelKeyboard() tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
@@ -376,17 +438,66 @@ func (h *Handler) createCancelKeyboard() tgbotapi.InlineKeyboardMarkup {
 	)
 }
 
-func (h *Handler) createConfirmationKeyboard() tgbotapi.InlineKeyboardMarkup {
+// createConfirmationKeyboard - если проверка не прошла, сохранить можно только явным "сохранить всё равно"
+func (h *Handler) createConfirmationKeyboard(reachable bool) tgbotapi.InlineKeyboardMarkup {
+	if !reachable {
+		return tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🔄 Проверить снова", "recheck_add_server"),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("⚠️ Сохранить всё равно", "force_add_server"),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				flows.BackButton(),
+				tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+			),
+		)
+	}
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("✅ Добавить сервер", "confirm_add_server"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
+			flows.BackButton(),
 			tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
 		),
 	)
 }
 
+// sendKeypad отправляет подсказку с цифровой клавиатурой
+func (h *Handler) sendKeypad(chatID int64, prompt string, cfg keypad.Config) error {
+	msg := tgbotapi.NewMessage(chatID, keypad.Text(prompt, ""))
+	msg.ReplyMarkup = keypad.Markup(cfg, "")
+
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+// readNumber возвращает число, введенное текстом или выбранное на клавиатуре.
+// Нажатия цифр только перерисовывают клавиатуру - тогда ok=false
+func (h *Handler) readNumber(update *tgbotapi.Update, prompt string, cfg keypad.Config) (value string, ok bool, err error) {
+	if update.CallbackQuery != nil {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+
+		action, value := keypad.ParseCallback(update.CallbackQuery.Data)
+		switch action {
+		case keypad.ActionSubmit:
+			return value, true, nil
+		case keypad.ActionEdit:
+			message := update.CallbackQuery.Message
+			edit := tgbotapi.NewEditMessageTextAndMarkup(message.Chat.ID, message.MessageID, keypad.Text(prompt, value), keypad.Markup(cfg, value))
+			return "", false, telegram.SafeEdit(h.bot, edit, "")
+		}
+	}
+
+	if update.Message == nil || update.Message.Text == "" {
+		return "", false, h.sendError(extractChatID(update), "Пожалуйста, введите число или выберите его на клавиатуре")
+	}
+
+	return strings.TrimSpace(update.Message.Text), true, nil
+}
+
 func (h *Handler) sendError(chatID int64, message string) error {
 	msg := tgbotapi.NewMessage(chatID, message)
 	_, err := h.bot.Send(msg)
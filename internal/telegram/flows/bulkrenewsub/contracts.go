@@ -0,0 +1,51 @@
+package bulkrenewsub
+
+import (
+	"context"
+
+	"kurut-bot/internal/stories/bulkrenew"
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type (
+	botApi interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+		Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	}
+
+	stateManager interface {
+		Clear(chatID int64)
+		GetBulkRenewData(chatID int64) (*flows.BulkRenewFlowData, error)
+		SetState(chatID int64, state states.State, data any)
+	}
+
+	subscriptionStorage interface {
+		ListRenewableSubscriptionsByAssistant(ctx context.Context, assistantTelegramID *int64, withinDays int, limit int) ([]*subs.Subscription, error)
+	}
+
+	tariffService interface {
+		GetRenewalTariff(ctx context.Context, tariffID int64) (*tariffs.Tariff, error)
+	}
+
+	paymentService interface {
+		CreatePayment(ctx context.Context, p payment.Payment) (*payment.Payment, error)
+		CheckPaymentStatus(ctx context.Context, paymentID int64) (*payment.Payment, error)
+		LinkPaymentToSubscriptions(ctx context.Context, paymentID int64, subscriptionIDs []int64) error
+		IsManualPayment() bool
+	}
+
+	bulkRenewalStorage interface {
+		CreateBulkRenewal(ctx context.Context, renewal bulkrenew.BulkRenewal) (*bulkrenew.BulkRenewal, error)
+		GetBulkRenewal(ctx context.Context, id int64) (*bulkrenew.BulkRenewal, error)
+	}
+
+	bulkRenewService interface {
+		Complete(ctx context.Context, renewal *bulkrenew.BulkRenewal) (*bulkrenew.Result, error)
+	}
+)
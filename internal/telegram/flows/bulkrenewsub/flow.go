@@ -0,0 +1,388 @@
+package bulkrenewsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/bulkrenew"
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type Handler struct {
+	bot                 botApi
+	stateManager        stateManager
+	subscriptionStorage subscriptionStorage
+	tariffService       tariffService
+	paymentService      paymentService
+	bulkRenewalStorage  bulkRenewalStorage
+	bulkRenewService    bulkRenewService
+	logger              *slog.Logger
+}
+
+func NewHandler(
+	bot botApi,
+	sm stateManager,
+	subStorage subscriptionStorage,
+	tariffService tariffService,
+	paymentService paymentService,
+	bulkRenewalStorage bulkRenewalStorage,
+	bulkRenewService bulkRenewService,
+	logger *slog.Logger,
+) *Handler {
+	return &Handler{
+		bot:                 bot,
+		stateManager:        sm,
+		subscriptionStorage: subStorage,
+		tariffService:       tariffService,
+		paymentService:      paymentService,
+		bulkRenewalStorage:  bulkRenewalStorage,
+		bulkRenewService:    bulkRenewService,
+		logger:              logger,
+	}
+}
+
+// Start показывает истекающие и просроченные подписки для выбора.
+// Ассистент видит только свои подписки, админ - все
+func (h *Handler) Start(ctx context.Context, userID, assistantTelegramID, chatID int64, isAdmin bool) error {
+	var createdBy *int64
+	if !isAdmin {
+		createdBy = &assistantTelegramID
+	}
+
+	subscriptions, err := h.subscriptionStorage.ListRenewableSubscriptionsByAssistant(ctx, createdBy, bulkrenew.ExpiringWithinDays, bulkrenew.MaxSubscriptions)
+	if err != nil {
+		h.logger.Error("Failed to list subscriptions for bulk renewal", "error", err)
+		return h.sendError(chatID, "❌ Ошибка загрузки подписок")
+	}
+	if len(subscriptions) == 0 {
+		return h.sendError(chatID, fmt.Sprintf("✅ Нет подписок, истекающих в ближайшие %d дня", bulkrenew.ExpiringWithinDays))
+	}
+
+	candidates, err := h.buildCandidates(ctx, subscriptions)
+	if err != nil {
+		h.logger.Error("Failed to build bulk renewal candidates", "error", err)
+		return h.sendError(chatID, "❌ Ошибка загрузки тарифов")
+	}
+
+	flowData := &flows.BulkRenewFlowData{
+		AdminUserID:         userID,
+		AssistantTelegramID: assistantTelegramID,
+		Candidates:          candidates,
+	}
+	return h.showSelection(chatID, flowData)
+}
+
+// Handle обрабатывает текущее состояние
+func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
+	ctx := context.Background()
+
+	switch state {
+	case states.AssistantBulkRenewWaitSelection:
+		return h.handleSelection(ctx, update)
+	default:
+		return fmt.Errorf("unknown state: %s", state)
+	}
+}
+
+// buildCandidates считает цену продления каждой подписки по тарифу продления: текущему,
+// а после окончания промо - тарифу, указанному у промо
+func (h *Handler) buildCandidates(ctx context.Context, subscriptions []*subs.Subscription) ([]flows.BulkRenewCandidate, error) {
+	tariffCache := make(map[int64]*tariffs.Tariff)
+	candidates := make([]flows.BulkRenewCandidate, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		tariff, ok := tariffCache[sub.TariffID]
+		if !ok {
+			var err error
+			tariff, err = h.tariffService.GetRenewalTariff(ctx, sub.TariffID)
+			if err != nil {
+				return nil, fmt.Errorf("get tariff %d: %w", sub.TariffID, err)
+			}
+			tariffCache[sub.TariffID] = tariff
+		}
+		if tariff == nil {
+			h.logger.Warn("Tariff not found, skipping subscription", "sub_id", sub.ID, "tariff_id", sub.TariffID)
+			continue
+		}
+
+		candidates = append(candidates, flows.BulkRenewCandidate{
+			SubscriptionID: sub.ID,
+			Label:          subscriptionLabel(sub),
+			Price:          sub.RenewalPrice(tariff.ID, tariff.Price),
+		})
+	}
+	return candidates, nil
+}
+
+// subscriptionLabel - текст кнопки подписки: "#12 · 996555123456 · до 01.02"
+func subscriptionLabel(sub *subs.Subscription) string {
+	label := fmt.Sprintf("#%d", sub.ID)
+	if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp != "" {
+		label += " · " + *sub.ClientWhatsApp
+	}
+	if sub.ExpiresAt != nil {
+		label += " · до " + sub.ExpiresAt.Format("02.01")
+	}
+	return label
+}
+
+// showSelection показывает список подписок с отметками выбора
+func (h *Handler) showSelection(chatID int64, flowData *flows.BulkRenewFlowData) error {
+	text := "🔁 *Массовое продление*\n\nОтметьте подписки, которые клиент оплатит одним платежом."
+	if len(flowData.Candidates) == bulkrenew.MaxSubscriptions {
+		text += fmt.Sprintf("\n\nПоказаны первые %d подписок.", bulkrenew.MaxSubscriptions)
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(flowData.Candidates)+3)
+	for _, c := range flowData.Candidates {
+		mark := "⬜"
+		if isSelected(flowData.Selected, c.SubscriptionID) {
+			mark = "☑️"
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%s %s · %.0f ₽", mark, c.Label, c.Price),
+				fmt.Sprintf("brn_tgl:%d", c.SubscriptionID),
+			),
+		))
+	}
+
+	allText := "☑️ Выбрать все"
+	if len(flowData.Selected) == len(flowData.Candidates) {
+		allText = "⬜ Снять все"
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(allText, "brn_all"),
+	))
+	if len(flowData.Selected) > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("💳 Создать ссылку: %d шт. · %.0f ₽", len(flowData.Selected), selectedTotal(flowData.Candidates, flowData.Selected)),
+				"brn_pay",
+			),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+	))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return h.show(chatID, flowData, text, &keyboard)
+}
+
+// handleSelection обрабатывает отметки подписок и создание ссылки
+func (h *Handler) handleSelection(ctx context.Context, update *tgbotapi.Update) error {
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Отметьте подписки кнопками в списке")
+	}
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	flowData, err := h.stateManager.GetBulkRenewData(chatID)
+	if err != nil {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	data := update.CallbackQuery.Data
+	switch {
+	case data == "brn_all":
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		flowData.Selected = toggleAll(flowData.Candidates, flowData.Selected)
+		return h.showSelection(chatID, flowData)
+	case data == "brn_pay":
+		return h.createPayment(ctx, update.CallbackQuery, flowData)
+	case strings.HasPrefix(data, "brn_tgl:"):
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		subID, err := strconv.ParseInt(strings.TrimPrefix(data, "brn_tgl:"), 10, 64)
+		if err != nil {
+			return nil
+		}
+		flowData.Selected = toggle(flowData.Selected, subID)
+		return h.showSelection(chatID, flowData)
+	}
+
+	_ = h.answerCallback(update.CallbackQuery.ID, "")
+	return nil
+}
+
+// createPayment создает один платеж на сумму выбранных подписок и связывает его с ними
+func (h *Handler) createPayment(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery, flowData *flows.BulkRenewFlowData) error {
+	chatID := callbackQuery.Message.Chat.ID
+	if len(flowData.Selected) == 0 {
+		return h.answerCallback(callbackQuery.ID, "Выберите хотя бы одну подписку")
+	}
+
+	total := selectedTotal(flowData.Candidates, flowData.Selected)
+	paymentObj, err := h.paymentService.CreatePayment(ctx, payment.Payment{
+		UserID: flowData.AdminUserID,
+		Amount: total,
+		Status: payment.StatusPending,
+	})
+	if err != nil {
+		h.logger.Error("Failed to create bulk renewal payment", "error", err, "amount", total)
+		return h.answerCallback(callbackQuery.ID, "Ошибка создания платежа")
+	}
+
+	if err := h.paymentService.LinkPaymentToSubscriptions(ctx, paymentObj.ID, flowData.Selected); err != nil {
+		h.logger.Error("Failed to link bulk renewal payment", "error", err, "payment_id", paymentObj.ID)
+		return h.answerCallback(callbackQuery.ID, "Ошибка создания платежа")
+	}
+
+	renewal, err := h.bulkRenewalStorage.CreateBulkRenewal(ctx, bulkrenew.BulkRenewal{
+		PaymentID:           paymentObj.ID,
+		AssistantTelegramID: flowData.AssistantTelegramID,
+		ChatID:              chatID,
+		MessageID:           flowData.MessageID,
+		TotalAmount:         total,
+	})
+	if err != nil {
+		h.logger.Error("Failed to create bulk renewal", "error", err, "payment_id", paymentObj.ID)
+		return h.answerCallback(callbackQuery.ID, "Ошибка создания платежа")
+	}
+	_ = h.answerCallback(callbackQuery.ID, "Ссылка создана")
+
+	h.logger.Info("Bulk renewal payment created",
+		"audit", true,
+		"assistant_telegram_id", flowData.AssistantTelegramID,
+		"bulk_renewal_id", renewal.ID,
+		"payment_id", paymentObj.ID,
+		"subscriptions", len(flowData.Selected),
+		"amount", total)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "💳 *Ссылка на оплату продления*\n\n")
+	for _, c := range flowData.Candidates {
+		if isSelected(flowData.Selected, c.SubscriptionID) {
+			fmt.Fprintf(&b, "%s · %.0f ₽\n", c.Label, c.Price)
+		}
+	}
+	fmt.Fprintf(&b, "\n💰 Итого: %.0f ₽", total)
+
+	checkText := "✅ Проверить"
+	if paymentObj.PaymentURL != nil && *paymentObj.PaymentURL != "" {
+		fmt.Fprintf(&b, "\n\n🔗 [link](%s)\n\nПосле оплаты все подписки продлятся автоматически.", *paymentObj.PaymentURL)
+	} else if h.paymentService.IsManualPayment() {
+		checkText = "✅ Оплачено"
+		b.WriteString("\n\nРучной режим оплаты: нажмите «Оплачено», когда клиент переведет деньги.")
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(checkText, fmt.Sprintf("brn_chk:%d", renewal.ID)),
+	))
+
+	h.stateManager.Clear(chatID)
+	if flowData.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, b.String())
+		editMsg.ParseMode = "Markdown"
+		editMsg.ReplyMarkup = &keyboard
+		editMsg.DisableWebPagePreview = true
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	msg.DisableWebPagePreview = true
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// HandleCheck обрабатывает кнопку brn_chk:renewalID - проверяет оплату и продлевает подписки.
+// Проверять может ассистент, создавший ссылку, или админ
+func (h *Handler) HandleCheck(ctx context.Context, telegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	renewalID, err := strconv.ParseInt(strings.TrimPrefix(callbackQuery.Data, "brn_chk:"), 10, 64)
+	if err != nil {
+		return h.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+
+	renewal, err := h.bulkRenewalStorage.GetBulkRenewal(ctx, renewalID)
+	if err != nil || renewal == nil {
+		h.logger.Error("Failed to get bulk renewal", "error", err, "bulk_renewal_id", renewalID)
+		return h.answerCallback(callbackQuery.ID, "Продление не найдено")
+	}
+	if !isAdmin && renewal.AssistantTelegramID != telegramID {
+		return h.answerCallback(callbackQuery.ID, "❌ Нет прав")
+	}
+	if renewal.Status != bulkrenew.StatusPending {
+		return h.answerCallback(callbackQuery.ID, "Продление уже обработано")
+	}
+
+	paymentObj, err := h.paymentService.CheckPaymentStatus(ctx, renewal.PaymentID)
+	if err != nil {
+		h.logger.Error("Failed to check bulk renewal payment", "error", err, "payment_id", renewal.PaymentID)
+		return h.answerCallback(callbackQuery.ID, "Ошибка проверки платежа")
+	}
+	if paymentObj.Status != payment.StatusApproved {
+		alertConfig := tgbotapi.NewCallbackWithAlert(callbackQuery.ID, "⏳ Платёж ещё не оплачен")
+		_, _ = h.bot.Request(alertConfig)
+		return nil
+	}
+
+	result, err := h.bulkRenewService.Complete(ctx, renewal)
+	if errors.Is(err, bulkrenew.ErrAlreadyProcessed) {
+		return h.answerCallback(callbackQuery.ID, "Продление уже обработано")
+	}
+	if err != nil {
+		h.logger.Error("Failed to complete bulk renewal", "error", err, "bulk_renewal_id", renewal.ID)
+		return h.answerCallback(callbackQuery.ID, "Ошибка продления")
+	}
+	_ = h.answerCallback(callbackQuery.ID, "✅ Подписки продлены")
+
+	editMsg := tgbotapi.NewEditMessageText(callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID, result.Summary())
+	return telegram.SafeEdit(h.bot, editMsg, "")
+}
+
+// show редактирует сообщение флоу или отправляет новое
+func (h *Handler) show(chatID int64, flowData *flows.BulkRenewFlowData, text string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	h.stateManager.SetState(chatID, states.AssistantBulkRenewWaitSelection, flowData)
+
+	if flowData.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
+		editMsg.ParseMode = "Markdown"
+		editMsg.ReplyMarkup = keyboard
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	sentMsg, err := h.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+	flowData.MessageID = &sentMsg.MessageID
+	return nil
+}
+
+func (h *Handler) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := h.bot.Request(callback)
+	return err
+}
+
+func (h *Handler) sendError(chatID int64, message string) error {
+	msg := tgbotapi.NewMessage(chatID, message)
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+func extractChatID(update *tgbotapi.Update) int64 {
+	if update.Message != nil {
+		return update.Message.Chat.ID
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		return update.CallbackQuery.Message.Chat.ID
+	}
+	return 0
+}
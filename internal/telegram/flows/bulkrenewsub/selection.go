@@ -0,0 +1,45 @@
+package bulkrenewsub
+
+import "kurut-bot/internal/telegram/flows"
+
+// toggle добавляет подписку в выбор или убирает ее, если она уже выбрана
+func toggle(selected []int64, id int64) []int64 {
+	for i, selectedID := range selected {
+		if selectedID == id {
+			return append(selected[:i:i], selected[i+1:]...)
+		}
+	}
+	return append(selected, id)
+}
+
+// toggleAll выбирает все подписки списка, а если выбраны уже все - снимает выбор
+func toggleAll(candidates []flows.BulkRenewCandidate, selected []int64) []int64 {
+	if len(selected) == len(candidates) {
+		return nil
+	}
+	all := make([]int64, 0, len(candidates))
+	for _, c := range candidates {
+		all = append(all, c.SubscriptionID)
+	}
+	return all
+}
+
+func isSelected(selected []int64, id int64) bool {
+	for _, selectedID := range selected {
+		if selectedID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// selectedTotal возвращает сумму продления выбранных подписок
+func selectedTotal(candidates []flows.BulkRenewCandidate, selected []int64) float64 {
+	var total float64
+	for _, c := range candidates {
+		if isSelected(selected, c.SubscriptionID) {
+			total += c.Price
+		}
+	}
+	return total
+}
@@ -0,0 +1,44 @@
+package bulkrenewsub
+
+import (
+	"reflect"
+	"testing"
+
+	"kurut-bot/internal/telegram/flows"
+)
+
+func TestToggle(t *testing.T) {
+	selected := toggle(nil, 5)
+	selected = toggle(selected, 7)
+	if want := []int64{5, 7}; !reflect.DeepEqual(selected, want) {
+		t.Fatalf("toggle() = %v, want %v", selected, want)
+	}
+
+	selected = toggle(selected, 5)
+	if want := []int64{7}; !reflect.DeepEqual(selected, want) {
+		t.Fatalf("toggle() = %v, want %v", selected, want)
+	}
+}
+
+func TestToggleAllAndTotal(t *testing.T) {
+	candidates := []flows.BulkRenewCandidate{
+		{SubscriptionID: 1, Price: 300},
+		{SubscriptionID: 2, Price: 450},
+		{SubscriptionID: 3, Price: 250},
+	}
+
+	selected := toggleAll(candidates, []int64{2})
+	if want := []int64{1, 2, 3}; !reflect.DeepEqual(selected, want) {
+		t.Fatalf("toggleAll() = %v, want %v", selected, want)
+	}
+	if got := selectedTotal(candidates, selected); got != 1000 {
+		t.Errorf("selectedTotal() = %v, want 1000", got)
+	}
+
+	if selected = toggleAll(candidates, selected); len(selected) != 0 {
+		t.Fatalf("toggleAll() with everything selected = %v, want empty", selected)
+	}
+	if got := selectedTotal(candidates, []int64{3}); got != 250 {
+		t.Errorf("selectedTotal() = %v, want 250", got)
+	}
+}
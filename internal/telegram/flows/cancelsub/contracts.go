@@ -0,0 +1,33 @@
+package cancelsub
+
+import (
+	"context"
+
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type (
+	botApi interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+		Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	}
+
+	stateManager interface {
+		Clear(chatID int64)
+		GetCancelSubData(chatID int64) (*flows.CancelSubFlowData, error)
+		SetState(chatID int64, state states.State, data any)
+	}
+
+	subscriptionStorage interface {
+		GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error)
+		ListSubscriptionsPage(ctx context.Context, criteria subs.PageCriteria) ([]storage.SubscriptionDetails, error)
+		// CancelSubscription отменяет подписку, только если она еще активна, и освобождает место на сервере
+		CancelSubscription(ctx context.Context, subscriptionID int64) (bool, error)
+		DeactivateAllSubscriptionMessages(ctx context.Context, subscriptionID int64) error
+	}
+)
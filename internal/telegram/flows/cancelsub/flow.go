@@ -0,0 +1,344 @@
+package cancelsub
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// pageSize - сколько подписок показывать на одной странице выбора
+const pageSize = 10
+
+type Handler struct {
+	bot                 botApi
+	stateManager        stateManager
+	subscriptionStorage subscriptionStorage
+	logger              *slog.Logger
+}
+
+func NewHandler(
+	bot botApi,
+	sm stateManager,
+	subStorage subscriptionStorage,
+	logger *slog.Logger,
+) *Handler {
+	return &Handler{
+		bot:                 bot,
+		stateManager:        sm,
+		subscriptionStorage: subStorage,
+		logger:              logger,
+	}
+}
+
+// Start начинает flow отмены подписки: показывает активные подписки ассистента
+func (h *Handler) Start(ctx context.Context, assistantTelegramID, chatID int64, isAdmin bool) error {
+	flowData := &flows.CancelSubFlowData{
+		AssistantTelegramID: assistantTelegramID,
+		IsAdmin:             isAdmin,
+	}
+	h.stateManager.SetState(chatID, states.AssistantCancelSubWaitSelection, flowData)
+
+	return h.showSubscriptions(ctx, chatID, flowData, nil)
+}
+
+// Handle обрабатывает текущее состояние
+func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
+	ctx := context.Background()
+
+	switch state {
+	case states.AssistantCancelSubWaitSelection:
+		return h.handleSelection(ctx, update)
+	case states.AssistantCancelSubWaitConfirm:
+		return h.handleConfirm(ctx, update)
+	default:
+		return fmt.Errorf("unknown state: %s", state)
+	}
+}
+
+// showSubscriptions показывает страницу активных подписок ассистента
+func (h *Handler) showSubscriptions(ctx context.Context, chatID int64, flowData *flows.CancelSubFlowData, beforeID *int64) error {
+	page, err := h.subscriptionStorage.ListSubscriptionsPage(ctx, subs.PageCriteria{
+		CreatedByTelegramID: flowData.AssistantTelegramID,
+		Status:              []subs.Status{subs.StatusActive},
+		BeforeID:            beforeID,
+		Limit:               pageSize,
+	})
+	if err != nil {
+		h.logger.Error("Failed to list subscriptions for cancel", "error", err)
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ Ошибка загрузки подписок")
+	}
+
+	if len(page) == 0 && beforeID == nil && !flowData.IsAdmin {
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "У вас нет активных подписок")
+	}
+
+	text := "🗑 Выберите подписку для отмены"
+	if len(page) == 0 {
+		text = "Больше активных подписок нет"
+	}
+	if flowData.IsAdmin {
+		text += "\n\nИли отправьте номер подписки (ID), чтобы отменить чужую"
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, details := range page {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(subscriptionLabel(details), fmt.Sprintf("csub_pick:%d", details.Subscription.ID)),
+		))
+	}
+	if len(page) == pageSize {
+		lastID := page[len(page)-1].Subscription.ID
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➡️ Ещё", fmt.Sprintf("csub_more:%d", lastID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+	))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return h.show(chatID, flowData, states.AssistantCancelSubWaitSelection, text, "", &keyboard)
+}
+
+// subscriptionLabel - текст кнопки подписки: "#12 · 996555123456 · до 01.02.2026"
+func subscriptionLabel(details storage.SubscriptionDetails) string {
+	sub := details.Subscription
+	label := fmt.Sprintf("#%d", sub.ID)
+	if sub.ClientWhatsApp != nil {
+		label += " · " + *sub.ClientWhatsApp
+	}
+	if sub.ExpiresAt != nil {
+		label += " · до " + sub.ExpiresAt.Format("02.01.2006")
+	}
+	return label
+}
+
+// handleSelection обрабатывает выбор подписки кнопкой или вводом ID (для админа)
+func (h *Handler) handleSelection(ctx context.Context, update *tgbotapi.Update) error {
+	chatID := extractChatID(update)
+
+	flowData, err := h.stateManager.GetCancelSubData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	if update.CallbackQuery != nil {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+
+		action, id, ok := parseCallback(update.CallbackQuery.Data)
+		if !ok {
+			return nil
+		}
+		switch action {
+		case "csub_more":
+			return h.showSubscriptions(ctx, chatID, flowData, &id)
+		case "csub_pick":
+			return h.askConfirm(ctx, chatID, flowData, id)
+		}
+		return nil
+	}
+
+	if update.Message == nil || update.Message.Text == "" {
+		return h.sendError(chatID, "Выберите подписку из списка")
+	}
+
+	subID, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(update.Message.Text), "#"), 10, 64)
+	if err != nil {
+		return h.sendError(chatID, "❌ Отправьте номер подписки цифрами или выберите её из списка")
+	}
+	// Новое сообщение от пользователя - дальше отвечаем новым сообщением
+	flowData.MessageID = nil
+	return h.askConfirm(ctx, chatID, flowData, subID)
+}
+
+// askConfirm показывает подписку и просит подтвердить отмену
+func (h *Handler) askConfirm(ctx context.Context, chatID int64, flowData *flows.CancelSubFlowData, subID int64) error {
+	sub, err := h.loadSubscription(ctx, flowData, subID)
+	if err != nil {
+		return h.sendError(chatID, "❌ "+err.Error())
+	}
+
+	flowData.SubscriptionID = sub.ID
+
+	whatsapp := "—"
+	if sub.ClientWhatsApp != nil {
+		whatsapp = *sub.ClientWhatsApp
+	}
+	expires := "—"
+	if sub.ExpiresAt != nil {
+		expires = sub.ExpiresAt.Format("02.01.2006")
+	}
+
+	text := fmt.Sprintf("🗑 *Отмена подписки #%d*\n\n📱 WhatsApp: %s\n📅 До: %s\n\n"+
+		"Подписка перестанет действовать сразу, место на сервере освободится. Отменить подписку?",
+		sub.ID, whatsapp, expires)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Да, отменить подписку", "csub_confirm"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Нет, оставить", "cancel"),
+		),
+	)
+
+	return h.show(chatID, flowData, states.AssistantCancelSubWaitConfirm, text, "Markdown", &keyboard)
+}
+
+// loadSubscription загружает активную подписку; ассистент может отменять только свои
+func (h *Handler) loadSubscription(ctx context.Context, flowData *flows.CancelSubFlowData, subID int64) (*subs.Subscription, error) {
+	sub, err := h.subscriptionStorage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{subID}})
+	if err != nil {
+		h.logger.Error("Failed to get subscription for cancel", "error", err, "sub_id", subID)
+		return nil, fmt.Errorf("ошибка получения подписки")
+	}
+	if sub == nil {
+		return nil, fmt.Errorf("подписка #%d не найдена", subID)
+	}
+	if !flowData.IsAdmin && (sub.CreatedByTelegramID == nil || *sub.CreatedByTelegramID != flowData.AssistantTelegramID) {
+		return nil, fmt.Errorf("подписка #%d создана другим ассистентом", subID)
+	}
+	if sub.Status != subs.StatusActive {
+		return nil, fmt.Errorf("отменить можно только активную подписку")
+	}
+	return sub, nil
+}
+
+// handleConfirm отменяет подписку и освобождает место на сервере
+func (h *Handler) handleConfirm(ctx context.Context, update *tgbotapi.Update) error {
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Подтвердите или отмените действие кнопками")
+	}
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	if update.CallbackQuery.Data != "csub_confirm" {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		return nil
+	}
+
+	flowData, err := h.stateManager.GetCancelSubData(chatID)
+	if err != nil {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	// Подписка могла истечь, пока шло подтверждение
+	sub, err := h.loadSubscription(ctx, flowData, flowData.SubscriptionID)
+	if err != nil {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ "+err.Error())
+	}
+
+	// Статус меняется условно: повторное нажатие или параллельная отмена не освобождают место на сервере дважды
+	cancelled, err := h.subscriptionStorage.CancelSubscription(ctx, sub.ID)
+	if err != nil {
+		h.logger.Error("Failed to cancel subscription", "error", err, "sub_id", sub.ID)
+		_ = h.answerCallback(update.CallbackQuery.ID, "Ошибка")
+		return h.sendError(chatID, "❌ Ошибка отмены подписки")
+	}
+	if !cancelled {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ Подписка уже не активна")
+	}
+	_ = h.answerCallback(update.CallbackQuery.ID, "Подписка отменена")
+
+	var serverID int64
+	if sub.ServerID != nil {
+		serverID = *sub.ServerID
+	}
+
+	// Старые напоминания об оплате больше не должны продлевать отмененную подписку
+	if err := h.subscriptionStorage.DeactivateAllSubscriptionMessages(ctx, sub.ID); err != nil {
+		h.logger.Error("Failed to deactivate subscription messages", "error", err, "sub_id", sub.ID)
+	}
+
+	h.logger.Info("Subscription cancelled",
+		"audit", true,
+		"assistant_telegram_id", flowData.AssistantTelegramID,
+		"sub_id", sub.ID,
+		"server_id", serverID,
+	)
+
+	text := fmt.Sprintf("✅ Подписка #%d отменена\n\nНе забудьте отключить клиента в панели сервера.", sub.ID)
+
+	h.stateManager.Clear(chatID)
+	if flowData.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+	_, err = h.bot.Send(tgbotapi.NewMessage(chatID, text))
+	return err
+}
+
+// show редактирует сообщение флоу или отправляет новое и переводит флоу в состояние state
+func (h *Handler) show(chatID int64, flowData *flows.CancelSubFlowData, state states.State, text, parseMode string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	h.stateManager.SetState(chatID, state, flowData)
+
+	if flowData.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
+		editMsg.ParseMode = parseMode
+		editMsg.ReplyMarkup = keyboard
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = parseMode
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	sentMsg, err := h.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+	flowData.MessageID = &sentMsg.MessageID
+	return nil
+}
+
+// parseCallback разбирает "action:id"
+func parseCallback(data string) (string, int64, bool) {
+	action, rawID, ok := strings.Cut(data, ":")
+	if !ok {
+		return "", 0, false
+	}
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return action, id, true
+}
+
+func (h *Handler) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := h.bot.Request(callback)
+	return err
+}
+
+func (h *Handler) sendError(chatID int64, message string) error {
+	msg := tgbotapi.NewMessage(chatID, message)
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+func extractChatID(update *tgbotapi.Update) int64 {
+	if update.Message != nil {
+		return update.Message.Chat.ID
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		return update.CallbackQuery.Message.Chat.ID
+	}
+	return 0
+}
@@ -0,0 +1,66 @@
+package createsubforclient
+
+import (
+	"fmt"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleBack возвращает на предыдущий шаг: к вводу номера, вопросу о пригласившем или списку тарифов
+func (h *Handler) handleBack(update *tgbotapi.Update) error {
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	state, ok := h.stateManager.Back(chatID)
+	if !ok {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Это первый шаг"))
+		return nil
+	}
+	_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+	// Убираем кнопки со старого шага, чтобы нажатие на них не попало в другой шаг
+	_ = telegram.SafeEdit(h.bot, tgbotapi.NewEditMessageReplyMarkup(chatID, update.CallbackQuery.Message.MessageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}), "")
+
+	flowData, err := h.stateManager.GetCreateSubForClientData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	switch state {
+	case states.AdminCreateSubWaitClientName:
+		// Выбранный раньше тариф сбросить: иначе после ввода номера флоу примет его за подарочный
+		resetTariff(flowData)
+		return h.showWhatsAppInput(chatID, flowData)
+	case states.AdminCreateSubWaitReferrer:
+		resetTariff(flowData)
+		flowData.ReferrerWhatsApp = nil
+		flowData.ReferrerSubscriptionID = nil
+		return h.showReferrerQuestion(chatID)
+	case states.AdminCreateSubWaitTariff:
+		return h.showTariffs(chatID)
+	default:
+		return fmt.Errorf("no back step for create sub state: %s", state)
+	}
+}
+
+// showWhatsAppInput повторяет запрос номера клиента
+func (h *Handler) showWhatsAppInput(chatID int64, flowData *flows.CreateSubForClientFlowData) error {
+	text := "📱 Введите номер WhatsApp клиента (например: +996555123456):"
+	if flowData.ReferrerSubscriptionID != nil && flowData.ReferrerWhatsApp != nil {
+		text = "👥 Клиент по приглашению от " + *flowData.ReferrerWhatsApp + "\n\n" + text
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+func resetTariff(flowData *flows.CreateSubForClientFlowData) {
+	flowData.TariffID = 0
+	flowData.TariffName = ""
+	flowData.Price = 0
+	flowData.TotalAmount = 0
+}
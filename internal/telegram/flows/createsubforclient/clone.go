@@ -0,0 +1,109 @@
+package createsubforclient
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// HandleCloneCallback обрабатывает clone_sub:subID - создание новой подписки по образцу
+// истекшей или отключенной: тот же WhatsApp, тот же тариф и, если есть место, тот же сервер.
+// Ассистент клонирует только свои подписки, админ - любые
+func (h *Handler) HandleCloneCallback(ctx context.Context, userID, assistantTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	chatID := callbackQuery.Message.Chat.ID
+
+	parts := strings.Split(callbackQuery.Data, ":")
+	if len(parts) != 2 {
+		return h.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+	subID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return h.answerCallback(callbackQuery.ID, "Неверный ID подписки")
+	}
+
+	sub, err := h.subscriptionStorage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{subID}})
+	if err != nil || sub == nil {
+		h.logger.Error("Failed to get subscription for clone", "error", err, "sub_id", subID)
+		return h.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	if !isAdmin && (sub.CreatedByTelegramID == nil || *sub.CreatedByTelegramID != assistantTelegramID) {
+		return h.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	if sub.ClientWhatsApp == nil || *sub.ClientWhatsApp == "" {
+		return h.answerCallback(callbackQuery.ID, "У подписки не указан WhatsApp клиента")
+	}
+
+	_ = h.answerCallback(callbackQuery.ID, "")
+
+	flowData := &flows.CreateSubForClientFlowData{
+		AdminUserID:         userID,
+		AssistantTelegramID: assistantTelegramID,
+		ClientWhatsApp:      *sub.ClientWhatsApp,
+	}
+
+	if server := h.suggestServer(ctx, sub.ServerID); server != nil {
+		flowData.ServerID = &server.ID
+		flowData.ServerName = &server.Name
+	}
+
+	tariffsList, err := h.tariffService.GetActiveTariffs(ctx)
+	if err != nil {
+		h.logger.Error("Failed to get tariffs for clone", "error", err)
+		return h.sendError(chatID, "❌ Ошибка получения тарифов")
+	}
+
+	var tariff *tariffs.Tariff
+	for _, t := range tariffsList {
+		if t.ID == sub.TariffID {
+			tariff = t
+			break
+		}
+	}
+
+	// Прежний тариф больше не продается - даем выбрать из актуальных
+	if tariff == nil {
+		h.stateManager.SetState(chatID, states.AdminCreateSubWaitTariff, flowData)
+		return h.showTariffs(chatID)
+	}
+
+	flowData.TariffID = tariff.ID
+	flowData.TariffName = tariff.Name
+	flowData.Price = tariff.Price
+	flowData.TotalAmount = tariff.Price
+
+	return h.showQuickConfirm(ctx, chatID, flowData, tariff, "📄 *Новая подписка по образцу*")
+}
+
+// suggestServer возвращает прежний сервер клиента, если он не в архиве и на нем есть место
+func (h *Handler) suggestServer(ctx context.Context, serverID *int64) *servers.Server {
+	if serverID == nil {
+		return nil
+	}
+
+	archived := false
+	server, err := h.serverStorage.GetServer(ctx, servers.GetCriteria{ID: serverID, Archived: &archived})
+	if err != nil || server == nil {
+		return nil
+	}
+
+	activeCount, err := h.serverStorage.GetActiveUsersCountByServer(ctx, server.ID)
+	if err != nil || activeCount >= server.MaxUsers {
+		return nil
+	}
+
+	return server
+}
+
+func (h *Handler) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := h.bot.Request(callback)
+	return err
+}
@@ -0,0 +1,110 @@
+package createsubforclient
+
+import (
+	"context"
+	"fmt"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// showOrderConfirm показывает итог заказа после выбора тарифа: платеж создается только по кнопке подтверждения.
+// Редактирует сообщение со списком тарифов, если оно есть
+func (h *Handler) showOrderConfirm(chatID int64, flowData *flows.CreateSubForClientFlowData) error {
+	text := formatOrderSummary(flowData)
+	keyboard := orderConfirmKeyboard(flowData.Price == 0)
+
+	h.stateManager.SetState(chatID, states.AdminCreateSubWaitConfirm, flowData)
+
+	if flowData.MessageID != nil {
+		edit := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
+		edit.ReplyMarkup = &keyboard
+		return telegram.SafeEdit(h.bot, edit, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	sentMsg, err := h.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+	flowData.MessageID = &sentMsg.MessageID
+	return nil
+}
+
+// handleOrderConfirm обрабатывает кнопки итога заказа: подтвердить, изменить тариф или отменить
+func (h *Handler) handleOrderConfirm(ctx context.Context, update *tgbotapi.Update) error {
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Подтвердите заказ, измените тариф или отмените кнопками")
+	}
+
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	flowData, err := h.stateManager.GetCreateSubForClientData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	switch update.CallbackQuery.Data {
+	case "cancel":
+		return h.handleCancel(ctx, update)
+
+	case "acs_edit":
+		_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+		// Убираем кнопки со старой карточки: список тарифов придет новым сообщением
+		_ = telegram.SafeEdit(h.bot, tgbotapi.NewEditMessageReplyMarkup(chatID, update.CallbackQuery.Message.MessageID,
+			tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}), "")
+		h.stateManager.SetState(chatID, states.AdminCreateSubWaitTariff, flowData)
+		return h.showTariffs(chatID)
+
+	case "acs_confirm":
+		_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Создаём заказ..."))
+
+		// Если тариф бесплатный - сразу создаем подписку без оплаты
+		if flowData.Price == 0 {
+			return h.createFreeSubscription(ctx, chatID, flowData)
+		}
+
+		h.stateManager.SetState(chatID, states.AdminCreateSubWaitPayment, flowData)
+		return h.createPaymentAndShow(ctx, chatID, flowData)
+
+	default:
+		return nil
+	}
+}
+
+// formatOrderSummary - карточка заказа перед созданием платежа
+func formatOrderSummary(flowData *flows.CreateSubForClientFlowData) string {
+	serverText := "будет выбран автоматически"
+	if flowData.ServerName != nil {
+		serverText = *flowData.ServerName
+	}
+
+	text := fmt.Sprintf(
+		"🧾 Проверьте заказ\n\n"+
+			"📱 Клиент: %s\n"+
+			"📅 Тариф: %s\n"+
+			"💰 Сумма: %s\n"+
+			"🖥 Сервер: %s",
+		flowData.ClientWhatsApp, flowData.TariffName,
+		formatAmount(flowData.TotalAmount, baseAmount(flowData.Price, flowData.TotalAmount)), serverText)
+	if flowData.ReferrerWhatsApp != nil {
+		text += "\n👥 Пригласил: " + *flowData.ReferrerWhatsApp
+	}
+	return text
+}
+
+func orderConfirmKeyboard(free bool) tgbotapi.InlineKeyboardMarkup {
+	confirmText := "✅ Подтвердить и создать платеж"
+	if free {
+		confirmText = "✅ Подтвердить и создать подписку"
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(confirmText, "acs_confirm")),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("✏️ Изменить тариф", "acs_edit")),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel")),
+	)
+}
@@ -0,0 +1,42 @@
+package createsubforclient
+
+import (
+	"strings"
+	"testing"
+
+	"kurut-bot/internal/telegram/flows"
+)
+
+func TestFormatOrderSummary(t *testing.T) {
+	server := "de-1"
+	referrer := "996555000111"
+	text := formatOrderSummary(&flows.CreateSubForClientFlowData{
+		ClientWhatsApp:   "996555123456",
+		TariffName:       "3 месяца",
+		Price:            350,
+		TotalAmount:      450,
+		ServerName:       &server,
+		ReferrerWhatsApp: &referrer,
+	})
+
+	for _, want := range []string{
+		"📱 Клиент: 996555123456",
+		"📅 Тариф: 3 месяца",
+		"💰 Сумма: 450.00 ₽ (тариф 350.00 ₽ + сервер 100.00 ₽)",
+		"🖥 Сервер: de-1",
+		"👥 Пригласил: 996555000111",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("summary %q does not contain %q", text, want)
+		}
+	}
+}
+
+func TestOrderConfirmKeyboard(t *testing.T) {
+	if got := orderConfirmKeyboard(true).InlineKeyboard[0][0].Text; !strings.Contains(got, "подписку") {
+		t.Errorf("free order confirm button = %q", got)
+	}
+	if got := orderConfirmKeyboard(false).InlineKeyboard[0][0].Text; !strings.Contains(got, "платеж") {
+		t.Errorf("paid order confirm button = %q", got)
+	}
+}
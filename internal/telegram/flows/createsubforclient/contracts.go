@@ -5,10 +5,15 @@ import (
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/flowcancel"
 	"kurut-bot/internal/stories/orders"
 	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/shortlinks"
 	"kurut-bot/internal/stories/subs"
 	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/stories/waitlist"
 	"kurut-bot/internal/telegram/flows"
 	"kurut-bot/internal/telegram/states"
 )
@@ -17,15 +22,19 @@ type (
 	botApi interface {
 		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
 		Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+		GetChat(config tgbotapi.ChatInfoConfig) (tgbotapi.Chat, error)
 	}
 
 	stateManager interface {
 		Clear(chatID int64)
+		GetState(chatID int64) states.State
 		GetCreateSubForClientData(chatID int64) (*flows.CreateSubForClientFlowData, error)
 		SetState(chatID int64, state states.State, data any)
+		Back(chatID int64) (states.State, bool)
 	}
 
 	tariffService interface {
+		GetTariff(ctx context.Context, criteria tariffs.GetCriteria) (*tariffs.Tariff, error)
 		GetActiveTariffs(ctx context.Context) ([]*tariffs.Tariff, error)
 		GetTrialTariff(ctx context.Context) (*tariffs.Tariff, error)
 	}
@@ -37,11 +46,30 @@ type (
 
 	subscriptionStorage interface {
 		HasUsedTrialByPhone(ctx context.Context, phoneNumber string) (bool, error)
+		GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error)
+		GetClientLanguage(ctx context.Context, clientWhatsApp string) (clientlang.Language, error)
+		GetLinkClickStats(ctx context.Context, criteria shortlinks.ClickCriteria) (*shortlinks.ClickStats, error)
+	}
+
+	serverStorage interface {
+		ListServers(ctx context.Context, criteria servers.ListCriteria) ([]*servers.Server, error)
+		GetServer(ctx context.Context, criteria servers.GetCriteria) (*servers.Server, error)
+		GetActiveUsersCountByServer(ctx context.Context, serverID int64) (int, error)
+		GetAvailableServer(ctx context.Context, cluster string) (*servers.Server, error)
+	}
+
+	waitlistStorage interface {
+		CreateWaitlistEntry(ctx context.Context, entry waitlist.Entry) (*waitlist.Entry, error)
+		GetWaitlistEntry(ctx context.Context, id int64) (*waitlist.Entry, error)
+		ListWaitlistEntries(ctx context.Context, criteria waitlist.ListCriteria) ([]*waitlist.Entry, error)
+		UpdateWaitlistEntryStatus(ctx context.Context, id int64, status waitlist.Status) error
 	}
 
 	paymentService interface {
-		CreatePayment(ctx context.Context, paymentEntity payment.Payment) (*payment.Payment, error)
+		CreatePaymentWithMeta(ctx context.Context, paymentEntity payment.Payment, meta payment.CreatePaymentMeta) (*payment.Payment, error)
+		CreateReceiptPayment(ctx context.Context, paymentEntity payment.Payment) (*payment.Payment, error)
 		CheckPaymentStatus(ctx context.Context, paymentID int64) (*payment.Payment, error)
+		CancelPayment(ctx context.Context, paymentID int64) (*payment.Payment, error)
 		IsManualPayment() bool
 	}
 
@@ -50,7 +78,21 @@ type (
 		GetPendingOrderByID(ctx context.Context, id int64) (*orders.PendingOrder, error)
 		UpdateMessageID(ctx context.Context, id int64, messageID int) error
 		UpdatePaymentID(ctx context.Context, id int64, paymentID int64) error
+		ClaimLinkRefresh(ctx context.Context, id int64) (bool, error)
 		UpdateStatus(ctx context.Context, id int64, status orders.Status) error
+		AttachReceipt(ctx context.Context, id int64, fileID string) (bool, error)
+		ClaimReceiptReview(ctx context.Context, id int64, reviewerTelegramID int64, status orders.Status) (bool, error)
+		ListPending(ctx context.Context) ([]*orders.PendingOrder, error)
+		ListPendingByAssistant(ctx context.Context, assistantTelegramID int64, limit int) ([]*orders.PendingOrder, error)
 		DeletePendingOrder(ctx context.Context, id int64) error
 	}
+
+	cancelSurvey interface {
+		Ask(ctx context.Context, telegramID, chatID int64, flow flowcancel.Flow, state string)
+	}
+
+	// adminChatsProvider - куда пересылать чеки: админская группа или текущие админы
+	adminChatsProvider interface {
+		AdminChatIDs() []int64
+	}
 )
@@ -8,12 +8,22 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/bonusrules"
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/devices"
+	"kurut-bot/internal/stories/flowcancel"
 	"kurut-bot/internal/stories/orders"
 	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/shortlinks"
 	"kurut-bot/internal/stories/subs"
 	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/stories/watemplates"
 	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/messages"
 	"kurut-bot/internal/telegram/states"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -27,6 +37,10 @@ type Handler struct {
 	subscriptionStorage subscriptionStorage
 	paymentService      paymentService
 	orderService        orderService
+	serverStorage       serverStorage
+	waitlistStorage     waitlistStorage
+	cancelSurvey        cancelSurvey
+	adminChats          adminChatsProvider // куда пересылать чеки на подтверждение в ручном режиме оплаты
 	logger              *slog.Logger
 }
 
@@ -38,6 +52,10 @@ func NewHandler(
 	storage subscriptionStorage,
 	ps paymentService,
 	os orderService,
+	srv serverStorage,
+	wl waitlistStorage,
+	cs cancelSurvey,
+	adminChats adminChatsProvider,
 	logger *slog.Logger,
 ) *Handler {
 	return &Handler{
@@ -48,6 +66,10 @@ func NewHandler(
 		subscriptionStorage: storage,
 		paymentService:      ps,
 		orderService:        os,
+		serverStorage:       srv,
+		waitlistStorage:     wl,
+		cancelSurvey:        cs,
+		adminChats:          adminChats,
 		logger:              logger,
 	}
 }
@@ -70,6 +92,10 @@ func (h *Handler) Start(userID, assistantTelegramID, chatID int64) error {
 func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
 	ctx := context.Background()
 
+	if update.CallbackQuery != nil && update.CallbackQuery.Data == flows.BackCallback {
+		return h.handleBack(update)
+	}
+
 	switch state {
 	case states.AdminCreateSubWaitClientName:
 		return h.handleWhatsAppInput(ctx, update)
@@ -77,8 +103,16 @@ func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
 		return h.handleReferrerInput(ctx, update)
 	case states.AdminCreateSubWaitTariff:
 		return h.handleTariffSelection(ctx, update)
+	case states.AdminCreateSubWaitConfirm:
+		return h.handleOrderConfirm(ctx, update)
 	case states.AdminCreateSubWaitPayment:
 		return h.handlePaymentConfirmation(ctx, update)
+	case states.AdminCreateSubWaitQuickConfirm:
+		return h.handleQuickConfirm(ctx, update)
+	case states.AdminCreateSubWaitWaitlist:
+		return h.handleWaitlistChoice(ctx, update)
+	case states.AdminCreateSubWaitReceipt:
+		return h.handleReceiptUpload(ctx, update)
 	default:
 		return fmt.Errorf("unknown state: %s", state)
 	}
@@ -122,6 +156,20 @@ func (h *Handler) handleWhatsAppInput(ctx context.Context, update *tgbotapi.Upda
 		}
 	}
 
+	// Тариф уже выбран подарочной ссылкой - сразу к подтверждению
+	if flowData.TariffID != 0 {
+		return h.showGiftConfirm(ctx, chatID, flowData)
+	}
+
+	// Пригласивший уже известен из реферальной ссылки - сразу к тарифам
+	if flowData.ReferrerSubscriptionID != nil {
+		if flowData.ReferrerWhatsApp != nil && NormalizePhone(*flowData.ReferrerWhatsApp) == whatsapp {
+			return h.sendError(chatID, "❌ Это номер пригласившего. Введите номер нового клиента")
+		}
+		h.stateManager.SetState(chatID, states.AdminCreateSubWaitTariff, flowData)
+		return h.showTariffs(chatID)
+	}
+
 	// Переводим в состояние ввода реферала
 	h.stateManager.SetState(chatID, states.AdminCreateSubWaitReferrer, flowData)
 
@@ -132,7 +180,7 @@ func (h *Handler) handleWhatsAppInput(ctx context.Context, update *tgbotapi.Upda
 // showReferrerQuestion показывает вопрос о реферале
 func (h *Handler) showReferrerQuestion(chatID int64) error {
 	text := "👥 Есть номер того, кто пригласил клиента?\n\n" +
-		"Бонус +10 дней при первой оплате!"
+		"Пригласившему начисляется бонус при первой оплате!"
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
@@ -142,6 +190,7 @@ func (h *Handler) showReferrerQuestion(chatID int64) error {
 			tgbotapi.NewInlineKeyboardButtonData("❌ Нет", "ref_no"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
+			flows.BackButton(),
 			tgbotapi.NewInlineKeyboardButtonData("◀️ Отменить", "cancel"),
 		),
 	)
@@ -185,7 +234,7 @@ func (h *Handler) handleReferrerInput(ctx context.Context, update *tgbotapi.Upda
 			if flowData.MessageID != nil {
 				editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID,
 					"📱 Введите номер WhatsApp того, кто пригласил (например: +996555123456):")
-				_, _ = h.bot.Send(editMsg)
+				_ = telegram.SafeEdit(h.bot, editMsg, "")
 			} else {
 				msg := tgbotapi.NewMessage(chatID, "📱 Введите номер WhatsApp того, кто пригласил (например: +996555123456):")
 				_, _ = h.bot.Send(msg)
@@ -211,7 +260,7 @@ func (h *Handler) handleReferrerInput(ctx context.Context, update *tgbotapi.Upda
 			if flowData.MessageID != nil {
 				editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID,
 					"📱 Введите номер WhatsApp того, кто пригласил (например: +996555123456):")
-				_, _ = h.bot.Send(editMsg)
+				_ = telegram.SafeEdit(h.bot, editMsg, "")
 			}
 			return nil
 
@@ -283,8 +332,7 @@ func (h *Handler) sendReferrerError(chatID int64, flowData *flows.CreateSubForCl
 	if flowData.MessageID != nil {
 		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, errorMsg)
 		editMsg.ReplyMarkup = &keyboard
-		_, err := h.bot.Send(editMsg)
-		return err
+		return telegram.SafeEdit(h.bot, editMsg, "")
 	}
 
 	msg := tgbotapi.NewMessage(chatID, errorMsg)
@@ -345,10 +393,21 @@ func (h *Handler) showTariffs(chatID int64) error {
 		return err
 	}
 
+	// Цены зависят от сервера, поэтому сервер закрепляется за заказом до выбора тарифа
+	var server *servers.Server
+	if flowData != nil {
+		server = h.resolveServer(ctx, flowData)
+	}
+
 	// Создаем клавиатуру с тарифами
-	keyboard := h.createTariffsKeyboard(tariffsList)
+	keyboard := h.createTariffsKeyboard(tariffsList, server, flowData != nil && flowData.HasSteps())
+
+	text := "📅 Выберите тариф:"
+	if server.HasPriceModifier() {
+		text += fmt.Sprintf("\n\n🖥 Сервер: %s (%s)", server.Name, server.PriceModifierText())
+	}
 
-	msg := tgbotapi.NewMessage(chatID, "📅 Выберите тариф:")
+	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ReplyMarkup = keyboard
 
 	sentMsg, err := h.bot.Send(msg)
@@ -394,25 +453,22 @@ func (h *Handler) handleTariffSelection(ctx context.Context, update *tgbotapi.Up
 	flowData.TariffID = tariffData.ID
 	flowData.TariffName = tariffData.Name
 	flowData.Price = tariffData.Price
-	flowData.TotalAmount = tariffData.Price
+	applyServerPrice(flowData, h.resolveServer(ctx, flowData))
 
 	// Отвечаем на callback query
-	callbackConfig := tgbotapi.NewCallback(update.CallbackQuery.ID, "Создаём заказ...")
+	callbackConfig := tgbotapi.NewCallback(update.CallbackQuery.ID, "")
 	_, err = h.bot.Request(callbackConfig)
 	if err != nil {
 		return err
 	}
 
-	// Если тариф бесплатный - сразу создаем подписку без оплаты
-	if tariffData.Price == 0 {
-		return h.createFreeSubscription(ctx, chatID, flowData)
+	// Все серверы заполнены - предлагаем очередь вместо заказа, который не на что будет создать
+	if flowData.ServerID == nil {
+		return h.offerWaitlist(chatID, flowData)
 	}
 
-	// Переводим в состояние ожидания оплаты
-	h.stateManager.SetState(chatID, states.AdminCreateSubWaitPayment, flowData)
-
-	// Сразу создаём платёж и показываем ссылку на оплату
-	return h.createPaymentAndShow(ctx, chatID, flowData)
+	// Платеж создается только после подтверждения, чтобы случайное нажатие на тариф ничего не стоило
+	return h.showOrderConfirm(chatID, flowData)
 }
 
 // handlePaymentConfirmation обработка подтверждения оплаты
@@ -460,14 +516,24 @@ func (h *Handler) handleRefreshPaymentLink(ctx context.Context, update *tgbotapi
 
 // createPaymentAndShow создает платеж и сразу показывает ссылку на оплату
 func (h *Handler) createPaymentAndShow(ctx context.Context, chatID int64, data *flows.CreateSubForClientFlowData) error {
+	// Ручной режим: вместо ссылки ассистент присылает чек, подписка создается после подтверждения админом
+	if h.paymentService.IsManualPayment() {
+		return h.createReceiptOrder(ctx, chatID, data)
+	}
+
 	// Создаем платеж
 	paymentEntity := payment.Payment{
-		UserID: data.AdminUserID,
-		Amount: data.TotalAmount,
-		Status: payment.StatusPending,
+		UserID:     data.AdminUserID,
+		Amount:     data.TotalAmount,
+		Status:     payment.StatusPending,
+		BaseAmount: baseAmount(data.Price, data.TotalAmount),
+		ServerID:   data.ServerID,
 	}
 
-	paymentObj, err := h.paymentService.CreatePayment(ctx, paymentEntity)
+	paymentObj, err := h.paymentService.CreatePaymentWithMeta(ctx, paymentEntity, payment.CreatePaymentMeta{
+		TariffName:     data.TariffName,
+		ClientWhatsApp: data.ClientWhatsApp,
+	})
 	if err != nil {
 		h.logger.Error("Failed to create payment",
 			"error", err,
@@ -496,8 +562,10 @@ func (h *Handler) createPaymentAndShow(ctx context.Context, chatID int64, data *
 		TariffID:               data.TariffID,
 		TariffName:             data.TariffName,
 		TotalAmount:            data.TotalAmount,
+		BaseAmount:             paymentEntity.BaseAmount,
 		ReferrerWhatsApp:       data.ReferrerWhatsApp,
 		ReferrerSubscriptionID: data.ReferrerSubscriptionID,
+		TargetServerID:         data.ServerID,
 	}
 
 	createdOrder, err := h.orderService.CreatePendingOrder(ctx, pendingOrder)
@@ -511,9 +579,9 @@ func (h *Handler) createPaymentAndShow(ctx context.Context, chatID int64, data *
 		"💳 Заказ создан!\n\n"+
 			"📱 Клиент: %s\n"+
 			"📅 Тариф: %s\n"+
-			"💰 Сумма: %.2f ₽\n\n"+
+			"💰 Сумма: %s\n\n"+
 			"🔗 Ссылка на оплату: [link](%s)\n\n",
-		data.ClientWhatsApp, data.TariffName, data.TotalAmount, *paymentObj.PaymentURL)
+		data.ClientWhatsApp, data.TariffName, formatAmount(data.TotalAmount, paymentEntity.BaseAmount), *paymentObj.PaymentURL)
 
 	// Создаем кнопки с orderID для независимой работы каждого заказа
 	checkButton := tgbotapi.NewInlineKeyboardButtonData("🔄 Проверить оплату", fmt.Sprintf("pay_check:%d", createdOrder.ID))
@@ -524,6 +592,7 @@ func (h *Handler) createPaymentAndShow(ctx context.Context, chatID int64, data *
 		tgbotapi.NewInlineKeyboardRow(checkButton),
 		tgbotapi.NewInlineKeyboardRow(refreshButton),
 		tgbotapi.NewInlineKeyboardRow(cancelButton),
+		tgbotapi.NewInlineKeyboardRow(escalationButton(createdOrder.ID)),
 	)
 
 	// Редактируем существующее сообщение, если MessageID есть
@@ -532,7 +601,7 @@ func (h *Handler) createPaymentAndShow(ctx context.Context, chatID int64, data *
 		editMsg := tgbotapi.NewEditMessageText(chatID, *data.MessageID, paymentMsg)
 		editMsg.ParseMode = "Markdown"
 		editMsg.ReplyMarkup = &keyboard
-		_, err = h.bot.Send(editMsg)
+		err = telegram.SafeEdit(h.bot, editMsg, "")
 		if err != nil {
 			return err
 		}
@@ -565,6 +634,7 @@ func (h *Handler) createPaymentAndShow(ctx context.Context, chatID int64, data *
 func (h *Handler) handleCancel(ctx context.Context, update *tgbotapi.Update) error {
 	chatID := update.CallbackQuery.Message.Chat.ID
 
+	state := h.stateManager.GetState(chatID)
 	h.stateManager.Clear(chatID)
 
 	// Отвечаем на callback query
@@ -575,7 +645,15 @@ func (h *Handler) handleCancel(ctx context.Context, update *tgbotapi.Update) err
 	}
 
 	// Отправляем главное меню
-	return h.sendMainMenu(chatID)
+	if err := h.sendMainMenu(chatID); err != nil {
+		return err
+	}
+
+	// Отмену из шага флоу учитываем в статистике причин; кнопки вне флоу (например, в листе ожидания) - нет
+	if state != states.StateNone {
+		h.cancelSurvey.Ask(ctx, update.CallbackQuery.From.ID, chatID, flowcancel.FlowCreateSub, string(state))
+	}
+	return nil
 }
 
 // sendMainMenu отправляет главное меню
@@ -589,21 +667,26 @@ func (h *Handler) sendMainMenu(chatID int64) error {
 	return err
 }
 
-func (h *Handler) createTariffsKeyboard(tariffList []*tariffs.Tariff) tgbotapi.InlineKeyboardMarkup {
+// createTariffsKeyboard показывает цены с наценкой сервера; в callback остается цена тарифа
+func (h *Handler) createTariffsKeyboard(tariffList []*tariffs.Tariff, server *servers.Server, back bool) tgbotapi.InlineKeyboardMarkup {
 	var rows [][]tgbotapi.InlineKeyboardButton
 
 	for _, t := range tariffList {
 		durationText := formatDuration(t.DurationDays)
-		text := fmt.Sprintf("📅 %s - %.2f ₽ (%s)", t.Name, t.Price, durationText)
+		text := fmt.Sprintf("📅 %s - %.2f ₽ (%s)", t.Name, server.EffectivePrice(t.Price), durationText)
 		callbackData := fmt.Sprintf("tariff:%d:%.2f:%s:%d", t.ID, t.Price, t.Name, t.DurationDays)
 		button := tgbotapi.NewInlineKeyboardButtonData(text, callbackData)
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{button})
 	}
 
-	// Добавляем кнопку отмены
-	rows = append(rows, []tgbotapi.InlineKeyboardButton{
+	// Добавляем кнопку отмены, а если до тарифа были другие шаги - и возврата к ним
+	controlRow := []tgbotapi.InlineKeyboardButton{
 		tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
-	})
+	}
+	if back {
+		controlRow = append([]tgbotapi.InlineKeyboardButton{flows.BackButton()}, controlRow...)
+	}
+	rows = append(rows, controlRow)
 
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
@@ -659,7 +742,8 @@ func (h *Handler) handlePaymentCompleted(ctx context.Context, update *tgbotapi.U
 		return h.handleSuccessfulPayment(ctx, chatID, data, *data.PaymentID)
 	case payment.StatusPending:
 		// Платеж еще обрабатывается - показываем всплывающее уведомление
-		alertConfig := tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, "⏳ Платеж еще обрабатывается.\nПожалуйста, подождите и попробуйте еще раз.")
+		alertText := "⏳ Платеж еще обрабатывается.\nПожалуйста, подождите и попробуйте еще раз." + h.clickSummary(ctx, shortlinks.ClickCriteria{PaymentID: data.PaymentID})
+		alertConfig := tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, alertText)
 		_, _ = h.bot.Request(alertConfig)
 		return nil
 	case payment.StatusRejected, payment.StatusCancelled:
@@ -670,6 +754,16 @@ func (h *Handler) handlePaymentCompleted(ctx context.Context, update *tgbotapi.U
 	}
 }
 
+// clickSummary - строка для ассистента о переходах клиента по ссылке на оплату (пусто при ошибке)
+func (h *Handler) clickSummary(ctx context.Context, criteria shortlinks.ClickCriteria) string {
+	stats, err := h.subscriptionStorage.GetLinkClickStats(ctx, criteria)
+	if err != nil {
+		h.logger.Warn("Failed to get link click stats", "error", err)
+		return ""
+	}
+	return "\n\n" + stats.Summary(time.Now())
+}
+
 // sendPaymentCheckError отправляет сообщение об ошибке проверки с возможностью повторить
 func (h *Handler) sendPaymentCheckError(chatID int64, data *flows.CreateSubForClientFlowData, errorMsg string) error {
 	retryButton := tgbotapi.NewInlineKeyboardButtonData("🔄 Попробовать еще раз", "payment_completed")
@@ -684,8 +778,7 @@ func (h *Handler) sendPaymentCheckError(chatID int64, data *flows.CreateSubForCl
 	if data.MessageID != nil {
 		editMsg := tgbotapi.NewEditMessageText(chatID, *data.MessageID, errorMsg)
 		editMsg.ReplyMarkup = &keyboard
-		_, err := h.bot.Send(editMsg)
-		return err
+		return telegram.SafeEdit(h.bot, editMsg, "")
 	}
 
 	// Fallback: отправляем новое сообщение
@@ -708,6 +801,8 @@ func (h *Handler) handleSuccessfulPayment(ctx context.Context, chatID int64, dat
 		ClientWhatsApp:         data.ClientWhatsApp,
 		CreatedByTelegramID:    data.AssistantTelegramID,
 		ReferrerSubscriptionID: data.ReferrerSubscriptionID,
+		ServerID:               data.ServerID,
+		Channel:                bonusrules.ChannelAssistant,
 	}
 
 	result, err := h.subscriptionService.CreateSubscription(ctx, subReq)
@@ -717,11 +812,23 @@ func (h *Handler) handleSuccessfulPayment(ctx context.Context, chatID int64, dat
 	}
 
 	// Отправляем информацию о созданной подписке
-	return h.sendSubscriptionCreated(chatID, result, data)
+	return h.sendSubscriptionCreated(ctx, chatID, result, data)
+}
+
+// bonusLines описывает бонусы клиенту и сработавшие правила с уведомлениями
+func bonusLines(result *subs.CreateSubscriptionResult) string {
+	var b strings.Builder
+	if result.ClientBonusDays > 0 {
+		b.WriteString(fmt.Sprintf("\n\n🎁 *+%d дней бонуса* клиенту", result.ClientBonusDays))
+	}
+	for _, text := range result.BonusNotifications {
+		b.WriteString("\n📣 " + text)
+	}
+	return b.String()
 }
 
 // sendSubscriptionCreated отправляет сообщение об успешном создании подписки
-func (h *Handler) sendSubscriptionCreated(chatID int64, result *subs.CreateSubscriptionResult, data *flows.CreateSubForClientFlowData) error {
+func (h *Handler) sendSubscriptionCreated(ctx context.Context, chatID int64, result *subs.CreateSubscriptionResult, data *flows.CreateSubForClientFlowData) error {
 	// Формируем пароль если есть
 	passwordLine := ""
 	if result.ServerUIPassword != nil && *result.ServerUIPassword != "" {
@@ -736,10 +843,12 @@ func (h *Handler) sendSubscriptionCreated(chatID int64, result *subs.CreateSubsc
 			referralExpiresLine = fmt.Sprintf("\nПодписка до: %s",
 				result.ReferrerNewExpiresAt.Format("02.01.2006"))
 		}
-		referralLine = fmt.Sprintf("\n\n🎁 *+10 дней бонуса* пригласившему `%s`%s",
+		referralLine = fmt.Sprintf("\n\n🎁 *+%d дней бонуса* пригласившему `%s`%s",
+			result.ReferralBonusDays,
 			*result.ReferrerWhatsApp,
 			referralExpiresLine)
 	}
+	referralLine += bonusLines(result)
 
 	messageText := fmt.Sprintf(
 		"✅ *Подписка создана успешно!*\n\n"+
@@ -755,7 +864,8 @@ func (h *Handler) sendSubscriptionCreated(chatID int64, result *subs.CreateSubsc
 	)
 
 	// Создаем кнопки
-	whatsappLink := generateWhatsAppLink(data.ClientWhatsApp, "Ваша подписка VPN активирована! Сейчас отправлю инструкции по подключению.")
+	whatsappLink := generateWhatsAppLink(data.ClientWhatsApp, messages.WhatsAppTextWithVars(h.clientLanguage(ctx, data.ClientWhatsApp), messages.WATemplateActivated,
+		watemplates.NewVars(data.ClientWhatsApp, result.Subscription.ExpiresAt, data.TariffName)))
 
 	var rows [][]tgbotapi.InlineKeyboardButton
 
@@ -771,14 +881,24 @@ func (h *Handler) sendSubscriptionCreated(chatID int64, result *subs.CreateSubsc
 		tgbotapi.NewInlineKeyboardButtonURL("💬 Написать клиенту", whatsappLink),
 	))
 
+	// Кнопка для добавления окончания подписки в календарь и выбор устройства клиента для инструкции
+	if result.Subscription != nil {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📅 В календарь", fmt.Sprintf("cal_sub:%d", result.Subscription.ID)),
+		))
+		rows = append(rows, platformRow(result.Subscription.ID))
+		messageText += "\n\n📲 Выберите устройство клиента - пришлю инструкцию по подключению для него"
+	}
+
 	// Добавляем кнопку для написания пригласившему
 	if result.ReferralBonusApplied && result.ReferrerWhatsApp != nil {
 		referrerExpiresStr := ""
 		if result.ReferrerNewExpiresAt != nil {
 			referrerExpiresStr = result.ReferrerNewExpiresAt.Format("02.01.2006")
 		}
-		referrerMessage := fmt.Sprintf("🎉 Сизден жаңы кардар келди!\n\nБул жумада: %d чакыруу\nСиздин жазылууңузга +10күн кошулду\nэми %s чейин болду",
+		referrerMessage := messages.WhatsAppText(h.clientLanguage(ctx, *result.ReferrerWhatsApp), messages.WATemplateReferral,
 			result.ReferrerWeeklyCount,
+			result.ReferralBonusDays,
 			referrerExpiresStr)
 		referrerWhatsappLink := generateWhatsAppLink(*result.ReferrerWhatsApp, referrerMessage)
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
@@ -793,7 +913,7 @@ func (h *Handler) sendSubscriptionCreated(chatID int64, result *subs.CreateSubsc
 		editMsg := tgbotapi.NewEditMessageText(chatID, *data.MessageID, messageText)
 		editMsg.ParseMode = "Markdown"
 		editMsg.ReplyMarkup = &keyboard
-		_, err := h.bot.Send(editMsg)
+		err := telegram.SafeEdit(h.bot, editMsg, "")
 		if err != nil {
 			// Fallback: отправляем новое сообщение
 			msg := tgbotapi.NewMessage(chatID, messageText)
@@ -835,6 +955,8 @@ func (h *Handler) createSubscriptionWithPayment(ctx context.Context, chatID int6
 		ClientWhatsApp:         data.ClientWhatsApp,
 		CreatedByTelegramID:    data.AssistantTelegramID,
 		ReferrerSubscriptionID: data.ReferrerSubscriptionID,
+		ServerID:               data.ServerID,
+		Channel:                bonusrules.ChannelAssistant,
 	}
 
 	result, err := h.subscriptionService.CreateSubscription(ctx, subReq)
@@ -844,7 +966,16 @@ func (h *Handler) createSubscriptionWithPayment(ctx context.Context, chatID int6
 	}
 
 	// Отправляем информацию о созданной подписке
-	return h.sendSubscriptionCreated(chatID, result, data)
+	return h.sendSubscriptionCreated(ctx, chatID, result, data)
+}
+
+// clientLanguage возвращает язык клиента для предзаполненных сообщений WhatsApp
+func (h *Handler) clientLanguage(ctx context.Context, phone string) clientlang.Language {
+	stored, err := h.subscriptionStorage.GetClientLanguage(ctx, phone)
+	if err != nil {
+		h.logger.Warn("Failed to get client language", "error", err, "whatsapp", phone)
+	}
+	return clientlang.Resolve(stored, phone)
 }
 
 // generateWhatsAppLink генерирует ссылку на WhatsApp с предзаполненным сообщением
@@ -857,6 +988,20 @@ func generateWhatsAppLink(phone string, message string) string {
 	return fmt.Sprintf("https://wa.me/%s?text=%s", cleanPhone, url.QueryEscape(message))
 }
 
+// escalationButton - передать проблемный заказ админам с контекстом (esc_ord)
+func escalationButton(orderID int64) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("🆘 Эскалация", fmt.Sprintf("esc_ord:%d", orderID))
+}
+
+// platformRow - кнопки выбора устройства клиента; после выбора бот пришлет инструкцию под него (dev_set)
+func platformRow(subscriptionID int64) []tgbotapi.InlineKeyboardButton {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(devices.Supported))
+	for _, platform := range devices.Supported {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(platform.Title(), fmt.Sprintf("dev_set:%d:%s", subscriptionID, platform)))
+	}
+	return row
+}
+
 // TariffCallbackData - структура для данных тарифа из callback
 type TariffCallbackData struct {
 	ID           int64
@@ -918,7 +1063,7 @@ func extractChatID(update *tgbotapi.Update) int64 {
 	return 0
 }
 
-// HandlePaymentCallback обрабатывает callbacks от кнопок оплаты (pay_check, pay_refresh, pay_cancel)
+// HandlePaymentCallback обрабатывает callbacks от кнопок оплаты (pay_check, pay_refresh, pay_cancel, pay_receipt)
 // Эти callbacks работают независимо от состояния пользователя через orderID
 func (h *Handler) HandlePaymentCallback(update *tgbotapi.Update) error {
 	ctx := context.Background()
@@ -948,11 +1093,20 @@ func (h *Handler) HandlePaymentCallback(update *tgbotapi.Update) error {
 		h.logger.Error("Failed to get pending order", "error", err, "orderID", orderID)
 		return h.sendError(chatID, "❌ Ошибка получения заказа")
 	}
-	if order == nil {
+	// Отмененный заказ хранится ради поздней оплаты, но кнопки по нему уже не работают
+	if order == nil || order.Status != orders.StatusPending {
 		return h.sendCallbackError(update, chatID, "❌ Заказ не найден или уже обработан")
 	}
 
+	// В ручном режиме проверка оплаты провела бы платеж без чека: его подтверждает только админ
+	if h.paymentService.IsManualPayment() && (action == "check" || action == "refresh") {
+		_, _ = h.bot.Request(tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, "В ручном режиме оплату подтверждает админ по фото чека"))
+		return nil
+	}
+
 	switch action {
+	case "receipt":
+		return h.handleReceiptRequest(update, order)
 	case "check":
 		return h.handlePaymentCheckFromOrder(ctx, update, order)
 	case "refresh":
@@ -985,7 +1139,8 @@ func (h *Handler) handlePaymentCheckFromOrder(ctx context.Context, update *tgbot
 		return h.handleSuccessfulPaymentFromOrder(ctx, chatID, order)
 	case payment.StatusPending:
 		// Платеж еще обрабатывается - показываем всплывающее уведомление
-		alertConfig := tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, "⏳ Платеж еще обрабатывается.\nПожалуйста, подождите и попробуйте еще раз.")
+		alertText := "⏳ Платеж еще обрабатывается.\nПожалуйста, подождите и попробуйте еще раз." + h.clickSummary(ctx, shortlinks.ClickCriteria{OrderID: &order.ID})
+		alertConfig := tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, alertText)
 		_, _ = h.bot.Request(alertConfig)
 		return nil
 	case payment.StatusRejected, payment.StatusCancelled:
@@ -1006,6 +1161,8 @@ func (h *Handler) handleSuccessfulPaymentFromOrder(ctx context.Context, chatID i
 		ClientWhatsApp:         order.ClientWhatsApp,
 		CreatedByTelegramID:    order.AssistantTelegramID,
 		ReferrerSubscriptionID: order.ReferrerSubscriptionID,
+		ServerID:               order.TargetServerID,
+		Channel:                bonusrules.ChannelAssistant,
 	}
 
 	result, err := h.subscriptionService.CreateSubscription(ctx, subReq)
@@ -1015,7 +1172,7 @@ func (h *Handler) handleSuccessfulPaymentFromOrder(ctx context.Context, chatID i
 	}
 
 	// Отправляем сообщение об успехе
-	if err := h.sendSubscriptionCreatedForOrder(chatID, result, order); err != nil {
+	if err := h.sendSubscriptionCreatedForOrder(ctx, chatID, result, order); err != nil {
 		return err
 	}
 
@@ -1031,18 +1188,40 @@ func (h *Handler) handleSuccessfulPaymentFromOrder(ctx context.Context, chatID i
 func (h *Handler) handlePaymentRefreshFromOrder(ctx context.Context, update *tgbotapi.Update, order *orders.PendingOrder) error {
 	chatID := update.CallbackQuery.Message.Chat.ID
 
+	// Ограничиваем обновления ссылки: каждое создает новый платеж в ЮKassa
+	if reason := order.LinkRefreshBlockReason(time.Now()); reason != "" {
+		_, _ = h.bot.Request(tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, reason))
+		return nil
+	}
+	claimed, err := h.orderService.ClaimLinkRefresh(ctx, order.ID)
+	if err != nil {
+		h.logger.Error("Failed to claim link refresh", "error", err, "orderID", order.ID)
+		_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Ошибка"))
+		return h.sendError(chatID, "❌ Ошибка обновления ссылки")
+	}
+	if !claimed {
+		// Ссылку обновили параллельным нажатием
+		_, _ = h.bot.Request(tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, "⏳ Ссылка только что обновлена, подождите немного"))
+		return nil
+	}
+
 	// Отвечаем на callback query
 	callbackConfig := tgbotapi.NewCallback(update.CallbackQuery.ID, "Создаём новую ссылку...")
 	_, _ = h.bot.Request(callbackConfig)
 
 	// Создаем новый платеж
 	paymentEntity := payment.Payment{
-		UserID: order.AdminUserID,
-		Amount: order.TotalAmount,
-		Status: payment.StatusPending,
+		UserID:     order.AdminUserID,
+		Amount:     order.TotalAmount,
+		Status:     payment.StatusPending,
+		BaseAmount: order.BaseAmount,
+		ServerID:   order.TargetServerID,
 	}
 
-	paymentObj, err := h.paymentService.CreatePayment(ctx, paymentEntity)
+	paymentObj, err := h.paymentService.CreatePaymentWithMeta(ctx, paymentEntity, payment.CreatePaymentMeta{
+		TariffName:     order.TariffName,
+		ClientWhatsApp: order.ClientWhatsApp,
+	})
 	if err != nil {
 		h.logger.Error("Failed to create payment for refresh",
 			"error", err,
@@ -1060,16 +1239,21 @@ func (h *Handler) handlePaymentRefreshFromOrder(ctx context.Context, update *tgb
 		h.logger.Error("Failed to update payment ID", "error", err, "orderID", order.ID)
 	}
 
+	// Старая ссылка больше не нужна - отменяем ее платеж, чтобы заказ не оплатили дважды
+	if _, err := h.paymentService.CancelPayment(ctx, order.PaymentID); err != nil {
+		h.logger.Error("Failed to cancel superseded payment", "error", err, "paymentID", order.PaymentID, "orderID", order.ID)
+	}
+
 	// Формируем обновленное сообщение
 	paymentMsg := fmt.Sprintf(
 		"💳 *Заказ создан!*\n\n"+
 			"📱 Клиент: %s\n"+
 			"📅 Тариф: %s\n"+
-			"💰 Сумма: %.2f ₽\n\n"+
+			"💰 Сумма: %s\n\n"+
 			"🔗 Ссылка на оплату: [link](%s)\n\n"+
 			"Отправьте эту ссылку клиенту.\n"+
 			"После оплаты нажмите «Проверить оплату».",
-		order.ClientWhatsApp, order.TariffName, order.TotalAmount, *paymentObj.PaymentURL)
+		order.ClientWhatsApp, order.TariffName, formatAmount(order.TotalAmount, order.BaseAmount), *paymentObj.PaymentURL)
 
 	// Создаем кнопки
 	checkButton := tgbotapi.NewInlineKeyboardButtonData("🔄 Проверить оплату", fmt.Sprintf("pay_check:%d", order.ID))
@@ -1080,6 +1264,7 @@ func (h *Handler) handlePaymentRefreshFromOrder(ctx context.Context, update *tgb
 		tgbotapi.NewInlineKeyboardRow(checkButton),
 		tgbotapi.NewInlineKeyboardRow(refreshButton),
 		tgbotapi.NewInlineKeyboardRow(cancelButton),
+		tgbotapi.NewInlineKeyboardRow(escalationButton(order.ID)),
 	)
 
 	// Редактируем сообщение
@@ -1087,8 +1272,7 @@ func (h *Handler) handlePaymentRefreshFromOrder(ctx context.Context, update *tgb
 		editMsg := tgbotapi.NewEditMessageText(chatID, *order.MessageID, paymentMsg)
 		editMsg.ParseMode = "Markdown"
 		editMsg.ReplyMarkup = &keyboard
-		_, err = h.bot.Send(editMsg)
-		return err
+		return telegram.SafeEdit(h.bot, editMsg, "")
 	}
 
 	// Fallback: отправляем новое сообщение
@@ -1116,9 +1300,14 @@ func (h *Handler) handlePaymentCancelFromOrder(ctx context.Context, update *tgbo
 	callbackConfig := tgbotapi.NewCallback(update.CallbackQuery.ID, "Отменено")
 	_, _ = h.bot.Request(callbackConfig)
 
-	// Удаляем pending order
-	if err := h.orderService.DeletePendingOrder(ctx, order.ID); err != nil {
-		h.logger.Error("Failed to delete pending order", "error", err, "orderID", order.ID)
+	// Отменяем платеж заказа, чтобы по отправленной клиенту ссылке уже нельзя было оплатить
+	if _, err := h.paymentService.CancelPayment(ctx, order.PaymentID); err != nil {
+		h.logger.Error("Failed to cancel order payment", "error", err, "paymentID", order.PaymentID, "orderID", order.ID)
+	}
+
+	// Заказ не удаляем: если клиент все же оплатит старую ссылку, по нему можно будет выдать подписку
+	if err := h.orderService.UpdateStatus(ctx, order.ID, orders.StatusCancelled); err != nil {
+		h.logger.Error("Failed to mark pending order cancelled", "error", err, "orderID", order.ID)
 	}
 
 	// Редактируем сообщение чтобы показать что заказ отменен
@@ -1131,7 +1320,7 @@ func (h *Handler) handlePaymentCancelFromOrder(ctx context.Context, update *tgbo
 
 		editMsg := tgbotapi.NewEditMessageText(chatID, *order.MessageID, cancelledMsg)
 		editMsg.ParseMode = "Markdown"
-		_, _ = h.bot.Send(editMsg)
+		_ = telegram.SafeEdit(h.bot, editMsg, "")
 	}
 
 	return nil
@@ -1145,13 +1334,13 @@ func (h *Handler) sendPaymentCheckErrorForOrder(chatID int64, order *orders.Pend
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(retryButton),
 		tgbotapi.NewInlineKeyboardRow(cancelButton),
+		tgbotapi.NewInlineKeyboardRow(escalationButton(order.ID)),
 	)
 
 	if order.MessageID != nil {
 		editMsg := tgbotapi.NewEditMessageText(chatID, *order.MessageID, errorMsg)
 		editMsg.ReplyMarkup = &keyboard
-		_, err := h.bot.Send(editMsg)
-		return err
+		return telegram.SafeEdit(h.bot, editMsg, "")
 	}
 
 	msg := tgbotapi.NewMessage(chatID, errorMsg)
@@ -1161,7 +1350,7 @@ func (h *Handler) sendPaymentCheckErrorForOrder(chatID int64, order *orders.Pend
 }
 
 // sendSubscriptionCreatedForOrder отправляет сообщение об успешном создании подписки
-func (h *Handler) sendSubscriptionCreatedForOrder(chatID int64, result *subs.CreateSubscriptionResult, order *orders.PendingOrder) error {
+func (h *Handler) sendSubscriptionCreatedForOrder(ctx context.Context, chatID int64, result *subs.CreateSubscriptionResult, order *orders.PendingOrder) error {
 	passwordLine := ""
 	if result.ServerUIPassword != nil && *result.ServerUIPassword != "" {
 		passwordLine = fmt.Sprintf("\n`%s`", *result.ServerUIPassword)
@@ -1175,10 +1364,12 @@ func (h *Handler) sendSubscriptionCreatedForOrder(chatID int64, result *subs.Cre
 			referralExpiresLine = fmt.Sprintf("\nПодписка до: %s",
 				result.ReferrerNewExpiresAt.Format("02.01.2006"))
 		}
-		referralLine = fmt.Sprintf("\n\n🎁 *+10 дней бонуса* пригласившему `%s`%s",
+		referralLine = fmt.Sprintf("\n\n🎁 *+%d дней бонуса* пригласившему `%s`%s",
+			result.ReferralBonusDays,
 			*result.ReferrerWhatsApp,
 			referralExpiresLine)
 	}
+	referralLine += bonusLines(result)
 
 	messageText := fmt.Sprintf(
 		"✅ *Подписка создана успешно!*\n\n"+
@@ -1193,7 +1384,8 @@ func (h *Handler) sendSubscriptionCreatedForOrder(chatID int64, result *subs.Cre
 		referralLine,
 	)
 
-	whatsappLink := generateWhatsAppLink(order.ClientWhatsApp, "Ваша подписка VPN активирована! Сейчас отправлю инструкции по подключению.")
+	whatsappLink := generateWhatsAppLink(order.ClientWhatsApp, messages.WhatsAppTextWithVars(h.clientLanguage(ctx, order.ClientWhatsApp), messages.WATemplateActivated,
+		watemplates.NewVars(order.ClientWhatsApp, result.Subscription.ExpiresAt, order.TariffName)))
 
 	var rows [][]tgbotapi.InlineKeyboardButton
 
@@ -1207,14 +1399,24 @@ func (h *Handler) sendSubscriptionCreatedForOrder(chatID int64, result *subs.Cre
 		tgbotapi.NewInlineKeyboardButtonURL("💬 Написать клиенту", whatsappLink),
 	))
 
+	// Кнопка для добавления окончания подписки в календарь и выбор устройства клиента для инструкции
+	if result.Subscription != nil {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📅 В календарь", fmt.Sprintf("cal_sub:%d", result.Subscription.ID)),
+		))
+		rows = append(rows, platformRow(result.Subscription.ID))
+		messageText += "\n\n📲 Выберите устройство клиента - пришлю инструкцию по подключению для него"
+	}
+
 	// Добавляем кнопку для написания пригласившему
 	if result.ReferralBonusApplied && result.ReferrerWhatsApp != nil {
 		referrerExpiresStr := ""
 		if result.ReferrerNewExpiresAt != nil {
 			referrerExpiresStr = result.ReferrerNewExpiresAt.Format("02.01.2006")
 		}
-		referrerMessage := fmt.Sprintf("🎉 Сизден жаңы кардар келди!\n\nБул жумада: %d чакыруу\nСиздин жазылууңузга +10күн кошулду\nэми %s чейин болду",
+		referrerMessage := messages.WhatsAppText(h.clientLanguage(ctx, *result.ReferrerWhatsApp), messages.WATemplateReferral,
 			result.ReferrerWeeklyCount,
+			result.ReferralBonusDays,
 			referrerExpiresStr)
 		referrerWhatsappLink := generateWhatsAppLink(*result.ReferrerWhatsApp, referrerMessage)
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
@@ -1228,7 +1430,7 @@ func (h *Handler) sendSubscriptionCreatedForOrder(chatID int64, result *subs.Cre
 		editMsg := tgbotapi.NewEditMessageText(chatID, *order.MessageID, messageText)
 		editMsg.ParseMode = "Markdown"
 		editMsg.ReplyMarkup = &keyboard
-		_, err := h.bot.Send(editMsg)
+		err := telegram.SafeEdit(h.bot, editMsg, "")
 		if err != nil {
 			// Fallback
 			msg := tgbotapi.NewMessage(chatID, messageText)
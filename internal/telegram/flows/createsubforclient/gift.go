@@ -0,0 +1,55 @@
+package createsubforclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// StartWithTariff начинает создание подписки по подарочной ссылке (/start gift_<tariffID>):
+// тариф уже выбран, поэтому после ввода номера сразу показывается подтверждение заказа
+func (h *Handler) StartWithTariff(ctx context.Context, userID, assistantTelegramID, chatID, tariffID int64) error {
+	tariff, err := h.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &tariffID})
+	if err != nil {
+		h.logger.Error("Failed to get gift tariff", "error", err, "tariff_id", tariffID)
+		return h.sendError(chatID, "❌ Ошибка загрузки подарочной ссылки")
+	}
+	if tariff == nil || !tariff.IsAvailableAt(time.Now()) {
+		_ = h.sendError(chatID, "⚠️ Подарочная ссылка недействительна: тариф недоступен. Выберите тариф вручную.")
+		return h.Start(userID, assistantTelegramID, chatID)
+	}
+
+	flowData := &flows.CreateSubForClientFlowData{
+		AdminUserID:         userID,
+		AssistantTelegramID: assistantTelegramID,
+		TariffID:            tariff.ID,
+		TariffName:          tariff.Name,
+		Price:               tariff.Price,
+		TotalAmount:         tariff.Price,
+	}
+	h.stateManager.SetState(chatID, states.AdminCreateSubWaitClientName, flowData)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🎁 Подписка по тарифу «%s» (%s)\n\n"+
+		"📱 Введите номер WhatsApp клиента (например: +996555123456):", tariff.Name, formatDuration(tariff.DurationDays)))
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// showGiftConfirm показывает подтверждение заказа на тариф из подарочной ссылки
+func (h *Handler) showGiftConfirm(ctx context.Context, chatID int64, flowData *flows.CreateSubForClientFlowData) error {
+	tariff, err := h.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &flowData.TariffID})
+	if err != nil {
+		h.logger.Error("Failed to get gift tariff", "error", err, "tariff_id", flowData.TariffID)
+		return h.sendError(chatID, "❌ Ошибка получения тарифа")
+	}
+	if tariff == nil {
+		return h.sendError(chatID, "❌ Тариф не найден")
+	}
+	return h.showQuickConfirm(ctx, chatID, flowData, tariff, "🎁 *Подписка по подарочной ссылке*")
+}
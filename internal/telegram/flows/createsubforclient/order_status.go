@@ -0,0 +1,114 @@
+package createsubforclient
+
+import (
+	"context"
+	"fmt"
+
+	"kurut-bot/internal/stories/orders"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// pendingOrdersLimit - сколько заказов показывает /pending_orders
+const pendingOrdersLimit = 10
+
+// ShowOrder показывает статус заказа по ссылке /start pay_<orderID> с кнопками оплаты.
+// Ассистент видит только свои заказы, админ - любые
+func (h *Handler) ShowOrder(ctx context.Context, viewerTelegramID int64, isAdmin bool, chatID, orderID int64) error {
+	order, err := h.orderService.GetPendingOrderByID(ctx, orderID)
+	if err != nil {
+		h.logger.Error("Failed to get pending order", "error", err, "orderID", orderID)
+		return h.sendError(chatID, "❌ Ошибка получения заказа")
+	}
+	if order == nil || (!isAdmin && order.AssistantTelegramID != viewerTelegramID) {
+		return h.sendError(chatID, "❌ Заказ не найден")
+	}
+	return h.sendOrderCard(ctx, chatID, order)
+}
+
+// ShowPendingOrders - /pending_orders: неоплаченные заказы ассистента карточками с кнопками оплаты,
+// если исходное сообщение заказа потерялось в чате
+func (h *Handler) ShowPendingOrders(ctx context.Context, assistantTelegramID, chatID int64) error {
+	pending, err := h.orderService.ListPendingByAssistant(ctx, assistantTelegramID, pendingOrdersLimit+1)
+	if err != nil {
+		h.logger.Error("Failed to list assistant pending orders", "error", err, "assistantTelegramID", assistantTelegramID)
+		return h.sendError(chatID, "❌ Ошибка загрузки заказов")
+	}
+	if len(pending) == 0 {
+		_, err := h.bot.Send(tgbotapi.NewMessage(chatID, "✅ Неоплаченных заказов нет"))
+		return err
+	}
+
+	header := "⏳ Неоплаченные заказы:"
+	if len(pending) > pendingOrdersLimit {
+		header = fmt.Sprintf("⏳ Неоплаченные заказы: показаны %d последних", pendingOrdersLimit)
+		pending = pending[:pendingOrdersLimit]
+	}
+	if _, err := h.bot.Send(tgbotapi.NewMessage(chatID, header)); err != nil {
+		return err
+	}
+
+	for _, order := range pending {
+		if err := h.sendOrderCard(ctx, chatID, order); err != nil {
+			h.logger.Error("Failed to send pending order", "error", err, "orderID", order.ID)
+		}
+	}
+	return nil
+}
+
+// sendOrderCard отправляет карточку заказа; у ожидающего заказа - с кнопками оплаты
+func (h *Handler) sendOrderCard(ctx context.Context, chatID int64, order *orders.PendingOrder) error {
+	text := fmt.Sprintf(
+		"%s Заказ #%d\n\n"+
+			"📱 Клиент: %s\n"+
+			"📅 Тариф: %s\n"+
+			"💰 Сумма: %s\n"+
+			"🕐 Создан: %s",
+		orderStatusLabel(order.Status), order.ID, order.ClientWhatsApp, order.TariffName,
+		formatAmount(order.TotalAmount, order.BaseAmount), order.CreatedAt.Format("02.01.2006 15:04"))
+	if order.HasReceipt() && order.ReceiptUploadedAt != nil {
+		text += "\n🧾 Чек прикреплен: " + order.ReceiptUploadedAt.Format("02.01.2006 15:04")
+		if order.ReceiptReviewedAt == nil && order.Status == orders.StatusPending {
+			text += " (ждет подтверждения админа)"
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	if order.Status == orders.StatusPending && h.paymentService.IsManualPayment() {
+		msg.ReplyMarkup = receiptOrderKeyboard(order.ID)
+	} else if order.Status == orders.StatusPending {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("🔄 Проверить оплату", fmt.Sprintf("pay_check:%d", order.ID))),
+			tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("🔗 Обновить ссылку", fmt.Sprintf("pay_refresh:%d", order.ID))),
+			tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", fmt.Sprintf("pay_cancel:%d", order.ID))),
+			tgbotapi.NewInlineKeyboardRow(escalationButton(order.ID)),
+		)
+	}
+
+	sentMsg, err := h.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+
+	// Дальнейшие правки заказа (проверка, обновление ссылки) идут в это сообщение,
+	// если заказ открыл его владелец: у админа из чужого чата сообщение заказа не меняем
+	if order.Status == orders.StatusPending && order.ChatID == chatID {
+		if err := h.orderService.UpdateMessageID(ctx, order.ID, sentMsg.MessageID); err != nil {
+			h.logger.Error("Failed to update message ID", "error", err, "orderID", order.ID)
+		}
+	}
+	return nil
+}
+
+func orderStatusLabel(status orders.Status) string {
+	switch status {
+	case orders.StatusPending:
+		return "⏳ Ожидает оплаты:"
+	case orders.StatusCompleted:
+		return "✅ Оплачен:"
+	case orders.StatusCancelled:
+		return "❌ Отменен:"
+	default:
+		return string(status) + ":"
+	}
+}
@@ -0,0 +1,66 @@
+package createsubforclient
+
+import (
+	"context"
+	"fmt"
+
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/telegram/flows"
+)
+
+// resolveServer возвращает сервер заказа. Если сервер не выбран вручную - закрепляет за заказом
+// свободный сервер кластера выбранного тарифа. Сервер, закрепленный до выбора тарифа (для показа цен),
+// заменяется, если тариф требует другой кластер
+func (h *Handler) resolveServer(ctx context.Context, flowData *flows.CreateSubForClientFlowData) *servers.Server {
+	// Тариф может требовать сервер определенного кластера; до выбора тарифа TariffID = 0
+	var cluster string
+	if flowData.TariffID != 0 {
+		if tariff, err := h.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &flowData.TariffID}); err == nil && tariff != nil {
+			cluster = tariff.Cluster
+		}
+	}
+
+	if flowData.ServerID != nil {
+		server, err := h.serverStorage.GetServer(ctx, servers.GetCriteria{ID: flowData.ServerID})
+		if err != nil {
+			h.logger.Warn("Failed to get order server", "error", err, "server_id", *flowData.ServerID)
+			return nil
+		}
+		if server != nil && server.FitsCluster(cluster) {
+			return server
+		}
+		flowData.ServerID = nil
+		flowData.ServerName = nil
+	}
+
+	server, err := h.serverStorage.GetAvailableServer(ctx, cluster)
+	if err != nil || server == nil {
+		h.logger.Warn("Failed to get available server for order", "error", err, "cluster", cluster)
+		return nil
+	}
+	flowData.ServerID = &server.ID
+	flowData.ServerName = &server.Name
+	return server
+}
+
+// applyServerPrice пересчитывает сумму заказа с учетом наценки сервера (flowData.Price - цена тарифа)
+func applyServerPrice(flowData *flows.CreateSubForClientFlowData, server *servers.Server) {
+	flowData.TotalAmount = server.EffectivePrice(flowData.Price)
+}
+
+// baseAmount - цена тарифа для разбивки в платеже; nil если наценки нет
+func baseAmount(price, total float64) *float64 {
+	if price == total {
+		return nil
+	}
+	return &price
+}
+
+// formatAmount - "350.00 ₽" или "450.00 ₽ (тариф 350.00 ₽ + сервер 100.00 ₽)"
+func formatAmount(total float64, base *float64) string {
+	if base == nil {
+		return fmt.Sprintf("%.2f ₽", total)
+	}
+	return fmt.Sprintf("%.2f ₽ (тариф %.2f ₽ + сервер %.2f ₽)", total, *base, total-*base)
+}
@@ -0,0 +1,98 @@
+package createsubforclient
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/telegram/flows"
+)
+
+type fakeTariffs struct {
+	tariff *tariffs.Tariff
+}
+
+func (f *fakeTariffs) GetTariff(_ context.Context, _ tariffs.GetCriteria) (*tariffs.Tariff, error) {
+	return f.tariff, nil
+}
+
+func (f *fakeTariffs) GetActiveTariffs(_ context.Context) ([]*tariffs.Tariff, error) {
+	return []*tariffs.Tariff{f.tariff}, nil
+}
+
+func (f *fakeTariffs) GetTrialTariff(_ context.Context) (*tariffs.Tariff, error) {
+	return nil, nil
+}
+
+// fakeServers - свободные серверы; GetAvailableServer отдает первый подходящий по кластеру
+type fakeServers struct {
+	servers []*servers.Server
+}
+
+func (f *fakeServers) ListServers(_ context.Context, _ servers.ListCriteria) ([]*servers.Server, error) {
+	return f.servers, nil
+}
+
+func (f *fakeServers) GetServer(_ context.Context, criteria servers.GetCriteria) (*servers.Server, error) {
+	for _, s := range f.servers {
+		if criteria.ID != nil && s.ID == *criteria.ID {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeServers) GetActiveUsersCountByServer(_ context.Context, _ int64) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeServers) GetAvailableServer(_ context.Context, cluster string) (*servers.Server, error) {
+	for _, s := range f.servers {
+		if cluster == "" || s.Cluster == cluster {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestResolveServerRespectsTariffCluster(t *testing.T) {
+	basic := &servers.Server{ID: 1, Name: "basic"}
+	premium := &servers.Server{ID: 2, Name: "premium", Cluster: "Premium", PriceSurcharge: 100}
+	tariff := &tariffs.Tariff{ID: 7, Name: "Премиум", Price: 300, Cluster: "Premium"}
+
+	h := &Handler{
+		tariffService: &fakeTariffs{tariff: tariff},
+		serverStorage: &fakeServers{servers: []*servers.Server{basic, premium}},
+		logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	flowData := &flows.CreateSubForClientFlowData{}
+
+	// До выбора тарифа (showTariffs) кластер не известен - закрепляется любой сервер
+	if got := h.resolveServer(context.Background(), flowData); got != basic {
+		t.Fatalf("before tariff selection got %v, want basic server", got)
+	}
+
+	// После выбора тарифа кластера Premium закрепленный сервер другого кластера заменяется
+	flowData.TariffID = tariff.ID
+	flowData.Price = tariff.Price
+	server := h.resolveServer(context.Background(), flowData)
+	if server != premium || flowData.ServerID == nil || *flowData.ServerID != premium.ID {
+		t.Fatalf("after tariff selection got %v (pinned %v), want premium server", server, flowData.ServerID)
+	}
+	if *flowData.ServerName != premium.Name {
+		t.Errorf("server name = %q, want %q", *flowData.ServerName, premium.Name)
+	}
+
+	applyServerPrice(flowData, server)
+	if flowData.TotalAmount != 400 {
+		t.Errorf("total = %v, want 400", flowData.TotalAmount)
+	}
+
+	// Сервер нужного кластера остается закрепленным
+	if got := h.resolveServer(context.Background(), flowData); got != premium {
+		t.Errorf("second call got %v, want premium server", got)
+	}
+}
@@ -0,0 +1,252 @@
+package createsubforclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const quickSubUsage = "Использование: `/quick_sub +996555123456 3m [сервер]`\n\n" +
+	"Срок: `7d` — дни, `3m` — месяцы, `1y` — годы.\n" +
+	"Сервер (необязательно) — ID или название, иначе выбирается автоматически."
+
+var quickSubDurationRe = regexp.MustCompile(`^(\d+)([dmy])$`)
+
+// QuickSubArgs - разобранные аргументы команды /quick_sub
+type QuickSubArgs struct {
+	ClientWhatsApp string
+	Amount         int
+	Unit           byte // 'd', 'm' или 'y'
+	Server         string
+}
+
+// ParseQuickSubArgs разбирает аргументы "<номер> <срок> [сервер]"
+func ParseQuickSubArgs(args string) (*QuickSubArgs, error) {
+	parts := strings.Fields(args)
+	if len(parts) < 2 {
+		return nil, errors.New("укажите номер клиента и срок")
+	}
+
+	phone := NormalizePhone(parts[0])
+	if !IsValidPhoneNumber(phone) {
+		return nil, errors.New("неверный формат номера")
+	}
+
+	match := quickSubDurationRe.FindStringSubmatch(strings.ToLower(parts[1]))
+	if match == nil {
+		return nil, errors.New("неверный срок")
+	}
+	amount, err := strconv.Atoi(match[1])
+	if err != nil || amount <= 0 {
+		return nil, errors.New("неверный срок")
+	}
+
+	return &QuickSubArgs{
+		ClientWhatsApp: phone,
+		Amount:         amount,
+		Unit:           match[2][0],
+		Server:         strings.Join(parts[2:], " "),
+	}, nil
+}
+
+// MatchesTariff проверяет что срок совпадает с длительностью тарифа (в тех же единицах, что formatDuration)
+func (a *QuickSubArgs) MatchesTariff(durationDays int) bool {
+	switch a.Unit {
+	case 'd':
+		return durationDays == a.Amount
+	case 'm':
+		if a.Amount%12 == 0 && durationDays >= 365 {
+			return durationDays/365 == a.Amount/12
+		}
+		return durationDays >= 30 && durationDays < 365 && durationDays/30 == a.Amount
+	case 'y':
+		return durationDays >= 365 && durationDays/365 == a.Amount
+	default:
+		return false
+	}
+}
+
+// QuickStart создает заказ одной командой /quick_sub, минуя пошаговый флоу.
+// Перед созданием платежа показывает подтверждение
+func (h *Handler) QuickStart(ctx context.Context, userID, assistantTelegramID, chatID int64, rawArgs string) error {
+	args, err := ParseQuickSubArgs(rawArgs)
+	if err != nil {
+		return h.sendQuickUsage(chatID, "❌ "+err.Error())
+	}
+
+	tariffsList, err := h.tariffService.GetActiveTariffs(ctx)
+	if err != nil {
+		h.logger.Error("Failed to get tariffs for quick sub", "error", err)
+		return h.sendError(chatID, "❌ Ошибка получения тарифов")
+	}
+
+	var tariff *tariffs.Tariff
+	for _, t := range tariffsList {
+		if args.MatchesTariff(t.DurationDays) {
+			tariff = t
+			break
+		}
+	}
+	if tariff == nil {
+		available := make([]string, 0, len(tariffsList))
+		for _, t := range tariffsList {
+			available = append(available, fmt.Sprintf("%s (%s)", t.Name, formatDuration(t.DurationDays)))
+		}
+		return h.sendError(chatID, fmt.Sprintf("❌ Нет активного тарифа на такой срок.\n\nДоступные тарифы: %s",
+			strings.Join(available, ", ")))
+	}
+
+	flowData := &flows.CreateSubForClientFlowData{
+		AdminUserID:         userID,
+		AssistantTelegramID: assistantTelegramID,
+		ClientWhatsApp:      args.ClientWhatsApp,
+		TariffID:            tariff.ID,
+		TariffName:          tariff.Name,
+		Price:               tariff.Price,
+		TotalAmount:         tariff.Price,
+	}
+
+	if args.Server != "" {
+		server, err := h.findServer(ctx, args.Server)
+		if err != nil {
+			h.logger.Error("Failed to list servers for quick sub", "error", err)
+			return h.sendError(chatID, "❌ Ошибка получения списка серверов")
+		}
+		if server == nil {
+			return h.sendError(chatID, fmt.Sprintf("❌ Сервер «%s» не найден", args.Server))
+		}
+		flowData.ServerID = &server.ID
+		flowData.ServerName = &server.Name
+	}
+
+	return h.showQuickConfirm(ctx, chatID, flowData, tariff, "⚡️ *Быстрое создание подписки*")
+}
+
+// showQuickConfirm показывает итог заказа с кнопкой подтверждения (используется /quick_sub, клонированием и очередью)
+func (h *Handler) showQuickConfirm(ctx context.Context, chatID int64, flowData *flows.CreateSubForClientFlowData, tariff *tariffs.Tariff, title string) error {
+	server := h.resolveServer(ctx, flowData)
+	applyServerPrice(flowData, server)
+
+	serverText := "автоматически"
+	if flowData.ServerName != nil {
+		serverText = *flowData.ServerName
+	}
+
+	text := fmt.Sprintf(
+		"%s\n\n"+
+			"📱 Клиент: %s\n"+
+			"📅 Тариф: %s (%s)\n"+
+			"💰 Сумма: %s\n"+
+			"🖥 Сервер: %s",
+		title, flowData.ClientWhatsApp, tariff.Name, formatDuration(tariff.DurationDays),
+		formatAmount(flowData.TotalAmount, baseAmount(flowData.Price, flowData.TotalAmount)), serverText)
+
+	// Предупреждаем, если у клиента уже есть активная подписка
+	activeSub, err := h.subscriptionService.FindActiveSubscriptionByWhatsApp(ctx, flowData.ClientWhatsApp)
+	if err != nil {
+		h.logger.Warn("Failed to check active subscription", "error", err, "whatsapp", flowData.ClientWhatsApp)
+	} else if activeSub != nil {
+		text += "\n\n⚠️ У клиента уже есть активная подписка"
+		if activeSub.ExpiresAt != nil {
+			text += fmt.Sprintf(" до %s", activeSub.ExpiresAt.Format("02.01.2006"))
+		}
+	}
+
+	confirmText := "✅ Создать заказ"
+	if tariff.Price == 0 {
+		confirmText = "✅ Создать подписку"
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(confirmText, "qsub_confirm"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	sentMsg, err := h.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+
+	flowData.MessageID = &sentMsg.MessageID
+	h.stateManager.SetState(chatID, states.AdminCreateSubWaitQuickConfirm, flowData)
+	return nil
+}
+
+// handleQuickConfirm обрабатывает подтверждение /quick_sub, клонирования подписки и заказа из очереди
+func (h *Handler) handleQuickConfirm(ctx context.Context, update *tgbotapi.Update) error {
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Подтвердите или отмените создание кнопками")
+	}
+
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	if update.CallbackQuery.Data == "cancel" {
+		return h.handleCancel(ctx, update)
+	}
+	if update.CallbackQuery.Data != "qsub_confirm" {
+		return nil
+	}
+
+	flowData, err := h.stateManager.GetCreateSubForClientData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	callbackConfig := tgbotapi.NewCallback(update.CallbackQuery.ID, "Создаём заказ...")
+	_, _ = h.bot.Request(callbackConfig)
+
+	if h.resolveServer(ctx, flowData) == nil {
+		return h.offerWaitlist(chatID, flowData)
+	}
+	h.fulfillWaitlistEntry(ctx, flowData)
+
+	if flowData.Price == 0 {
+		return h.createFreeSubscription(ctx, chatID, flowData)
+	}
+
+	h.stateManager.SetState(chatID, states.AdminCreateSubWaitPayment, flowData)
+	return h.createPaymentAndShow(ctx, chatID, flowData)
+}
+
+// findServer ищет неархивный сервер по ID или названию (без учета регистра)
+func (h *Handler) findServer(ctx context.Context, query string) (*servers.Server, error) {
+	archived := false
+	list, err := h.serverStorage.ListServers(ctx, servers.ListCriteria{Archived: &archived, Limit: 100})
+	if err != nil {
+		return nil, err
+	}
+
+	id, idErr := strconv.ParseInt(query, 10, 64)
+	for _, srv := range list {
+		if idErr == nil && srv.ID == id {
+			return srv, nil
+		}
+		if strings.EqualFold(srv.Name, query) {
+			return srv, nil
+		}
+	}
+	return nil, nil
+}
+
+func (h *Handler) sendQuickUsage(chatID int64, errorText string) error {
+	msg := tgbotapi.NewMessage(chatID, errorText+"\n\n"+quickSubUsage)
+	msg.ParseMode = "Markdown"
+	_, err := h.bot.Send(msg)
+	return err
+}
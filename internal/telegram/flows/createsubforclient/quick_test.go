@@ -0,0 +1,83 @@
+package createsubforclient
+
+import "testing"
+
+func TestParseQuickSubArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *QuickSubArgs
+		wantErr bool
+	}{
+		{
+			name:  "months without server",
+			input: "+996555123456 3m",
+			want:  &QuickSubArgs{ClientWhatsApp: "996555123456", Amount: 3, Unit: 'm'},
+		},
+		{
+			name:  "days with server name",
+			input: "+996-555-123-456 7D Bishkek 2",
+			want:  &QuickSubArgs{ClientWhatsApp: "996555123456", Amount: 7, Unit: 'd', Server: "Bishkek 2"},
+		},
+		{
+			name:    "missing duration",
+			input:   "+996555123456",
+			wantErr: true,
+		},
+		{
+			name:    "invalid phone",
+			input:   "12345 1m",
+			wantErr: true,
+		},
+		{
+			name:    "invalid duration",
+			input:   "+996555123456 3w",
+			wantErr: true,
+		},
+		{
+			name:    "zero duration",
+			input:   "+996555123456 0m",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQuickSubArgs(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseQuickSubArgs(%q) expected error", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseQuickSubArgs(%q) unexpected error: %v", tt.input, err)
+			}
+			if *got != *tt.want {
+				t.Errorf("ParseQuickSubArgs(%q) = %+v, want %+v", tt.input, *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestQuickSubArgsMatchesTariff(t *testing.T) {
+	tests := []struct {
+		args         QuickSubArgs
+		durationDays int
+		want         bool
+	}{
+		{QuickSubArgs{Amount: 1, Unit: 'm'}, 30, true},
+		{QuickSubArgs{Amount: 3, Unit: 'm'}, 90, true},
+		{QuickSubArgs{Amount: 3, Unit: 'm'}, 30, false},
+		{QuickSubArgs{Amount: 12, Unit: 'm'}, 365, true},
+		{QuickSubArgs{Amount: 1, Unit: 'y'}, 365, true},
+		{QuickSubArgs{Amount: 7, Unit: 'd'}, 7, true},
+		{QuickSubArgs{Amount: 7, Unit: 'd'}, 30, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.args.MatchesTariff(tt.durationDays); got != tt.want {
+			t.Errorf("%d%c MatchesTariff(%d) = %v, want %v", tt.args.Amount, tt.args.Unit, tt.durationDays, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package createsubforclient
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/bonusrules"
+	"kurut-bot/internal/stories/orders"
+	"kurut-bot/internal/stories/payment"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Кнопки админа под пересланным чеком: rcpt_ok:<orderID> - подтвердить, rcpt_no:<orderID> - отклонить
+const (
+	receiptApprovePrefix = "rcpt_ok:"
+	receiptRejectPrefix  = "rcpt_no:"
+)
+
+// paymentsQueueLimit - сколько чеков /payments_pending присылает за раз
+const paymentsQueueLimit = 10
+
+// createReceiptOrder - заказ в ручном режиме оплаты: ссылки нет, ассистент присылает фото чека,
+// а подписка создается после подтверждения чека админом
+func (h *Handler) createReceiptOrder(ctx context.Context, chatID int64, data *flows.CreateSubForClientFlowData) error {
+	paymentObj, err := h.paymentService.CreateReceiptPayment(ctx, payment.Payment{
+		UserID:     data.AdminUserID,
+		Amount:     data.TotalAmount,
+		BaseAmount: baseAmount(data.Price, data.TotalAmount),
+		ServerID:   data.ServerID,
+	})
+	if err != nil {
+		h.logger.Error("Failed to create receipt payment", "error", err, "user_id", data.AdminUserID, "amount", data.TotalAmount)
+		return h.sendError(chatID, "Ошибка создания платежа. Попробуйте позже или обратитесь к администратору.")
+	}
+
+	createdOrder, err := h.orderService.CreatePendingOrder(ctx, orders.PendingOrder{
+		PaymentID:              paymentObj.ID,
+		AdminUserID:            data.AdminUserID,
+		AssistantTelegramID:    data.AssistantTelegramID,
+		ChatID:                 chatID,
+		ClientWhatsApp:         data.ClientWhatsApp,
+		TariffID:               data.TariffID,
+		TariffName:             data.TariffName,
+		TotalAmount:            data.TotalAmount,
+		BaseAmount:             paymentObj.BaseAmount,
+		ReferrerWhatsApp:       data.ReferrerWhatsApp,
+		ReferrerSubscriptionID: data.ReferrerSubscriptionID,
+		TargetServerID:         data.ServerID,
+	})
+	if err != nil {
+		h.logger.Error("Failed to create pending order", "error", err)
+		return h.sendError(chatID, "❌ Ошибка создания заказа")
+	}
+
+	text := fmt.Sprintf(
+		"🧾 Заказ #%d создан\n\n"+
+			"📱 Клиент: %s\n"+
+			"📅 Тариф: %s\n"+
+			"💰 Сумма: %s\n\n"+
+			"📸 Пришлите фото чека об оплате - заказ уйдет админу на подтверждение",
+		createdOrder.ID, data.ClientWhatsApp, data.TariffName, formatAmount(data.TotalAmount, paymentObj.BaseAmount))
+	keyboard := receiptOrderKeyboard(createdOrder.ID)
+
+	var messageID int
+	if data.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *data.MessageID, text)
+		editMsg.ReplyMarkup = &keyboard
+		if err := telegram.SafeEdit(h.bot, editMsg, ""); err != nil {
+			return err
+		}
+		messageID = *data.MessageID
+	} else {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ReplyMarkup = keyboard
+		sentMsg, err := h.bot.Send(msg)
+		if err != nil {
+			return err
+		}
+		messageID = sentMsg.MessageID
+	}
+
+	if err := h.orderService.UpdateMessageID(ctx, createdOrder.ID, messageID); err != nil {
+		h.logger.Error("Failed to update message ID", "error", err, "orderID", createdOrder.ID)
+	}
+
+	h.stateManager.SetState(chatID, states.AdminCreateSubWaitReceipt, &flows.CreateSubForClientFlowData{
+		AdminUserID:         data.AdminUserID,
+		AssistantTelegramID: data.AssistantTelegramID,
+		ReceiptOrderID:      &createdOrder.ID,
+	})
+	return nil
+}
+
+// receiptOrderKeyboard - кнопки заказа в ручном режиме: проверять оплату нечем, только прикрепить чек или отменить
+func receiptOrderKeyboard(orderID int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("📸 Прикрепить чек", fmt.Sprintf("pay_receipt:%d", orderID))),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", fmt.Sprintf("pay_cancel:%d", orderID))),
+		tgbotapi.NewInlineKeyboardRow(escalationButton(orderID)),
+	)
+}
+
+// handleReceiptRequest - кнопка «Прикрепить чек»: ждем фото к заказу, в том числе повторное, пока админ его не рассмотрел
+func (h *Handler) handleReceiptRequest(update *tgbotapi.Update, order *orders.PendingOrder) error {
+	chatID := update.CallbackQuery.Message.Chat.ID
+	_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+
+	h.stateManager.SetState(chatID, states.AdminCreateSubWaitReceipt, &flows.CreateSubForClientFlowData{
+		AdminUserID:         order.AdminUserID,
+		AssistantTelegramID: order.AssistantTelegramID,
+		ReceiptOrderID:      &order.ID,
+	})
+
+	_, err := h.bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("📸 Пришлите фото чека по заказу #%d", order.ID)))
+	return err
+}
+
+// handleReceiptUpload сохраняет фото чека у заказа и пересылает его админам на подтверждение
+func (h *Handler) handleReceiptUpload(ctx context.Context, update *tgbotapi.Update) error {
+	if update.Message == nil {
+		return nil
+	}
+	chatID := update.Message.Chat.ID
+
+	if len(update.Message.Photo) == 0 {
+		_, err := h.bot.Send(tgbotapi.NewMessage(chatID, "📸 Пришлите чек фотографией (не файлом). Отменить заказ можно кнопкой под ним"))
+		return err
+	}
+
+	flowData, err := h.stateManager.GetCreateSubForClientData(chatID)
+	if err != nil || flowData.ReceiptOrderID == nil {
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+	orderID := *flowData.ReceiptOrderID
+
+	// Telegram присылает несколько размеров фото, последний - самый крупный
+	fileID := update.Message.Photo[len(update.Message.Photo)-1].FileID
+	attached, err := h.orderService.AttachReceipt(ctx, orderID, fileID)
+	if err != nil {
+		h.logger.Error("Failed to attach receipt", "error", err, "orderID", orderID)
+		return h.sendError(chatID, "❌ Ошибка сохранения чека")
+	}
+	h.stateManager.Clear(chatID)
+	if !attached {
+		return h.sendError(chatID, "❌ Заказ уже обработан или отменен")
+	}
+
+	order, err := h.orderService.GetPendingOrderByID(ctx, orderID)
+	if err != nil || order == nil {
+		h.logger.Error("Failed to get pending order", "error", err, "orderID", orderID)
+		return h.sendError(chatID, "❌ Ошибка получения заказа")
+	}
+
+	h.logger.Info("Receipt attached to order", "audit", true, "order_id", order.ID, "assistant_telegram_id", order.AssistantTelegramID)
+	h.forwardReceipt(order, fileID)
+
+	_, err = h.bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf(
+		"✅ Чек по заказу #%d отправлен админу на подтверждение. Подписку создам после подтверждения", order.ID)))
+	return err
+}
+
+// forwardReceipt отправляет чек в очередь подтверждения: в админскую группу или каждому админу
+func (h *Handler) forwardReceipt(order *orders.PendingOrder, fileID string) {
+	for _, adminChatID := range h.adminChats.AdminChatIDs() {
+		if _, err := h.bot.Send(h.receiptMessage(adminChatID, order, fileID)); err != nil {
+			h.logger.Error("Failed to forward receipt", "error", err, "orderID", order.ID, "chat_id", adminChatID)
+		}
+	}
+}
+
+// receiptMessage - фото чека с данными заказа и кнопками решения админа
+func (h *Handler) receiptMessage(chatID int64, order *orders.PendingOrder, fileID string) tgbotapi.PhotoConfig {
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(fileID))
+	photo.Caption = fmt.Sprintf(
+		"🧾 Чек по заказу #%d\n\n"+
+			"📱 Клиент: %s\n"+
+			"📅 Тариф: %s\n"+
+			"💰 Сумма: %s\n"+
+			"👤 Ассистент: %s",
+		order.ID, order.ClientWhatsApp, order.TariffName,
+		formatAmount(order.TotalAmount, order.BaseAmount), telegram.UserName(h.bot, order.AssistantTelegramID))
+	photo.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Подтвердить", fmt.Sprintf("%s%d", receiptApprovePrefix, order.ID)),
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отклонить", fmt.Sprintf("%s%d", receiptRejectPrefix, order.ID)),
+	))
+	return photo
+}
+
+// ShowPaymentsQueue - /payments_pending: платежи ручного режима, ждущие решения админа.
+// Заказы с чеком приходят фото с кнопками, заказы без чека - одним списком
+func (h *Handler) ShowPaymentsQueue(ctx context.Context, chatID int64) error {
+	if !h.paymentService.IsManualPayment() {
+		_, err := h.bot.Send(tgbotapi.NewMessage(chatID,
+			"ℹ️ Очередь подтверждения работает в ручном режиме оплаты. Сейчас платежи проверяются через ЮKassa автоматически"))
+		return err
+	}
+
+	pending, err := h.orderService.ListPending(ctx)
+	if err != nil {
+		h.logger.Error("Failed to list pending orders", "error", err)
+		return h.sendError(chatID, "❌ Ошибка загрузки очереди платежей")
+	}
+
+	var withReceipt, withoutReceipt []*orders.PendingOrder
+	for _, order := range pending {
+		if order.HasReceipt() {
+			withReceipt = append(withReceipt, order)
+		} else {
+			withoutReceipt = append(withoutReceipt, order)
+		}
+	}
+	if len(withReceipt) == 0 && len(withoutReceipt) == 0 {
+		_, err := h.bot.Send(tgbotapi.NewMessage(chatID, "✅ Нет платежей на подтверждении"))
+		return err
+	}
+
+	header := fmt.Sprintf("🧾 *Платежи на подтверждении:* %d", len(withReceipt))
+	if len(withReceipt) > paymentsQueueLimit {
+		header += fmt.Sprintf("\nПоказаны %d самых старых, остальные - после их обработки", paymentsQueueLimit)
+		withReceipt = withReceipt[:paymentsQueueLimit]
+	}
+	if len(withoutReceipt) > 0 {
+		header += fmt.Sprintf("\n\n⏳ *Ждут чек от ассистента:* %d\n", len(withoutReceipt))
+		for _, order := range withoutReceipt {
+			header += fmt.Sprintf("• #%d %s - %s, %s\n",
+				order.ID, order.ClientWhatsApp, formatAmount(order.TotalAmount, order.BaseAmount), order.CreatedAt.Format("02.01 15:04"))
+		}
+	}
+	msg := tgbotapi.NewMessage(chatID, header)
+	msg.ParseMode = "Markdown"
+	if _, err := h.bot.Send(msg); err != nil {
+		return err
+	}
+
+	for _, order := range withReceipt {
+		if _, err := h.bot.Send(h.receiptMessage(chatID, order, *order.ReceiptFileID)); err != nil {
+			h.logger.Error("Failed to send receipt from queue", "error", err, "orderID", order.ID)
+		}
+	}
+	return nil
+}
+
+// HandleReceiptCallback - решение админа по чеку (rcpt_ok, rcpt_no). Права проверяет роутер
+func (h *Handler) HandleReceiptCallback(ctx context.Context, adminTelegramID int64, callbackQuery *tgbotapi.CallbackQuery) error {
+	approve := strings.HasPrefix(callbackQuery.Data, receiptApprovePrefix)
+	idPart := strings.TrimPrefix(strings.TrimPrefix(callbackQuery.Data, receiptApprovePrefix), receiptRejectPrefix)
+	orderID, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "❌ Неверный ID заказа"))
+		return nil
+	}
+
+	status := orders.StatusCancelled
+	if approve {
+		status = orders.StatusCompleted
+	}
+	claimed, err := h.orderService.ClaimReceiptReview(ctx, orderID, adminTelegramID, status)
+	if err != nil {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "Ошибка"))
+		return fmt.Errorf("claim receipt review: %w", err)
+	}
+	if !claimed {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "Заказ уже рассмотрен или отменен"))
+		h.closeReceipt(callbackQuery, "ℹ️ Уже обработан")
+		return nil
+	}
+
+	order, err := h.orderService.GetPendingOrderByID(ctx, orderID)
+	if err != nil || order == nil {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "Ошибка"))
+		return fmt.Errorf("get pending order %d: %w", orderID, err)
+	}
+
+	h.logger.Info("Receipt reviewed",
+		"audit", true,
+		"admin_telegram_id", adminTelegramID,
+		"order_id", order.ID,
+		"approved", approve,
+	)
+
+	if !approve {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "Чек отклонен"))
+		h.closeReceipt(callbackQuery, "❌ Отклонен")
+		return h.rejectReceipt(ctx, order)
+	}
+
+	_, _ = h.bot.Request(tgbotapi.NewCallback(callbackQuery.ID, "Создаем подписку..."))
+	h.closeReceipt(callbackQuery, "✅ Подтвержден")
+	return h.approveReceipt(ctx, order)
+}
+
+// approveReceipt подтверждает платеж и создает подписку; сообщение получает ассистент в чате заказа.
+// Заказ остается в базе со статусом completed, чтобы чек сохранился в истории
+func (h *Handler) approveReceipt(ctx context.Context, order *orders.PendingOrder) error {
+	paymentObj, err := h.paymentService.CheckPaymentStatus(ctx, order.PaymentID)
+	if err != nil || paymentObj.Status != payment.StatusApproved {
+		h.logger.Error("Failed to approve receipt payment", "error", err, "paymentID", order.PaymentID)
+		return h.sendError(order.ChatID, fmt.Sprintf("❌ Чек по заказу #%d подтвержден, но платеж не удалось провести. Обратитесь к администратору", order.ID))
+	}
+
+	result, err := h.subscriptionService.CreateSubscription(ctx, &subs.CreateSubscriptionRequest{
+		UserID:                 order.AdminUserID,
+		TariffID:               order.TariffID,
+		PaymentID:              &order.PaymentID,
+		ClientWhatsApp:         order.ClientWhatsApp,
+		CreatedByTelegramID:    order.AssistantTelegramID,
+		ReferrerSubscriptionID: order.ReferrerSubscriptionID,
+		ServerID:               order.TargetServerID,
+		Channel:                bonusrules.ChannelAssistant,
+	})
+	if err != nil {
+		h.logger.Error("Failed to create subscription after receipt approval", "error", err, "paymentID", order.PaymentID)
+		return h.sendError(order.ChatID, fmt.Sprintf("❌ Чек по заказу #%d подтвержден, но подписку создать не удалось", order.ID))
+	}
+
+	// Новое сообщение вместо правки заказа: ассистент должен получить уведомление
+	order.MessageID = nil
+	return h.sendSubscriptionCreatedForOrder(ctx, order.ChatID, result, order)
+}
+
+// rejectReceipt отменяет платеж заказа и сообщает ассистенту
+func (h *Handler) rejectReceipt(ctx context.Context, order *orders.PendingOrder) error {
+	if _, err := h.paymentService.CancelPayment(ctx, order.PaymentID); err != nil {
+		h.logger.Error("Failed to cancel receipt payment", "error", err, "paymentID", order.PaymentID, "orderID", order.ID)
+	}
+
+	if order.MessageID != nil {
+		_ = telegram.SafeEdit(h.bot, tgbotapi.NewEditMessageReplyMarkup(order.ChatID, *order.MessageID,
+			tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}), "")
+	}
+
+	_, err := h.bot.Send(tgbotapi.NewMessage(order.ChatID, fmt.Sprintf(
+		"❌ Админ отклонил чек по заказу #%d\n\n📱 Клиент: %s\n📅 Тариф: %s\n\nЗаказ отменен. Проверьте оплату и создайте заказ заново",
+		order.ID, order.ClientWhatsApp, order.TariffName)))
+	return err
+}
+
+// closeReceipt убирает кнопки под чеком и дописывает решение, чтобы другие админы его видели
+func (h *Handler) closeReceipt(callbackQuery *tgbotapi.CallbackQuery, verdict string) {
+	if callbackQuery.Message == nil {
+		return
+	}
+	edit := tgbotapi.NewEditMessageCaption(callbackQuery.Message.Chat.ID, callbackQuery.Message.MessageID,
+		callbackQuery.Message.Caption+"\n\n"+verdict)
+	edit.ReplyMarkup = &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	_ = telegram.SafeEdit(h.bot, edit, "")
+}
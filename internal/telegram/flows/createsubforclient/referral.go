@@ -0,0 +1,38 @@
+package createsubforclient
+
+import (
+	"context"
+
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// StartWithReferrer начинает создание подписки по реферальной ссылке (/start ref_<subID>):
+// пригласивший уже известен, поэтому шаг с вопросом о реферале пропускается
+func (h *Handler) StartWithReferrer(ctx context.Context, userID, assistantTelegramID, chatID, referrerSubscriptionID int64) error {
+	referrerSub, err := h.subscriptionStorage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{referrerSubscriptionID}})
+	if err != nil {
+		h.logger.Error("Failed to get referrer subscription", "error", err, "subscription_id", referrerSubscriptionID)
+		return h.sendError(chatID, "❌ Ошибка загрузки реферальной ссылки")
+	}
+	if referrerSub == nil || referrerSub.Status != subs.StatusActive || referrerSub.ClientWhatsApp == nil {
+		_ = h.sendError(chatID, "⚠️ Реферальная ссылка недействительна: у пригласившего нет активной подписки. Создаем подписку без реферала.")
+		return h.Start(userID, assistantTelegramID, chatID)
+	}
+
+	flowData := &flows.CreateSubForClientFlowData{
+		AdminUserID:            userID,
+		AssistantTelegramID:    assistantTelegramID,
+		ReferrerWhatsApp:       referrerSub.ClientWhatsApp,
+		ReferrerSubscriptionID: &referrerSub.ID,
+	}
+	h.stateManager.SetState(chatID, states.AdminCreateSubWaitClientName, flowData)
+
+	msg := tgbotapi.NewMessage(chatID, "👥 Клиент по приглашению от "+*referrerSub.ClientWhatsApp+"\n\n"+
+		"📱 Введите номер WhatsApp нового клиента (например: +996555123456):")
+	_, err = h.bot.Send(msg)
+	return err
+}
@@ -0,0 +1,218 @@
+package createsubforclient
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/stories/waitlist"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// offerWaitlist сообщает что все серверы заполнены и предлагает записать клиента в очередь
+func (h *Handler) offerWaitlist(chatID int64, flowData *flows.CreateSubForClientFlowData) error {
+	text := fmt.Sprintf(
+		"⚠️ Все VPN серверы сейчас заполнены.\n\n"+
+			"Можно записать клиента %s в очередь: бот напишет, как только освободится место, "+
+			"а цена тарифа «%s» (%.2f ₽) сохранится на %d ч после уведомления.",
+		flowData.ClientWhatsApp, flowData.TariffName, flowData.Price, int(waitlist.PriceHoldDuration.Hours()))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🕒 Встать в очередь", "waitlist_join"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+		),
+	)
+
+	h.stateManager.SetState(chatID, states.AdminCreateSubWaitWaitlist, flowData)
+
+	if flowData.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
+		editMsg.ReplyMarkup = &keyboard
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	sentMsg, err := h.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+	flowData.MessageID = &sentMsg.MessageID
+	h.stateManager.SetState(chatID, states.AdminCreateSubWaitWaitlist, flowData)
+	return nil
+}
+
+// handleWaitlistChoice записывает клиента в очередь после согласия ассистента
+func (h *Handler) handleWaitlistChoice(ctx context.Context, update *tgbotapi.Update) error {
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Используйте кнопки для выбора")
+	}
+
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	if update.CallbackQuery.Data == "cancel" {
+		return h.handleCancel(ctx, update)
+	}
+	if update.CallbackQuery.Data != "waitlist_join" {
+		return nil
+	}
+
+	flowData, err := h.stateManager.GetCreateSubForClientData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	entry, err := h.joinWaitlist(ctx, chatID, flowData)
+	if err != nil {
+		h.logger.Error("Failed to join waitlist", "error", err, "whatsapp", flowData.ClientWhatsApp)
+		_ = h.answerCallback(update.CallbackQuery.ID, "Ошибка")
+		return h.sendError(chatID, "❌ Ошибка записи в очередь")
+	}
+	_ = h.answerCallback(update.CallbackQuery.ID, "Записано")
+
+	h.stateManager.Clear(chatID)
+
+	text := fmt.Sprintf(
+		"🕒 Клиент %s в очереди (запись #%d)\n"+
+			"📅 Тариф: %s — %.2f ₽\n\n"+
+			"Бот напишет сюда, как только освободится место.",
+		entry.ClientWhatsApp, entry.ID, entry.TariffName, entry.HeldPrice)
+
+	if flowData.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+	_, err = h.bot.Send(tgbotapi.NewMessage(chatID, text))
+	return err
+}
+
+// joinWaitlist создает запись в очереди. Повторно клиента не записывает: заказ из очереди,
+// на который снова не хватило места, возвращается в ожидание, а уже стоящий в очереди клиент остается со своей записью
+func (h *Handler) joinWaitlist(ctx context.Context, chatID int64, flowData *flows.CreateSubForClientFlowData) (*waitlist.Entry, error) {
+	if flowData.WaitlistEntryID != nil {
+		if err := h.waitlistStorage.UpdateWaitlistEntryStatus(ctx, *flowData.WaitlistEntryID, waitlist.StatusWaiting); err != nil {
+			return nil, err
+		}
+		return h.waitlistStorage.GetWaitlistEntry(ctx, *flowData.WaitlistEntryID)
+	}
+
+	existing, err := h.waitlistStorage.ListWaitlistEntries(ctx, waitlist.ListCriteria{
+		Statuses:       []waitlist.Status{waitlist.StatusWaiting, waitlist.StatusNotified},
+		ClientWhatsApp: &flowData.ClientWhatsApp,
+		Limit:          1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return existing[0], nil
+	}
+
+	return h.waitlistStorage.CreateWaitlistEntry(ctx, waitlist.Entry{
+		AdminUserID:         flowData.AdminUserID,
+		AssistantTelegramID: flowData.AssistantTelegramID,
+		ChatID:              chatID,
+		ClientWhatsApp:      flowData.ClientWhatsApp,
+		TariffID:            flowData.TariffID,
+		TariffName:          flowData.TariffName,
+		HeldPrice:           flowData.Price,
+	})
+}
+
+// fulfillWaitlistEntry закрывает запись очереди, по которой создается заказ
+func (h *Handler) fulfillWaitlistEntry(ctx context.Context, flowData *flows.CreateSubForClientFlowData) {
+	if flowData.WaitlistEntryID == nil {
+		return
+	}
+	if err := h.waitlistStorage.UpdateWaitlistEntryStatus(ctx, *flowData.WaitlistEntryID, waitlist.StatusFulfilled); err != nil {
+		h.logger.Error("Failed to fulfill waitlist entry", "error", err, "entry_id", *flowData.WaitlistEntryID)
+	}
+}
+
+// HandleWaitlistCallback обрабатывает кнопки уведомления об освободившемся месте:
+// wl_buy:ID - оформить заказ по записи, wl_cancel:ID - убрать клиента из очереди.
+// Ассистенту доступны только его записи, админу - любые
+func (h *Handler) HandleWaitlistCallback(ctx context.Context, userID, assistantTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	chatID := callbackQuery.Message.Chat.ID
+
+	parts := strings.Split(callbackQuery.Data, ":")
+	if len(parts) != 2 {
+		return h.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+	entryID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return h.answerCallback(callbackQuery.ID, "Неверный ID записи")
+	}
+
+	entry, err := h.waitlistStorage.GetWaitlistEntry(ctx, entryID)
+	if err != nil || entry == nil {
+		h.logger.Error("Failed to get waitlist entry", "error", err, "entry_id", entryID)
+		return h.answerCallback(callbackQuery.ID, "Запись не найдена")
+	}
+	if !isAdmin && entry.AssistantTelegramID != assistantTelegramID {
+		return h.answerCallback(callbackQuery.ID, "Запись не найдена")
+	}
+	if !entry.IsOpen() {
+		return h.answerCallback(callbackQuery.ID, "Запись уже обработана")
+	}
+
+	switch strings.TrimPrefix(parts[0], "wl_") {
+	case "cancel":
+		if err := h.waitlistStorage.UpdateWaitlistEntryStatus(ctx, entry.ID, waitlist.StatusCancelled); err != nil {
+			h.logger.Error("Failed to cancel waitlist entry", "error", err, "entry_id", entry.ID)
+			return h.answerCallback(callbackQuery.ID, "Ошибка")
+		}
+		_ = h.answerCallback(callbackQuery.ID, "Удалено из очереди")
+		editMsg := tgbotapi.NewEditMessageText(chatID, callbackQuery.Message.MessageID,
+			fmt.Sprintf("❌ Клиент %s удален из очереди", entry.ClientWhatsApp))
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	case "buy":
+		return h.startWaitlistOrder(ctx, userID, assistantTelegramID, callbackQuery, entry)
+	default:
+		return h.answerCallback(callbackQuery.ID, "Неизвестное действие")
+	}
+}
+
+// startWaitlistOrder показывает подтверждение заказа по записи очереди с удержанной ценой
+func (h *Handler) startWaitlistOrder(ctx context.Context, userID, assistantTelegramID int64, callbackQuery *tgbotapi.CallbackQuery, entry *waitlist.Entry) error {
+	chatID := callbackQuery.Message.Chat.ID
+
+	tariff, err := h.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &entry.TariffID})
+	if err != nil || tariff == nil {
+		h.logger.Error("Failed to get tariff for waitlist entry", "error", err, "entry_id", entry.ID)
+		return h.answerCallback(callbackQuery.ID, "Тариф не найден")
+	}
+
+	_ = h.answerCallback(callbackQuery.ID, "")
+
+	now := time.Now()
+	price := entry.Price(tariff.Price, now)
+
+	flowData := &flows.CreateSubForClientFlowData{
+		AdminUserID:         userID,
+		AssistantTelegramID: assistantTelegramID,
+		ClientWhatsApp:      entry.ClientWhatsApp,
+		TariffID:            tariff.ID,
+		TariffName:          tariff.Name,
+		Price:               price,
+		TotalAmount:         price,
+		WaitlistEntryID:     &entry.ID,
+	}
+
+	title := "🕒 *Заказ из очереди*"
+	if entry.IsPriceHeld(now) {
+		title += fmt.Sprintf("\nЦена сохранена до %s", entry.PriceHeldUntil.Local().Format("02.01.2006 15:04"))
+	}
+
+	return h.showQuickConfirm(ctx, chatID, flowData, tariff, title)
+}
@@ -19,6 +19,7 @@ type (
 	stateManager interface {
 		GetState(chatID int64) states.State
 		SetState(chatID int64, state states.State, data any)
+		Back(chatID int64) (states.State, bool)
 		Clear(chatID int64)
 		GetCreateTariffData(chatID int64) (*flows.CreateTariffFlowData, error)
 	}
@@ -9,8 +9,10 @@ import (
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"kurut-bot/internal/infra/telegram"
 	"kurut-bot/internal/stories/tariffs"
 	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/keypad"
 	"kurut-bot/internal/telegram/states"
 )
 
@@ -35,6 +37,11 @@ func NewHandler(
 	}
 }
 
+var (
+	priceKeypad    = keypad.Config{Presets: []string{"0", "199", "299", "499", "990"}, Decimal: true, Back: true}
+	durationKeypad = keypad.Config{Presets: []string{"7", "30", "90", "180", "365"}, MaxLen: 3, Back: true}
+)
+
 // Start начинает флоу создания тарифа (только для админов)
 func (h *Handler) Start(chatID int64) error {
 	// Инициализируем данные флоу
@@ -49,6 +56,10 @@ func (h *Handler) Start(chatID int64) error {
 func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
 	ctx := context.Background()
 
+	if update.CallbackQuery != nil && update.CallbackQuery.Data == flows.BackCallback {
+		return h.handleBack(update)
+	}
+
 	switch state {
 	case states.AdminCreateTariffWaitName:
 		return h.handleNameInput(ctx, update)
@@ -119,20 +130,15 @@ func (h *Handler) handleNameInput(ctx context.Context, update *tgbotapi.Update)
 }
 
 func (h *Handler) showPriceInput(chatID int64, tariffName string) error {
-	messageText := fmt.Sprintf("📝 *Создание тарифа: %s*\n\n"+
-		"💰 Введите цену тарифа в рублях:\n\n"+
+	return h.sendKeypad(chatID, priceInputText(tariffName), priceKeypad)
+}
+
+func priceInputText(tariffName string) string {
+	return fmt.Sprintf("📝 *Создание тарифа: %s*\n\n"+
+		"💰 Введите цену тарифа в рублях или выберите на клавиатуре:\n\n"+
 		"• От 0 до 10000 рублей (0 = бесплатный)\n"+
 		"• Можно с копейками (например: 199.99)",
 		tariffName)
-
-	keyboard := h.createCancelKeyboard()
-
-	msg := tgbotapi.NewMessage(chatID, messageText)
-	msg.ReplyMarkup = keyboard
-	msg.ParseMode = "Markdown"
-
-	_, err := h.bot.Send(msg)
-	return err
 }
 
 func (h *Handler) handlePriceInput(ctx context.Context, update *tgbotapi.Update) error {
@@ -143,13 +149,19 @@ func (h *Handler) handlePriceInput(ctx context.Context, update *tgbotapi.Update)
 		return h.handleCancel(ctx, update)
 	}
 
-	// Обрабатываем только текстовые сообщения
-	if update.Message == nil || update.Message.Text == "" {
-		return h.sendError(chatID, "Пожалуйста, введите цену числом")
+	// Получаем данные флоу
+	data, err := h.stateManager.GetCreateTariffData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
 	}
 
-	priceStr := strings.TrimSpace(update.Message.Text)
-	price, err := strconv.ParseFloat(priceStr, 64)
+	// Цена вводится текстом или на клавиатуре
+	priceStr, ok, err := h.readNumber(update, priceInputText(data.Name), priceKeypad)
+	if !ok {
+		return err
+	}
+
+	price, err := strconv.ParseFloat(strings.ReplaceAll(priceStr, ",", "."), 64)
 	if err != nil {
 		return h.sendError(chatID, "❌ Неверный формат цены. Введите число (например: 199 или 199.99)")
 	}
@@ -162,12 +174,6 @@ func (h *Handler) handlePriceInput(ctx context.Context, update *tgbotapi.Update)
 		return h.sendError(chatID, "❌ Цена слишком большая (максимум 10000 рублей)")
 	}
 
-	// Получаем данные флоу
-	data, err := h.stateManager.GetCreateTariffData(chatID)
-	if err != nil {
-		return h.sendError(chatID, "Ошибка получения данных флоу")
-	}
-
 	// Обновляем данные
 	data.Price = price
 
@@ -179,21 +185,16 @@ func (h *Handler) handlePriceInput(ctx context.Context, update *tgbotapi.Update)
 }
 
 func (h *Handler) showDurationInput(chatID int64, tariffName string, price float64) error {
-	messageText := fmt.Sprintf("📝 *Создание тарифа: %s*\n\n"+
+	return h.sendKeypad(chatID, durationInputText(tariffName, price), durationKeypad)
+}
+
+func durationInputText(tariffName string, price float64) string {
+	return fmt.Sprintf("📝 *Создание тарифа: %s*\n\n"+
 		"💰 *Цена:* %.2f ₽\n"+
-		"⏰ Введите продолжительность тарифа в днях:\n\n"+
+		"⏰ Введите продолжительность тарифа в днях или выберите на клавиатуре:\n\n"+
 		"• От 1 до 365 дней\n"+
 		"• Только целые числа",
 		tariffName, price)
-
-	keyboard := h.createCancelKeyboard()
-
-	msg := tgbotapi.NewMessage(chatID, messageText)
-	msg.ReplyMarkup = keyboard
-	msg.ParseMode = "Markdown"
-
-	_, err := h.bot.Send(msg)
-	return err
 }
 
 func (h *Handler) handleDurationInput(ctx context.Context, update *tgbotapi.Update) error {
@@ -204,12 +205,18 @@ func (h *Handler) handleDurationInput(ctx context.Context, update *tgbotapi.Upda
 		return h.handleCancel(ctx, update)
 	}
 
-	// Обрабатываем только текстовые сообщения
-	if update.Message == nil || update.Message.Text == "" {
-		return h.sendError(chatID, "Пожалуйста, введите количество дней числом")
+	// Получаем данные флоу
+	data, err := h.stateManager.GetCreateTariffData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	// Продолжительность вводится текстом или на клавиатуре
+	durationStr, ok, err := h.readNumber(update, durationInputText(data.Name, data.Price), durationKeypad)
+	if !ok {
+		return err
 	}
 
-	durationStr := strings.TrimSpace(update.Message.Text)
 	duration, err := strconv.Atoi(durationStr)
 	if err != nil {
 		return h.sendError(chatID, "❌ Неверный формат. Введите целое число дней")
@@ -223,12 +230,6 @@ func (h *Handler) handleDurationInput(ctx context.Context, update *tgbotapi.Upda
 		return h.sendError(chatID, "❌ Продолжительность слишком большая (максимум 365 дней)")
 	}
 
-	// Получаем данные флоу
-	data, err := h.stateManager.GetCreateTariffData(chatID)
-	if err != nil {
-		return h.sendError(chatID, "Ошибка получения данных флоу")
-	}
-
 	// Обновляем данные
 	data.DurationDays = duration
 
@@ -349,6 +350,37 @@ func (h *Handler) handleCancel(ctx context.Context, update *tgbotapi.Update) err
 	return err
 }
 
+// handleBack возвращает на предыдущий шаг и заново показывает его форму. Введенные значения сохраняются
+func (h *Handler) handleBack(update *tgbotapi.Update) error {
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	state, ok := h.stateManager.Back(chatID)
+	if !ok {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Это первый шаг"))
+		return nil
+	}
+	_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+	// Убираем кнопки со старого шага, чтобы нажатие на них не попало в другой шаг
+	_ = telegram.SafeEdit(h.bot, tgbotapi.NewEditMessageReplyMarkup(chatID, update.CallbackQuery.Message.MessageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}), "")
+
+	data, err := h.stateManager.GetCreateTariffData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	switch state {
+	case states.AdminCreateTariffWaitName:
+		return h.showNameInput(chatID)
+	case states.AdminCreateTariffWaitPrice:
+		return h.showPriceInput(chatID, data.Name)
+	case states.AdminCreateTariffWaitDuration:
+		return h.showDurationInput(chatID, data.Name, data.Price)
+	default:
+		return fmt.Errorf("no back step for create tariff state: %s", state)
+	}
+}
+
 func (h *Handler) createCancelKeyboard() tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
@@ -363,11 +395,47 @@ func (h *Handler) createConfirmationKeyboard() tgbotapi.InlineKeyboardMarkup {
 			tgbotapi.NewInlineKeyboardButtonData("✅ Создать тариф", "confirm_create_tariff"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
+			flows.BackButton(),
 			tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
 		),
 	)
 }
 
+// sendKeypad отправляет подсказку с цифровой клавиатурой
+func (h *Handler) sendKeypad(chatID int64, prompt string, cfg keypad.Config) error {
+	msg := tgbotapi.NewMessage(chatID, keypad.Text(prompt, ""))
+	msg.ReplyMarkup = keypad.Markup(cfg, "")
+	msg.ParseMode = "Markdown"
+
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+// readNumber возвращает число, введенное текстом или выбранное на клавиатуре.
+// Нажатия цифр только перерисовывают клавиатуру - тогда ok=false
+func (h *Handler) readNumber(update *tgbotapi.Update, prompt string, cfg keypad.Config) (value string, ok bool, err error) {
+	if update.CallbackQuery != nil {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+
+		action, value := keypad.ParseCallback(update.CallbackQuery.Data)
+		switch action {
+		case keypad.ActionSubmit:
+			return value, true, nil
+		case keypad.ActionEdit:
+			message := update.CallbackQuery.Message
+			edit := tgbotapi.NewEditMessageTextAndMarkup(message.Chat.ID, message.MessageID, keypad.Text(prompt, value), keypad.Markup(cfg, value))
+			edit.ParseMode = "Markdown"
+			return "", false, telegram.SafeEdit(h.bot, edit, "")
+		}
+	}
+
+	if update.Message == nil || update.Message.Text == "" {
+		return "", false, h.sendError(extractChatID(update), "Пожалуйста, введите число или выберите его на клавиатуре")
+	}
+
+	return strings.TrimSpace(update.Message.Text), true, nil
+}
+
 func (h *Handler) sendError(chatID int64, message string) error {
 	msg := tgbotapi.NewMessage(chatID, message)
 	_, err := h.bot.Send(msg)
@@ -0,0 +1,30 @@
+package editserver
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+)
+
+type (
+	botApi interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+		Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	}
+
+	stateManager interface {
+		SetState(chatID int64, state states.State, data any)
+		Clear(chatID int64)
+		GetEditServerData(chatID int64) (*flows.EditServerFlowData, error)
+	}
+
+	serverService interface {
+		GetServer(ctx context.Context, criteria servers.GetCriteria) (*servers.Server, error)
+		UpdateServer(ctx context.Context, criteria servers.GetCriteria, params servers.UpdateParams) (*servers.Server, error)
+		GetActiveUsersCount(ctx context.Context, serverID int64) (int, error)
+	}
+)
@@ -0,0 +1,418 @@
+package editserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/keypad"
+	"kurut-bot/internal/telegram/states"
+)
+
+type Handler struct {
+	bot           botApi
+	stateManager  stateManager
+	serverService serverService
+	logger        *slog.Logger
+}
+
+func NewHandler(
+	bot botApi,
+	sm stateManager,
+	ss serverService,
+	logger *slog.Logger,
+) *Handler {
+	return &Handler{
+		bot:           bot,
+		stateManager:  sm,
+		serverService: ss,
+		logger:        logger,
+	}
+}
+
+var (
+	maxUsersKeypad     = keypad.Config{Presets: []string{"100", "150", "200", "250", "300"}, MaxLen: 5}
+	currentUsersKeypad = keypad.Config{Presets: []string{"0"}, MaxLen: 5}
+)
+
+// Start открывает карточку сервера для редактирования на месте списка серверов (только для админов)
+func (h *Handler) Start(ctx context.Context, chatID int64, messageID int, serverID int64) error {
+	flowData := &flows.EditServerFlowData{
+		ServerID:  serverID,
+		MessageID: &messageID,
+	}
+	return h.showCard(ctx, chatID, flowData, "")
+}
+
+// Handle обрабатывает текущее состояние
+func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
+	ctx := context.Background()
+	chatID := extractChatID(update)
+
+	flowData, err := h.stateManager.GetEditServerData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	switch state {
+	case states.AdminEditServerWaitField:
+		return h.handleField(ctx, update, flowData)
+	case states.AdminEditServerWaitName:
+		return h.handleText(ctx, update, flowData, "name", parseName, func(v string) servers.UpdateParams {
+			return servers.UpdateParams{Name: &v}
+		}, "✅ Название изменено")
+	case states.AdminEditServerWaitURL:
+		return h.handleText(ctx, update, flowData, "ui_url", parseURL, func(v string) servers.UpdateParams {
+			return servers.UpdateParams{UIURL: &v}
+		}, "✅ URL панели изменен")
+	case states.AdminEditServerWaitPassword:
+		return h.handleText(ctx, update, flowData, "ui_password", parsePassword, func(v string) servers.UpdateParams {
+			return servers.UpdateParams{UIPassword: &v}
+		}, "✅ Пароль панели изменен")
+	case states.AdminEditServerWaitCluster:
+		return h.handleText(ctx, update, flowData, "cluster", parseCluster, func(v string) servers.UpdateParams {
+			return servers.UpdateParams{Cluster: &v}
+		}, "✅ Кластер изменен")
+	case states.AdminEditServerWaitMaxUsers:
+		return h.handleMaxUsers(ctx, update, flowData)
+	case states.AdminEditServerWaitCurrentUsers:
+		return h.handleCurrentUsers(ctx, update, flowData)
+	default:
+		return fmt.Errorf("unknown edit server state: %s", state)
+	}
+}
+
+// loadServer возвращает сервер флоу вместе с числом активных подписок на нем
+func (h *Handler) loadServer(ctx context.Context, chatID int64, flowData *flows.EditServerFlowData) (*servers.Server, int, bool) {
+	server, err := h.serverService.GetServer(ctx, servers.GetCriteria{ID: &flowData.ServerID})
+	if err != nil || server == nil {
+		h.logger.Error("Failed to get server for edit", "error", err, "server_id", flowData.ServerID)
+		h.stateManager.Clear(chatID)
+		_ = h.sendError(chatID, "❌ Сервер не найден")
+		return nil, 0, false
+	}
+
+	activeUsers, err := h.serverService.GetActiveUsersCount(ctx, server.ID)
+	if err != nil {
+		h.logger.Error("Failed to get active users count", "error", err, "server_id", server.ID)
+		activeUsers = server.CurrentUsers // Fallback на сохраненное значение
+	}
+	return server, activeUsers, true
+}
+
+// showCard показывает карточку сервера с кнопками изменения полей; notice - итог последнего изменения
+func (h *Handler) showCard(ctx context.Context, chatID int64, flowData *flows.EditServerFlowData, notice string) error {
+	server, activeUsers, ok := h.loadServer(ctx, chatID, flowData)
+	if !ok {
+		return nil
+	}
+
+	text := cardText(server, activeUsers)
+	if notice != "" {
+		text += "\n\n" + notice
+	}
+	text += "\n\nЧто изменить?"
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📝 Название", "esv_name"),
+			tgbotapi.NewInlineKeyboardButtonData("🌐 URL панели", "esv_url"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔑 Пароль панели", "esv_password"),
+			tgbotapi.NewInlineKeyboardButtonData("🏷 Кластер", "esv_cluster"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔢 Макс. пользователей", "esv_max_users"),
+			tgbotapi.NewInlineKeyboardButtonData("👥 Текущие", "esv_current_users"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Готово", "esv_done"),
+		),
+	)
+	return h.show(chatID, flowData, states.AdminEditServerWaitField, text, keyboard)
+}
+
+func cardText(server *servers.Server, activeUsers int) string {
+	status := "активный"
+	if server.Archived {
+		status = "в архиве"
+	}
+	return fmt.Sprintf("✏️ Редактирование сервера\n\n"+
+		"🖥 Название: %s\n"+
+		"🌐 URL панели: %s\n"+
+		"🔑 Пароль панели: %s\n"+
+		"🏷 Кластер: %s\n"+
+		"👥 Активных подписок: %d из %d (вручную указано текущих: %d)\n"+
+		"📦 Статус: %s",
+		server.Name, server.UIURL, maskPassword(server.UIPassword), servers.ClusterName(server.Cluster),
+		activeUsers, server.MaxUsers, server.CurrentUsers, status)
+}
+
+// handleField обрабатывает выбор поля на карточке сервера
+func (h *Handler) handleField(ctx context.Context, update *tgbotapi.Update, flowData *flows.EditServerFlowData) error {
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Выберите, что изменить, кнопками")
+	}
+	callbackQuery := update.CallbackQuery
+	chatID := callbackQuery.Message.Chat.ID
+	_ = h.answerCallback(callbackQuery.ID, "")
+
+	server, activeUsers, ok := h.loadServer(ctx, chatID, flowData)
+	if !ok {
+		return nil
+	}
+
+	switch callbackQuery.Data {
+	case "esv_name":
+		return h.showTextPrompt(chatID, flowData, states.AdminEditServerWaitName,
+			fmt.Sprintf("🖥 Сервер: %s\n\nВведите новое название сервера:", server.Name))
+	case "esv_url":
+		return h.showTextPrompt(chatID, flowData, states.AdminEditServerWaitURL,
+			fmt.Sprintf("🖥 Сервер: %s\n🌐 Текущий URL: %s\n\nВведите новый URL панели управления (например: https://wg.example.com):", server.Name, server.UIURL))
+	case "esv_password":
+		return h.showTextPrompt(chatID, flowData, states.AdminEditServerWaitPassword,
+			fmt.Sprintf("🖥 Сервер: %s\n\nВведите новый пароль от панели управления.\nСообщение с паролем будет удалено из чата.", server.Name))
+	case "esv_cluster":
+		return h.showTextPrompt(chatID, flowData, states.AdminEditServerWaitCluster,
+			fmt.Sprintf("🖥 Сервер: %s\n🏷 Текущий кластер: %s\n\n"+
+				"Введите название кластера (например: EU, Asia, Premium) или \"-\", чтобы убрать сервер из кластера.\n"+
+				"Тарифы с кластером выдают подписки только на серверах этого кластера.",
+				server.Name, servers.ClusterName(server.Cluster)))
+	case "esv_max_users":
+		return h.showKeypad(chatID, flowData, states.AdminEditServerWaitMaxUsers, maxUsersPrompt(server, activeUsers), maxUsersKeypad)
+	case "esv_current_users":
+		return h.showKeypad(chatID, flowData, states.AdminEditServerWaitCurrentUsers, currentUsersPrompt(server, activeUsers), currentUsersKeypad)
+	case "esv_done":
+		h.stateManager.Clear(chatID)
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("📡 К серверам", "srv_list"),
+			),
+		)
+		editMsg := tgbotapi.NewEditMessageText(chatID, callbackQuery.Message.MessageID, cardText(server, activeUsers))
+		editMsg.ReplyMarkup = &keyboard
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+
+	return nil
+}
+
+func maxUsersPrompt(server *servers.Server, activeUsers int) string {
+	return fmt.Sprintf("🖥 Сервер: %s\n\n"+
+		"🔢 Сейчас: %d, активных подписок: %d\n"+
+		"Введите новое максимальное количество пользователей:",
+		server.Name, server.MaxUsers, activeUsers)
+}
+
+func currentUsersPrompt(server *servers.Server, activeUsers int) string {
+	return fmt.Sprintf("🖥 Сервер: %s\n\n"+
+		"👥 Указано вручную: %d, активных подписок в боте: %d\n"+
+		"Введите текущее количество пользователей на сервере:",
+		server.Name, server.CurrentUsers, activeUsers)
+}
+
+// handleText сохраняет текстовое поле сервера: название, URL, пароль панели или кластер
+func (h *Handler) handleText(
+	ctx context.Context,
+	update *tgbotapi.Update,
+	flowData *flows.EditServerFlowData,
+	field string,
+	parse func(string) (string, string),
+	params func(string) servers.UpdateParams,
+	notice string,
+) error {
+	chatID := extractChatID(update)
+
+	if update.CallbackQuery != nil {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		if update.CallbackQuery.Data == "esv_back" {
+			return h.showCard(ctx, chatID, flowData, "")
+		}
+		return nil
+	}
+	if update.Message == nil || update.Message.Text == "" {
+		return h.sendError(chatID, "Пожалуйста, введите значение текстом")
+	}
+
+	// Пароль панели не оставляем в истории чата
+	if field == "ui_password" {
+		if _, err := h.bot.Request(tgbotapi.NewDeleteMessage(chatID, update.Message.MessageID)); err != nil {
+			h.logger.Warn("Failed to delete message with server password", "error", err, "server_id", flowData.ServerID)
+		}
+	}
+
+	value, errText := parse(update.Message.Text)
+	if errText != "" {
+		return h.sendError(chatID, errText)
+	}
+
+	if _, err := h.serverService.UpdateServer(ctx, servers.GetCriteria{ID: &flowData.ServerID}, params(value)); err != nil {
+		h.logger.Error("Failed to update server", "error", err, "server_id", flowData.ServerID, "field", field)
+		return h.sendError(chatID, "❌ Ошибка изменения сервера")
+	}
+
+	// Значение пароля в лог не пишем
+	logValue := value
+	if field == "ui_password" {
+		logValue = maskPassword(value)
+	}
+	h.logChange(chatID, flowData.ServerID, field, logValue)
+
+	// Значение пришло сообщением - карточку отправляем заново под ним
+	flowData.MessageID = nil
+	return h.showCard(ctx, chatID, flowData, notice)
+}
+
+// handleMaxUsers сохраняет новое максимальное количество пользователей
+func (h *Handler) handleMaxUsers(ctx context.Context, update *tgbotapi.Update, flowData *flows.EditServerFlowData) error {
+	chatID := extractChatID(update)
+
+	server, activeUsers, ok := h.loadServer(ctx, chatID, flowData)
+	if !ok {
+		return nil
+	}
+
+	input, ok, err := h.readNumber(update, flowData, maxUsersPrompt(server, activeUsers), maxUsersKeypad)
+	if !ok {
+		return err
+	}
+	maxUsers, errText := parseMaxUsers(input, activeUsers)
+	if errText != "" {
+		return h.sendError(chatID, errText)
+	}
+
+	if _, err := h.serverService.UpdateServer(ctx, servers.GetCriteria{ID: &flowData.ServerID}, servers.UpdateParams{MaxUsers: &maxUsers}); err != nil {
+		h.logger.Error("Failed to update server max users", "error", err, "server_id", flowData.ServerID)
+		return h.sendError(chatID, "❌ Ошибка изменения сервера")
+	}
+	h.logChange(chatID, flowData.ServerID, "max_users", maxUsers)
+
+	return h.showCard(ctx, chatID, flowData, "✅ Максимальное количество пользователей изменено")
+}
+
+// handleCurrentUsers сохраняет указанное вручную текущее количество пользователей
+func (h *Handler) handleCurrentUsers(ctx context.Context, update *tgbotapi.Update, flowData *flows.EditServerFlowData) error {
+	chatID := extractChatID(update)
+
+	server, activeUsers, ok := h.loadServer(ctx, chatID, flowData)
+	if !ok {
+		return nil
+	}
+
+	input, ok, err := h.readNumber(update, flowData, currentUsersPrompt(server, activeUsers), currentUsersKeypad)
+	if !ok {
+		return err
+	}
+	currentUsers, errText := parseCurrentUsers(input)
+	if errText != "" {
+		return h.sendError(chatID, errText)
+	}
+
+	if _, err := h.serverService.UpdateServer(ctx, servers.GetCriteria{ID: &flowData.ServerID}, servers.UpdateParams{CurrentUsers: &currentUsers}); err != nil {
+		h.logger.Error("Failed to update server current users", "error", err, "server_id", flowData.ServerID)
+		return h.sendError(chatID, "❌ Ошибка изменения сервера")
+	}
+	h.logChange(chatID, flowData.ServerID, "current_users", currentUsers)
+
+	return h.showCard(ctx, chatID, flowData, "✅ Текущее количество пользователей изменено")
+}
+
+func (h *Handler) logChange(chatID, serverID int64, field string, value any) {
+	h.logger.Info("Server changed",
+		"audit", true,
+		"admin_chat_id", chatID,
+		"server_id", serverID,
+		"field", field,
+		"value", value,
+	)
+}
+
+// show редактирует карточку сервера или отправляет новую и переводит флоу в состояние state
+func (h *Handler) show(chatID int64, flowData *flows.EditServerFlowData, state states.State, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	h.stateManager.SetState(chatID, state, flowData)
+
+	if flowData.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
+		editMsg.ReplyMarkup = &keyboard
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	sentMsg, err := h.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+	flowData.MessageID = &sentMsg.MessageID
+	return nil
+}
+
+// showTextPrompt показывает на карточке подсказку для ввода текста с кнопкой возврата
+func (h *Handler) showTextPrompt(chatID int64, flowData *flows.EditServerFlowData, state states.State, prompt string) error {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", "esv_back"),
+		),
+	)
+	return h.show(chatID, flowData, state, prompt, keyboard)
+}
+
+// showKeypad показывает на карточке подсказку с цифровой клавиатурой
+func (h *Handler) showKeypad(chatID int64, flowData *flows.EditServerFlowData, state states.State, prompt string, cfg keypad.Config) error {
+	return h.show(chatID, flowData, state, keypad.Text(prompt, ""), keypad.Markup(cfg, ""))
+}
+
+// readNumber возвращает число, введенное текстом или выбранное на клавиатуре.
+// Нажатия цифр только перерисовывают клавиатуру - тогда ok=false.
+// Если число пришло сообщением, карточка дальше отправляется заново под ним
+func (h *Handler) readNumber(update *tgbotapi.Update, flowData *flows.EditServerFlowData, prompt string, cfg keypad.Config) (value string, ok bool, err error) {
+	if update.CallbackQuery != nil {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+
+		action, value := keypad.ParseCallback(update.CallbackQuery.Data)
+		switch action {
+		case keypad.ActionSubmit:
+			return value, true, nil
+		case keypad.ActionEdit:
+			message := update.CallbackQuery.Message
+			edit := tgbotapi.NewEditMessageTextAndMarkup(message.Chat.ID, message.MessageID, keypad.Text(prompt, value), keypad.Markup(cfg, value))
+			return "", false, telegram.SafeEdit(h.bot, edit, "")
+		}
+		return "", false, nil
+	}
+
+	if update.Message == nil || update.Message.Text == "" {
+		return "", false, h.sendError(extractChatID(update), "Пожалуйста, введите число или выберите его на клавиатуре")
+	}
+
+	flowData.MessageID = nil
+	return update.Message.Text, true, nil
+}
+
+func (h *Handler) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := h.bot.Request(callback)
+	return err
+}
+
+func (h *Handler) sendError(chatID int64, message string) error {
+	msg := tgbotapi.NewMessage(chatID, message)
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+func extractChatID(update *tgbotapi.Update) int64 {
+	if update.Message != nil {
+		return update.Message.Chat.ID
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		return update.CallbackQuery.Message.Chat.ID
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package editserver
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"kurut-bot/internal/stories/servers"
+)
+
+// Функции parse* возвращают значение и текст ошибки для админа; пустой текст - значение корректно
+
+func parseName(input string) (string, string) {
+	name := strings.TrimSpace(input)
+	if name == "" {
+		return "", "❌ Название не может быть пустым"
+	}
+	if utf8.RuneCountInString(name) > 100 {
+		return "", "❌ Название слишком длинное (максимум 100 символов)"
+	}
+	return name, ""
+}
+
+func parseURL(input string) (string, string) {
+	raw := strings.TrimSpace(input)
+	if !strings.HasPrefix(raw, "http://") && !strings.HasPrefix(raw, "https://") {
+		return "", "❌ URL должен начинаться с http:// или https://"
+	}
+	if u, err := url.Parse(raw); err != nil || u.Host == "" {
+		return "", "❌ Неверный URL (например: https://wg.example.com)"
+	}
+	return raw, ""
+}
+
+func parsePassword(input string) (string, string) {
+	password := strings.TrimSpace(input)
+	if password == "" {
+		return "", "❌ Пароль не может быть пустым"
+	}
+	return password, ""
+}
+
+// parseCluster проверяет название кластера; "-" убирает сервер из кластера
+func parseCluster(input string) (string, string) {
+	cluster, ok := servers.NormalizeCluster(input)
+	if !ok {
+		return "", "❌ Название кластера: до 32 символов, только буквы, цифры, пробел и дефис"
+	}
+	return cluster, ""
+}
+
+func parseCurrentUsers(input string) (int, string) {
+	count, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil {
+		return 0, "❌ Неверный формат. Введите целое число"
+	}
+	if count < 0 {
+		return 0, "❌ Количество пользователей не может быть отрицательным"
+	}
+	return count, ""
+}
+
+// parseMaxUsers проверяет лимит: больше 0 и не меньше числа активных подписок на сервере
+func parseMaxUsers(input string, activeUsers int) (int, string) {
+	maxUsers, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil {
+		return 0, "❌ Неверный формат. Введите целое число"
+	}
+	if maxUsers < 1 {
+		return 0, "❌ Максимальное количество должно быть больше 0"
+	}
+	if maxUsers < activeUsers {
+		return 0, fmt.Sprintf("❌ Максимальное количество (%d) не может быть меньше числа активных подписок (%d)", maxUsers, activeUsers)
+	}
+	return maxUsers, ""
+}
+
+// maskPassword скрывает пароль панели на карточке, оставляя два последних символа для сверки
+func maskPassword(password string) string {
+	runes := []rune(password)
+	if len(runes) <= 4 {
+		return "••••"
+	}
+	return "••••" + string(runes[len(runes)-2:])
+}
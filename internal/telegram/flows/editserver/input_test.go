@@ -0,0 +1,67 @@
+package editserver
+
+import "testing"
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{" https://wg.example.com ", "https://wg.example.com", true},
+		{"http://10.0.0.1:51821", "http://10.0.0.1:51821", true},
+		{"wg.example.com", "", false},
+		{"https://", "", false},
+		{"ftp://wg.example.com", "", false},
+	}
+	for _, tt := range tests {
+		got, msg := parseURL(tt.input)
+		if (msg == "") != tt.ok || got != tt.want {
+			t.Errorf("parseURL(%q) = %q, %q, want %q, ok=%v", tt.input, got, msg, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseMaxUsers(t *testing.T) {
+	tests := []struct {
+		input  string
+		active int
+		want   int
+		ok     bool
+	}{
+		{"200", 150, 200, true},
+		{"150", 150, 150, true},
+		{"100", 150, 0, false},
+		{"0", 0, 0, false},
+		{"много", 0, 0, false},
+	}
+	for _, tt := range tests {
+		got, msg := parseMaxUsers(tt.input, tt.active)
+		if (msg == "") != tt.ok || got != tt.want {
+			t.Errorf("parseMaxUsers(%q, %d) = %d, %q, want %d, ok=%v", tt.input, tt.active, got, msg, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseCurrentUsers(t *testing.T) {
+	if got, msg := parseCurrentUsers("0"); msg != "" || got != 0 {
+		t.Errorf("parseCurrentUsers(0) = %d, %q", got, msg)
+	}
+	if _, msg := parseCurrentUsers("-1"); msg == "" {
+		t.Error("parseCurrentUsers(-1) accepted a negative count")
+	}
+}
+
+func TestMaskPassword(t *testing.T) {
+	tests := map[string]string{
+		"s3cretPass": "••••ss",
+		"abcd":       "••••",
+		"":           "••••",
+		"пароль":     "••••ль",
+	}
+	for input, want := range tests {
+		if got := maskPassword(input); got != want {
+			t.Errorf("maskPassword(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
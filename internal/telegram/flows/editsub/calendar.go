@@ -0,0 +1,71 @@
+package editsub
+
+import (
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	calendarMonthLayout = "2006-01"
+	calendarDayLayout   = "2006-01-02"
+	// calendarNoop - кнопки-подписи календаря (заголовок, дни недели, пустые клетки)
+	calendarNoop = "esub_noop"
+)
+
+var calendarMonths = [...]string{"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь",
+	"Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь"}
+
+// calendarKeyboard - календарь месяца month для выбора даты окончания: esub_day:ГГГГ-ММ-ДД выбирает день,
+// esub_cal:ГГГГ-ММ листает месяцы. Дни раньше minDate (если задана) не выбираются. Неделя начинается с понедельника
+func calendarKeyboard(month time.Time, minDate *time.Time) tgbotapi.InlineKeyboardMarkup {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("«", "esub_cal:"+first.AddDate(0, -1, 0).Format(calendarMonthLayout)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s %d", calendarMonths[first.Month()-1], first.Year()), calendarNoop),
+			tgbotapi.NewInlineKeyboardButtonData("»", "esub_cal:"+first.AddDate(0, 1, 0).Format(calendarMonthLayout)),
+		),
+	}
+
+	var weekdays []tgbotapi.InlineKeyboardButton
+	for _, day := range []string{"Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"} {
+		weekdays = append(weekdays, tgbotapi.NewInlineKeyboardButtonData(day, calendarNoop))
+	}
+	rows = append(rows, weekdays)
+
+	var minDay time.Time
+	if minDate != nil {
+		minDay = time.Date(minDate.Year(), minDate.Month(), minDate.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	// Пустые клетки до первого числа: Weekday() считает с воскресенья
+	week := make([]tgbotapi.InlineKeyboardButton, 0, 7)
+	for range (int(first.Weekday()) + 6) % 7 {
+		week = append(week, tgbotapi.NewInlineKeyboardButtonData(" ", calendarNoop))
+	}
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		if minDate != nil && day.Before(minDay) {
+			week = append(week, tgbotapi.NewInlineKeyboardButtonData("·", calendarNoop))
+		} else {
+			week = append(week, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d", day.Day()), "esub_day:"+day.Format(calendarDayLayout)))
+		}
+		if len(week) == 7 {
+			rows = append(rows, week)
+			week = make([]tgbotapi.InlineKeyboardButton, 0, 7)
+		}
+	}
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, tgbotapi.NewInlineKeyboardButtonData(" ", calendarNoop))
+		}
+		rows = append(rows, week)
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,66 @@
+package editsub
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarKeyboard(t *testing.T) {
+	// Октябрь 2026 начинается с четверга
+	month := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	minDate := time.Date(2026, 10, 5, 15, 0, 0, 0, time.UTC)
+
+	keyboard := calendarKeyboard(month, &minDate)
+	rows := keyboard.InlineKeyboard
+
+	header := rows[0]
+	if *header[0].CallbackData != "esub_cal:2026-09" || *header[2].CallbackData != "esub_cal:2026-11" {
+		t.Errorf("navigation = %s / %s", *header[0].CallbackData, *header[2].CallbackData)
+	}
+	if header[1].Text != "Октябрь 2026" {
+		t.Errorf("title = %q", header[1].Text)
+	}
+
+	weeks := rows[2 : len(rows)-1]
+	if weeks[0][3].Text != "·" || weeks[0][2].Text != " " {
+		t.Errorf("first week = %q %q, want blank before Thursday 1st", weeks[0][2].Text, weeks[0][3].Text)
+	}
+
+	var days, disabled int
+	for _, week := range weeks {
+		if len(week) != 7 {
+			t.Fatalf("week has %d buttons", len(week))
+		}
+		for _, b := range week {
+			switch {
+			case strings.HasPrefix(*b.CallbackData, "esub_day:"):
+				days++
+			case b.Text == "·":
+				disabled++
+			}
+		}
+	}
+	if days != 27 || disabled != 4 {
+		t.Errorf("selectable days = %d, disabled = %d, want 27 and 4", days, disabled)
+	}
+	if got := *weeks[0][4].CallbackData; got != calendarNoop {
+		t.Errorf("2nd before min date = %s", got)
+	}
+	if got := *weeks[1][0].CallbackData; got != "esub_day:2026-10-05" {
+		t.Errorf("first selectable day = %s", got)
+	}
+
+	// Без ограничения (админ) выбираются все дни месяца
+	days = 0
+	for _, week := range calendarKeyboard(month, nil).InlineKeyboard {
+		for _, b := range week {
+			if strings.HasPrefix(*b.CallbackData, "esub_day:") {
+				days++
+			}
+		}
+	}
+	if days != 31 {
+		t.Errorf("selectable days without min date = %d, want 31", days)
+	}
+}
@@ -0,0 +1,40 @@
+package editsub
+
+import (
+	"context"
+
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type (
+	botApi interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+		Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	}
+
+	stateManager interface {
+		Clear(chatID int64)
+		GetEditSubData(chatID int64) (*flows.EditSubFlowData, error)
+		SetState(chatID int64, state states.State, data any)
+	}
+
+	subscriptionStorage interface {
+		GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error)
+		ListSubscriptionsPage(ctx context.Context, criteria subs.PageCriteria) ([]storage.SubscriptionDetails, error)
+		UpdateSubscription(ctx context.Context, criteria subs.GetCriteria, params subs.UpdateParams) (*subs.Subscription, error)
+		ChangeSubscriptionWhatsApp(ctx context.Context, change subs.PhoneChange) error
+		MoveSubscriptionServer(ctx context.Context, subscriptionID int64, fromServerID *int64, toServerID int64) (bool, error)
+	}
+
+	serverService interface {
+		ListServers(ctx context.Context, criteria servers.ListCriteria) ([]*servers.Server, error)
+		GetServer(ctx context.Context, criteria servers.GetCriteria) (*servers.Server, error)
+		GetActiveUsersCount(ctx context.Context, serverID int64) (int, error)
+	}
+)
@@ -0,0 +1,776 @@
+package editsub
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/flows/createsubforclient"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+)
+
+// pageSize - сколько подписок показывать на одной странице выбора
+const pageSize = 10
+
+type Handler struct {
+	bot                 botApi
+	stateManager        stateManager
+	subscriptionStorage subscriptionStorage
+	serverService       serverService
+	logger              *slog.Logger
+}
+
+func NewHandler(
+	bot botApi,
+	sm stateManager,
+	storage subscriptionStorage,
+	ss serverService,
+	logger *slog.Logger,
+) *Handler {
+	return &Handler{
+		bot:                 bot,
+		stateManager:        sm,
+		subscriptionStorage: storage,
+		serverService:       ss,
+		logger:              logger,
+	}
+}
+
+// Start начинает flow редактирования подписки: показывает активные подписки ассистента
+func (h *Handler) Start(ctx context.Context, assistantTelegramID, chatID int64, isAdmin bool) error {
+	flowData := &flows.EditSubFlowData{
+		AssistantTelegramID: assistantTelegramID,
+		IsAdmin:             isAdmin,
+	}
+	h.stateManager.SetState(chatID, states.AssistantEditSubWaitSelection, flowData)
+
+	return h.showSubscriptions(ctx, chatID, flowData, nil)
+}
+
+// StartPhoneChange обрабатывает sub_phone:ID из карточки подписки и сразу спрашивает новый номер клиента
+func (h *Handler) StartPhoneChange(ctx context.Context, viewerTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	return h.startFromCard(ctx, viewerTelegramID, isAdmin, callbackQuery, "sub_phone:", flows.EditSubFieldWhatsApp)
+}
+
+// StartExpiryChange обрабатывает sub_expiry:ID из карточки подписки и сразу показывает календарь даты окончания
+func (h *Handler) StartExpiryChange(ctx context.Context, viewerTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	return h.startFromCard(ctx, viewerTelegramID, isAdmin, callbackQuery, "sub_expiry:", flows.EditSubFieldExpiresAt)
+}
+
+// StartServerChange обрабатывает sub_server:ID из карточки подписки и сразу показывает серверы для переноса
+func (h *Handler) StartServerChange(ctx context.Context, viewerTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	return h.startFromCard(ctx, viewerTelegramID, isAdmin, callbackQuery, "sub_server:", flows.EditSubFieldServer)
+}
+
+// startFromCard начинает флоу с выбранной подписки и поля, минуя список подписок
+func (h *Handler) startFromCard(ctx context.Context, viewerTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery, prefix string, field flows.EditSubField) error {
+	subID, err := strconv.ParseInt(strings.TrimPrefix(callbackQuery.Data, prefix), 10, 64)
+	if err != nil {
+		return h.answerCallback(callbackQuery.ID, "Неверный ID подписки")
+	}
+
+	flowData := &flows.EditSubFlowData{
+		AssistantTelegramID: viewerTelegramID,
+		IsAdmin:             isAdmin,
+		SubscriptionID:      subID,
+		Field:               field,
+	}
+	sub, err := h.loadSubscription(ctx, flowData, subID)
+	if err != nil {
+		return h.answerCallback(callbackQuery.ID, err.Error())
+	}
+	_ = h.answerCallback(callbackQuery.ID, "")
+
+	chatID := callbackQuery.Message.Chat.ID
+	switch field {
+	case flows.EditSubFieldExpiresAt:
+		return h.showExpiryPicker(chatID, flowData, sub, nil)
+	case flows.EditSubFieldServer:
+		return h.showServers(ctx, chatID, flowData)
+	}
+
+	text := fmt.Sprintf("✏️ Подписка #%d\n\n📱 Сейчас: %s\nВведите новый номер WhatsApp клиента (например: +996555123456):",
+		sub.ID, valueOrDash(sub.ClientWhatsApp))
+	return h.showMessage(chatID, flowData, states.AssistantEditSubWaitValue, text, cancelKeyboard())
+}
+
+// Handle обрабатывает текущее состояние
+func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
+	ctx := context.Background()
+
+	switch state {
+	case states.AssistantEditSubWaitSelection:
+		return h.handleSelection(ctx, update)
+	case states.AssistantEditSubWaitField:
+		return h.handleField(ctx, update)
+	case states.AssistantEditSubWaitValue:
+		return h.handleValue(ctx, update)
+	case states.AssistantEditSubWaitServer:
+		return h.handleServer(ctx, update)
+	case states.AssistantEditSubWaitConfirm:
+		return h.handleConfirm(ctx, update)
+	default:
+		return fmt.Errorf("unknown state: %s", state)
+	}
+}
+
+// showSubscriptions показывает страницу активных подписок ассистента (курсор beforeID - как в Mini App)
+func (h *Handler) showSubscriptions(ctx context.Context, chatID int64, flowData *flows.EditSubFlowData, beforeID *int64) error {
+	page, err := h.subscriptionStorage.ListSubscriptionsPage(ctx, subs.PageCriteria{
+		CreatedByTelegramID: flowData.AssistantTelegramID,
+		Status:              []subs.Status{subs.StatusActive},
+		BeforeID:            beforeID,
+		Limit:               pageSize,
+	})
+	if err != nil {
+		h.logger.Error("Failed to list subscriptions for edit", "error", err)
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ Ошибка загрузки подписок")
+	}
+
+	text := "✏️ Выберите подписку для изменения"
+	if flowData.IsAdmin {
+		text += "\n\nИли отправьте номер подписки (ID), чтобы изменить чужую"
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, details := range page {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(subscriptionLabel(details.Subscription), fmt.Sprintf("esub_pick:%d", details.Subscription.ID)),
+		))
+	}
+	if len(page) == pageSize {
+		lastID := page[len(page)-1].Subscription.ID
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➡️ Ещё", fmt.Sprintf("esub_more:%d", lastID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+	))
+
+	if len(page) == 0 {
+		if beforeID == nil && !flowData.IsAdmin {
+			h.stateManager.Clear(chatID)
+			return h.sendError(chatID, "У вас нет активных подписок")
+		}
+		text = "Больше активных подписок нет"
+		if flowData.IsAdmin {
+			text += "\n\nОтправьте номер подписки (ID), чтобы изменить чужую"
+		}
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return h.showMessage(chatID, flowData, states.AssistantEditSubWaitSelection, text, &keyboard)
+}
+
+// subscriptionLabel - текст кнопки подписки: "#12 · 996555123456 · до 01.02.2026"
+func subscriptionLabel(sub *subs.Subscription) string {
+	label := fmt.Sprintf("#%d", sub.ID)
+	if sub.ClientWhatsApp != nil {
+		label += " · " + *sub.ClientWhatsApp
+	}
+	if sub.ExpiresAt != nil {
+		label += " · до " + sub.ExpiresAt.Format("02.01.2006")
+	}
+	return label
+}
+
+// handleSelection обрабатывает выбор подписки кнопкой или вводом ID (для админа)
+func (h *Handler) handleSelection(ctx context.Context, update *tgbotapi.Update) error {
+	chatID := extractChatID(update)
+
+	flowData, err := h.stateManager.GetEditSubData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	if update.CallbackQuery != nil {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+
+		action, id, ok := parseCallback(update.CallbackQuery.Data)
+		if !ok {
+			return nil
+		}
+		switch action {
+		case "esub_more":
+			return h.showSubscriptions(ctx, chatID, flowData, &id)
+		case "esub_pick":
+			return h.selectSubscription(ctx, chatID, flowData, id)
+		}
+		return nil
+	}
+
+	if update.Message == nil || update.Message.Text == "" {
+		return h.sendError(chatID, "Выберите подписку из списка")
+	}
+
+	subID, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(update.Message.Text), "#"), 10, 64)
+	if err != nil {
+		return h.sendError(chatID, "❌ Отправьте номер подписки цифрами или выберите её из списка")
+	}
+	// Новое сообщение от пользователя - дальше отвечаем новым сообщением
+	flowData.MessageID = nil
+	return h.selectSubscription(ctx, chatID, flowData, subID)
+}
+
+// selectSubscription проверяет доступ к подписке и показывает что можно изменить
+func (h *Handler) selectSubscription(ctx context.Context, chatID int64, flowData *flows.EditSubFlowData, subID int64) error {
+	sub, err := h.loadSubscription(ctx, flowData, subID)
+	if err != nil {
+		return h.sendError(chatID, "❌ "+err.Error())
+	}
+
+	flowData.SubscriptionID = sub.ID
+
+	text := fmt.Sprintf("✏️ *Подписка #%d*\n\n%s\n\nЧто изменить?", sub.ID, h.describeSubscription(ctx, sub))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📱 Номер WhatsApp", "esub_field:"+string(flows.EditSubFieldWhatsApp)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📅 Дату окончания", "esub_field:"+string(flows.EditSubFieldExpiresAt)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🖥 Сервер", "esub_field:"+string(flows.EditSubFieldServer)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+		),
+	)
+
+	return h.showMarkdown(chatID, flowData, states.AssistantEditSubWaitField, text, &keyboard)
+}
+
+// loadSubscription загружает активную подписку; ассистент может менять только свои
+func (h *Handler) loadSubscription(ctx context.Context, flowData *flows.EditSubFlowData, subID int64) (*subs.Subscription, error) {
+	sub, err := h.subscriptionStorage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{subID}})
+	if err != nil {
+		h.logger.Error("Failed to get subscription for edit", "error", err, "sub_id", subID)
+		return nil, fmt.Errorf("ошибка получения подписки")
+	}
+	if sub == nil {
+		return nil, fmt.Errorf("подписка #%d не найдена", subID)
+	}
+	if !flowData.IsAdmin && (sub.CreatedByTelegramID == nil || *sub.CreatedByTelegramID != flowData.AssistantTelegramID) {
+		return nil, fmt.Errorf("подписка #%d создана другим ассистентом", subID)
+	}
+	if sub.Status != subs.StatusActive {
+		return nil, fmt.Errorf("изменять можно только активные подписки")
+	}
+	return sub, nil
+}
+
+// describeSubscription - текущие значения изменяемых полей
+func (h *Handler) describeSubscription(ctx context.Context, sub *subs.Subscription) string {
+	return fmt.Sprintf("📱 WhatsApp: %s\n📅 До: %s\n🖥 Сервер: %s",
+		valueOrDash(sub.ClientWhatsApp), formatExpiry(sub.ExpiresAt), h.serverName(ctx, sub.ServerID))
+}
+
+// handleField обрабатывает выбор поля для изменения
+func (h *Handler) handleField(ctx context.Context, update *tgbotapi.Update) error {
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Выберите что изменить кнопками")
+	}
+	chatID := update.CallbackQuery.Message.Chat.ID
+	_ = h.answerCallback(update.CallbackQuery.ID, "")
+
+	flowData, err := h.stateManager.GetEditSubData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	field, ok := strings.CutPrefix(update.CallbackQuery.Data, "esub_field:")
+	if !ok {
+		return nil
+	}
+	flowData.Field = flows.EditSubField(field)
+
+	switch flowData.Field {
+	case flows.EditSubFieldWhatsApp:
+		return h.showMessage(chatID, flowData, states.AssistantEditSubWaitValue,
+			"📱 Введите новый номер WhatsApp клиента (например: +996555123456):", cancelKeyboard())
+	case flows.EditSubFieldExpiresAt:
+		sub, err := h.loadSubscription(ctx, flowData, flowData.SubscriptionID)
+		if err != nil {
+			return h.sendError(chatID, "❌ "+err.Error())
+		}
+		return h.showExpiryPicker(chatID, flowData, sub, nil)
+	case flows.EditSubFieldServer:
+		return h.showServers(ctx, chatID, flowData)
+	default:
+		return nil
+	}
+}
+
+// showServers показывает неархивные серверы со свободными местами
+func (h *Handler) showServers(ctx context.Context, chatID int64, flowData *flows.EditSubFlowData) error {
+	sub, err := h.loadSubscription(ctx, flowData, flowData.SubscriptionID)
+	if err != nil {
+		return h.sendError(chatID, "❌ "+err.Error())
+	}
+
+	archived := false
+	list, err := h.serverService.ListServers(ctx, servers.ListCriteria{Archived: &archived})
+	if err != nil {
+		h.logger.Error("Failed to list servers for edit", "error", err)
+		return h.sendError(chatID, "❌ Ошибка получения списка серверов")
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, srv := range list {
+		if sub.ServerID != nil && srv.ID == *sub.ServerID {
+			continue
+		}
+		activeCount, err := h.serverService.GetActiveUsersCount(ctx, srv.ID)
+		if err != nil || activeCount >= srv.MaxUsers {
+			continue
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🖥 %s (%d/%d)", srv.Name, activeCount, srv.MaxUsers), fmt.Sprintf("esub_srv:%d", srv.ID)),
+		))
+	}
+
+	if len(rows) == 0 {
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ Нет других серверов со свободными местами")
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+	))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return h.showMessage(chatID, flowData, states.AssistantEditSubWaitServer, "🖥 Выберите новый сервер:", &keyboard)
+}
+
+// showExpiryPicker показывает календарь даты окончания на месяц month (nil - месяц текущей даты окончания).
+// Ассистент выбирает только будущие дни, админ - любые
+func (h *Handler) showExpiryPicker(chatID int64, flowData *flows.EditSubFlowData, sub *subs.Subscription, month *time.Time) error {
+	now := time.Now()
+	if month == nil {
+		month = &now
+		if sub.ExpiresAt != nil {
+			month = sub.ExpiresAt
+		}
+	}
+	var minDate *time.Time
+	if !flowData.IsAdmin {
+		minDate = &now
+	}
+
+	text := fmt.Sprintf("📅 Подписка #%d, сейчас до %s\n\nВыберите новую дату окончания в календаре или отправьте ее в формате ДД.ММ.ГГГГ",
+		sub.ID, formatExpiry(sub.ExpiresAt))
+	if flowData.IsAdmin {
+		text += "\n\nПрошедшая дата тоже подойдет: подписка истечет при ближайшей проверке"
+	}
+	keyboard := calendarKeyboard(*month, minDate)
+	return h.showMessage(chatID, flowData, states.AssistantEditSubWaitValue, text, &keyboard)
+}
+
+// handleCalendar листает календарь (esub_cal:ГГГГ-ММ) и принимает выбранный день (esub_day:ГГГГ-ММ-ДД)
+func (h *Handler) handleCalendar(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery, flowData *flows.EditSubFlowData) error {
+	chatID := callbackQuery.Message.Chat.ID
+	_ = h.answerCallback(callbackQuery.ID, "")
+	if flowData.Field != flows.EditSubFieldExpiresAt || callbackQuery.Data == calendarNoop {
+		return nil
+	}
+
+	sub, err := h.loadSubscription(ctx, flowData, flowData.SubscriptionID)
+	if err != nil {
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ "+err.Error())
+	}
+
+	if rawMonth, ok := strings.CutPrefix(callbackQuery.Data, "esub_cal:"); ok {
+		month, err := time.Parse(calendarMonthLayout, rawMonth)
+		if err != nil {
+			return nil
+		}
+		return h.showExpiryPicker(chatID, flowData, sub, &month)
+	}
+
+	rawDay, ok := strings.CutPrefix(callbackQuery.Data, "esub_day:")
+	if !ok {
+		return nil
+	}
+	day, err := time.Parse(calendarDayLayout, rawDay)
+	if err != nil {
+		return nil
+	}
+	if err := setExpiry(flowData, sub, day.Format("02.01.2006")); err != nil {
+		return h.sendError(chatID, "❌ "+err.Error())
+	}
+	return h.showConfirm(chatID, flowData)
+}
+
+// setExpiry проверяет новую дату окончания и запоминает ее для подтверждения
+func setExpiry(flowData *flows.EditSubFlowData, sub *subs.Subscription, text string) error {
+	expiresAt, err := ParseExpiryDate(text, sub.ExpiresAt, time.Now(), flowData.IsAdmin)
+	if err != nil {
+		return err
+	}
+	flowData.NewExpiresAt = &expiresAt
+	flowData.OldValue = formatExpiry(sub.ExpiresAt)
+	flowData.NewValue = formatExpiry(&expiresAt)
+	return nil
+}
+
+// handleValue обрабатывает ввод нового номера или даты окончания (текстом или в календаре)
+func (h *Handler) handleValue(ctx context.Context, update *tgbotapi.Update) error {
+	chatID := extractChatID(update)
+
+	flowData, err := h.stateManager.GetEditSubData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	if update.CallbackQuery != nil {
+		return h.handleCalendar(ctx, update.CallbackQuery, flowData)
+	}
+	if update.Message == nil || update.Message.Text == "" {
+		return h.sendError(chatID, "Пожалуйста, введите значение текстом")
+	}
+
+	sub, err := h.loadSubscription(ctx, flowData, flowData.SubscriptionID)
+	if err != nil {
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ "+err.Error())
+	}
+
+	switch flowData.Field {
+	case flows.EditSubFieldWhatsApp:
+		whatsapp := createsubforclient.NormalizePhone(update.Message.Text)
+		if !createsubforclient.IsValidPhoneNumber(whatsapp) {
+			return h.sendError(chatID, "❌ Неверный формат номера. Введите номер в формате +996555123456")
+		}
+		if sub.ClientWhatsApp != nil && *sub.ClientWhatsApp == whatsapp {
+			return h.sendError(chatID, "❌ Номер совпадает с текущим. Введите другой номер")
+		}
+		flowData.NewWhatsApp = &whatsapp
+		flowData.OldValue = valueOrDash(sub.ClientWhatsApp)
+		flowData.NewValue = whatsapp
+	case flows.EditSubFieldExpiresAt:
+		if err := setExpiry(flowData, sub, update.Message.Text); err != nil {
+			return h.sendError(chatID, "❌ "+err.Error())
+		}
+	default:
+		return nil
+	}
+
+	// Пользователь ответил текстом - подтверждение отправляем новым сообщением
+	flowData.MessageID = nil
+	return h.showConfirm(chatID, flowData)
+}
+
+// handleServer обрабатывает выбор нового сервера
+func (h *Handler) handleServer(ctx context.Context, update *tgbotapi.Update) error {
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Выберите сервер кнопкой")
+	}
+	chatID := update.CallbackQuery.Message.Chat.ID
+	_ = h.answerCallback(update.CallbackQuery.ID, "")
+
+	flowData, err := h.stateManager.GetEditSubData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	action, serverID, ok := parseCallback(update.CallbackQuery.Data)
+	if !ok || action != "esub_srv" {
+		return nil
+	}
+
+	sub, err := h.loadSubscription(ctx, flowData, flowData.SubscriptionID)
+	if err != nil {
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ "+err.Error())
+	}
+
+	archived := false
+	server, err := h.serverService.GetServer(ctx, servers.GetCriteria{ID: &serverID, Archived: &archived})
+	if err != nil || server == nil {
+		return h.sendError(chatID, "❌ Сервер не найден")
+	}
+
+	flowData.NewServerID = &server.ID
+	flowData.OldValue = h.serverName(ctx, sub.ServerID)
+	flowData.NewValue = server.Name
+
+	return h.showConfirm(chatID, flowData)
+}
+
+// showConfirm показывает изменение и просит подтвердить
+func (h *Handler) showConfirm(chatID int64, flowData *flows.EditSubFlowData) error {
+	text := fmt.Sprintf("✏️ *Подписка #%d*\n\n%s:\n%s → %s\n\nПодтвердить изменение?",
+		flowData.SubscriptionID, fieldLabel(flowData.Field), flowData.OldValue, flowData.NewValue)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Подтвердить", "esub_confirm"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+		),
+	)
+
+	return h.showMarkdown(chatID, flowData, states.AssistantEditSubWaitConfirm, text, &keyboard)
+}
+
+// handleConfirm применяет изменение и пишет аудит
+func (h *Handler) handleConfirm(ctx context.Context, update *tgbotapi.Update) error {
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Подтвердите или отмените изменение кнопками")
+	}
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	if update.CallbackQuery.Data != "esub_confirm" {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		return nil
+	}
+
+	flowData, err := h.stateManager.GetEditSubData(chatID)
+	if err != nil {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	// Подписка могла истечь или уйти другому ассистенту, пока шло подтверждение
+	sub, err := h.loadSubscription(ctx, flowData, flowData.SubscriptionID)
+	if err != nil {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ "+err.Error())
+	}
+
+	switch {
+	case flowData.NewWhatsApp != nil:
+		// Смена номера пишется в историю подписки вместе с прежним номером
+		err = h.subscriptionStorage.ChangeSubscriptionWhatsApp(ctx, subs.PhoneChange{
+			SubscriptionID:      flowData.SubscriptionID,
+			OldWhatsApp:         lo.FromPtr(sub.ClientWhatsApp),
+			NewWhatsApp:         *flowData.NewWhatsApp,
+			ChangedByTelegramID: flowData.AssistantTelegramID,
+		})
+	case flowData.NewServerID != nil:
+		// Перенос меняет сервер и счетчики обоих серверов одной транзакцией
+		var moved bool
+		moved, err = h.subscriptionStorage.MoveSubscriptionServer(ctx, flowData.SubscriptionID, sub.ServerID, *flowData.NewServerID)
+		if err == nil && !moved {
+			_ = h.answerCallback(update.CallbackQuery.ID, "")
+			h.stateManager.Clear(chatID)
+			return h.sendError(chatID, "❌ Подписку уже перенесли на другой сервер, откройте ее заново")
+		}
+	default:
+		_, err = h.subscriptionStorage.UpdateSubscription(ctx, subs.GetCriteria{IDs: []int64{flowData.SubscriptionID}}, subs.UpdateParams{
+			ExpiresAt: flowData.NewExpiresAt,
+		})
+	}
+	if err != nil {
+		h.logger.Error("Failed to update subscription", "error", err, "sub_id", flowData.SubscriptionID)
+		_ = h.answerCallback(update.CallbackQuery.ID, "Ошибка")
+		return h.sendError(chatID, "❌ Ошибка сохранения изменений")
+	}
+	_ = h.answerCallback(update.CallbackQuery.ID, "Сохранено")
+
+	// Аудит: кто и что поменял в подписке
+	h.logger.Info("Subscription edited",
+		"audit", true,
+		"assistant_telegram_id", flowData.AssistantTelegramID,
+		"sub_id", flowData.SubscriptionID,
+		"field", string(flowData.Field),
+		"old_value", flowData.OldValue,
+		"new_value", flowData.NewValue,
+	)
+
+	text := fmt.Sprintf("✅ *Подписка #%d изменена*\n\n%s: %s",
+		flowData.SubscriptionID, fieldLabel(flowData.Field), flowData.NewValue)
+
+	var keyboard *tgbotapi.InlineKeyboardMarkup
+	switch {
+	case flowData.NewServerID != nil:
+		var serverText string
+		serverText, keyboard = h.movedServerDetails(ctx, sub, *flowData.NewServerID)
+		text += serverText
+	case flowData.NewWhatsApp != nil:
+		// После смены номера ссылки на чат строятся по новому номеру: даем открыть чат и обновленную карточку
+		text += "\n\nПрежний номер сохранен в истории подписки."
+		markup := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonURL("💬 WhatsApp", "https://wa.me/"+*flowData.NewWhatsApp),
+				tgbotapi.NewInlineKeyboardButtonData("📋 Открыть подписку", fmt.Sprintf("sub_view:%d", flowData.SubscriptionID)),
+			),
+		)
+		keyboard = &markup
+	}
+
+	h.stateManager.Clear(chatID)
+	if flowData.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
+		editMsg.ParseMode = "Markdown"
+		editMsg.ReplyMarkup = keyboard
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// movedServerDetails - что нужно ассистенту, чтобы завести клиента в панели нового сервера:
+// User ID подписки, пароль и ссылка на панель
+func (h *Handler) movedServerDetails(ctx context.Context, sub *subs.Subscription, serverID int64) (string, *tgbotapi.InlineKeyboardMarkup) {
+	text := "\n\nСоздайте клиента в панели нового сервера и удалите его на старом."
+	if sub.GeneratedUserID != nil && *sub.GeneratedUserID != "" {
+		text += fmt.Sprintf("\n\n🔑 User ID:\n`%s`", *sub.GeneratedUserID)
+	}
+
+	server, err := h.serverService.GetServer(ctx, servers.GetCriteria{ID: &serverID})
+	if err != nil || server == nil {
+		h.logger.Warn("Failed to get new server for moved subscription", "error", err, "server_id", serverID)
+		return text, nil
+	}
+	if server.UIPassword != "" {
+		text += fmt.Sprintf("\n🔐 Пароль:\n`%s`", server.UIPassword)
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if server.UIURL != "" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL("🖥 Открыть панель управления", server.UIURL),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📋 Открыть подписку", fmt.Sprintf("sub_view:%d", sub.ID)),
+	))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return text, &keyboard
+}
+
+// showMessage редактирует сообщение флоу или отправляет новое и переводит флоу в состояние state
+func (h *Handler) showMessage(chatID int64, flowData *flows.EditSubFlowData, state states.State, text string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	return h.show(chatID, flowData, state, text, "", keyboard)
+}
+
+// showMarkdown - showMessage с разметкой Markdown
+func (h *Handler) showMarkdown(chatID int64, flowData *flows.EditSubFlowData, state states.State, text string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	return h.show(chatID, flowData, state, text, "Markdown", keyboard)
+}
+
+func (h *Handler) show(chatID int64, flowData *flows.EditSubFlowData, state states.State, text, parseMode string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	h.stateManager.SetState(chatID, state, flowData)
+
+	if flowData.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
+		editMsg.ParseMode = parseMode
+		editMsg.ReplyMarkup = keyboard
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = parseMode
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	sentMsg, err := h.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+	flowData.MessageID = &sentMsg.MessageID
+	return nil
+}
+
+func (h *Handler) serverName(ctx context.Context, serverID *int64) string {
+	if serverID == nil {
+		return "—"
+	}
+	server, err := h.serverService.GetServer(ctx, servers.GetCriteria{ID: serverID})
+	if err != nil || server == nil {
+		return fmt.Sprintf("#%d", *serverID)
+	}
+	return server.Name
+}
+
+func fieldLabel(field flows.EditSubField) string {
+	switch field {
+	case flows.EditSubFieldWhatsApp:
+		return "📱 WhatsApp"
+	case flows.EditSubFieldExpiresAt:
+		return "📅 Дата окончания"
+	case flows.EditSubFieldServer:
+		return "🖥 Сервер"
+	default:
+		return string(field)
+	}
+}
+
+func formatExpiry(expiresAt *time.Time) string {
+	if expiresAt == nil {
+		return "—"
+	}
+	return expiresAt.Format("02.01.2006")
+}
+
+func valueOrDash(value *string) string {
+	if value == nil || *value == "" {
+		return "—"
+	}
+	return *value
+}
+
+func cancelKeyboard() *tgbotapi.InlineKeyboardMarkup {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+		),
+	)
+	return &keyboard
+}
+
+// parseCallback разбирает "action:id"
+func parseCallback(data string) (string, int64, bool) {
+	action, rawID, ok := strings.Cut(data, ":")
+	if !ok {
+		return "", 0, false
+	}
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return action, id, true
+}
+
+func (h *Handler) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := h.bot.Request(callback)
+	return err
+}
+
+func (h *Handler) sendError(chatID int64, message string) error {
+	msg := tgbotapi.NewMessage(chatID, message)
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+func extractChatID(update *tgbotapi.Update) int64 {
+	if update.Message != nil {
+		return update.Message.Chat.ID
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		return update.CallbackQuery.Message.Chat.ID
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package editsub
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxExpiryYearsAhead - дата дальше этого срока скорее всего опечатка в годе
+const maxExpiryYearsAhead = 3
+
+// ParseExpiryDate разбирает новую дату окончания "ДД.ММ.ГГГГ" или "ДД.ММ" (текущий год).
+// Время суток берется из прежней даты окончания, чтобы подписка истекала в привычный час.
+// Дата в прошлом принимается только при allowPast (админ исправляет ошибки импорта)
+func ParseExpiryDate(text string, current *time.Time, now time.Time, allowPast bool) (time.Time, error) {
+	text = strings.TrimSpace(text)
+
+	loc := now.Location()
+	var date time.Time
+	var err error
+	if strings.Count(text, ".") == 1 {
+		date, err = time.ParseInLocation("02.01", text, loc)
+		date = date.AddDate(now.Year()-date.Year(), 0, 0)
+	} else {
+		date, err = time.ParseInLocation("02.01.2006", text, loc)
+	}
+	if err != nil {
+		return time.Time{}, errors.New("неверный формат даты, используйте ДД.ММ.ГГГГ")
+	}
+
+	if current != nil {
+		c := current.In(loc)
+		date = time.Date(date.Year(), date.Month(), date.Day(), c.Hour(), c.Minute(), c.Second(), 0, loc)
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if !allowPast && date.Before(today) {
+		return time.Time{}, errors.New("дата окончания не может быть в прошлом")
+	}
+	if date.After(today.AddDate(maxExpiryYearsAhead, 0, 0)) {
+		return time.Time{}, fmt.Errorf("дата окончания дальше %d лет, проверьте год", maxExpiryYearsAhead)
+	}
+
+	return date, nil
+}
@@ -0,0 +1,42 @@
+package editsub
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExpiryDate(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	current := time.Date(2026, 3, 20, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		text      string
+		current   *time.Time
+		want      time.Time
+		allowPast bool
+		wantErr   bool
+	}{
+		{name: "full date keeps time of day", text: "15.04.2026", current: &current, want: time.Date(2026, 4, 15, 18, 30, 0, 0, time.UTC)},
+		{name: "short date uses current year", text: " 01.05 ", current: &current, want: time.Date(2026, 5, 1, 18, 30, 0, 0, time.UTC)},
+		{name: "no current expiry", text: "15.04.2026", want: time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)},
+		{name: "today is allowed", text: "10.03.2026", current: &current, want: time.Date(2026, 3, 10, 18, 30, 0, 0, time.UTC)},
+		{name: "past date", text: "09.03.2026", current: &current, wantErr: true},
+		{name: "garbage", text: "завтра", current: &current, wantErr: true},
+		{name: "invalid day", text: "31.02.2026", current: &current, wantErr: true},
+		{name: "past date allowed for admin", text: "01.02.2026", current: &current, allowPast: true, want: time.Date(2026, 2, 1, 18, 30, 0, 0, time.UTC)},
+		{name: "too far ahead", text: "10.03.2030", current: &current, allowPast: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExpiryDate(tt.text, tt.current, now, tt.allowPast)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExpiryDate(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("ParseExpiryDate(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package edittariff
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+)
+
+type (
+	botApi interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+		Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	}
+
+	stateManager interface {
+		SetState(chatID int64, state states.State, data any)
+		Clear(chatID int64)
+		GetEditTariffData(chatID int64) (*flows.EditTariffFlowData, error)
+	}
+
+	tariffService interface {
+		GetTariff(ctx context.Context, criteria tariffs.GetCriteria) (*tariffs.Tariff, error)
+		GetActiveTariffs(ctx context.Context) ([]*tariffs.Tariff, error)
+		UpdateTariff(ctx context.Context, tariffID int64, params tariffs.UpdateParams) (*tariffs.Tariff, error)
+		ChangePrice(ctx context.Context, tariffID int64, price float64, onlyNewPurchases bool) (*tariffs.Tariff, int64, error)
+	}
+
+	serverService interface {
+		ListClusters(ctx context.Context) ([]string, error)
+	}
+)
@@ -0,0 +1,787 @@
+package edittariff
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/keypad"
+	"kurut-bot/internal/telegram/states"
+)
+
+type Handler struct {
+	bot           botApi
+	stateManager  stateManager
+	tariffService tariffService
+	serverService serverService
+	logger        *slog.Logger
+}
+
+func NewHandler(
+	bot botApi,
+	sm stateManager,
+	ts tariffService,
+	ss serverService,
+	logger *slog.Logger,
+) *Handler {
+	return &Handler{
+		bot:           bot,
+		stateManager:  sm,
+		tariffService: ts,
+		serverService: ss,
+		logger:        logger,
+	}
+}
+
+var (
+	priceKeypad    = keypad.Config{Presets: []string{"199", "299", "499", "990"}, Decimal: true}
+	durationKeypad = keypad.Config{Presets: []string{"7", "30", "90", "180", "365"}, MaxLen: 3}
+	trafficKeypad  = keypad.Config{Presets: []string{"0", "50", "100", "200", "500"}, MaxLen: 5}
+)
+
+// Start открывает карточку тарифа для редактирования на месте списка тарифов (только для админов)
+func (h *Handler) Start(ctx context.Context, chatID int64, messageID int, tariffID int64) error {
+	flowData := &flows.EditTariffFlowData{
+		TariffID:  tariffID,
+		MessageID: &messageID,
+	}
+	return h.showCard(ctx, chatID, flowData, "")
+}
+
+// Handle обрабатывает текущее состояние
+func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
+	ctx := context.Background()
+	chatID := extractChatID(update)
+
+	flowData, err := h.stateManager.GetEditTariffData(chatID)
+	if err != nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	switch state {
+	case states.AdminEditTariffWaitField:
+		return h.handleField(ctx, update, flowData)
+	case states.AdminEditTariffWaitName:
+		return h.handleName(ctx, update, flowData)
+	case states.AdminEditTariffWaitPrice:
+		return h.handlePrice(ctx, update, flowData)
+	case states.AdminEditTariffWaitPriceScope:
+		return h.handlePriceScope(ctx, update, flowData)
+	case states.AdminEditTariffWaitDuration:
+		return h.handleDuration(ctx, update, flowData)
+	case states.AdminEditTariffWaitTraffic:
+		return h.handleTraffic(ctx, update, flowData)
+	case states.AdminEditTariffWaitCluster:
+		return h.handleCluster(ctx, update, flowData)
+	case states.AdminEditTariffWaitReminders:
+		return h.handleReminders(ctx, update, flowData)
+	case states.AdminEditTariffWaitPromo:
+		return h.handlePromo(ctx, update, flowData)
+	case states.AdminEditTariffWaitFallback:
+		return h.handleFallback(ctx, update, flowData)
+	default:
+		return fmt.Errorf("unknown edit tariff state: %s", state)
+	}
+}
+
+// showCard показывает карточку тарифа с кнопками изменения полей; notice - итог последнего изменения
+func (h *Handler) showCard(ctx context.Context, chatID int64, flowData *flows.EditTariffFlowData, notice string) error {
+	tariff, err := h.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &flowData.TariffID})
+	if err != nil || tariff == nil {
+		h.logger.Error("Failed to get tariff for edit", "error", err, "tariff_id", flowData.TariffID)
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ Тариф не найден")
+	}
+
+	text := cardText(tariff) + h.promoText(ctx, tariff)
+	if notice != "" {
+		text += "\n\n" + notice
+	}
+	text += "\n\nЧто изменить?"
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	// Цена бесплатного тарифа не меняется: он используется только для пробного периода
+	if tariff.Price > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📝 Название", "etf_name"),
+			tgbotapi.NewInlineKeyboardButtonData("💰 Цена", "etf_price"),
+		))
+	} else {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📝 Название", "etf_name"),
+		))
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏰ Продолжительность", "etf_duration"),
+			tgbotapi.NewInlineKeyboardButtonData("📶 Трафик", "etf_traffic"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🏷 Кластер серверов", "etf_cluster"),
+			tgbotapi.NewInlineKeyboardButtonData("🔔 Напоминания", "etf_reminders"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗓 Промо-период", "etf_promo"),
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Тариф после промо", "etf_fallback"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Готово", "etf_done"),
+		),
+	)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return h.show(chatID, flowData, states.AdminEditTariffWaitField, text, keyboard)
+}
+
+func cardText(tariff *tariffs.Tariff) string {
+	traffic := "без лимита"
+	if tariff.TrafficLimitGB != nil {
+		traffic = fmt.Sprintf("%d ГБ", *tariff.TrafficLimitGB)
+	}
+	status := "активный"
+	if !tariff.IsActive {
+		status = "в архиве"
+	}
+	return fmt.Sprintf("✏️ Редактирование тарифа\n\n"+
+		"📅 Название: %s\n"+
+		"💰 Цена: %.2f ₽\n"+
+		"⏰ Продолжительность: %d дней\n"+
+		"📶 Трафик: %s\n"+
+		"🏷 Кластер серверов: %s\n"+
+		"🔔 Напоминания: %s\n"+
+		"📦 Статус: %s",
+		tariff.Name, tariff.Price, tariff.DurationDays, traffic, clusterText(tariff.Cluster), remindersText(tariff), status)
+}
+
+// remindersText - расписание напоминаний тарифа: "за 7 дн., за 3 дн., в день истечения"
+func remindersText(tariff *tariffs.Tariff) string {
+	parts := make([]string, 0, len(tariff.ReminderSchedule()))
+	for _, days := range tariff.ReminderSchedule() {
+		if days == 0 {
+			parts = append(parts, "в день истечения")
+		} else {
+			parts = append(parts, fmt.Sprintf("за %d дн.", days))
+		}
+	}
+	text := strings.Join(parts, ", ")
+	if len(tariff.ReminderDays) == 0 {
+		text += " (по умолчанию)"
+	}
+	return text
+}
+
+// promoText - строки карточки о промо-периоде и тарифе для продлений после него
+func (h *Handler) promoText(ctx context.Context, tariff *tariffs.Tariff) string {
+	text := "\n🗓 Промо-период: " + promoPeriodText(tariff)
+	if tariff.FallbackTariffID == nil {
+		return text + "\n↩️ После промо: продление на этот же тариф"
+	}
+
+	fallback, err := h.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: tariff.FallbackTariffID})
+	if err != nil || fallback == nil {
+		h.logger.Error("Failed to get fallback tariff", "error", err, "tariff_id", tariff.ID)
+		return text + fmt.Sprintf("\n↩️ После промо: тариф #%d", *tariff.FallbackTariffID)
+	}
+	return text + fmt.Sprintf("\n↩️ После промо: %s (%.2f ₽)", fallback.Name, fallback.Price)
+}
+
+// promoPeriodText - промо-период с включительной датой окончания
+func promoPeriodText(tariff *tariffs.Tariff) string {
+	switch {
+	case tariff.ValidFrom != nil && tariff.ValidUntil != nil:
+		return fmt.Sprintf("%s - %s", tariff.ValidFrom.Format("02.01.2006"), tariff.ValidUntil.AddDate(0, 0, -1).Format("02.01.2006"))
+	case tariff.ValidFrom != nil:
+		return "с " + tariff.ValidFrom.Format("02.01.2006")
+	case tariff.ValidUntil != nil:
+		return "по " + tariff.ValidUntil.AddDate(0, 0, -1).Format("02.01.2006")
+	}
+	return "без ограничения"
+}
+
+// clusterText - кластер тарифа на карточке: без кластера подписка выдается на любом сервере
+func clusterText(cluster string) string {
+	if cluster == "" {
+		return "любой сервер"
+	}
+	return cluster
+}
+
+// handleField обрабатывает выбор поля на карточке тарифа
+func (h *Handler) handleField(ctx context.Context, update *tgbotapi.Update, flowData *flows.EditTariffFlowData) error {
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Выберите, что изменить, кнопками")
+	}
+	callbackQuery := update.CallbackQuery
+	chatID := callbackQuery.Message.Chat.ID
+	_ = h.answerCallback(callbackQuery.ID, "")
+
+	tariff, err := h.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &flowData.TariffID})
+	if err != nil || tariff == nil {
+		h.logger.Error("Failed to get tariff for edit", "error", err, "tariff_id", flowData.TariffID)
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ Тариф не найден")
+	}
+
+	switch callbackQuery.Data {
+	case "etf_name":
+		text := cardText(tariff) + "\n\nВведите новое название тарифа (максимум 100 символов):"
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", "etf_back"),
+			),
+		)
+		return h.show(chatID, flowData, states.AdminEditTariffWaitName, text, keyboard)
+	case "etf_price":
+		if tariff.Price == 0 {
+			return nil
+		}
+		return h.showKeypad(chatID, flowData, states.AdminEditTariffWaitPrice, priceInputText(tariff), priceKeypad)
+	case "etf_duration":
+		return h.showKeypad(chatID, flowData, states.AdminEditTariffWaitDuration, durationInputText(tariff), durationKeypad)
+	case "etf_traffic":
+		return h.showKeypad(chatID, flowData, states.AdminEditTariffWaitTraffic, trafficInputText(tariff), trafficKeypad)
+	case "etf_cluster":
+		clusters, err := h.serverService.ListClusters(ctx)
+		if err != nil {
+			h.logger.Error("Failed to list server clusters", "error", err)
+			return h.sendError(chatID, "❌ Ошибка получения кластеров")
+		}
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", "etf_back"),
+			),
+		)
+		return h.show(chatID, flowData, states.AdminEditTariffWaitCluster, clusterInputText(tariff, clusters), keyboard)
+	case "etf_reminders":
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", "etf_back"),
+			),
+		)
+		return h.show(chatID, flowData, states.AdminEditTariffWaitReminders, remindersInputText(tariff), keyboard)
+	case "etf_promo":
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", "etf_back"),
+			),
+		)
+		return h.show(chatID, flowData, states.AdminEditTariffWaitPromo, promoInputText(tariff), keyboard)
+	case "etf_fallback":
+		return h.showFallbackChoice(ctx, chatID, flowData, tariff)
+	case "etf_done":
+		h.stateManager.Clear(chatID)
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("📋 К тарифам", "trf_list"),
+			),
+		)
+		editMsg := tgbotapi.NewEditMessageText(chatID, callbackQuery.Message.MessageID, cardText(tariff))
+		editMsg.ReplyMarkup = &keyboard
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+
+	return nil
+}
+
+func priceInputText(tariff *tariffs.Tariff) string {
+	return fmt.Sprintf("✏️ Тариф: %s\n\n"+
+		"💰 Текущая цена: %.2f ₽\n"+
+		"Введите новую цену в рублях или выберите на клавиатуре (до 10000, можно с копейками):",
+		tariff.Name, tariff.Price)
+}
+
+func durationInputText(tariff *tariffs.Tariff) string {
+	return fmt.Sprintf("✏️ Тариф: %s\n\n"+
+		"⏰ Текущая продолжительность: %d дней\n"+
+		"Введите новую продолжительность в днях (от 1 до 365).\n"+
+		"Уже оплаченные подписки не изменятся, новая продолжительность действует для следующих покупок и продлений.",
+		tariff.Name, tariff.DurationDays)
+}
+
+func trafficInputText(tariff *tariffs.Tariff) string {
+	current := "без лимита"
+	if tariff.TrafficLimitGB != nil {
+		current = fmt.Sprintf("%d ГБ", *tariff.TrafficLimitGB)
+	}
+	return fmt.Sprintf("✏️ Тариф: %s\n\n"+
+		"📶 Текущий лимит трафика: %s\n"+
+		"Введите новый лимит в ГБ, 0 - без лимита:",
+		tariff.Name, current)
+}
+
+func clusterInputText(tariff *tariffs.Tariff, clusters []string) string {
+	available := "нет - сначала укажите кластер у серверов в /servers"
+	if len(clusters) > 0 {
+		available = strings.Join(clusters, ", ")
+	}
+	return fmt.Sprintf("✏️ Тариф: %s\n\n"+
+		"🏷 Текущий кластер: %s\n"+
+		"Доступные кластеры: %s\n\n"+
+		"Введите кластер, на серверах которого будут выдаваться подписки тарифа, или \"-\" - любой сервер.\n"+
+		"Уже выданные подписки останутся на своих серверах.",
+		tariff.Name, clusterText(tariff.Cluster), available)
+}
+
+func remindersInputText(tariff *tariffs.Tariff) string {
+	return fmt.Sprintf("✏️ Тариф: %s\n\n"+
+		"🔔 Текущие напоминания: %s\n\n"+
+		"Введите, за сколько дней до истечения напоминать о продлении, через запятую (от 0 до 30, не больше 5 дней), "+
+		"например 7, 3, 0 - 0 означает день истечения. \"-\" - расписание по умолчанию.",
+		tariff.Name, remindersText(tariff))
+}
+
+func promoInputText(tariff *tariffs.Tariff) string {
+	return fmt.Sprintf("✏️ Тариф: %s\n\n"+
+		"🗓 Текущий промо-период: %s\n\n"+
+		"Введите период в формате ДД.ММ.ГГГГ-ДД.ММ.ГГГГ (обе даты включительно), например 01.12.2026-31.12.2026, "+
+		"или \"-\", чтобы тариф продавался без ограничения.\n"+
+		"Вне периода тариф скрыт из покупки, но уже оформленные подписки продолжают работать.",
+		tariff.Name, promoPeriodText(tariff))
+}
+
+// handleName сохраняет новое название тарифа
+func (h *Handler) handleName(ctx context.Context, update *tgbotapi.Update, flowData *flows.EditTariffFlowData) error {
+	chatID := extractChatID(update)
+
+	if update.CallbackQuery != nil {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		if update.CallbackQuery.Data == "etf_back" {
+			return h.showCard(ctx, chatID, flowData, "")
+		}
+		return nil
+	}
+	if update.Message == nil || update.Message.Text == "" {
+		return h.sendError(chatID, "Пожалуйста, введите название тарифа текстом")
+	}
+
+	name, errText := parseName(update.Message.Text)
+	if errText != "" {
+		return h.sendError(chatID, errText)
+	}
+
+	if _, err := h.tariffService.UpdateTariff(ctx, flowData.TariffID, tariffs.UpdateParams{Name: &name}); err != nil {
+		h.logger.Error("Failed to rename tariff", "error", err, "tariff_id", flowData.TariffID)
+		return h.sendError(chatID, "❌ Ошибка изменения тарифа")
+	}
+	h.logChange(chatID, flowData.TariffID, "name", name)
+
+	// Название пришло сообщением - карточку отправляем заново под ним
+	flowData.MessageID = nil
+	return h.showCard(ctx, chatID, flowData, "✅ Название изменено")
+}
+
+// handlePrice принимает новую цену и спрашивает, к каким подпискам ее применить
+func (h *Handler) handlePrice(ctx context.Context, update *tgbotapi.Update, flowData *flows.EditTariffFlowData) error {
+	chatID := extractChatID(update)
+
+	tariff, err := h.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &flowData.TariffID})
+	if err != nil || tariff == nil {
+		h.logger.Error("Failed to get tariff for edit", "error", err, "tariff_id", flowData.TariffID)
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ Тариф не найден")
+	}
+
+	input, ok, err := h.readNumber(update, flowData, priceInputText(tariff), priceKeypad)
+	if !ok {
+		return err
+	}
+	price, errText := parsePrice(input)
+	if errText != "" {
+		return h.sendError(chatID, errText)
+	}
+
+	if price == tariff.Price {
+		return h.showCard(ctx, chatID, flowData, "Цена не изменилась")
+	}
+	flowData.NewPrice = price
+
+	text := fmt.Sprintf("✏️ Тариф: %s\n\n"+
+		"💰 Цена: %.2f ₽ → %.2f ₽\n\n"+
+		"Применить новую цену к продлениям текущих подписок или только к новым покупкам?\n"+
+		"Подписки с индивидуальной ценой сохранят ее в любом случае.",
+		tariff.Name, tariff.Price, price)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👥 Ко всем подпискам", "etf_scope_all"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🆕 Только к новым покупкам", "etf_scope_new"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", "etf_back"),
+		),
+	)
+	return h.show(chatID, flowData, states.AdminEditTariffWaitPriceScope, text, keyboard)
+}
+
+// handlePriceScope меняет цену тарифа для всех подписок или только для новых покупок
+func (h *Handler) handlePriceScope(ctx context.Context, update *tgbotapi.Update, flowData *flows.EditTariffFlowData) error {
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Используйте кнопки для выбора")
+	}
+	callbackQuery := update.CallbackQuery
+	chatID := callbackQuery.Message.Chat.ID
+	_ = h.answerCallback(callbackQuery.ID, "")
+
+	var onlyNewPurchases bool
+	switch callbackQuery.Data {
+	case "etf_scope_all":
+		onlyNewPurchases = false
+	case "etf_scope_new":
+		onlyNewPurchases = true
+	case "etf_back":
+		return h.showCard(ctx, chatID, flowData, "")
+	default:
+		return nil
+	}
+
+	tariff, kept, err := h.tariffService.ChangePrice(ctx, flowData.TariffID, flowData.NewPrice, onlyNewPurchases)
+	if err != nil {
+		h.logger.Error("Failed to change tariff price", "error", err, "tariff_id", flowData.TariffID)
+		return h.sendError(chatID, "❌ Ошибка изменения цены")
+	}
+
+	h.logger.Info("Tariff price changed",
+		"audit", true,
+		"admin_chat_id", chatID,
+		"tariff_id", tariff.ID,
+		"price", tariff.Price,
+		"only_new_purchases", onlyNewPurchases,
+		"kept_subscriptions", kept,
+	)
+
+	notice := "✅ Цена изменена для всех подписок"
+	if onlyNewPurchases {
+		notice = fmt.Sprintf("✅ Цена изменена для новых покупок. Старая цена сохранена у подписок: %d", kept)
+	}
+	return h.showCard(ctx, chatID, flowData, notice)
+}
+
+// handleDuration сохраняет новую продолжительность тарифа
+func (h *Handler) handleDuration(ctx context.Context, update *tgbotapi.Update, flowData *flows.EditTariffFlowData) error {
+	chatID := extractChatID(update)
+
+	tariff, err := h.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &flowData.TariffID})
+	if err != nil || tariff == nil {
+		h.logger.Error("Failed to get tariff for edit", "error", err, "tariff_id", flowData.TariffID)
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ Тариф не найден")
+	}
+
+	input, ok, err := h.readNumber(update, flowData, durationInputText(tariff), durationKeypad)
+	if !ok {
+		return err
+	}
+	duration, errText := parseDuration(input)
+	if errText != "" {
+		return h.sendError(chatID, errText)
+	}
+
+	if _, err := h.tariffService.UpdateTariff(ctx, flowData.TariffID, tariffs.UpdateParams{DurationDays: &duration}); err != nil {
+		h.logger.Error("Failed to change tariff duration", "error", err, "tariff_id", flowData.TariffID)
+		return h.sendError(chatID, "❌ Ошибка изменения тарифа")
+	}
+	h.logChange(chatID, flowData.TariffID, "duration_days", duration)
+
+	return h.showCard(ctx, chatID, flowData, "✅ Продолжительность изменена")
+}
+
+// handleTraffic сохраняет новый лимит трафика тарифа
+func (h *Handler) handleTraffic(ctx context.Context, update *tgbotapi.Update, flowData *flows.EditTariffFlowData) error {
+	chatID := extractChatID(update)
+
+	tariff, err := h.tariffService.GetTariff(ctx, tariffs.GetCriteria{ID: &flowData.TariffID})
+	if err != nil || tariff == nil {
+		h.logger.Error("Failed to get tariff for edit", "error", err, "tariff_id", flowData.TariffID)
+		h.stateManager.Clear(chatID)
+		return h.sendError(chatID, "❌ Тариф не найден")
+	}
+
+	input, ok, err := h.readNumber(update, flowData, trafficInputText(tariff), trafficKeypad)
+	if !ok {
+		return err
+	}
+	limit, errText := parseTrafficLimit(input)
+	if errText != "" {
+		return h.sendError(chatID, errText)
+	}
+
+	params := tariffs.UpdateParams{TrafficLimitGB: limit, ClearTrafficLimit: limit == nil}
+	if _, err := h.tariffService.UpdateTariff(ctx, flowData.TariffID, params); err != nil {
+		h.logger.Error("Failed to change tariff traffic limit", "error", err, "tariff_id", flowData.TariffID)
+		return h.sendError(chatID, "❌ Ошибка изменения тарифа")
+	}
+	h.logChange(chatID, flowData.TariffID, "traffic_limit_gb", limit)
+
+	return h.showCard(ctx, chatID, flowData, "✅ Лимит трафика изменен")
+}
+
+func (h *Handler) logChange(chatID, tariffID int64, field string, value any) {
+	h.logger.Info("Tariff changed",
+		"audit", true,
+		"admin_chat_id", chatID,
+		"tariff_id", tariffID,
+		"field", field,
+		"value", value,
+	)
+}
+
+// show редактирует карточку тарифа или отправляет новую и переводит флоу в состояние state
+func (h *Handler) show(chatID int64, flowData *flows.EditTariffFlowData, state states.State, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	h.stateManager.SetState(chatID, state, flowData)
+
+	if flowData.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
+		editMsg.ReplyMarkup = &keyboard
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	sentMsg, err := h.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+	flowData.MessageID = &sentMsg.MessageID
+	return nil
+}
+
+// showKeypad показывает на карточке подсказку с цифровой клавиатурой
+func (h *Handler) showKeypad(chatID int64, flowData *flows.EditTariffFlowData, state states.State, prompt string, cfg keypad.Config) error {
+	return h.show(chatID, flowData, state, keypad.Text(prompt, ""), keypad.Markup(cfg, ""))
+}
+
+// readNumber возвращает число, введенное текстом или выбранное на клавиатуре.
+// Нажатия цифр только перерисовывают клавиатуру - тогда ok=false.
+// Если число пришло сообщением, карточка дальше отправляется заново под ним
+func (h *Handler) readNumber(update *tgbotapi.Update, flowData *flows.EditTariffFlowData, prompt string, cfg keypad.Config) (value string, ok bool, err error) {
+	if update.CallbackQuery != nil {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+
+		action, value := keypad.ParseCallback(update.CallbackQuery.Data)
+		switch action {
+		case keypad.ActionSubmit:
+			return value, true, nil
+		case keypad.ActionEdit:
+			message := update.CallbackQuery.Message
+			edit := tgbotapi.NewEditMessageTextAndMarkup(message.Chat.ID, message.MessageID, keypad.Text(prompt, value), keypad.Markup(cfg, value))
+			return "", false, telegram.SafeEdit(h.bot, edit, "")
+		}
+		return "", false, nil
+	}
+
+	if update.Message == nil || update.Message.Text == "" {
+		return "", false, h.sendError(extractChatID(update), "Пожалуйста, введите число или выберите его на клавиатуре")
+	}
+
+	flowData.MessageID = nil
+	return update.Message.Text, true, nil
+}
+
+func (h *Handler) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := h.bot.Request(callback)
+	return err
+}
+
+func (h *Handler) sendError(chatID int64, message string) error {
+	msg := tgbotapi.NewMessage(chatID, message)
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+func extractChatID(update *tgbotapi.Update) int64 {
+	if update.Message != nil {
+		return update.Message.Chat.ID
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		return update.CallbackQuery.Message.Chat.ID
+	}
+	return 0
+}
+
+// handleCluster привязывает тариф к кластеру серверов или снимает привязку
+func (h *Handler) handleCluster(ctx context.Context, update *tgbotapi.Update, flowData *flows.EditTariffFlowData) error {
+	chatID := extractChatID(update)
+
+	if update.CallbackQuery != nil {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		if update.CallbackQuery.Data == "etf_back" {
+			return h.showCard(ctx, chatID, flowData, "")
+		}
+		return nil
+	}
+	if update.Message == nil || update.Message.Text == "" {
+		return h.sendError(chatID, "Пожалуйста, введите название кластера текстом")
+	}
+
+	clusters, err := h.serverService.ListClusters(ctx)
+	if err != nil {
+		h.logger.Error("Failed to list server clusters", "error", err)
+		return h.sendError(chatID, "❌ Ошибка получения кластеров")
+	}
+	cluster, errText := parseCluster(update.Message.Text, clusters)
+	if errText != "" {
+		return h.sendError(chatID, errText)
+	}
+
+	if _, err := h.tariffService.UpdateTariff(ctx, flowData.TariffID, tariffs.UpdateParams{Cluster: &cluster}); err != nil {
+		h.logger.Error("Failed to change tariff cluster", "error", err, "tariff_id", flowData.TariffID)
+		return h.sendError(chatID, "❌ Ошибка изменения тарифа")
+	}
+	h.logChange(chatID, flowData.TariffID, "cluster", cluster)
+
+	// Кластер пришел сообщением - карточку отправляем заново под ним
+	flowData.MessageID = nil
+	return h.showCard(ctx, chatID, flowData, "✅ Кластер серверов изменен")
+}
+
+// handleReminders меняет расписание напоминаний о продлении подписок тарифа
+func (h *Handler) handleReminders(ctx context.Context, update *tgbotapi.Update, flowData *flows.EditTariffFlowData) error {
+	chatID := extractChatID(update)
+
+	if update.CallbackQuery != nil {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		if update.CallbackQuery.Data == "etf_back" {
+			return h.showCard(ctx, chatID, flowData, "")
+		}
+		return nil
+	}
+	if update.Message == nil || update.Message.Text == "" {
+		return h.sendError(chatID, "Пожалуйста, введите дни напоминаний текстом")
+	}
+
+	days, errText := parseReminderDays(update.Message.Text)
+	if errText != "" {
+		return h.sendError(chatID, errText)
+	}
+
+	// Пустое расписание сохраняется как NULL - тариф напоминает по расписанию по умолчанию
+	if _, err := h.tariffService.UpdateTariff(ctx, flowData.TariffID, tariffs.UpdateParams{ReminderDays: days}); err != nil {
+		h.logger.Error("Failed to change tariff reminder days", "error", err, "tariff_id", flowData.TariffID)
+		return h.sendError(chatID, "❌ Ошибка изменения тарифа")
+	}
+	h.logChange(chatID, flowData.TariffID, "reminder_days", days)
+
+	// Дни пришли сообщением - карточку отправляем заново под ним
+	flowData.MessageID = nil
+	return h.showCard(ctx, chatID, flowData, "✅ Напоминания изменены")
+}
+
+// handlePromo задает или снимает промо-период тарифа
+func (h *Handler) handlePromo(ctx context.Context, update *tgbotapi.Update, flowData *flows.EditTariffFlowData) error {
+	chatID := extractChatID(update)
+
+	if update.CallbackQuery != nil {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		if update.CallbackQuery.Data == "etf_back" {
+			return h.showCard(ctx, chatID, flowData, "")
+		}
+		return nil
+	}
+	if update.Message == nil || update.Message.Text == "" {
+		return h.sendError(chatID, "Пожалуйста, введите промо-период текстом")
+	}
+
+	from, until, errText := parsePromoPeriod(update.Message.Text, time.Now())
+	if errText != "" {
+		return h.sendError(chatID, errText)
+	}
+
+	params := tariffs.UpdateParams{ValidFrom: from, ValidUntil: until, ClearPromoPeriod: from == nil}
+	if _, err := h.tariffService.UpdateTariff(ctx, flowData.TariffID, params); err != nil {
+		h.logger.Error("Failed to change tariff promo period", "error", err, "tariff_id", flowData.TariffID)
+		return h.sendError(chatID, "❌ Ошибка изменения тарифа")
+	}
+
+	notice := "✅ Промо-период снят"
+	if from != nil {
+		h.logChange(chatID, flowData.TariffID, "promo_period", fmt.Sprintf("%s - %s", from.Format(time.RFC3339), until.Format(time.RFC3339)))
+		notice = "✅ Промо-период изменен"
+	} else {
+		h.logChange(chatID, flowData.TariffID, "promo_period", nil)
+	}
+
+	// Период пришел сообщением - карточку отправляем заново под ним
+	flowData.MessageID = nil
+	return h.showCard(ctx, chatID, flowData, notice)
+}
+
+// showFallbackChoice предлагает выбрать тариф для продлений после промо среди активных тарифов без промо-периода
+func (h *Handler) showFallbackChoice(ctx context.Context, chatID int64, flowData *flows.EditTariffFlowData, tariff *tariffs.Tariff) error {
+	active, err := h.tariffService.GetActiveTariffs(ctx)
+	if err != nil {
+		h.logger.Error("Failed to get active tariffs", "error", err)
+		return h.sendError(chatID, "❌ Ошибка получения тарифов")
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, t := range active {
+		if t.ID == tariff.ID || t.IsPromo() {
+			continue
+		}
+		label := fmt.Sprintf("%s - %.2f ₽", t.Name, t.Price)
+		if tariff.FallbackTariffID != nil && *tariff.FallbackTariffID == t.ID {
+			label = "✅ " + label
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("etf_fb:%d", t.ID)),
+		))
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔁 Продлевать на этот же тариф", "etf_fb:0"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", "etf_back"),
+		),
+	)
+
+	text := fmt.Sprintf("✏️ Тариф: %s\n\n"+
+		"Выберите тариф, на который будут продлеваться подписки после окончания промо-периода.\n"+
+		"Если тариф не выбран или недоступен, подписки продлеваются на этот же тариф.",
+		tariff.Name)
+	return h.show(chatID, flowData, states.AdminEditTariffWaitFallback, text, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// handleFallback сохраняет тариф для продлений после промо
+func (h *Handler) handleFallback(ctx context.Context, update *tgbotapi.Update, flowData *flows.EditTariffFlowData) error {
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Выберите тариф кнопками")
+	}
+	callbackQuery := update.CallbackQuery
+	chatID := callbackQuery.Message.Chat.ID
+	_ = h.answerCallback(callbackQuery.ID, "")
+
+	if callbackQuery.Data == "etf_back" {
+		return h.showCard(ctx, chatID, flowData, "")
+	}
+	idStr, found := strings.CutPrefix(callbackQuery.Data, "etf_fb:")
+	if !found {
+		return nil
+	}
+	fallbackID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || fallbackID == flowData.TariffID {
+		return nil
+	}
+
+	params := tariffs.UpdateParams{ClearFallbackTariff: true}
+	if fallbackID != 0 {
+		params = tariffs.UpdateParams{FallbackTariffID: &fallbackID}
+	}
+	if _, err := h.tariffService.UpdateTariff(ctx, flowData.TariffID, params); err != nil {
+		h.logger.Error("Failed to change tariff fallback", "error", err, "tariff_id", flowData.TariffID)
+		return h.sendError(chatID, "❌ Ошибка изменения тарифа")
+	}
+	h.logChange(chatID, flowData.TariffID, "fallback_tariff_id", params.FallbackTariffID)
+
+	return h.showCard(ctx, chatID, flowData, "✅ Тариф после промо изменен")
+}
@@ -0,0 +1,159 @@
+package edittariff
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"kurut-bot/internal/stories/servers"
+)
+
+// parseName проверяет новое название тарифа; пустая строка ошибки - значение корректно
+func parseName(input string) (string, string) {
+	name := strings.TrimSpace(input)
+	if name == "" {
+		return "", "❌ Название не может быть пустым"
+	}
+	if utf8.RuneCountInString(name) > 100 {
+		return "", "❌ Название слишком длинное (максимум 100 символов)"
+	}
+	return name, ""
+}
+
+// parsePrice разбирает цену платного тарифа: больше 0 и не больше 10000, запятая допустима
+func parsePrice(input string) (float64, string) {
+	price, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(input), ",", "."), 64)
+	if err != nil {
+		return 0, "❌ Неверный формат цены. Введите число (например: 199 или 199.99)"
+	}
+	if price <= 0 {
+		return 0, "❌ Цена платного тарифа должна быть больше 0"
+	}
+	if price > 10000 {
+		return 0, "❌ Цена слишком большая (максимум 10000 рублей)"
+	}
+	return price, ""
+}
+
+// parseDuration разбирает продолжительность тарифа в днях: от 1 до 365
+func parseDuration(input string) (int, string) {
+	duration, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil {
+		return 0, "❌ Неверный формат. Введите целое число дней"
+	}
+	if duration < 1 {
+		return 0, "❌ Продолжительность должна быть больше 0 дней"
+	}
+	if duration > 365 {
+		return 0, "❌ Продолжительность слишком большая (максимум 365 дней)"
+	}
+	return duration, ""
+}
+
+// parseTrafficLimit разбирает лимит трафика в ГБ; 0 - без лимита (nil)
+func parseTrafficLimit(input string) (*int, string) {
+	limit, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil {
+		return nil, "❌ Неверный формат. Введите целое число ГБ"
+	}
+	if limit < 0 {
+		return nil, "❌ Лимит не может быть отрицательным"
+	}
+	if limit > 10000 {
+		return nil, "❌ Лимит слишком большой (максимум 10000 ГБ)"
+	}
+	if limit == 0 {
+		return nil, ""
+	}
+	return &limit, ""
+}
+
+// parseCluster выбирает кластер из существующих без учета регистра; "-" - любой сервер.
+// Кластер без серверов не принимается: иначе подписки тарифа негде было бы выдать
+func parseCluster(input string, clusters []string) (string, string) {
+	cluster, ok := servers.NormalizeCluster(input)
+	if !ok {
+		return "", "❌ Название кластера: до 32 символов, только буквы, цифры, пробел и дефис"
+	}
+	if cluster == "" {
+		return "", ""
+	}
+	for _, c := range clusters {
+		if strings.EqualFold(c, cluster) {
+			return c, ""
+		}
+	}
+	return "", "❌ Нет активных серверов в кластере " + cluster
+}
+
+// maxReminderDays - ограничения расписания напоминаний: не больше 5 дней, не раньше чем за 30 дней
+const (
+	maxReminderDays      = 5
+	maxReminderDaysAhead = 30
+)
+
+// parseReminderDays разбирает дни напоминаний до истечения через запятую или пробел ("7, 3, 0"), 0 - в день истечения.
+// "-" возвращает пустое расписание - тариф напоминает по расписанию по умолчанию. Дни сортируются по убыванию
+func parseReminderDays(input string) ([]int, string) {
+	input = strings.TrimSpace(input)
+	if input == "-" {
+		return []int{}, ""
+	}
+
+	fields := strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 0 {
+		return nil, "❌ Введите дни через запятую, например 7, 3, 0"
+	}
+	var days []int
+	for _, field := range fields {
+		day, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, "❌ Неверный формат. Введите целые числа дней через запятую, например 7, 3, 0"
+		}
+		if day < 0 || day > maxReminderDaysAhead {
+			return nil, "❌ Дни напоминаний - от 0 (в день истечения) до 30"
+		}
+		if !slices.Contains(days, day) {
+			days = append(days, day)
+		}
+	}
+	if len(days) > maxReminderDays {
+		return nil, "❌ Слишком много напоминаний (максимум 5)"
+	}
+	slices.SortFunc(days, func(a, b int) int { return b - a })
+	return days, ""
+}
+
+// parsePromoPeriod разбирает промо-период "ДД.ММ.ГГГГ-ДД.ММ.ГГГГ": тариф доступен с начала первого дня
+// до конца последнего. "-" снимает промо-период (оба значения nil). Уже закончившийся период не принимается
+func parsePromoPeriod(input string, now time.Time) (from, until *time.Time, errText string) {
+	input = strings.TrimSpace(input)
+	if input == "-" {
+		return nil, nil, ""
+	}
+
+	start, end, found := strings.Cut(input, "-")
+	if !found {
+		return nil, nil, "❌ Введите период в формате ДД.ММ.ГГГГ-ДД.ММ.ГГГГ или \"-\", чтобы снять промо"
+	}
+	loc := now.Location()
+	startDate, err := time.ParseInLocation("02.01.2006", strings.TrimSpace(start), loc)
+	if err != nil {
+		return nil, nil, "❌ Неверная дата начала, используйте ДД.ММ.ГГГГ"
+	}
+	endDate, err := time.ParseInLocation("02.01.2006", strings.TrimSpace(end), loc)
+	if err != nil {
+		return nil, nil, "❌ Неверная дата окончания, используйте ДД.ММ.ГГГГ"
+	}
+	if endDate.Before(startDate) {
+		return nil, nil, "❌ Дата окончания раньше даты начала"
+	}
+
+	endDate = endDate.AddDate(0, 0, 1)
+	if !endDate.After(now) {
+		return nil, nil, "❌ Промо-период уже закончился"
+	}
+	return &startDate, &endDate, ""
+}
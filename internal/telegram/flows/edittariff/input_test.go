@@ -0,0 +1,157 @@
+package edittariff
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseName(t *testing.T) {
+	if name, msg := parseName("  Премиум "); msg != "" || name != "Премиум" {
+		t.Errorf("parseName() = %q, %q, want %q", name, msg, "Премиум")
+	}
+	if _, msg := parseName("   "); msg == "" {
+		t.Error("parseName() accepted an empty name")
+	}
+	// 100 кириллических символов - это 200 байт, но название допустимое
+	if _, msg := parseName(strings.Repeat("я", 100)); msg != "" {
+		t.Errorf("parseName() rejected 100 runes: %q", msg)
+	}
+	if _, msg := parseName(strings.Repeat("я", 101)); msg == "" {
+		t.Error("parseName() accepted 101 runes")
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		ok    bool
+	}{
+		{"299", 299, true},
+		{"199,99", 199.99, true},
+		{" 10000 ", 10000, true},
+		{"0", 0, false},
+		{"-5", 0, false},
+		{"10000.01", 0, false},
+		{"abc", 0, false},
+	}
+	for _, tt := range tests {
+		got, msg := parsePrice(tt.input)
+		if (msg == "") != tt.ok || got != tt.want {
+			t.Errorf("parsePrice(%q) = %v, %q, want %v, ok=%v", tt.input, got, msg, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+		ok    bool
+	}{
+		{"30", 30, true},
+		{"1", 1, true},
+		{"365", 365, true},
+		{"0", 0, false},
+		{"366", 0, false},
+		{"7.5", 0, false},
+	}
+	for _, tt := range tests {
+		got, msg := parseDuration(tt.input)
+		if (msg == "") != tt.ok || got != tt.want {
+			t.Errorf("parseDuration(%q) = %v, %q, want %v, ok=%v", tt.input, got, msg, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseTrafficLimit(t *testing.T) {
+	got, msg := parseTrafficLimit("100")
+	if msg != "" || got == nil || *got != 100 {
+		t.Errorf("parseTrafficLimit(100) = %v, %q, want 100", got, msg)
+	}
+
+	got, msg = parseTrafficLimit("0")
+	if msg != "" || got != nil {
+		t.Errorf("parseTrafficLimit(0) = %v, %q, want no limit", got, msg)
+	}
+
+	for _, input := range []string{"-1", "10001", "много"} {
+		if _, msg := parseTrafficLimit(input); msg == "" {
+			t.Errorf("parseTrafficLimit(%q) accepted invalid input", input)
+		}
+	}
+}
+
+func TestParseCluster(t *testing.T) {
+	clusters := []string{"EU", "Premium"}
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"premium", "Premium", true},
+		{" EU ", "EU", true},
+		{"-", "", true},
+		{"Asia", "", false},
+		{"EU/West", "", false},
+	}
+	for _, tt := range tests {
+		got, msg := parseCluster(tt.input, clusters)
+		if (msg == "") != tt.ok || got != tt.want {
+			t.Errorf("parseCluster(%q) = %q, %q, want %q, ok=%v", tt.input, got, msg, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParsePromoPeriod(t *testing.T) {
+	now := time.Date(2026, 12, 10, 12, 0, 0, 0, time.UTC)
+
+	from, until, msg := parsePromoPeriod(" 01.12.2026 - 31.12.2026 ", now)
+	if msg != "" {
+		t.Fatalf("parsePromoPeriod() unexpected error: %q", msg)
+	}
+	if !from.Equal(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)) || !until.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("parsePromoPeriod() = %v - %v, want 01.12.2026 - 01.01.2027", from, until)
+	}
+
+	if from, until, msg := parsePromoPeriod("-", now); msg != "" || from != nil || until != nil {
+		t.Errorf("parsePromoPeriod(-) = %v, %v, %q, want clear", from, until, msg)
+	}
+
+	// Последний день периода включается целиком
+	if _, _, msg := parsePromoPeriod("01.12.2026-10.12.2026", now); msg != "" {
+		t.Errorf("parsePromoPeriod() rejected a period ending today: %q", msg)
+	}
+
+	for _, input := range []string{"01.12.2026", "31.12.2026-01.12.2026", "01.11.2026-09.12.2026", "1.12-31.12", "завтра-потом"} {
+		if _, _, msg := parsePromoPeriod(input, now); msg == "" {
+			t.Errorf("parsePromoPeriod(%q) accepted invalid period", input)
+		}
+	}
+}
+
+func TestParseReminderDays(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []int
+		ok    bool
+	}{
+		{"7, 3, 0", []int{7, 3, 0}, true},
+		{"0 3 7 3", []int{7, 3, 0}, true},
+		{"1,14", []int{14, 1}, true},
+		{"-", []int{}, true},
+		{"", nil, false},
+		{"3, abc", nil, false},
+		{"-1", nil, false},
+		{"31", nil, false},
+		{"1,2,3,4,5,6", nil, false},
+	}
+	for _, tt := range tests {
+		got, msg := parseReminderDays(tt.input)
+		if (msg == "") != tt.ok || !slices.Equal(got, tt.want) || (tt.want != nil) != (got != nil) {
+			t.Errorf("parseReminderDays(%q) = %v, %q, want %v, ok=%v", tt.input, got, msg, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package flows
+
+import tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+// BackCallback - кнопка «Назад» в многошаговых флоу
+const BackCallback = "flow_back"
+
+// BackButton возвращает флоу на предыдущий шаг
+func BackButton() tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", BackCallback)
+}
+
+// StepRecorder - данные флоу, которые помнят пройденные шаги. Шаги записывает менеджер состояний при смене состояния
+type StepRecorder interface {
+	RecordStep(from, to string)
+	PopStep() (string, bool)
+}
+
+// StepHistory - пройденные шаги флоу, встраивается в данные флоу
+type StepHistory struct {
+	steps []string
+}
+
+// RecordStep запоминает шаг from при переходе на to. Переход на уже пройденный шаг
+// отбрасывает историю после него, чтобы «Назад» не водил по кругу
+func (h *StepHistory) RecordStep(from, to string) {
+	for i, step := range h.steps {
+		if step == to {
+			h.steps = h.steps[:i]
+			return
+		}
+	}
+	h.steps = append(h.steps, from)
+}
+
+// PopStep возвращает предыдущий шаг и убирает его из истории. false - это первый шаг флоу
+func (h *StepHistory) PopStep() (string, bool) {
+	if len(h.steps) == 0 {
+		return "", false
+	}
+	step := h.steps[len(h.steps)-1]
+	h.steps = h.steps[:len(h.steps)-1]
+	return step, true
+}
+
+// HasSteps - есть ли куда вернуться
+func (h *StepHistory) HasSteps() bool {
+	return len(h.steps) > 0
+}
@@ -3,9 +3,12 @@ package migrateclient
 import (
 	"context"
 
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/flowcancel"
 	"kurut-bot/internal/stories/orders"
 	"kurut-bot/internal/stories/payment"
 	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/shortlinks"
 	"kurut-bot/internal/stories/subs"
 	"kurut-bot/internal/stories/tariffs"
 	"kurut-bot/internal/telegram/flows"
@@ -22,8 +25,10 @@ type (
 
 	stateManager interface {
 		Clear(chatID int64)
+		GetState(chatID int64) states.State
 		GetMigrateClientData(chatID int64) (*flows.MigrateClientFlowData, error)
 		SetState(chatID int64, state states.State, data any)
+		Back(chatID int64) (states.State, bool)
 	}
 
 	tariffService interface {
@@ -32,6 +37,7 @@ type (
 
 	serverService interface {
 		ListServers(ctx context.Context, criteria servers.ListCriteria) ([]*servers.Server, error)
+		GetServer(ctx context.Context, criteria servers.GetCriteria) (*servers.Server, error)
 	}
 
 	subscriptionService interface {
@@ -39,8 +45,9 @@ type (
 	}
 
 	paymentService interface {
-		CreatePayment(ctx context.Context, paymentEntity payment.Payment) (*payment.Payment, error)
+		CreatePaymentWithMeta(ctx context.Context, paymentEntity payment.Payment, meta payment.CreatePaymentMeta) (*payment.Payment, error)
 		CheckPaymentStatus(ctx context.Context, paymentID int64) (*payment.Payment, error)
+		CancelPayment(ctx context.Context, paymentID int64) (*payment.Payment, error)
 	}
 
 	orderService interface {
@@ -48,6 +55,20 @@ type (
 		GetPendingOrderByID(ctx context.Context, id int64) (*orders.PendingOrder, error)
 		UpdateMessageID(ctx context.Context, id int64, messageID int) error
 		UpdatePaymentID(ctx context.Context, id int64, paymentID int64) error
+		ClaimLinkRefresh(ctx context.Context, id int64) (bool, error)
+		UpdateStatus(ctx context.Context, id int64, status orders.Status) error
 		DeletePendingOrder(ctx context.Context, id int64) error
 	}
+
+	langStorage interface {
+		GetClientLanguage(ctx context.Context, clientWhatsApp string) (clientlang.Language, error)
+	}
+
+	clickStorage interface {
+		GetLinkClickStats(ctx context.Context, criteria shortlinks.ClickCriteria) (*shortlinks.ClickStats, error)
+	}
+
+	cancelSurvey interface {
+		Ask(ctx context.Context, telegramID, chatID int64, flow flowcancel.Flow, state string)
+	}
 )
@@ -8,13 +8,21 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/devices"
+	"kurut-bot/internal/stories/flowcancel"
 	"kurut-bot/internal/stories/orders"
 	"kurut-bot/internal/stories/payment"
 	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/shortlinks"
 	"kurut-bot/internal/stories/subs"
 	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/stories/watemplates"
 	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/messages"
 	"kurut-bot/internal/telegram/states"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -28,6 +36,9 @@ type Handler struct {
 	subscriptionService subscriptionService
 	paymentService      paymentService
 	orderService        orderService
+	langStorage         langStorage
+	clickStorage        clickStorage
+	cancelSurvey        cancelSurvey
 	logger              *slog.Logger
 }
 
@@ -39,6 +50,9 @@ func NewHandler(
 	subSvc subscriptionService,
 	ps paymentService,
 	os orderService,
+	ls langStorage,
+	cs clickStorage,
+	survey cancelSurvey,
 	logger *slog.Logger,
 ) *Handler {
 	return &Handler{
@@ -49,6 +63,9 @@ func NewHandler(
 		subscriptionService: subSvc,
 		paymentService:      ps,
 		orderService:        os,
+		langStorage:         ls,
+		clickStorage:        cs,
+		cancelSurvey:        survey,
 		logger:              logger,
 	}
 }
@@ -62,15 +79,35 @@ func (h *Handler) Start(userID, assistantTelegramID, chatID int64) error {
 	}
 	h.stateManager.SetState(chatID, states.AdminMigrateClientWaitName, flowData)
 
+	return h.showWhatsAppInput(chatID)
+}
+
+func (h *Handler) showWhatsAppInput(chatID int64) error {
 	msg := tgbotapi.NewMessage(chatID, "📱 Введите номер WhatsApp клиента для миграции (например: +996555123456):")
 	_, err := h.bot.Send(msg)
 	return err
 }
 
+// StartForClient начинает миграцию уже известного клиента (кнопка в карточке подписки) - сразу с выбора сервера
+func (h *Handler) StartForClient(ctx context.Context, userID, assistantTelegramID, chatID int64, clientWhatsApp string) error {
+	flowData := &flows.MigrateClientFlowData{
+		AdminUserID:         userID,
+		AssistantTelegramID: assistantTelegramID,
+		ClientWhatsApp:      clientWhatsApp,
+	}
+	h.stateManager.SetState(chatID, states.AdminMigrateClientWaitServer, flowData)
+
+	return h.showServers(ctx, chatID)
+}
+
 // Handle обрабатывает текущее состояние
 func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
 	ctx := context.Background()
 
+	if update.CallbackQuery != nil && update.CallbackQuery.Data == flows.BackCallback {
+		return h.handleBack(ctx, update)
+	}
+
 	switch state {
 	case states.AdminMigrateClientWaitName:
 		return h.handleWhatsAppInput(ctx, update)
@@ -135,6 +172,11 @@ func (h *Handler) showServers(ctx context.Context, chatID int64) error {
 		return h.sendError(chatID, "❌ Нет активных серверов")
 	}
 
+	flowData, _ := h.stateManager.GetMigrateClientData(chatID)
+	if flowData == nil {
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
 	// Создаем клавиатуру с серверами
 	var rows [][]tgbotapi.InlineKeyboardButton
 	for _, s := range serversList {
@@ -144,14 +186,17 @@ func (h *Handler) showServers(ctx context.Context, chatID int64) error {
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{button})
 	}
 
-	// Добавляем кнопку отмены
-	rows = append(rows, []tgbotapi.InlineKeyboardButton{
+	// Добавляем кнопку отмены, а если номер вводили на предыдущем шаге - и возврата к нему
+	controlRow := []tgbotapi.InlineKeyboardButton{
 		tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "mig_cancel"),
-	})
+	}
+	if flowData.HasSteps() {
+		controlRow = append([]tgbotapi.InlineKeyboardButton{flows.BackButton()}, controlRow...)
+	}
+	rows = append(rows, controlRow)
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
-	flowData, _ := h.stateManager.GetMigrateClientData(chatID)
 	text := fmt.Sprintf("🖥 Выберите сервер, на котором находится клиент:\n\n📱 Клиент: `%s`", flowData.ClientWhatsApp)
 
 	msg := tgbotapi.NewMessage(chatID, text)
@@ -163,10 +208,8 @@ func (h *Handler) showServers(ctx context.Context, chatID int64) error {
 		return err
 	}
 
-	if flowData != nil {
-		flowData.MessageID = &sentMsg.MessageID
-		h.stateManager.SetState(chatID, states.AdminMigrateClientWaitServer, flowData)
-	}
+	flowData.MessageID = &sentMsg.MessageID
+	h.stateManager.SetState(chatID, states.AdminMigrateClientWaitServer, flowData)
 
 	return nil
 }
@@ -237,24 +280,27 @@ func (h *Handler) showTariffs(ctx context.Context, chatID int64) error {
 		return h.sendError(chatID, "❌ Нет активных тарифов")
 	}
 
-	// Создаем клавиатуру с тарифами
-	keyboard := h.createTariffsKeyboard(tariffsList)
-
 	flowData, err := h.stateManager.GetMigrateClientData(chatID)
 	if err != nil || flowData == nil {
 		return h.sendError(chatID, "Ошибка получения данных флоу")
 	}
 
+	// Создаем клавиатуру с тарифами (цены с наценкой выбранного сервера)
+	server := h.selectedServer(ctx, flowData)
+	keyboard := h.createTariffsKeyboard(tariffsList, server)
+
 	text := fmt.Sprintf("📅 Выберите тариф:\n\n📱 Клиент: `%s`\n🖥 Сервер: %s",
 		flowData.ClientWhatsApp, flowData.ServerName)
+	if server.HasPriceModifier() {
+		text += fmt.Sprintf(" (%s)", server.PriceModifierText())
+	}
 
 	// Редактируем существующее сообщение
 	if flowData.MessageID != nil {
 		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
 		editMsg.ParseMode = "Markdown"
 		editMsg.ReplyMarkup = &keyboard
-		_, err = h.bot.Send(editMsg)
-		return err
+		return telegram.SafeEdit(h.bot, editMsg, "")
 	}
 
 	msg := tgbotapi.NewMessage(chatID, text)
@@ -303,6 +349,7 @@ func (h *Handler) handleTariffSelection(ctx context.Context, update *tgbotapi.Up
 	flowData.TariffID = tariffData.ID
 	flowData.TariffName = tariffData.Name
 	flowData.Price = tariffData.Price
+	flowData.TotalAmount = h.selectedServer(ctx, flowData).EffectivePrice(tariffData.Price)
 
 	// Отвечаем на callback query
 	callbackConfig := tgbotapi.NewCallback(update.CallbackQuery.ID, "Создаём заказ...")
@@ -338,11 +385,11 @@ func (h *Handler) createMigratedSubscription(ctx context.Context, chatID int64,
 	}
 
 	// Отправляем сообщение об успехе
-	return h.sendSubscriptionCreated(chatID, result, data)
+	return h.sendSubscriptionCreated(ctx, chatID, result, data)
 }
 
 // sendSubscriptionCreated отправляет сообщение об успешном создании подписки
-func (h *Handler) sendSubscriptionCreated(chatID int64, result *subs.CreateSubscriptionResult, data *flows.MigrateClientFlowData) error {
+func (h *Handler) sendSubscriptionCreated(ctx context.Context, chatID int64, result *subs.CreateSubscriptionResult, data *flows.MigrateClientFlowData) error {
 	// Формируем пароль если есть
 	passwordLine := ""
 	if result.ServerUIPassword != nil && *result.ServerUIPassword != "" {
@@ -364,7 +411,8 @@ func (h *Handler) sendSubscriptionCreated(chatID int64, result *subs.CreateSubsc
 	)
 
 	// Создаем кнопки
-	whatsappLink := generateWhatsAppLink(data.ClientWhatsApp, "Ваша подписка VPN активирована!")
+	whatsappLink := generateWhatsAppLink(data.ClientWhatsApp, messages.WhatsAppTextWithVars(h.clientLanguage(ctx, data.ClientWhatsApp), messages.WATemplateActivated,
+		watemplates.NewVars(data.ClientWhatsApp, result.Subscription.ExpiresAt, data.TariffName)))
 
 	var rows [][]tgbotapi.InlineKeyboardButton
 
@@ -380,6 +428,15 @@ func (h *Handler) sendSubscriptionCreated(chatID int64, result *subs.CreateSubsc
 		tgbotapi.NewInlineKeyboardButtonURL("💬 Написать клиенту", whatsappLink),
 	))
 
+	// Кнопка для добавления окончания подписки в календарь и выбор устройства клиента для инструкции
+	if result.Subscription != nil {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📅 В календарь", fmt.Sprintf("cal_sub:%d", result.Subscription.ID)),
+		))
+		rows = append(rows, platformRow(result.Subscription.ID))
+		messageText += "\n\n📲 Выберите устройство клиента - пришлю инструкцию по подключению для него"
+	}
+
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
 	// Редактируем существующее сообщение, если MessageID есть
@@ -387,7 +444,7 @@ func (h *Handler) sendSubscriptionCreated(chatID int64, result *subs.CreateSubsc
 		editMsg := tgbotapi.NewEditMessageText(chatID, *data.MessageID, messageText)
 		editMsg.ParseMode = "Markdown"
 		editMsg.ReplyMarkup = &keyboard
-		_, err := h.bot.Send(editMsg)
+		err := telegram.SafeEdit(h.bot, editMsg, "")
 		if err != nil {
 			// Fallback: отправляем новое сообщение
 			msg := tgbotapi.NewMessage(chatID, messageText)
@@ -414,6 +471,7 @@ func (h *Handler) sendSubscriptionCreated(chatID int64, result *subs.CreateSubsc
 func (h *Handler) handleCancel(update *tgbotapi.Update) error {
 	chatID := update.CallbackQuery.Message.Chat.ID
 
+	state := h.stateManager.GetState(chatID)
 	h.stateManager.Clear(chatID)
 
 	// Отвечаем на callback query
@@ -421,7 +479,38 @@ func (h *Handler) handleCancel(update *tgbotapi.Update) error {
 	_, _ = h.bot.Request(callbackConfig)
 
 	// Отправляем главное меню
-	return h.sendMainMenu(chatID)
+	if err := h.sendMainMenu(chatID); err != nil {
+		return err
+	}
+
+	if state != states.StateNone {
+		h.cancelSurvey.Ask(context.Background(), update.CallbackQuery.From.ID, chatID, flowcancel.FlowMigrateClient, string(state))
+	}
+	return nil
+}
+
+// handleBack возвращает на предыдущий шаг: к вводу номера или выбору сервера
+func (h *Handler) handleBack(ctx context.Context, update *tgbotapi.Update) error {
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	state, ok := h.stateManager.Back(chatID)
+	if !ok {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Это первый шаг"))
+		return nil
+	}
+	_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+	// Убираем кнопки со старого шага, чтобы нажатие на них не попало в другой шаг
+	_ = telegram.SafeEdit(h.bot, tgbotapi.NewEditMessageReplyMarkup(chatID, update.CallbackQuery.Message.MessageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}), "")
+
+	switch state {
+	case states.AdminMigrateClientWaitName:
+		return h.showWhatsAppInput(chatID)
+	case states.AdminMigrateClientWaitServer:
+		return h.showServers(ctx, chatID)
+	default:
+		return fmt.Errorf("no back step for migrate client state: %s", state)
+	}
 }
 
 // sendMainMenu отправляет главное меню
@@ -436,25 +525,45 @@ func (h *Handler) sendMainMenu(chatID int64) error {
 	return err
 }
 
-func (h *Handler) createTariffsKeyboard(tariffList []*tariffs.Tariff) tgbotapi.InlineKeyboardMarkup {
+// selectedServer возвращает выбранный для миграции сервер (nil - не удалось получить)
+func (h *Handler) selectedServer(ctx context.Context, flowData *flows.MigrateClientFlowData) *servers.Server {
+	server, err := h.serverService.GetServer(ctx, servers.GetCriteria{ID: &flowData.ServerID})
+	if err != nil {
+		h.logger.Warn("Failed to get migration server", "error", err, "server_id", flowData.ServerID)
+		return nil
+	}
+	return server
+}
+
+// createTariffsKeyboard показывает цены с наценкой сервера; в callback остается цена тарифа
+func (h *Handler) createTariffsKeyboard(tariffList []*tariffs.Tariff, server *servers.Server) tgbotapi.InlineKeyboardMarkup {
 	var rows [][]tgbotapi.InlineKeyboardButton
 
 	for _, t := range tariffList {
 		durationText := formatDuration(t.DurationDays)
-		text := fmt.Sprintf("📅 %s - %.2f ₽ (%s)", t.Name, t.Price, durationText)
+		text := fmt.Sprintf("📅 %s - %.2f ₽ (%s)", t.Name, server.EffectivePrice(t.Price), durationText)
 		callbackData := fmt.Sprintf("mig_trf:%d:%.2f:%s:%d", t.ID, t.Price, t.Name, t.DurationDays)
 		button := tgbotapi.NewInlineKeyboardButtonData(text, callbackData)
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{button})
 	}
 
-	// Добавляем кнопку отмены
+	// Добавляем кнопки возврата к выбору сервера и отмены
 	rows = append(rows, []tgbotapi.InlineKeyboardButton{
+		flows.BackButton(),
 		tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "mig_cancel"),
 	})
 
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
+// formatAmount - "350.00 ₽" или "450.00 ₽ (тариф 350.00 ₽ + сервер 100.00 ₽)"
+func formatAmount(total float64, base *float64) string {
+	if base == nil {
+		return fmt.Sprintf("%.2f ₽", total)
+	}
+	return fmt.Sprintf("%.2f ₽ (тариф %.2f ₽ + сервер %.2f ₽)", total, *base, total-*base)
+}
+
 // formatDuration форматирует длительность в удобный формат
 func formatDuration(days int) string {
 	if days >= 365 {
@@ -555,6 +664,15 @@ func isValidPhoneNumber(normalizedPhone string) bool {
 	return match
 }
 
+// clientLanguage возвращает язык клиента для предзаполненных сообщений WhatsApp
+func (h *Handler) clientLanguage(ctx context.Context, phone string) clientlang.Language {
+	stored, err := h.langStorage.GetClientLanguage(ctx, phone)
+	if err != nil {
+		h.logger.Warn("Failed to get client language", "error", err, "whatsapp", phone)
+	}
+	return clientlang.Resolve(stored, phone)
+}
+
 // generateWhatsAppLink генерирует ссылку на WhatsApp с предзаполненным сообщением
 func generateWhatsAppLink(phone string, message string) string {
 	// Убираем + из начала номера для WhatsApp API
@@ -565,21 +683,37 @@ func generateWhatsAppLink(phone string, message string) string {
 	return fmt.Sprintf("https://wa.me/%s?text=%s", cleanPhone, url.QueryEscape(message))
 }
 
+// platformRow - кнопки выбора устройства клиента; после выбора бот пришлет инструкцию под него (dev_set)
+func platformRow(subscriptionID int64) []tgbotapi.InlineKeyboardButton {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(devices.Supported))
+	for _, platform := range devices.Supported {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(platform.Title(), fmt.Sprintf("dev_set:%d:%s", subscriptionID, platform)))
+	}
+	return row
+}
+
 // createPaymentAndShow создает платеж и показывает ссылку на оплату
 func (h *Handler) createPaymentAndShow(ctx context.Context, chatID int64, data *flows.MigrateClientFlowData) error {
 	// Создаем платеж
 	paymentEntity := payment.Payment{
-		UserID: data.AdminUserID,
-		Amount: data.Price,
-		Status: payment.StatusPending,
+		UserID:   data.AdminUserID,
+		Amount:   data.TotalAmount,
+		Status:   payment.StatusPending,
+		ServerID: &data.ServerID,
+	}
+	if data.TotalAmount != data.Price {
+		paymentEntity.BaseAmount = &data.Price
 	}
 
-	paymentObj, err := h.paymentService.CreatePayment(ctx, paymentEntity)
+	paymentObj, err := h.paymentService.CreatePaymentWithMeta(ctx, paymentEntity, payment.CreatePaymentMeta{
+		TariffName:     data.TariffName,
+		ClientWhatsApp: data.ClientWhatsApp,
+	})
 	if err != nil {
 		h.logger.Error("Failed to create payment",
 			"error", err,
 			"user_id", data.AdminUserID,
-			"amount", data.Price)
+			"amount", data.TotalAmount)
 		return h.sendError(chatID, "Ошибка создания платежа. Попробуйте позже или обратитесь к администратору.")
 	}
 
@@ -602,7 +736,8 @@ func (h *Handler) createPaymentAndShow(ctx context.Context, chatID int64, data *
 		ClientWhatsApp:      data.ClientWhatsApp,
 		TariffID:            data.TariffID,
 		TariffName:          data.TariffName,
-		TotalAmount:         data.Price,
+		TotalAmount:         data.TotalAmount,
+		BaseAmount:          paymentEntity.BaseAmount,
 		ServerID:            &data.ServerID,
 		ServerName:          &data.ServerName,
 	}
@@ -619,9 +754,9 @@ func (h *Handler) createPaymentAndShow(ctx context.Context, chatID int64, data *
 			"📱 Клиент: %s\n"+
 			"🖥 Сервер: %s\n"+
 			"📅 Тариф: %s\n"+
-			"💰 Сумма: %.2f ₽\n\n"+
+			"💰 Сумма: %s\n\n"+
 			"🔗 Ссылка на оплату: [link](%s)\n\n",
-		data.ClientWhatsApp, data.ServerName, data.TariffName, data.Price, *paymentObj.PaymentURL)
+		data.ClientWhatsApp, data.ServerName, data.TariffName, formatAmount(data.TotalAmount, paymentEntity.BaseAmount), *paymentObj.PaymentURL)
 
 	// Создаем кнопки с orderID для независимой работы каждого заказа
 	checkButton := tgbotapi.NewInlineKeyboardButtonData("🔄 Проверить оплату", fmt.Sprintf("migpay_check:%d", createdOrder.ID))
@@ -640,7 +775,7 @@ func (h *Handler) createPaymentAndShow(ctx context.Context, chatID int64, data *
 		editMsg := tgbotapi.NewEditMessageText(chatID, *data.MessageID, paymentMsg)
 		editMsg.ParseMode = "Markdown"
 		editMsg.ReplyMarkup = &keyboard
-		_, err = h.bot.Send(editMsg)
+		err = telegram.SafeEdit(h.bot, editMsg, "")
 		if err != nil {
 			return err
 		}
@@ -716,7 +851,8 @@ func (h *Handler) HandleMigratePaymentCallback(update *tgbotapi.Update) error {
 		h.logger.Error("Failed to get pending order", "error", err, "orderID", orderID)
 		return h.sendError(chatID, "❌ Ошибка получения заказа")
 	}
-	if order == nil {
+	// Отмененный заказ хранится ради поздней оплаты, но кнопки по нему уже не работают
+	if order == nil || order.Status != orders.StatusPending {
 		callbackConfig := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Заказ не найден или уже обработан")
 		_, _ = h.bot.Request(callbackConfig)
 		return nil
@@ -754,8 +890,14 @@ func (h *Handler) handleMigratePaymentCheck(ctx context.Context, update *tgbotap
 		// Платеж успешен - создаем подписку
 		return h.handleSuccessfulMigratePayment(ctx, chatID, order)
 	case payment.StatusPending:
-		// Платеж еще обрабатывается
-		alertConfig := tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, "⏳ Платеж еще обрабатывается.\nПожалуйста, подождите и попробуйте еще раз.")
+		// Платеж еще обрабатывается - подсказываем, открывал ли клиент ссылку
+		alertText := "⏳ Платеж еще обрабатывается.\nПожалуйста, подождите и попробуйте еще раз."
+		if stats, err := h.clickStorage.GetLinkClickStats(ctx, shortlinks.ClickCriteria{OrderID: &order.ID}); err != nil {
+			h.logger.Warn("Failed to get link click stats", "error", err, "order_id", order.ID)
+		} else {
+			alertText += "\n\n" + stats.Summary(time.Now())
+		}
+		alertConfig := tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, alertText)
 		_, _ = h.bot.Request(alertConfig)
 		return nil
 	case payment.StatusRejected, payment.StatusCancelled:
@@ -794,7 +936,7 @@ func (h *Handler) handleSuccessfulMigratePayment(ctx context.Context, chatID int
 	}
 
 	// Отправляем сообщение об успехе
-	if err := h.sendMigrateSubscriptionCreatedForOrder(chatID, result, order, serverName); err != nil {
+	if err := h.sendMigrateSubscriptionCreatedForOrder(ctx, chatID, result, order, serverName); err != nil {
 		return err
 	}
 
@@ -810,18 +952,40 @@ func (h *Handler) handleSuccessfulMigratePayment(ctx context.Context, chatID int
 func (h *Handler) handleMigratePaymentRefresh(ctx context.Context, update *tgbotapi.Update, order *orders.PendingOrder) error {
 	chatID := update.CallbackQuery.Message.Chat.ID
 
+	// Ограничиваем обновления ссылки: каждое создает новый платеж в ЮKassa
+	if reason := order.LinkRefreshBlockReason(time.Now()); reason != "" {
+		_, _ = h.bot.Request(tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, reason))
+		return nil
+	}
+	claimed, err := h.orderService.ClaimLinkRefresh(ctx, order.ID)
+	if err != nil {
+		h.logger.Error("Failed to claim link refresh", "error", err, "orderID", order.ID)
+		_, _ = h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "Ошибка"))
+		return h.sendError(chatID, "❌ Ошибка обновления ссылки")
+	}
+	if !claimed {
+		// Ссылку обновили параллельным нажатием
+		_, _ = h.bot.Request(tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, "⏳ Ссылка только что обновлена, подождите немного"))
+		return nil
+	}
+
 	// Отвечаем на callback query
 	callbackConfig := tgbotapi.NewCallback(update.CallbackQuery.ID, "Создаём новую ссылку...")
 	_, _ = h.bot.Request(callbackConfig)
 
 	// Создаем новый платеж
 	paymentEntity := payment.Payment{
-		UserID: order.AdminUserID,
-		Amount: order.TotalAmount,
-		Status: payment.StatusPending,
+		UserID:     order.AdminUserID,
+		Amount:     order.TotalAmount,
+		Status:     payment.StatusPending,
+		BaseAmount: order.BaseAmount,
+		ServerID:   order.ServerID,
 	}
 
-	paymentObj, err := h.paymentService.CreatePayment(ctx, paymentEntity)
+	paymentObj, err := h.paymentService.CreatePaymentWithMeta(ctx, paymentEntity, payment.CreatePaymentMeta{
+		TariffName:     order.TariffName,
+		ClientWhatsApp: order.ClientWhatsApp,
+	})
 	if err != nil {
 		h.logger.Error("Failed to create payment for refresh", "error", err)
 		return h.sendError(chatID, "Ошибка создания платежа. Попробуйте позже.")
@@ -836,6 +1000,11 @@ func (h *Handler) handleMigratePaymentRefresh(ctx context.Context, update *tgbot
 		h.logger.Error("Failed to update payment ID", "error", err, "orderID", order.ID)
 	}
 
+	// Старая ссылка больше не нужна - отменяем ее платеж, чтобы заказ не оплатили дважды
+	if _, err := h.paymentService.CancelPayment(ctx, order.PaymentID); err != nil {
+		h.logger.Error("Failed to cancel superseded payment", "error", err, "paymentID", order.PaymentID, "orderID", order.ID)
+	}
+
 	serverName := ""
 	if order.ServerName != nil {
 		serverName = *order.ServerName
@@ -847,10 +1016,10 @@ func (h *Handler) handleMigratePaymentRefresh(ctx context.Context, update *tgbot
 			"📱 Клиент: %s\n"+
 			"🖥 Сервер: %s\n"+
 			"📅 Тариф: %s\n"+
-			"💰 Сумма: %.2f ₽\n\n"+
+			"💰 Сумма: %s\n\n"+
 			"🔗 Ссылка на оплату: [link](%s)\n\n"+
 			"После оплаты нажмите «Проверить оплату».",
-		order.ClientWhatsApp, serverName, order.TariffName, order.TotalAmount, *paymentObj.PaymentURL)
+		order.ClientWhatsApp, serverName, order.TariffName, formatAmount(order.TotalAmount, order.BaseAmount), *paymentObj.PaymentURL)
 
 	// Создаем кнопки
 	checkButton := tgbotapi.NewInlineKeyboardButtonData("🔄 Проверить оплату", fmt.Sprintf("migpay_check:%d", order.ID))
@@ -868,8 +1037,7 @@ func (h *Handler) handleMigratePaymentRefresh(ctx context.Context, update *tgbot
 		editMsg := tgbotapi.NewEditMessageText(chatID, *order.MessageID, paymentMsg)
 		editMsg.ParseMode = "Markdown"
 		editMsg.ReplyMarkup = &keyboard
-		_, err = h.bot.Send(editMsg)
-		return err
+		return telegram.SafeEdit(h.bot, editMsg, "")
 	}
 
 	// Fallback: отправляем новое сообщение
@@ -897,9 +1065,14 @@ func (h *Handler) handleMigratePaymentCancel(ctx context.Context, update *tgbota
 	callbackConfig := tgbotapi.NewCallback(update.CallbackQuery.ID, "Отменено")
 	_, _ = h.bot.Request(callbackConfig)
 
-	// Удаляем pending order
-	if err := h.orderService.DeletePendingOrder(ctx, order.ID); err != nil {
-		h.logger.Error("Failed to delete pending order", "error", err, "orderID", order.ID)
+	// Отменяем платеж заказа, чтобы по отправленной клиенту ссылке уже нельзя было оплатить
+	if _, err := h.paymentService.CancelPayment(ctx, order.PaymentID); err != nil {
+		h.logger.Error("Failed to cancel order payment", "error", err, "paymentID", order.PaymentID, "orderID", order.ID)
+	}
+
+	// Заказ не удаляем: если клиент все же оплатит старую ссылку, по нему можно будет выдать подписку
+	if err := h.orderService.UpdateStatus(ctx, order.ID, orders.StatusCancelled); err != nil {
+		h.logger.Error("Failed to mark pending order cancelled", "error", err, "orderID", order.ID)
 	}
 
 	serverName := ""
@@ -918,7 +1091,7 @@ func (h *Handler) handleMigratePaymentCancel(ctx context.Context, update *tgbota
 
 		editMsg := tgbotapi.NewEditMessageText(chatID, *order.MessageID, cancelledMsg)
 		editMsg.ParseMode = "Markdown"
-		_, _ = h.bot.Send(editMsg)
+		_ = telegram.SafeEdit(h.bot, editMsg, "")
 	}
 
 	return nil
@@ -937,8 +1110,7 @@ func (h *Handler) sendMigratePaymentError(chatID int64, order *orders.PendingOrd
 	if order.MessageID != nil {
 		editMsg := tgbotapi.NewEditMessageText(chatID, *order.MessageID, errorMsg)
 		editMsg.ReplyMarkup = &keyboard
-		_, err := h.bot.Send(editMsg)
-		return err
+		return telegram.SafeEdit(h.bot, editMsg, "")
 	}
 
 	msg := tgbotapi.NewMessage(chatID, errorMsg)
@@ -948,7 +1120,7 @@ func (h *Handler) sendMigratePaymentError(chatID int64, order *orders.PendingOrd
 }
 
 // sendMigrateSubscriptionCreatedForOrder отправляет сообщение об успешном создании подписки
-func (h *Handler) sendMigrateSubscriptionCreatedForOrder(chatID int64, result *subs.CreateSubscriptionResult, order *orders.PendingOrder, serverName string) error {
+func (h *Handler) sendMigrateSubscriptionCreatedForOrder(ctx context.Context, chatID int64, result *subs.CreateSubscriptionResult, order *orders.PendingOrder, serverName string) error {
 	passwordLine := ""
 	if result.ServerUIPassword != nil && *result.ServerUIPassword != "" {
 		passwordLine = fmt.Sprintf("\n`%s`", *result.ServerUIPassword)
@@ -968,7 +1140,8 @@ func (h *Handler) sendMigrateSubscriptionCreatedForOrder(chatID int64, result *s
 		passwordLine,
 	)
 
-	whatsappLink := generateWhatsAppLink(order.ClientWhatsApp, "Ваша подписка VPN активирована! Сейчас отправлю инструкции по подключению.")
+	whatsappLink := generateWhatsAppLink(order.ClientWhatsApp, messages.WhatsAppTextWithVars(h.clientLanguage(ctx, order.ClientWhatsApp), messages.WATemplateActivated,
+		watemplates.NewVars(order.ClientWhatsApp, result.Subscription.ExpiresAt, order.TariffName)))
 
 	var rows [][]tgbotapi.InlineKeyboardButton
 
@@ -982,13 +1155,22 @@ func (h *Handler) sendMigrateSubscriptionCreatedForOrder(chatID int64, result *s
 		tgbotapi.NewInlineKeyboardButtonURL("💬 Написать клиенту", whatsappLink),
 	))
 
+	// Кнопка для добавления окончания подписки в календарь и выбор устройства клиента для инструкции
+	if result.Subscription != nil {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📅 В календарь", fmt.Sprintf("cal_sub:%d", result.Subscription.ID)),
+		))
+		rows = append(rows, platformRow(result.Subscription.ID))
+		messageText += "\n\n📲 Выберите устройство клиента - пришлю инструкцию по подключению для него"
+	}
+
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
 	if order.MessageID != nil {
 		editMsg := tgbotapi.NewEditMessageText(chatID, *order.MessageID, messageText)
 		editMsg.ParseMode = "Markdown"
 		editMsg.ReplyMarkup = &keyboard
-		_, err := h.bot.Send(editMsg)
+		err := telegram.SafeEdit(h.bot, editMsg, "")
 		if err != nil {
 			// Fallback
 			msg := tgbotapi.NewMessage(chatID, messageText)
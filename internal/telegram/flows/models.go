@@ -1,5 +1,12 @@
 package flows
 
+import (
+	"time"
+
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/watemplates"
+)
+
 // BuySubFlowData - data for buy sub
 type BuySubFlowData struct {
 	UserID      int64 // Внутренний ID пользователя
@@ -20,6 +27,8 @@ type CreateSubFlowData struct {
 
 // CreateSubForClientFlowData - data for assistant creating sub for client
 type CreateSubForClientFlowData struct {
+	StepHistory
+
 	AdminUserID            int64
 	AssistantTelegramID    int64
 	ClientWhatsApp         string
@@ -31,8 +40,12 @@ type CreateSubForClientFlowData struct {
 	TotalAmount            float64
 	PaymentID              *int64
 	PaymentURL             *string
-	MessageID              *int // ID сообщения для бесшовного редактирования
-	IsTrialEligible        bool // true if client can get trial
+	MessageID              *int   // ID сообщения для бесшовного редактирования
+	IsTrialEligible        bool   // true if client can get trial
+	ServerID               *int64 // Сервер, выбранный вручную (/quick_sub); nil - автоматически
+	ServerName             *string
+	WaitlistEntryID        *int64 // Заказ по записи из очереди на свободное место
+	ReceiptOrderID         *int64 // Заказ, к которому ждем фото чека (ручной режим оплаты)
 }
 
 // DisableSubFlowData - data for disable sub
@@ -47,6 +60,8 @@ type EnableSubFlowData struct {
 
 // CreateTariffFlowData - data for create tariff
 type CreateTariffFlowData struct {
+	StepHistory
+
 	Name           string
 	Price          float64
 	DurationDays   int
@@ -75,15 +90,20 @@ type WelcomeFlowData struct {
 
 // AddServerFlowData - data for adding server
 type AddServerFlowData struct {
+	StepHistory
+
 	Name         string
 	UIURL        string
 	UIPassword   string
 	CurrentUsers int
 	MaxUsers     int
+	Reachable    bool // результат последней проверки подключения
 }
 
 // MigrateClientFlowData - data for migrating existing client
 type MigrateClientFlowData struct {
+	StepHistory
+
 	AdminUserID         int64
 	AssistantTelegramID int64
 	ClientWhatsApp      string
@@ -91,8 +111,106 @@ type MigrateClientFlowData struct {
 	ServerName          string
 	TariffID            int64
 	TariffName          string
-	Price               float64
+	Price               float64 // Цена тарифа
+	TotalAmount         float64 // Цена с наценкой сервера
 	PaymentID           *int64
 	PaymentURL          *string
 	MessageID           *int
 }
+
+// EditSubField - что меняется в подписке во флоу редактирования
+type EditSubField string
+
+const (
+	EditSubFieldWhatsApp  EditSubField = "wa"
+	EditSubFieldExpiresAt EditSubField = "exp"
+	EditSubFieldServer    EditSubField = "srv"
+)
+
+// EditSubFlowData - data for assistant editing one of their subscriptions
+type EditSubFlowData struct {
+	AssistantTelegramID int64
+	IsAdmin             bool // админ может править чужие подписки
+	SubscriptionID      int64
+	Field               EditSubField
+	OldValue            string // текущее значение для подтверждения и аудита
+	NewValue            string // новое значение для подтверждения и аудита
+	NewWhatsApp         *string
+	NewExpiresAt        *time.Time
+	NewServerID         *int64
+	MessageID           *int
+}
+
+// CancelSubFlowData - data for assistant cancelling one of their subscriptions
+type CancelSubFlowData struct {
+	AssistantTelegramID int64
+	IsAdmin             bool // админ может отменять чужие подписки
+	SubscriptionID      int64
+	MessageID           *int
+}
+
+// BulkRenewCandidate - подписка в списке массового продления
+type BulkRenewCandidate struct {
+	SubscriptionID int64
+	Label          string
+	Price          float64 // цена продления с учетом индивидуальной цены подписки
+}
+
+// BulkRenewFlowData - data for renewing several subscriptions with one payment
+type BulkRenewFlowData struct {
+	AdminUserID         int64 // Внутренний ID пользователя, на которого создается платеж
+	AssistantTelegramID int64
+	Candidates          []BulkRenewCandidate
+	Selected            []int64 // ID выбранных подписок в порядке выбора
+	MessageID           *int
+}
+
+// TransferSubsCandidate - подписка в списке выбора для передачи другому ассистенту
+type TransferSubsCandidate struct {
+	SubscriptionID int64
+	Label          string
+}
+
+// TransferSubsFlowData - data for admin transferring subscriptions between assistants
+type TransferSubsFlowData struct {
+	AdminTelegramID int64
+	FromTelegramID  int64
+	ToTelegramID    int64
+	TotalCount      int  // сколько всего подписок у исходного ассистента
+	All             bool // передать все подписки исходного ассистента
+	Candidates      []TransferSubsCandidate
+	Selected        []int64
+	MessageID       *int
+}
+
+// EditTariffFlowData - data for admin editing an existing tariff
+type EditTariffFlowData struct {
+	TariffID  int64
+	NewPrice  float64 // введенная цена, ждет выбора: для всех подписок или только для новых покупок
+	MessageID *int    // карточка тарифа, которую перерисовываем после каждого изменения
+}
+
+// EditServerFlowData - data for admin editing an existing server
+type EditServerFlowData struct {
+	ServerID  int64
+	MessageID *int // карточка сервера, которую перерисовываем после каждого изменения
+}
+
+// BroadcastFlowData - data for admin broadcast to everyone who created a subscription
+type BroadcastFlowData struct {
+	AdminTelegramID int64
+	RecipientsCount int
+	Text            string
+	MessageID       *int
+}
+
+// SubNoteFlowData - data for assistant editing a subscription note
+type SubNoteFlowData struct {
+	SubscriptionID int64
+}
+
+// WATemplateFlowData - data for admin editing a WhatsApp message template
+type WATemplateFlowData struct {
+	Name     watemplates.Name
+	Language clientlang.Language
+}
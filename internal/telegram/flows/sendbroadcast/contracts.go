@@ -0,0 +1,32 @@
+package sendbroadcast
+
+import (
+	"context"
+
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+	"kurut-bot/internal/workers/broadcast"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type (
+	botApi interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+		Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	}
+
+	stateManager interface {
+		Clear(chatID int64)
+		GetBroadcastData(chatID int64) (*flows.BroadcastFlowData, error)
+		SetState(chatID int64, state states.State, data any)
+	}
+
+	recipientStorage interface {
+		ListSubscriptionCreators(ctx context.Context) ([]int64, error)
+	}
+
+	broadcastQueue interface {
+		Enqueue(job broadcast.Job) bool
+	}
+)
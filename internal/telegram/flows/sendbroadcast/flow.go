@@ -0,0 +1,227 @@
+package sendbroadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+	"kurut-bot/internal/workers/broadcast"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxTextLength - лимит Telegram на длину текста сообщения
+const maxTextLength = 4096
+
+type Handler struct {
+	bot          botApi
+	stateManager stateManager
+	storage      recipientStorage
+	queue        broadcastQueue
+	logger       *slog.Logger
+}
+
+func NewHandler(bot botApi, sm stateManager, storage recipientStorage, queue broadcastQueue, logger *slog.Logger) *Handler {
+	return &Handler{
+		bot:          bot,
+		stateManager: sm,
+		storage:      storage,
+		queue:        queue,
+		logger:       logger,
+	}
+}
+
+// validateText проверяет текст рассылки
+func validateText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", errors.New("текст рассылки пустой")
+	}
+	if utf8.RuneCountInString(text) > maxTextLength {
+		return "", fmt.Errorf("текст длиннее %d символов", maxTextLength)
+	}
+	return text, nil
+}
+
+// Start начинает flow рассылки: админ вводит текст
+func (h *Handler) Start(ctx context.Context, adminTelegramID, chatID int64) error {
+	recipients, err := h.storage.ListSubscriptionCreators(ctx)
+	if err != nil {
+		h.logger.Error("Failed to list broadcast recipients", "error", err)
+		return h.sendError(chatID, "❌ Ошибка загрузки получателей")
+	}
+	if len(recipients) == 0 {
+		return h.sendError(chatID, "📢 Получателей нет: ещё никто не создал ни одной подписки")
+	}
+
+	flowData := &flows.BroadcastFlowData{AdminTelegramID: adminTelegramID, RecipientsCount: len(recipients)}
+	return h.askText(chatID, flowData)
+}
+
+// Handle обрабатывает текущее состояние
+func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
+	ctx := context.Background()
+	chatID := extractChatID(update)
+
+	flowData, err := h.stateManager.GetBroadcastData(chatID)
+	if err != nil {
+		if update.CallbackQuery != nil {
+			_ = h.answerCallback(update.CallbackQuery.ID, "")
+		}
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	switch state {
+	case states.AdminBroadcastWaitText:
+		return h.handleText(update, flowData)
+	case states.AdminBroadcastWaitConfirm:
+		return h.handleConfirm(ctx, update, flowData)
+	default:
+		return fmt.Errorf("unknown state: %s", state)
+	}
+}
+
+func (h *Handler) askText(chatID int64, flowData *flows.BroadcastFlowData) error {
+	text := fmt.Sprintf("📢 Рассылка\n\nПолучателей: %d — все, кто создал хотя бы одну подписку.\n\n"+
+		"Отправьте текст сообщения. Оно уйдет как есть, без форматирования.", flowData.RecipientsCount)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(cancelRow())
+	return h.show(chatID, flowData, states.AdminBroadcastWaitText, text, &keyboard)
+}
+
+// handleText принимает текст и показывает предпросмотр: само сообщение и кнопки подтверждения под ним
+func (h *Handler) handleText(update *tgbotapi.Update, flowData *flows.BroadcastFlowData) error {
+	if update.Message == nil {
+		if update.CallbackQuery != nil {
+			_ = h.answerCallback(update.CallbackQuery.ID, "")
+		}
+		return nil
+	}
+	chatID := update.Message.Chat.ID
+
+	text, err := validateText(update.Message.Text)
+	if err != nil {
+		return h.sendError(chatID, "❌ "+err.Error()+". Отправьте другой текст.")
+	}
+	flowData.Text = text
+
+	if _, err := h.bot.Send(tgbotapi.NewMessage(chatID, text)); err != nil {
+		return err
+	}
+
+	// Карточку подтверждения отправляем под предпросмотром
+	flowData.MessageID = nil
+	confirm := fmt.Sprintf("👆 Так сообщение увидят получатели: %d\n\nОтправить?", flowData.RecipientsCount)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ Отправить (%d)", flowData.RecipientsCount), "bc_send"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Другой текст", "bc_edit"),
+		),
+		cancelRow(),
+	)
+	return h.show(chatID, flowData, states.AdminBroadcastWaitConfirm, confirm, &keyboard)
+}
+
+func (h *Handler) handleConfirm(ctx context.Context, update *tgbotapi.Update, flowData *flows.BroadcastFlowData) error {
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Выберите вариант кнопками")
+	}
+	callbackQuery := update.CallbackQuery
+	chatID := callbackQuery.Message.Chat.ID
+
+	switch callbackQuery.Data {
+	case "bc_edit":
+		_ = h.answerCallback(callbackQuery.ID, "")
+		return h.askText(chatID, flowData)
+	case "bc_send":
+	default:
+		return h.answerCallback(callbackQuery.ID, "")
+	}
+
+	// Получателей берем заново: за время набора текста могли появиться новые
+	recipients, err := h.storage.ListSubscriptionCreators(ctx)
+	if err != nil {
+		h.logger.Error("Failed to list broadcast recipients", "error", err)
+		_ = h.answerCallback(callbackQuery.ID, "Ошибка")
+		return h.sendError(chatID, "❌ Ошибка загрузки получателей")
+	}
+
+	if !h.queue.Enqueue(broadcast.Job{
+		Text:         flowData.Text,
+		Recipients:   recipients,
+		ReportChatID: chatID,
+		AdminID:      flowData.AdminTelegramID,
+	}) {
+		return h.answerCallback(callbackQuery.ID, "Очередь рассылок заполнена, попробуйте позже")
+	}
+	_ = h.answerCallback(callbackQuery.ID, "Рассылка запущена")
+
+	h.logger.Info("Broadcast queued",
+		"audit", true,
+		"admin_telegram_id", flowData.AdminTelegramID,
+		"recipients", len(recipients),
+		"text_length", utf8.RuneCountInString(flowData.Text),
+	)
+
+	h.stateManager.Clear(chatID)
+	text := fmt.Sprintf("📤 Рассылка запущена: получателей %d.\nПришлю отчет, когда все сообщения будут отправлены.", len(recipients))
+	editMsg := tgbotapi.NewEditMessageText(chatID, callbackQuery.Message.MessageID, text)
+	return telegram.SafeEdit(h.bot, editMsg, "")
+}
+
+// show редактирует сообщение флоу или отправляет новое и переводит флоу в состояние state
+func (h *Handler) show(chatID int64, flowData *flows.BroadcastFlowData, state states.State, text string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	h.stateManager.SetState(chatID, state, flowData)
+
+	if flowData.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
+		editMsg.ReplyMarkup = keyboard
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	sentMsg, err := h.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+	flowData.MessageID = &sentMsg.MessageID
+	return nil
+}
+
+func cancelRow() []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+	)
+}
+
+func (h *Handler) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := h.bot.Request(callback)
+	return err
+}
+
+func (h *Handler) sendError(chatID int64, message string) error {
+	msg := tgbotapi.NewMessage(chatID, message)
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+func extractChatID(update *tgbotapi.Update) int64 {
+	if update.Message != nil {
+		return update.Message.Chat.ID
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		return update.CallbackQuery.Message.Chat.ID
+	}
+	return 0
+}
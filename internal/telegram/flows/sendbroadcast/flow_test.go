@@ -0,0 +1,32 @@
+package sendbroadcast
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateText(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"trimmed", "  Плановые работы в 02:00  \n", "Плановые работы в 02:00", false},
+		{"empty", " \n ", "", true},
+		{"limit in runes", strings.Repeat("я", maxTextLength), strings.Repeat("я", maxTextLength), false},
+		{"too long", strings.Repeat("я", maxTextLength+1), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateText(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateText() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("validateText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
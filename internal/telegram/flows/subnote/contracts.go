@@ -0,0 +1,29 @@
+package subnote
+
+import (
+	"context"
+
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type (
+	botApi interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+		Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	}
+
+	stateManager interface {
+		Clear(chatID int64)
+		GetSubNoteData(chatID int64) (*flows.SubNoteFlowData, error)
+		SetState(chatID int64, state states.State, data any)
+	}
+
+	noteStorage interface {
+		GetSubscription(ctx context.Context, criteria subs.GetCriteria) (*subs.Subscription, error)
+		SetSubscriptionNote(ctx context.Context, subscriptionID int64, note string) error
+	}
+)
@@ -0,0 +1,157 @@
+package subnote
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Handler - заметка ассистента к подписке: кнопка sub_note:ID, затем текст заметки сообщением
+type Handler struct {
+	bot          botApi
+	stateManager stateManager
+	storage      noteStorage
+	logger       *slog.Logger
+}
+
+func NewHandler(bot botApi, sm stateManager, storage noteStorage, logger *slog.Logger) *Handler {
+	return &Handler{
+		bot:          bot,
+		stateManager: sm,
+		storage:      storage,
+		logger:       logger,
+	}
+}
+
+// Start обрабатывает sub_note:ID и ждет текст заметки.
+// Ассистент может писать заметки только к своим подпискам, админ - к любым
+func (h *Handler) Start(ctx context.Context, viewerTelegramID int64, isAdmin bool, callbackQuery *tgbotapi.CallbackQuery) error {
+	subID, err := strconv.ParseInt(strings.TrimPrefix(callbackQuery.Data, "sub_note:"), 10, 64)
+	if err != nil {
+		return h.answerCallback(callbackQuery.ID, "Неверный ID подписки")
+	}
+
+	sub, err := h.storage.GetSubscription(ctx, subs.GetCriteria{IDs: []int64{subID}})
+	if err != nil {
+		h.logger.Error("Failed to get subscription for note", "error", err, "sub_id", subID)
+		return h.answerCallback(callbackQuery.ID, "Ошибка")
+	}
+	if sub == nil || (!isAdmin && (sub.CreatedByTelegramID == nil || *sub.CreatedByTelegramID != viewerTelegramID)) {
+		return h.answerCallback(callbackQuery.ID, "Подписка не найдена")
+	}
+	_ = h.answerCallback(callbackQuery.ID, "")
+
+	chatID := callbackQuery.Message.Chat.ID
+	h.stateManager.SetState(chatID, states.AssistantSubNoteWaitText, &flows.SubNoteFlowData{SubscriptionID: sub.ID})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📝 Заметка к подписке #%d\n\n", sub.ID)
+	if sub.Note != "" {
+		fmt.Fprintf(&b, "Сейчас: %s\n\n", sub.Note)
+	}
+	fmt.Fprintf(&b, "Отправьте текст заметки, например «платит 5-го» или «пользуется iPhone» (до %d символов).", maxNoteLength)
+	if sub.Note != "" {
+		b.WriteString("\nЧтобы удалить заметку, отправьте «-».")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+		),
+	)
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// Handle обрабатывает текущее состояние
+func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
+	ctx := context.Background()
+	chatID := extractChatID(update)
+
+	flowData, err := h.stateManager.GetSubNoteData(chatID)
+	if err != nil {
+		if update.CallbackQuery != nil {
+			_ = h.answerCallback(update.CallbackQuery.ID, "")
+		}
+		return h.sendMessage(chatID, "Ошибка получения данных флоу")
+	}
+
+	switch state {
+	case states.AssistantSubNoteWaitText:
+		return h.handleText(ctx, update, flowData)
+	default:
+		return fmt.Errorf("unknown state: %s", state)
+	}
+}
+
+// handleText сохраняет заметку и предлагает открыть карточку подписки
+func (h *Handler) handleText(ctx context.Context, update *tgbotapi.Update, flowData *flows.SubNoteFlowData) error {
+	if update.Message == nil {
+		if update.CallbackQuery != nil {
+			_ = h.answerCallback(update.CallbackQuery.ID, "")
+		}
+		return nil
+	}
+	chatID := update.Message.Chat.ID
+
+	note, err := ParseNote(update.Message.Text)
+	if err != nil {
+		return h.sendMessage(chatID, "❌ "+err.Error()+". Отправьте другой текст или «-», чтобы удалить заметку.")
+	}
+
+	if err := h.storage.SetSubscriptionNote(ctx, flowData.SubscriptionID, note); err != nil {
+		h.logger.Error("Failed to save subscription note", "error", err, "sub_id", flowData.SubscriptionID)
+		return h.sendMessage(chatID, "❌ Не удалось сохранить заметку, попробуйте ещё раз")
+	}
+	h.stateManager.Clear(chatID)
+
+	h.logger.Info("Subscription note updated",
+		"audit", true,
+		"telegram_id", update.Message.From.ID,
+		"sub_id", flowData.SubscriptionID,
+		"cleared", note == "",
+	)
+
+	text := fmt.Sprintf("✅ Заметка к подписке #%d сохранена", flowData.SubscriptionID)
+	if note == "" {
+		text = fmt.Sprintf("🗑 Заметка к подписке #%d удалена", flowData.SubscriptionID)
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📋 Открыть подписку", fmt.Sprintf("sub_view:%d", flowData.SubscriptionID)),
+		),
+	)
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+func (h *Handler) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := h.bot.Request(callback)
+	return err
+}
+
+func (h *Handler) sendMessage(chatID int64, text string) error {
+	_, err := h.bot.Send(tgbotapi.NewMessage(chatID, text))
+	return err
+}
+
+func extractChatID(update *tgbotapi.Update) int64 {
+	if update.Message != nil {
+		return update.Message.Chat.ID
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		return update.CallbackQuery.Message.Chat.ID
+	}
+	return 0
+}
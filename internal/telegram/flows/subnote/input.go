@@ -0,0 +1,30 @@
+package subnote
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxNoteLength - заметка выводится в карточках и списках, поэтому держим ее короткой
+const maxNoteLength = 200
+
+// clearNoteText - ответ, которым ассистент удаляет заметку
+const clearNoteText = "-"
+
+// ParseNote проверяет введенную заметку: "-" удаляет заметку (пустая строка),
+// переносы строк схлопываются в пробелы, чтобы заметка оставалась одной строкой в списках
+func ParseNote(text string) (string, error) {
+	note := strings.Join(strings.Fields(text), " ")
+	if note == "" {
+		return "", errors.New("заметка пустая")
+	}
+	if note == clearNoteText {
+		return "", nil
+	}
+	if utf8.RuneCountInString(note) > maxNoteLength {
+		return "", fmt.Errorf("заметка длиннее %d символов", maxNoteLength)
+	}
+	return note, nil
+}
@@ -0,0 +1,34 @@
+package subnote
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNote(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", text: "платит 5-го", want: "платит 5-го"},
+		{name: "whitespace collapsed", text: "  пользуется\n iPhone  ", want: "пользуется iPhone"},
+		{name: "dash clears", text: " - ", want: ""},
+		{name: "empty", text: "   ", wantErr: true},
+		{name: "max length", text: strings.Repeat("я", maxNoteLength), want: strings.Repeat("я", maxNoteLength)},
+		{name: "too long", text: strings.Repeat("я", maxNoteLength+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNote(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseNote(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseNote(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
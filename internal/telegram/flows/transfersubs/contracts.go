@@ -0,0 +1,37 @@
+package transfersubs
+
+import (
+	"context"
+
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type (
+	botApi interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+		Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+		GetChat(config tgbotapi.ChatInfoConfig) (tgbotapi.Chat, error)
+	}
+
+	stateManager interface {
+		Clear(chatID int64)
+		GetTransferSubsData(chatID int64) (*flows.TransferSubsFlowData, error)
+		SetState(chatID int64, state states.State, data any)
+	}
+
+	subscriptionStorage interface {
+		CountSubscriptionsByCreator(ctx context.Context, telegramID int64) (int, error)
+		ListSubscriptionsPage(ctx context.Context, criteria subs.PageCriteria) ([]storage.SubscriptionDetails, error)
+		ReassignSubscriptions(ctx context.Context, fromTelegramID, toTelegramID int64, subscriptionIDs []int64) (int64, error)
+	}
+
+	// staffProvider - текущие админы и ассистенты с учетом ролей из /set_role
+	staffProvider interface {
+		StaffIDs() []int64
+	}
+)
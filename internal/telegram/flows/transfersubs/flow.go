@@ -0,0 +1,400 @@
+package transfersubs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxCandidates - сколько подписок показывать при ручном выборе
+const maxCandidates = 40
+
+type Handler struct {
+	bot                 botApi
+	stateManager        stateManager
+	subscriptionStorage subscriptionStorage
+	staff               staffProvider
+	logger              *slog.Logger
+}
+
+// NewHandler создает флоу; staff - ассистенты и админы, между которыми можно передавать подписки
+func NewHandler(
+	bot botApi,
+	sm stateManager,
+	subStorage subscriptionStorage,
+	staff staffProvider,
+	logger *slog.Logger,
+) *Handler {
+	return &Handler{
+		bot:                 bot,
+		stateManager:        sm,
+		subscriptionStorage: subStorage,
+		staff:               staff,
+		logger:              logger,
+	}
+}
+
+// Start начинает flow передачи подписок: админ выбирает, чьи подписки передать
+func (h *Handler) Start(ctx context.Context, adminTelegramID, chatID int64) error {
+	flowData := &flows.TransferSubsFlowData{AdminTelegramID: adminTelegramID}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, staffID := range h.staff.StaffIDs() {
+		count, err := h.subscriptionStorage.CountSubscriptionsByCreator(ctx, staffID)
+		if err != nil {
+			h.logger.Error("Failed to count assistant subscriptions", "error", err, "telegram_id", staffID)
+			return h.sendError(chatID, "❌ Ошибка загрузки подписок")
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%s · %d", telegram.UserName(h.bot, staffID), count),
+				fmt.Sprintf("tsub_from:%d", staffID),
+			),
+		))
+	}
+	rows = append(rows, cancelRow())
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return h.show(chatID, flowData, states.AdminTransferSubsWaitFrom, "🔀 Передача подписок\n\nЧьи подписки передать?", &keyboard)
+}
+
+// Handle обрабатывает текущее состояние
+func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
+	ctx := context.Background()
+
+	if update.CallbackQuery == nil {
+		return h.sendError(extractChatID(update), "Выберите вариант кнопками")
+	}
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	flowData, err := h.stateManager.GetTransferSubsData(chatID)
+	if err != nil {
+		_ = h.answerCallback(update.CallbackQuery.ID, "")
+		return h.sendError(chatID, "Ошибка получения данных флоу")
+	}
+
+	switch state {
+	case states.AdminTransferSubsWaitFrom:
+		return h.handleFrom(ctx, update.CallbackQuery, flowData)
+	case states.AdminTransferSubsWaitScope:
+		return h.handleScope(ctx, update.CallbackQuery, flowData)
+	case states.AdminTransferSubsWaitSelection:
+		return h.handleSelection(update.CallbackQuery, flowData)
+	case states.AdminTransferSubsWaitTo:
+		return h.handleTo(update.CallbackQuery, flowData)
+	case states.AdminTransferSubsWaitConfirm:
+		return h.handleConfirm(ctx, update.CallbackQuery, flowData)
+	default:
+		return fmt.Errorf("unknown state: %s", state)
+	}
+}
+
+// handleFrom запоминает исходного ассистента и спрашивает, все ли подписки передать
+func (h *Handler) handleFrom(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery, flowData *flows.TransferSubsFlowData) error {
+	fromID, ok := parseID(callbackQuery.Data, "tsub_from:")
+	if !ok {
+		return h.answerCallback(callbackQuery.ID, "")
+	}
+
+	count, err := h.subscriptionStorage.CountSubscriptionsByCreator(ctx, fromID)
+	if err != nil {
+		h.logger.Error("Failed to count assistant subscriptions", "error", err, "telegram_id", fromID)
+		return h.answerCallback(callbackQuery.ID, "Ошибка загрузки подписок")
+	}
+	if count == 0 {
+		return h.answerCallback(callbackQuery.ID, "У ассистента нет подписок")
+	}
+	_ = h.answerCallback(callbackQuery.ID, "")
+
+	flowData.FromTelegramID = fromID
+	flowData.TotalCount = count
+
+	text := fmt.Sprintf("🔀 Передача подписок\n\nУ %s подписок: %d. Что передать?", telegram.UserName(h.bot, fromID), count)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("📦 Все подписки (%d)", count), "tsub_all"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("☑️ Выбрать подписки", "tsub_pick"),
+		),
+		cancelRow(),
+	)
+	return h.show(callbackQuery.Message.Chat.ID, flowData, states.AdminTransferSubsWaitScope, text, &keyboard)
+}
+
+// handleScope выбирает передачу всех подписок или ручной выбор
+func (h *Handler) handleScope(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery, flowData *flows.TransferSubsFlowData) error {
+	chatID := callbackQuery.Message.Chat.ID
+
+	switch callbackQuery.Data {
+	case "tsub_all":
+		_ = h.answerCallback(callbackQuery.ID, "")
+		flowData.All = true
+		flowData.Selected = nil
+		return h.showTo(chatID, flowData)
+	case "tsub_pick":
+		page, err := h.subscriptionStorage.ListSubscriptionsPage(ctx, subs.PageCriteria{
+			CreatedByTelegramID: flowData.FromTelegramID,
+			Status:              []subs.Status{subs.StatusActive, subs.StatusExpired},
+			Limit:               maxCandidates,
+		})
+		if err != nil {
+			h.logger.Error("Failed to list subscriptions for transfer", "error", err)
+			return h.answerCallback(callbackQuery.ID, "Ошибка загрузки подписок")
+		}
+		if len(page) == 0 {
+			return h.answerCallback(callbackQuery.ID, "Нет активных или истекших подписок, передайте все")
+		}
+		_ = h.answerCallback(callbackQuery.ID, "")
+
+		flowData.All = false
+		flowData.Candidates = make([]flows.TransferSubsCandidate, 0, len(page))
+		for _, details := range page {
+			flowData.Candidates = append(flowData.Candidates, flows.TransferSubsCandidate{
+				SubscriptionID: details.Subscription.ID,
+				Label:          subscriptionLabel(details),
+			})
+		}
+		return h.showSelection(chatID, flowData)
+	}
+
+	return h.answerCallback(callbackQuery.ID, "")
+}
+
+// subscriptionLabel - текст кнопки подписки: "#12 · 996555123456 · до 01.02.2026"
+func subscriptionLabel(details storage.SubscriptionDetails) string {
+	sub := details.Subscription
+	label := fmt.Sprintf("#%d", sub.ID)
+	if sub.ClientWhatsApp != nil {
+		label += " · " + *sub.ClientWhatsApp
+	}
+	if sub.ExpiresAt != nil {
+		label += " · до " + sub.ExpiresAt.Format("02.01.2006")
+	}
+	return label
+}
+
+// showSelection показывает подписки исходного ассистента с отметками выбора
+func (h *Handler) showSelection(chatID int64, flowData *flows.TransferSubsFlowData) error {
+	text := "🔀 Отметьте подписки для передачи"
+	if len(flowData.Candidates) == maxCandidates {
+		text += fmt.Sprintf("\n\nПоказаны последние %d активных и истекших подписок.", maxCandidates)
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(flowData.Candidates)+2)
+	for _, c := range flowData.Candidates {
+		mark := "⬜"
+		if isSelected(flowData.Selected, c.SubscriptionID) {
+			mark = "☑️"
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(mark+" "+c.Label, fmt.Sprintf("tsub_tgl:%d", c.SubscriptionID)),
+		))
+	}
+	if len(flowData.Selected) > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("➡️ Далее: %d шт.", len(flowData.Selected)), "tsub_next"),
+		))
+	}
+	rows = append(rows, cancelRow())
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return h.show(chatID, flowData, states.AdminTransferSubsWaitSelection, text, &keyboard)
+}
+
+// handleSelection обрабатывает отметки подписок
+func (h *Handler) handleSelection(callbackQuery *tgbotapi.CallbackQuery, flowData *flows.TransferSubsFlowData) error {
+	_ = h.answerCallback(callbackQuery.ID, "")
+	chatID := callbackQuery.Message.Chat.ID
+
+	if callbackQuery.Data == "tsub_next" && len(flowData.Selected) > 0 {
+		return h.showTo(chatID, flowData)
+	}
+	subID, ok := parseID(callbackQuery.Data, "tsub_tgl:")
+	if !ok {
+		return nil
+	}
+	flowData.Selected = toggle(flowData.Selected, subID)
+	return h.showSelection(chatID, flowData)
+}
+
+// showTo показывает сотрудников, которым можно передать подписки
+func (h *Handler) showTo(chatID int64, flowData *flows.TransferSubsFlowData) error {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, staffID := range h.staff.StaffIDs() {
+		if staffID == flowData.FromTelegramID {
+			continue
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(telegram.UserName(h.bot, staffID), fmt.Sprintf("tsub_to:%d", staffID)),
+		))
+	}
+	rows = append(rows, cancelRow())
+
+	count := transferCount(flowData.All, flowData.TotalCount, flowData.Selected)
+	text := fmt.Sprintf("🔀 Кому передать подписки (%d шт.)?", count)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return h.show(chatID, flowData, states.AdminTransferSubsWaitTo, text, &keyboard)
+}
+
+// handleTo запоминает получателя и просит подтвердить передачу
+func (h *Handler) handleTo(callbackQuery *tgbotapi.CallbackQuery, flowData *flows.TransferSubsFlowData) error {
+	toID, ok := parseID(callbackQuery.Data, "tsub_to:")
+	if !ok || toID == flowData.FromTelegramID {
+		return h.answerCallback(callbackQuery.ID, "")
+	}
+	_ = h.answerCallback(callbackQuery.ID, "")
+
+	flowData.ToTelegramID = toID
+
+	count := transferCount(flowData.All, flowData.TotalCount, flowData.Selected)
+	text := fmt.Sprintf("🔀 Передать %d подписок от %s к %s?\n\n"+
+		"Напоминания об истечении и продления будут приходить новому ассистенту.",
+		count, telegram.UserName(h.bot, flowData.FromTelegramID), telegram.UserName(h.bot, toID))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Передать", "tsub_confirm"),
+		),
+		cancelRow(),
+	)
+	return h.show(callbackQuery.Message.Chat.ID, flowData, states.AdminTransferSubsWaitConfirm, text, &keyboard)
+}
+
+// handleConfirm переназначает подписки и уведомляет нового ассистента
+func (h *Handler) handleConfirm(ctx context.Context, callbackQuery *tgbotapi.CallbackQuery, flowData *flows.TransferSubsFlowData) error {
+	chatID := callbackQuery.Message.Chat.ID
+	if callbackQuery.Data != "tsub_confirm" {
+		return h.answerCallback(callbackQuery.ID, "")
+	}
+
+	var ids []int64
+	if !flowData.All {
+		ids = flowData.Selected
+	}
+	transferred, err := h.subscriptionStorage.ReassignSubscriptions(ctx, flowData.FromTelegramID, flowData.ToTelegramID, ids)
+	if err != nil {
+		h.logger.Error("Failed to reassign subscriptions", "error", err,
+			"from_telegram_id", flowData.FromTelegramID, "to_telegram_id", flowData.ToTelegramID)
+		_ = h.answerCallback(callbackQuery.ID, "Ошибка")
+		return h.sendError(chatID, "❌ Ошибка передачи подписок")
+	}
+	_ = h.answerCallback(callbackQuery.ID, "Подписки переданы")
+
+	h.logger.Info("Subscriptions reassigned",
+		"audit", true,
+		"admin_telegram_id", flowData.AdminTelegramID,
+		"from_telegram_id", flowData.FromTelegramID,
+		"to_telegram_id", flowData.ToTelegramID,
+		"all", flowData.All,
+		"subscription_ids", ids,
+		"transferred", transferred,
+	)
+
+	fromName := telegram.UserName(h.bot, flowData.FromTelegramID)
+	toName := telegram.UserName(h.bot, flowData.ToTelegramID)
+	h.notifyReceiver(flowData, fromName, transferred)
+
+	h.stateManager.Clear(chatID)
+	text := fmt.Sprintf("✅ Передано подписок: %d\nОт %s к %s", transferred, fromName, toName)
+	if flowData.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+	_, err = h.bot.Send(tgbotapi.NewMessage(chatID, text))
+	return err
+}
+
+// notifyReceiver сообщает ассистенту, что ему передали подписки
+func (h *Handler) notifyReceiver(flowData *flows.TransferSubsFlowData, fromName string, transferred int64) {
+	if transferred == 0 || flowData.ToTelegramID == flowData.AdminTelegramID {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📥 Вам переданы подписки от %s: %d\n", fromName, transferred)
+	if !flowData.All {
+		for _, c := range flowData.Candidates {
+			if isSelected(flowData.Selected, c.SubscriptionID) {
+				b.WriteString("\n" + c.Label)
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nТеперь напоминания и продления по ним приходят вам. Список — /my_subs")
+
+	if _, err := h.bot.Send(tgbotapi.NewMessage(flowData.ToTelegramID, b.String())); err != nil {
+		h.logger.Error("Failed to notify assistant about transferred subscriptions", "error", err, "telegram_id", flowData.ToTelegramID)
+	}
+}
+
+// show редактирует сообщение флоу или отправляет новое и переводит флоу в состояние state
+func (h *Handler) show(chatID int64, flowData *flows.TransferSubsFlowData, state states.State, text string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	h.stateManager.SetState(chatID, state, flowData)
+
+	if flowData.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(chatID, *flowData.MessageID, text)
+		editMsg.ReplyMarkup = keyboard
+		return telegram.SafeEdit(h.bot, editMsg, "")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	sentMsg, err := h.bot.Send(msg)
+	if err != nil {
+		return err
+	}
+	flowData.MessageID = &sentMsg.MessageID
+	return nil
+}
+
+func cancelRow() []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+	)
+}
+
+// parseID разбирает "prefix:id"
+func parseID(data, prefix string) (int64, bool) {
+	if !strings.HasPrefix(data, prefix) {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(data, prefix), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := h.bot.Request(callback)
+	return err
+}
+
+func (h *Handler) sendError(chatID int64, message string) error {
+	msg := tgbotapi.NewMessage(chatID, message)
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+func extractChatID(update *tgbotapi.Update) int64 {
+	if update.Message != nil {
+		return update.Message.Chat.ID
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		return update.CallbackQuery.Message.Chat.ID
+	}
+	return 0
+}
@@ -0,0 +1,28 @@
+package transfersubs
+
+// toggle добавляет подписку в выбор или убирает ее, если она уже выбрана
+func toggle(selected []int64, id int64) []int64 {
+	for i, selectedID := range selected {
+		if selectedID == id {
+			return append(selected[:i:i], selected[i+1:]...)
+		}
+	}
+	return append(selected, id)
+}
+
+func isSelected(selected []int64, id int64) bool {
+	for _, selectedID := range selected {
+		if selectedID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// transferCount возвращает сколько подписок будет передано
+func transferCount(all bool, totalCount int, selected []int64) int {
+	if all {
+		return totalCount
+	}
+	return len(selected)
+}
@@ -0,0 +1,27 @@
+package transfersubs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToggle(t *testing.T) {
+	selected := toggle(nil, 3)
+	selected = toggle(selected, 8)
+	selected = toggle(selected, 3)
+	if want := []int64{8}; !reflect.DeepEqual(selected, want) {
+		t.Fatalf("toggle() = %v, want %v", selected, want)
+	}
+	if !isSelected(selected, 8) || isSelected(selected, 3) {
+		t.Errorf("isSelected() mismatch for %v", selected)
+	}
+}
+
+func TestTransferCount(t *testing.T) {
+	if got := transferCount(true, 25, []int64{1}); got != 25 {
+		t.Errorf("transferCount(all) = %d, want 25", got)
+	}
+	if got := transferCount(false, 25, []int64{1, 2}); got != 2 {
+		t.Errorf("transferCount(selected) = %d, want 2", got)
+	}
+}
@@ -0,0 +1,31 @@
+package watemplate
+
+import (
+	"context"
+
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/watemplates"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type (
+	botApi interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+		Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	}
+
+	stateManager interface {
+		Clear(chatID int64)
+		GetWATemplateData(chatID int64) (*flows.WATemplateFlowData, error)
+		SetState(chatID int64, state states.State, data any)
+	}
+
+	templateStorage interface {
+		ListWhatsAppTemplates(ctx context.Context) ([]*watemplates.Template, error)
+		SaveWhatsAppTemplate(ctx context.Context, t watemplates.Template) error
+		DeleteWhatsAppTemplate(ctx context.Context, name watemplates.Name, lang clientlang.Language) (bool, error)
+	}
+)
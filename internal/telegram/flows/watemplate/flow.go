@@ -0,0 +1,322 @@
+package watemplate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/watemplates"
+	"kurut-bot/internal/telegram/flows"
+	"kurut-bot/internal/telegram/messages"
+	"kurut-bot/internal/telegram/states"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// defaultLangCode - основной текст в callback data, пустой язык в ней не отличить от отсутствующего
+const defaultLangCode = "def"
+
+// Handler - /templates: админ меняет тексты предзаполненных сообщений WhatsApp.
+// Шаблон и язык выбираются кнопками wtpl:, новый текст приходит сообщением
+type Handler struct {
+	bot          botApi
+	stateManager stateManager
+	storage      templateStorage
+	logger       *slog.Logger
+}
+
+func NewHandler(bot botApi, sm stateManager, storage templateStorage, logger *slog.Logger) *Handler {
+	return &Handler{
+		bot:          bot,
+		stateManager: sm,
+		storage:      storage,
+		logger:       logger,
+	}
+}
+
+// Execute показывает список шаблонов
+func (h *Handler) Execute(ctx context.Context, chatID int64) error {
+	msg := tgbotapi.NewMessage(chatID, templatesListText)
+	msg.ReplyMarkup = templatesListKeyboard()
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+// HandleCallback обрабатывает кнопки wtpl:list, wtpl:n:<шаблон>, wtpl:v|e|r:<шаблон>:<язык>
+func (h *Handler) HandleCallback(ctx context.Context, adminTelegramID int64, callbackQuery *tgbotapi.CallbackQuery) error {
+	chatID := callbackQuery.Message.Chat.ID
+	messageID := callbackQuery.Message.MessageID
+
+	parts := strings.Split(strings.TrimPrefix(callbackQuery.Data, "wtpl:"), ":")
+	if parts[0] == "list" {
+		_ = h.answerCallback(callbackQuery.ID, "")
+		return h.editMessage(chatID, messageID, templatesListText, templatesListKeyboard())
+	}
+	if len(parts) < 2 || !watemplates.Name(parts[1]).Valid() {
+		return h.answerCallback(callbackQuery.ID, "Неизвестный шаблон")
+	}
+	name := watemplates.Name(parts[1])
+
+	if parts[0] == "n" {
+		_ = h.answerCallback(callbackQuery.ID, "")
+		return h.editMessage(chatID, messageID, fmt.Sprintf("📝 %s\n\nВыберите язык:", name.Title()), languagesKeyboard(name))
+	}
+
+	if len(parts) != 3 {
+		return h.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+	lang, ok := parseLang(parts[2])
+	if !ok {
+		return h.answerCallback(callbackQuery.ID, "Неизвестный язык")
+	}
+
+	switch parts[0] {
+	case "v":
+		_ = h.answerCallback(callbackQuery.ID, "")
+		return h.showTemplate(ctx, chatID, messageID, name, lang)
+	case "e":
+		_ = h.answerCallback(callbackQuery.ID, "")
+		h.stateManager.SetState(chatID, states.AdminWATemplateWaitText, &flows.WATemplateFlowData{Name: name, Language: lang})
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(
+			"✏️ %s · %s\n\nОтправьте новый текст (до %d символов). Подстановки: %s",
+			name.Title(), langTitle(lang), watemplates.MaxTextLength, strings.Join(watemplates.Placeholders, ", ")))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+			),
+		)
+		_, err := h.bot.Send(msg)
+		return err
+	case "r":
+		deleted, err := h.storage.DeleteWhatsAppTemplate(ctx, name, lang)
+		if err != nil {
+			h.logger.Error("Failed to reset WhatsApp template", "error", err, "name", name, "language", lang)
+			return h.answerCallback(callbackQuery.ID, "Ошибка")
+		}
+		if !deleted {
+			_ = h.answerCallback(callbackQuery.ID, "Текст и так встроенный")
+			return nil
+		}
+		h.reloadOverrides(ctx)
+		h.logger.Info("WhatsApp template reset",
+			"audit", true,
+			"admin_telegram_id", adminTelegramID,
+			"name", name,
+			"language", lang,
+		)
+		_ = h.answerCallback(callbackQuery.ID, "Встроенный текст восстановлен")
+		return h.showTemplate(ctx, chatID, messageID, name, lang)
+	default:
+		return h.answerCallback(callbackQuery.ID, "Неверный формат")
+	}
+}
+
+// Handle обрабатывает текущее состояние
+func (h *Handler) Handle(update *tgbotapi.Update, state states.State) error {
+	ctx := context.Background()
+	chatID := extractChatID(update)
+
+	flowData, err := h.stateManager.GetWATemplateData(chatID)
+	if err != nil {
+		if update.CallbackQuery != nil {
+			_ = h.answerCallback(update.CallbackQuery.ID, "")
+		}
+		return h.sendMessage(chatID, "Ошибка получения данных флоу")
+	}
+
+	switch state {
+	case states.AdminWATemplateWaitText:
+		return h.handleText(ctx, update, flowData)
+	default:
+		return fmt.Errorf("unknown state: %s", state)
+	}
+}
+
+// handleText проверяет и сохраняет новый текст шаблона
+func (h *Handler) handleText(ctx context.Context, update *tgbotapi.Update, flowData *flows.WATemplateFlowData) error {
+	if update.Message == nil {
+		if update.CallbackQuery != nil {
+			_ = h.answerCallback(update.CallbackQuery.ID, "")
+		}
+		return nil
+	}
+	chatID := update.Message.Chat.ID
+
+	text, err := watemplates.Validate(update.Message.Text)
+	if err != nil {
+		return h.sendMessage(chatID, "❌ "+err.Error()+". Отправьте другой текст.")
+	}
+
+	err = h.storage.SaveWhatsAppTemplate(ctx, watemplates.Template{
+		Name:                flowData.Name,
+		Language:            flowData.Language,
+		Text:                text,
+		UpdatedByTelegramID: update.Message.From.ID,
+	})
+	if err != nil {
+		h.logger.Error("Failed to save WhatsApp template", "error", err, "name", flowData.Name, "language", flowData.Language)
+		return h.sendMessage(chatID, "❌ Не удалось сохранить шаблон, попробуйте ещё раз")
+	}
+	h.stateManager.Clear(chatID)
+	h.reloadOverrides(ctx)
+
+	h.logger.Info("WhatsApp template updated",
+		"audit", true,
+		"admin_telegram_id", update.Message.From.ID,
+		"name", flowData.Name,
+		"language", flowData.Language,
+	)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ Шаблон «%s» (%s) сохранен\n\nПример:\n%s",
+		flowData.Name.Title(), langTitle(flowData.Language), watemplates.Render(text, exampleVars)))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📝 К шаблонам", "wtpl:list"),
+		),
+	)
+	_, err = h.bot.Send(msg)
+	return err
+}
+
+// showTemplate показывает текущий текст шаблона на языке и встроенный, если текст изменен
+func (h *Handler) showTemplate(ctx context.Context, chatID int64, messageID int, name watemplates.Name, lang clientlang.Language) error {
+	custom, err := h.findTemplate(ctx, name, lang)
+	if err != nil {
+		h.logger.Error("Failed to list WhatsApp templates", "error", err)
+		return h.sendMessage(chatID, "❌ Ошибка получения шаблонов")
+	}
+
+	builtin := messages.BuiltinWhatsAppText(name, lang)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📝 %s · %s\n\n", name.Title(), langTitle(lang))
+	if custom != nil {
+		fmt.Fprintf(&b, "Текущий текст (изменен %s):\n%s\n\nВстроенный текст:\n%s", custom.UpdatedAt.Format("02.01.2006"), custom.Text, builtin)
+	} else {
+		fmt.Fprintf(&b, "Текущий текст (встроенный):\n%s", builtin)
+	}
+	fmt.Fprintf(&b, "\n\nПодстановки: %s — номер клиента, дата окончания, тариф.", strings.Join(watemplates.Placeholders, ", "))
+	if lang == clientlang.Default {
+		b.WriteString("\nОсновной текст получают клиенты, для языка которых нет своего текста.")
+	}
+
+	code := langCode(lang)
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Изменить", fmt.Sprintf("wtpl:e:%s:%s", name, code)),
+		),
+	}
+	if custom != nil {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("↩️ По умолчанию", fmt.Sprintf("wtpl:r:%s:%s", name, code)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Языки", fmt.Sprintf("wtpl:n:%s", name)),
+	))
+
+	return h.editMessage(chatID, messageID, b.String(), tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+func (h *Handler) findTemplate(ctx context.Context, name watemplates.Name, lang clientlang.Language) (*watemplates.Template, error) {
+	templates, err := h.storage.ListWhatsAppTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		if t.Name == name && t.Language == lang {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+// reloadOverrides применяет изменения в процессе бота сразу; воркеры перечитывают шаблоны при каждом запуске
+func (h *Handler) reloadOverrides(ctx context.Context) {
+	templates, err := h.storage.ListWhatsAppTemplates(ctx)
+	if err != nil {
+		h.logger.Error("Failed to reload WhatsApp templates", "error", err)
+		return
+	}
+	messages.SetWhatsAppOverrides(templates)
+}
+
+// exampleVars - значения для примера после сохранения
+var exampleVars = watemplates.Vars{Client: "996700123456", ExpiresAt: "01.12.2026", Tariff: "1 месяц"}
+
+const templatesListText = "📝 Шаблоны сообщений WhatsApp\n\nВыберите шаблон, чтобы посмотреть или изменить текст:"
+
+func templatesListKeyboard() tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, name := range watemplates.Names {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(name.Title(), fmt.Sprintf("wtpl:n:%s", name)),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func languagesKeyboard(name watemplates.Name) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, lang := range watemplates.Languages {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(langTitle(lang), fmt.Sprintf("wtpl:v:%s:%s", name, langCode(lang))),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Шаблоны", "wtpl:list"),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// langTitle - название языка; основной текст не "по умолчанию", а общий для языков без своего текста
+func langTitle(lang clientlang.Language) string {
+	if lang == clientlang.Default {
+		return "Основной"
+	}
+	return lang.Title()
+}
+
+func langCode(lang clientlang.Language) string {
+	if lang == clientlang.Default {
+		return defaultLangCode
+	}
+	return string(lang)
+}
+
+func parseLang(code string) (clientlang.Language, bool) {
+	if code == defaultLangCode {
+		return clientlang.Default, true
+	}
+	lang := clientlang.Language(code)
+	return lang, lang.IsSupported()
+}
+
+func (h *Handler) answerCallback(callbackID string, text string) error {
+	callback := tgbotapi.NewCallback(callbackID, text)
+	_, err := h.bot.Request(callback)
+	return err
+}
+
+func (h *Handler) editMessage(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard)
+	return telegram.SafeEdit(h.bot, edit, "")
+}
+
+func (h *Handler) sendMessage(chatID int64, text string) error {
+	_, err := h.bot.Send(tgbotapi.NewMessage(chatID, text))
+	return err
+}
+
+func extractChatID(update *tgbotapi.Update) int64 {
+	if update.Message != nil {
+		return update.Message.Chat.ID
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		return update.CallbackQuery.Message.Chat.ID
+	}
+	return 0
+}
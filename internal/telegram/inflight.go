@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"sync"
+	"time"
+)
+
+// slowCommands - команды, которые долго считаются; повторный вызов, пока первый не завершился, игнорируется
+var slowCommands = map[string]bool{
+	"stats":           true,
+	"top_referrers":   true,
+	"cohorts":         true,
+	"waplan":          true,
+	"overdue":         true,
+	"expiring":        true,
+	"exp3":            true,
+	"servers":         true,
+	"clients":         true,
+	"find":            true,
+	"export_subs":     true,
+	"export_payments": true,
+	"db_stats":        true,
+}
+
+type inflightKey struct {
+	chatID  int64
+	command string
+}
+
+// commandTracker отслеживает выполняющиеся медленные команды в каждом чате.
+// Обновления обрабатываются по очереди, поэтому дубликат, набранный во время выполнения первой команды,
+// приходит уже после ее завершения - такие дубликаты распознаются по времени отправки сообщения
+type commandTracker struct {
+	mu       sync.Mutex
+	running  map[inflightKey]bool
+	finished map[inflightKey]time.Time
+	now      func() time.Time
+}
+
+func newCommandTracker() *commandTracker {
+	return &commandTracker{
+		running:  make(map[inflightKey]bool),
+		finished: make(map[inflightKey]time.Time),
+		now:      time.Now,
+	}
+}
+
+// Begin отмечает начало команды. sentAt - время отправки сообщения с командой.
+// Возвращает false, если такая же команда в этом чате еще выполняется или сообщение
+// было отправлено до завершения предыдущего вызова. Иначе после выполнения нужно вызвать done
+func (t *commandTracker) Begin(chatID int64, command string, sentAt time.Time) (done func(), ok bool) {
+	key := inflightKey{chatID: chatID, command: command}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.running[key] {
+		return nil, false
+	}
+	// Время сообщения в Telegram с точностью до секунды: сравниваем с началом секунды завершения,
+	// чтобы не отбросить команду, отправленную сразу после ответа
+	if finishedAt, exists := t.finished[key]; exists && sentAt.Before(finishedAt.Truncate(time.Second)) {
+		return nil, false
+	}
+
+	t.running[key] = true
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.running, key)
+		t.finished[key] = t.now()
+	}, true
+}
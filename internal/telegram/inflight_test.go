@@ -0,0 +1,45 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCommandTrackerConcurrentDuplicate(t *testing.T) {
+	tracker := newCommandTracker()
+	sentAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	done, ok := tracker.Begin(1, "stats", sentAt)
+	if !ok {
+		t.Fatal("Begin() first call = false, want true")
+	}
+	if _, ok := tracker.Begin(1, "stats", sentAt); ok {
+		t.Error("Begin() while running = true, want false")
+	}
+	if _, ok := tracker.Begin(2, "stats", sentAt); !ok {
+		t.Error("Begin() in another chat = false, want true")
+	}
+	if _, ok := tracker.Begin(1, "overdue", sentAt); !ok {
+		t.Error("Begin() of another command = false, want true")
+	}
+	done()
+}
+
+func TestCommandTrackerQueuedDuplicate(t *testing.T) {
+	tracker := newCommandTracker()
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	finish := start.Add(5*time.Second + 300*time.Millisecond)
+	tracker.now = func() time.Time { return finish }
+
+	done, _ := tracker.Begin(1, "stats", start)
+	done()
+
+	// Набрана, пока первая считалась, и обработана после нее
+	if _, ok := tracker.Begin(1, "stats", start.Add(2*time.Second)); ok {
+		t.Error("Begin() for message sent while running = true, want false")
+	}
+	// Отправлена в ту же секунду, что пришел ответ: не отбрасываем
+	if _, ok := tracker.Begin(1, "stats", start.Add(5*time.Second)); !ok {
+		t.Error("Begin() for message sent after finish = false, want true")
+	}
+}
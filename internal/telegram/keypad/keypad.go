@@ -0,0 +1,137 @@
+package keypad
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"kurut-bot/internal/telegram/flows"
+)
+
+// Клавиатура не хранит состояние: текущий ввод целиком лежит в callback data кнопок.
+// kp:<ввод> - перерисовать клавиатуру с новым вводом, kp_ok:<значение> - значение выбрано
+const (
+	editPrefix   = "kp:"
+	submitPrefix = "kp_ok:"
+
+	keyBackspace = "del"
+	keyDot       = "."
+
+	defaultMaxLen = 7
+)
+
+// Config - настройки клавиатуры для конкретного поля
+type Config struct {
+	Presets []string // значения для быстрого выбора, отправляются сразу
+	Decimal bool     // разрешить дробную часть
+	MaxLen  int      // максимальная длина ввода, 0 - по умолчанию
+	Back    bool     // показать кнопку возврата на предыдущий шаг флоу
+}
+
+// Action - что сделал пользователь нажатием кнопки
+type Action int
+
+const (
+	ActionNone   Action = iota // callback не от клавиатуры
+	ActionEdit                 // ввод изменился, нужно перерисовать клавиатуру
+	ActionSubmit               // значение выбрано
+)
+
+// ParseCallback разбирает callback data кнопки клавиатуры
+func ParseCallback(data string) (Action, string) {
+	switch {
+	case strings.HasPrefix(data, submitPrefix):
+		return ActionSubmit, strings.TrimPrefix(data, submitPrefix)
+	case strings.HasPrefix(data, editPrefix):
+		return ActionEdit, strings.TrimPrefix(data, editPrefix)
+	default:
+		return ActionNone, ""
+	}
+}
+
+// Press применяет нажатие клавиши (цифра, "." или "del") к текущему вводу
+func Press(cfg Config, input, key string) string {
+	maxLen := cfg.MaxLen
+	if maxLen <= 0 {
+		maxLen = defaultMaxLen
+	}
+
+	switch key {
+	case keyBackspace:
+		if input == "" {
+			return input
+		}
+		return input[:len(input)-1]
+	case keyDot:
+		if !cfg.Decimal || strings.Contains(input, keyDot) || len(input)+1 > maxLen {
+			return input
+		}
+		if input == "" {
+			return "0."
+		}
+		return input + keyDot
+	}
+
+	if len(key) != 1 || key[0] < '0' || key[0] > '9' {
+		return input
+	}
+	// Ведущий ноль заменяем: "0" + "5" = "5"
+	if input == "0" {
+		return key
+	}
+	// Не больше двух знаков после запятой
+	if i := strings.Index(input, keyDot); i >= 0 && len(input)-i > 2 {
+		return input
+	}
+	if len(input)+1 > maxLen {
+		return input
+	}
+	return input + key
+}
+
+// Text дописывает к подсказке текущее значение ввода
+func Text(prompt, input string) string {
+	if input == "" {
+		input = "—"
+	}
+	return fmt.Sprintf("%s\n\n⌨️ Значение: %s", prompt, input)
+}
+
+// Markup строит клавиатуру для текущего ввода: пресеты, цифры, стирание, "Готово", отмена и, если нужно, "Назад"
+func Markup(cfg Config, input string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	if len(cfg.Presets) > 0 {
+		var presetRow []tgbotapi.InlineKeyboardButton
+		for _, preset := range cfg.Presets {
+			presetRow = append(presetRow, tgbotapi.NewInlineKeyboardButtonData(preset, submitPrefix+preset))
+		}
+		rows = append(rows, presetRow)
+	}
+
+	key := func(label, k string) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(label, editPrefix+Press(cfg, input, k))
+	}
+
+	for _, digits := range [][]string{{"1", "2", "3"}, {"4", "5", "6"}, {"7", "8", "9"}} {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(key(digits[0], digits[0]), key(digits[1], digits[1]), key(digits[2], digits[2])))
+	}
+
+	lastRow := tgbotapi.NewInlineKeyboardRow(key("0", "0"), key("⌫", keyBackspace))
+	if cfg.Decimal {
+		lastRow = append([]tgbotapi.InlineKeyboardButton{key(",", keyDot)}, lastRow...)
+	}
+	rows = append(rows, lastRow)
+
+	controlRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", "cancel"),
+		tgbotapi.NewInlineKeyboardButtonData("✅ Готово", submitPrefix+input),
+	)
+	if cfg.Back {
+		controlRow = append([]tgbotapi.InlineKeyboardButton{flows.BackButton()}, controlRow...)
+	}
+	rows = append(rows, controlRow)
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
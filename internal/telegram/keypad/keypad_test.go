@@ -0,0 +1,60 @@
+package keypad
+
+import "testing"
+
+func TestPress(t *testing.T) {
+	integer := Config{}
+	decimal := Config{Decimal: true}
+	short := Config{MaxLen: 3}
+
+	tests := []struct {
+		name  string
+		cfg   Config
+		input string
+		key   string
+		want  string
+	}{
+		{name: "first digit", cfg: integer, input: "", key: "5", want: "5"},
+		{name: "append digit", cfg: integer, input: "12", key: "3", want: "123"},
+		{name: "leading zero replaced", cfg: integer, input: "0", key: "7", want: "7"},
+		{name: "backspace", cfg: integer, input: "123", key: "del", want: "12"},
+		{name: "backspace empty", cfg: integer, input: "", key: "del", want: ""},
+		{name: "dot not allowed", cfg: integer, input: "12", key: ".", want: "12"},
+		{name: "dot", cfg: decimal, input: "199", key: ".", want: "199."},
+		{name: "dot on empty", cfg: decimal, input: "", key: ".", want: "0."},
+		{name: "second dot ignored", cfg: decimal, input: "1.5", key: ".", want: "1.5"},
+		{name: "two decimals max", cfg: decimal, input: "199.99", key: "1", want: "199.99"},
+		{name: "max len", cfg: short, input: "150", key: "0", want: "150"},
+		{name: "unknown key", cfg: integer, input: "1", key: "x", want: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Press(tt.cfg, tt.input, tt.key); got != tt.want {
+				t.Errorf("Press(%q, %q) = %q, want %q", tt.input, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCallback(t *testing.T) {
+	tests := []struct {
+		data       string
+		wantAction Action
+		wantValue  string
+	}{
+		{data: "kp:12", wantAction: ActionEdit, wantValue: "12"},
+		{data: "kp:", wantAction: ActionEdit, wantValue: ""},
+		{data: "kp_ok:150", wantAction: ActionSubmit, wantValue: "150"},
+		{data: "cancel", wantAction: ActionNone, wantValue: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			action, value := ParseCallback(tt.data)
+			if action != tt.wantAction || value != tt.wantValue {
+				t.Errorf("ParseCallback(%q) = %v, %q, want %v, %q", tt.data, action, value, tt.wantAction, tt.wantValue)
+			}
+		})
+	}
+}
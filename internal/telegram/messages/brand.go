@@ -0,0 +1,60 @@
+package messages
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Brand - брендинг бота: одна сборка обслуживает несколько брендов с разными токенами и настройками
+type Brand struct {
+	Name    string // название в приветствии, подписи сообщений клиентам и на странице подключения
+	LogoURL string // логотип, отправляется фото в /start; пусто - без фото
+	Support string // контакт поддержки, например @kurut_support; пусто - сообщения клиентам без подписи
+	Color   string // основной цвет страницы подключения
+}
+
+// DefaultBrand - исторический бренд, если настройки не заданы
+var DefaultBrand = Brand{Name: "Kurut VPN", Color: "#D42631"}
+
+var (
+	brandMu sync.RWMutex
+	brand   = DefaultBrand
+)
+
+// SetBrand задает бренд для всех шаблонов; пустые поля берутся из DefaultBrand. Вызывается при запуске
+func SetBrand(b Brand) {
+	if b.Name == "" {
+		b.Name = DefaultBrand.Name
+	}
+	if b.Color == "" {
+		b.Color = DefaultBrand.Color
+	}
+
+	brandMu.Lock()
+	defer brandMu.Unlock()
+	brand = b
+}
+
+// CurrentBrand возвращает бренд, заданный при запуске
+func CurrentBrand() Brand {
+	brandMu.RLock()
+	defer brandMu.RUnlock()
+	return brand
+}
+
+// Signature - подпись сообщений клиентам: бренд и контакт поддержки. Без контакта подписи нет,
+// чтобы тексты оставались прежними
+func (b Brand) Signature() string {
+	if b.Support == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\n— %s · 💬 %s", b.Name, b.Support)
+}
+
+// SupportHint - как связаться с поддержкой в сообщениях об ошибках
+func (b Brand) SupportHint() string {
+	if b.Support == "" {
+		return "обратитесь в поддержку"
+	}
+	return "напишите в поддержку: " + b.Support
+}
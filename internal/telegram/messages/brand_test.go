@@ -0,0 +1,36 @@
+package messages
+
+import (
+	"testing"
+
+	"kurut-bot/internal/stories/clientlang"
+)
+
+func TestSetBrandDefaults(t *testing.T) {
+	t.Cleanup(func() { SetBrand(DefaultBrand) })
+
+	SetBrand(Brand{Support: "@help"})
+	got := CurrentBrand()
+	if got.Name != DefaultBrand.Name || got.Color != DefaultBrand.Color || got.Support != "@help" {
+		t.Errorf("CurrentBrand() = %+v, want default name and color with support @help", got)
+	}
+}
+
+func TestWhatsAppTextSignature(t *testing.T) {
+	t.Cleanup(func() { SetBrand(DefaultBrand) })
+
+	// Без контакта поддержки тексты остаются прежними
+	SetBrand(Brand{Name: "Acme VPN"})
+	if got := WhatsAppText(clientlang.Russian, WATemplateRenewed); got != "Ваша подписка VPN продлена!" {
+		t.Errorf("WhatsAppText() without support = %q", got)
+	}
+
+	SetBrand(Brand{Name: "Acme VPN", Support: "@acme_help"})
+	want := "Ваша подписка VPN продлена!\n\n— Acme VPN · 💬 @acme_help"
+	if got := WhatsAppText(clientlang.Russian, WATemplateRenewed); got != want {
+		t.Errorf("WhatsAppText() = %q, want %q", got, want)
+	}
+	if got := CurrentBrand().SupportHint(); got != "напишите в поддержку: @acme_help" {
+		t.Errorf("SupportHint() = %q", got)
+	}
+}
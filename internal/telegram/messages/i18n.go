@@ -0,0 +1,252 @@
+package messages
+
+import "fmt"
+
+// Lang - язык интерфейса бота для пользователя (users.language)
+type Lang string
+
+const (
+	LangRU Lang = "ru"
+	LangEN Lang = "en"
+	LangKG Lang = "ky"
+)
+
+// Langs - языки, доступные в /language
+var Langs = []Lang{LangRU, LangEN, LangKG}
+
+// ParseLang разбирает язык из users.language или callback; неизвестный язык - false
+func ParseLang(s string) (Lang, bool) {
+	for _, lang := range Langs {
+		if Lang(s) == lang {
+			return lang, true
+		}
+	}
+	return LangRU, false
+}
+
+// Title возвращает название языка для кнопок
+func (l Lang) Title() string {
+	switch l {
+	case LangEN:
+		return "🇬🇧 English"
+	case LangKG:
+		return "🇰🇬 Кыргызча"
+	default:
+		return "🇷🇺 Русский"
+	}
+}
+
+// Key - ключ переводимого текста
+type Key string
+
+const (
+	KeyError                  Key = "error"
+	KeyWelcome                Key = "welcome"
+	KeyButtonMySubs           Key = "button_my_subs"
+	KeyHelpTitle              Key = "help_title"
+	KeyAssistantCommandsTitle Key = "assistant_commands_title"
+	KeyAssistantCommands      Key = "assistant_commands"
+	KeyAdminCommandsTitle     Key = "admin_commands_title"
+	KeyAdminCommands          Key = "admin_commands"
+	KeyViewerHelp             Key = "viewer_help"
+	KeyViewerOnly             Key = "viewer_only"
+	KeyLanguageChoose         Key = "language_choose"
+	KeyLanguageChanged        Key = "language_changed" // аргумент: название языка
+)
+
+// translations - тексты интерфейса по языкам; LangRU - полный набор, остальные переводы проверяются тестом
+var translations = map[Lang]map[Key]string{
+	LangRU: {
+		KeyError:                  Error,
+		KeyWelcome:                "Добро пожаловать!\n\nЭтот бот помогает ассистентам управлять подписками клиентов.",
+		KeyButtonMySubs:           "Мои подписки",
+		KeyHelpTitle:              "Доступные команды:\n\n/start — Главное меню",
+		KeyAssistantCommandsTitle: "Команды ассистента:",
+		KeyAssistantCommands: "/create_sub — Создать подписку для клиента\n" +
+			"/edit_sub — Редактировать подписку\n" +
+			"/cancel_sub — Отменить подписку\n" +
+			"/find — Поиск клиента\n" +
+			"/check — Статус клиентов по списку номеров\n" +
+			"/clients — Список клиентов с фильтрами\n" +
+			"/referral — Реферальная ссылка клиента\n" +
+			"/commission — Комиссия к выплате\n" +
+			"/bulk_renew — Продлить несколько подписок одним платежом\n" +
+			"/pending_orders — Неоплаченные заказы с кнопками оплаты\n" +
+			"/my_subs — Список подписок\n" +
+			"/vacation — Отпуск и замена\n" +
+			"/language — Язык бота",
+		KeyAdminCommandsTitle: "Команды администратора:",
+		KeyAdminCommands: "/tariffs — Управление тарифами\n" +
+			"/servers — Управление серверами\n" +
+			"/stats — Статистика (/stats @ассистент — по ассистенту)\n" +
+			"/revenue — Выручка по тарифам и ассистентам (/revenue 01.09.2026 15.09.2026 — за период)\n" +
+			"/payments_pending — Платежи на подтверждении по чекам (ручной режим оплаты)\n" +
+			"/export_subs — Выгрузка подписок в CSV (фильтры: status, server, assistant, from, to)\n" +
+			"/export_payments — Выгрузка платежей в CSV для сверки (фильтры: status, from, to)\n" +
+			"/top_referrers — Топ рефералов за неделю\n" +
+			"/cohorts — Когорты клиентов\n" +
+			"/waplan — План рассылки WhatsApp\n" +
+			"/quick_sub — Быстрое создание подписки одной командой\n" +
+			"/sub_price — Индивидуальная цена продления\n" +
+			"/tariff_price — Плановое изменение цены тарифа\n" +
+			"/server_price — Наценка сервера\n" +
+			"/transfer_subs — Передать подписки другому ассистенту\n" +
+			"/ban — Заблокировать пользователя\n" +
+			"/bans — Список блокировок\n" +
+			"/merge_phones — Объединить клиентов, записанных под разными форматами номера\n" +
+			"/templates — Тексты сообщений клиентам в WhatsApp\n" +
+			"/db_stats — Размер базы, число строк в таблицах, VACUUM и ANALYZE\n" +
+			"/whitelist — Белый список мягкого запуска\n" +
+			"/set_role — Роли: админ, ассистент, наблюдатель\n" +
+			"/add_admin, /remove_admin — Назначить или снять админа (супер-админ)\n" +
+			"/broadcast — Рассылка всем, кто создавал подписки\n" +
+			"/overdue — Просроченные подписки\n" +
+			"/expiring — Истекающие подписки\n" +
+			"/exp3 — Истекающие через 3 дня",
+		KeyViewerHelp: "Доступные команды (только просмотр):\n\n" +
+			"/my_subs — Список подписок\n" +
+			"/stats — Общая статистика\n" +
+			"/language — Язык бота",
+		KeyViewerOnly:      "❌ Наблюдателю доступен только просмотр",
+		KeyLanguageChoose:  "🌐 Выберите язык бота:",
+		KeyLanguageChanged: "✅ Язык бота: %s",
+	},
+	LangEN: {
+		KeyError:                  "❌ Error. Please try again later.",
+		KeyWelcome:                "Welcome!\n\nThis bot helps assistants manage client subscriptions.",
+		KeyButtonMySubs:           "My subscriptions",
+		KeyHelpTitle:              "Available commands:\n\n/start — Main menu",
+		KeyAssistantCommandsTitle: "Assistant commands:",
+		KeyAssistantCommands: "/create_sub — Create a subscription for a client\n" +
+			"/edit_sub — Edit a subscription\n" +
+			"/cancel_sub — Cancel a subscription\n" +
+			"/find — Find a client\n" +
+			"/check — Status of clients from a list of numbers\n" +
+			"/clients — Client list with filters\n" +
+			"/referral — Client referral link\n" +
+			"/commission — Commission to be paid\n" +
+			"/bulk_renew — Renew several subscriptions with one payment\n" +
+			"/pending_orders — Unpaid orders with payment buttons\n" +
+			"/my_subs — Subscription list\n" +
+			"/vacation — Vacation and cover\n" +
+			"/language — Bot language",
+		KeyAdminCommandsTitle: "Admin commands:",
+		KeyAdminCommands: "/tariffs — Manage tariffs\n" +
+			"/servers — Manage servers\n" +
+			"/stats — Statistics (/stats @assistant — per assistant)\n" +
+			"/revenue — Revenue by tariff and assistant (/revenue 01.09.2026 15.09.2026 — for a period)\n" +
+			"/payments_pending — Payments awaiting receipt approval (manual payment mode)\n" +
+			"/export_subs — Export subscriptions to CSV (filters: status, server, assistant, from, to)\n" +
+			"/export_payments — Export payments to CSV for reconciliation (filters: status, from, to)\n" +
+			"/top_referrers — Top referrers of the week\n" +
+			"/cohorts — Client cohorts\n" +
+			"/waplan — WhatsApp outreach plan\n" +
+			"/quick_sub — Create a subscription with one command\n" +
+			"/sub_price — Custom renewal price\n" +
+			"/tariff_price — Scheduled tariff price change\n" +
+			"/server_price — Server markup\n" +
+			"/transfer_subs — Transfer subscriptions to another assistant\n" +
+			"/ban — Ban a user\n" +
+			"/bans — Ban list\n" +
+			"/merge_phones — Merge clients stored under different phone formats\n" +
+			"/templates — WhatsApp message texts for clients\n" +
+			"/db_stats — Database size, table row counts, VACUUM and ANALYZE\n" +
+			"/whitelist — Soft launch whitelist\n" +
+			"/set_role — Roles: admin, assistant, viewer\n" +
+			"/add_admin, /remove_admin — Grant or revoke admin (super-admin)\n" +
+			"/broadcast — Message everyone who created subscriptions\n" +
+			"/overdue — Overdue subscriptions\n" +
+			"/expiring — Expiring subscriptions\n" +
+			"/exp3 — Expiring in 3 days",
+		KeyViewerHelp: "Available commands (read-only):\n\n" +
+			"/my_subs — Subscription list\n" +
+			"/stats — Overall statistics\n" +
+			"/language — Bot language",
+		KeyViewerOnly:      "❌ Viewers have read-only access",
+		KeyLanguageChoose:  "🌐 Choose the bot language:",
+		KeyLanguageChanged: "✅ Bot language: %s",
+	},
+	LangKG: {
+		KeyError:                  "❌ Ката. Кийинчерээк кайра аракет кылыңыз.",
+		KeyWelcome:                "Кош келиңиз!\n\nБул бот ассистенттерге кардарлардын жазылууларын башкарууга жардам берет.",
+		KeyButtonMySubs:           "Менин жазылууларым",
+		KeyHelpTitle:              "Жеткиликтүү командалар:\n\n/start — Башкы меню",
+		KeyAssistantCommandsTitle: "Ассистенттин командалары:",
+		KeyAssistantCommands: "/create_sub — Кардарга жазылуу түзүү\n" +
+			"/edit_sub — Жазылууну өзгөртүү\n" +
+			"/cancel_sub — Жазылууну жокко чыгаруу\n" +
+			"/find — Кардарды издөө\n" +
+			"/check — Номерлердин тизмеси боюнча кардарлардын абалы\n" +
+			"/clients — Кардарлардын тизмеси чыпкалар менен\n" +
+			"/referral — Кардардын реферал шилтемеси\n" +
+			"/commission — Төлөнө турган комиссия\n" +
+			"/bulk_renew — Бир нече жазылууну бир төлөм менен узартуу\n" +
+			"/pending_orders — Төлөнбөгөн заказдар, төлөм баскычтары менен\n" +
+			"/my_subs — Жазылуулардын тизмеси\n" +
+			"/vacation — Өргүү жана алмаштыруу\n" +
+			"/language — Боттун тили",
+		KeyAdminCommandsTitle: "Администратордун командалары:",
+		KeyAdminCommands: "/tariffs — Тарифтерди башкаруу\n" +
+			"/servers — Серверлерди башкаруу\n" +
+			"/stats — Статистика (/stats @ассистент — ассистент боюнча)\n" +
+			"/revenue — Тарифтер жана ассистенттер боюнча киреше (/revenue 01.09.2026 15.09.2026 — мезгил үчүн)\n" +
+			"/payments_pending — Чек боюнча ырастоону күткөн төлөмдөр (кол менен төлөө режими)\n" +
+			"/export_subs — Жазылууларды CSV файлга чыгаруу (чыпкалар: status, server, assistant, from, to)\n" +
+			"/export_payments — Төлөмдөрдү салыштыруу үчүн CSV файлга чыгаруу (чыпкалар: status, from, to)\n" +
+			"/top_referrers — Жуманын мыкты рефералдары\n" +
+			"/cohorts — Кардарлардын когорталары\n" +
+			"/waplan — WhatsApp жөнөтүү планы\n" +
+			"/quick_sub — Бир команда менен жазылуу түзүү\n" +
+			"/sub_price — Узартуунун жеке баасы\n" +
+			"/tariff_price — Тарифтин баасын пландуу өзгөртүү\n" +
+			"/server_price — Сервердин үстөк баасы\n" +
+			"/transfer_subs — Жазылууларды башка ассистентке өткөрүү\n" +
+			"/ban — Колдонуучуну бөгөттөө\n" +
+			"/bans — Бөгөттөлгөндөрдүн тизмеси\n" +
+			"/merge_phones — Ар кандай номер форматында жазылган кардарларды бириктирүү\n" +
+			"/templates — Кардарларга WhatsApp билдирүүлөрүнүн тексттери\n" +
+			"/db_stats — Базанын көлөмү, таблицалардагы саптар, VACUUM жана ANALYZE\n" +
+			"/whitelist — Жумшак ишке киргизүүнүн ак тизмеси\n" +
+			"/set_role — Ролдор: админ, ассистент, байкоочу\n" +
+			"/add_admin, /remove_admin — Админ дайындоо же алып салуу (супер-админ)\n" +
+			"/broadcast — Жазылуу түзгөндөрдүн баарына билдирүү\n" +
+			"/overdue — Мөөнөтү өткөн жазылуулар\n" +
+			"/expiring — Мөөнөтү бүтүп жаткан жазылуулар\n" +
+			"/exp3 — 3 күндөн кийин бүтө турган жазылуулар",
+		KeyViewerHelp: "Жеткиликтүү командалар (көрүү гана):\n\n" +
+			"/my_subs — Жазылуулардын тизмеси\n" +
+			"/stats — Жалпы статистика\n" +
+			"/language — Боттун тили",
+		KeyViewerOnly:      "❌ Байкоочу көрө гана алат",
+		KeyLanguageChoose:  "🌐 Боттун тилин тандаңыз:",
+		KeyLanguageChanged: "✅ Боттун тили: %s",
+	},
+}
+
+// Localizer возвращает тексты интерфейса на языке пользователя
+type Localizer struct {
+	lang Lang
+}
+
+// For создает Localizer по users.language; неизвестный или пустой язык - русский
+func For(language string) Localizer {
+	lang, _ := ParseLang(language)
+	return Localizer{lang: lang}
+}
+
+// Lang возвращает язык локализатора
+func (l Localizer) Lang() Lang {
+	return l.lang
+}
+
+// T возвращает текст по ключу (с подстановкой args). Если перевода нет - используется русский текст
+func (l Localizer) T(key Key, args ...any) string {
+	text, ok := translations[l.lang][key]
+	if !ok {
+		text = translations[LangRU][key]
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
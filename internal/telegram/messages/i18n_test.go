@@ -0,0 +1,25 @@
+package messages
+
+import "testing"
+
+func TestTranslationsComplete(t *testing.T) {
+	for _, lang := range Langs {
+		for key := range translations[LangRU] {
+			if _, ok := translations[lang][key]; !ok {
+				t.Errorf("%s: missing translation for %q", lang, key)
+			}
+		}
+	}
+}
+
+func TestLocalizerFallback(t *testing.T) {
+	if got := For("").T(KeyButtonMySubs); got != "Мои подписки" {
+		t.Errorf("For(\"\") = %q, want russian", got)
+	}
+	if got := For("de").Lang(); got != LangRU {
+		t.Errorf("For(\"de\").Lang() = %q, want ru", got)
+	}
+	if got := For("en").T(KeyLanguageChanged, LangEN.Title()); got != "✅ Bot language: 🇬🇧 English" {
+		t.Errorf("T(KeyLanguageChanged) = %q", got)
+	}
+}
@@ -0,0 +1,42 @@
+package messages
+
+import "kurut-bot/internal/stories/devices"
+
+// setupInstructions - инструкции по подключению WireGuard со ссылками на приложение для каждой платформы
+var setupInstructions = map[devices.Platform]string{
+	devices.IOS: `📋 Инструкция по подключению (iPhone/iPad):
+
+1. Установите WireGuard из App Store:
+https://apps.apple.com/app/wireguard/id1441195209
+2. Откройте WireGuard и нажмите "Добавить туннель"
+3. Выберите "Создать из QR-кода" и отсканируйте код, который я отправлю, или "Создать из файла" для файла конфигурации
+4. Разрешите добавление конфигурации VPN и включите туннель`,
+	devices.Android: `📋 Инструкция по подключению (Android):
+
+1. Установите WireGuard из Google Play:
+https://play.google.com/store/apps/details?id=com.wireguard.android
+2. Откройте WireGuard и нажмите +
+3. Выберите "Сканировать QR-код" и отсканируйте код, который я отправлю, или "Импорт из файла"
+4. Разрешите подключение VPN и включите туннель`,
+	devices.Windows: `📋 Инструкция по подключению (Windows):
+
+1. Скачайте и установите WireGuard:
+https://www.wireguard.com/install/
+2. Сохраните файл конфигурации, который я отправлю
+3. В WireGuard нажмите "Импорт туннелей из файла" и выберите этот файл
+4. Нажмите "Подключить"`,
+	devices.Router: `📋 Инструкция по подключению (роутер):
+
+1. Откройте веб-интерфейс роутера (Keenetic, MikroTik, OpenWrt и др.) и найдите раздел WireGuard
+2. Импортируйте файл конфигурации, который я отправлю, или перенесите из него ключи и адрес сервера
+3. Включите подключение и направьте через него трафик нужных устройств
+Если в прошивке роутера нет WireGuard - напишите, подберем другой вариант`,
+}
+
+// SetupInstructions возвращает инструкцию по подключению для платформы клиента; без платформы - общую
+func SetupInstructions(platform devices.Platform) string {
+	if text, ok := setupInstructions[platform]; ok {
+		return text
+	}
+	return SubscriptionInstructions
+}
@@ -1,5 +1,13 @@
 package messages
 
+import (
+	"fmt"
+
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/drip"
+	"kurut-bot/internal/stories/watemplates"
+)
+
 // WhatsApp сообщения для клиентов о продлении подписки
 
 const WhatsAppMsgToday = `Саламатсызбы! впн бугун акыркы кун экен, саат 23:00 очот, дагы канча айга улап коелу`
@@ -8,4 +16,144 @@ const WhatsAppMsg1Day = `Саламатсызбы! впн эртең акырк
 
 const WhatsAppMsg3Days = `Саламатсызбы! впн 3 кундон кийин бүтөт, дагы канча айга улап коелу`
 
+// WhatsAppMsgNDays - шаблон для произвольного количества дней (fmt.Sprintf с числом дней)
+const WhatsAppMsgNDays = `Саламатсызбы! впн %d кундон кийин бүтөт, дагы канча айга улап коелу`
+
 const WhatsAppMsgExpired = `Ассалому алейкум 🤝 улап коелу бу же очуп калат`
+
+// WhatsAppTemplate - вид предзаполненного сообщения клиенту
+type WhatsAppTemplate string
+
+const (
+	WATemplateToday     WhatsAppTemplate = "today"
+	WATemplate1Day      WhatsAppTemplate = "1day"
+	WATemplate3Days     WhatsAppTemplate = "3days"
+	WATemplateNDays     WhatsAppTemplate = "ndays"     // аргумент: количество дней
+	WATemplateExpired   WhatsAppTemplate = "expired"   // ссылка на оплату просроченной подписки
+	WATemplateDisabled  WhatsAppTemplate = "disabled"  // подписка отключена
+	WATemplateRenewed   WhatsAppTemplate = "renewed"   // подписка продлена
+	WATemplateActivated WhatsAppTemplate = "activated" // подписка создана, дальше инструкции
+	WATemplateReferral  WhatsAppTemplate = "referral"  // аргументы: приглашений за неделю, бонусные дни, новая дата окончания
+
+	WATemplateTrialCheckIn WhatsAppTemplate = "trial_checkin" // 2-й день пробного периода
+	WATemplateTrialUpgrade WhatsAppTemplate = "trial_upgrade" // аргумент: скидка в процентах
+	WATemplateTrialWinBack WhatsAppTemplate = "trial_winback" // пробный период закончился без оплаты
+
+	WATemplatePriceLock WhatsAppTemplate = "price_lock" // аргументы: дата повышения, новая цена, старая цена
+)
+
+// whatsAppTemplates - шаблоны по языкам; clientlang.Default - исторические тексты
+var whatsAppTemplates = map[clientlang.Language]map[WhatsAppTemplate]string{
+	clientlang.Default: {
+		WATemplateToday:     WhatsAppMsgToday,
+		WATemplate1Day:      WhatsAppMsg1Day,
+		WATemplate3Days:     WhatsAppMsg3Days,
+		WATemplateNDays:     WhatsAppMsgNDays,
+		WATemplateExpired:   WhatsAppMsgExpired,
+		WATemplateDisabled:  "Здравствуйте! Ваша подписка VPN истекла. Для продолжения работы необходимо оплатить подписку.",
+		WATemplateRenewed:   "Ваша подписка VPN продлена!",
+		WATemplateActivated: "Ваша подписка VPN активирована! Сейчас отправлю инструкции по подключению.",
+		WATemplateReferral:  "🎉 Сизден жаңы кардар келди!\n\nБул жумада: %d чакыруу\nСиздин жазылууңузга +%dкүн кошулду\nэми %s чейин болду",
+
+		WATemplateTrialCheckIn: "Здравствуйте! Как вам VPN, всё работает? Если есть вопросы - пишите 🙂",
+		WATemplateTrialUpgrade: "Здравствуйте! Завтра заканчивается пробный период VPN. Если оплатите сейчас - скидка %d%%. Подключаем?",
+		WATemplateTrialWinBack: "Здравствуйте! Пробный период VPN закончился. Хотите продолжить? Подключим за пару минут 🤝",
+
+		WATemplatePriceLock: "Здравствуйте! С %s цена VPN вырастет до %.0f ₽. Если продлите до этой даты - останется %.0f ₽. Продлеваем?",
+	},
+	clientlang.Kyrgyz: {
+		WATemplateToday:     WhatsAppMsgToday,
+		WATemplate1Day:      WhatsAppMsg1Day,
+		WATemplate3Days:     WhatsAppMsg3Days,
+		WATemplateNDays:     WhatsAppMsgNDays,
+		WATemplateExpired:   "Саламатсызбы! 🤝 впн мөөнөтү бүттү, улап коелу",
+		WATemplateDisabled:  "Саламатсызбы! впн мөөнөтү бүтүп, өчүрүлдү. Улантуу үчүн төлөм кылыңыз.",
+		WATemplateRenewed:   "впн жазылууңуз узартылды!",
+		WATemplateActivated: "впн жазылууңуз иштетилди! Азыр туташуу боюнча нускама жиберем.",
+		WATemplateReferral:  "🎉 Сизден жаңы кардар келди!\n\nБул жумада: %d чакыруу\nСиздин жазылууңузга +%dкүн кошулду\nэми %s чейин болду",
+
+		WATemplateTrialCheckIn: "Саламатсызбы! впн кандай иштеп жатат? Суроолор болсо жазыңыз 🙂",
+		WATemplateTrialUpgrade: "Саламатсызбы! сыноо мөөнөтү эртең бүтөт. Азыр төлөсөңүз %d%% арзандатуу, улап коелубу?",
+		WATemplateTrialWinBack: "Саламатсызбы! впн сыноо мөөнөтү бүттү. Улантабызбы? 🤝",
+
+		WATemplatePriceLock: "Саламатсызбы! %s баштап впн баасы %.0f ₽ болот. Ага чейин узартсаңыз, %.0f ₽ бойдон калат. Узартабызбы?",
+	},
+	clientlang.Russian: {
+		WATemplateToday:     "Здравствуйте! Сегодня последний день VPN, в 23:00 отключится. На сколько месяцев продлить?",
+		WATemplate1Day:      "Здравствуйте! Завтра последний день VPN. На сколько месяцев продлить?",
+		WATemplate3Days:     "Здравствуйте! VPN закончится через 3 дня. На сколько месяцев продлить?",
+		WATemplateNDays:     "Здравствуйте! VPN закончится через %d дн. На сколько месяцев продлить?",
+		WATemplateExpired:   "Здравствуйте! 🤝 Подписка VPN закончилась, давайте продлим",
+		WATemplateDisabled:  "Здравствуйте! Ваша подписка VPN истекла. Для продолжения работы необходимо оплатить подписку.",
+		WATemplateRenewed:   "Ваша подписка VPN продлена!",
+		WATemplateActivated: "Ваша подписка VPN активирована! Сейчас отправлю инструкции по подключению.",
+		WATemplateReferral:  "🎉 По вашей рекомендации пришел новый клиент!\n\nНа этой неделе: %d приглашений\nК вашей подписке добавлено +%d дней\nтеперь она действует до %s",
+
+		WATemplateTrialCheckIn: "Здравствуйте! Как вам VPN, всё работает? Если есть вопросы - пишите 🙂",
+		WATemplateTrialUpgrade: "Здравствуйте! Завтра заканчивается пробный период VPN. Если оплатите сейчас - скидка %d%%. Подключаем?",
+		WATemplateTrialWinBack: "Здравствуйте! Пробный период VPN закончился. Хотите продолжить? Подключим за пару минут 🤝",
+
+		WATemplatePriceLock: "Здравствуйте! С %s цена VPN вырастет до %.0f ₽. Если продлите до этой даты - останется %.0f ₽. Продлеваем?",
+	},
+	clientlang.Uzbek: {
+		WATemplateToday:     "Assalomu alaykum! VPN bugun oxirgi kun, soat 23:00 da oʻchadi. Necha oyga uzaytiramiz?",
+		WATemplate1Day:      "Assalomu alaykum! VPN ertaga oxirgi kun. Necha oyga uzaytiramiz?",
+		WATemplate3Days:     "Assalomu alaykum! VPN 3 kundan keyin tugaydi. Necha oyga uzaytiramiz?",
+		WATemplateNDays:     "Assalomu alaykum! VPN %d kundan keyin tugaydi. Necha oyga uzaytiramiz?",
+		WATemplateExpired:   "Assalomu alaykum 🤝 VPN muddati tugadi, uzaytirib qoʻyamizmi?",
+		WATemplateDisabled:  "Assalomu alaykum! VPN obunangiz muddati tugadi. Davom ettirish uchun toʻlov qiling.",
+		WATemplateRenewed:   "VPN obunangiz uzaytirildi!",
+		WATemplateActivated: "VPN obunangiz faollashtirildi! Hozir ulanish boʻyicha yoʻriqnoma yuboraman.",
+		WATemplateReferral:  "🎉 Sizning tavsiyangiz bilan yangi mijoz keldi!\n\nShu hafta: %d ta taklif\nObunangizga +%d kun qoʻshildi\nendi %s gacha amal qiladi",
+
+		WATemplateTrialCheckIn: "Assalomu alaykum! VPN qanday ishlayapti? Savollar boʻlsa yozing 🙂",
+		WATemplateTrialUpgrade: "Assalomu alaykum! VPN sinov muddati ertaga tugaydi. Hozir toʻlasangiz %d%% chegirma. Ulab qoʻyamizmi?",
+		WATemplateTrialWinBack: "Assalomu alaykum! VPN sinov muddati tugadi. Davom ettiramizmi? 🤝",
+
+		WATemplatePriceLock: "Assalomu alaykum! %s dan VPN narxi %.0f ₽ gacha oshadi. Shu sanagacha uzaytirsangiz, %.0f ₽ boʻlib qoladi. Uzaytiramizmi?",
+	},
+}
+
+// WhatsAppText возвращает текст шаблона на языке клиента (с подстановкой args) и подписью бренда.
+// Если перевода нет - используется исторический текст
+func WhatsAppText(lang clientlang.Language, tpl WhatsAppTemplate, args ...any) string {
+	return WhatsAppTextWithVars(lang, tpl, watemplates.Vars{}, args...)
+}
+
+// WhatsAppTextWithVars - как WhatsAppText, но с данными подписки для подстановок
+// ({client}, {expires_at}, {tariff}) в текстах, измененных через /templates
+func WhatsAppTextWithVars(lang clientlang.Language, tpl WhatsAppTemplate, vars watemplates.Vars, args ...any) string {
+	text := whatsAppTemplateText(lang, tpl)
+	if len(args) > 0 {
+		text = fmt.Sprintf(text, args...)
+	}
+	return watemplates.Render(text, vars) + CurrentBrand().Signature()
+}
+
+// WhatsAppTextForDays возвращает напоминание об истечении через daysUntilExpiry дней (<0 - уже истекла)
+func WhatsAppTextForDays(lang clientlang.Language, daysUntilExpiry int, vars watemplates.Vars) string {
+	switch {
+	case daysUntilExpiry < 0:
+		return WhatsAppTextWithVars(lang, WATemplateExpired, vars)
+	case daysUntilExpiry == 0:
+		return WhatsAppTextWithVars(lang, WATemplateToday, vars)
+	case daysUntilExpiry == 1:
+		return WhatsAppTextWithVars(lang, WATemplate1Day, vars)
+	case daysUntilExpiry == 3:
+		return WhatsAppTextWithVars(lang, WATemplate3Days, vars)
+	default:
+		return WhatsAppTextWithVars(lang, WATemplateNDays, vars, daysUntilExpiry)
+	}
+}
+
+// WhatsAppTextForDripStep возвращает сообщение клиенту на этапе рассылки пробного периода
+func WhatsAppTextForDripStep(lang clientlang.Language, step drip.Step, discountPercent int) string {
+	switch step {
+	case drip.StepUpgrade:
+		return WhatsAppText(lang, WATemplateTrialUpgrade, discountPercent)
+	case drip.StepWinBack:
+		return WhatsAppText(lang, WATemplateTrialWinBack)
+	default:
+		return WhatsAppText(lang, WATemplateTrialCheckIn)
+	}
+}
@@ -0,0 +1,67 @@
+package messages
+
+import (
+	"sync"
+
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/watemplates"
+)
+
+// editableWhatsAppTemplates - встроенные шаблоны, текст которых админ может заменить через /templates
+var editableWhatsAppTemplates = map[watemplates.Name]WhatsAppTemplate{
+	watemplates.NameExpiringToday:    WATemplateToday,
+	watemplates.NameExpiringTomorrow: WATemplate1Day,
+	watemplates.NameOverdue:          WATemplateDisabled,
+	watemplates.NameActivated:        WATemplateActivated,
+}
+
+var (
+	overridesMu sync.RWMutex
+	overrides   = map[clientlang.Language]map[WhatsAppTemplate]string{}
+)
+
+// SetWhatsAppOverrides заменяет тексты, сохраненные админом. Вызывается при запуске и после правки шаблона
+func SetWhatsAppOverrides(templates []*watemplates.Template) {
+	loaded := make(map[clientlang.Language]map[WhatsAppTemplate]string)
+	for _, t := range templates {
+		tpl, ok := editableWhatsAppTemplates[t.Name]
+		if !ok {
+			continue
+		}
+		if loaded[t.Language] == nil {
+			loaded[t.Language] = make(map[WhatsAppTemplate]string)
+		}
+		loaded[t.Language][tpl] = t.Text
+	}
+
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	overrides = loaded
+}
+
+// BuiltinWhatsAppText - встроенный текст шаблона без подписи, для показа админу рядом с измененным
+func BuiltinWhatsAppText(name watemplates.Name, lang clientlang.Language) string {
+	tpl := editableWhatsAppTemplates[name]
+	if text, ok := whatsAppTemplates[lang][tpl]; ok {
+		return text
+	}
+	return whatsAppTemplates[clientlang.Default][tpl]
+}
+
+// whatsAppTemplateText выбирает текст: сначала язык клиента (измененный, затем встроенный),
+// потом основной текст в том же порядке
+func whatsAppTemplateText(lang clientlang.Language, tpl WhatsAppTemplate) string {
+	overridesMu.RLock()
+	defer overridesMu.RUnlock()
+
+	if text, ok := overrides[lang][tpl]; ok {
+		return text
+	}
+	if text, ok := whatsAppTemplates[lang][tpl]; ok {
+		return text
+	}
+	if text, ok := overrides[clientlang.Default][tpl]; ok {
+		return text
+	}
+	return whatsAppTemplates[clientlang.Default][tpl]
+}
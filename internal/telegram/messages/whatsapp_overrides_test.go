@@ -0,0 +1,41 @@
+package messages
+
+import (
+	"testing"
+
+	"kurut-bot/internal/stories/clientlang"
+	"kurut-bot/internal/stories/watemplates"
+)
+
+func TestWhatsAppOverrides(t *testing.T) {
+	t.Cleanup(func() { SetWhatsAppOverrides(nil) })
+
+	SetWhatsAppOverrides([]*watemplates.Template{
+		{Name: watemplates.NameExpiringToday, Language: clientlang.Russian, Text: "{client}, тариф {tariff} до {expires_at}"},
+		{Name: watemplates.NameActivated, Language: clientlang.Default, Text: "Готово!"},
+	})
+	vars := watemplates.Vars{Client: "996700", ExpiresAt: "01.11.2026", Tariff: "1 месяц"}
+
+	if got := WhatsAppTextWithVars(clientlang.Russian, WATemplateToday, vars); got != "996700, тариф 1 месяц до 01.11.2026" {
+		t.Errorf("override for client language = %q", got)
+	}
+	// Для киргизского свой встроенный текст, измененный русский на него не влияет
+	if got := WhatsAppTextWithVars(clientlang.Kyrgyz, WATemplateToday, vars); got != WhatsAppMsgToday {
+		t.Errorf("builtin for other language = %q", got)
+	}
+	// Встроенный перевод важнее измененного основного текста
+	if got := WhatsAppText(clientlang.Russian, WATemplateActivated); got != BuiltinWhatsAppText(watemplates.NameActivated, clientlang.Russian) {
+		t.Errorf("builtin translation = %q", got)
+	}
+	if got := WhatsAppText(clientlang.Default, WATemplateActivated); got != "Готово!" {
+		t.Errorf("override for default language = %q", got)
+	}
+	if got := WhatsAppTextForDays(clientlang.Russian, 0, vars); got != "996700, тариф 1 месяц до 01.11.2026" {
+		t.Errorf("WhatsAppTextForDays() = %q", got)
+	}
+
+	SetWhatsAppOverrides(nil)
+	if got := WhatsAppText(clientlang.Default, WATemplateActivated); got != BuiltinWhatsAppText(watemplates.NameActivated, clientlang.Default) {
+		t.Errorf("after reset = %q", got)
+	}
+}
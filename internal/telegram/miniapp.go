@@ -0,0 +1,242 @@
+package telegram
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/subs"
+)
+
+const (
+	// initDataMaxAge - сколько живет initData Mini App
+	initDataMaxAge = 24 * time.Hour
+
+	defaultSubscriptionsPageSize = 20
+	maxSubscriptionsPageSize     = 100
+)
+
+// miniAppStatuses - статусы, по которым можно фильтровать подписки в API
+var miniAppStatuses = []subs.Status{
+	subs.StatusPending,
+	subs.StatusActive,
+	subs.StatusExpired,
+	subs.StatusDisabled,
+	subs.StatusArchived,
+	subs.StatusCancelled,
+	subs.StatusPaused,
+}
+
+var (
+	errInitDataHash    = errors.New("invalid init data hash")
+	errInitDataExpired = errors.New("init data expired")
+)
+
+// MiniAppStorage provides data for Mini App API
+type MiniAppStorage interface {
+	ListSubscriptionsPage(ctx context.Context, criteria subs.PageCriteria) ([]storage.SubscriptionDetails, error)
+}
+
+// ValidateInitData проверяет подпись initData Mini App и возвращает Telegram ID пользователя.
+// См. https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app
+func ValidateInitData(initData string, botToken string, now time.Time) (int64, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return 0, fmt.Errorf("parse init data: %w", err)
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return 0, errInitDataHash
+	}
+
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		if key == "hash" {
+			continue
+		}
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+	dataCheckString := strings.Join(pairs, "\n")
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(dataCheckString))
+	if !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(hash)) {
+		return 0, errInitDataHash
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse auth_date: %w", err)
+	}
+	if now.Sub(time.Unix(authDate, 0)) > initDataMaxAge {
+		return 0, errInitDataExpired
+	}
+
+	var user struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil {
+		return 0, fmt.Errorf("parse user: %w", err)
+	}
+	if user.ID == 0 {
+		return 0, errors.New("user id is missing")
+	}
+
+	return user.ID, nil
+}
+
+type miniAppTariff struct {
+	ID           int64   `json:"id"`
+	Name         string  `json:"name"`
+	DurationDays int     `json:"duration_days"`
+	Price        float64 `json:"price"`
+}
+
+type miniAppServer struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type miniAppSubscription struct {
+	ID             int64          `json:"id"`
+	Status         string         `json:"status"`
+	ClientWhatsApp *string        `json:"client_whatsapp,omitempty"`
+	ActivatedAt    *time.Time     `json:"activated_at,omitempty"`
+	ExpiresAt      *time.Time     `json:"expires_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	Tariff         miniAppTariff  `json:"tariff"`
+	Server         *miniAppServer `json:"server"`
+}
+
+type miniAppSubscriptionsResponse struct {
+	Items      []miniAppSubscription `json:"items"`
+	NextCursor *string               `json:"next_cursor"`
+}
+
+// MiniAppSubscriptionsHandler обрабатывает GET /api/v1/users/{telegramID}/subscriptions.
+// Авторизация: заголовок "Authorization: tma <initData>".
+// Параметры: status (через запятую), cursor, limit
+func MiniAppSubscriptionsHandler(store MiniAppStorage, botToken string, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		telegramID, err := strconv.ParseInt(r.PathValue("telegramID"), 10, 64)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid telegram id")
+			return
+		}
+
+		initData, ok := strings.CutPrefix(r.Header.Get("Authorization"), "tma ")
+		if !ok {
+			writeJSONError(w, http.StatusUnauthorized, "missing init data")
+			return
+		}
+		userID, err := ValidateInitData(initData, botToken, time.Now())
+		if err != nil {
+			writeJSONError(w, http.StatusUnauthorized, "invalid init data")
+			return
+		}
+		if userID != telegramID {
+			writeJSONError(w, http.StatusForbidden, "forbidden")
+			return
+		}
+
+		criteria := subs.PageCriteria{
+			CreatedByTelegramID: telegramID,
+			Limit:               defaultSubscriptionsPageSize,
+		}
+
+		query := r.URL.Query()
+		if limit := query.Get("limit"); limit != "" {
+			criteria.Limit, err = strconv.Atoi(limit)
+			if err != nil || criteria.Limit <= 0 || criteria.Limit > maxSubscriptionsPageSize {
+				writeJSONError(w, http.StatusBadRequest, "invalid limit")
+				return
+			}
+		}
+		if cursor := query.Get("cursor"); cursor != "" {
+			beforeID, err := strconv.ParseInt(cursor, 10, 64)
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, "invalid cursor")
+				return
+			}
+			criteria.BeforeID = &beforeID
+		}
+		if status := query.Get("status"); status != "" {
+			for _, st := range strings.Split(status, ",") {
+				if !slices.Contains(miniAppStatuses, subs.Status(st)) {
+					writeJSONError(w, http.StatusBadRequest, "invalid status")
+					return
+				}
+				criteria.Status = append(criteria.Status, subs.Status(st))
+			}
+		}
+
+		// Запрашиваем на одну запись больше, чтобы понять есть ли следующая страница
+		pageSize := criteria.Limit
+		criteria.Limit++
+
+		items, err := store.ListSubscriptionsPage(r.Context(), criteria)
+		if err != nil {
+			logger.Error("Failed to list subscriptions page", "error", err, "telegram_id", telegramID)
+			writeJSONError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		resp := miniAppSubscriptionsResponse{Items: make([]miniAppSubscription, 0, pageSize)}
+		if len(items) > pageSize {
+			items = items[:pageSize]
+			cursor := strconv.FormatInt(items[len(items)-1].Subscription.ID, 10)
+			resp.NextCursor = &cursor
+		}
+
+		for _, item := range items {
+			sub := item.Subscription
+			dto := miniAppSubscription{
+				ID:             sub.ID,
+				Status:         string(sub.Status),
+				ClientWhatsApp: sub.ClientWhatsApp,
+				ActivatedAt:    sub.ActivatedAt,
+				ExpiresAt:      sub.ExpiresAt,
+				CreatedAt:      sub.CreatedAt,
+				Tariff: miniAppTariff{
+					ID:           sub.TariffID,
+					Name:         item.TariffName,
+					DurationDays: item.TariffDurationDays,
+					Price:        item.TariffPrice,
+				},
+			}
+			if sub.ServerID != nil && item.ServerName != nil {
+				dto.Server = &miniAppServer{ID: *sub.ServerID, Name: *item.ServerName}
+			}
+			resp.Items = append(resp.Items, dto)
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
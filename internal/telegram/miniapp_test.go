@@ -0,0 +1,72 @@
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signInitData(t *testing.T, botToken string, values url.Values) string {
+	t.Helper()
+
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+
+	values.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+	return values.Encode()
+}
+
+func TestValidateInitData(t *testing.T) {
+	const botToken = "123:token"
+	now := time.Unix(1_700_000_000, 0)
+
+	valid := url.Values{}
+	valid.Set("auth_date", strconv.FormatInt(now.Add(-time.Hour).Unix(), 10))
+	valid.Set("user", `{"id":42,"first_name":"Test"}`)
+	validData := signInitData(t, botToken, valid)
+
+	expired := url.Values{}
+	expired.Set("auth_date", strconv.FormatInt(now.Add(-48*time.Hour).Unix(), 10))
+	expired.Set("user", `{"id":42}`)
+	expiredData := signInitData(t, botToken, expired)
+
+	tests := []struct {
+		name     string
+		initData string
+		token    string
+		wantID   int64
+		wantErr  bool
+	}{
+		{"valid", validData, botToken, 42, false},
+		{"wrong token", validData, "other", 0, true},
+		{"tampered", strings.Replace(validData, "42", "43", 1), botToken, 0, true},
+		{"expired", expiredData, botToken, 0, true},
+		{"no hash", "auth_date=1&user=%7B%7D", botToken, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := ValidateInitData(tt.initData, tt.token, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateInitData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if id != tt.wantID {
+				t.Errorf("ValidateInitData() = %d, want %d", id, tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package telegram
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec - контракт HTTP API. Соответствие обработчикам проверяется в openapi_test.go
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPISpecHandler отдает OpenAPI-спецификацию API (GET /api/openapi.json)
+func OpenAPISpecHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		_, _ = w.Write(openAPISpec)
+	}
+}
@@ -0,0 +1,140 @@
+package telegram
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type specSchema struct {
+	Required   []string                   `json:"required"`
+	Properties map[string]json.RawMessage `json:"properties"`
+	Enum       []string                   `json:"enum"`
+}
+
+type specParameter struct {
+	Name   string `json:"name"`
+	Schema struct {
+		Maximum *int `json:"maximum"`
+		Default *int `json:"default"`
+	} `json:"schema"`
+}
+
+type spec struct {
+	OpenAPI string `json:"openapi"`
+	Paths   map[string]map[string]struct {
+		Parameters []specParameter `json:"parameters"`
+	} `json:"paths"`
+	Components struct {
+		Schemas map[string]specSchema `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadSpec(t *testing.T) spec {
+	t.Helper()
+
+	var s spec
+	if err := json.Unmarshal(openAPISpec, &s); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	if !strings.HasPrefix(s.OpenAPI, "3.") {
+		t.Fatalf("openapi = %q, want 3.x", s.OpenAPI)
+	}
+	return s
+}
+
+// jsonFields возвращает имена JSON-полей структуры и те из них, что есть в ответе всегда (без omitempty)
+func jsonFields(v any) (all, required []string) {
+	typ := reflect.TypeOf(v)
+	for i := range typ.NumField() {
+		name, opts, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		all = append(all, name)
+		if opts != "omitempty" {
+			required = append(required, name)
+		}
+	}
+	slices.Sort(all)
+	slices.Sort(required)
+	return all, required
+}
+
+func TestOpenAPISchemasMatchResponses(t *testing.T) {
+	s := loadSpec(t)
+
+	for name, dto := range map[string]any{
+		"Subscription":      miniAppSubscription{},
+		"Tariff":            miniAppTariff{},
+		"Server":            miniAppServer{},
+		"SubscriptionsPage": miniAppSubscriptionsResponse{},
+		"WebSession":        webSessionResponse{},
+		"AgentPeer":         agentPeer{},
+		"AgentPeers":        agentPeersResponse{},
+	} {
+		schema, ok := s.Components.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s is missing", name)
+			continue
+		}
+
+		wantAll, wantRequired := jsonFields(dto)
+		var gotAll []string
+		for prop := range schema.Properties {
+			gotAll = append(gotAll, prop)
+		}
+		slices.Sort(gotAll)
+		gotRequired := slices.Sorted(slices.Values(schema.Required))
+
+		if !slices.Equal(gotAll, wantAll) {
+			t.Errorf("schema %s properties = %v, response fields = %v", name, gotAll, wantAll)
+		}
+		if !slices.Equal(gotRequired, wantRequired) {
+			t.Errorf("schema %s required = %v, want %v", name, gotRequired, wantRequired)
+		}
+	}
+}
+
+func TestOpenAPIParametersMatchHandler(t *testing.T) {
+	s := loadSpec(t)
+
+	var statuses []string
+	for _, st := range miniAppStatuses {
+		statuses = append(statuses, string(st))
+	}
+	if got := s.Components.Schemas["SubscriptionStatus"].Enum; !slices.Equal(got, statuses) {
+		t.Errorf("SubscriptionStatus enum = %v, handler accepts %v", got, statuses)
+	}
+
+	op, ok := s.Paths["/api/v1/users/{telegramID}/subscriptions"]["get"]
+	if !ok {
+		t.Fatal("GET /api/v1/users/{telegramID}/subscriptions is missing")
+	}
+	for _, p := range op.Parameters {
+		if p.Name != "limit" {
+			continue
+		}
+		if p.Schema.Maximum == nil || *p.Schema.Maximum != maxSubscriptionsPageSize {
+			t.Errorf("limit maximum = %v, want %d", p.Schema.Maximum, maxSubscriptionsPageSize)
+		}
+		if p.Schema.Default == nil || *p.Schema.Default != defaultSubscriptionsPageSize {
+			t.Errorf("limit default = %v, want %d", p.Schema.Default, defaultSubscriptionsPageSize)
+		}
+		return
+	}
+	t.Error("limit parameter is missing")
+}
+
+func TestOpenAPISpecHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	OpenAPISpecHandler()(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if rec.Body.String() != string(openAPISpec) {
+		t.Error("handler body differs from embedded spec")
+	}
+}
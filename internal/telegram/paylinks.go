@@ -0,0 +1,35 @@
+package telegram
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"kurut-bot/internal/stories/shortlinks"
+)
+
+// PaymentLinkOpener находит короткую ссылку и учитывает переход
+type PaymentLinkOpener interface {
+	Open(ctx context.Context, token string, userAgent string) (*shortlinks.Link, error)
+}
+
+// PaymentLinkRedirectHandler обрабатывает GET /p/{token}: 302 на страницу оплаты YooKassa
+func PaymentLinkRedirectHandler(opener PaymentLinkOpener, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.PathValue("token")
+
+		link, err := opener.Open(r.Context(), token, r.UserAgent())
+		if err != nil {
+			logger.Error("Failed to open payment link", "error", err, "token", token)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		if link == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		logger.Info("Payment link opened", "payment_id", link.PaymentID, "clicks", link.Clicks+1)
+		http.Redirect(w, r, link.TargetURL, http.StatusFound)
+	}
+}
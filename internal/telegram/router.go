@@ -2,15 +2,28 @@ package telegram
 
 import (
 	"context"
+	"strconv"
 	"strings"
+	"time"
 
+	tgclient "kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/bans"
 	"kurut-bot/internal/stories/users"
 	"kurut-bot/internal/telegram/cmds"
 	"kurut-bot/internal/telegram/flows"
 	"kurut-bot/internal/telegram/flows/addserver"
+	"kurut-bot/internal/telegram/flows/bulkrenewsub"
+	"kurut-bot/internal/telegram/flows/cancelsub"
 	"kurut-bot/internal/telegram/flows/createsubforclient"
 	"kurut-bot/internal/telegram/flows/createtariff"
+	"kurut-bot/internal/telegram/flows/editserver"
+	"kurut-bot/internal/telegram/flows/editsub"
+	"kurut-bot/internal/telegram/flows/edittariff"
 	"kurut-bot/internal/telegram/flows/migrateclient"
+	"kurut-bot/internal/telegram/flows/sendbroadcast"
+	"kurut-bot/internal/telegram/flows/subnote"
+	"kurut-bot/internal/telegram/flows/transfersubs"
+	"kurut-bot/internal/telegram/flows/watemplate"
 	"kurut-bot/internal/telegram/messages"
 	"kurut-bot/internal/telegram/states"
 
@@ -28,17 +41,62 @@ type Router struct {
 	createTariffHandler       *createtariff.Handler
 	addServerHandler          *addserver.Handler
 	migrateClientHandler      *migrateclient.Handler
+	editSubHandler            *editsub.Handler
+	cancelSubHandler          *cancelsub.Handler
+	bulkRenewSubHandler       *bulkrenewsub.Handler
+	transferSubsHandler       *transfersubs.Handler
+	editTariffHandler         *edittariff.Handler
+	editServerHandler         *editserver.Handler
 	mySubsCommand             *cmds.MySubsCommand
 	statsCommand              *cmds.StatsCommand
 	expirationCommand         *cmds.ExpirationCommand
 	tariffsCommand            *cmds.TariffsCommand
 	serversCommand            *cmds.ServersCommand
 	topReferrersCommand       *cmds.TopReferrersCommand
+	stuckPaymentsCommand      *cmds.StuckPaymentsCommand
+	cohortsCommand            *cmds.CohortsCommand
+	waPlanCommand             *cmds.WAPlanCommand
+	calendarCommand           *cmds.CalendarCommand
+	trafficTopUpCommand       *cmds.TrafficTopUpCommand
+	unpaidSubsCommand         *cmds.UnpaidSubsCommand
+	vacationCommand           *cmds.VacationCommand
+	clientLanguageCommand     *cmds.ClientLanguageCommand
+	clientPlatformCommand     *cmds.ClientPlatformCommand
+	subPriceCommand           *cmds.SubPriceCommand
+	tariffPriceCommand        *cmds.TariffPriceCommand
+	serverPriceCommand        *cmds.ServerPriceCommand
+	findCommand               *cmds.FindCommand
+	latePaymentsCommand       *cmds.LatePaymentsCommand
+	subViewCommand            *cmds.SubViewCommand
+	bansCommand               *cmds.BansCommand
+	mergePhonesCommand        *cmds.MergePhonesCommand
+	dbStatsCommand            *cmds.DBStatsCommand
+	floodGuard                *bans.FloodGuard
+	clientsCommand            *cmds.ClientsCommand
+	whitelistCommand          *cmds.WhitelistCommand
+	broadcastHandler          *sendbroadcast.Handler
+	referralCommand           *cmds.ReferralCommand
+	revenueCommand            *cmds.RevenueCommand
+	exportSubsCommand         *cmds.ExportSubsCommand
+	exportPaymentsCommand     *cmds.ExportPaymentsCommand
+	commissionCommand         *cmds.CommissionCommand
+	trialDripCommand          *cmds.TrialDripCommand
+	escalationCommand         *cmds.EscalationCommand
+	cancelReasonCommand       *cmds.CancelReasonCommand
+	subNoteHandler            *subnote.Handler
+	waTemplateHandler         *watemplate.Handler
+	rolesCommand              *cmds.RolesCommand
+	languageCommand           *cmds.LanguageCommand
+	inlineClientsCommand      *cmds.InlineClientsCommand
+	checkCommand              *cmds.CheckCommand
+	tariffOffersCommand       *cmds.TariffOffersCommand
+	inflight                  *commandTracker
 }
 
 type stateManager interface {
 	GetState(tgUserID int64) states.State
 	SetState(chatID int64, state states.State, data any)
+	Touch(chatID int64)
 	Clear(tgUserID int64)
 	GetWelcomeData(chatID int64) (*flows.WelcomeFlowData, error)
 }
@@ -49,7 +107,9 @@ type userService interface {
 }
 
 type adminChecker interface {
+	IsSuperAdmin(telegramID int64) bool
 	IsAdmin(telegramID int64) bool
+	IsViewer(telegramID int64) bool
 	IsAllowedUser(telegramID int64) bool
 }
 
@@ -62,6 +122,21 @@ func (r *Router) Route(update *tgbotapi.Update) error {
 		return nil // Некорректный update
 	}
 
+	// Флуд и заблокированные пользователи отсекаются до любой обработки
+	if r.dropBanned(ctx, update, telegramID) {
+		return nil
+	}
+
+	// Клиент открыл ссылку из предложения тарифа: доступ к боту ему не нужен, заявка уходит ассистенту-отправителю
+	if link, ok := r.clientOfferLink(update, telegramID); ok {
+		return r.tariffOffersCommand.HandleClientStart(ctx, update.Message, link.ID, link.AssistantID)
+	}
+
+	// В режиме мягкого запуска ботом пользуются только админы и пользователи из белого списка
+	if !r.adminChecker.IsAdmin(telegramID) && r.whitelistCommand.Restricts(ctx, telegramID) {
+		return r.whitelistCommand.HandleOutsider(ctx, update)
+	}
+
 	// Проверяем доступ к боту
 	if !r.adminChecker.IsAllowedUser(telegramID) {
 		return r.sendAccessDenied(extractChatID(update))
@@ -77,6 +152,20 @@ func (r *Router) Route(update *tgbotapi.Update) error {
 		return err
 	}
 
+	// Inline-режим: предложения тарифов (@бот тарифы) и поиск клиента по началу номера из любого чата (@бот +99655512)
+	if update.InlineQuery != nil {
+		if cmds.IsTariffOfferQuery(update.InlineQuery.Query) && !r.adminChecker.IsViewer(telegramID) {
+			return r.tariffOffersCommand.HandleInline(ctx, user.TelegramID, update.InlineQuery)
+		}
+		return r.inlineClientsCommand.Handle(ctx, user.TelegramID, r.adminChecker.IsAdmin(telegramID), update.InlineQuery)
+	}
+
+	// Наблюдателю доступен только просмотр: остальные команды, кнопки и флоу закрыты
+	if r.adminChecker.IsViewer(telegramID) {
+		r.setupViewerCommands(telegramID)
+		return r.handleViewer(ctx, update, user)
+	}
+
 	// Устанавливаем команды при первом взаимодействии
 	if r.adminChecker.IsAdmin(telegramID) {
 		r.setupAdminCommands(telegramID)
@@ -93,6 +182,9 @@ func (r *Router) Route(update *tgbotapi.Update) error {
 
 	// Используем внутренний ID для состояния
 	state := r.stateManager.GetState(telegramID)
+	if state != states.StateNone {
+		r.stateManager.Touch(telegramID)
+	}
 
 	// Проверяем callback кнопки из главного меню
 	if update.CallbackQuery != nil {
@@ -100,6 +192,8 @@ func (r *Router) Route(update *tgbotapi.Update) error {
 		switch {
 		case callbackData == "cancel" || callbackData == "main_menu":
 			return r.handleGlobalCancelWithInternalID(update, user)
+		case strings.HasPrefix(callbackData, flowRestartPrefix):
+			return r.restartFlow(update, user)
 		case callbackData == "my_subscriptions":
 			return r.mySubsCommand.Execute(ctx, user.TelegramID, extractChatID(update))
 		case callbackData == "stats_refresh":
@@ -135,6 +229,17 @@ func (r *Router) Route(update *tgbotapi.Update) error {
 			chatID := update.CallbackQuery.Message.Chat.ID
 			messageID := update.CallbackQuery.Message.MessageID
 			return r.statsCommand.RefreshAnalytics(ctx, chatID, messageID)
+		case callbackData == "stats_weekdays":
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "")
+			_, _ = r.bot.Request(callback)
+			chatID := update.CallbackQuery.Message.Chat.ID
+			messageID := update.CallbackQuery.Message.MessageID
+			return r.statsCommand.ShowWeekdayHeatmap(ctx, chatID, messageID)
 		case callbackData == "stats_overview":
 			if !r.adminChecker.IsAdmin(user.TelegramID) {
 				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
@@ -146,6 +251,80 @@ func (r *Router) Route(update *tgbotapi.Update) error {
 			chatID := update.CallbackQuery.Message.Chat.ID
 			messageID := update.CallbackQuery.Message.MessageID
 			return r.statsCommand.Refresh(ctx, chatID, messageID)
+		case callbackData == "stats_assistants":
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "")
+			_, _ = r.bot.Request(callback)
+			chatID := update.CallbackQuery.Message.Chat.ID
+			messageID := update.CallbackQuery.Message.MessageID
+			return r.statsCommand.ShowAssistants(chatID, messageID)
+		case strings.HasPrefix(callbackData, "my_subs_more:"):
+			return r.mySubsCommand.HandleMore(ctx, user.TelegramID, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "sub_migrate:"):
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			clientWhatsApp, ok := r.subViewCommand.ClientWhatsApp(ctx, user.TelegramID, true, update.CallbackQuery)
+			if !ok {
+				return nil
+			}
+			return r.migrateClientHandler.StartForClient(ctx, user.ID, user.TelegramID, update.CallbackQuery.Message.Chat.ID, clientWhatsApp)
+		case strings.HasPrefix(callbackData, "pause_"):
+			// Пауза подписки (pause_menu, pause_set, pause_resume): ассистент ставит на паузу свои подписки, админ - любые
+			return r.subViewCommand.HandlePauseCallback(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "grt:"), strings.HasPrefix(callbackData, "grt_ok:"):
+			// Возврат по гарантии: ассистент оформляет возврат по своим подпискам, админ - по любым
+			return r.subViewCommand.HandleGuaranteeCallback(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "sub_phone:"):
+			// Смена номера клиента: ассистент меняет номер в своих подписках, админ - в любых
+			return r.editSubHandler.StartPhoneChange(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "sub_server:"):
+			// Перенос подписки на другой сервер: ассистент переносит свои подписки, админ - любые
+			return r.editSubHandler.StartServerChange(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "sub_expiry:"):
+			// Точная дата окончания из карточки: исправление импорта и компенсации - только админ
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.editSubHandler.StartExpiryChange(ctx, user.TelegramID, true, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "sub_note:"):
+			// Заметка к подписке: ассистент пишет заметки к своим подпискам, админ - к любым
+			return r.subNoteHandler.Start(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "sub_view:"), strings.HasPrefix(callbackData, "sub_kb:"),
+			strings.HasPrefix(callbackData, "sub_payments:"), strings.HasPrefix(callbackData, "sub_disable"):
+			// Карточка подписки: ассистент видит свои подписки, админ - любые
+			return r.subViewCommand.HandleCallback(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "cli:"), strings.HasPrefix(callbackData, "clisrv:"):
+			// Справочник клиентов: ассистент видит своих клиентов, админ - всех
+			return r.clientsCommand.HandleCallback(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "brn_chk:"):
+			// Проверка оплаты массового продления: ассистент проверяет свои, админ - любые
+			return r.bulkRenewSubHandler.HandleCheck(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "stats_asst:"):
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			assistantID, err := strconv.ParseInt(strings.TrimPrefix(callbackData, "stats_asst:"), 10, 64)
+			if err != nil {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "Неверный формат")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "")
+			_, _ = r.bot.Request(callback)
+			chatID := update.CallbackQuery.Message.Chat.ID
+			messageID := update.CallbackQuery.Message.MessageID
+			return r.statsCommand.ShowAssistant(ctx, chatID, messageID, assistantID)
 		case callbackData == "top_ref_refresh":
 			if !r.adminChecker.IsAdmin(user.TelegramID) {
 				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
@@ -157,13 +336,86 @@ func (r *Router) Route(update *tgbotapi.Update) error {
 			chatID := update.CallbackQuery.Message.Chat.ID
 			messageID := update.CallbackQuery.Message.MessageID
 			return r.topReferrersCommand.Refresh(ctx, chatID, messageID)
+		case strings.HasPrefix(callbackData, "rev_"):
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.revenueCommand.HandleCallback(ctx, update.CallbackQuery)
+		case callbackData == "cohorts_csv":
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "")
+			_, _ = r.bot.Request(callback)
+			return r.cohortsCommand.SendCSV(ctx, update.CallbackQuery.Message.Chat.ID)
 		case strings.HasPrefix(callbackData, "exp_"):
 			// Expiration callbacks (exp_dis, exp_link, exp_paid, exp_tariff, etc.)
 			// Доступны для всех пользователей с доступом к боту (ассистентов и админов)
 			return r.expirationCommand.HandleCallback(ctx, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "offer_go:"):
+			// Заявка клиента по предложению тарифа: ассистент оформляет подписку на предложенный тариф
+			_, _ = r.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+			tariffID, err := strconv.ParseInt(strings.TrimPrefix(callbackData, "offer_go:"), 10, 64)
+			if err != nil {
+				return nil
+			}
+			return r.createSubForClientHandler.StartWithTariff(ctx, user.ID, user.TelegramID, extractChatID(update), tariffID)
+		case strings.HasPrefix(callbackData, "tasks:"):
+			// Кнопки закрепленных задач на сегодня - списки подписок нажавшего ассистента
+			return r.expirationCommand.HandleTasksCallback(ctx, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "cal_sub:"):
+			// Calendar export: ассистент выгружает свои подписки, админ - любые
+			return r.calendarCommand.HandleCallback(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "ttu_"):
+			// Докупка трафика (ttu_menu, ttu_buy, ttu_check): ассистент - для своих подписок, админ - для любых
+			return r.trafficTopUpCommand.HandleCallback(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "wal_"):
+			// Язык клиента для сообщений WhatsApp (wal_menu, wal_set): ассистент - для своих подписок, админ - для любых
+			return r.clientLanguageCommand.HandleCallback(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "esc_"):
+			// Эскалация админам: esc_sub и esc_ord - ассистенты по своим подпискам и заказам, esc_take и esc_done - только админы
+			return r.escalationCommand.HandleCallback(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "drip_"):
+			// Отказ клиента от рассылки пробного периода (drip_off) - доступен всем пользователям с доступом к боту
+			return r.trialDripCommand.HandleCallback(ctx, user.TelegramID, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "fcr:"):
+			// Причина отмены флоу (fcr:<id>:<reason>) - отвечает тот, кто отменил флоу
+			return r.cancelReasonCommand.HandleCallback(ctx, user.TelegramID, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "dev_"):
+			// Устройство клиента и инструкция по подключению (dev_menu, dev_set): ассистент - по своим подпискам, админ - по любым
+			return r.clientPlatformCommand.HandleCallback(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "com_"):
+			// Выплата комиссии и выгрузка (com_pay, com_payok, com_csv, com_back) - только для админов
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.commissionCommand.HandleCallback(ctx, user.TelegramID, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "vac_"):
+			// Отпуск ассистента (vac_days, vac_bk, vac_off) - каждый управляет своим отпуском
+			return r.vacationCommand.HandleCallback(ctx, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "clone_sub:"):
+			// Новая подписка по образцу истекшей/отключенной: ассистент клонирует свои подписки, админ - любые
+			return r.createSubForClientHandler.HandleCloneCallback(ctx, user.ID, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "wl_"):
+			// Уведомление об освободившемся месте (wl_buy, wl_cancel): ассистент работает со своими записями, админ - с любыми
+			return r.createSubForClientHandler.HandleWaitlistCallback(ctx, user.ID, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), update.CallbackQuery)
 		case strings.HasPrefix(callbackData, "pay_"):
-			// Payment callbacks (pay_check, pay_refresh, pay_cancel) - работают независимо от состояния
+			// Payment callbacks (pay_check, pay_refresh, pay_cancel, pay_receipt) - работают независимо от состояния
 			return r.createSubForClientHandler.HandlePaymentCallback(update)
+		case strings.HasPrefix(callbackData, "rcpt_"):
+			// Чеки ручного режима оплаты (rcpt_ok, rcpt_no) - подтверждает админ
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.createSubForClientHandler.HandleReceiptCallback(ctx, user.TelegramID, update.CallbackQuery)
 		case strings.HasPrefix(callbackData, "migpay_"):
 			// Migrate payment callbacks (migpay_check, migpay_refresh, migpay_cancel) - работают независимо от состояния
 			return r.migrateClientHandler.HandleMigratePaymentCallback(update)
@@ -180,6 +432,15 @@ func (r *Router) Route(update *tgbotapi.Update) error {
 				_, _ = r.bot.Request(callback)
 				return r.createTariffHandler.Start(extractChatID(update))
 			}
+			// Редактирование тарифа запускает flow на месте списка тарифов
+			if strings.HasPrefix(callbackData, "trf_edit:") {
+				_, _ = r.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+				tariffID, err := strconv.ParseInt(strings.TrimPrefix(callbackData, "trf_edit:"), 10, 64)
+				if err != nil {
+					return nil
+				}
+				return r.editTariffHandler.Start(ctx, extractChatID(update), update.CallbackQuery.Message.MessageID, tariffID)
+			}
 			return r.tariffsCommand.HandleCallback(ctx, update.CallbackQuery)
 		case strings.HasPrefix(callbackData, "srv_"):
 			// Server callbacks
@@ -194,7 +455,99 @@ func (r *Router) Route(update *tgbotapi.Update) error {
 				_, _ = r.bot.Request(callback)
 				return r.addServerHandler.Start(extractChatID(update))
 			}
+			// Редактирование сервера запускает flow на месте списка серверов
+			if strings.HasPrefix(callbackData, "srv_edit:") {
+				_, _ = r.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+				serverID, err := strconv.ParseInt(strings.TrimPrefix(callbackData, "srv_edit:"), 10, 64)
+				if err != nil {
+					return nil
+				}
+				return r.editServerHandler.Start(ctx, extractChatID(update), update.CallbackQuery.Message.MessageID, serverID)
+			}
 			return r.serversCommand.HandleCallback(ctx, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "spay_"):
+			// Stuck payments alert callbacks (spay_check, spay_cancel)
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.stuckPaymentsCommand.HandleCallback(ctx, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "late_"):
+			// Late payment alert callbacks (late_fulfill, late_refund, late_credit)
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.latePaymentsCommand.HandleCallback(ctx, user.TelegramID, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "usub_"):
+			// Unpaid subscriptions alert callbacks (usub_inv, usub_ign)
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.unpaidSubsCommand.HandleCallback(ctx, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "ban_rm:"):
+			// Снятие бана из списка /bans
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.bansCommand.HandleCallback(ctx, user.TelegramID, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "phm:"):
+			// Объединение форматов номера клиента из списка /merge_phones
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.mergePhonesCommand.HandleCallback(ctx, user.TelegramID, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "wtpl:"):
+			// Шаблоны сообщений WhatsApp из /templates
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.waTemplateHandler.HandleCallback(ctx, user.TelegramID, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "dbs_"):
+			// Обслуживание базы из /db_stats
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.dbStatsCommand.HandleCallback(ctx, user.TelegramID, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "lang_set:"):
+			// Язык интерфейса бота - доступен всем пользователям с доступом к боту
+			return r.languageCommand.HandleCallback(ctx, user.TelegramID, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "role_set:"):
+			// Выбор роли кнопкой (role_set:ID:роль); админов назначает только супер-админ (проверяется в команде)
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.rolesCommand.HandleCallback(ctx, user.TelegramID, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "lwl_ok:"), strings.HasPrefix(callbackData, "lwl_rm:"):
+			// Белый список мягкого запуска (lwl_ok, lwl_rm)
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.whitelistCommand.HandleCallback(ctx, user.TelegramID, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "wap_"):
+			// WhatsApp outreach plan callbacks (wap_menu, wap_week, wap_srv, etc.)
+			if !r.adminChecker.IsAdmin(user.TelegramID) {
+				callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "❌ Нет прав")
+				_, _ = r.bot.Request(callback)
+				return nil
+			}
+			return r.waPlanCommand.HandleCallback(ctx, update.CallbackQuery)
 		}
 	}
 
@@ -208,16 +561,61 @@ func (r *Router) Route(update *tgbotapi.Update) error {
 		return r.createTariffHandler.Handle(update, state)
 	}
 
+	// Проверяем состояние флоу редактирования тарифа
+	if strings.HasPrefix(string(state), "aet_") {
+		return r.editTariffHandler.Handle(update, state)
+	}
+
 	// Проверяем состояние флоу добавления сервера
 	if strings.HasPrefix(string(state), "asv_") {
 		return r.addServerHandler.Handle(update, state)
 	}
 
+	// Проверяем состояние флоу редактирования сервера
+	if strings.HasPrefix(string(state), "aesv_") {
+		return r.editServerHandler.Handle(update, state)
+	}
+
 	// Проверяем состояние флоу миграции клиента
 	if strings.HasPrefix(string(state), "amc_") {
 		return r.migrateClientHandler.Handle(update, state)
 	}
 
+	// Проверяем состояние флоу редактирования подписки
+	if strings.HasPrefix(string(state), "aes_") {
+		return r.editSubHandler.Handle(update, state)
+	}
+
+	// Проверяем состояние флоу отмены подписки
+	if strings.HasPrefix(string(state), "acl_") {
+		return r.cancelSubHandler.Handle(update, state)
+	}
+
+	// Проверяем состояние флоу массового продления
+	if strings.HasPrefix(string(state), "abr_") {
+		return r.bulkRenewSubHandler.Handle(update, state)
+	}
+
+	// Проверяем состояние флоу передачи подписок
+	if strings.HasPrefix(string(state), "ats_") {
+		return r.transferSubsHandler.Handle(update, state)
+	}
+
+	// Проверяем состояние флоу рассылки
+	if strings.HasPrefix(string(state), "abc_") {
+		return r.broadcastHandler.Handle(update, state)
+	}
+
+	// Проверяем состояние флоу заметки к подписке
+	if strings.HasPrefix(string(state), "asn_") {
+		return r.subNoteHandler.Handle(update, state)
+	}
+
+	// Проверяем состояние флоу редактирования шаблона WhatsApp
+	if strings.HasPrefix(string(state), "awt_") {
+		return r.waTemplateHandler.Handle(update, state)
+	}
+
 	// Если нет активного состояния - обрабатываем как обычное сообщение
 	return r.sendHelp(extractChatID(update))
 }
@@ -230,9 +628,19 @@ func (r *Router) handleCommandWithUser(update *tgbotapi.Update, user *users.User
 	ctx := context.Background()
 	chatID := update.Message.Chat.ID
 
+	// Повторный вызов медленной команды, пока считается первый, не запускаем
+	if command := update.Message.Command(); slowCommands[command] {
+		done, ok := r.inflight.Begin(chatID, command, update.Message.Time())
+		if !ok {
+			_, err := r.bot.Send(tgbotapi.NewMessage(chatID, "⏳ /"+command+" уже выполняется"))
+			return err
+		}
+		defer done()
+	}
+
 	switch update.Message.Command() {
 	case "start":
-		return r.sendWelcome(chatID, user)
+		return r.handleStartPayload(ctx, chatID, user, update.Message.CommandArguments())
 	case "create_sub":
 		// Любой пользователь может создавать подписки для клиентов (ассистенты)
 		return r.createSubForClientHandler.Start(user.ID, user.TelegramID, chatID)
@@ -250,18 +658,58 @@ func (r *Router) handleCommandWithUser(update *tgbotapi.Update, user *users.User
 		return r.serversCommand.Execute(ctx, chatID)
 	case "my_subs":
 		return r.mySubsCommand.Execute(ctx, user.TelegramID, chatID)
+	case "language":
+		return r.languageCommand.Execute(chatID, user.Language)
+	case "vacation":
+		return r.vacationCommand.Execute(ctx, user.TelegramID, chatID, update.Message.CommandArguments())
 	case "stats":
 		if !r.adminChecker.IsAdmin(user.TelegramID) {
 			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для просмотра статистики"))
 			return r.sendHelp(chatID)
 		}
-		return r.statsCommand.Execute(ctx, chatID)
+		return r.statsCommand.Execute(ctx, chatID, update.Message.CommandArguments())
+	case "revenue":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для просмотра выручки"))
+			return r.sendHelp(chatID)
+		}
+		return r.revenueCommand.Execute(ctx, chatID, update.Message.CommandArguments())
+	case "export_subs":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для выгрузки подписок"))
+			return r.sendHelp(chatID)
+		}
+		return r.exportSubsCommand.Execute(ctx, chatID, update.Message.CommandArguments())
+	case "export_payments":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для выгрузки платежей"))
+			return r.sendHelp(chatID)
+		}
+		return r.exportPaymentsCommand.Execute(ctx, chatID, update.Message.CommandArguments())
+	case "payments_pending":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для подтверждения платежей"))
+			return r.sendHelp(chatID)
+		}
+		return r.createSubForClientHandler.ShowPaymentsQueue(ctx, chatID)
 	case "top_referrers":
 		if !r.adminChecker.IsAdmin(user.TelegramID) {
 			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для просмотра топа рефералов"))
 			return r.sendHelp(chatID)
 		}
 		return r.topReferrersCommand.Execute(ctx, chatID)
+	case "cohorts":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для просмотра когорт"))
+			return r.sendHelp(chatID)
+		}
+		return r.cohortsCommand.Execute(ctx, chatID)
+	case "waplan":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для плана рассылки"))
+			return r.sendHelp(chatID)
+		}
+		return r.waPlanCommand.Execute(ctx, chatID)
 	case "overdue":
 		// Все ассистенты видят все просроченные подписки
 		return r.expirationCommand.ExecuteOverdue(ctx, chatID, nil)
@@ -271,44 +719,186 @@ func (r *Router) handleCommandWithUser(update *tgbotapi.Update, user *users.User
 	case "exp3":
 		// Все ассистенты видят все подписки истекающие через 3 дня
 		return r.expirationCommand.ExecuteExp3(ctx, chatID, nil)
+	case "quick_sub":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для быстрого создания подписок"))
+			return r.sendHelp(chatID)
+		}
+		return r.createSubForClientHandler.QuickStart(ctx, user.ID, user.TelegramID, chatID, update.Message.CommandArguments())
+	case "sub_price":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для изменения цен"))
+			return r.sendHelp(chatID)
+		}
+		return r.subPriceCommand.Execute(ctx, user.TelegramID, chatID, update.Message.CommandArguments())
+	case "tariff_price":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для изменения цен"))
+			return r.sendHelp(chatID)
+		}
+		return r.tariffPriceCommand.Execute(ctx, user.TelegramID, chatID, update.Message.CommandArguments())
+	case "server_price":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для изменения цен"))
+			return r.sendHelp(chatID)
+		}
+		return r.serverPriceCommand.Execute(ctx, user.TelegramID, chatID, update.Message.CommandArguments())
 	case "migrate_client":
 		if !r.adminChecker.IsAdmin(user.TelegramID) {
 			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для миграции клиентов"))
 			return r.sendHelp(chatID)
 		}
 		return r.migrateClientHandler.Start(user.ID, user.TelegramID, chatID)
+	case "transfer_subs":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для передачи подписок"))
+			return r.sendHelp(chatID)
+		}
+		return r.transferSubsHandler.Start(ctx, user.TelegramID, chatID)
+	case "ban":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для блокировки пользователей"))
+			return r.sendHelp(chatID)
+		}
+		return r.bansCommand.Ban(ctx, user.TelegramID, chatID, update.Message.CommandArguments())
+	case "bans":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для блокировки пользователей"))
+			return r.sendHelp(chatID)
+		}
+		return r.bansCommand.Execute(ctx, chatID)
+	case "merge_phones":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для объединения клиентов"))
+			return r.sendHelp(chatID)
+		}
+		return r.mergePhonesCommand.Execute(ctx, chatID)
+	case "templates":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для изменения шаблонов"))
+			return r.sendHelp(chatID)
+		}
+		return r.waTemplateHandler.Execute(ctx, chatID)
+	case "db_stats":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для просмотра состояния базы"))
+			return r.sendHelp(chatID)
+		}
+		return r.dbStatsCommand.Execute(ctx, chatID)
+	case "whitelist":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для управления белым списком"))
+			return r.sendHelp(chatID)
+		}
+		return r.whitelistCommand.Execute(ctx, user.TelegramID, chatID, update.Message.CommandArguments())
+	case "add_admin", "remove_admin":
+		// Короткие формы /set_role <ID> admin и /set_role <ID> assistant
+		if !r.adminChecker.IsSuperAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ Назначать админов может только супер-админ"))
+			return r.sendHelp(chatID)
+		}
+		role := users.RoleAdmin
+		if update.Message.Command() == "remove_admin" {
+			role = users.RoleAssistant
+		}
+		return r.rolesCommand.ExecuteAlias(ctx, user.TelegramID, chatID, update.Message.CommandArguments(), role)
+	case "set_role":
+		// Админы назначают ассистентов и наблюдателей, админов - только супер-админ (проверяется в команде)
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для управления ролями"))
+			return r.sendHelp(chatID)
+		}
+		return r.rolesCommand.Execute(ctx, user.TelegramID, chatID, update.Message.CommandArguments())
+	case "broadcast":
+		if !r.adminChecker.IsAdmin(user.TelegramID) {
+			_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, "❌ У вас нет прав для рассылки"))
+			return r.sendHelp(chatID)
+		}
+		return r.broadcastHandler.Start(ctx, user.TelegramID, chatID)
+	case "edit_sub":
+		// Ассистент меняет свои подписки, админ - любые
+		return r.editSubHandler.Start(ctx, user.TelegramID, chatID, r.adminChecker.IsAdmin(user.TelegramID))
+	case "cancel_sub":
+		// Ассистент отменяет свои подписки, админ - любые
+		return r.cancelSubHandler.Start(ctx, user.TelegramID, chatID, r.adminChecker.IsAdmin(user.TelegramID))
+	case "find":
+		// Ассистент ищет среди своих подписок, админ - среди всех
+		return r.findCommand.Execute(ctx, user.TelegramID, chatID, r.adminChecker.IsAdmin(user.TelegramID), update.Message.CommandArguments())
+	case "check":
+		// Ассистент проверяет своих клиентов, админ - любых
+		return r.checkCommand.Execute(ctx, user.TelegramID, chatID, r.adminChecker.IsAdmin(user.TelegramID), update.Message.CommandArguments())
+	case "referral":
+		return r.referralCommand.Execute(ctx, chatID, update.Message.CommandArguments())
+	case "commission":
+		// Ассистент видит свою комиссию, админ - по всем ассистентам с выплатой и выгрузкой
+		return r.commissionCommand.Execute(ctx, user.TelegramID, chatID, r.adminChecker.IsAdmin(user.TelegramID))
+	case "clients":
+		// Ассистент видит своих клиентов, админ - всех
+		return r.clientsCommand.Execute(ctx, user.TelegramID, chatID, r.adminChecker.IsAdmin(user.TelegramID))
+	case "pending_orders":
+		// Только свои заказы: кнопки оплаты повторяют исходное сообщение заказа
+		return r.createSubForClientHandler.ShowPendingOrders(ctx, user.TelegramID, chatID)
+	case "bulk_renew":
+		// Ассистент продлевает свои подписки, админ - любые
+		return r.bulkRenewSubHandler.Start(ctx, user.ID, user.TelegramID, chatID, r.adminChecker.IsAdmin(user.TelegramID))
 	default:
 		return r.sendHelp(chatID)
 	}
 }
 
+// handleStartPayload обрабатывает /start со ссылки t.me/<bot>?start=<payload>; без параметра - приветствие
+func (r *Router) handleStartPayload(ctx context.Context, chatID int64, user *users.User, payload string) error {
+	link := ParseDeepLink(payload)
+	switch link.Kind {
+	case DeepLinkReferral:
+		return r.createSubForClientHandler.StartWithReferrer(ctx, user.ID, user.TelegramID, chatID, link.ID)
+	case DeepLinkGift:
+		return r.createSubForClientHandler.StartWithTariff(ctx, user.ID, user.TelegramID, chatID, link.ID)
+	case DeepLinkPayment:
+		return r.createSubForClientHandler.ShowOrder(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), chatID, link.ID)
+	case DeepLinkSub:
+		return r.subViewCommand.Open(ctx, user.TelegramID, r.adminChecker.IsAdmin(user.TelegramID), chatID, link.ID)
+	case DeepLinkOffer:
+		// Сотрудник открыл ссылку из предложения тарифа - сразу оформление на этот тариф
+		return r.createSubForClientHandler.StartWithTariff(ctx, user.ID, user.TelegramID, chatID, link.ID)
+	default:
+		return r.sendWelcome(chatID, user)
+	}
+}
+
+// clientOfferLink возвращает ссылку из предложения тарифа, если /start offer_* прислал клиент без доступа к боту,
+// а отправивший предложение все еще админ или ассистент
+func (r *Router) clientOfferLink(update *tgbotapi.Update, telegramID int64) (DeepLink, bool) {
+	if update.Message == nil || update.Message.Command() != "start" || r.adminChecker.IsAllowedUser(telegramID) {
+		return DeepLink{}, false
+	}
+	link := ParseDeepLink(update.Message.CommandArguments())
+	if link.Kind != DeepLinkOffer || !r.adminChecker.IsAllowedUser(link.AssistantID) || r.adminChecker.IsViewer(link.AssistantID) {
+		return DeepLink{}, false
+	}
+	return link, true
+}
+
 func (r *Router) sendWelcome(chatID int64, user *users.User) error {
-	text := "Добро пожаловать!\n\nЭтот бот помогает ассистентам управлять подписками клиентов."
+	l := messages.For(user.Language)
+	brand := messages.CurrentBrand()
+	text := brand.Name + "\n\n" + l.T(messages.KeyWelcome)
 
 	// Создаем кнопки для ассистентов
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				"Мои подписки",
+				l.T(messages.KeyButtonMySubs),
 				"my_subscriptions",
 			),
 		),
 	)
 
 	if r.adminChecker.IsAdmin(chatID) {
-		text += "\n\nКоманды администратора:\n" +
-			"/tariffs — Управление тарифами\n" +
-			"/servers — Управление серверами\n" +
-			"/stats — Просмотр статистики\n" +
-			"/top_referrers — Топ рефералов за неделю\n" +
-			"/overdue — Просроченные подписки\n" +
-			"/expiring — Истекающие подписки\n" +
-			"/exp3 — Истекающие через 3 дня"
+		text += "\n\n" + l.T(messages.KeyAdminCommandsTitle) + "\n" + l.T(messages.KeyAdminCommands)
 	}
 
-	text += "\n\nКоманды ассистента:\n" +
-		"/create_sub — Создать подписку для клиента\n" +
-		"/my_subs — Список подписок"
+	text += "\n\n" + l.T(messages.KeyAssistantCommandsTitle) + "\n" + l.T(messages.KeyAssistantCommands)
 
 	// Проверяем есть ли сохраненное сообщение для редактирования
 	welcomeData, _ := r.stateManager.GetWelcomeData(chatID)
@@ -316,8 +906,15 @@ func (r *Router) sendWelcome(chatID int64, user *users.User) error {
 		// Редактируем существующее сообщение
 		editMsg := tgbotapi.NewEditMessageText(chatID, welcomeData.MessageID, text)
 		editMsg.ReplyMarkup = &keyboard
-		_, err := r.bot.Send(editMsg)
-		return err
+		return tgclient.SafeEdit(r.bot, editMsg, "")
+	}
+
+	// Логотип отдельным фото: приветствие потом редактируется как текст.
+	// Без логотипа приветствие все равно нужно, поэтому ошибку фото не возвращаем
+	if brand.LogoURL != "" {
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileURL(brand.LogoURL))
+		photo.Caption = brand.Name
+		_, _ = r.bot.Send(photo)
 	}
 
 	// Отправляем новое сообщение и сохраняем его ID
@@ -336,32 +933,80 @@ func (r *Router) sendWelcome(chatID int64, user *users.User) error {
 	return nil
 }
 
-func (r *Router) sendHelp(chatID int64) error {
-	if chatID == 0 {
-		return nil // Не можем отправить сообщение
+// localizer возвращает тексты на языке пользователя; если пользователя не удалось получить - на русском
+func (r *Router) localizer(telegramID int64) messages.Localizer {
+	user, err := r.userService.GetOrCreateUserByTelegramID(context.Background(), telegramID)
+	if err != nil || user == nil {
+		return messages.For("")
 	}
-	text := "Доступные команды:\n\n" +
-		"/start — Главное меню\n" +
-		"/create_sub — Создать подписку для клиента\n" +
-		"/my_subs — Список подписок"
+	return messages.For(user.Language)
+}
 
+// helpText возвращает список доступных команд: ассистентские и, для админов, админские
+func (r *Router) helpText(chatID int64) string {
+	l := r.localizer(chatID)
+	text := l.T(messages.KeyHelpTitle) + "\n" + l.T(messages.KeyAssistantCommands)
 	if r.adminChecker.IsAdmin(chatID) {
-		text += "\n\nКоманды администратора:\n" +
-			"/tariffs — Управление тарифами\n" +
-			"/servers — Управление серверами\n" +
-			"/stats — Просмотр статистики\n" +
-			"/top_referrers — Топ рефералов за неделю\n" +
-			"/overdue — Просроченные подписки\n" +
-			"/expiring — Истекающие подписки\n" +
-			"/exp3 — Истекающие через 3 дня"
+		text += "\n\n" + l.T(messages.KeyAdminCommandsTitle) + "\n" + l.T(messages.KeyAdminCommands)
 	}
-	msg := tgbotapi.NewMessage(chatID, text)
+	return text
+}
+
+func (r *Router) sendHelp(chatID int64) error {
+	if chatID == 0 {
+		return nil // Не можем отправить сообщение
+	}
+	msg := tgbotapi.NewMessage(chatID, r.helpText(chatID))
 	_, err := r.bot.Send(msg)
 	return err
 }
 
 func (r *Router) sendError(chatID int64) error {
-	msg := tgbotapi.NewMessage(chatID, messages.Error)
+	msg := tgbotapi.NewMessage(chatID, r.localizer(chatID).T(messages.KeyError))
+	_, err := r.bot.Send(msg)
+	return err
+}
+
+// handleViewer обрабатывает апдейты наблюдателя: /my_subs и общая статистика без разбивки
+func (r *Router) handleViewer(ctx context.Context, update *tgbotapi.Update, user *users.User) error {
+	chatID := extractChatID(update)
+	l := messages.For(user.Language)
+
+	if update.CallbackQuery != nil {
+		callbackData := update.CallbackQuery.Data
+		switch {
+		case callbackData == "my_subscriptions":
+			_, _ = r.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+			return r.mySubsCommand.Execute(ctx, user.TelegramID, chatID)
+		case strings.HasPrefix(callbackData, "my_subs_more:"):
+			return r.mySubsCommand.HandleMore(ctx, user.TelegramID, update.CallbackQuery)
+		case strings.HasPrefix(callbackData, "lang_set:"):
+			return r.languageCommand.HandleCallback(ctx, user.TelegramID, update.CallbackQuery)
+		}
+		_, _ = r.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, l.T(messages.KeyViewerOnly)))
+		return nil
+	}
+
+	if update.Message == nil || !update.Message.IsCommand() {
+		return r.sendViewerHelp(chatID, l)
+	}
+
+	switch update.Message.Command() {
+	case "my_subs":
+		return r.mySubsCommand.Execute(ctx, user.TelegramID, chatID)
+	case "stats":
+		return r.statsCommand.ExecuteOverview(ctx, chatID)
+	case "language":
+		return r.languageCommand.Execute(chatID, user.Language)
+	case "start", "help":
+		return r.sendViewerHelp(chatID, l)
+	}
+	_, _ = r.bot.Send(tgbotapi.NewMessage(chatID, l.T(messages.KeyViewerOnly)))
+	return r.sendViewerHelp(chatID, l)
+}
+
+func (r *Router) sendViewerHelp(chatID int64, l messages.Localizer) error {
+	msg := tgbotapi.NewMessage(chatID, l.T(messages.KeyViewerHelp))
 	_, err := r.bot.Send(msg)
 	return err
 }
@@ -377,6 +1022,30 @@ func (r *Router) sendAccessDenied(chatID int64) error {
 	return err
 }
 
+// dropBanned учитывает апдейт во флуд-контроле и проверяет бан. true - апдейт нужно отбросить.
+// Заблокированному пользователю отвечаем только на команды и кнопки, остальное молча игнорируем
+func (r *Router) dropBanned(ctx context.Context, update *tgbotapi.Update, telegramID int64) bool {
+	if r.adminChecker.IsAdmin(telegramID) {
+		return false
+	}
+
+	if r.floodGuard.Hit(telegramID, time.Now()) {
+		r.bansCommand.AutoBan(ctx, telegramID, "флуд: слишком много запросов подряд")
+	}
+
+	if !r.bansCommand.IsBanned(ctx, telegramID) {
+		return false
+	}
+
+	switch {
+	case update.Message != nil && update.Message.IsCommand():
+		_, _ = r.bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "🚫 Доступ к боту заблокирован"))
+	case update.CallbackQuery != nil:
+		_, _ = r.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, "🚫 Доступ к боту заблокирован"))
+	}
+	return true
+}
+
 func extractUserID(update *tgbotapi.Update) int64 {
 	if update.Message != nil {
 		return update.Message.From.ID
@@ -384,6 +1053,9 @@ func extractUserID(update *tgbotapi.Update) int64 {
 	if update.CallbackQuery != nil {
 		return update.CallbackQuery.From.ID
 	}
+	if update.InlineQuery != nil {
+		return update.InlineQuery.From.ID
+	}
 	return 0
 }
 
@@ -421,25 +1093,8 @@ func (r *Router) handleGlobalCancelWithInternalID(update *tgbotapi.Update, user
 
 // editToHelp редактирует сообщение на список доступных команд
 func (r *Router) editToHelp(chatID int64, messageID int) error {
-	text := "Доступные команды:\n\n" +
-		"/start — Главное меню\n" +
-		"/create_sub — Создать подписку для клиента\n" +
-		"/my_subs — Список подписок"
-
-	if r.adminChecker.IsAdmin(chatID) {
-		text += "\n\nКоманды администратора:\n" +
-			"/tariffs — Управление тарифами\n" +
-			"/servers — Управление серверами\n" +
-			"/stats — Просмотр статистики\n" +
-			"/top_referrers — Топ рефералов за неделю\n" +
-			"/overdue — Просроченные подписки\n" +
-			"/expiring — Истекающие подписки\n" +
-			"/exp3 — Истекающие через 3 дня"
-	}
-
-	editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text)
-	_, err := r.bot.Send(editMsg)
-	return err
+	editMsg := tgbotapi.NewEditMessageText(chatID, messageID, r.helpText(chatID))
+	return tgclient.SafeEdit(r.bot, editMsg, "")
 }
 
 // NewRouter создает новый роутер с зависимостями
@@ -452,12 +1107,55 @@ func NewRouter(
 	createTariffHandler *createtariff.Handler,
 	addServerHandler *addserver.Handler,
 	migrateClientHandler *migrateclient.Handler,
+	editSubHandler *editsub.Handler,
+	cancelSubHandler *cancelsub.Handler,
 	mySubsCommand *cmds.MySubsCommand,
 	statsCommand *cmds.StatsCommand,
 	expirationCommand *cmds.ExpirationCommand,
 	tariffsCommand *cmds.TariffsCommand,
 	serversCommand *cmds.ServersCommand,
 	topReferrersCommand *cmds.TopReferrersCommand,
+	stuckPaymentsCommand *cmds.StuckPaymentsCommand,
+	cohortsCommand *cmds.CohortsCommand,
+	waPlanCommand *cmds.WAPlanCommand,
+	calendarCommand *cmds.CalendarCommand,
+	trafficTopUpCommand *cmds.TrafficTopUpCommand,
+	unpaidSubsCommand *cmds.UnpaidSubsCommand,
+	vacationCommand *cmds.VacationCommand,
+	clientLanguageCommand *cmds.ClientLanguageCommand,
+	subPriceCommand *cmds.SubPriceCommand,
+	tariffPriceCommand *cmds.TariffPriceCommand,
+	serverPriceCommand *cmds.ServerPriceCommand,
+	findCommand *cmds.FindCommand,
+	latePaymentsCommand *cmds.LatePaymentsCommand,
+	subViewCommand *cmds.SubViewCommand,
+	bulkRenewSubHandler *bulkrenewsub.Handler,
+	transferSubsHandler *transfersubs.Handler,
+	editTariffHandler *edittariff.Handler,
+	editServerHandler *editserver.Handler,
+	bansCommand *cmds.BansCommand,
+	mergePhonesCommand *cmds.MergePhonesCommand,
+	dbStatsCommand *cmds.DBStatsCommand,
+	floodGuard *bans.FloodGuard,
+	clientsCommand *cmds.ClientsCommand,
+	whitelistCommand *cmds.WhitelistCommand,
+	broadcastHandler *sendbroadcast.Handler,
+	referralCommand *cmds.ReferralCommand,
+	revenueCommand *cmds.RevenueCommand,
+	exportSubsCommand *cmds.ExportSubsCommand,
+	exportPaymentsCommand *cmds.ExportPaymentsCommand,
+	clientPlatformCommand *cmds.ClientPlatformCommand,
+	commissionCommand *cmds.CommissionCommand,
+	trialDripCommand *cmds.TrialDripCommand,
+	escalationCommand *cmds.EscalationCommand,
+	cancelReasonCommand *cmds.CancelReasonCommand,
+	subNoteHandler *subnote.Handler,
+	waTemplateHandler *watemplate.Handler,
+	rolesCommand *cmds.RolesCommand,
+	languageCommand *cmds.LanguageCommand,
+	inlineClientsCommand *cmds.InlineClientsCommand,
+	checkCommand *cmds.CheckCommand,
+	tariffOffersCommand *cmds.TariffOffersCommand,
 ) *Router {
 	return &Router{
 		bot:                       bot,
@@ -468,12 +1166,56 @@ func NewRouter(
 		createTariffHandler:       createTariffHandler,
 		addServerHandler:          addServerHandler,
 		migrateClientHandler:      migrateClientHandler,
+		editSubHandler:            editSubHandler,
+		cancelSubHandler:          cancelSubHandler,
 		mySubsCommand:             mySubsCommand,
 		statsCommand:              statsCommand,
 		expirationCommand:         expirationCommand,
 		tariffsCommand:            tariffsCommand,
 		serversCommand:            serversCommand,
 		topReferrersCommand:       topReferrersCommand,
+		stuckPaymentsCommand:      stuckPaymentsCommand,
+		cohortsCommand:            cohortsCommand,
+		waPlanCommand:             waPlanCommand,
+		calendarCommand:           calendarCommand,
+		trafficTopUpCommand:       trafficTopUpCommand,
+		unpaidSubsCommand:         unpaidSubsCommand,
+		vacationCommand:           vacationCommand,
+		clientLanguageCommand:     clientLanguageCommand,
+		subPriceCommand:           subPriceCommand,
+		tariffPriceCommand:        tariffPriceCommand,
+		serverPriceCommand:        serverPriceCommand,
+		findCommand:               findCommand,
+		latePaymentsCommand:       latePaymentsCommand,
+		subViewCommand:            subViewCommand,
+		bulkRenewSubHandler:       bulkRenewSubHandler,
+		transferSubsHandler:       transferSubsHandler,
+		editTariffHandler:         editTariffHandler,
+		editServerHandler:         editServerHandler,
+		bansCommand:               bansCommand,
+		mergePhonesCommand:        mergePhonesCommand,
+		dbStatsCommand:            dbStatsCommand,
+		floodGuard:                floodGuard,
+		clientsCommand:            clientsCommand,
+		whitelistCommand:          whitelistCommand,
+		broadcastHandler:          broadcastHandler,
+		referralCommand:           referralCommand,
+		revenueCommand:            revenueCommand,
+		exportSubsCommand:         exportSubsCommand,
+		exportPaymentsCommand:     exportPaymentsCommand,
+		clientPlatformCommand:     clientPlatformCommand,
+		commissionCommand:         commissionCommand,
+		trialDripCommand:          trialDripCommand,
+		escalationCommand:         escalationCommand,
+		cancelReasonCommand:       cancelReasonCommand,
+		subNoteHandler:            subNoteHandler,
+		waTemplateHandler:         waTemplateHandler,
+		rolesCommand:              rolesCommand,
+		languageCommand:           languageCommand,
+		inlineClientsCommand:      inlineClientsCommand,
+		checkCommand:              checkCommand,
+		tariffOffersCommand:       tariffOffersCommand,
+		inflight:                  newCommandTracker(),
 	}
 }
 
@@ -489,10 +1231,54 @@ func (r *Router) SetupBotCommands() error {
 			Command:     "create_sub",
 			Description: "Создать подписку для клиента",
 		},
+		{
+			Command:     "edit_sub",
+			Description: "Редактировать подписку",
+		},
+		{
+			Command:     "cancel_sub",
+			Description: "Отменить подписку",
+		},
+		{
+			Command:     "find",
+			Description: "Поиск клиента",
+		},
+		{
+			Command:     "check",
+			Description: "Статус клиентов по списку номеров",
+		},
+		{
+			Command:     "clients",
+			Description: "Список клиентов",
+		},
+		{
+			Command:     "referral",
+			Description: "Реферальная ссылка клиента",
+		},
+		{
+			Command:     "commission",
+			Description: "Комиссия к выплате",
+		},
+		{
+			Command:     "bulk_renew",
+			Description: "Продлить несколько подписок",
+		},
+		{
+			Command:     "pending_orders",
+			Description: "Неоплаченные заказы",
+		},
 		{
 			Command:     "my_subs",
 			Description: "Список подписок",
 		},
+		{
+			Command:     "vacation",
+			Description: "Отпуск и замена",
+		},
+		{
+			Command:     "language",
+			Description: "Язык бота",
+		},
 	}
 
 	setCommandsConfig := tgbotapi.NewSetMyCommands(commands...)
@@ -511,10 +1297,54 @@ func (r *Router) setupAdminCommands(chatID int64) {
 			Command:     "create_sub",
 			Description: "Создать подписку для клиента",
 		},
+		{
+			Command:     "edit_sub",
+			Description: "Редактировать подписку",
+		},
+		{
+			Command:     "cancel_sub",
+			Description: "Отменить подписку",
+		},
+		{
+			Command:     "find",
+			Description: "Поиск клиента",
+		},
+		{
+			Command:     "check",
+			Description: "Статус клиентов по списку номеров",
+		},
+		{
+			Command:     "clients",
+			Description: "Список клиентов",
+		},
+		{
+			Command:     "referral",
+			Description: "Реферальная ссылка клиента",
+		},
+		{
+			Command:     "commission",
+			Description: "Комиссия к выплате",
+		},
+		{
+			Command:     "bulk_renew",
+			Description: "Продлить несколько подписок",
+		},
+		{
+			Command:     "pending_orders",
+			Description: "Неоплаченные заказы",
+		},
 		{
 			Command:     "my_subs",
 			Description: "Список подписок",
 		},
+		{
+			Command:     "vacation",
+			Description: "Отпуск и замена",
+		},
+		{
+			Command:     "language",
+			Description: "Язык бота",
+		},
 		{
 			Command:     "tariffs",
 			Description: "Управление тарифами",
@@ -527,10 +1357,50 @@ func (r *Router) setupAdminCommands(chatID int64) {
 			Command:     "stats",
 			Description: "Просмотр статистики",
 		},
+		{
+			Command:     "revenue",
+			Description: "Выручка за период",
+		},
+		{
+			Command:     "payments_pending",
+			Description: "Платежи на подтверждении",
+		},
+		{
+			Command:     "export_subs",
+			Description: "Выгрузка подписок в CSV",
+		},
+		{
+			Command:     "export_payments",
+			Description: "Выгрузка платежей в CSV",
+		},
 		{
 			Command:     "top_referrers",
 			Description: "Топ рефералов за неделю",
 		},
+		{
+			Command:     "cohorts",
+			Description: "Когорты клиентов",
+		},
+		{
+			Command:     "waplan",
+			Description: "План рассылки WhatsApp",
+		},
+		{
+			Command:     "quick_sub",
+			Description: "Быстрое создание подписки",
+		},
+		{
+			Command:     "sub_price",
+			Description: "Индивидуальная цена продления",
+		},
+		{
+			Command:     "tariff_price",
+			Description: "Плановое изменение цены тарифа",
+		},
+		{
+			Command:     "server_price",
+			Description: "Наценка сервера",
+		},
 		{
 			Command:     "overdue",
 			Description: "Просроченные подписки",
@@ -547,6 +1417,50 @@ func (r *Router) setupAdminCommands(chatID int64) {
 			Command:     "migrate_client",
 			Description: "Миграция существующего клиента",
 		},
+		{
+			Command:     "transfer_subs",
+			Description: "Передать подписки другому ассистенту",
+		},
+		{
+			Command:     "ban",
+			Description: "Заблокировать пользователя",
+		},
+		{
+			Command:     "bans",
+			Description: "Список блокировок",
+		},
+		{
+			Command:     "merge_phones",
+			Description: "Дубли номеров клиентов",
+		},
+		{
+			Command:     "templates",
+			Description: "Шаблоны сообщений WhatsApp",
+		},
+		{
+			Command:     "db_stats",
+			Description: "Состояние базы данных",
+		},
+		{
+			Command:     "whitelist",
+			Description: "Белый список мягкого запуска",
+		},
+		{
+			Command:     "set_role",
+			Description: "Роли пользователей",
+		},
+		{
+			Command:     "add_admin",
+			Description: "Назначить админа",
+		},
+		{
+			Command:     "remove_admin",
+			Description: "Снять админа",
+		},
+		{
+			Command:     "broadcast",
+			Description: "Рассылка всем, кто создавал подписки",
+		},
 	}
 
 	scope := tgbotapi.NewBotCommandScopeChat(chatID)
@@ -559,6 +1473,32 @@ func (r *Router) setupAdminCommands(chatID int64) {
 	_, _ = r.bot.Request(setCommandsConfig)
 }
 
+// setupViewerCommands устанавливает команды для наблюдателей: только просмотр
+func (r *Router) setupViewerCommands(chatID int64) {
+	commands := []tgbotapi.BotCommand{
+		{
+			Command:     "my_subs",
+			Description: "Список подписок",
+		},
+		{
+			Command:     "stats",
+			Description: "Общая статистика",
+		},
+		{
+			Command:     "language",
+			Description: "Язык бота",
+		},
+	}
+
+	scope := tgbotapi.NewBotCommandScopeChat(chatID)
+	setCommandsConfig := tgbotapi.SetMyCommandsConfig{
+		Commands: commands,
+		Scope:    &scope,
+	}
+
+	_, _ = r.bot.Request(setCommandsConfig)
+}
+
 // setupAssistantCommands устанавливает команды для ассистентов (без админских)
 func (r *Router) setupAssistantCommands(chatID int64) {
 	commands := []tgbotapi.BotCommand{
@@ -570,10 +1510,54 @@ func (r *Router) setupAssistantCommands(chatID int64) {
 			Command:     "create_sub",
 			Description: "Создать подписку для клиента",
 		},
+		{
+			Command:     "edit_sub",
+			Description: "Редактировать подписку",
+		},
+		{
+			Command:     "cancel_sub",
+			Description: "Отменить подписку",
+		},
+		{
+			Command:     "find",
+			Description: "Поиск клиента",
+		},
+		{
+			Command:     "check",
+			Description: "Статус клиентов по списку номеров",
+		},
+		{
+			Command:     "clients",
+			Description: "Список клиентов",
+		},
+		{
+			Command:     "referral",
+			Description: "Реферальная ссылка клиента",
+		},
+		{
+			Command:     "commission",
+			Description: "Комиссия к выплате",
+		},
+		{
+			Command:     "bulk_renew",
+			Description: "Продлить несколько подписок",
+		},
+		{
+			Command:     "pending_orders",
+			Description: "Неоплаченные заказы",
+		},
 		{
 			Command:     "my_subs",
 			Description: "Список подписок",
 		},
+		{
+			Command:     "vacation",
+			Description: "Отпуск и замена",
+		},
+		{
+			Command:     "language",
+			Description: "Язык бота",
+		},
 		{
 			Command:     "overdue",
 			Description: "Мои просроченные подписки",
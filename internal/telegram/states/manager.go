@@ -3,6 +3,7 @@ package states
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"kurut-bot/internal/telegram/flows"
 )
@@ -12,6 +13,14 @@ type Manager struct {
 	mu         sync.RWMutex
 	userStates map[int64]State
 	userData   map[int64]any
+	touchedAt  map[int64]time.Time // последнее действие во флоу, по нему флоу истекает
+	now        func() time.Time
+}
+
+// IdleFlow - флоу, отмененный из-за бездействия
+type IdleFlow struct {
+	ChatID int64
+	State  State
 }
 
 // NewManager создает новый менеджер состояний
@@ -19,6 +28,8 @@ func NewManager() *Manager {
 	return &Manager{
 		userStates: make(map[int64]State),
 		userData:   make(map[int64]any),
+		touchedAt:  make(map[int64]time.Time),
+		now:        time.Now,
 	}
 }
 
@@ -42,17 +53,77 @@ func (m *Manager) GetData(chatID int64) any {
 	return m.userData[chatID]
 }
 
-// SetState устанавливает состояние пользователя
+// SetState устанавливает состояние пользователя. Если данные флоу помнят шаги (flows.StepRecorder),
+// переход внутри того же флоу записывается в историю для кнопки «Назад»
 func (m *Manager) SetState(chatID int64, state State, data any) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	prev, hasPrev := m.userStates[chatID]
+	stored := m.userData[chatID]
+	if data == nil {
+		data = stored
+	}
+	// Новые данные означают новый флоу - его первый шаг не связан с предыдущим состоянием
+	if recorder, ok := data.(flows.StepRecorder); ok && hasPrev && prev != state && data == stored {
+		recorder.RecordStep(string(prev), string(state))
+	}
+
 	m.userStates[chatID] = state
+	m.touchedAt[chatID] = m.now()
 	if data != nil {
 		m.userData[chatID] = data
 	}
 }
 
+// Back возвращает пользователя на предыдущий шаг флоу. false - возвращаться некуда
+func (m *Manager) Back(chatID int64) (State, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recorder, ok := m.userData[chatID].(flows.StepRecorder)
+	if !ok {
+		return StateNone, false
+	}
+	step, ok := recorder.PopStep()
+	if !ok {
+		return StateNone, false
+	}
+
+	m.userStates[chatID] = State(step)
+	m.touchedAt[chatID] = m.now()
+	return State(step), true
+}
+
+// Touch отмечает действие пользователя во флоу: ввод, который не меняет шаг, тоже продлевает сессию
+func (m *Manager) Touch(chatID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.userStates[chatID]; ok {
+		m.touchedAt[chatID] = m.now()
+	}
+}
+
+// ExpireIdle очищает флоу без действий дольше ttl и возвращает их для уведомления пользователей
+func (m *Manager) ExpireIdle(ttl time.Duration) []IdleFlow {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deadline := m.now().Add(-ttl)
+	var expired []IdleFlow
+	for chatID, state := range m.userStates {
+		if !m.touchedAt[chatID].Before(deadline) {
+			continue
+		}
+		expired = append(expired, IdleFlow{ChatID: chatID, State: state})
+		delete(m.userStates, chatID)
+		delete(m.userData, chatID)
+		delete(m.touchedAt, chatID)
+	}
+	return expired
+}
+
 // Clear очищает состояние пользователя
 func (m *Manager) Clear(chatID int64) {
 	m.mu.Lock()
@@ -60,6 +131,7 @@ func (m *Manager) Clear(chatID int64) {
 
 	delete(m.userStates, chatID)
 	delete(m.userData, chatID)
+	delete(m.touchedAt, chatID)
 }
 
 // GetBuySubData получает данные флоу покупки подписки
@@ -187,3 +259,165 @@ func (m *Manager) GetMigrateClientData(chatID int64) (*flows.MigrateClientFlowDa
 
 	return flowData, nil
 }
+
+// GetEditSubData получает данные флоу редактирования подписки
+func (m *Manager) GetEditSubData(chatID int64) (*flows.EditSubFlowData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, exists := m.userData[chatID]
+	if !exists {
+		return nil, fmt.Errorf("no data for chat %d", chatID)
+	}
+
+	flowData, ok := data.(*flows.EditSubFlowData)
+	if !ok {
+		return nil, fmt.Errorf("invalid data type for chat %d", chatID)
+	}
+
+	return flowData, nil
+}
+
+// GetCancelSubData получает данные флоу отмены подписки
+func (m *Manager) GetCancelSubData(chatID int64) (*flows.CancelSubFlowData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, exists := m.userData[chatID]
+	if !exists {
+		return nil, fmt.Errorf("no data for chat %d", chatID)
+	}
+
+	flowData, ok := data.(*flows.CancelSubFlowData)
+	if !ok {
+		return nil, fmt.Errorf("invalid data type for chat %d", chatID)
+	}
+
+	return flowData, nil
+}
+
+// GetBulkRenewData получает данные флоу массового продления
+func (m *Manager) GetBulkRenewData(chatID int64) (*flows.BulkRenewFlowData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, exists := m.userData[chatID]
+	if !exists {
+		return nil, fmt.Errorf("no data for chat %d", chatID)
+	}
+
+	flowData, ok := data.(*flows.BulkRenewFlowData)
+	if !ok {
+		return nil, fmt.Errorf("invalid data type for chat %d", chatID)
+	}
+
+	return flowData, nil
+}
+
+// GetTransferSubsData получает данные флоу передачи подписок
+func (m *Manager) GetTransferSubsData(chatID int64) (*flows.TransferSubsFlowData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, exists := m.userData[chatID]
+	if !exists {
+		return nil, fmt.Errorf("no data for chat %d", chatID)
+	}
+
+	flowData, ok := data.(*flows.TransferSubsFlowData)
+	if !ok {
+		return nil, fmt.Errorf("invalid data type for chat %d", chatID)
+	}
+
+	return flowData, nil
+}
+
+// GetEditTariffData получает данные флоу редактирования тарифа
+func (m *Manager) GetEditTariffData(chatID int64) (*flows.EditTariffFlowData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, exists := m.userData[chatID]
+	if !exists {
+		return nil, fmt.Errorf("no data for chat %d", chatID)
+	}
+
+	flowData, ok := data.(*flows.EditTariffFlowData)
+	if !ok {
+		return nil, fmt.Errorf("invalid data type for chat %d", chatID)
+	}
+
+	return flowData, nil
+}
+
+// GetEditServerData получает данные флоу редактирования сервера
+func (m *Manager) GetEditServerData(chatID int64) (*flows.EditServerFlowData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, exists := m.userData[chatID]
+	if !exists {
+		return nil, fmt.Errorf("no data for chat %d", chatID)
+	}
+
+	flowData, ok := data.(*flows.EditServerFlowData)
+	if !ok {
+		return nil, fmt.Errorf("invalid data type for chat %d", chatID)
+	}
+
+	return flowData, nil
+}
+
+// GetBroadcastData получает данные флоу рассылки
+func (m *Manager) GetBroadcastData(chatID int64) (*flows.BroadcastFlowData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, exists := m.userData[chatID]
+	if !exists {
+		return nil, fmt.Errorf("no data for chat %d", chatID)
+	}
+
+	flowData, ok := data.(*flows.BroadcastFlowData)
+	if !ok {
+		return nil, fmt.Errorf("invalid data type for chat %d", chatID)
+	}
+
+	return flowData, nil
+}
+
+// GetSubNoteData получает данные флоу заметки к подписке
+func (m *Manager) GetSubNoteData(chatID int64) (*flows.SubNoteFlowData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, exists := m.userData[chatID]
+	if !exists {
+		return nil, fmt.Errorf("no data for chat %d", chatID)
+	}
+
+	flowData, ok := data.(*flows.SubNoteFlowData)
+	if !ok {
+		return nil, fmt.Errorf("invalid data type for chat %d", chatID)
+	}
+
+	return flowData, nil
+}
+
+// GetWATemplateData получает данные флоу редактирования шаблона WhatsApp
+func (m *Manager) GetWATemplateData(chatID int64) (*flows.WATemplateFlowData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, exists := m.userData[chatID]
+	if !exists {
+		return nil, fmt.Errorf("no data for chat %d", chatID)
+	}
+
+	flowData, ok := data.(*flows.WATemplateFlowData)
+	if !ok {
+		return nil, fmt.Errorf("invalid data type for chat %d", chatID)
+	}
+
+	return flowData, nil
+}
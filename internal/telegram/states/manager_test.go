@@ -0,0 +1,85 @@
+package states
+
+import (
+	"testing"
+	"time"
+
+	"kurut-bot/internal/telegram/flows"
+)
+
+func TestManagerBack(t *testing.T) {
+	const chatID = 1
+	m := NewManager()
+
+	data := &flows.CreateTariffFlowData{}
+	m.SetState(chatID, AdminCreateTariffWaitName, data)
+	if _, ok := m.Back(chatID); ok {
+		t.Fatal("Back() on the first step = true, want false")
+	}
+
+	m.SetState(chatID, AdminCreateTariffWaitPrice, data)
+	m.SetState(chatID, AdminCreateTariffWaitPrice, nil) // повтор шага не записывается
+	m.SetState(chatID, AdminCreateTariffWaitDuration, data)
+	m.SetState(chatID, AdminCreateTariffWaitConfirmation, data)
+
+	for _, want := range []State{AdminCreateTariffWaitDuration, AdminCreateTariffWaitPrice} {
+		got, ok := m.Back(chatID)
+		if !ok || got != want {
+			t.Fatalf("Back() = %q, %v, want %q, true", got, ok, want)
+		}
+		if m.GetState(chatID) != want {
+			t.Fatalf("GetState() = %q, want %q", m.GetState(chatID), want)
+		}
+	}
+
+	// После возврата шаги снова идут вперед, а переход на пройденный шаг обрезает историю
+	m.SetState(chatID, AdminCreateTariffWaitDuration, data)
+	m.SetState(chatID, AdminCreateTariffWaitPrice, data)
+	got, ok := m.Back(chatID)
+	if !ok || got != AdminCreateTariffWaitName {
+		t.Fatalf("Back() = %q, %v, want %q, true", got, ok, AdminCreateTariffWaitName)
+	}
+	if _, ok := m.Back(chatID); ok {
+		t.Fatal("Back() after returning to the first step = true, want false")
+	}
+}
+
+func TestManagerBackNewFlow(t *testing.T) {
+	const chatID = 1
+	m := NewManager()
+
+	m.SetState(chatID, AdminServerWaitName, &flows.AddServerFlowData{})
+	// Новые данные - новый флоу: шаг прошлого флоу в историю не попадает
+	m.SetState(chatID, AdminCreateTariffWaitName, &flows.CreateTariffFlowData{})
+	if _, ok := m.Back(chatID); ok {
+		t.Fatal("Back() after starting a new flow = true, want false")
+	}
+}
+
+func TestManagerExpireIdle(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := NewManager()
+	m.now = func() time.Time { return now }
+
+	m.SetState(1, AdminCreateTariffWaitName, &flows.CreateTariffFlowData{})
+	m.SetState(2, AdminServerWaitName, &flows.AddServerFlowData{})
+
+	now = now.Add(20 * time.Minute)
+	m.Touch(2)
+	m.Touch(3) // без флоу отметка не создается
+
+	now = now.Add(15 * time.Minute)
+	expired := m.ExpireIdle(30 * time.Minute)
+	if len(expired) != 1 || expired[0] != (IdleFlow{ChatID: 1, State: AdminCreateTariffWaitName}) {
+		t.Fatalf("ExpireIdle() = %+v, want only chat 1", expired)
+	}
+	if m.GetState(1) != StateNone || m.GetData(1) != nil {
+		t.Error("expired flow is not cleared")
+	}
+	if m.GetState(2) != AdminServerWaitName {
+		t.Errorf("GetState(2) = %q, want the touched flow to stay", m.GetState(2))
+	}
+	if expired := m.ExpireIdle(30 * time.Minute); len(expired) != 0 {
+		t.Errorf("second ExpireIdle() = %+v, want none", expired)
+	}
+}
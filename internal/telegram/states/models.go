@@ -22,10 +22,14 @@ const (
 
 // admin create sub states
 const (
-	AdminCreateSubWaitClientName State = "acs_wt_client_name"
-	AdminCreateSubWaitReferrer   State = "acs_wt_referrer"
-	AdminCreateSubWaitTariff     State = "acs_wt_tariff"
-	AdminCreateSubWaitPayment    State = "acs_wt_payment"
+	AdminCreateSubWaitClientName   State = "acs_wt_client_name"
+	AdminCreateSubWaitReferrer     State = "acs_wt_referrer"
+	AdminCreateSubWaitTariff       State = "acs_wt_tariff"
+	AdminCreateSubWaitConfirm      State = "acs_wt_confirm"
+	AdminCreateSubWaitPayment      State = "acs_wt_payment"
+	AdminCreateSubWaitQuickConfirm State = "acs_wt_quick_confirm"
+	AdminCreateSubWaitWaitlist     State = "acs_wt_waitlist"
+	AdminCreateSubWaitReceipt      State = "acs_wt_receipt"
 )
 
 // admin disable sub states
@@ -68,6 +72,21 @@ const (
 	AdminServerWaitConfirmation State = "asv_wt_confirmation"
 )
 
+// assistant edit sub states (aes -> assistant edit sub)
+const (
+	AssistantEditSubWaitSelection State = "aes_wt_selection"
+	AssistantEditSubWaitField     State = "aes_wt_field"
+	AssistantEditSubWaitValue     State = "aes_wt_value"
+	AssistantEditSubWaitServer    State = "aes_wt_server"
+	AssistantEditSubWaitConfirm   State = "aes_wt_confirm"
+)
+
+// assistant cancel sub states (acl -> assistant cancel)
+const (
+	AssistantCancelSubWaitSelection State = "acl_wt_selection"
+	AssistantCancelSubWaitConfirm   State = "acl_wt_confirm"
+)
+
 // admin migrate client states (amc -> admin migrate client)
 const (
 	AdminMigrateClientWaitName    State = "amc_wt_name"
@@ -75,3 +94,58 @@ const (
 	AdminMigrateClientWaitTariff  State = "amc_wt_tariff"
 	AdminMigrateClientWaitPayment State = "amc_wt_payment"
 )
+
+// assistant bulk renew states (abr -> assistant bulk renew)
+const (
+	AssistantBulkRenewWaitSelection State = "abr_wt_selection"
+)
+
+// admin transfer subs states (ats -> admin transfer subs)
+const (
+	AdminTransferSubsWaitFrom      State = "ats_wt_from"
+	AdminTransferSubsWaitScope     State = "ats_wt_scope"
+	AdminTransferSubsWaitSelection State = "ats_wt_selection"
+	AdminTransferSubsWaitTo        State = "ats_wt_to"
+	AdminTransferSubsWaitConfirm   State = "ats_wt_confirm"
+)
+
+// admin edit tariff states (aet -> admin edit tariff)
+const (
+	AdminEditTariffWaitField      State = "aet_wt_field"
+	AdminEditTariffWaitName       State = "aet_wt_name"
+	AdminEditTariffWaitPrice      State = "aet_wt_price"
+	AdminEditTariffWaitPriceScope State = "aet_wt_price_scope"
+	AdminEditTariffWaitDuration   State = "aet_wt_duration"
+	AdminEditTariffWaitTraffic    State = "aet_wt_traffic"
+	AdminEditTariffWaitCluster    State = "aet_wt_cluster"
+	AdminEditTariffWaitReminders  State = "aet_wt_reminders"
+	AdminEditTariffWaitPromo      State = "aet_wt_promo"
+	AdminEditTariffWaitFallback   State = "aet_wt_fallback"
+)
+
+// admin edit server states (aesv -> admin edit server)
+const (
+	AdminEditServerWaitField        State = "aesv_wt_field"
+	AdminEditServerWaitName         State = "aesv_wt_name"
+	AdminEditServerWaitURL          State = "aesv_wt_url"
+	AdminEditServerWaitPassword     State = "aesv_wt_password"
+	AdminEditServerWaitMaxUsers     State = "aesv_wt_max_users"
+	AdminEditServerWaitCurrentUsers State = "aesv_wt_current_users"
+	AdminEditServerWaitCluster      State = "aesv_wt_cluster"
+)
+
+// admin broadcast states (abc -> admin broadcast)
+const (
+	AdminBroadcastWaitText    State = "abc_wt_text"
+	AdminBroadcastWaitConfirm State = "abc_wt_confirm"
+)
+
+// assistant subscription note states (asn -> assistant sub note)
+const (
+	AssistantSubNoteWaitText State = "asn_wt_text"
+)
+
+// admin whatsapp template states (awt -> admin whatsapp template)
+const (
+	AdminWATemplateWaitText State = "awt_wt_text"
+)
@@ -0,0 +1,185 @@
+package telegram
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/config"
+	"kurut-bot/internal/stories/websessions"
+)
+
+const (
+	// loginWidgetMaxAge - сколько действуют данные Telegram Login Widget после авторизации
+	loginWidgetMaxAge = time.Hour
+
+	webSessionCookie = "kurut_session"
+)
+
+var errLoginHash = errors.New("invalid login widget hash")
+
+// WebSessionService - сессии веб-админки
+type WebSessionService interface {
+	Login(ctx context.Context, telegramID int64) (*websessions.Session, string, error)
+	Authenticate(ctx context.Context, token string) (*websessions.Session, error)
+	Logout(ctx context.Context, token string) error
+}
+
+// ValidateLoginWidget проверяет подпись данных Telegram Login Widget и возвращает Telegram ID пользователя.
+// В отличие от initData Mini App ключ подписи - SHA256 от токена бота.
+// См. https://core.telegram.org/widgets/login#checking-authorization
+func ValidateLoginWidget(values url.Values, botToken string, now time.Time) (int64, error) {
+	hash := values.Get("hash")
+	if hash == "" {
+		return 0, errLoginHash
+	}
+
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		if key == "hash" {
+			continue
+		}
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+	dataCheckString := strings.Join(pairs, "\n")
+
+	secret := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(dataCheckString))
+	if !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(hash)) {
+		return 0, errLoginHash
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse auth_date: %w", err)
+	}
+	if now.Sub(time.Unix(authDate, 0)) > loginWidgetMaxAge {
+		return 0, errInitDataExpired
+	}
+
+	telegramID, err := strconv.ParseInt(values.Get("id"), 10, 64)
+	if err != nil || telegramID == 0 {
+		return 0, errors.New("user id is missing")
+	}
+
+	return telegramID, nil
+}
+
+type webSessionResponse struct {
+	TelegramID int64     `json:"telegram_id"`
+	Role       string    `json:"role"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func newWebSessionResponse(session *websessions.Session) webSessionResponse {
+	return webSessionResponse{
+		TelegramID: session.TelegramID,
+		Role:       string(session.Role),
+		ExpiresAt:  session.ExpiresAt,
+	}
+}
+
+// TelegramLoginHandler обрабатывает GET /api/auth/telegram - адрес data-auth-url виджета.
+// Создает сессию для админов и ассистентов бота и ставит cookie
+func TelegramLoginHandler(sessions WebSessionService, botToken string, cfg config.WebAdminConfig, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		telegramID, err := ValidateLoginWidget(r.URL.Query(), botToken, time.Now())
+		if err != nil {
+			writeJSONError(w, http.StatusUnauthorized, "invalid login data")
+			return
+		}
+
+		session, token, err := sessions.Login(r.Context(), telegramID)
+		if errors.Is(err, websessions.ErrNoRole) {
+			logger.Warn("Web login without role", "telegram_id", telegramID)
+			writeJSONError(w, http.StatusForbidden, "forbidden")
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to create web session", "error", err, "telegram_id", telegramID)
+			writeJSONError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		logger.Info("Web admin login",
+			"audit", true,
+			"telegram_id", telegramID,
+			"role", session.Role,
+		)
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     webSessionCookie,
+			Value:    token,
+			Path:     "/",
+			Expires:  session.ExpiresAt,
+			HttpOnly: true,
+			Secure:   cfg.CookieSecure,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		if cfg.RedirectURL != "" {
+			http.Redirect(w, r, cfg.RedirectURL, http.StatusSeeOther)
+			return
+		}
+		writeJSON(w, http.StatusOK, newWebSessionResponse(session))
+	}
+}
+
+// WebSessionHandler обрабатывает GET /api/auth/session - текущая сессия по cookie
+func WebSessionHandler(sessions WebSessionService, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session, err := sessions.Authenticate(r.Context(), sessionToken(r))
+		if err != nil {
+			logger.Error("Failed to authenticate web session", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if session == nil {
+			writeJSONError(w, http.StatusUnauthorized, "no session")
+			return
+		}
+		writeJSON(w, http.StatusOK, newWebSessionResponse(session))
+	}
+}
+
+// WebLogoutHandler обрабатывает POST /api/auth/logout - удаляет сессию и cookie
+func WebLogoutHandler(sessions WebSessionService, cfg config.WebAdminConfig, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := sessions.Logout(r.Context(), sessionToken(r)); err != nil {
+			logger.Error("Failed to delete web session", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     webSessionCookie,
+			Value:    "",
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: true,
+			Secure:   cfg.CookieSecure,
+			SameSite: http.SameSiteLaxMode,
+		})
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func sessionToken(r *http.Request) string {
+	cookie, err := r.Cookie(webSessionCookie)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
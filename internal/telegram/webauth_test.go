@@ -0,0 +1,79 @@
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signLoginWidget(t *testing.T, botToken string, values url.Values) url.Values {
+	t.Helper()
+
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+
+	secret := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+
+	values.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+	return values
+}
+
+func TestValidateLoginWidget(t *testing.T) {
+	const botToken = "123:token"
+	now := time.Unix(1_700_000_000, 0)
+
+	loginData := func(authDate time.Time) url.Values {
+		values := url.Values{}
+		values.Set("id", "42")
+		values.Set("first_name", "Test")
+		values.Set("auth_date", strconv.FormatInt(authDate.Unix(), 10))
+		return signLoginWidget(t, botToken, values)
+	}
+
+	tampered := loginData(now.Add(-time.Minute))
+	tampered.Set("id", "43")
+
+	// Данные Mini App подписаны другим ключом и для виджета не подходят
+	miniApp, _ := url.ParseQuery(signInitData(t, botToken, url.Values{
+		"id":        {"42"},
+		"auth_date": {strconv.FormatInt(now.Unix(), 10)},
+	}))
+
+	tests := []struct {
+		name    string
+		values  url.Values
+		token   string
+		wantID  int64
+		wantErr bool
+	}{
+		{"valid", loginData(now.Add(-time.Minute)), botToken, 42, false},
+		{"wrong token", loginData(now.Add(-time.Minute)), "other", 0, true},
+		{"tampered", tampered, botToken, 0, true},
+		{"expired", loginData(now.Add(-2 * time.Hour)), botToken, 0, true},
+		{"mini app init data", miniApp, botToken, 0, true},
+		{"no hash", url.Values{"id": {"42"}}, botToken, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := ValidateLoginWidget(tt.values, tt.token, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateLoginWidget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if id != tt.wantID {
+				t.Errorf("ValidateLoginWidget() = %d, want %d", id, tt.wantID)
+			}
+		})
+	}
+}
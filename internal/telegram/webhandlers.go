@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"context"
 	"crypto/sha256"
 	"embed"
 	"encoding/base64"
@@ -10,11 +11,16 @@ import (
 	"os"
 	"sync"
 	"time"
+
+	"kurut-bot/internal/telegram/messages"
 )
 
 //go:embed templates/*
 var templatesFS embed.FS
 
+// ConfigStore держит конфиги WireGuard и QR-коды для страницы подключения только в памяти, 24 часа.
+// Конфиги и ключи клиентов не сохраняются ни в БД, ни во внешнем хранилище: если это понадобится,
+// их нужно шифровать ключом подписки от мастер-ключа, а не класть открытым текстом
 type ConfigStore struct {
 	mu      sync.RWMutex
 	configs map[string]*storedConfig
@@ -92,7 +98,12 @@ func (cs *ConfigStore) cleanupExpired() {
 	}
 }
 
-func WGConnectHandler(store *ConfigStore) http.HandlerFunc {
+// ConfigOpenRecorder учитывает открытия страницы подключения
+type ConfigOpenRecorder interface {
+	RecordConfigOpen(ctx context.Context, configID string, userAgent string) error
+}
+
+func WGConnectHandler(store *ConfigStore, recorder ConfigOpenRecorder) http.HandlerFunc {
 	tmpl := template.Must(template.ParseFS(templatesFS, "templates/wg_connect.html"))
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -108,6 +119,9 @@ func WGConnectHandler(store *ConfigStore) http.HandlerFunc {
 			return
 		}
 
+		// Статистика не должна мешать клиенту получить конфиг
+		_ = recorder.RecordConfigOpen(r.Context(), configID, r.UserAgent())
+
 		encodedConfig := base64.StdEncoding.EncodeToString([]byte(config))
 
 		data := map[string]interface{}{
@@ -115,6 +129,7 @@ func WGConnectHandler(store *ConfigStore) http.HandlerFunc {
 			"EncodedConfig": encodedConfig,
 			"QRCode":        qrCode,
 			"ConfigID":      configID,
+			"Brand":         messages.CurrentBrand(),
 		}
 
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -0,0 +1,13 @@
+package archival
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Storage provides subscription archival operations
+	Storage interface {
+		ArchiveExpiredSubscriptions(ctx context.Context, expiredBefore time.Time) (int64, error)
+	}
+)
@@ -0,0 +1,81 @@
+package archival
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// archiveAfterMonths - через сколько месяцев после истечения подписка уходит в архив
+const archiveAfterMonths = 6
+
+// Worker moves long-expired subscriptions to archived status
+type Worker struct {
+	storage Storage
+	logger  *slog.Logger
+	cron    *cron.Cron
+}
+
+// NewWorker creates a new archival worker
+func NewWorker(storage Storage, logger *slog.Logger) *Worker {
+	return &Worker{
+		storage: storage,
+		logger:  logger,
+		cron:    cron.New(),
+	}
+}
+
+// Name returns the worker name
+func (w *Worker) Name() string {
+	return "archival"
+}
+
+// Start starts the archival worker
+func (w *Worker) Start() error {
+	// Runs daily at 04:00
+	_, err := w.cron.AddFunc("0 4 * * *", func() {
+		defer func() {
+			if r := recover(); r != nil {
+				w.logger.Error("Panic in archival worker", "panic", r)
+			}
+		}()
+		ctx := context.Background()
+		if err := w.run(ctx); err != nil {
+			w.logger.Error("Archival worker failed", "error", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule archival worker: %w", err)
+	}
+
+	w.cron.Start()
+	return nil
+}
+
+// Stop stops the worker
+func (w *Worker) Stop() {
+	w.logger.Info("Stopping archival worker")
+	w.cron.Stop()
+}
+
+// RunNow runs the worker immediately (for manual testing)
+func (w *Worker) RunNow(ctx context.Context) error {
+	w.logger.Info("Manual run of archival worker")
+	return w.run(ctx)
+}
+
+// run archives subscriptions expired more than archiveAfterMonths ago
+func (w *Worker) run(ctx context.Context) error {
+	expiredBefore := time.Now().UTC().AddDate(0, -archiveAfterMonths, 0)
+
+	archived, err := w.storage.ArchiveExpiredSubscriptions(ctx, expiredBefore)
+	if err != nil {
+		return fmt.Errorf("archive expired subscriptions: %w", err)
+	}
+
+	w.logger.Info("Archived long-expired subscriptions", "count", archived, "expired_before", expiredBefore)
+	return nil
+}
@@ -0,0 +1,179 @@
+package broadcast
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// sendInterval - пауза между сообщениями рассылки: ~20 сообщений в секунду,
+	// чтобы оставить запас до лимита Telegram (30/с) для обычной работы бота
+	sendInterval = 50 * time.Millisecond
+	// queueSize - сколько рассылок может ждать в очереди
+	queueSize = 10
+	// maxReportedFailures - сколько недоставленных получателей перечислять в отчете
+	maxReportedFailures = 30
+)
+
+// Job - рассылка одного текста списку получателей; отчет уходит в ReportChatID
+type Job struct {
+	Text         string
+	Recipients   []int64
+	ReportChatID int64
+	AdminID      int64
+}
+
+// failure - получатель, которому не удалось доставить сообщение
+type failure struct {
+	chatID int64
+	reason string
+}
+
+// Worker отправляет рассылки из очереди по одной, с паузой между сообщениями
+type Worker struct {
+	telegramBot TelegramBot
+	interval    time.Duration
+	queue       chan Job
+	logger      *slog.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWorker creates a new broadcast worker
+func NewWorker(telegramBot TelegramBot, logger *slog.Logger) *Worker {
+	return &Worker{
+		telegramBot: telegramBot,
+		interval:    sendInterval,
+		queue:       make(chan Job, queueSize),
+		logger:      logger,
+	}
+}
+
+// Name returns the worker name
+func (w *Worker) Name() string {
+	return "broadcast"
+}
+
+// Start запускает обработку очереди рассылок
+func (w *Worker) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case job := <-w.queue:
+				w.run(ctx, job)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop останавливает рассылку; неотправленные сообщения текущей рассылки отбрасываются
+func (w *Worker) Stop() {
+	w.logger.Info("Stopping broadcast worker")
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+}
+
+// Enqueue ставит рассылку в очередь; false - очередь заполнена
+func (w *Worker) Enqueue(job Job) bool {
+	select {
+	case w.queue <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// run отправляет рассылку и сообщает админу итог
+func (w *Worker) run(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.logger.Error("Panic in broadcast worker", "panic", r)
+		}
+	}()
+
+	progress, err := telegram.StartProgress(w.telegramBot, job.ReportChatID, "Рассылка", len(job.Recipients))
+	if err != nil {
+		w.logger.Warn("Failed to send broadcast progress", "error", err, "chat_id", job.ReportChatID)
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	var sent int
+	var failures []failure
+	for i, chatID := range job.Recipients {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				w.logger.Warn("Broadcast interrupted", "sent", sent, "failed", len(failures), "total", len(job.Recipients))
+				return
+			case <-ticker.C:
+			}
+		}
+
+		if _, err := w.telegramBot.Send(tgbotapi.NewMessage(chatID, job.Text)); err != nil {
+			failures = append(failures, failure{chatID: chatID, reason: err.Error()})
+			w.logger.Warn("Failed to deliver broadcast message", "error", err, "chat_id", chatID)
+		} else {
+			sent++
+		}
+
+		if progress != nil {
+			progress.Update(i + 1)
+		}
+	}
+
+	w.logger.Info("Broadcast finished",
+		"audit", true,
+		"admin_telegram_id", job.AdminID,
+		"sent", sent,
+		"failed", len(failures),
+	)
+
+	report := formatReport(sent, len(job.Recipients), failures)
+	if progress != nil && progress.Finish(report) == nil {
+		return
+	}
+	if _, err := w.telegramBot.Send(tgbotapi.NewMessage(job.ReportChatID, report)); err != nil {
+		w.logger.Error("Failed to send broadcast report", "error", err, "chat_id", job.ReportChatID)
+	}
+}
+
+// formatReport собирает отчет о рассылке со статусом по каждому недоставленному получателю
+func formatReport(sent, total int, failures []failure) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "✅ Рассылка завершена\n\nДоставлено: %d из %d", sent, total)
+	if len(failures) == 0 {
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\nНе доставлено: %d\n", len(failures))
+	for i, f := range failures {
+		if i == maxReportedFailures {
+			fmt.Fprintf(&b, "\n...и еще %d", len(failures)-maxReportedFailures)
+			break
+		}
+		fmt.Fprintf(&b, "\n• %d — %s", f.chatID, f.reason)
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package broadcast
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type fakeBot struct {
+	mu      sync.Mutex
+	sent    map[int64][]string
+	blocked map[int64]bool
+	report  chan string
+}
+
+func (b *fakeBot) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	// Итоговый отчет заменяет сообщение с прогрессом
+	if edit, ok := c.(tgbotapi.EditMessageTextConfig); ok {
+		if strings.HasPrefix(edit.Text, "✅ Рассылка завершена") {
+			b.report <- edit.Text
+		}
+		return tgbotapi.Message{}, nil
+	}
+
+	msg := c.(tgbotapi.MessageConfig)
+	if b.blocked[msg.ChatID] {
+		return tgbotapi.Message{}, errors.New("Forbidden: bot was blocked by the user")
+	}
+
+	b.mu.Lock()
+	b.sent[msg.ChatID] = append(b.sent[msg.ChatID], msg.Text)
+	b.mu.Unlock()
+
+	return tgbotapi.Message{MessageID: 1}, nil
+}
+
+func (b *fakeBot) Request(tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func TestWorkerSendsQueuedJobs(t *testing.T) {
+	bot := &fakeBot{
+		sent:    map[int64][]string{},
+		blocked: map[int64]bool{3: true},
+		report:  make(chan string, 2),
+	}
+	w := NewWorker(bot, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	w.interval = time.Millisecond
+	if err := w.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	if !w.Enqueue(Job{Text: "Плановые работы", Recipients: []int64{1, 2, 3}, ReportChatID: 100}) ||
+		!w.Enqueue(Job{Text: "Новые цены", Recipients: []int64{1}, ReportChatID: 100}) {
+		t.Fatal("Enqueue() = false, want queued")
+	}
+
+	first := <-bot.report
+	if !strings.Contains(first, "Доставлено: 2 из 3") || !strings.Contains(first, "Не доставлено: 1") {
+		t.Errorf("first report = %q, want 2 of 3 delivered", first)
+	}
+	if !strings.Contains(first, "• 3 — Forbidden: bot was blocked by the user") {
+		t.Errorf("first report = %q, want status of recipient 3", first)
+	}
+	<-bot.report
+
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if got := bot.sent[1]; len(got) != 2 || got[0] != "Плановые работы" || got[1] != "Новые цены" {
+		t.Errorf("recipient 1 got %v, want both broadcasts in order", got)
+	}
+	if len(bot.sent[2]) != 1 {
+		t.Errorf("recipient 2 got %v, want one message", bot.sent[2])
+	}
+}
+
+func TestFormatReportTruncatesFailures(t *testing.T) {
+	failures := make([]failure, maxReportedFailures+5)
+	for i := range failures {
+		failures[i] = failure{chatID: int64(i + 1), reason: "chat not found"}
+	}
+
+	report := formatReport(10, 10+len(failures), failures)
+	if got := strings.Count(report, "• "); got != maxReportedFailures {
+		t.Errorf("listed failures = %d, want %d", got, maxReportedFailures)
+	}
+	if !strings.HasSuffix(report, "...и еще 5") {
+		t.Errorf("report = %q, want tail with remaining count", report)
+	}
+}
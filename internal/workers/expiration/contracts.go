@@ -2,6 +2,7 @@ package expiration
 
 import (
 	"context"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
@@ -9,16 +10,27 @@ import (
 	"kurut-bot/internal/stories/submessages"
 	"kurut-bot/internal/stories/subs"
 	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/stories/vacations"
+	"kurut-bot/internal/stories/watemplates"
 )
 
 type (
 	// Storage provides database operations
 	Storage interface {
 		ListExpiredSubscriptions(ctx context.Context) ([]*subs.Subscription, error)
+		ListEndedPauses(ctx context.Context) ([]*subs.Subscription, error)
+		ResumeSubscription(ctx context.Context, subscriptionID int64, expiresAt time.Time) (bool, error)
 		ListExpiringTodayGroupedByAssistant(ctx context.Context) (map[int64][]*subs.Subscription, error)
-		ListExpiringByAssistantAndDays(ctx context.Context, daysUntilExpiry int) (map[int64][]*subs.Subscription, error)
+		ListExpiringByAssistantAndDaysFrom(ctx context.Context, from time.Time, daysUntilExpiry int) (map[int64][]*subs.Subscription, error)
+		ListClientPaymentTimes(ctx context.Context, since time.Time) (map[string][]time.Time, error)
 		ListOverdueSubscriptionsGroupedByAssistant(ctx context.Context) (map[int64][]*subs.Subscription, error)
 		UpdateSubscription(ctx context.Context, criteria subs.GetCriteria, params subs.UpdateParams) (*subs.Subscription, error)
+		ListTariffs(ctx context.Context, criteria tariffs.ListCriteria) ([]*tariffs.Tariff, error)
+		ListActiveVacations(ctx context.Context, at time.Time) ([]*vacations.Vacation, error)
+		ListWhatsAppTemplates(ctx context.Context) ([]*watemplates.Template, error)
+		ListTaskPins(ctx context.Context) (map[int64]int, error)
+		SaveTaskPin(ctx context.Context, chatID int64, messageID int) error
+		DeleteTaskPin(ctx context.Context, chatID int64) error
 	}
 
 	// NotificationService provides notification functionality
@@ -39,9 +51,15 @@ type (
 
 	TelegramBot interface {
 		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+		Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
 	}
 
 	TariffService interface {
 		GetTariff(ctx context.Context, criteria tariffs.GetCriteria) (*tariffs.Tariff, error)
 	}
+
+	// AdminChats - куда слать служебные уведомления: админская группа или текущие админы
+	AdminChats interface {
+		AdminChatIDs() []int64
+	}
 )
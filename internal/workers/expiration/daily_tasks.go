@@ -0,0 +1,142 @@
+package expiration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/subs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// dailyTasks - сколько подписок ассистенту нужно обработать за день
+type dailyTasks struct {
+	ExpiringToday int
+	ExpiringIn3   int
+	Overdue       int
+}
+
+// pinDailyTasks отправляет каждому ассистенту с задачами сообщение «задачи на сегодня», закрепляет его
+// и открепляет вчерашнее. У ассистентов без задач вчерашнее сообщение просто открепляется
+func (w *Worker) pinDailyTasks(ctx context.Context) error {
+	tasks, err := w.collectDailyTasks(ctx)
+	if err != nil {
+		return err
+	}
+	pins, err := w.storage.ListTaskPins(ctx)
+	if err != nil {
+		return fmt.Errorf("list task pins: %w", err)
+	}
+
+	now := time.Now()
+	for assistantID, t := range tasks {
+		text, keyboard := formatDailyTasks(t, now)
+		msg := tgbotapi.NewMessage(assistantID, text)
+		msg.ParseMode = "Markdown"
+		msg.ReplyMarkup = keyboard
+		sent, err := w.telegramBot.Send(msg)
+		if err != nil {
+			if !telegram.IsUnreachable(err) {
+				w.logger.Error("Failed to send daily tasks", "assistant_id", assistantID, "error", err)
+			}
+			continue
+		}
+
+		pin := tgbotapi.PinChatMessageConfig{ChatID: assistantID, MessageID: sent.MessageID, DisableNotification: true}
+		if _, err := w.telegramBot.Request(pin); err != nil {
+			w.logger.Error("Failed to pin daily tasks", "assistant_id", assistantID, "error", err)
+			continue
+		}
+		if oldMessageID, ok := pins[assistantID]; ok {
+			w.unpinDailyTasks(assistantID, oldMessageID)
+		}
+		if err := w.storage.SaveTaskPin(ctx, assistantID, sent.MessageID); err != nil {
+			w.logger.Error("Failed to save task pin", "assistant_id", assistantID, "error", err)
+		}
+	}
+
+	for chatID, oldMessageID := range pins {
+		if _, ok := tasks[chatID]; ok {
+			continue
+		}
+		w.unpinDailyTasks(chatID, oldMessageID)
+		if err := w.storage.DeleteTaskPin(ctx, chatID); err != nil {
+			w.logger.Error("Failed to delete task pin", "chat_id", chatID, "error", err)
+		}
+	}
+
+	w.logger.Info("Daily tasks pinned", "assistants_count", len(tasks))
+	return nil
+}
+
+// collectDailyTasks считает по каждому ассистенту подписки, истекающие сегодня и через 3 дня, и просроченные.
+// Окна истечения считаются от часа по умолчанию, как и напоминания
+func (w *Worker) collectDailyTasks(ctx context.Context) (map[int64]dailyTasks, error) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), w.schedule.DefaultHour, 0, 0, 0, now.Location())
+
+	today, err := w.storage.ListExpiringByAssistantAndDaysFrom(ctx, from, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list expiring today: %w", err)
+	}
+	in3, err := w.storage.ListExpiringByAssistantAndDaysFrom(ctx, from, 3)
+	if err != nil {
+		return nil, fmt.Errorf("list expiring in 3 days: %w", err)
+	}
+	overdue, err := w.storage.ListOverdueSubscriptionsGroupedByAssistant(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list overdue: %w", err)
+	}
+
+	tasks := make(map[int64]dailyTasks)
+	add := func(grouped map[int64][]*subs.Subscription, apply func(t *dailyTasks, count int)) {
+		for assistantID, subscriptions := range grouped {
+			if len(subscriptions) == 0 {
+				continue
+			}
+			t := tasks[assistantID]
+			apply(&t, len(subscriptions))
+			tasks[assistantID] = t
+		}
+	}
+	add(today, func(t *dailyTasks, count int) { t.ExpiringToday = count })
+	add(in3, func(t *dailyTasks, count int) { t.ExpiringIn3 = count })
+	add(overdue, func(t *dailyTasks, count int) { t.Overdue = count })
+	return tasks, nil
+}
+
+// unpinDailyTasks открепляет вчерашнее сообщение; его могли уже открепить или удалить вручную
+func (w *Worker) unpinDailyTasks(chatID int64, messageID int) {
+	if _, err := w.telegramBot.Request(tgbotapi.UnpinChatMessageConfig{ChatID: chatID, MessageID: messageID}); err != nil {
+		w.logger.Warn("Failed to unpin previous daily tasks", "chat_id", chatID, "message_id", messageID, "error", err)
+	}
+}
+
+// formatDailyTasks - текст сообщения «задачи на сегодня» и кнопки перехода к спискам (tasks:*)
+func formatDailyTasks(t dailyTasks, now time.Time) (string, tgbotapi.InlineKeyboardMarkup) {
+	text := fmt.Sprintf("📌 *Задачи на сегодня, %s*\n\n"+
+		"🔔 Истекают сегодня: %d\n"+
+		"⏰ Истекают через 3 дня: %d\n"+
+		"⚠️ Просрочены: %d",
+		now.Format("02.01.2006"), t.ExpiringToday, t.ExpiringIn3, t.Overdue)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if t.ExpiringToday > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🔔 Сегодня (%d)", t.ExpiringToday), "tasks:expiring"),
+		))
+	}
+	if t.ExpiringIn3 > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("⏰ Через 3 дня (%d)", t.ExpiringIn3), "tasks:exp3"),
+		))
+	}
+	if t.Overdue > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("⚠️ Просроченные (%d)", t.Overdue), "tasks:overdue"),
+		))
+	}
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
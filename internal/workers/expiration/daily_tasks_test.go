@@ -0,0 +1,29 @@
+package expiration
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatDailyTasks(t *testing.T) {
+	now := time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC)
+
+	text, keyboard := formatDailyTasks(dailyTasks{ExpiringToday: 2, Overdue: 5}, now)
+	for _, want := range []string{"16.10.2026", "Истекают сегодня: 2", "Истекают через 3 дня: 0", "Просрочены: 5"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("formatDailyTasks() text misses %q:\n%s", want, text)
+		}
+	}
+
+	// Кнопки только для непустых списков
+	var callbacks []string
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			callbacks = append(callbacks, *button.CallbackData)
+		}
+	}
+	if strings.Join(callbacks, ",") != "tasks:expiring,tasks:overdue" {
+		t.Errorf("formatDailyTasks() buttons = %v, want tasks:expiring and tasks:overdue", callbacks)
+	}
+}
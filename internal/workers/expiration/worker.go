@@ -4,11 +4,19 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
+	"time"
 
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/peakhour"
 	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/stories/vacations"
+	"kurut-bot/internal/telegram/messages"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/robfig/cron/v3"
+	"github.com/samber/lo"
 )
 
 // Worker handles sending notifications about expiring subscriptions
@@ -16,6 +24,8 @@ type Worker struct {
 	storage             Storage
 	telegramBot         TelegramBot
 	notificationService NotificationService
+	adminChats          AdminChats
+	schedule            peakhour.Schedule
 	logger              *slog.Logger
 	cron                *cron.Cron
 }
@@ -25,12 +35,16 @@ func NewWorker(
 	storage Storage,
 	telegramBot TelegramBot,
 	notificationService NotificationService,
+	adminChats AdminChats,
+	schedule peakhour.Schedule,
 	logger *slog.Logger,
 ) *Worker {
 	return &Worker{
 		storage:             storage,
 		telegramBot:         telegramBot,
 		notificationService: notificationService,
+		adminChats:          adminChats,
+		schedule:            schedule,
 		logger:              logger,
 		cron:                cron.New(),
 	}
@@ -43,16 +57,26 @@ func (w *Worker) Name() string {
 
 // Start starts the expiration worker
 func (w *Worker) Start() error {
-	// Runs daily at 07:00
-	_, err := w.cron.AddFunc("0 7 * * *", func() {
+	if w.schedule.DefaultHour < 0 || w.schedule.DefaultHour > 23 {
+		return fmt.Errorf("invalid reminder default hour: %d", w.schedule.DefaultHour)
+	}
+
+	// Runs daily at the default hour; with peak hours - every hour, чтобы отправить
+	// напоминания клиентам в их час оплат
+	spec := fmt.Sprintf("0 %d * * *", w.schedule.DefaultHour)
+	if w.schedule.PeakHours {
+		spec = "0 * * * *"
+	}
+	_, err := w.cron.AddFunc(spec, func() {
 		defer func() {
 			if r := recover(); r != nil {
 				w.logger.Error("Panic in expiration worker", "panic", r)
 			}
 		}()
 		ctx := context.Background()
-		w.logger.Info("Running expiration worker")
-		if err := w.run(ctx); err != nil {
+		hour := time.Now().Hour()
+		w.logger.Info("Running expiration worker", "hour", hour)
+		if err := w.run(ctx, &hour); err != nil {
 			w.logger.Error("Expiration worker failed", "error", err)
 		}
 	})
@@ -73,25 +97,43 @@ func (w *Worker) Stop() {
 // RunNow runs the worker immediately (for manual testing)
 func (w *Worker) RunNow(ctx context.Context) error {
 	w.logger.Info("Manual run of expiration worker")
-	return w.run(ctx)
+	return w.run(ctx, nil)
 }
 
-// run executes the expiration logic
-func (w *Worker) run(ctx context.Context) error {
+// run executes the expiration logic. hour - час запуска по расписанию: в час по умолчанию выполняется
+// вся обработка, в остальные часы - только напоминания клиентам с этим часом оплат.
+// nil - ручной запуск: напоминания всем клиентам сразу
+func (w *Worker) run(ctx context.Context, hour *int) error {
 	w.logger.Info("Starting expiration worker execution")
 
-	// 1. Уведомления за 3 дня
-	if err := w.sendExpiringNotifications(ctx, 3); err != nil {
-		w.logger.Error("Failed to send 3-day notifications", "error", err)
+	// Шаблоны могли измениться через /templates в процессе бота
+	if err := w.loadWhatsAppTemplates(ctx); err != nil {
+		w.logger.Error("Failed to load WhatsApp templates, using previous texts", "error", err)
 	}
 
-	// 2. Уведомления в день истечения
-	if err := w.sendExpiringNotifications(ctx, 0); err != nil {
-		w.logger.Error("Failed to send expiring today notifications", "error", err)
+	// Ассистенты в отпуске - их уведомления уходят замене
+	onVacation, err := w.loadActiveVacations(ctx)
+	if err != nil {
+		w.logger.Error("Failed to load assistant vacations, notifying assistants directly", "error", err)
+	}
+
+	if hour != nil && *hour != w.schedule.DefaultHour {
+		return w.sendScheduledReminders(ctx, hour, onVacation)
+	}
+
+	// 0. Снять с паузы подписки, у которых закончилась пауза: их новая дата окончания
+	// должна попасть в уведомления ниже
+	if err := w.resumeEndedPauses(ctx); err != nil {
+		w.logger.Error("Failed to resume paused subscriptions", "error", err)
+	}
+
+	// 1-2. Уведомления об истекающих подписках по расписанию тарифов
+	if err := w.sendScheduledReminders(ctx, hour, onVacation); err != nil {
+		w.logger.Error("Failed to send expiring notifications", "error", err)
 	}
 
 	// 3. Уведомления о просроченных
-	if err := w.sendOverdueNotifications(ctx); err != nil {
+	if err := w.sendOverdueNotifications(ctx, onVacation); err != nil {
 		w.logger.Error("Failed to send overdue notifications", "error", err)
 	}
 
@@ -100,27 +142,177 @@ func (w *Worker) run(ctx context.Context) error {
 		w.logger.Error("Failed to mark expired subscriptions", "error", err)
 	}
 
+	// 5. Закрепить у ассистентов задачи на сегодня вместо вчерашних
+	if err := w.pinDailyTasks(ctx); err != nil {
+		w.logger.Error("Failed to pin daily tasks", "error", err)
+	}
+
 	w.logger.Info("Expiration worker execution completed")
 	return nil
 }
 
-// sendExpiringNotifications отправляет уведомления за N дней до истечения
-func (w *Worker) sendExpiringNotifications(ctx context.Context, daysUntilExpiry int) error {
-	expiringByAssistant, err := w.storage.ListExpiringByAssistantAndDays(ctx, daysUntilExpiry)
+// sendScheduledReminders отправляет напоминания об истекающих подписках клиентов, чей час напоминаний - hour
+// (nil - всех клиентов). Окна истечения считаются от часа по умолчанию, поэтому подписка
+// попадает ровно в один запуск за день, в какой бы час ни было ее напоминание
+func (w *Worker) sendScheduledReminders(ctx context.Context, hour *int, onVacation map[int64]*vacations.Vacation) error {
+	schedules, err := w.loadReminderSchedules(ctx)
+	if err != nil {
+		w.logger.Error("Failed to load tariff reminder schedules, using default", "error", err)
+	}
+
+	var clientHours map[string]int
+	if hour != nil && w.schedule.PeakHours {
+		clientHours, err = w.loadClientHours(ctx)
+		if err != nil {
+			// Без истории оплат все напоминания уходят в час по умолчанию
+			w.logger.Error("Failed to load client payment hours, using default hour", "error", err)
+		}
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), w.schedule.DefaultHour, 0, 0, 0, now.Location())
+	if hour == nil {
+		from = now
+	}
+
+	inHour := func(sub *subs.Subscription) bool {
+		return hour == nil || w.schedule.HourFor(clientHours, sub.ClientWhatsApp) == *hour
+	}
+	for _, days := range reminderDaysUnion(schedules) {
+		if err := w.sendExpiringNotifications(ctx, from, days, schedules, inHour, onVacation); err != nil {
+			w.logger.Error("Failed to send expiring notifications", "days_until_expiry", days, "error", err)
+		}
+	}
+	return nil
+}
+
+// loadClientHours возвращает час, в который каждый клиент обычно оплачивает подписку
+func (w *Worker) loadClientHours(ctx context.Context) (map[string]int, error) {
+	paidAt, err := w.storage.ListClientPaymentTimes(ctx, time.Now().Add(-peakhour.Lookback))
+	if err != nil {
+		return nil, fmt.Errorf("list client payment times: %w", err)
+	}
+	return peakhour.PreferredHours(paidAt, time.Local), nil
+}
+
+// loadActiveVacations возвращает текущие отпуска по Telegram ID ассистента
+func (w *Worker) loadActiveVacations(ctx context.Context) (map[int64]*vacations.Vacation, error) {
+	list, err := w.storage.ListActiveVacations(ctx, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list active vacations: %w", err)
+	}
+
+	result := make(map[int64]*vacations.Vacation, len(list))
+	for _, v := range list {
+		result[v.AssistantTelegramID] = v
+	}
+	return result, nil
+}
+
+// loadWhatsAppTemplates подгружает тексты сообщений клиентам, измененные админом
+func (w *Worker) loadWhatsAppTemplates(ctx context.Context) error {
+	templates, err := w.storage.ListWhatsAppTemplates(ctx)
+	if err != nil {
+		return fmt.Errorf("list whatsapp templates: %w", err)
+	}
+	messages.SetWhatsAppOverrides(templates)
+	return nil
+}
+
+// recipientsFor возвращает чаты для уведомлений ассистента и пометку о замене (пустую, если ассистент на месте).
+// Если замена тоже в отпуске или не назначена - уведомления уходят админам
+func (w *Worker) recipientsFor(assistantTelegramID int64, onVacation map[int64]*vacations.Vacation) ([]int64, string) {
+	vacation, ok := onVacation[assistantTelegramID]
+	if !ok {
+		return []int64{assistantTelegramID}, ""
+	}
+
+	note := fmt.Sprintf("🏖 *Замена:* ассистент `%d` в отпуске до %s\n\n",
+		assistantTelegramID, vacation.LastDay().Format("02.01.2006"))
+
+	if vacation.BackupTelegramID != nil {
+		if _, backupAway := onVacation[*vacation.BackupTelegramID]; !backupAway {
+			return []int64{*vacation.BackupTelegramID}, note
+		}
+	}
+	return w.adminChats.AdminChatIDs(), note
+}
+
+// loadReminderSchedules возвращает расписание напоминаний для каждого тарифа
+func (w *Worker) loadReminderSchedules(ctx context.Context) (map[int64][]int, error) {
+	allTariffs, err := w.storage.ListTariffs(ctx, tariffs.ListCriteria{})
+	if err != nil {
+		return nil, fmt.Errorf("list tariffs: %w", err)
+	}
+
+	schedules := make(map[int64][]int, len(allTariffs))
+	for _, t := range allTariffs {
+		schedules[t.ID] = t.ReminderSchedule()
+	}
+	return schedules, nil
+}
+
+// reminderDaysUnion возвращает все дни напоминаний по всем тарифам по убыванию
+func reminderDaysUnion(schedules map[int64][]int) []int {
+	seen := make(map[int]bool)
+	for _, d := range tariffs.DefaultReminderDays {
+		seen[d] = true
+	}
+	for _, days := range schedules {
+		for _, d := range days {
+			seen[d] = true
+		}
+	}
+
+	result := make([]int, 0, len(seen))
+	for d := range seen {
+		if d >= 0 {
+			result = append(result, d)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(result)))
+	return result
+}
+
+// sendExpiringNotifications отправляет уведомления за N дней до истечения (считая от from)
+// только по подпискам, в расписании тарифа которых есть этот день и которые прошли фильтр inHour
+func (w *Worker) sendExpiringNotifications(
+	ctx context.Context,
+	from time.Time,
+	daysUntilExpiry int,
+	schedules map[int64][]int,
+	inHour func(sub *subs.Subscription) bool,
+	onVacation map[int64]*vacations.Vacation,
+) error {
+	expiringByAssistant, err := w.storage.ListExpiringByAssistantAndDaysFrom(ctx, from, daysUntilExpiry)
 	if err != nil {
 		return fmt.Errorf("list expiring subscriptions for %d days: %w", daysUntilExpiry, err)
 	}
 
+	for assistantID, subscriptions := range expiringByAssistant {
+		expiringByAssistant[assistantID] = lo.Filter(subscriptions, func(sub *subs.Subscription, _ int) bool {
+			schedule, ok := schedules[sub.TariffID]
+			if !ok {
+				schedule = tariffs.DefaultReminderDays
+			}
+			return lo.Contains(schedule, daysUntilExpiry) && inHour(sub)
+		})
+	}
+
 	w.logger.Info("Found expiring subscriptions",
 		"assistants_count", len(expiringByAssistant),
 		"days_until_expiry", daysUntilExpiry)
 
 	for assistantID, subscriptions := range expiringByAssistant {
-		if err := w.sendExpiringNotificationToAssistant(ctx, assistantID, subscriptions, daysUntilExpiry); err != nil {
-			w.logger.Error("Failed to send expiring notification",
-				"assistant_id", assistantID,
-				"days_until_expiry", daysUntilExpiry,
-				"error", err)
+		chatIDs, note := w.recipientsFor(assistantID, onVacation)
+		for _, chatID := range chatIDs {
+			if err := w.sendExpiringNotificationToAssistant(ctx, chatID, note, subscriptions, daysUntilExpiry); err != nil {
+				w.logger.Error("Failed to send expiring notification",
+					"assistant_id", assistantID,
+					"chat_id", chatID,
+					"days_until_expiry", daysUntilExpiry,
+					"error", err)
+			}
 		}
 	}
 
@@ -128,9 +320,11 @@ func (w *Worker) sendExpiringNotifications(ctx context.Context, daysUntilExpiry
 }
 
 // sendExpiringNotificationToAssistant отправляет уведомления об истекающих подписках ассистенту
+// (или его замене - тогда в начале сводки пометка vacationNote)
 func (w *Worker) sendExpiringNotificationToAssistant(
 	ctx context.Context,
 	assistantTelegramID int64,
+	vacationNote string,
 	subscriptions []*subs.Subscription,
 	daysUntilExpiry int,
 ) error {
@@ -143,15 +337,22 @@ func (w *Worker) sendExpiringNotificationToAssistant(
 	switch daysUntilExpiry {
 	case 0:
 		summaryText = fmt.Sprintf("🔔 *У вас %d подписок истекают сегодня*\n\nНиже отдельные сообщения для каждой подписки.", len(subscriptions))
+	case 1:
+		summaryText = fmt.Sprintf("⏰ *У вас %d подписок истекают завтра*\n\nНиже отдельные сообщения для каждой подписки.", len(subscriptions))
 	case 3:
 		summaryText = fmt.Sprintf("⏰ *У вас %d подписок истекают через 3 дня*\n\nНиже отдельные сообщения для каждой подписки.", len(subscriptions))
 	default:
 		summaryText = fmt.Sprintf("⏰ *У вас %d подписок истекают через %d дней*\n\nНиже отдельные сообщения для каждой подписки.", len(subscriptions), daysUntilExpiry)
 	}
 
-	summaryMsg := tgbotapi.NewMessage(assistantTelegramID, summaryText)
+	summaryMsg := tgbotapi.NewMessage(assistantTelegramID, vacationNote+summaryText)
 	summaryMsg.ParseMode = "Markdown"
-	_, _ = w.telegramBot.Send(summaryMsg)
+	if _, err := w.telegramBot.Send(summaryMsg); err != nil && telegram.IsUnreachable(err) {
+		// Ассистент недоступен - отдельные сообщения тоже не дойдут
+		w.logger.Warn("Assistant is unreachable, skipping expiring notifications",
+			"assistant_id", assistantTelegramID, "count", len(subscriptions))
+		return nil
+	}
 
 	// Отправляем отдельные сообщения через notification service
 	for _, sub := range subscriptions {
@@ -167,7 +368,7 @@ func (w *Worker) sendExpiringNotificationToAssistant(
 }
 
 // sendOverdueNotifications sends notifications about overdue subscriptions
-func (w *Worker) sendOverdueNotifications(ctx context.Context) error {
+func (w *Worker) sendOverdueNotifications(ctx context.Context, onVacation map[int64]*vacations.Vacation) error {
 	overdueByAssistant, err := w.storage.ListOverdueSubscriptionsGroupedByAssistant(ctx)
 	if err != nil {
 		return fmt.Errorf("list overdue: %w", err)
@@ -176,10 +377,14 @@ func (w *Worker) sendOverdueNotifications(ctx context.Context) error {
 	w.logger.Info("Found overdue subscriptions", "assistants_count", len(overdueByAssistant))
 
 	for assistantID, subscriptions := range overdueByAssistant {
-		if err := w.sendOverdueNotification(ctx, assistantID, subscriptions); err != nil {
-			w.logger.Error("Failed to send overdue notification",
-				"assistant_id", assistantID,
-				"error", err)
+		chatIDs, note := w.recipientsFor(assistantID, onVacation)
+		for _, chatID := range chatIDs {
+			if err := w.sendOverdueNotification(ctx, chatID, note, subscriptions); err != nil {
+				w.logger.Error("Failed to send overdue notification",
+					"assistant_id", assistantID,
+					"chat_id", chatID,
+					"error", err)
+			}
 		}
 	}
 
@@ -187,16 +392,20 @@ func (w *Worker) sendOverdueNotifications(ctx context.Context) error {
 }
 
 // sendOverdueNotification sends a notification about overdue subscriptions to an assistant
-func (w *Worker) sendOverdueNotification(ctx context.Context, assistantTelegramID int64, subscriptions []*subs.Subscription) error {
+func (w *Worker) sendOverdueNotification(ctx context.Context, assistantTelegramID int64, vacationNote string, subscriptions []*subs.Subscription) error {
 	if len(subscriptions) == 0 {
 		return nil
 	}
 
 	// Summary message
 	summaryText := fmt.Sprintf("⚠️ *У вас %d просроченных подписок*\n\nНиже отдельные сообщения для каждой подписки.", len(subscriptions))
-	summaryMsg := tgbotapi.NewMessage(assistantTelegramID, summaryText)
+	summaryMsg := tgbotapi.NewMessage(assistantTelegramID, vacationNote+summaryText)
 	summaryMsg.ParseMode = "Markdown"
-	_, _ = w.telegramBot.Send(summaryMsg)
+	if _, err := w.telegramBot.Send(summaryMsg); err != nil && telegram.IsUnreachable(err) {
+		w.logger.Warn("Assistant is unreachable, skipping overdue notifications",
+			"assistant_id", assistantTelegramID, "count", len(subscriptions))
+		return nil
+	}
 
 	// Individual messages via notification service
 	for _, sub := range subscriptions {
@@ -208,6 +417,49 @@ func (w *Worker) sendOverdueNotification(ctx context.Context, assistantTelegramI
 	return nil
 }
 
+// resumeEndedPauses возобновляет подписки с закончившейся паузой и сообщает об этом создателю подписки.
+// Подписки на паузе не истекают: ListExpiredSubscriptions выбирает только активные
+func (w *Worker) resumeEndedPauses(ctx context.Context) error {
+	subscriptions, err := w.storage.ListEndedPauses(ctx)
+	if err != nil {
+		return fmt.Errorf("list ended pauses: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for _, sub := range subscriptions {
+		if sub.ExpiresAt == nil || sub.PausedAt == nil {
+			continue
+		}
+		expiresAt := subs.ResumedExpiry(*sub.ExpiresAt, *sub.PausedAt, now)
+		resumed, err := w.storage.ResumeSubscription(ctx, sub.ID, expiresAt)
+		if err != nil {
+			w.logger.Error("Failed to resume subscription", "subscription_id", sub.ID, "error", err)
+			continue
+		}
+		if !resumed {
+			continue
+		}
+
+		w.logger.Info("Subscription resumed after pause",
+			"subscription_id", sub.ID,
+			"expires_at", expiresAt)
+
+		text := fmt.Sprintf("▶️ Пауза подписки #%d закончилась, подписка снова активна до %s.\n\n"+
+			"Не забудьте включить клиента в панели сервера.", sub.ID, expiresAt.Format("02.01.2006"))
+		recipients := w.adminChats.AdminChatIDs()
+		if sub.CreatedByTelegramID != nil {
+			recipients = []int64{*sub.CreatedByTelegramID}
+		}
+		for _, chatID := range recipients {
+			if _, err := w.telegramBot.Send(tgbotapi.NewMessage(chatID, text)); err != nil {
+				w.logger.Error("Failed to send resume notification", "chat_id", chatID, "subscription_id", sub.ID, "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // markExpiredSubscriptions marks expired subscriptions as expired in DB
 func (w *Worker) markExpiredSubscriptions(ctx context.Context) error {
 	subscriptions, err := w.storage.ListExpiredSubscriptions(ctx)
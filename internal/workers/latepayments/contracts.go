@@ -0,0 +1,35 @@
+package latepayments
+
+import (
+	"context"
+	"time"
+
+	"kurut-bot/internal/stories/orders"
+	"kurut-bot/internal/stories/payment"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type (
+	// Storage provides access to cancelled orders and their payments
+	Storage interface {
+		ListCancelledOrdersAwaitingLateCheck(ctx context.Context, cancelledAfter time.Time) ([]*orders.PendingOrder, error)
+		GetPayment(ctx context.Context, criteria payment.GetCriteria) (*payment.Payment, error)
+		UpdatePayment(ctx context.Context, criteria payment.GetCriteria, params payment.UpdateParams) (*payment.Payment, error)
+	}
+
+	// PaymentService provides payment status checks
+	PaymentService interface {
+		CheckPaymentStatus(ctx context.Context, paymentID int64) (*payment.Payment, error)
+	}
+
+	// TelegramBot provides telegram messaging
+	TelegramBot interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	}
+
+	// Admins - текущие админы: из конфига и назначенные через /set_role
+	Admins interface {
+		AdminIDs() []int64
+	}
+)
@@ -0,0 +1,197 @@
+package latepayments
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"kurut-bot/internal/stories/orders"
+	"kurut-bot/internal/stories/payment"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/robfig/cron/v3"
+)
+
+// lookbackWindow - сколько после отмены заказа следим за его платежом.
+// Ссылка ЮKassa дольше не живет, старые заказы проверять незачем
+const lookbackWindow = 7 * 24 * time.Hour
+
+// Worker finds payments approved after their order was cancelled and asks admins what to do
+type Worker struct {
+	storage        Storage
+	paymentService PaymentService
+	telegramBot    TelegramBot
+	admins         Admins
+	manualPayment  bool
+	logger         *slog.Logger
+	cron           *cron.Cron
+}
+
+// NewWorker creates a new late payments worker
+func NewWorker(
+	storage Storage,
+	paymentService PaymentService,
+	telegramBot TelegramBot,
+	admins Admins,
+	manualPayment bool,
+	logger *slog.Logger,
+) *Worker {
+	return &Worker{
+		storage:        storage,
+		paymentService: paymentService,
+		telegramBot:    telegramBot,
+		admins:         admins,
+		manualPayment:  manualPayment,
+		logger:         logger,
+		cron:           cron.New(),
+	}
+}
+
+// Name returns the worker name
+func (w *Worker) Name() string {
+	return "late-payments"
+}
+
+// Start starts the late payments worker
+func (w *Worker) Start() error {
+	// В ручном режиме ссылок ЮKassa нет - оплатить отмененный заказ нечем
+	if w.manualPayment {
+		w.logger.Info("Manual payment mode enabled, skipping late payments worker")
+		return nil
+	}
+
+	// Runs every 10 minutes
+	_, err := w.cron.AddFunc("*/10 * * * *", func() {
+		defer func() {
+			if r := recover(); r != nil {
+				w.logger.Error("Panic in late payments worker", "panic", r)
+			}
+		}()
+		ctx := context.Background()
+		if err := w.run(ctx); err != nil {
+			w.logger.Error("Late payments worker failed", "error", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule late payments worker: %w", err)
+	}
+
+	w.cron.Start()
+	return nil
+}
+
+// Stop stops the worker
+func (w *Worker) Stop() {
+	w.logger.Info("Stopping late payments worker")
+	w.cron.Stop()
+}
+
+// RunNow runs the worker immediately (for manual testing)
+func (w *Worker) RunNow(ctx context.Context) error {
+	w.logger.Info("Manual run of late payments worker")
+	return w.run(ctx)
+}
+
+// run checks payments of recently cancelled orders and notifies admins about approved ones
+func (w *Worker) run(ctx context.Context) error {
+	cancelled, err := w.storage.ListCancelledOrdersAwaitingLateCheck(ctx, time.Now().UTC().Add(-lookbackWindow))
+	if err != nil {
+		return fmt.Errorf("list cancelled orders: %w", err)
+	}
+
+	for _, order := range cancelled {
+		if err := w.checkOrder(ctx, order); err != nil {
+			w.logger.Error("Failed to check cancelled order payment",
+				"order_id", order.ID,
+				"payment_id", order.PaymentID,
+				"error", err)
+		}
+	}
+
+	return nil
+}
+
+// checkOrder проверяет платеж отмененного заказа в ЮKassa и при оплате помечает его как поздний
+func (w *Worker) checkOrder(ctx context.Context, order *orders.PendingOrder) error {
+	p, err := w.storage.GetPayment(ctx, payment.GetCriteria{ID: &order.PaymentID})
+	if err != nil {
+		return fmt.Errorf("get payment: %w", err)
+	}
+	if p == nil {
+		return nil
+	}
+
+	if p.Status != payment.StatusApproved {
+		p, err = w.paymentService.CheckPaymentStatus(ctx, order.PaymentID)
+		if err != nil {
+			return fmt.Errorf("check payment status: %w", err)
+		}
+		if p.Status != payment.StatusApproved {
+			return nil
+		}
+	}
+
+	detected := payment.LateDetected
+	if _, err := w.storage.UpdatePayment(ctx, payment.GetCriteria{ID: &p.ID}, payment.UpdateParams{LateStatus: &detected}); err != nil {
+		return fmt.Errorf("mark payment late: %w", err)
+	}
+
+	w.logger.Warn("Late payment for cancelled order detected",
+		"audit", true,
+		"order_id", order.ID,
+		"payment_id", p.ID,
+		"amount", p.Amount,
+		"whatsapp", order.ClientWhatsApp)
+
+	text, keyboard := buildAlert(order, p)
+	for _, adminID := range w.admins.AdminIDs() {
+		msg := tgbotapi.NewMessage(adminID, text)
+		msg.ParseMode = "Markdown"
+		msg.ReplyMarkup = keyboard
+		if _, err := w.telegramBot.Send(msg); err != nil {
+			w.logger.Error("Failed to send late payment alert", "admin_id", adminID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// buildAlert формирует текст алерта и кнопки выбора: выдать подписку, вернуть деньги или зачислить на баланс
+func buildAlert(order *orders.PendingOrder, p *payment.Payment) (string, tgbotapi.InlineKeyboardMarkup) {
+	kind := "новая подписка"
+	if order.IsMigration() {
+		kind = "миграция"
+	}
+	// Телефон и название тарифа вводятся вручную: символы разметки в них сломали бы отправку алерта
+	client := tgbotapi.EscapeText(tgbotapi.ModeMarkdown, order.ClientWhatsApp)
+	tariffName := tgbotapi.EscapeText(tgbotapi.ModeMarkdown, order.TariffName)
+
+	text := fmt.Sprintf(
+		"💸 *Оплата отмененного заказа*\n\n"+
+			"Клиент %s оплатил ссылку заказа #%d после его отмены.\n\n"+
+			"📱 Клиент: %s\n"+
+			"📅 Тариф: %s (%s)\n"+
+			"💰 Сумма: %.2f ₽\n"+
+			"🧾 Платеж: #%d\n"+
+			"👤 Ассистент: [%d](tg://user?id=%d)\n\n"+
+			"Что сделать с оплатой?",
+		client, order.ID,
+		client,
+		tariffName, kind,
+		p.Amount,
+		p.ID,
+		order.AssistantTelegramID, order.AssistantTelegramID)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Выдать подписку", fmt.Sprintf("late_fulfill:%d", p.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Вернуть деньги", fmt.Sprintf("late_refund:%d", p.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("💰 На баланс", fmt.Sprintf("late_credit:%d", p.ID)),
+		),
+	)
+
+	return text, keyboard
+}
@@ -0,0 +1,20 @@
+package latepayments
+
+import (
+	"strings"
+	"testing"
+
+	"kurut-bot/internal/stories/orders"
+	"kurut-bot/internal/stories/payment"
+)
+
+func TestBuildAlertEscapesMarkdown(t *testing.T) {
+	order := &orders.PendingOrder{ID: 5, ClientWhatsApp: "+996_555*", TariffName: "VIP_1 [год]", AssistantTelegramID: 42}
+	text, _ := buildAlert(order, &payment.Payment{ID: 7, Amount: 300})
+
+	for _, want := range []string{`+996\_555\*`, `VIP\_1 \[год]`, "[42](tg://user?id=42)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("alert %q does not contain %q", text, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// lockName - блокировка всех воркеров в worker_locks
+	lockName = "workers"
+	// lockTTL - через сколько блокировку упавшего процесса может забрать другой
+	lockTTL = time.Minute
+	// lockRenewInterval - как часто владелец продлевает блокировку и как часто ее пробуют взять остальные
+	lockRenewInterval = lockTTL / 3
+)
+
+// LockStorage - блокировка в БД, через которую процессы договариваются, кто запускает воркеры
+type LockStorage interface {
+	AcquireWorkerLock(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	ReleaseWorkerLock(ctx context.Context, name, owner string) error
+}
+
+// WithLock включает координацию через БД: воркеры запускаются, только когда процесс owner взял
+// блокировку. Нужно, когда воркеры запущены отдельными процессами. Если блокировка потеряна,
+// менеджер останавливает воркеры и сообщает об этом через Failed
+func (m *Manager) WithLock(storage LockStorage, owner string) *Manager {
+	m.lock = storage
+	m.owner = owner
+	m.renewInterval = lockRenewInterval
+	m.failed = make(chan error, 1)
+	return m
+}
+
+// Failed возвращает канал, в который менеджер с блокировкой отправляет ошибку, если воркеры остановились
+// без Stop: блокировку забрал другой процесс, ее не удалось продлить дольше lockTTL или воркеры не запустились.
+// Cron воркеров нельзя запустить повторно, поэтому процесс должен завершиться, чтобы супервизор его перезапустил.
+// Без блокировки канал nil
+func (m *Manager) Failed() <-chan error {
+	return m.failed
+}
+
+// holdLock берет блокировку и продлевает ее до остановки менеджера
+func (m *Manager) holdLock() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.renewInterval)
+	defer ticker.Stop()
+
+	running := false
+	// renewedAt - время последнего успешного продления блокировки
+	var renewedAt time.Time
+	for {
+		acquired, err := m.lock.AcquireWorkerLock(context.Background(), lockName, m.owner, lockTTL)
+		if err != nil {
+			m.logger.Error("Failed to acquire workers lock", "error", err, "owner", m.owner)
+		}
+		if acquired {
+			renewedAt = time.Now()
+		}
+
+		var failure error
+		switch {
+		case running && err != nil && time.Since(renewedAt) >= lockTTL:
+			// БД недоступна дольше lockTTL: блокировка истекла, и ее мог забрать другой процесс
+			failure = fmt.Errorf("workers lock not renewed since %s", renewedAt.Format(time.RFC3339))
+		case acquired && !running:
+			m.logger.Info("Workers lock acquired", "owner", m.owner)
+			// Часть воркеров могла запуститься до ошибки - их остановит fail
+			running = true
+			if err := m.startAll(); err != nil {
+				failure = err
+			}
+		case !acquired && running && err == nil:
+			// Блокировку забрал другой процесс, пока этот не продлевал ее дольше lockTTL
+			failure = errors.New("workers lock taken over by another process")
+		case !acquired && !running:
+			m.logger.Debug("Workers lock is held by another process", "owner", m.owner)
+		}
+		if failure != nil {
+			m.fail(running, failure)
+			return
+		}
+
+		select {
+		case <-m.stop:
+			if running {
+				m.stopAll()
+			}
+			m.releaseLock()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fail останавливает воркеры, чтобы они не работали в двух процессах одновременно,
+// отпускает блокировку и сообщает об ошибке через Failed
+func (m *Manager) fail(running bool, err error) {
+	m.logger.Error("Workers stopped, process must be restarted", "error", err, "owner", m.owner)
+	if running {
+		m.stopAll()
+	}
+	m.releaseLock()
+	m.failed <- err
+}
+
+// releaseLock снимает блокировку, если ее держит этот процесс
+func (m *Manager) releaseLock() {
+	if err := m.lock.ReleaseWorkerLock(context.Background(), lockName, m.owner); err != nil {
+		m.logger.Error("Failed to release workers lock", "error", err, "owner", m.owner)
+	}
+}
@@ -0,0 +1,155 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeLock struct {
+	mu       sync.Mutex
+	holder   string
+	released bool
+}
+
+func (l *fakeLock) AcquireWorkerLock(_ context.Context, _, owner string, _ time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holder == "" {
+		l.holder = owner
+	}
+	return l.holder == owner, nil
+}
+
+func (l *fakeLock) ReleaseWorkerLock(_ context.Context, _, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holder == owner {
+		l.holder = ""
+		l.released = true
+	}
+	return nil
+}
+
+type fakeWorker struct {
+	mu       sync.Mutex
+	started  bool
+	stopped  bool
+	startErr error
+}
+
+func (w *fakeWorker) Start() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.started = true
+	return w.startErr
+}
+
+func (w *fakeWorker) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+}
+
+func (w *fakeWorker) Name() string { return "fake" }
+
+func (w *fakeWorker) state() (started, stopped bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.started, w.stopped
+}
+
+func TestManagerWithLock(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	lock := &fakeLock{holder: "other"}
+	worker := &fakeWorker{}
+
+	m := NewManager(logger, worker).WithLock(lock, "me")
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Блокировку держит другой процесс - воркер не запускается
+	time.Sleep(50 * time.Millisecond)
+	if started, _ := worker.state(); started {
+		t.Fatal("worker started without the lock")
+	}
+	m.Stop()
+
+	lock.holder = ""
+	m = NewManager(logger, worker).WithLock(lock, "me")
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for started, _ := worker.state(); !started; started, _ = worker.state() {
+		if time.Now().After(deadline) {
+			t.Fatal("worker not started after acquiring the lock")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	m.Stop()
+	if _, stopped := worker.state(); !stopped || !lock.released {
+		t.Errorf("after Stop: stopped = %v, released = %v", stopped, lock.released)
+	}
+}
+
+func TestManagerWithLockFails(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	waitFailed := func(t *testing.T, m *Manager) error {
+		t.Helper()
+		select {
+		case err := <-m.Failed():
+			return err
+		case <-time.After(time.Second):
+			t.Fatal("manager did not report the failure")
+			return nil
+		}
+	}
+
+	t.Run("lock taken over", func(t *testing.T) {
+		lock := &fakeLock{}
+		worker := &fakeWorker{}
+		m := NewManager(logger, worker).WithLock(lock, "me")
+		m.renewInterval = 5 * time.Millisecond
+		if err := m.Start(); err != nil {
+			t.Fatal(err)
+		}
+		for started, _ := worker.state(); !started; started, _ = worker.state() {
+			time.Sleep(time.Millisecond)
+		}
+
+		lock.mu.Lock()
+		lock.holder = "other"
+		lock.mu.Unlock()
+
+		if err := waitFailed(t, m); err == nil {
+			t.Error("Failed() must carry the error")
+		}
+		if _, stopped := worker.state(); !stopped {
+			t.Error("worker must be stopped after losing the lock")
+		}
+		m.Stop()
+	})
+
+	t.Run("workers fail to start", func(t *testing.T) {
+		lock := &fakeLock{}
+		worker := &fakeWorker{startErr: errors.New("bad cron spec")}
+		m := NewManager(logger, worker).WithLock(lock, "me")
+		if err := m.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		waitFailed(t, m)
+		m.Stop()
+		if _, stopped := worker.state(); !stopped || !lock.released {
+			t.Errorf("after start failure: stopped = %v, released = %v", stopped, lock.released)
+		}
+	})
+}
@@ -3,12 +3,21 @@ package workers
 import (
 	"fmt"
 	"log/slog"
+	"time"
 )
 
 // Manager manages multiple workers
 type Manager struct {
 	workers []Worker
 	logger  *slog.Logger
+
+	// Координация через БД (WithLock); без нее воркеры запускаются сразу
+	lock          LockStorage
+	owner         string
+	renewInterval time.Duration
+	stop          chan struct{}
+	done          chan struct{}
+	failed        chan error
 }
 
 // NewManager creates a new worker manager
@@ -19,8 +28,31 @@ func NewManager(logger *slog.Logger, workers ...Worker) *Manager {
 	}
 }
 
-// Start starts all workers
+// Start starts all workers. With a lock workers start in the background once the lock is acquired
 func (m *Manager) Start() error {
+	if m.lock == nil {
+		return m.startAll()
+	}
+
+	m.logger.Info("Waiting for workers lock", "owner", m.owner)
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.holdLock()
+	return nil
+}
+
+// Stop stops all workers and releases the lock
+func (m *Manager) Stop() {
+	if m.lock == nil {
+		m.stopAll()
+		return
+	}
+
+	close(m.stop)
+	<-m.done
+}
+
+func (m *Manager) startAll() error {
 	m.logger.Info("Starting worker manager", "worker_count", len(m.workers))
 
 	for _, worker := range m.workers {
@@ -35,8 +67,7 @@ func (m *Manager) Start() error {
 	return nil
 }
 
-// Stop stops all workers
-func (m *Manager) Stop() {
+func (m *Manager) stopAll() {
 	m.logger.Info("Stopping all workers")
 
 	for _, worker := range m.workers {
@@ -3,6 +3,7 @@ package paymentautocheck
 import (
 	"context"
 
+	"kurut-bot/internal/stories/bulkrenew"
 	"kurut-bot/internal/stories/orders"
 	"kurut-bot/internal/stories/payment"
 	"kurut-bot/internal/stories/servers"
@@ -27,6 +28,17 @@ type (
 		GetSubscriptionMessageByID(ctx context.Context, id int64) (*submessages.SubscriptionMessage, error)
 	}
 
+	// BulkRenewalStorage provides operations for bulk renewals
+	BulkRenewalStorage interface {
+		ListPendingBulkRenewals(ctx context.Context) ([]*bulkrenew.BulkRenewal, error)
+	}
+
+	// BulkRenewService extends subscriptions paid with one bulk renewal payment
+	BulkRenewService interface {
+		Complete(ctx context.Context, renewal *bulkrenew.BulkRenewal) (*bulkrenew.Result, error)
+		Cancel(ctx context.Context, renewal *bulkrenew.BulkRenewal) (bool, error)
+	}
+
 	// PaymentService provides payment operations
 	PaymentService interface {
 		CheckPaymentStatus(ctx context.Context, paymentID int64) (*payment.Payment, error)
@@ -60,5 +72,6 @@ type (
 	// TelegramBot provides telegram messaging
 	TelegramBot interface {
 		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+		Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
 	}
 )
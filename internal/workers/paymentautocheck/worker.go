@@ -2,10 +2,14 @@ package paymentautocheck
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
 
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/stories/bonusrules"
+	"kurut-bot/internal/stories/bulkrenew"
 	"kurut-bot/internal/stories/orders"
 	"kurut-bot/internal/stories/payment"
 	"kurut-bot/internal/stories/servers"
@@ -21,6 +25,8 @@ import (
 type Worker struct {
 	orderStorage        OrderStorage
 	messageStorage      MessageStorage
+	bulkRenewalStorage  BulkRenewalStorage
+	bulkRenewService    BulkRenewService
 	paymentService      PaymentService
 	subscriptionService SubscriptionService
 	subscriptionStorage SubscriptionStorage
@@ -32,14 +38,17 @@ type Worker struct {
 	manualPayment       bool
 
 	// Track orders being processed to prevent race conditions
-	processingOrders   sync.Map
-	processingMessages sync.Map
+	processingOrders       sync.Map
+	processingMessages     sync.Map
+	processingBulkRenewals sync.Map
 }
 
 // NewWorker creates a new payment autocheck worker
 func NewWorker(
 	orderStorage OrderStorage,
 	messageStorage MessageStorage,
+	bulkRenewalStorage BulkRenewalStorage,
+	bulkRenewService BulkRenewService,
 	paymentService PaymentService,
 	subscriptionService SubscriptionService,
 	subscriptionStorage SubscriptionStorage,
@@ -52,6 +61,8 @@ func NewWorker(
 	return &Worker{
 		orderStorage:        orderStorage,
 		messageStorage:      messageStorage,
+		bulkRenewalStorage:  bulkRenewalStorage,
+		bulkRenewService:    bulkRenewService,
 		paymentService:      paymentService,
 		subscriptionService: subscriptionService,
 		subscriptionStorage: subscriptionStorage,
@@ -116,6 +127,11 @@ func (w *Worker) run(ctx context.Context) error {
 		w.logger.Error("Failed to process subscription messages", "error", err)
 	}
 
+	// Process bulk renewals (one payment for several subscriptions)
+	if err := w.processBulkRenewals(ctx); err != nil {
+		w.logger.Error("Failed to process bulk renewals", "error", err)
+	}
+
 	return nil
 }
 
@@ -203,6 +219,8 @@ func (w *Worker) handleApprovedOrderPayment(ctx context.Context, order *orders.P
 			ClientWhatsApp:         order.ClientWhatsApp,
 			CreatedByTelegramID:    order.AssistantTelegramID,
 			ReferrerSubscriptionID: order.ReferrerSubscriptionID,
+			ServerID:               order.TargetServerID,
+			Channel:                bonusrules.ChannelAutoCheck,
 		}
 		result, err = w.subscriptionService.CreateSubscription(ctx, req)
 	}
@@ -275,7 +293,13 @@ func (w *Worker) sendOrderSuccessMessage(order *orders.PendingOrder, result *sub
 
 	// Add referral bonus info if applicable
 	if result.ReferralBonusApplied && result.ReferrerWhatsApp != nil {
-		text += fmt.Sprintf("\n\n*Реферальный бонус*: +10 дней для %s", *result.ReferrerWhatsApp)
+		text += fmt.Sprintf("\n\n*Реферальный бонус*: +%d дней для %s", result.ReferralBonusDays, *result.ReferrerWhatsApp)
+	}
+	if result.ClientBonusDays > 0 {
+		text += fmt.Sprintf("\n*Бонус клиенту*: +%d дней", result.ClientBonusDays)
+	}
+	for _, note := range result.BonusNotifications {
+		text += "\n📣 " + note
 	}
 
 	// Build keyboard with server link
@@ -285,6 +309,11 @@ func (w *Worker) sendOrderSuccessMessage(order *orders.PendingOrder, result *sub
 			tgbotapi.NewInlineKeyboardButtonURL("Сервер", serverURL),
 		))
 	}
+	if result.Subscription != nil {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📅 В календарь", fmt.Sprintf("cal_sub:%d", result.Subscription.ID)),
+		))
+	}
 
 	var keyboard *tgbotapi.InlineKeyboardMarkup
 	if len(rows) > 0 {
@@ -297,8 +326,7 @@ func (w *Worker) sendOrderSuccessMessage(order *orders.PendingOrder, result *sub
 		editMsg := tgbotapi.NewEditMessageText(order.ChatID, *order.MessageID, text)
 		editMsg.ParseMode = "Markdown"
 		editMsg.ReplyMarkup = keyboard
-		_, err := w.telegramBot.Send(editMsg)
-		return err
+		return telegram.SafeEdit(w.telegramBot, editMsg, "")
 	}
 
 	// Fallback: send new message
@@ -482,6 +510,9 @@ func (w *Worker) sendRenewalSuccessMessage(
 			tgbotapi.NewInlineKeyboardButtonURL("Сервер", server.UIURL),
 		))
 	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📅 В календарь", fmt.Sprintf("cal_sub:%d", sub.ID)),
+	))
 
 	var keyboard *tgbotapi.InlineKeyboardMarkup
 	if len(rows) > 0 {
@@ -492,6 +523,74 @@ func (w *Worker) sendRenewalSuccessMessage(
 	editMsg := tgbotapi.NewEditMessageText(msg.ChatID, msg.MessageID, text)
 	editMsg.ParseMode = "Markdown"
 	editMsg.ReplyMarkup = keyboard
-	_, err := w.telegramBot.Send(editMsg)
+	return telegram.SafeEdit(w.telegramBot, editMsg, "")
+}
+
+// processBulkRenewals handles pending bulk renewals
+func (w *Worker) processBulkRenewals(ctx context.Context) error {
+	renewals, err := w.bulkRenewalStorage.ListPendingBulkRenewals(ctx)
+	if err != nil {
+		return fmt.Errorf("list pending bulk renewals: %w", err)
+	}
+
+	for _, renewal := range renewals {
+		// Check if already being processed
+		if _, loaded := w.processingBulkRenewals.LoadOrStore(renewal.ID, true); loaded {
+			continue
+		}
+
+		go func(renewal *bulkrenew.BulkRenewal) {
+			defer w.processingBulkRenewals.Delete(renewal.ID)
+
+			if err := w.processBulkRenewal(ctx, renewal); err != nil {
+				w.logger.Error("Failed to process bulk renewal",
+					"bulk_renewal_id", renewal.ID,
+					"payment_id", renewal.PaymentID,
+					"error", err)
+			}
+		}(renewal)
+	}
+
+	return nil
+}
+
+// processBulkRenewal checks the payment and extends all linked subscriptions once it is approved
+func (w *Worker) processBulkRenewal(ctx context.Context, renewal *bulkrenew.BulkRenewal) error {
+	paymentObj, err := w.paymentService.CheckPaymentStatus(ctx, renewal.PaymentID)
+	if err != nil {
+		return fmt.Errorf("check payment status: %w", err)
+	}
+
+	var text string
+	switch paymentObj.Status {
+	case payment.StatusApproved:
+		result, err := w.bulkRenewService.Complete(ctx, renewal)
+		if errors.Is(err, bulkrenew.ErrAlreadyProcessed) {
+			// Assistant pressed the check button first
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("complete bulk renewal: %w", err)
+		}
+		text = result.Summary()
+	case payment.StatusRejected, payment.StatusCancelled:
+		// Bulk renewal has no "new link" button, so the assistant starts over
+		cancelled, err := w.bulkRenewService.Cancel(ctx, renewal)
+		if err != nil {
+			return fmt.Errorf("cancel bulk renewal: %w", err)
+		}
+		if !cancelled {
+			return nil
+		}
+		text = fmt.Sprintf("❌ Платёж на %.0f ₽ отменён, подписки не продлены.\nСоздайте новую ссылку через /bulk_renew", renewal.TotalAmount)
+	default:
+		return nil
+	}
+
+	if renewal.MessageID != nil {
+		editMsg := tgbotapi.NewEditMessageText(renewal.ChatID, *renewal.MessageID, text)
+		return telegram.SafeEdit(w.telegramBot, editMsg, "")
+	}
+	_, err = w.telegramBot.Send(tgbotapi.NewMessage(renewal.ChatID, text))
 	return err
 }
@@ -0,0 +1,29 @@
+package pricechange
+
+import (
+	"context"
+
+	"kurut-bot/internal/stories/pricechanges"
+	"kurut-bot/internal/stories/subs"
+)
+
+type (
+	// Storage provides scheduled price changes and subscriptions of the tariff
+	Storage interface {
+		ListScheduledPriceChanges(ctx context.Context) ([]*pricechanges.PriceChange, error)
+		MarkPriceChangeNotified(ctx context.Context, id int64) error
+		ApplyPriceChange(ctx context.Context, change pricechanges.PriceChange) (bool, error)
+		ListSubscriptions(ctx context.Context, criteria subs.ListCriteria) ([]*subs.Subscription, error)
+	}
+
+	// Notifier sends price lock cards to assistants and reports applied prices to admins
+	Notifier interface {
+		SendPriceLockMessages(ctx context.Context, chatID int64, change *pricechanges.PriceChange, clients []*subs.Subscription) error
+		SendPriceAppliedMessage(ctx context.Context, chatID int64, change *pricechanges.PriceChange) error
+	}
+
+	// AdminChats - куда слать служебные уведомления: админская группа или текущие админы
+	AdminChats interface {
+		AdminChatIDs() []int64
+	}
+)
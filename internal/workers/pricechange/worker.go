@@ -0,0 +1,190 @@
+package pricechange
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"kurut-bot/internal/stories/pricechanges"
+	"kurut-bot/internal/stories/subs"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Worker ведет кампанию повышения цены тарифа: после планирования раздает ассистентам их клиентов
+// с предложением продлиться по старой цене, а в день повышения меняет цену тарифа
+type Worker struct {
+	storage    Storage
+	notifier   Notifier
+	adminChats AdminChats
+	logger     *slog.Logger
+	cron       *cron.Cron
+}
+
+// NewWorker creates a new price change worker; adminChats receive clients without a creator and applied prices
+func NewWorker(
+	storage Storage,
+	notifier Notifier,
+	adminChats AdminChats,
+	logger *slog.Logger,
+) *Worker {
+	return &Worker{
+		storage:    storage,
+		notifier:   notifier,
+		adminChats: adminChats,
+		logger:     logger,
+		cron:       cron.New(),
+	}
+}
+
+// Name returns the worker name
+func (w *Worker) Name() string {
+	return "price-change"
+}
+
+// Start starts the price change worker
+func (w *Worker) Start() error {
+	// Runs hourly: карточки уходят вскоре после планирования, а новая цена применяется в начале дня
+	_, err := w.cron.AddFunc("0 * * * *", func() {
+		defer func() {
+			if r := recover(); r != nil {
+				w.logger.Error("Panic in price change worker", "panic", r)
+			}
+		}()
+		ctx := context.Background()
+		if err := w.run(ctx); err != nil {
+			w.logger.Error("Price change worker failed", "error", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule price change worker: %w", err)
+	}
+
+	w.cron.Start()
+	return nil
+}
+
+// Stop stops the worker
+func (w *Worker) Stop() {
+	w.logger.Info("Stopping price change worker")
+	w.cron.Stop()
+}
+
+// RunNow runs the worker immediately (for manual testing)
+func (w *Worker) RunNow(ctx context.Context) error {
+	w.logger.Info("Manual run of price change worker")
+	return w.run(ctx)
+}
+
+// run применяет наступившие изменения цен и рассылает карточки по новым повышениям
+func (w *Worker) run(ctx context.Context) error {
+	changes, err := w.storage.ListScheduledPriceChanges(ctx)
+	if err != nil {
+		return fmt.Errorf("list scheduled price changes: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for _, change := range changes {
+		switch {
+		case change.Due(now):
+			w.apply(ctx, change)
+		case change.NeedsNotification(now):
+			if err := w.notify(ctx, change); err != nil {
+				w.logger.Error("Failed to notify about price change", "change_id", change.ID, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (w *Worker) apply(ctx context.Context, change *pricechanges.PriceChange) {
+	applied, err := w.storage.ApplyPriceChange(ctx, *change)
+	if err != nil {
+		w.logger.Error("Failed to apply price change", "change_id", change.ID, "error", err)
+		return
+	}
+	if !applied {
+		return
+	}
+
+	w.logger.Info("Tariff price change applied",
+		"audit", true,
+		"change_id", change.ID,
+		"tariff_id", change.TariffID,
+		"old_price", change.OldPrice,
+		"new_price", change.NewPrice,
+	)
+	for _, chatID := range w.adminChats.AdminChatIDs() {
+		if err := w.notifier.SendPriceAppliedMessage(ctx, chatID, change); err != nil {
+			w.logger.Error("Failed to send price applied message", "change_id", change.ID, "chat_id", chatID, "error", err)
+		}
+	}
+}
+
+// notify отправляет каждому ассистенту его клиентов тарифа (без создателя - админам).
+// Клиенты с индивидуальной ценой не затронуты повышением и в рассылку не попадают
+func (w *Worker) notify(ctx context.Context, change *pricechanges.PriceChange) error {
+	subscriptions, err := w.storage.ListSubscriptions(ctx, subs.ListCriteria{
+		TariffIDs: []int64{change.TariffID},
+		Status:    []subs.Status{subs.StatusActive},
+	})
+	if err != nil {
+		return fmt.Errorf("list subscriptions: %w", err)
+	}
+
+	byAssistant := groupAffected(subscriptions)
+
+	var delivered, failed int
+	for assistantID, clients := range byAssistant {
+		recipients := w.adminChats.AdminChatIDs()
+		if assistantID != 0 {
+			recipients = []int64{assistantID}
+		}
+		for _, chatID := range recipients {
+			if err := w.notifier.SendPriceLockMessages(ctx, chatID, change, clients); err != nil {
+				w.logger.Error("Failed to send price lock message", "change_id", change.ID, "chat_id", chatID, "error", err)
+				failed++
+				continue
+			}
+			delivered++
+		}
+	}
+	if delivered == 0 && failed > 0 {
+		// Не отмечаем - попробуем в следующий запуск
+		return fmt.Errorf("no price lock messages delivered")
+	}
+
+	if err := w.storage.MarkPriceChangeNotified(ctx, change.ID); err != nil {
+		return fmt.Errorf("mark price change notified: %w", err)
+	}
+	w.logger.Info("Price lock campaign sent", "change_id", change.ID, "assistants", len(byAssistant), "delivered", delivered)
+	return nil
+}
+
+// groupAffected группирует клиентов, которых коснется повышение, по создателю подписки (0 - без создателя),
+// ближайшие окончания - первыми
+func groupAffected(subscriptions []*subs.Subscription) map[int64][]*subs.Subscription {
+	result := make(map[int64][]*subs.Subscription)
+	for _, sub := range subscriptions {
+		if sub.CustomPrice != nil || sub.ClientWhatsApp == nil || *sub.ClientWhatsApp == "" {
+			continue
+		}
+		var assistantID int64
+		if sub.CreatedByTelegramID != nil {
+			assistantID = *sub.CreatedByTelegramID
+		}
+		result[assistantID] = append(result[assistantID], sub)
+	}
+
+	for _, clients := range result {
+		sort.SliceStable(clients, func(i, j int) bool {
+			if clients[i].ExpiresAt == nil || clients[j].ExpiresAt == nil {
+				return clients[j].ExpiresAt == nil && clients[i].ExpiresAt != nil
+			}
+			return clients[i].ExpiresAt.Before(*clients[j].ExpiresAt)
+		})
+	}
+	return result
+}
@@ -0,0 +1,36 @@
+package pricechange
+
+import (
+	"testing"
+	"time"
+
+	"kurut-bot/internal/stories/subs"
+)
+
+func TestGroupAffected(t *testing.T) {
+	phone := "+996555000111"
+	empty := ""
+	assistant := int64(42)
+	customPrice := 250.0
+	soon := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	later := soon.AddDate(0, 1, 0)
+
+	grouped := groupAffected([]*subs.Subscription{
+		{ID: 1, ClientWhatsApp: &phone, CreatedByTelegramID: &assistant, ExpiresAt: &later},
+		{ID: 2, ClientWhatsApp: &phone, CreatedByTelegramID: &assistant, ExpiresAt: &soon},
+		{ID: 3, ClientWhatsApp: &phone, CreatedByTelegramID: &assistant, CustomPrice: &customPrice},
+		{ID: 4, ClientWhatsApp: &empty, CreatedByTelegramID: &assistant},
+		{ID: 5, ClientWhatsApp: &phone},
+	})
+
+	if len(grouped) != 2 {
+		t.Fatalf("groups = %d, want 2", len(grouped))
+	}
+	clients := grouped[assistant]
+	if len(clients) != 2 || clients[0].ID != 2 || clients[1].ID != 1 {
+		t.Errorf("assistant clients = %v, want subscriptions 2, 1", clients)
+	}
+	if len(grouped[0]) != 1 || grouped[0][0].ID != 5 {
+		t.Errorf("clients without creator = %v, want subscription 5", grouped[0])
+	}
+}
@@ -0,0 +1,28 @@
+package stuckpayments
+
+import (
+	"context"
+	"time"
+
+	"kurut-bot/internal/stories/payment"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type (
+	// Storage provides payment storage operations
+	Storage interface {
+		ListPendingPaymentsCreatedBefore(ctx context.Context, before time.Time) ([]*payment.Payment, error)
+		CountPendingPayments(ctx context.Context) (int, error)
+	}
+
+	// TelegramBot provides telegram messaging
+	TelegramBot interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	}
+
+	// Admins - текущие админы: из конфига и назначенные через /set_role
+	Admins interface {
+		AdminIDs() []int64
+	}
+)
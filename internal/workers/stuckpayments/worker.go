@@ -0,0 +1,163 @@
+package stuckpayments
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/stories/payment"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// stuckThreshold - сколько платеж может висеть в pending до алерта
+	stuckThreshold = 2 * time.Hour
+	// backlogThreshold - при каком количестве pending платежей слать алерт
+	backlogThreshold = 20
+	// maxListedPayments - сколько платежей показывать в одном сообщении
+	maxListedPayments = 10
+)
+
+// Worker alerts admins about payments stuck in pending status
+type Worker struct {
+	storage       Storage
+	telegramBot   TelegramBot
+	admins        Admins
+	manualPayment bool
+	logger        *slog.Logger
+	cron          *cron.Cron
+}
+
+// NewWorker creates a new stuck payments worker
+func NewWorker(
+	storage Storage,
+	telegramBot TelegramBot,
+	admins Admins,
+	manualPayment bool,
+	logger *slog.Logger,
+) *Worker {
+	return &Worker{
+		storage:       storage,
+		telegramBot:   telegramBot,
+		admins:        admins,
+		manualPayment: manualPayment,
+		logger:        logger,
+		cron:          cron.New(),
+	}
+}
+
+// Name returns the worker name
+func (w *Worker) Name() string {
+	return "stuck-payments"
+}
+
+// Start starts the stuck payments worker
+func (w *Worker) Start() error {
+	// В ручном режиме платежи сразу approved - следить не за чем
+	if w.manualPayment {
+		w.logger.Info("Manual payment mode enabled, skipping stuck payments worker")
+		return nil
+	}
+
+	// Runs every hour
+	_, err := w.cron.AddFunc("0 * * * *", func() {
+		defer func() {
+			if r := recover(); r != nil {
+				w.logger.Error("Panic in stuck payments worker", "panic", r)
+			}
+		}()
+		ctx := context.Background()
+		if err := w.run(ctx); err != nil {
+			w.logger.Error("Stuck payments worker failed", "error", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule stuck payments worker: %w", err)
+	}
+
+	w.cron.Start()
+	return nil
+}
+
+// Stop stops the worker
+func (w *Worker) Stop() {
+	w.logger.Info("Stopping stuck payments worker")
+	w.cron.Stop()
+}
+
+// RunNow runs the worker immediately (for manual testing)
+func (w *Worker) RunNow(ctx context.Context) error {
+	w.logger.Info("Manual run of stuck payments worker")
+	return w.run(ctx)
+}
+
+// run checks pending payments and notifies admins
+func (w *Worker) run(ctx context.Context) error {
+	stuck, err := w.storage.ListPendingPaymentsCreatedBefore(ctx, time.Now().UTC().Add(-stuckThreshold))
+	if err != nil {
+		return fmt.Errorf("list stuck payments: %w", err)
+	}
+
+	pendingCount, err := w.storage.CountPendingPayments(ctx)
+	if err != nil {
+		return fmt.Errorf("count pending payments: %w", err)
+	}
+
+	if len(stuck) == 0 && pendingCount < backlogThreshold {
+		return nil
+	}
+
+	w.logger.Warn("Stuck payments detected", "stuck", len(stuck), "pending_total", pendingCount)
+
+	text, keyboard := buildAlert(stuck, pendingCount)
+	for _, adminID := range w.admins.AdminIDs() {
+		msg := tgbotapi.NewMessage(adminID, text)
+		msg.ParseMode = "Markdown"
+		if keyboard != nil {
+			msg.ReplyMarkup = *keyboard
+		}
+		if _, err := w.telegramBot.Send(msg); err != nil {
+			w.logger.Error("Failed to send stuck payments alert", "admin_id", adminID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// buildAlert формирует текст алерта и кнопки быстрых действий
+func buildAlert(stuck []*payment.Payment, pendingCount int) (string, *tgbotapi.InlineKeyboardMarkup) {
+	var sb strings.Builder
+	sb.WriteString("⚠️ *Зависшие платежи*\n\n")
+	sb.WriteString(fmt.Sprintf("В ожидании всего: %d\n", pendingCount))
+	if pendingCount >= backlogThreshold {
+		sb.WriteString(fmt.Sprintf("Очередь превышает порог (%d)\n", backlogThreshold))
+	}
+	sb.WriteString(fmt.Sprintf("Старше %d ч: %d\n", int(stuckThreshold.Hours()), len(stuck)))
+
+	if len(stuck) == 0 {
+		return sb.String(), nil
+	}
+
+	sb.WriteString("\n")
+	var rows [][]tgbotapi.InlineKeyboardButton
+	now := time.Now().UTC()
+	for i, p := range stuck {
+		if i >= maxListedPayments {
+			sb.WriteString(fmt.Sprintf("…и ещё %d\n", len(stuck)-maxListedPayments))
+			break
+		}
+		age := now.Sub(p.CreatedAt)
+		sb.WriteString(fmt.Sprintf("#%d — %.0f ₽, висит %d ч\n", p.ID, p.Amount, int(age.Hours())))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🔄 #%d", p.ID), fmt.Sprintf("spay_check:%d", p.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("❌ #%d", p.ID), fmt.Sprintf("spay_cancel:%d", p.ID)),
+		))
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return sb.String(), &keyboard
+}
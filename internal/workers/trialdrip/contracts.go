@@ -0,0 +1,28 @@
+package trialdrip
+
+import (
+	"context"
+	"time"
+
+	"kurut-bot/internal/stories/drip"
+	"kurut-bot/internal/stories/subs"
+)
+
+type (
+	// Storage provides trial subscriptions and sent drip steps
+	Storage interface {
+		ListDripCandidates(ctx context.Context, createdAfter time.Time) ([]*subs.Subscription, error)
+		ListSentDripSteps(ctx context.Context, subscriptionIDs []int64) (map[int64]map[drip.Step]bool, error)
+		CreateDripMessage(ctx context.Context, msg drip.Message) error
+	}
+
+	// Notifier sends the drip step card to the assistant
+	Notifier interface {
+		SendStepMessage(ctx context.Context, chatID int64, sub *subs.Subscription, step drip.Step) error
+	}
+
+	// AdminChats - куда слать служебные уведомления: админская группа или текущие админы
+	AdminChats interface {
+		AdminChatIDs() []int64
+	}
+)
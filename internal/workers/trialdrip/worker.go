@@ -0,0 +1,154 @@
+package trialdrip
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"kurut-bot/internal/stories/drip"
+	"kurut-bot/internal/stories/subs"
+
+	"github.com/robfig/cron/v3"
+)
+
+// lookbackWindow - насколько старые пробные подписки проверяем; покрывает пробный период и окно win-back
+const lookbackWindow = 60 * 24 * time.Hour
+
+// Worker ведет рассылку клиентам на пробном периоде: на 2-й день, в последний день и после окончания
+// отправляет ассистенту карточку с готовым сообщением клиенту в WhatsApp
+type Worker struct {
+	storage    Storage
+	notifier   Notifier
+	adminChats AdminChats
+	logger     *slog.Logger
+	cron       *cron.Cron
+}
+
+// NewWorker creates a new trial drip worker; adminChats receive cards for subscriptions without a creator
+func NewWorker(
+	storage Storage,
+	notifier Notifier,
+	adminChats AdminChats,
+	logger *slog.Logger,
+) *Worker {
+	return &Worker{
+		storage:    storage,
+		notifier:   notifier,
+		adminChats: adminChats,
+		logger:     logger,
+		cron:       cron.New(),
+	}
+}
+
+// Name returns the worker name
+func (w *Worker) Name() string {
+	return "trial-drip"
+}
+
+// Start starts the trial drip worker
+func (w *Worker) Start() error {
+	// Runs daily at 11:00 - днем, когда клиенту удобно ответить.
+	// Окна этапов по 24 часа, поэтому каждый этап попадает ровно в один запуск
+	_, err := w.cron.AddFunc("0 11 * * *", func() {
+		defer func() {
+			if r := recover(); r != nil {
+				w.logger.Error("Panic in trial drip worker", "panic", r)
+			}
+		}()
+		ctx := context.Background()
+		if err := w.run(ctx); err != nil {
+			w.logger.Error("Trial drip worker failed", "error", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule trial drip worker: %w", err)
+	}
+
+	w.cron.Start()
+	return nil
+}
+
+// Stop stops the worker
+func (w *Worker) Stop() {
+	w.logger.Info("Stopping trial drip worker")
+	w.cron.Stop()
+}
+
+// RunNow runs the worker immediately (for manual testing)
+func (w *Worker) RunNow(ctx context.Context) error {
+	w.logger.Info("Manual run of trial drip worker")
+	return w.run(ctx)
+}
+
+// run sends due drip steps for trial subscriptions
+func (w *Worker) run(ctx context.Context) error {
+	now := time.Now().UTC()
+	candidates, err := w.storage.ListDripCandidates(ctx, now.Add(-lookbackWindow))
+	if err != nil {
+		return fmt.Errorf("list drip candidates: %w", err)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(candidates))
+	for _, sub := range candidates {
+		ids = append(ids, sub.ID)
+	}
+	sent, err := w.storage.ListSentDripSteps(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("list sent drip steps: %w", err)
+	}
+
+	var sentCount int
+	for _, sub := range candidates {
+		step := drip.DueStep(sub.CreatedAt, sub.ExpiresAt, now, sent[sub.ID])
+		if step == "" {
+			continue
+		}
+		if w.sendStep(ctx, sub, step) {
+			sentCount++
+		}
+	}
+
+	w.logger.Info("Trial drip completed", "candidates", len(candidates), "sent", sentCount)
+	return nil
+}
+
+// sendStep отправляет карточку создателю подписки (без создателя - админам) и записывает этап
+func (w *Worker) sendStep(ctx context.Context, sub *subs.Subscription, step drip.Step) bool {
+	recipients := w.adminChats.AdminChatIDs()
+	if sub.CreatedByTelegramID != nil {
+		recipients = []int64{*sub.CreatedByTelegramID}
+	}
+
+	var delivered bool
+	for _, chatID := range recipients {
+		if err := w.notifier.SendStepMessage(ctx, chatID, sub, step); err != nil {
+			w.logger.Error("Failed to send trial drip message",
+				"subscription_id", sub.ID, "step", step, "chat_id", chatID, "error", err)
+			continue
+		}
+		delivered = true
+	}
+	if !delivered {
+		// Не записываем - попробуем в следующий запуск, если окно этапа еще открыто
+		return false
+	}
+
+	var assistantID int64
+	if sub.CreatedByTelegramID != nil {
+		assistantID = *sub.CreatedByTelegramID
+	}
+	err := w.storage.CreateDripMessage(ctx, drip.Message{
+		SubscriptionID:      sub.ID,
+		ClientWhatsApp:      *sub.ClientWhatsApp,
+		Step:                step,
+		AssistantTelegramID: assistantID,
+	})
+	if err != nil {
+		w.logger.Error("Failed to record trial drip message", "subscription_id", sub.ID, "step", step, "error", err)
+	}
+	return true
+}
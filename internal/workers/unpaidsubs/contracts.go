@@ -0,0 +1,29 @@
+package unpaidsubs
+
+import (
+	"context"
+	"time"
+
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/unpaidsubs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type (
+	// Storage provides access to subscriptions without payment
+	Storage interface {
+		ListUnpaidSubscriptions(ctx context.Context, createdAfter, createdBefore time.Time) ([]*subs.Subscription, error)
+		SetUnpaidSubscriptionAlertStatus(ctx context.Context, subscriptionID int64, status unpaidsubs.AlertStatus) error
+	}
+
+	// TelegramBot provides telegram messaging
+	TelegramBot interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	}
+
+	// Admins - текущие админы: из конфига и назначенные через /set_role
+	Admins interface {
+		AdminIDs() []int64
+	}
+)
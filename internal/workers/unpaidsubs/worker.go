@@ -0,0 +1,152 @@
+package unpaidsubs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/stories/subs"
+	"kurut-bot/internal/stories/unpaidsubs"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// paymentGracePeriod - сколько после создания подписки ждем привязки оплаченного платежа
+	paymentGracePeriod = time.Hour
+	// lookbackWindow - насколько старые подписки проверяем (чтобы не поднимать исторические данные)
+	lookbackWindow = 7 * 24 * time.Hour
+	// maxListedSubscriptions - сколько подписок показывать в одном сообщении, остальные уйдут в следующий запуск
+	maxListedSubscriptions = 10
+)
+
+// Worker alerts admins about subscriptions on paid tariffs that have no approved payment
+type Worker struct {
+	storage     Storage
+	telegramBot TelegramBot
+	admins      Admins
+	logger      *slog.Logger
+	cron        *cron.Cron
+}
+
+// NewWorker creates a new unpaid subscriptions worker
+func NewWorker(
+	storage Storage,
+	telegramBot TelegramBot,
+	admins Admins,
+	logger *slog.Logger,
+) *Worker {
+	return &Worker{
+		storage:     storage,
+		telegramBot: telegramBot,
+		admins:      admins,
+		logger:      logger,
+		cron:        cron.New(),
+	}
+}
+
+// Name returns the worker name
+func (w *Worker) Name() string {
+	return "unpaid-subscriptions"
+}
+
+// Start starts the unpaid subscriptions worker
+func (w *Worker) Start() error {
+	// Runs every hour at :30, чтобы не пересекаться с алертом о зависших платежах
+	_, err := w.cron.AddFunc("30 * * * *", func() {
+		defer func() {
+			if r := recover(); r != nil {
+				w.logger.Error("Panic in unpaid subscriptions worker", "panic", r)
+			}
+		}()
+		ctx := context.Background()
+		if err := w.run(ctx); err != nil {
+			w.logger.Error("Unpaid subscriptions worker failed", "error", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule unpaid subscriptions worker: %w", err)
+	}
+
+	w.cron.Start()
+	return nil
+}
+
+// Stop stops the worker
+func (w *Worker) Stop() {
+	w.logger.Info("Stopping unpaid subscriptions worker")
+	w.cron.Stop()
+}
+
+// RunNow runs the worker immediately (for manual testing)
+func (w *Worker) RunNow(ctx context.Context) error {
+	w.logger.Info("Manual run of unpaid subscriptions worker")
+	return w.run(ctx)
+}
+
+// run finds subscriptions without approved payment and notifies admins
+func (w *Worker) run(ctx context.Context) error {
+	now := time.Now().UTC()
+	unpaid, err := w.storage.ListUnpaidSubscriptions(ctx, now.Add(-lookbackWindow), now.Add(-paymentGracePeriod))
+	if err != nil {
+		return fmt.Errorf("list unpaid subscriptions: %w", err)
+	}
+	if len(unpaid) == 0 {
+		return nil
+	}
+	if len(unpaid) > maxListedSubscriptions {
+		unpaid = unpaid[:maxListedSubscriptions]
+	}
+
+	w.logger.Warn("Subscriptions without payment detected", "count", len(unpaid))
+
+	text, keyboard := buildAlert(unpaid, now)
+	for _, adminID := range w.admins.AdminIDs() {
+		msg := tgbotapi.NewMessage(adminID, text)
+		msg.ParseMode = "Markdown"
+		msg.ReplyMarkup = keyboard
+		if _, err := w.telegramBot.Send(msg); err != nil {
+			w.logger.Error("Failed to send unpaid subscriptions alert", "admin_id", adminID, "error", err)
+		}
+	}
+
+	// Помечаем, чтобы не алертить повторно каждый час
+	for _, sub := range unpaid {
+		if err := w.storage.SetUnpaidSubscriptionAlertStatus(ctx, sub.ID, unpaidsubs.AlertStatusAlerted); err != nil {
+			w.logger.Error("Failed to mark unpaid subscription as alerted", "subscription_id", sub.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// buildAlert формирует текст алерта и кнопки "Проверить"/"Игнорировать"
+func buildAlert(unpaid []*subs.Subscription, now time.Time) (string, tgbotapi.InlineKeyboardMarkup) {
+	var sb strings.Builder
+	sb.WriteString("🚨 *Подписки без оплаты*\n\n")
+	sb.WriteString(fmt.Sprintf("Платный тариф, но нет оплаченного платежа спустя %d ч после создания:\n\n", int(paymentGracePeriod.Hours())))
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(unpaid))
+	for _, sub := range unpaid {
+		client := "—"
+		if sub.ClientWhatsApp != nil {
+			client = *sub.ClientWhatsApp
+		}
+		creator := "—"
+		if sub.CreatedByTelegramID != nil {
+			creator = fmt.Sprintf("%d", *sub.CreatedByTelegramID)
+		}
+		age := now.Sub(sub.CreatedAt)
+		sb.WriteString(fmt.Sprintf("#%d — клиент %s, создал %s, %d ч назад\n", sub.ID, client, creator, int(age.Hours())))
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🔍 #%d", sub.ID), fmt.Sprintf("usub_inv:%d", sub.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🙈 #%d", sub.ID), fmt.Sprintf("usub_ign:%d", sub.ID)),
+		))
+	}
+
+	return sb.String(), tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,28 @@
+package waitlist
+
+import (
+	"context"
+	"time"
+
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/stories/waitlist"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type (
+	// Storage provides server capacity and waitlist operations
+	Storage interface {
+		ListServers(ctx context.Context, criteria servers.ListCriteria) ([]*servers.Server, error)
+		GetActiveUsersCountByServer(ctx context.Context, serverID int64) (int, error)
+		GetTariff(ctx context.Context, criteria tariffs.GetCriteria) (*tariffs.Tariff, error)
+		ListWaitlistEntries(ctx context.Context, criteria waitlist.ListCriteria) ([]*waitlist.Entry, error)
+		MarkWaitlistEntryNotified(ctx context.Context, id int64, notifiedAt, priceHeldUntil time.Time) error
+	}
+
+	// TelegramBot provides telegram messaging
+	TelegramBot interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	}
+)
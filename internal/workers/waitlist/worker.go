@@ -0,0 +1,236 @@
+package waitlist
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"kurut-bot/internal/stories/servers"
+	"kurut-bot/internal/stories/tariffs"
+	"kurut-bot/internal/stories/waitlist"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/robfig/cron/v3"
+)
+
+// Worker notifies assistants when a server slot frees up for a client in the waiting list
+type Worker struct {
+	storage     Storage
+	telegramBot TelegramBot
+	logger      *slog.Logger
+	cron        *cron.Cron
+}
+
+// NewWorker creates a new waitlist worker
+func NewWorker(storage Storage, telegramBot TelegramBot, logger *slog.Logger) *Worker {
+	return &Worker{
+		storage:     storage,
+		telegramBot: telegramBot,
+		logger:      logger,
+		cron:        cron.New(),
+	}
+}
+
+// Name returns the worker name
+func (w *Worker) Name() string {
+	return "waitlist"
+}
+
+// Start starts the waitlist worker
+func (w *Worker) Start() error {
+	// Runs every 10 minutes: места освобождаются при отключении подписок и при добавлении сервера
+	_, err := w.cron.AddFunc("*/10 * * * *", func() {
+		defer func() {
+			if r := recover(); r != nil {
+				w.logger.Error("Panic in waitlist worker", "panic", r)
+			}
+		}()
+		ctx := context.Background()
+		if err := w.run(ctx); err != nil {
+			w.logger.Error("Waitlist worker failed", "error", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule waitlist worker: %w", err)
+	}
+
+	w.cron.Start()
+	return nil
+}
+
+// Stop stops the worker
+func (w *Worker) Stop() {
+	w.logger.Info("Stopping waitlist worker")
+	w.cron.Stop()
+}
+
+// RunNow runs the worker immediately (for manual testing)
+func (w *Worker) RunNow(ctx context.Context) error {
+	w.logger.Info("Manual run of waitlist worker")
+	return w.run(ctx)
+}
+
+// run notifies the oldest waiting entries, one per free slot in the cluster of the entry's tariff
+func (w *Worker) run(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	list, err := w.serversLoad(ctx)
+	if err != nil {
+		return err
+	}
+
+	clusters := newTariffClusters(w.storage)
+
+	// Места, обещанные уведомленным записям, занимают слоты кластера их тарифа
+	notified, err := w.storage.ListWaitlistEntries(ctx, waitlist.ListCriteria{
+		Statuses: []waitlist.Status{waitlist.StatusNotified},
+	})
+	if err != nil {
+		return fmt.Errorf("list notified waitlist entries: %w", err)
+	}
+	held := make(map[string]int)
+	for _, entry := range notified {
+		if !entry.IsPriceHeld(now) {
+			continue
+		}
+		cluster, err := clusters.get(ctx, entry.TariffID)
+		if err != nil {
+			return err
+		}
+		held[cluster]++
+	}
+
+	entries, err := w.storage.ListWaitlistEntries(ctx, waitlist.ListCriteria{
+		Statuses: []waitlist.Status{waitlist.StatusWaiting},
+	})
+	if err != nil {
+		return fmt.Errorf("list waitlist entries: %w", err)
+	}
+
+	// slots - оставшиеся свободные места по кластерам, считаются при первой записи кластера
+	slots := make(map[string]int)
+	sent := 0
+	for _, entry := range entries {
+		cluster, err := clusters.get(ctx, entry.TariffID)
+		if err != nil {
+			return err
+		}
+		if _, ok := slots[cluster]; !ok {
+			capacity, active := list.load(cluster)
+			slots[cluster] = waitlist.FreeSlots(capacity, active, held[cluster])
+		}
+		if slots[cluster] == 0 {
+			continue
+		}
+
+		heldUntil := now.Add(waitlist.PriceHoldDuration)
+		if _, err := w.telegramBot.Send(buildNotification(entry, heldUntil)); err != nil {
+			w.logger.Error("Failed to send waitlist notification", "entry_id", entry.ID, "chat_id", entry.ChatID, "error", err)
+			continue
+		}
+		if err := w.storage.MarkWaitlistEntryNotified(ctx, entry.ID, now, heldUntil); err != nil {
+			w.logger.Error("Failed to mark waitlist entry notified", "entry_id", entry.ID, "error", err)
+		}
+		slots[cluster]--
+		sent++
+	}
+
+	if sent > 0 {
+		w.logger.Info("Waitlist notifications sent", "count", sent)
+	}
+	return nil
+}
+
+// serverLoad - емкость неархивного сервера и число активных подписок на нем
+type serverLoad struct {
+	server *servers.Server
+	active int
+}
+
+type serversLoad []serverLoad
+
+// load возвращает суммарную емкость и число активных подписок на серверах, подходящих тарифу кластера cluster
+func (l serversLoad) load(cluster string) (capacity, active int) {
+	for _, s := range l {
+		if !s.server.FitsCluster(cluster) {
+			continue
+		}
+		capacity += s.server.MaxUsers
+		// Переполненный сервер не должен съедать свободные места других серверов
+		active += min(s.active, s.server.MaxUsers)
+	}
+	return capacity, active
+}
+
+// serversLoad возвращает неархивные серверы с числом активных подписок на них
+func (w *Worker) serversLoad(ctx context.Context) (serversLoad, error) {
+	archived := false
+	list, err := w.storage.ListServers(ctx, servers.ListCriteria{Archived: &archived})
+	if err != nil {
+		return nil, fmt.Errorf("list servers: %w", err)
+	}
+
+	result := make(serversLoad, 0, len(list))
+	for _, srv := range list {
+		count, err := w.storage.GetActiveUsersCountByServer(ctx, srv.ID)
+		if err != nil {
+			return nil, fmt.Errorf("count active users on server %d: %w", srv.ID, err)
+		}
+		result = append(result, serverLoad{server: srv, active: count})
+	}
+
+	return result, nil
+}
+
+// tariffClusters кэширует кластер тарифа на время одного прогона
+type tariffClusters struct {
+	storage Storage
+	cache   map[int64]string
+}
+
+func newTariffClusters(storage Storage) *tariffClusters {
+	return &tariffClusters{storage: storage, cache: make(map[int64]string)}
+}
+
+// get возвращает кластер тарифа; удаленный тариф считается тарифом без кластера
+func (c *tariffClusters) get(ctx context.Context, tariffID int64) (string, error) {
+	if cluster, ok := c.cache[tariffID]; ok {
+		return cluster, nil
+	}
+	tariff, err := c.storage.GetTariff(ctx, tariffs.GetCriteria{ID: &tariffID})
+	if err != nil {
+		return "", fmt.Errorf("get tariff %d: %w", tariffID, err)
+	}
+	var cluster string
+	if tariff != nil {
+		cluster = tariff.Cluster
+	}
+	c.cache[tariffID] = cluster
+	return cluster, nil
+}
+
+// buildNotification формирует уведомление ассистенту о свободном месте
+func buildNotification(entry *waitlist.Entry, heldUntil time.Time) tgbotapi.MessageConfig {
+	text := fmt.Sprintf(
+		"🟢 *Освободилось место на сервере*\n\n"+
+			"📱 Клиент: `%s`\n"+
+			"📅 Тариф: %s\n"+
+			"💰 Цена %.2f ₽ сохранена до %s\n\n"+
+			"Создайте заказ, пока место не заняли.",
+		entry.ClientWhatsApp, entry.TariffName, entry.HeldPrice, heldUntil.Local().Format("02.01.2006 15:04"))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Создать заказ", fmt.Sprintf("wl_buy:%d", entry.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Убрать из очереди", fmt.Sprintf("wl_cancel:%d", entry.ID)),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(entry.ChatID, text)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	return msg
+}
@@ -0,0 +1,32 @@
+package waitlist
+
+import (
+	"testing"
+
+	"kurut-bot/internal/stories/servers"
+)
+
+func TestServersLoadByCluster(t *testing.T) {
+	list := serversLoad{
+		{server: &servers.Server{ID: 1, MaxUsers: 100}, active: 100},
+		{server: &servers.Server{ID: 2, MaxUsers: 50, Cluster: "Premium"}, active: 40},
+		{server: &servers.Server{ID: 3, MaxUsers: 20, Cluster: "EU"}, active: 25},
+	}
+
+	tests := []struct {
+		cluster        string
+		capacity, used int
+	}{
+		{cluster: "", capacity: 170, used: 160},
+		{cluster: "Premium", capacity: 50, used: 40},
+		{cluster: "EU", capacity: 20, used: 20},
+		{cluster: "Asia", capacity: 0, used: 0},
+	}
+
+	for _, tt := range tests {
+		capacity, active := list.load(tt.cluster)
+		if capacity != tt.capacity || active != tt.used {
+			t.Errorf("load(%q) = %d, %d, want %d, %d", tt.cluster, capacity, active, tt.capacity, tt.used)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package weeklyreport
+
+import (
+	"context"
+	"time"
+
+	"kurut-bot/internal/storage"
+	"kurut-bot/internal/stories/heatmap"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type (
+	// Storage provides data for the weekly report and the monthly summary
+	Storage interface {
+		GetWeekdayHeatmap(ctx context.Context) (*heatmap.WeekdayHeatmap, error)
+		GetRevenueReport(ctx context.Context, from, to time.Time) (*storage.RevenueReport, error)
+		GetRefundsSummary(ctx context.Context, from, to time.Time) (*storage.RefundsSummary, error)
+	}
+
+	// TelegramBot provides telegram messaging and assistants' names for the summary
+	TelegramBot interface {
+		Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+		GetChat(config tgbotapi.ChatInfoConfig) (tgbotapi.Chat, error)
+	}
+
+	// Admins - текущие админы: из конфига и назначенные через /set_role
+	Admins interface {
+		AdminIDs() []int64
+	}
+)
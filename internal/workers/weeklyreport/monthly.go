@@ -0,0 +1,183 @@
+package weeklyreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"kurut-bot/internal/infra/telegram"
+	"kurut-bot/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// topAssistantsLimit - сколько ассистентов показывать в месячной сводке
+const topAssistantsLimit = 5
+
+// MonthlySummary - финансовая сводка за месяц для владельца бизнеса
+type MonthlySummary struct {
+	Month          time.Time
+	Revenue        *storage.RevenueReport
+	Refunds        *storage.RefundsSummary
+	FeePercent     float64          // комиссия платежного провайдера, %
+	AssistantNames map[int64]string // имена ассистентов по Telegram ID
+}
+
+// ProviderFee - оценка комиссии провайдера: процент от всей выручки месяца
+func (s *MonthlySummary) ProviderFee() float64 {
+	return s.Revenue.Total * s.FeePercent / 100
+}
+
+// Net - выручка за вычетом возвратов и комиссии провайдера
+func (s *MonthlySummary) Net() float64 {
+	return s.Revenue.Total - s.Refunds.Amount - s.ProviderFee()
+}
+
+// TopAssistants - ассистенты с наибольшей выручкой, без подписок с неизвестным создателем
+func (s *MonthlySummary) TopAssistants() []storage.RevenueByAssistant {
+	var top []storage.RevenueByAssistant
+	for _, row := range s.Revenue.ByAssistant {
+		if row.AssistantTelegramID == nil {
+			continue
+		}
+		top = append(top, row)
+		if len(top) == topAssistantsLimit {
+			break
+		}
+	}
+	return top
+}
+
+// Text - сводка в Markdown для сообщения
+func (s *MonthlySummary) Text() string {
+	var text strings.Builder
+
+	fmt.Fprintf(&text, "📊 *Финансовая сводка за %s*\n\n", s.Month.Format("01.2006"))
+	fmt.Fprintf(&text, "*Выручка:* %.2f ₽ (платежей: %d)\n", s.Revenue.Total, s.Revenue.PaymentsCount)
+	fmt.Fprintf(&text, "*Возвраты:* %.2f ₽ (%d)\n", s.Refunds.Amount, s.Refunds.Count)
+	fmt.Fprintf(&text, "*Комиссия провайдера:* ~%.2f ₽ (%.1f%%)\n", s.ProviderFee(), s.FeePercent)
+	fmt.Fprintf(&text, "*Итого:* %.2f ₽\n", s.Net())
+
+	if len(s.Revenue.ByTariff) > 0 {
+		text.WriteString("\n*По тарифам:*\n")
+		for _, row := range s.Revenue.ByTariff {
+			fmt.Fprintf(&text, "• %s: *%.2f ₽* (%d)\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, tariffName(row)), row.Amount, row.PaymentsCount)
+		}
+	}
+
+	if top := s.TopAssistants(); len(top) > 0 {
+		text.WriteString("\n*Лучшие ассистенты:*\n")
+		for i, row := range top {
+			name := tgbotapi.EscapeText(tgbotapi.ModeMarkdown, s.assistantName(*row.AssistantTelegramID))
+			fmt.Fprintf(&text, "%d. %s: *%.2f ₽* (%d)\n", i+1, name, row.Amount, row.PaymentsCount)
+		}
+	}
+
+	return text.String()
+}
+
+// CSV - та же сводка построчно для бухгалтерии: раздел, позиция, сумма, количество
+func (s *MonthlySummary) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	records := [][]string{
+		{"section", "item", "amount", "count"},
+		{"total", "revenue", formatAmount(s.Revenue.Total), strconv.Itoa(s.Revenue.PaymentsCount)},
+		{"total", "refunds", formatAmount(s.Refunds.Amount), strconv.Itoa(s.Refunds.Count)},
+		{"total", "provider_fee_estimate", formatAmount(s.ProviderFee()), ""},
+		{"total", "net", formatAmount(s.Net()), ""},
+	}
+	for _, row := range s.Revenue.ByTariff {
+		records = append(records, []string{"tariff", tariffName(row), formatAmount(row.Amount), strconv.Itoa(row.PaymentsCount)})
+	}
+	for _, row := range s.TopAssistants() {
+		records = append(records, []string{"assistant", s.assistantName(*row.AssistantTelegramID), formatAmount(row.Amount), strconv.Itoa(row.PaymentsCount)})
+	}
+
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *MonthlySummary) assistantName(telegramID int64) string {
+	if name, ok := s.AssistantNames[telegramID]; ok {
+		return name
+	}
+	return strconv.FormatInt(telegramID, 10)
+}
+
+func tariffName(row storage.RevenueByTariff) string {
+	if row.TariffID == nil {
+		return "без подписки"
+	}
+	if row.TariffName == "" {
+		return fmt.Sprintf("тариф #%d", *row.TariffID)
+	}
+	return row.TariffName
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// previousMonth - начало прошлого месяца: сводка 1-го числа подводит итоги закрытого месяца
+func previousMonth(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+}
+
+// runMonthly собирает сводку за прошлый месяц и отправляет ее владельцам: текстом и CSV файлом
+func (w *Worker) runMonthly(ctx context.Context) error {
+	month := previousMonth(time.Now().UTC())
+	to := month.AddDate(0, 1, 0)
+
+	revenue, err := w.storage.GetRevenueReport(ctx, month, to)
+	if err != nil {
+		return fmt.Errorf("get revenue report: %w", err)
+	}
+	refunds, err := w.storage.GetRefundsSummary(ctx, month, to)
+	if err != nil {
+		return fmt.Errorf("get refunds summary: %w", err)
+	}
+
+	summary := &MonthlySummary{
+		Month:          month,
+		Revenue:        revenue,
+		Refunds:        refunds,
+		FeePercent:     w.providerFeePercent,
+		AssistantNames: make(map[int64]string),
+	}
+	for _, row := range summary.TopAssistants() {
+		summary.AssistantNames[*row.AssistantTelegramID] = telegram.UserName(w.telegramBot, *row.AssistantTelegramID)
+	}
+
+	data, err := summary.CSV()
+	if err != nil {
+		return fmt.Errorf("build monthly csv: %w", err)
+	}
+
+	text := summary.Text()
+	for _, ownerID := range w.ownerIDs {
+		msg := tgbotapi.NewMessage(ownerID, text)
+		msg.ParseMode = "Markdown"
+		if _, err := w.telegramBot.Send(msg); err != nil {
+			w.logger.Error("Failed to send monthly summary", "owner_id", ownerID, "error", err)
+			continue
+		}
+
+		doc := tgbotapi.NewDocument(ownerID, tgbotapi.FileBytes{
+			Name:  fmt.Sprintf("summary-%s.csv", month.Format("2006-01")),
+			Bytes: data,
+		})
+		if _, err := w.telegramBot.Send(doc); err != nil {
+			w.logger.Error("Failed to send monthly summary csv", "owner_id", ownerID, "error", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package weeklyreport
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"kurut-bot/internal/storage"
+)
+
+func TestMonthlySummary(t *testing.T) {
+	tariffID := int64(1)
+	assistantA, assistantB := int64(100), int64(200)
+	summary := &MonthlySummary{
+		Month: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		Revenue: &storage.RevenueReport{
+			Total:         1000,
+			PaymentsCount: 4,
+			ByTariff: []storage.RevenueByTariff{
+				{TariffID: &tariffID, TariffName: "Месяц", Amount: 900, PaymentsCount: 3},
+				{Amount: 100, PaymentsCount: 1},
+			},
+			ByAssistant: []storage.RevenueByAssistant{
+				{AssistantTelegramID: &assistantA, Amount: 600, PaymentsCount: 2},
+				{Amount: 300, PaymentsCount: 1},
+				{AssistantTelegramID: &assistantB, Amount: 100, PaymentsCount: 1},
+			},
+		},
+		Refunds:        &storage.RefundsSummary{Amount: 150, Count: 1},
+		FeePercent:     3.5,
+		AssistantNames: map[int64]string{assistantA: "@anna"},
+	}
+
+	if got := summary.ProviderFee(); got != 35 {
+		t.Errorf("ProviderFee() = %v, want 35", got)
+	}
+	if got := summary.Net(); got != 815 {
+		t.Errorf("Net() = %v, want 815", got)
+	}
+
+	data, err := summary.CSV()
+	if err != nil {
+		t.Fatalf("CSV() error = %v", err)
+	}
+	want := strings.Join([]string{
+		"section,item,amount,count",
+		"total,revenue,1000.00,4",
+		"total,refunds,150.00,1",
+		"total,provider_fee_estimate,35.00,",
+		"total,net,815.00,",
+		"tariff,Месяц,900.00,3",
+		"tariff,без подписки,100.00,1",
+		"assistant,@anna,600.00,2",
+		"assistant,200,100.00,1",
+	}, "\n") + "\n"
+	if string(data) != want {
+		t.Errorf("CSV() =\n%s\nwant\n%s", data, want)
+	}
+
+	text := summary.Text()
+	for _, part := range []string{"09.2026", "*Итого:* 815.00 ₽", "1. @anna", "2. 200"} {
+		if !strings.Contains(text, part) {
+			t.Errorf("Text() does not contain %q:\n%s", part, text)
+		}
+	}
+}
+
+func TestPreviousMonth(t *testing.T) {
+	got := previousMonth(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	if want := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("previousMonth() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package weeklyreport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/robfig/cron/v3"
+)
+
+// Worker sends admins a weekly report to plan assistants' coverage for the coming week
+// and owners a monthly financial summary on the 1st of each month
+type Worker struct {
+	storage            Storage
+	telegramBot        TelegramBot
+	admins             Admins
+	ownerIDs           []int64
+	providerFeePercent float64
+	logger             *slog.Logger
+	cron               *cron.Cron
+}
+
+// NewWorker creates a new weekly report worker
+func NewWorker(
+	storage Storage,
+	telegramBot TelegramBot,
+	admins Admins,
+	ownerIDs []int64,
+	providerFeePercent float64,
+	logger *slog.Logger,
+) *Worker {
+	return &Worker{
+		storage:            storage,
+		telegramBot:        telegramBot,
+		admins:             admins,
+		ownerIDs:           ownerIDs,
+		providerFeePercent: providerFeePercent,
+		logger:             logger,
+		cron:               cron.New(),
+	}
+}
+
+// Name returns the worker name
+func (w *Worker) Name() string {
+	return "weekly-report"
+}
+
+// Start starts the weekly report worker
+func (w *Worker) Start() error {
+	// Runs every Monday at 09:00, до начала рабочей недели ассистентов
+	_, err := w.cron.AddFunc("0 9 * * 1", func() {
+		defer func() {
+			if r := recover(); r != nil {
+				w.logger.Error("Panic in weekly report worker", "panic", r)
+			}
+		}()
+		ctx := context.Background()
+		if err := w.run(ctx); err != nil {
+			w.logger.Error("Weekly report worker failed", "error", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule weekly report worker: %w", err)
+	}
+
+	// Runs on the 1st of each month at 09:00 with the summary for the previous month
+	_, err = w.cron.AddFunc("0 9 1 * *", func() {
+		defer func() {
+			if r := recover(); r != nil {
+				w.logger.Error("Panic in monthly summary", "panic", r)
+			}
+		}()
+		ctx := context.Background()
+		if err := w.runMonthly(ctx); err != nil {
+			w.logger.Error("Monthly summary failed", "error", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule monthly summary: %w", err)
+	}
+
+	w.cron.Start()
+	return nil
+}
+
+// Stop stops the worker
+func (w *Worker) Stop() {
+	w.logger.Info("Stopping weekly report worker")
+	w.cron.Stop()
+}
+
+// RunNow runs the worker immediately (for manual testing)
+func (w *Worker) RunNow(ctx context.Context) error {
+	w.logger.Info("Manual run of weekly report worker")
+	if err := w.run(ctx); err != nil {
+		return err
+	}
+	return w.runMonthly(ctx)
+}
+
+// run builds the report and sends it to every admin
+func (w *Worker) run(ctx context.Context) error {
+	h, err := w.storage.GetWeekdayHeatmap(ctx)
+	if err != nil {
+		return fmt.Errorf("get weekday heatmap: %w", err)
+	}
+
+	text := "📅 *Еженедельный отчет*\n\n" + h.Text()
+	for _, adminID := range w.admins.AdminIDs() {
+		msg := tgbotapi.NewMessage(adminID, text)
+		msg.ParseMode = "Markdown"
+		if _, err := w.telegramBot.Send(msg); err != nil {
+			w.logger.Error("Failed to send weekly report", "admin_id", adminID, "error", err)
+		}
+	}
+
+	return nil
+}
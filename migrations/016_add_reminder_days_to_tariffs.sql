@@ -0,0 +1,8 @@
+-- +goose Up
+-- JSON массив дней до истечения, за которые отправлять напоминания, например [14,7,1].
+-- NULL - расписание по умолчанию
+ALTER TABLE tariffs
+    ADD COLUMN reminder_days TEXT;
+
+-- +goose Down
+-- SQLite doesn't support DROP COLUMN, so we can't rollback this migration cleanly
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE pending_orders
+    ADD COLUMN target_server_id INTEGER;
+
+-- +goose Down
+ALTER TABLE pending_orders DROP COLUMN target_server_id;
@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE subscriptions
+    ADD COLUMN extra_traffic_gb INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE traffic_topups (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL,
+    payment_id INTEGER NOT NULL,
+    traffic_gb INTEGER NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'applied', 'cancelled')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_traffic_topups_subscription_id ON traffic_topups(subscription_id);
+
+-- +goose Down
+DROP TABLE traffic_topups;
+ALTER TABLE subscriptions DROP COLUMN extra_traffic_gb;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE unpaid_subscription_alerts (
+    subscription_id INTEGER PRIMARY KEY,
+    status TEXT NOT NULL DEFAULT 'alerted' CHECK (status IN ('alerted', 'ignored')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (subscription_id) REFERENCES subscriptions(id)
+);
+
+-- +goose Down
+DROP TABLE unpaid_subscription_alerts;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE assistant_vacations (
+    assistant_telegram_id INTEGER PRIMARY KEY,
+    backup_telegram_id INTEGER,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE assistant_vacations;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE client_languages (
+    client_whatsapp TEXT PRIMARY KEY,
+    language TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE client_languages;
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE subscriptions
+    ADD COLUMN custom_price DECIMAL(10,2);
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN custom_price;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE payment_links (
+    token TEXT PRIMARY KEY,
+    payment_id INTEGER NOT NULL,
+    target_url TEXT NOT NULL,
+    clicks INTEGER NOT NULL DEFAULT 0,
+    last_clicked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payment_links_payment_id ON payment_links(payment_id);
+
+-- +goose Down
+DROP TABLE payment_links;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE link_clicks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    link_type TEXT NOT NULL CHECK (link_type IN ('payment', 'config')),
+    link_key TEXT NOT NULL,
+    payment_id INTEGER,
+    subscription_id INTEGER,
+    order_id INTEGER,
+    user_agent TEXT NOT NULL DEFAULT '',
+    clicked_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_link_clicks_payment_id ON link_clicks(payment_id);
+CREATE INDEX idx_link_clicks_subscription_id ON link_clicks(subscription_id);
+CREATE INDEX idx_link_clicks_order_id ON link_clicks(order_id);
+
+-- +goose Down
+DROP TABLE link_clicks;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE bonus_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    event TEXT NOT NULL,
+    tariff_id INTEGER,
+    min_amount REAL,
+    channel TEXT,
+    action TEXT NOT NULL,
+    bonus_days INTEGER NOT NULL DEFAULT 0,
+    discount_percent INTEGER NOT NULL DEFAULT 0,
+    notify_text TEXT NOT NULL DEFAULT '',
+    is_active INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bonus_rules_trigger ON bonus_rules(event, is_active);
+
+-- Правило, которое раньше было зашито в код: +10 дней пригласившему за первую оплату клиента
+INSERT INTO bonus_rules (name, event, action, bonus_days)
+VALUES ('Реферальный бонус', 'referral_paid', 'bonus_days', 10);
+
+-- +goose Down
+DROP TABLE bonus_rules;
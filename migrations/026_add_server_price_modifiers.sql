@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE servers
+    ADD COLUMN price_multiplier REAL NOT NULL DEFAULT 1;
+ALTER TABLE servers
+    ADD COLUMN price_surcharge DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+-- Разбивка суммы: цена тарифа без наценки сервера и сам сервер
+ALTER TABLE payments
+    ADD COLUMN base_amount DECIMAL(10,2);
+ALTER TABLE payments
+    ADD COLUMN server_id INTEGER REFERENCES servers(id);
+ALTER TABLE pending_orders
+    ADD COLUMN base_amount DECIMAL(10,2);
+
+-- +goose Down
+ALTER TABLE pending_orders DROP COLUMN base_amount;
+ALTER TABLE payments DROP COLUMN server_id;
+ALTER TABLE payments DROP COLUMN base_amount;
+ALTER TABLE servers DROP COLUMN price_surcharge;
+ALTER TABLE servers DROP COLUMN price_multiplier;
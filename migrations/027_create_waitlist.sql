@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE waitlist_entries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    admin_user_id INTEGER NOT NULL,
+    assistant_telegram_id INTEGER NOT NULL,
+    chat_id INTEGER NOT NULL,
+    client_whatsapp TEXT NOT NULL,
+    tariff_id INTEGER NOT NULL REFERENCES tariffs(id),
+    tariff_name TEXT NOT NULL,
+    held_price DECIMAL(10,2) NOT NULL,
+    status TEXT NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting', 'notified', 'fulfilled', 'cancelled')),
+    notified_at TIMESTAMP,
+    price_held_until TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_waitlist_entries_status ON waitlist_entries(status, created_at);
+
+-- +goose Down
+DROP TABLE waitlist_entries;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE pending_orders ADD COLUMN link_refresh_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE pending_orders ADD COLUMN link_refreshed_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE pending_orders DROP COLUMN link_refreshed_at;
+ALTER TABLE pending_orders DROP COLUMN link_refresh_count;
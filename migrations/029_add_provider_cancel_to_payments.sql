@@ -0,0 +1,10 @@
+-- +goose Up
+-- Результат отмены платежа в ЮKassa: cancelled - отменен, failed - ЮKassa отказала (текст в provider_cancel_error)
+ALTER TABLE payments ADD COLUMN provider_cancel_result TEXT;
+ALTER TABLE payments ADD COLUMN provider_cancel_error TEXT;
+ALTER TABLE payments ADD COLUMN provider_cancel_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE payments DROP COLUMN provider_cancel_at;
+ALTER TABLE payments DROP COLUMN provider_cancel_error;
+ALTER TABLE payments DROP COLUMN provider_cancel_result;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Оплата по ссылке уже отмененного заказа: detected - найдена и ждет решения админа,
+-- fulfilled - подписка выдана, refunded - деньги возвращены, credited - сумма зачислена на баланс клиента
+ALTER TABLE payments ADD COLUMN late_status TEXT CHECK (late_status IN ('detected', 'fulfilled', 'refunded', 'credited'));
+ALTER TABLE payments ADD COLUMN late_resolved_at TIMESTAMP;
+
+CREATE TABLE client_balances (
+    client_whatsapp TEXT PRIMARY KEY,
+    amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE client_balances;
+ALTER TABLE payments DROP COLUMN late_resolved_at;
+ALTER TABLE payments DROP COLUMN late_status;
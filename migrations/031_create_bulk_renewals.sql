@@ -0,0 +1,19 @@
+-- +goose Up
+-- Массовое продление: один платеж на несколько подписок (подписки связаны через payment_subscriptions)
+CREATE TABLE bulk_renewals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    payment_id INTEGER NOT NULL UNIQUE REFERENCES payments(id),
+    assistant_telegram_id INTEGER NOT NULL,
+    chat_id INTEGER NOT NULL,
+    message_id INTEGER,
+    total_amount DECIMAL(10,2) NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'cancelled')),
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bulk_renewals_status ON bulk_renewals(status);
+
+-- +goose Down
+DROP TABLE bulk_renewals;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Сессии веб-админки после входа через Telegram Login Widget. Храним только хеш токена из cookie
+CREATE TABLE web_sessions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    token_hash TEXT NOT NULL UNIQUE,
+    telegram_id INTEGER NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_web_sessions_expires_at ON web_sessions(expires_at);
+
+-- +goose Down
+DROP TABLE web_sessions;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Заблокированные пользователи: их апдейты отбрасываются в Router. banned_by NULL - бан выдан автоматически
+CREATE TABLE bans (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    telegram_id INTEGER NOT NULL UNIQUE,
+    reason TEXT NOT NULL DEFAULT '',
+    banned_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE bans;
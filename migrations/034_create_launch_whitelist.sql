@@ -0,0 +1,13 @@
+-- +goose Up
+-- Белый список мягкого запуска: approved = 0 - заявка из листа ожидания, 1 - доступ открыт
+CREATE TABLE launch_whitelist (
+    telegram_id INTEGER PRIMARY KEY,
+    username TEXT NOT NULL DEFAULT '',
+    approved BOOLEAN NOT NULL DEFAULT 0,
+    added_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE launch_whitelist;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Кластеры серверов ("EU", "Asia", "Premium"): у тарифа с кластером подписки выдаются только на серверах этого кластера.
+-- Пустая строка - сервер вне кластеров / тариф без предпочтений
+ALTER TABLE servers ADD COLUMN cluster TEXT NOT NULL DEFAULT '';
+ALTER TABLE tariffs ADD COLUMN cluster TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE tariffs DROP COLUMN cluster;
+ALTER TABLE servers DROP COLUMN cluster;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Платформа устройства клиента (ios, android, windows, router) для инструкций по подключению; пусто - не указана
+ALTER TABLE subscriptions ADD COLUMN client_platform TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN client_platform;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Выплаты комиссии ассистентам. Невыплаченная комиссия считается по оплатам после period_to последней выплаты
+CREATE TABLE commission_settlements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    assistant_telegram_id INTEGER NOT NULL,
+    period_from TIMESTAMP NOT NULL,
+    period_to TIMESTAMP NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    created_by_telegram_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_commission_settlements_assistant ON commission_settlements(assistant_telegram_id, period_to);
+
+-- +goose Down
+DROP TABLE commission_settlements;
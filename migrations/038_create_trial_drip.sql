@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE drip_messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id),
+    client_whatsapp TEXT NOT NULL,
+    step TEXT NOT NULL,
+    assistant_telegram_id INTEGER NOT NULL,
+    sent_at TIMESTAMP NOT NULL,
+    UNIQUE (subscription_id, step)
+);
+
+CREATE INDEX idx_drip_messages_client ON drip_messages(client_whatsapp);
+
+CREATE TABLE drip_optouts (
+    client_whatsapp TEXT PRIMARY KEY,
+    created_by_telegram_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE drip_optouts;
+DROP INDEX idx_drip_messages_client;
+DROP TABLE drip_messages;
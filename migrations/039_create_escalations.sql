@@ -0,0 +1,18 @@
+-- +goose Up
+-- Эскалации: ассистент передает проблему по подписке или заказу админам, админ берет ее и закрывает
+CREATE TABLE escalations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subject_type TEXT NOT NULL CHECK (subject_type IN ('subscription', 'order')),
+    subject_id INTEGER NOT NULL,
+    created_by_telegram_id INTEGER NOT NULL,
+    assignee_telegram_id INTEGER,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'taken', 'resolved')),
+    created_at TIMESTAMP NOT NULL,
+    taken_at TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX idx_escalations_subject ON escalations(subject_type, subject_id, status);
+
+-- +goose Down
+DROP TABLE escalations;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Заметка ассистента о подписке ("платит 5-го", "пользуется iPhone"); пусто - нет заметки
+ALTER TABLE subscriptions ADD COLUMN note TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN note;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Пауза подписки: со статусом paused подписка не истекает, оставшиеся дни возвращаются при возобновлении
+ALTER TABLE subscriptions ADD COLUMN paused_at DATETIME;
+ALTER TABLE subscriptions ADD COLUMN paused_until DATETIME;
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN paused_until;
+ALTER TABLE subscriptions DROP COLUMN paused_at;
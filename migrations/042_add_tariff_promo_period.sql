@@ -0,0 +1,11 @@
+-- +goose Up
+-- Промо-тарифы: тариф доступен для покупки только в окне [valid_from, valid_until); NULL - без ограничения.
+-- fallback_tariff_id - тариф, на который продлеваются подписки после окончания промо
+ALTER TABLE tariffs ADD COLUMN valid_from DATETIME;
+ALTER TABLE tariffs ADD COLUMN valid_until DATETIME;
+ALTER TABLE tariffs ADD COLUMN fallback_tariff_id INTEGER REFERENCES tariffs(id);
+
+-- +goose Down
+ALTER TABLE tariffs DROP COLUMN fallback_tariff_id;
+ALTER TABLE tariffs DROP COLUMN valid_until;
+ALTER TABLE tariffs DROP COLUMN valid_from;
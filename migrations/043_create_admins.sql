@@ -0,0 +1,10 @@
+-- +goose Up
+-- Админы, назначенные через бота (/add_admin). Супер-админы из TELEGRAM_ADMIN_IDS здесь не хранятся
+CREATE TABLE admins (
+    telegram_id INTEGER PRIMARY KEY,
+    added_by INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE admins;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Токен агента WireGuard на сервере: агент забирает по нему список пиров для включения и отключения.
+-- Хранится только SHA-256 хеш токена
+ALTER TABLE servers ADD COLUMN agent_token_hash TEXT;
+CREATE UNIQUE INDEX idx_servers_agent_token_hash ON servers(agent_token_hash);
+
+-- +goose Down
+DROP INDEX idx_servers_agent_token_hash;
+ALTER TABLE servers DROP COLUMN agent_token_hash;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Роль пользователя в боте: admin, assistant, viewer; пустая строка - роль не назначена.
+-- Админы, назначенные через /add_admin, переезжают в роли, таблица admins больше не нужна
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT '';
+
+INSERT OR IGNORE INTO users (telegram_id, language) SELECT telegram_id, 'ru' FROM admins;
+UPDATE users SET role = 'admin' WHERE telegram_id IN (SELECT telegram_id FROM admins);
+
+DROP TABLE admins;
+
+CREATE INDEX idx_users_role ON users(role);
+
+-- +goose Down
+CREATE TABLE admins (
+    telegram_id INTEGER PRIMARY KEY,
+    added_by INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO admins (telegram_id, added_by) SELECT telegram_id, 0 FROM users WHERE role = 'admin';
+
+DROP INDEX idx_users_role;
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Блокировки воркеров: при запуске нескольких процессов воркеров (MODULES=workers) воркеры работают
+-- только в процессе, который держит блокировку; владелец продлевает expires_at, пока жив
+CREATE TABLE worker_locks (
+    name       TEXT PRIMARY KEY,
+    owner      TEXT     NOT NULL,
+    expires_at DATETIME NOT NULL
+);
+
+-- +goose Down
+DROP TABLE worker_locks;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- generated_user_id - имя пользователя на VPN-сервере, поэтому должен быть уникальным.
+-- Повторы у старых подписок исправляются: первая подписка сохраняет id, остальные получают суффикс _d<id подписки>.
+-- На VPN-сервере эти пользователи остались под старым именем, поэтому переименования записываются
+-- в generated_user_id_renames - админ видит их в /db_stats
+CREATE TABLE generated_user_id_renames (
+    subscription_id INTEGER PRIMARY KEY REFERENCES subscriptions(id),
+    old_user_id TEXT NOT NULL,
+    new_user_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO generated_user_id_renames (subscription_id, old_user_id, new_user_id)
+SELECT id, generated_user_id, generated_user_id || '_d' || id
+FROM subscriptions
+WHERE generated_user_id IS NOT NULL
+  AND EXISTS (SELECT 1
+              FROM subscriptions earlier
+              WHERE earlier.generated_user_id = subscriptions.generated_user_id
+                AND earlier.id < subscriptions.id);
+
+UPDATE subscriptions
+SET generated_user_id = (SELECT new_user_id
+                         FROM generated_user_id_renames renames
+                         WHERE renames.subscription_id = subscriptions.id)
+WHERE id IN (SELECT subscription_id FROM generated_user_id_renames);
+
+CREATE UNIQUE INDEX idx_subscriptions_generated_user_id ON subscriptions(generated_user_id);
+
+-- +goose Down
+DROP INDEX idx_subscriptions_generated_user_id;
+DROP TABLE IF EXISTS generated_user_id_renames;
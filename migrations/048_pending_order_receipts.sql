@@ -0,0 +1,13 @@
+-- +goose Up
+-- Чек об оплате в ручном режиме: ассистент прикрепляет фото к заказу, админ подтверждает или отклоняет.
+-- receipt_file_id - file_id фото в Telegram; заказ с чеком не удаляется после оплаты, чтобы чек оставался в истории
+ALTER TABLE pending_orders ADD COLUMN receipt_file_id TEXT;
+ALTER TABLE pending_orders ADD COLUMN receipt_uploaded_at TIMESTAMP;
+ALTER TABLE pending_orders ADD COLUMN receipt_reviewed_by INTEGER;
+ALTER TABLE pending_orders ADD COLUMN receipt_reviewed_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE pending_orders DROP COLUMN receipt_reviewed_at;
+ALTER TABLE pending_orders DROP COLUMN receipt_reviewed_by;
+ALTER TABLE pending_orders DROP COLUMN receipt_uploaded_at;
+ALTER TABLE pending_orders DROP COLUMN receipt_file_id;
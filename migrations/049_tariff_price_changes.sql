@@ -0,0 +1,21 @@
+-- +goose Up
+-- Запланированное повышение цены тарифа: до effective_at клиенты могут продлиться по старой цене.
+-- notified_at - ассистентам отправлены карточки клиентов, applied_at - цена тарифа обновлена
+CREATE TABLE tariff_price_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tariff_id INTEGER NOT NULL REFERENCES tariffs(id),
+    old_price REAL NOT NULL,
+    new_price REAL NOT NULL,
+    effective_at TIMESTAMP NOT NULL,
+    created_by_telegram_id INTEGER NOT NULL,
+    notified_at TIMESTAMP,
+    applied_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_tariff_price_changes_scheduled ON tariff_price_changes(tariff_id) WHERE applied_at IS NULL AND cancelled_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tariff_price_changes_scheduled;
+DROP TABLE IF EXISTS tariff_price_changes;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Отмены флоу: какой флоу, на каком шаге и почему (reason заполняется, если пользователь ответил на опрос).
+-- Нужны для статистики причин отказа от покупки
+CREATE TABLE flow_cancellations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    flow TEXT NOT NULL,
+    state TEXT NOT NULL,
+    telegram_id INTEGER NOT NULL,
+    reason TEXT,
+    answered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_flow_cancellations_created_at ON flow_cancellations(created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_flow_cancellations_created_at;
+DROP TABLE IF EXISTS flow_cancellations;
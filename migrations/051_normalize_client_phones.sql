@@ -0,0 +1,63 @@
+-- +goose Up
+-- Номера клиентов хранятся только цифрами (как storage.NormalizePhone), но часть старых записей сохранена
+-- как "+996..." или с пробелами и дефисами. Ссылки на номер нормализуются всегда, а сами клиенты - только если
+-- у номера один формат: клиенты, записанные под несколькими форматами, объединяются вручную через /merge_phones
+UPDATE subscriptions
+SET referrer_whatsapp = REPLACE(REPLACE(REPLACE(referrer_whatsapp, '+', ''), ' ', ''), '-', '')
+WHERE referrer_whatsapp <> REPLACE(REPLACE(REPLACE(referrer_whatsapp, '+', ''), ' ', ''), '-', '');
+
+UPDATE pending_orders
+SET client_whatsapp = REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '')
+WHERE client_whatsapp <> REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '');
+
+UPDATE pending_orders
+SET referrer_whatsapp = REPLACE(REPLACE(REPLACE(referrer_whatsapp, '+', ''), ' ', ''), '-', '')
+WHERE referrer_whatsapp <> REPLACE(REPLACE(REPLACE(referrer_whatsapp, '+', ''), ' ', ''), '-', '');
+
+UPDATE waitlist_entries
+SET client_whatsapp = REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '')
+WHERE client_whatsapp <> REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '');
+
+UPDATE drip_messages
+SET client_whatsapp = REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '')
+WHERE client_whatsapp <> REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '');
+
+UPDATE subscriptions
+SET client_whatsapp = REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '')
+WHERE client_whatsapp <> REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '')
+  AND NOT EXISTS (SELECT 1
+                  FROM subscriptions other
+                  WHERE other.client_whatsapp <> subscriptions.client_whatsapp
+                    AND REPLACE(REPLACE(REPLACE(other.client_whatsapp, '+', ''), ' ', ''), '-', '') =
+                        REPLACE(REPLACE(REPLACE(subscriptions.client_whatsapp, '+', ''), ' ', ''), '-', ''));
+
+-- В таблицах с номером в первичном ключе второй формат того же номера не дает переименовать запись
+UPDATE client_languages
+SET client_whatsapp = REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '')
+WHERE client_whatsapp <> REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '')
+  AND NOT EXISTS (SELECT 1
+                  FROM client_languages other
+                  WHERE other.client_whatsapp <> client_languages.client_whatsapp
+                    AND REPLACE(REPLACE(REPLACE(other.client_whatsapp, '+', ''), ' ', ''), '-', '') =
+                        REPLACE(REPLACE(REPLACE(client_languages.client_whatsapp, '+', ''), ' ', ''), '-', ''));
+
+UPDATE client_balances
+SET client_whatsapp = REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '')
+WHERE client_whatsapp <> REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '')
+  AND NOT EXISTS (SELECT 1
+                  FROM client_balances other
+                  WHERE other.client_whatsapp <> client_balances.client_whatsapp
+                    AND REPLACE(REPLACE(REPLACE(other.client_whatsapp, '+', ''), ' ', ''), '-', '') =
+                        REPLACE(REPLACE(REPLACE(client_balances.client_whatsapp, '+', ''), ' ', ''), '-', ''));
+
+UPDATE drip_optouts
+SET client_whatsapp = REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '')
+WHERE client_whatsapp <> REPLACE(REPLACE(REPLACE(client_whatsapp, '+', ''), ' ', ''), '-', '')
+  AND NOT EXISTS (SELECT 1
+                  FROM drip_optouts other
+                  WHERE other.client_whatsapp <> drip_optouts.client_whatsapp
+                    AND REPLACE(REPLACE(REPLACE(other.client_whatsapp, '+', ''), ' ', ''), '-', '') =
+                        REPLACE(REPLACE(REPLACE(drip_optouts.client_whatsapp, '+', ''), ' ', ''), '-', ''));
+
+-- +goose Down
+-- Исходные форматы номеров не сохраняются, откатывать нечего
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Тексты предзаполненных сообщений WhatsApp, измененные админом через /templates.
+-- Пустой language - основной текст для клиентов без отдельного перевода; без записи используется встроенный текст
+CREATE TABLE whatsapp_templates (
+    name TEXT NOT NULL,
+    language TEXT NOT NULL DEFAULT '',
+    text TEXT NOT NULL,
+    updated_by_telegram_id INTEGER NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (name, language)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS whatsapp_templates;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- История смены номера WhatsApp клиента в подписке: клиенты меняют номера, а старый номер нужен,
+-- чтобы найти переписку и платежи до смены
+CREATE TABLE subscription_phone_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id),
+    old_whatsapp TEXT NOT NULL,
+    new_whatsapp TEXT NOT NULL,
+    changed_by_telegram_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_subscription_phone_changes_subscription_id ON subscription_phone_changes(subscription_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_subscription_phone_changes_subscription_id;
+DROP TABLE IF EXISTS subscription_phone_changes;
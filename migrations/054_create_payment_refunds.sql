@@ -0,0 +1,28 @@
+-- +goose Up
+-- Возвраты платежей клиентам: оплаты отмененных заказов и возвраты по гарантии.
+-- На платеж не больше одного возврата; возврат, который ЮKassa отклонила (failed), можно повторить
+CREATE TABLE payment_refunds (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    payment_id INTEGER NOT NULL UNIQUE REFERENCES payments(id),
+    subscription_id INTEGER REFERENCES subscriptions(id),
+    reason TEXT NOT NULL CHECK (reason IN ('late_payment', 'guarantee')),
+    status TEXT NOT NULL CHECK (status IN ('pending', 'succeeded', 'failed')),
+    amount REAL NOT NULL,
+    requested_by_telegram_id INTEGER NOT NULL, -- 0 - возврат сделан до появления таблицы
+    provider_refund_id TEXT,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX idx_payment_refunds_completed_at ON payment_refunds(completed_at);
+
+-- Прежние возвраты поздних оплат, чтобы сводки за прошлые месяцы не изменились
+INSERT INTO payment_refunds (payment_id, reason, status, amount, requested_by_telegram_id, created_at, completed_at)
+SELECT id, 'late_payment', 'succeeded', amount, 0, COALESCE(late_resolved_at, updated_at), COALESCE(late_resolved_at, updated_at)
+FROM payments
+WHERE late_status = 'refunded';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_payment_refunds_completed_at;
+DROP TABLE IF EXISTS payment_refunds;
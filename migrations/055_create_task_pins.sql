@@ -0,0 +1,11 @@
+-- +goose Up
+-- Закрепленное сообщение «задачи на сегодня» в чате ассистента: нужно, чтобы на следующий день
+-- открепить его и закрепить новое, в том числе после перезапуска бота
+CREATE TABLE task_pins (
+    chat_id INTEGER PRIMARY KEY,
+    message_id INTEGER NOT NULL,
+    pinned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS task_pins;
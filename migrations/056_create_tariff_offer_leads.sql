@@ -0,0 +1,18 @@
+-- +goose Up
+-- Клиенты, открывшие ссылку из предложения тарифа (inline-режим «@бот тарифы»). Заявка закрепляется за
+-- ассистентом, который отправил предложение; повторное открытие той же ссылки новую заявку не создает
+CREATE TABLE tariff_offer_leads (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tariff_id INTEGER NOT NULL REFERENCES tariffs(id),
+    assistant_telegram_id INTEGER NOT NULL,
+    client_telegram_id INTEGER NOT NULL,
+    client_name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tariff_id, assistant_telegram_id, client_telegram_id)
+);
+
+CREATE INDEX idx_tariff_offer_leads_assistant ON tariff_offer_leads(assistant_telegram_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tariff_offer_leads_assistant;
+DROP TABLE IF EXISTS tariff_offer_leads;